@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+)
+
+var assumeYes bool      // Start runs without asking, whatever their estimated cost
+var confirmCost float64 // Estimated cost in USD above which a run asks for confirmation
+
+// defaultConfirmCost is the estimated run cost in USD above which confirmation is asked by default
+const defaultConfirmCost = 5.0
+
+// pendingTexts returns the source texts a run will translate into target, without
+// changing target
+func pendingTexts(source, target, indep *parser.LocaleFileContent, opts processOptions) ([]string, error) {
+	keys, _, err := pendingKeys(source, target, indep, opts)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		texts = append(texts, source.LocaleItemsMap[k])
+	}
+	return texts, nil
+}
+
+// pendingKeys returns the sorted source keys a run will translate into target, with
+// the current values of target once retranslation markers are taken out, without
+// changing target
+func pendingKeys(source, target, indep *parser.LocaleFileContent, opts processOptions) ([]string, map[string]string, error) {
+	items := make(map[string]string, len(target.LocaleItemsMap))
+	for k, v := range target.LocaleItemsMap {
+		items[k] = v
+	}
+	// Extracting suffix markers renames keys, so work on a copy
+	copied := &parser.LocaleFileContent{Code: target.Code, Lang: target.Lang, Path: target.Path, LocaleItemsMap: items}
+	marked, err := opts.Marker.Extract(opts.fs(), copied)
+	if err != nil {
+		return nil, nil, err
+	}
+	return forecast.PendingKeys(opts.restrict(source).LocaleItemsMap, copied.LocaleItemsMap, marked, opts.Mode == "full" && indep == nil), copied.LocaleItemsMap, nil
+}
+
+// approveRun estimates the cost of translating texts and, above threshold, asks for
+// confirmation on the terminal. Without a terminal the run is refused unless --yes is set.
+func approveRun(gptHandler *gpt.Handler, texts []string, batch int, threshold float64) bool {
+	samples, _ := forecast.LoadSamples()
+	f := forecast.Estimate(texts, forecast.Options{
+		Model:       gptHandler.Model(),
+		BatchSize:   batch,
+		BatchTokens: gptHandler.BatchLimit(),
+		Expansion:   forecast.DefaultExpansion,
+		Throughput:  forecast.MeasureThroughput(samples, gptHandler.Model(), batch > 0),
+	})
+	return confirmEstimate(f, threshold, os.Stdin, os.Stdout, isTerminal(os.Stdin))
+}
+
+// confirmEstimate asks whether to start a run of estimate f when its cost exceeds
+// threshold, reading the answer from in. 0 disables the check.
+func confirmEstimate(f forecast.Forecast, threshold float64, in io.Reader, out io.Writer, interactive bool) bool {
+	if assumeYes || threshold <= 0 || !f.CostKnown || f.Cost <= threshold {
+		return true
+	}
+
+	fmt.Fprintf(out, "💰 This run translates %d texts in %d requests, estimated at $%.2f (confirmation threshold: $%.2f)\n", f.Keys, f.Requests, f.Cost, threshold)
+	if !interactive {
+		fmt.Fprintln(out, "❌ Not starting without confirmation; rerun with --yes or raise confirmCost")
+		return false
+	}
+
+	fmt.Fprint(out, "Continue? [y/N] ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	fmt.Fprintln(out, "🛑 Cancelled")
+	return false
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
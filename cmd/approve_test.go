@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfirmEstimate tests that only runs above the threshold need a confirmation
+func TestConfirmEstimate(t *testing.T) {
+	expensive := forecast.Forecast{Keys: 5000, Requests: 250, Cost: 12.5, CostKnown: true}
+	var out bytes.Buffer
+
+	assert.True(t, confirmEstimate(forecast.Forecast{Cost: 1, CostKnown: true}, 5, strings.NewReader(""), &out, false))
+	assert.True(t, confirmEstimate(expensive, 0, strings.NewReader(""), &out, false))
+	assert.Empty(t, out.String())
+
+	assert.True(t, confirmEstimate(expensive, 5, strings.NewReader("y\n"), &out, true))
+	assert.Contains(t, out.String(), "$12.50")
+	assert.False(t, confirmEstimate(expensive, 5, strings.NewReader("\n"), &out, true))
+	assert.False(t, confirmEstimate(expensive, 5, strings.NewReader("yes\n"), &out, false))
+
+	assumeYes = true
+	defer func() { assumeYes = false }()
+	assert.True(t, confirmEstimate(expensive, 5, strings.NewReader(""), &out, false))
+}
+
+// TestPendingTexts tests that estimating a run leaves suffix-marked targets untouched
+func TestPendingTexts(t *testing.T) {
+	source := &parser.LocaleFileContent{LocaleItemsMap: map[string]string{"a": "A", "b": "B", "c": "C"}}
+	target := &parser.LocaleFileContent{LocaleItemsMap: map[string]string{"a": "À", "b!": "Bé"}}
+	opts := processOptions{Mode: "full", Marker: marker.Marker{Style: marker.StyleSuffix, Suffix: "!"}}
+
+	texts, err := pendingTexts(source, target, nil, opts)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"B", "C"}, texts)
+	assert.Contains(t, target.LocaleItemsMap, "b!")
+
+	// Keys of existing targets are never retranslated with an independent file
+	texts, err = pendingTexts(source, target, target, opts)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"C"}, texts)
+}
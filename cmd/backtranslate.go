@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// backMethodEmbedding compares the source and the back-translation by the cosine
+	// similarity of their embeddings
+	backMethodEmbedding = "embedding"
+	// backMethodJudge has the model rate whether the back-translation keeps the meaning
+	backMethodJudge = "judge"
+)
+
+// backBatchSize is the number of translations back-translated per request
+const backBatchSize = 20
+
+var backTranslateCmd = &cobra.Command{
+	Use:   "back-translate",
+	Short: "Flag translations whose back-translation diverges from the source",
+	Long: `Translate the translations of the target catalogs back into the source language and
+compare each back-translation with the original source text, by the cosine similarity of
+their embeddings (--method embedding, OpenAI only) or by having the model rate whether
+the meaning was kept (--method judge, the default for other providers). Keys whose
+back-translation diverges badly are written to a review report. Locale files are never
+modified. Exits with a non-zero status when keys are flagged.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		method, _ := cmd.Flags().GetString("method")
+		minSimilarity, _ := cmd.Flags().GetFloat64("min-similarity")
+		minScore, _ := cmd.Flags().GetInt("min-score")
+		sampleSize, _ := cmd.Flags().GetInt("sample")
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			os.Exit(1)
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			os.Exit(1)
+		}
+		defer printUsageReport(gptHandler)
+		if method == "" {
+			method = backMethodJudge
+			if gptHandler.Provider() == gpt.ProviderOpenAI {
+				method = backMethodEmbedding
+			}
+		}
+		if method != backMethodEmbedding && method != backMethodJudge {
+			fmt.Printf("❌ Unknown method %q, use %s or %s\n", method, backMethodEmbedding, backMethodJudge)
+			os.Exit(1)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		entries := map[string][]tm.Entry{}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range memoryEntries(source.LocaleItemsMap, target.LocaleItemsMap, nil, pair.TargetLang) {
+				e.Key = pair.TargetFile + ":" + e.Key
+				entries[pair.TargetLang] = append(entries[pair.TargetLang], e)
+			}
+		}
+
+		sourceName, err := langCodeToName(sourceLang)
+		if err != nil {
+			sourceName = sourceLang
+		}
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		ctx := context.Background()
+		results := []backResult{}
+		langs = make([]string, 0, len(entries))
+		for lang := range entries {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			sample := entries[lang]
+			if sampleSize > 0 && len(sample) > sampleSize {
+				rng.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+				sample = sample[:sampleSize]
+				sort.Slice(sample, func(i, j int) bool { return sample[i].Key < sample[j].Key })
+			}
+			fmt.Printf("🔁 Back-translating %d %s translations\n", len(sample), lang)
+			langResults, err := backTranslate(gpt.WithLanguages(ctx, lang, sourceLang), gptHandler, sample, sourceName, method)
+			if err != nil {
+				fmt.Printf("❌ Error back-translating %s: %v\n", lang, err)
+				os.Exit(1)
+			}
+			results = append(results, langResults...)
+		}
+
+		flagged := divergent(results, method, minSimilarity, minScore)
+		report := backTranslationReport(results, flagged, method)
+		if outputPath != "" {
+			if err := os.WriteFile(outputPath, []byte(report), 0644); err != nil {
+				fmt.Printf("❌ Error writing report: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Review report saved to %s\n", outputPath)
+		} else {
+			fmt.Print("\n" + report)
+		}
+
+		if len(flagged) > 0 {
+			fmt.Printf("\n⚠️ %d of %d translations diverge from their source once back-translated\n", len(flagged), len(results))
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ %d translations keep their meaning once back-translated\n", len(results))
+	},
+}
+
+// backTranslator back-translates translations and compares them with their source
+type backTranslator interface {
+	BatchTranslate(ctx context.Context, keys []string, texts []string, lang string) ([]string, error)
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Equivalence(ctx context.Context, source, back, lang string) (gpt.Grade, error)
+}
+
+var _ backTranslator = (*gpt.Handler)(nil)
+
+// backResult is the back-translation of a translation and how close it is to its source
+type backResult struct {
+	tm.Entry
+	Back string
+	// Cosine similarity of the embeddings of the source and the back-translation, with
+	// the embedding method
+	Similarity float64
+	// Rating of the model from 1 to 5 and its reason, with the judge method
+	Score  int
+	Reason string
+}
+
+// backTranslate translates the targets of entries back into sourceName and compares them
+// with their source by method
+func backTranslate(ctx context.Context, h backTranslator, entries []tm.Entry, sourceName, method string) ([]backResult, error) {
+	results := make([]backResult, 0, len(entries))
+	for start := 0; start < len(entries); start += backBatchSize {
+		end := start + backBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		texts := make([]string, 0, end-start)
+		for _, e := range entries[start:end] {
+			texts = append(texts, e.Target)
+		}
+		backs, err := h.BatchTranslate(ctx, nil, texts, sourceName)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range entries[start:end] {
+			results = append(results, backResult{Entry: e, Back: backs[i]})
+		}
+	}
+
+	switch method {
+	case backMethodEmbedding:
+		texts := make([]string, 0, 2*len(results))
+		for _, r := range results {
+			texts = append(texts, r.Source, r.Back)
+		}
+		if len(texts) == 0 {
+			return results, nil
+		}
+		vectors, err := h.Embed(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Similarity = tm.Cosine(vectors[2*i], vectors[2*i+1])
+		}
+	case backMethodJudge:
+		for i, r := range results {
+			grade, err := h.Equivalence(ctx, r.Source, r.Back, r.Lang)
+			if err != nil {
+				return nil, err
+			}
+			results[i].Score, results[i].Reason = grade.Score, grade.Reason
+		}
+	}
+	return results, nil
+}
+
+// divergent returns the results whose back-translation diverges from the source: below
+// minSimilarity with the embedding method, rated below minScore with the judge method
+func divergent(results []backResult, method string, minSimilarity float64, minScore int) []backResult {
+	flagged := []backResult{}
+	for _, r := range results {
+		if (method == backMethodEmbedding && r.Similarity < minSimilarity) || (method == backMethodJudge && r.Score < minScore) {
+			flagged = append(flagged, r)
+		}
+	}
+	return flagged
+}
+
+// backTranslationReport returns the review report of the flagged results in markdown:
+// the counts per language, then the flagged keys with their back-translation
+func backTranslationReport(results, flagged []backResult, method string) string {
+	var report strings.Builder
+	report.WriteString("# Back-Translation Review\n\n")
+	report.WriteString(fmt.Sprintf("Method: %s\n\n", method))
+
+	checked, diverging := map[string]int{}, map[string]int{}
+	langs := []string{}
+	for _, r := range results {
+		if checked[r.Lang] == 0 {
+			langs = append(langs, r.Lang)
+		}
+		checked[r.Lang]++
+	}
+	for _, r := range flagged {
+		diverging[r.Lang]++
+	}
+	summary := table.New("Language", "Checked", "Diverging")
+	for _, lang := range langs {
+		summary.Add(lang, checked[lang], diverging[lang])
+	}
+	report.WriteString(summary.String())
+
+	if len(flagged) == 0 {
+		return report.String()
+	}
+	report.WriteString("\n## Keys to Review\n\n")
+	keys := table.New("Key", "Source", "Translation", "Back-Translation", "Closeness")
+	keys.MaxWidth = historyCellWidth
+	for _, r := range flagged {
+		closeness := fmt.Sprintf("%.2f", r.Similarity)
+		if method == backMethodJudge {
+			closeness = fmt.Sprintf("%d/5 %s", r.Score, r.Reason)
+		}
+		keys.Add(r.Key, r.Source, r.Target, r.Back, closeness)
+	}
+	report.WriteString(keys.String())
+	return report.String()
+}
+
+func init() {
+	backTranslateCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	backTranslateCmd.Flags().String("source", "en", "Source language code (default: en)")
+	backTranslateCmd.Flags().String("config", "", "Path to configuration file")
+	backTranslateCmd.Flags().StringSlice("lang", nil, "Languages to check (default: every target language)")
+	backTranslateCmd.Flags().String("method", "", "Comparison of sources and back-translations: 'embedding' or 'judge' (default: embedding with OpenAI, judge otherwise)")
+	backTranslateCmd.Flags().Float64("min-similarity", 0.75, "Embedding similarity below which a back-translation diverges")
+	backTranslateCmd.Flags().Int("min-score", 3, "Rating of the judge, from 1 to 5, below which a back-translation diverges")
+	backTranslateCmd.Flags().Int("sample", 0, "Number of translations to check per language, picked at random (0 checks them all)")
+	backTranslateCmd.Flags().String("output", "", "Save the review report to a file")
+
+	backTranslateCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(backTranslateCmd)
+}
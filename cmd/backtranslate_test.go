@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackTranslator back-translates from a map, embeds texts as one-hot vectors of
+// their words and judges identical texts equivalent
+type fakeBackTranslator struct {
+	backs map[string]string
+}
+
+func (f fakeBackTranslator) BatchTranslate(ctx context.Context, keys []string, texts []string, lang string) ([]string, error) {
+	backs := make([]string, len(texts))
+	for i, text := range texts {
+		backs[i] = f.backs[text]
+	}
+	return backs, nil
+}
+
+func (f fakeBackTranslator) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vocabulary := map[string]int{}
+	for _, text := range texts {
+		for w := range wordsOf(text) {
+			if _, ok := vocabulary[w]; !ok {
+				vocabulary[w] = len(vocabulary)
+			}
+		}
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(vocabulary))
+		for w := range wordsOf(text) {
+			vectors[i][vocabulary[w]] = 1
+		}
+	}
+	return vectors, nil
+}
+
+func (f fakeBackTranslator) Equivalence(ctx context.Context, source, back, lang string) (gpt.Grade, error) {
+	if source == back {
+		return gpt.Grade{Score: 5}, nil
+	}
+	return gpt.Grade{Score: 1, Reason: "meaning reversed"}, nil
+}
+
+// wordsOf returns the words of text
+func wordsOf(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		words[w] = true
+	}
+	return words
+}
+
+// TestBackTranslate tests that diverging back-translations are flagged by both methods
+func TestBackTranslate(t *testing.T) {
+	entries := []tm.Entry{
+		{Key: "fr.json:save", Source: "Save changes", Target: "Enregistrer les modifications", Lang: "fr"},
+		{Key: "fr.json:delete", Source: "Delete account", Target: "Garder le compte", Lang: "fr"},
+	}
+	h := fakeBackTranslator{backs: map[string]string{
+		"Enregistrer les modifications": "Save changes",
+		"Garder le compte":              "Keep account",
+	}}
+
+	for _, method := range []string{backMethodEmbedding, backMethodJudge} {
+		results, err := backTranslate(context.Background(), h, entries, "English", method)
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		flagged := divergent(results, method, 0.75, 3)
+		if assert.Len(t, flagged, 1, method) {
+			assert.Equal(t, "fr.json:delete", flagged[0].Key)
+			assert.Equal(t, "Keep account", flagged[0].Back)
+		}
+
+		report := backTranslationReport(results, flagged, method)
+		assert.Contains(t, report, "Keys to Review")
+		assert.Contains(t, report, "Keep account")
+		assert.NotContains(t, report, "Enregistrer")
+	}
+}
@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var asyncMode bool // Submit pending strings to the OpenAI Batch API instead of translating them now
+
+// jobManifest records what a batch job translates, so that its results can be
+// written to the right keys once it is done
+type jobManifest struct {
+	Job       gpt.Job   `json:"job"`
+	CreatedAt time.Time `json:"createdAt"`
+	Model     string    `json:"model"`
+	Items     []jobItem `json:"items"`
+	// Set once the results were written to the target files
+	Fetched bool `json:"fetched,omitempty"`
+}
+
+// jobItem is a text of a batch job: the value of a key, or an element of its value
+// when the value is a JSON array
+type jobItem struct {
+	ID   string           `json:"id"`
+	Pair scanner.FilePair `json:"pair"`
+	Key  string           `json:"key"`
+	Text string           `json:"text"`
+	// Index of the text in the JSON array value of the key, -1 for plain values
+	Index int `json:"index"`
+	// Set when the key was flagged for retranslation
+	Marked bool `json:"marked,omitempty"`
+}
+
+// jobManifestPrefix prefixes the names of manifest files in the state directory
+const jobManifestPrefix = "job-"
+
+func jobManifestName(id string) string {
+	return jobManifestPrefix + id + ".json"
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Follow translation jobs submitted with sync --async",
+	Long:  `Translation jobs submitted to the OpenAI Batch API with sync --async are answered within 24 hours at half the price of realtime requests. Use poll to check their progress and fetch to write their results to the target files.`,
+}
+
+var batchPollCmd = &cobra.Command{
+	Use:   "poll [job-id]",
+	Short: "Show the progress of batch jobs",
+	Long:  `Show the progress of a batch job, or of every job whose results were not fetched yet.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifests, gptHandler, _, ok := loadJobs(cmd, args)
+		if !ok {
+			return
+		}
+		ctx := context.Background()
+		for _, m := range manifests {
+			job, err := gptHandler.PollJob(ctx, m.Job)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			m.Job = job
+			if err := state.Save(jobManifestName(job.ID), m); err != nil {
+				fmt.Printf("⚠️ Error saving manifest of job %s: %v\n", job.ID, err)
+			}
+			fmt.Printf("%s %s: %s, %d/%d requests done (%d failed), submitted %s\n", jobIcon(job), job.ID, job.Status, job.Completed, job.Total, job.Failed, m.CreatedAt.Format(time.RFC3339))
+		}
+	},
+}
+
+var batchFetchCmd = &cobra.Command{
+	Use:   "fetch [job-id]",
+	Short: "Write the results of done batch jobs to the target files",
+	Long:  `Download the results of a batch job, or of every done job whose results were not fetched yet, and write them to the target files. Translations failing validation and keys whose source text changed since the job was submitted are left untouched, to be translated by the next sync.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		manifests, gptHandler, cfg, ok := loadJobs(cmd, args)
+		if !ok {
+			return
+		}
+		release, err := acquireProjectLock(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		defer release()
+
+		opts := processOptions{Mode: cfg.Mode, Marker: cfg.Marker, Keys: cfg.Keys}
+		safeMode = cfg.Safe
+		framework = cfg.Framework
+		if cfg.LocalizeExamples {
+			examplesFrom = cfg.SourceLang
+		}
+		rewriteOutput = cfg.Rewrite
+		sortOptions = cfg.Sort
+
+		ctx := cmd.Context()
+		for _, m := range manifests {
+			job, err := gptHandler.PollJob(ctx, m.Job)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			m.Job = job
+			if !job.Done() {
+				fmt.Printf("⏳ %s: %s, %d/%d requests done, fetch again later\n", job.ID, job.Status, job.Completed, job.Total)
+				continue
+			}
+
+			auditProvider = gpt.ProviderOpenAI + "/" + m.Model
+			if err := fetchJob(ctx, gptHandler, m, opts); err != nil {
+				fmt.Printf("❌ Error fetching job %s: %v\n", job.ID, err)
+				continue
+			}
+			m.Fetched = true
+			if err := state.Save(jobManifestName(job.ID), m); err != nil {
+				fmt.Printf("⚠️ Error saving manifest of job %s: %v\n", job.ID, err)
+			}
+		}
+	},
+}
+
+// loadJobs returns the manifests of the job given in args, or of every unfetched job,
+// with the handler to query them and the configuration, the default one when none is given
+func loadJobs(cmd *cobra.Command, args []string) ([]*jobManifest, *gpt.Handler, *config.Config, bool) {
+	cfg, err := loadOptionalConfig(cmd)
+	if err != nil {
+		fmt.Printf("❌ Error loading configuration: %v\n", err)
+		return nil, nil, nil, false
+	}
+
+	var manifests []*jobManifest
+	if len(args) == 1 {
+		m, err := loadJobManifest(args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return nil, nil, nil, false
+		}
+		manifests = []*jobManifest{m}
+	} else {
+		manifests, err = pendingJobManifests()
+		if err != nil {
+			fmt.Printf("❌ Error listing jobs: %v\n", err)
+			return nil, nil, nil, false
+		}
+		if len(manifests) == 0 {
+			fmt.Println("✅ No batch job waiting to be fetched")
+			return nil, nil, nil, false
+		}
+	}
+
+	apiKeys := resolveAPIKeys(cfg)
+	if len(apiKeys) == 0 {
+		fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+		return nil, nil, nil, false
+	}
+	gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+	if err != nil {
+		fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+		return nil, nil, nil, false
+	}
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return manifests, gptHandler, cfg, true
+}
+
+// loadJobManifest reads the manifest of job id from the state directory
+func loadJobManifest(id string) (*jobManifest, error) {
+	m := &jobManifest{}
+	if err := state.Load(jobManifestName(id), m); err != nil {
+		return nil, fmt.Errorf("error reading manifest of job %s: %w", id, err)
+	}
+	if m.Job.ID == "" {
+		return nil, fmt.Errorf("no batch job %s was submitted from this directory", id)
+	}
+	return m, nil
+}
+
+// pendingJobManifests returns the manifests of the jobs whose results were not
+// fetched yet, the oldest first
+func pendingJobManifests() ([]*jobManifest, error) {
+	dir, err := state.ProjectDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, jobManifestPrefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []*jobManifest{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		m := &jobManifest{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+		if !m.Fetched {
+			manifests = append(manifests, m)
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].CreatedAt.Before(manifests[j].CreatedAt) })
+	return manifests, nil
+}
+
+func jobIcon(job gpt.Job) string {
+	switch {
+	case job.Status == gpt.JobCompleted:
+		return "✅"
+	case job.Done():
+		return "⚠️"
+	}
+	return "⏳"
+}
+
+// jobItems returns the texts the pairs are pending, with one item per element of
+// JSON array values. Secrets are left out, as in realtime runs.
+func jobItems(ctx context.Context, gptHandler Translator, pairs []scanner.FilePair, opts processOptions) ([]jobItem, map[string][]gpt.Example, error) {
+	items := []jobItem{}
+	examples := map[string][]gpt.Example{}
+	for _, pair := range pairs {
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading pair: %w", err)
+		}
+		marked, err := opts.Marker.Extract(opts.fs(), target)
+		if err != nil {
+			return nil, nil, err
+		}
+		source = withoutSecrets(gptHandler, source)
+		pairOpts := opts.withMemory(gpt.WithLanguages(ctx, source.Code, target.Code), gptHandler, source, target, marked)
+
+		for _, k := range forecast.PendingKeys(pairOpts.restrict(source).LocaleItemsMap, target.LocaleItemsMap, marked, opts.Mode == "full") {
+			if protected.Key(k) {
+				// Left to the next realtime sync, which copies them untranslated
+				continue
+			}
+			v := source.LocaleItemsMap[k]
+			_, isMarked := marked[k]
+			texts := []string{v}
+			elements, isArray := jsonArray(v)
+			if isArray {
+				texts = elements
+			}
+			for i, text := range texts {
+				item := jobItem{ID: strconv.Itoa(len(items)), Pair: pair, Key: k, Text: text, Index: -1, Marked: isMarked}
+				if isArray {
+					item.Index = i
+				}
+				items = append(items, item)
+				examples[item.ID] = pairOpts.examplesFor(text, target)
+			}
+		}
+	}
+	return items, examples, nil
+}
+
+// jsonArray returns the elements of v when it is a JSON array of strings
+func jsonArray(v string) ([]string, bool) {
+	if !strings.HasPrefix(v, "[") || !strings.HasSuffix(v, "]") {
+		return nil, false
+	}
+	var elements []string
+	if err := json.Unmarshal([]byte(v), &elements); err != nil {
+		return nil, false
+	}
+	return elements, true
+}
+
+// submitJobs submits the texts pending in pairs as batch jobs of at most
+// gpt.MaxJobRequests requests, and writes their manifests to the state directory
+func submitJobs(ctx context.Context, gptHandler *gpt.Handler, pairs []scanner.FilePair, opts processOptions) error {
+	items, examples, err := jobItems(ctx, gptHandler, pairs, opts)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("✅ Nothing to translate")
+		return nil
+	}
+
+	for start := 0; start < len(items); start += gpt.MaxJobRequests {
+		end := start + gpt.MaxJobRequests
+		if end > len(items) {
+			end = len(items)
+		}
+		chunk := items[start:end]
+
+		requests := make([]gpt.JobRequest, len(chunk))
+		for i, item := range chunk {
+			meta := opts.Keys.Lookup(item.Key)
+			requests[i] = gpt.JobRequest{ID: item.ID, Key: item.Key, Text: item.Text, Lang: item.Pair.TargetLang, Examples: examples[item.ID], Glossary: termbase.Prompt([]string{item.Text}, item.Pair.TargetLang), Instructions: withLength(instructionsFor(item.Pair.TargetLang), opts.lengthInstruction(item.Key)), Description: meta.Describe(), Screenshot: meta.Screenshot}
+		}
+		job, err := gptHandler.SubmitJob(ctx, requests)
+		if err != nil {
+			return err
+		}
+
+		m := jobManifest{Job: job, CreatedAt: opts.clock().Now(), Model: gptHandler.Model(), Items: chunk}
+		if err := state.Save(jobManifestName(job.ID), m); err != nil {
+			return fmt.Errorf("error saving manifest of job %s: %w", job.ID, err)
+		}
+		fmt.Printf("📤 Submitted batch job %s with %d texts\n", job.ID, len(chunk))
+	}
+	fmt.Println("✅ Run i18n-cli batch poll to follow the jobs and i18n-cli batch fetch to write their results once done")
+	return nil
+}
+
+// fetchJob writes the results of a done job to its target files. Keys whose source
+// text changed since the job was submitted are skipped.
+func fetchJob(ctx context.Context, gptHandler *gpt.Handler, m *jobManifest, opts processOptions) error {
+	translations, failures, err := gptHandler.JobResults(ctx, m.Job)
+	if err != nil {
+		return err
+	}
+
+	byTarget := map[string][]jobItem{}
+	for _, item := range m.Items {
+		byTarget[item.Pair.TargetFile] = append(byTarget[item.Pair.TargetFile], item)
+	}
+	paths := make([]string, 0, len(byTarget))
+	for path := range byTarget {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		items := byTarget[path]
+		pair := items[0].Pair
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			return fmt.Errorf("error loading pair: %w", err)
+		}
+		if _, err := opts.Marker.Extract(opts.fs(), target); err != nil {
+			return err
+		}
+
+		byKey := map[string][]jobItem{}
+		keys := []string{}
+		for _, item := range items {
+			if _, ok := byKey[item.Key]; !ok {
+				keys = append(keys, item.Key)
+			}
+			byKey[item.Key] = append(byKey[item.Key], item)
+		}
+
+		translated, failedKeys, retranslatedKeys := 0, []string{}, []string{}
+		for _, k := range keys {
+			keyItems := byKey[k]
+			value, err := jobValue(keyItems, translations, failures, target, opts)
+			if err == nil && jobSource(keyItems) != source.LocaleItemsMap[k] {
+				fmt.Printf("⚠️ Key %s changed since the job was submitted, skipping\n", k)
+				continue
+			}
+			if err != nil {
+				fmt.Printf("⚠️ Error translating key %s: %v\n", k, err)
+				opts.logTranslationError(k, source.LocaleItemsMap[k], target.Lang, err)
+				failedKeys = append(failedKeys, k)
+				continue
+			}
+			target.LocaleItemsMap[k] = value
+			translated++
+			if keyItems[0].Marked {
+				retranslatedKeys = append(retranslatedKeys, k)
+			}
+		}
+
+		if len(failedKeys) > 0 {
+			opts.saveFailedKeys(target, failedKeys)
+		}
+		if err := opts.writeLocaleFile(target); err != nil {
+			return err
+		}
+		if err := opts.Marker.Clear(opts.fs(), target, retranslatedKeys); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s: %d keys translated, %d failed\n", target.Path, translated, len(failedKeys))
+	}
+	return nil
+}
+
+// jobSource returns the source value the items of a key were submitted for
+func jobSource(items []jobItem) string {
+	if items[0].Index < 0 {
+		return items[0].Text
+	}
+	elements := make([]string, len(items))
+	for _, item := range items {
+		elements[item.Index] = item.Text
+	}
+	data, _ := json.Marshal(elements)
+	return string(data)
+}
+
+// jobValue returns the translated value of the items of a key, all of whose texts
+// must have been answered and pass validation
+func jobValue(items []jobItem, translations map[string]string, failures map[string]error, target *parser.LocaleFileContent, opts processOptions) (string, error) {
+	results := make([]string, len(items))
+	for _, item := range items {
+		result, ok := translations[item.ID]
+		if !ok {
+			if err, failed := failures[item.ID]; failed {
+				return "", err
+			}
+			return "", fmt.Errorf("not answered by the batch job")
+		}
+		result, err := protected.Restore(item.Text, result)
+		if err != nil {
+			return "", err
+		}
+		result = framework.Fix(item.Text, result)
+		if err := checkTranslation(item.Text, result, target); err != nil {
+			return "", err
+		}
+		result = opts.finalize(item.Key, item.Text, result, target.Code)
+		if item.Index < 0 {
+			return result, opts.checkLength(item.Key, result)
+		}
+		results[item.Index] = result
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{batchPollCmd, batchFetchCmd} {
+		c.Flags().String("root", "", "Root directory of the catalogs the jobs were submitted for")
+		c.Flags().String("config", "", "Path to configuration file")
+		c.MarkFlagRequired("root")
+		batchCmd.AddCommand(c)
+	}
+
+	rootCmd.AddCommand(batchCmd)
+}
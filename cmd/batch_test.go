@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJobValue tests that batch job answers are reassembled into key values, JSON
+// arrays included, and that keys with an unanswered text fail as a whole
+func TestJobValue(t *testing.T) {
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French", LocaleItemsMap: map[string]string{}}
+	items := []jobItem{
+		{ID: "0", Key: "days", Text: "Monday", Index: 0},
+		{ID: "1", Key: "days", Text: "Tuesday", Index: 1},
+	}
+	translations := map[string]string{"0": "Lundi", "1": "Mardi", "2": "Bonjour"}
+
+	value, err := jobValue(items, translations, nil, target, processOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `["Lundi","Mardi"]`, value)
+	assert.Equal(t, `["Monday","Tuesday"]`, jobSource(items))
+
+	plain := []jobItem{{ID: "2", Key: "hello", Text: "Hello", Index: -1}}
+	value, err = jobValue(plain, translations, nil, target, processOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bonjour", value)
+	assert.Equal(t, "Hello", jobSource(plain))
+
+	delete(translations, "1")
+	_, err = jobValue(items, translations, map[string]error{"1": errors.New("rate_limit")}, target, processOptions{})
+	assert.EqualError(t, err, "rate_limit")
+	delete(translations, "0")
+	_, err = jobValue(items, translations, nil, target, processOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/benchmark"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Compare machine translations with professional reference translations",
+	Long: `Machine-translate the source texts of professionally translated reference catalogs and
+score the results per language: chrF (character n-gram F-score) and BLEU against the
+references, the share of identical translations and, with --grade, the adequacy rated
+by the model from 1 to 5. Languages scoring above the thresholds are reported as
+acceptable for machine translation. The reference directory uses the layout of the
+catalogs; locale files are never modified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		referenceDir, _ := cmd.Flags().GetString("reference")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		sampleSize, _ := cmd.Flags().GetInt("sample")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		grade, _ := cmd.Flags().GetBool("grade")
+		minChrF, _ := cmd.Flags().GetFloat64("min-chrf")
+		minAdequacy, _ := cmd.Flags().GetFloat64("min-adequacy")
+		format, _ := cmd.Flags().GetString("format")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			registers, langNotes, geoNames = cfg.Registers(), cfg.Instructions, cfg.GeoNames
+			framework = cfg.Framework
+		}
+		if termbase, err = loadGlossary(cfg); err != nil {
+			fmt.Printf("❌ Error loading glossary: %v\n", err)
+			os.Exit(1)
+		}
+		if templates, err = loadValues(cfg); err != nil {
+			fmt.Printf("❌ Error loading values file: %v\n", err)
+			os.Exit(1)
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			os.Exit(1)
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			os.Exit(1)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		if len(langs) == 0 {
+			langs = selectTargetLanguages(ds, cfg)
+		}
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed))
+		ctx := context.Background()
+		if format != "json" {
+			fmt.Printf("🔍 Benchmarking up to %d keys per language against %s (seed %d)\n", sampleSize, referenceDir, seed)
+		}
+
+		results := []benchmarkResult{}
+		for _, lang := range langs {
+			entries, err := referenceEntries(ds, referenceDir, lang)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+			if sampleSize > 0 && len(entries) > sampleSize {
+				entries = entries[:sampleSize]
+			}
+
+			result := benchmarkLanguage(ctx, gptHandler, ds.SourceLang, lang, entries, grade)
+			result.Acceptable = result.Keys > 0 && result.ChrF >= minChrF && (result.Adequacy == nil || *result.Adequacy >= minAdequacy)
+			results = append(results, result)
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		if len(results) == 0 {
+			fmt.Printf("⚠️ No reference catalogs found in %s\n", referenceDir)
+			return
+		}
+
+		tbl := table.New("Language", "Keys", "chrF", "BLEU", "Exact", "Adequacy", "Acceptable")
+		for _, r := range results {
+			adequacy := "-"
+			if r.Adequacy != nil {
+				adequacy = fmt.Sprintf("%.2f", *r.Adequacy)
+			}
+			acceptable := "❌"
+			if r.Acceptable {
+				acceptable = "✅"
+			}
+			tbl.Add(r.Lang, r.Keys, fmt.Sprintf("%.1f", r.ChrF), fmt.Sprintf("%.1f", r.BLEU), fmt.Sprintf("%.1f%%", r.Exact*100), adequacy, acceptable)
+		}
+		fmt.Print("\n" + tbl.String())
+	},
+}
+
+// benchmarkResult holds the scores of the machine translations of a language
+type benchmarkResult struct {
+	Lang string `json:"lang"`
+	// Number of keys translated and scored
+	Keys  int     `json:"keys"`
+	ChrF  float64 `json:"chrf"`
+	BLEU  float64 `json:"bleu"`
+	Exact float64 `json:"exact"`
+	// Average model-graded adequacy from 1 to 5, nil when not graded
+	Adequacy   *float64 `json:"adequacy,omitempty"`
+	Acceptable bool     `json:"acceptable"`
+}
+
+// referenceEntries returns the source texts of the catalogs of ds with their reference
+// translation into lang, read from the catalogs of referenceDir, sorted by key
+func referenceEntries(ds *scanner.DirectoryStructure, referenceDir, lang string) ([]tm.Entry, error) {
+	entries := []tm.Entry{}
+	for _, fileType := range ds.FileTypes {
+		path := ds.Layout.Path(referenceDir, lang, fileType)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		reference := &parser.LocaleFileContent{Path: path}
+		if err := reference.ParseContent(); err != nil {
+			return nil, fmt.Errorf("error reading reference %s: %w", path, err)
+		}
+		source := &parser.LocaleFileContent{Path: ds.Path(ds.SourceLang, fileType)}
+		if err := source.ParseContent(); err != nil {
+			return nil, fmt.Errorf("error reading source %s: %w", source.Path, err)
+		}
+		for k, v := range source.LocaleItemsMap {
+			if ref := reference.LocaleItemsMap[k]; v != "" && ref != "" {
+				entries = append(entries, tm.Entry{Key: fileType + ":" + k, Source: v, Target: ref, Lang: lang})
+			}
+		}
+	}
+	// Sort first so the same seed always picks the same keys
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// benchmarkLanguage translates the source texts of entries into lang and scores the
+// translations against their references
+func benchmarkLanguage(ctx context.Context, gptHandler *gpt.Handler, sourceLang, lang string, entries []tm.Entry, grade bool) benchmarkResult {
+	name, err := langCodeToName(lang)
+	if err != nil {
+		name = lang
+	}
+	target := &parser.LocaleFileContent{Code: lang, Lang: name, LocaleItemsMap: map[string]string{}}
+	ctx = gpt.WithLanguages(ctx, sourceLang, lang)
+
+	result := benchmarkResult{Lang: lang}
+	var corpus benchmark.Corpus
+	graded, totalScore := 0, 0
+	for _, e := range entries {
+		translation, err := translateText(ctx, gptHandler, e.Source, target, processOptions{})
+		if err != nil {
+			fmt.Printf("⚠️ Error translating %s [%s]: %v\n", e.Key, lang, err)
+			continue
+		}
+		corpus.Add(translation, e.Target)
+
+		if !grade {
+			continue
+		}
+		g, err := gptHandler.Adequacy(ctx, e.Source, translation, e.Target, name)
+		if err != nil {
+			fmt.Printf("⚠️ Error grading %s [%s]: %v\n", e.Key, lang, err)
+			continue
+		}
+		graded++
+		totalScore += g.Score
+		if g.Score <= 2 {
+			fmt.Printf("⚠️ %s [%s] scored %d: %s\n   reference: %s\n   machine:   %s\n", e.Key, lang, g.Score, g.Reason, e.Target, translation)
+		}
+	}
+
+	result.Keys = corpus.Segments
+	result.ChrF = corpus.ChrF()
+	result.BLEU = corpus.BLEU()
+	result.Exact = corpus.Exact()
+	if graded > 0 {
+		adequacy := float64(totalScore) / float64(graded)
+		result.Adequacy = &adequacy
+	}
+	return result
+}
+
+func init() {
+	benchmarkCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	benchmarkCmd.Flags().String("reference", "", "Directory of the reference translations, laid out like the catalogs")
+	benchmarkCmd.Flags().String("source", "en", "Source language code (default: en)")
+	benchmarkCmd.Flags().String("config", "", "Path to configuration file")
+	benchmarkCmd.Flags().StringSlice("lang", nil, "Languages to benchmark (default: every target language)")
+	benchmarkCmd.Flags().Int("sample", 50, "Number of reference keys to translate per language (0 for all)")
+	benchmarkCmd.Flags().Int64("seed", 0, "Random seed for sampling (default: current time)")
+	benchmarkCmd.Flags().Bool("grade", true, "Have the model rate the adequacy of each machine translation")
+	benchmarkCmd.Flags().Float64("min-chrf", 60, "chrF from which machine translation of a language counts as acceptable")
+	benchmarkCmd.Flags().Float64("min-adequacy", 4, "Average adequacy from which machine translation of a language counts as acceptable")
+	benchmarkCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	benchmarkCmd.MarkFlagRequired("root")
+	benchmarkCmd.MarkFlagRequired("reference")
+
+	rootCmd.AddCommand(benchmarkCmd)
+}
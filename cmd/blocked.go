@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/blocked"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+)
+
+var retryBlocked bool // Translate again the keys the provider refused in earlier runs
+
+// blockList holds the keys of a target file the provider refused to translate for
+// content policy reasons
+type blockList struct {
+	store   blocked.Store
+	target  *parser.LocaleFileContent
+	refused int
+}
+
+// skipBlocked returns opts leaving out the keys of target the provider refused in
+// earlier runs, unless they are retried, and the block list new refusals are recorded in
+func (o processOptions) skipBlocked(target *parser.LocaleFileContent) (processOptions, *blockList) {
+	store, err := blocked.Load()
+	if err != nil {
+		fmt.Printf("⚠️ Could not read the blocked keys: %v\n", err)
+		store = blocked.Store{}
+	}
+	list := &blockList{store: store, target: target}
+	keys := store.Keys(target.Path)
+	if len(keys) == 0 {
+		return o, list
+	}
+	if retryBlocked {
+		fmt.Printf("🔁 Retrying %d keys of %s refused by the provider\n", len(keys), target.Path)
+		return o, list
+	}
+
+	held := make(map[string]bool, len(o.Held)+len(keys))
+	for k := range o.Held {
+		held[k] = true
+	}
+	skipped := 0
+	for k := range keys {
+		if target.LocaleItemsMap[k] == "" {
+			held[k] = true
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("🚫 Skipping %d keys of %s refused by the provider (translate them by hand, or rerun with --retry-blocked)\n", skipped, target.Path)
+	}
+	o.Held = held
+	return o, list
+}
+
+// record records key as blocked when err is a content policy refusal, and reports
+// whether it was one
+func (b *blockList) record(key, source string, err error) bool {
+	var refusal *gpt.PolicyError
+	if !errors.As(err, &refusal) {
+		return false
+	}
+	b.store.Add(blocked.Entry{File: b.target.Path, Key: key, Lang: b.target.Code, Source: source, Provider: auditProvider, Reason: refusal.Reason, BlockedAt: systemClock{}.Now().UTC()})
+	b.refused++
+	return true
+}
+
+// save forgets the blocked keys translated since, on a retry or by hand, and saves the
+// keys refused in this run
+func (b *blockList) save() {
+	resolved := b.store.Resolve(b.target.Path, b.target.LocaleItemsMap)
+	if resolved == 0 && b.refused == 0 {
+		return
+	}
+	if err := b.store.Save(); err != nil {
+		fmt.Printf("⚠️ Could not save the blocked keys: %v\n", err)
+		return
+	}
+	if b.refused > 0 {
+		fmt.Printf("\n🚫 %d keys of %s were refused by the provider for content policy reasons and will be skipped by later runs\n", b.refused, b.target.Path)
+	}
+}
+
+// translateRefusedBatch translates the texts of a batch the provider refused one at a
+// time, so that only the texts it refuses fail. Texts that fail are logged, recorded as
+// blocked when refused, and reported in failed; the error is only set when the run
+// must stop.
+func translateRefusedBatch(ctx context.Context, gptHandler Translator, keys, texts []string, target *parser.LocaleFileContent, opts processOptions, blocks *blockList) ([]string, map[int]bool, error) {
+	fmt.Printf("\n⚠️ Batch refused by the content policy of the provider, translating its %d keys one at a time\n", len(texts))
+	results := make([]string, len(texts))
+	failed := map[int]bool{}
+	for i, text := range texts {
+		result, err := translateText(opts.withKey(ctx, keys[i]), gptHandler, text, target, opts)
+		if errors.Is(err, gpt.ErrBudgetExhausted) {
+			return nil, nil, err
+		}
+		if err != nil {
+			fmt.Printf("\n⚠️ Error translating key %s: %v\n", keys[i], err)
+			opts.logTranslationError(keys[i], text, target.Lang, err)
+			blocks.record(keys[i], text, err)
+			failed[i] = true
+			continue
+		}
+		results[i] = result
+	}
+	return results, failed, nil
+}
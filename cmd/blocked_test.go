@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/blocked"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlockedKeys tests that the keys of a batch the provider refuses are found one at a
+// time, recorded with the reason, skipped by later runs and translated again on request
+func TestBlockedKeys(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	defer func() { retryBlocked = false }()
+
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"a": "Hello", "b": "Graphic scene", "c": "Save"}}
+	newTarget := func() *parser.LocaleFileContent {
+		return &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "French", LocaleItemsMap: map[string]string{}}
+	}
+	refusal := &gpt.PolicyError{Reason: "violence"}
+	translator := &fakeTranslator{
+		batch: func(ctx context.Context, srcs []string, lang string) ([]string, error) {
+			return nil, refusal
+		},
+		translate: func(ctx context.Context, src, lang string) (string, error) {
+			if src == "Graphic scene" {
+				return "", refusal
+			}
+			return "FR:" + src, nil
+		},
+	}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}}
+
+	target := newTarget()
+	require.NoError(t, batch_process(context.Background(), translator, source, target, nil, 10, opts))
+	assert.Equal(t, "FR:Hello", target.LocaleItemsMap["a"])
+	assert.Equal(t, "FR:Save", target.LocaleItemsMap["c"])
+	store, err := blocked.Load()
+	require.NoError(t, err)
+	require.Len(t, store.Entries(), 1)
+	assert.Equal(t, blocked.Entry{File: "/locales/fr.json", Key: "b", Lang: "fr", Source: "Graphic scene", Reason: "violence", BlockedAt: store.Entries()[0].BlockedAt}, store.Entries()[0])
+
+	// Later runs leave the key out
+	translator.sent = nil
+	require.NoError(t, single_process(context.Background(), translator, source, newTarget(), nil, opts))
+	assert.ElementsMatch(t, []string{"Hello", "Save"}, translator.sent)
+
+	// Retried on request, and forgotten once translated
+	retryBlocked = true
+	translator.translate = func(ctx context.Context, src, lang string) (string, error) { return "FR:" + src, nil }
+	target = newTarget()
+	require.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+	assert.Equal(t, "FR:Graphic scene", target.LocaleItemsMap["b"])
+	store, err = blocked.Load()
+	require.NoError(t, err)
+	assert.Empty(t, store.Entries())
+}
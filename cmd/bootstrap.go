@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap <source-file>",
+	Short: "Create the catalogs and configuration of a project from a single source file and translate it",
+	Long: `Set up a project from a single monolingual source file in one step: copy it into the
+catalog layout under --root, create the catalogs of the target languages, write a
+configuration file for them, and run a first full sync translating every key. Existing
+target catalogs are kept and only their missing keys are translated.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourceFile := args[0]
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		targets, _ := cmd.Flags().GetStringSlice("targets")
+		configPath, _ := cmd.Flags().GetString("config")
+		layoutName, _ := cmd.Flags().GetString("layout")
+		force, _ := cmd.Flags().GetBool("force")
+		noTranslate, _ := cmd.Flags().GetBool("no-translate")
+
+		if len(targets) == 0 {
+			fmt.Println("❌ At least one target language is required (--targets)")
+			os.Exit(1)
+		}
+		for _, lang := range append([]string{sourceLang}, targets...) {
+			if _, err := langCodeToName(lang); err != nil {
+				fmt.Printf("❌ Unknown language code %q: %v\n", lang, err)
+				os.Exit(1)
+			}
+		}
+		layout, err := scanner.ParseLayout(layoutName)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(configPath); err == nil && !force {
+			fmt.Printf("⚠️ Configuration file %s already exists. Use --force to override.\n", configPath)
+			os.Exit(1)
+		}
+
+		if err := os.MkdirAll(rootDir, 0755); err != nil {
+			fmt.Printf("❌ Error creating directory: %v\n", err)
+			os.Exit(1)
+		}
+		release, err := acquireProjectLock(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer release()
+
+		created, err := bootstrapCatalogs(rootDir, sourceFile, sourceLang, targets, layout)
+		if err != nil {
+			fmt.Printf("❌ Error creating catalogs: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range created {
+			fmt.Printf("📁 Created %s\n", path)
+		}
+
+		cfg := config.DefaultConfig()
+		cfg.SourceLang = sourceLang
+		cfg.TargetLangs = targets
+		if layoutName != scanner.LayoutLangDir {
+			cfg.Layout = layoutName
+		}
+		cfg.BatchSize, _ = cmd.Flags().GetInt("batch")
+		if dir := filepath.Dir(configPath); dir != "." && dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				fmt.Printf("❌ Error creating directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := config.SaveConfig(cfg, configPath); err != nil {
+			fmt.Printf("❌ Error saving configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Configuration file created at %s\n", configPath)
+
+		if noTranslate {
+			fmt.Println("💡 Translate the catalogs with:")
+			fmt.Printf("   i18n-cli sync --root=%s --config=%s\n", rootDir, configPath)
+			return
+		}
+		startInvocation(cmd, configPath)
+		runSync(cmd.Context(), rootDir, cfg)
+	},
+}
+
+// bootstrapCatalogs copies sourceFile into the layout of rootDir as the catalog of
+// sourceLang and creates an empty catalog for each target language without one,
+// returning the paths of the files created. The source file must be a valid catalog.
+func bootstrapCatalogs(rootDir, sourceFile, sourceLang string, targets []string, layout scanner.Layout) ([]string, error) {
+	data, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&parser.LocaleFileContent{}).ParseJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid source file %s: %w", sourceFile, err)
+	}
+
+	fileType := layout.FileType(filepath.Base(sourceFile))
+	created := []string{}
+	write := func(path string, data []byte) error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return err
+		}
+		created = append(created, path)
+		return nil
+	}
+
+	sourcePath := layout.Path(rootDir, sourceLang, fileType)
+	if !samePath(sourcePath, sourceFile) {
+		if _, err := os.Stat(sourcePath); err == nil {
+			return nil, fmt.Errorf("%s already exists", sourcePath)
+		}
+		if err := write(sourcePath, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, lang := range targets {
+		path := layout.Path(rootDir, lang, fileType)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := write(path, []byte("{}\n")); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+func init() {
+	bootstrapCmd.Flags().String("root", "./locales", "Root directory of the catalogs to create")
+	bootstrapCmd.Flags().String("source", "en", "Source language code of the source file")
+	bootstrapCmd.Flags().StringSlice("targets", nil, "Target language codes (comma-separated)")
+	bootstrapCmd.Flags().String("config", "i18n-config.json", "Path of the configuration file to write")
+	bootstrapCmd.Flags().String("layout", scanner.LayoutLangDir, "Catalog layout: lang-dir, flat, namespace-dir or a path template such as src/{namespace}/i18n/{lang}.json")
+	bootstrapCmd.Flags().Int("batch", 5, "Number of keys translated per request")
+	bootstrapCmd.Flags().Bool("force", false, "Override an existing configuration file")
+	bootstrapCmd.Flags().Bool("no-translate", false, "Only create the catalogs and the configuration file")
+
+	rootCmd.AddCommand(bootstrapCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBootstrapCatalogs tests that the source file is copied into the layout and that
+// empty catalogs are created for the target languages without one
+func TestBootstrapCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	sourceFile := filepath.Join(dir, "messages.json")
+	require.NoError(t, os.WriteFile(sourceFile, []byte(`{"hello": "Hello"}`), 0644))
+	root := filepath.Join(dir, "locales")
+
+	layout, err := scanner.ParseLayout(scanner.LayoutLangDir)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "de"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "de", "messages.json"), []byte(`{"hello": "Hallo"}`), 0644))
+	created, err := bootstrapCatalogs(root, sourceFile, "en", []string{"de", "fr"}, layout)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "en", "messages.json"), filepath.Join(root, "fr", "messages.json")}, created)
+
+	data, err := os.ReadFile(filepath.Join(root, "de", "messages.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hello": "Hallo"}`, string(data))
+	ds, err := scanner.ScanLayout(root, "en", layout)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"de", "en", "fr"}, ds.Languages)
+
+	// The source catalog isn't overwritten
+	_, err = bootstrapCatalogs(root, sourceFile, "en", []string{"fr"}, layout)
+	assert.Error(t, err)
+
+	flat, err := scanner.ParseLayout(scanner.LayoutFlat)
+	require.NoError(t, err)
+	flatRoot := filepath.Join(dir, "flat")
+	created, err = bootstrapCatalogs(flatRoot, sourceFile, "en", []string{"ja"}, flat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(flatRoot, "en.json"), filepath.Join(flatRoot, "ja.json")}, created)
+
+	// Invalid source files are refused
+	require.NoError(t, os.WriteFile(sourceFile, []byte(`not json`), 0644))
+	_, err = bootstrapCatalogs(filepath.Join(dir, "other"), sourceFile, "en", []string{"fr"}, layout)
+	assert.Error(t, err)
+}
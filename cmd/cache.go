@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/cache"
+	"github.com/pandodao/i18n-cli/internal/state"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var noCache bool // Translate every text anew instead of reusing cached translations
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the per-user data and cache directories",
+}
+
+var cacheDirCmd = &cobra.Command{
+	Use:         "dir",
+	Short:       "Show where i18n-cli keeps its data and caches",
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		cache, err := state.CacheDir()
+		if err != nil {
+			fmt.Printf("❌ Error locating cache directory: %v\n", err)
+			return
+		}
+		project, err := state.ProjectDir()
+		if err != nil {
+			fmt.Printf("❌ Error locating data directory: %v\n", err)
+			return
+		}
+		fmt.Printf("Cache:   %s\n", cache)
+		fmt.Printf("Project: %s\n", project)
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete cached data",
+	Long:  `Delete the per-user cache directory. With --project, the data kept for the project of --root, or of the working directory (request timings, quality history, logs, failed keys), is deleted too.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetBool("project")
+
+		dirs := []string{}
+		cache, err := state.CacheDir()
+		if err != nil {
+			fmt.Printf("❌ Error locating cache directory: %v\n", err)
+			return
+		}
+		dirs = append(dirs, cache)
+
+		if project {
+			dir, err := state.ProjectDir()
+			if err != nil {
+				fmt.Printf("❌ Error locating data directory: %v\n", err)
+				return
+			}
+			dirs = append(dirs, dir)
+			if _, err := os.Stat(state.LegacyDirName); err == nil {
+				dirs = append(dirs, state.LegacyDirName)
+			}
+		}
+
+		for _, dir := range dirs {
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				fmt.Printf("❌ Error removing %s: %v\n", dir, err)
+				continue
+			}
+			fmt.Printf("🗑️ Removed %s\n", dir)
+		}
+		fmt.Println("✅ Cache cleaned")
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:         "stats",
+	Short:       "Show the translations cached per model and language",
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := state.CacheDir()
+		if err != nil {
+			fmt.Printf("❌ Error locating cache directory: %v\n", err)
+			return
+		}
+		stats, err := cache.Stats(dir)
+		if err != nil {
+			fmt.Printf("❌ Error reading the translation cache: %v\n", err)
+			return
+		}
+		if len(stats) == 0 {
+			fmt.Println("No cached translations")
+			return
+		}
+		fmt.Print(cacheStatsReport(stats))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached translations",
+	Long:  `Delete the cached translations, only those of a model or a target language with --model and --lang. Data and other caches are kept; see cache clean to delete everything.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		model, _ := cmd.Flags().GetString("model")
+		lang, _ := cmd.Flags().GetString("lang")
+		dir, err := state.CacheDir()
+		if err != nil {
+			fmt.Printf("❌ Error locating cache directory: %v\n", err)
+			return
+		}
+		removed, err := cache.Clear(dir, model, lang)
+		if err != nil {
+			fmt.Printf("❌ Error clearing the translation cache: %v\n", err)
+			return
+		}
+		fmt.Printf("🗑️ Removed %d cached translations\n", removed)
+	},
+}
+
+// cacheStatsReport returns the table of the translations cached per model and language
+func cacheStatsReport(stats []cache.Stat) string {
+	tbl := table.New("Model", "Language", "Translations", "Size", "Oldest", "Newest")
+	for _, s := range stats {
+		langs := make([]string, 0, len(s.Languages))
+		for lang := range s.Languages {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		counts := make([]string, len(langs))
+		for i, lang := range langs {
+			counts[i] = fmt.Sprintf("%s (%d)", lang, s.Languages[lang])
+		}
+		oldest, newest := "-", "-"
+		if !s.Oldest.IsZero() {
+			oldest, newest = s.Oldest.Format("2006-01-02"), s.Newest.Format("2006-01-02")
+		}
+		tbl.Add(s.Model, strings.Join(counts, ", "), s.Entries, fmt.Sprintf("%.1f KB", float64(s.Bytes)/1024), oldest, newest)
+	}
+	return tbl.String()
+}
+
+// openTranslationCache returns the translation cache of model, nil with --no-cache or
+// when it can't be read
+func openTranslationCache(model string) *cache.Cache {
+	if noCache {
+		return nil
+	}
+	dir, err := state.CacheDir()
+	if err == nil {
+		var c *cache.Cache
+		if c, err = cache.Open(dir, model); err == nil {
+			return c
+		}
+	}
+	fmt.Printf("⚠️ Translation cache disabled: %v\n", err)
+	return nil
+}
+
+// saveTranslationCache writes the translations added to c, and tells how many texts
+// it saved sending
+func saveTranslationCache(c *cache.Cache) {
+	if err := c.Save(); err != nil {
+		fmt.Printf("⚠️ Could not save the translation cache: %v\n", err)
+	}
+	if hits, _ := c.Hits(); hits > 0 {
+		fmt.Printf("\n💾 %d translations reused from the cache\n", hits)
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{cacheDirCmd, cacheCleanCmd} {
+		c.Flags().String("root", "", "Root directory of the catalogs of the project (default: the working directory)")
+	}
+	cacheCleanCmd.Flags().Bool("project", false, "Also delete the data kept for the project")
+	cacheClearCmd.Flags().String("model", "", "Only delete the translations of this model")
+	cacheClearCmd.Flags().String("lang", "", "Only delete the translations into this language code")
+
+	cacheCmd.AddCommand(cacheDirCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
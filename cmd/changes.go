@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+)
+
+// applySourcePatch applies the change feed at patchPath to the source catalog of
+// fileType, e.g. from a CMS, and drops the translations of the changed and removed
+// keys from every target. It returns the source file and the keys to translate, which
+// stay missing for later runs should this one not translate them.
+func applySourcePatch(rootDir string, cfg *config.Config, patchPath, fileType string) (string, map[string]bool, error) {
+	data, err := os.ReadFile(patchPath)
+	if err != nil {
+		return "", nil, err
+	}
+	changes, err := parser.ParseChanges(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("error parsing %s: %w", patchPath, err)
+	}
+
+	ds, err := scanCatalogs(rootDir, cfg.SourceLang, cfg)
+	if err != nil {
+		return "", nil, fmt.Errorf("error scanning directory: %w", err)
+	}
+	fileType, err = patchedFileType(ds, fileType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	applyOutputSettings(cfg)
+	auditProvider = "patch"
+	source := &parser.LocaleFileContent{Code: ds.SourceLang, Lang: ds.SourceLang, Path: ds.Path(ds.SourceLang, fileType)}
+	if err := source.ParseContent(); err != nil {
+		return "", nil, fmt.Errorf("error parsing source file %s: %w", source.Path, err)
+	}
+	set, removed := parser.ApplyChanges(source.LocaleItemsMap, changes)
+	if err := writeLocaleFile(source); err != nil {
+		return "", nil, err
+	}
+	fmt.Printf("📝 Applied %s to %s: %d keys set, %d removed\n", patchPath, source.Path, len(set), len(removed))
+
+	for _, lang := range selectTargetLanguages(ds, cfg) {
+		pair := scanner.FilePair{SourceFile: source.Path, TargetFile: ds.Path(lang, fileType), SourceLang: ds.SourceLang, TargetLang: lang, FileType: fileType}
+		if _, err := os.Stat(pair.TargetFile); err != nil {
+			continue
+		}
+		_, target, err := pair.LoadPair()
+		if err != nil {
+			return "", nil, err
+		}
+		dropped := 0
+		for _, k := range append(set, removed...) {
+			if _, ok := target.LocaleItemsMap[k]; ok {
+				delete(target.LocaleItemsMap, k)
+				dropped++
+			}
+		}
+		if dropped == 0 {
+			continue
+		}
+		if err := writeLocaleFile(target); err != nil {
+			return "", nil, err
+		}
+		fmt.Printf("📝 Dropped %d outdated translations from %s\n", dropped, target.Path)
+	}
+
+	only := make(map[string]bool, len(set))
+	for _, k := range set {
+		only[k] = true
+	}
+	return source.Path, only, nil
+}
+
+// patchedFileType returns the source file type a patch applies to, the only one when
+// none is given
+func patchedFileType(ds *scanner.DirectoryStructure, fileType string) (string, error) {
+	if fileType == "" {
+		if len(ds.FileTypes) != 1 {
+			return "", fmt.Errorf("there are several source files, pick one with --patch-file: %v", ds.FileTypes)
+		}
+		return ds.FileTypes[0], nil
+	}
+	for _, name := range ds.FileTypes {
+		if scanner.SameName(name, fileType) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no source file %s, found: %v", fileType, ds.FileTypes)
+}
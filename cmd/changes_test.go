@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestApplySourcePatch tests that a change feed edits the source catalog and drops
+// the outdated translations, leaving only the changed keys to translate
+func TestApplySourcePatch(t *testing.T) {
+	root := t.TempDir()
+	for lang, content := range map[string]string{
+		"en": `{"home": {"title": "Welcome", "cta": "Buy"}, "legacy": "Old", "footer": "Bye"}`,
+		"fr": `{"home": {"title": "Bienvenue", "cta": "Acheter"}, "legacy": "Vieux", "footer": "Salut"}`,
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, lang), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, lang, "app.json"), []byte(content), 0644))
+	}
+	patch := filepath.Join(root, "changes.json")
+	assert.NoError(t, os.WriteFile(patch, []byte(`[
+		{"op": "replace", "path": "/home/title", "value": "Welcome back"},
+		{"op": "add", "path": "/home/more", "value": "Learn more"},
+		{"op": "remove", "path": "/legacy"}
+	]`), 0644))
+
+	cfg := config.DefaultConfig()
+	sourceFile, only, err := applySourcePatch(root, cfg, patch, "")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "en", "app.json"), sourceFile)
+	assert.Equal(t, map[string]bool{"home/title": true, "home/more": true}, only)
+
+	source := &parser.LocaleFileContent{Path: sourceFile}
+	assert.NoError(t, source.ParseContent())
+	assert.Equal(t, map[string]string{"home/title": "Welcome back", "home/cta": "Buy", "home/more": "Learn more", "footer": "Bye"}, source.LocaleItemsMap)
+	target := &parser.LocaleFileContent{Path: filepath.Join(root, "fr", "app.json")}
+	assert.NoError(t, target.ParseContent())
+	assert.Equal(t, map[string]string{"home/cta": "Acheter", "footer": "Salut"}, target.LocaleItemsMap)
+
+	_, _, err = applySourcePatch(root, cfg, patch, "other.json")
+	assert.Error(t, err)
+}
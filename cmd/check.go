@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Fail when target languages fall below their translation coverage threshold",
+	Long: `Measure the share of the source keys each target language has translated and exit with
+a non-zero status when one falls below its threshold: --min-coverage, or the "coverage"
+section of the configuration file, which may set thresholds per language. With
+--fail-on-missing, target languages lacking a catalog of the source, or configured target
+languages without any catalog, fail as well. Experimental languages are reported but never
+fail the check. Meant for CI pipelines; locale files are never modified.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		minCoverage, _ := cmd.Flags().GetFloat64("min-coverage")
+		failOnMissing, _ := cmd.Flags().GetBool("fail-on-missing")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "text" && format != "json" {
+			fmt.Printf("❌ Unknown format %q, use text or json\n", format)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		gate := config.Coverage{}
+		policy := ""
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			if cfg.Coverage != nil {
+				gate = *cfg.Coverage
+			}
+			policy = cfg.EmptySource
+		}
+		if cmd.Flags().Changed("min-coverage") {
+			gate.Min = minCoverage
+		}
+		gate.FailOnMissing = gate.FailOnMissing || failOnMissing
+		if err := gate.Validate(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		langs := selectTargetLanguages(ds, cfg)
+		if cfg != nil {
+			for _, lang := range cfg.TargetLangs {
+				if !containsLanguage(langs, lang) && !scanner.SameName(lang, ds.SourceLang) {
+					langs = append(langs, lang)
+				}
+			}
+			sort.Strings(langs)
+		}
+
+		results, err := measureCoverage(ds, langs, policy)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		failed := gateCoverage(results, &gate, cfg)
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		} else {
+			fmt.Print(coverageTable(results))
+		}
+
+		if len(failed) > 0 {
+			fmt.Printf("\n❌ Coverage check failed: %s\n", strings.Join(failed, "; "))
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ %d target languages pass the coverage check\n", len(results))
+	},
+}
+
+// languageCoverage is the translation coverage of a target language
+type languageCoverage struct {
+	Lang string `json:"lang"`
+	// Source keys counted under the empty-source policy, and those translated
+	Total      int     `json:"total"`
+	Translated int     `json:"translated"`
+	Percent    float64 `json:"percent"`
+	Threshold  float64 `json:"threshold,omitempty"`
+	// Catalogs of the source the language has no file for
+	MissingFiles []string `json:"missingFiles,omitempty"`
+	Experimental bool     `json:"experimental,omitempty"`
+	Passed       bool     `json:"passed"`
+}
+
+// measureCoverage returns the coverage of the catalogs of ds in each of langs, which may
+// have no catalog at all
+func measureCoverage(ds *scanner.DirectoryStructure, langs []string, policy string) ([]languageCoverage, error) {
+	results := make([]languageCoverage, 0, len(langs))
+	for _, lang := range langs {
+		c := languageCoverage{Lang: lang}
+		for _, fileType := range ds.FileTypes {
+			pair := scanner.FilePair{
+				SourceFile: ds.Path(ds.SourceLang, fileType),
+				TargetFile: ds.Path(lang, fileType),
+				SourceLang: ds.SourceLang,
+				TargetLang: lang,
+				FileType:   fileType,
+			}
+			if _, err := os.Stat(pair.SourceFile); os.IsNotExist(err) {
+				continue
+			}
+			if _, err := os.Stat(pair.TargetFile); os.IsNotExist(err) {
+				c.MissingFiles = append(c.MissingFiles, fileType)
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				return nil, err
+			}
+			total, translated := countKeys(source.LocaleItemsMap, target.LocaleItemsMap, policy)
+			c.Total += total
+			c.Translated += translated
+		}
+		c.Percent = 100
+		if c.Total > 0 {
+			c.Percent = float64(c.Translated) / float64(c.Total) * 100
+		}
+		results = append(results, c)
+	}
+	return results, nil
+}
+
+// gateCoverage sets the threshold of the results and whether they pass it, and returns
+// the failures. Experimental languages of cfg always pass.
+func gateCoverage(results []languageCoverage, gate *config.Coverage, cfg *config.Config) []string {
+	failed := []string{}
+	for i := range results {
+		c := &results[i]
+		c.Threshold = gate.Threshold(c.Lang)
+		c.Experimental = cfg.Experimental(c.Lang)
+		problems := []string{}
+		if c.Threshold > 0 && c.Percent < c.Threshold {
+			problems = append(problems, fmt.Sprintf("%.1f%% covered, below %.1f%%", c.Percent, c.Threshold))
+		}
+		if gate.FailOnMissing && len(c.MissingFiles) > 0 {
+			problems = append(problems, fmt.Sprintf("missing %s", strings.Join(c.MissingFiles, ", ")))
+		}
+		c.Passed = len(problems) == 0 || c.Experimental
+		if !c.Passed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", c.Lang, strings.Join(problems, ", ")))
+		}
+	}
+	return failed
+}
+
+// coverageTable returns the coverage of each language as a table
+func coverageTable(results []languageCoverage) string {
+	tbl := table.New("Language", "Translated", "Total Keys", "Coverage", "Threshold", "Missing Files", "Result")
+	for _, c := range results {
+		threshold := "-"
+		if c.Threshold > 0 {
+			threshold = fmt.Sprintf("%.1f%%", c.Threshold)
+		}
+		result := "✅ pass"
+		switch {
+		case !c.Passed:
+			result = "❌ fail"
+		case c.Experimental:
+			result = "✅ experimental"
+		}
+		tbl.Add(c.Lang, c.Translated, c.Total, fmt.Sprintf("%.1f%%", c.Percent), threshold, strings.Join(c.MissingFiles, ", "), result)
+	}
+	return tbl.String()
+}
+
+func init() {
+	checkCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	checkCmd.Flags().String("source", "en", "Source language code (default: en)")
+	checkCmd.Flags().String("config", "", "Path to configuration file")
+	checkCmd.Flags().Float64("min-coverage", 0, "Percent of the source keys every target language must have translated, overriding coverage.min of the config (0 disables)")
+	checkCmd.Flags().Bool("fail-on-missing", false, "Fail when a target language has no catalog for some source file")
+	checkCmd.Flags().String("format", "text", "Output format: text or json")
+
+	checkCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoverageCheck tests the coverage of target languages, with files or whole
+// languages missing, against the thresholds of the gate
+func TestCoverageCheck(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("en/common.json", `{"a": "A", "b": "B", "c": "C", "d": "D"}`)
+	write("en/legal.json", `{"terms": "Terms"}`)
+	write("fr/common.json", `{"a": "A fr", "b": "B fr", "c": "C fr", "d": "D fr"}`)
+	write("fr/legal.json", `{"terms": "Conditions"}`)
+	write("de/common.json", `{"a": "A de", "b": "B de", "c": "", "d": "D de"}`)
+	write("ko/common.json", `{"a": "A ko"}`)
+
+	ds, err := scanCatalogs(root, "en", nil)
+	require.NoError(t, err)
+	results, err := measureCoverage(ds, []string{"de", "fr", "ja", "ko"}, "")
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, languageCoverage{Lang: "de", Total: 5, Translated: 3, Percent: 60, MissingFiles: []string{"legal.json"}}, results[0])
+	assert.Equal(t, 100.0, results[1].Percent)
+	assert.Equal(t, []string{"common.json", "legal.json"}, results[2].MissingFiles)
+	assert.Equal(t, 0.0, results[2].Percent)
+
+	cfg := &config.Config{ExperimentalLangs: []string{"ko"}}
+	gate := &config.Coverage{Min: 50, Languages: map[string]float64{"ja": 0}}
+	assert.Empty(t, gateCoverage(results, gate, cfg))
+	assert.True(t, results[3].Experimental)
+
+	gate.FailOnMissing = true
+	assert.Equal(t, []string{"de (missing legal.json)", "ja (missing common.json, legal.json)"}, gateCoverage(results, gate, cfg))
+
+	gate = &config.Coverage{Min: 95}
+	assert.Equal(t, []string{"de (60.0% covered, below 95.0%)", "ja (0.0% covered, below 95.0%)"}, gateCoverage(results, gate, cfg))
+	assert.False(t, results[0].Passed)
+	assert.True(t, results[1].Passed)
+	assert.Contains(t, coverageTable(results), "❌ fail")
+
+	assert.Error(t, (&config.Coverage{Min: 120}).Validate())
+}
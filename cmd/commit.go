@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/lock"
+	"github.com/pandodao/i18n-cli/internal/vcs"
+
+	"github.com/spf13/cobra"
+)
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit the changed locale files with a structured message",
+	Long:  `Stage the changes under --root and commit them with a message listing the languages touched, the keys added, updated and removed in each catalog, the run that translated them with its model and cost, and the version of i18n-cli, so the history of every repository documents localization changes the same way. The run is the latest one of the journal unless --run picks another. Use --signoff to add a Signed-off-by trailer, --push to push the branch afterwards and --dry-run to only print the message.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		runFlag, _ := cmd.Flags().GetString("run")
+		signoff, _ := cmd.Flags().GetBool("signoff")
+		push, _ := cmd.Flags().GetBool("push")
+		remoteName, _ := cmd.Flags().GetString("remote")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		repo, err := vcs.Open(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		relRoot, err := repoPath(repo, rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		lockFile := filepath.ToSlash(filepath.Join(relRoot, lock.FileName))
+
+		// The files to commit: those staged, or those that would be with --dry-run
+		var files []string
+		if dryRun {
+			changed, err := repo.ChangedFiles(relRoot)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			for _, f := range changed {
+				if f != lockFile {
+					files = append(files, f)
+				}
+			}
+		} else {
+			if err := repo.Stage(relRoot, lockFile); err != nil {
+				fmt.Printf("❌ Error staging %s: %v\n", rootDir, err)
+				os.Exit(1)
+			}
+			if files, err = repo.StagedFiles(); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			for _, f := range files {
+				if !underPath(f, relRoot) {
+					fmt.Printf("❌ %s is staged but not under %s; commit or unstage it first\n", f, rootDir)
+					os.Exit(1)
+				}
+			}
+		}
+		if len(files) == 0 {
+			fmt.Printf("✅ Nothing to commit under %s\n", rootDir)
+			return
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		langs := map[string]string{}
+		for lang, paths := range ds.LanguageFiles {
+			for _, p := range paths {
+				if rel, err := repoPath(repo, p); err == nil {
+					langs[rel] = lang
+				}
+			}
+		}
+
+		changes := []catalogChange{}
+		for _, f := range files {
+			if !strings.EqualFold(filepath.Ext(f), ".json") {
+				continue
+			}
+			change, err := diffCommitted(repo, f)
+			if err != nil {
+				fmt.Printf("⚠️ Skipping %s in the message: %v\n", f, err)
+				continue
+			}
+			change.Lang = langs[f]
+			if change.Lang == "" {
+				change.Lang = catalogLanguage(f)
+			}
+			changes = append(changes, change)
+		}
+
+		var run *journal.Run
+		if runs, err := journal.Load(); err != nil {
+			fmt.Printf("⚠️ Error reading the run journal: %v\n", err)
+		} else if runFlag != "" {
+			found, err := journal.Find(runs, runFlag)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			run = &found
+		} else if len(runs) > 0 {
+			run = &runs[len(runs)-1]
+		}
+
+		message := commitMessage(changes, sourceLang, run, toolVersion())
+		if dryRun {
+			fmt.Println(message)
+			return
+		}
+
+		commit := repo.Commit
+		if signoff {
+			commit = repo.CommitSignedOff
+		}
+		if err := commit(message); err != nil {
+			fmt.Printf("❌ Error committing: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 Committed %d files: %s\n", len(files), strings.SplitN(message, "\n", 2)[0])
+
+		if !push {
+			return
+		}
+		branch, err := repo.CurrentBranch()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := repo.Push(remoteName, branch); err != nil {
+			fmt.Printf("❌ Error pushing %s: %v\n", branch, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Pushed %s to %s\n", branch, remoteName)
+	},
+}
+
+// catalogChange is what the change of a catalog does to its keys
+type catalogChange struct {
+	// Path relative to the repository root
+	Path    string
+	Lang    string
+	Added   int
+	Updated int
+	Removed int
+}
+
+// diffCommitted compares the catalog at path, relative to the repository root, in the
+// working tree with its last committed version. Files new or deleted since are compared
+// with an empty catalog.
+func diffCommitted(repo *vcs.Repo, path string) (catalogChange, error) {
+	change := catalogChange{Path: path}
+	before, err := repo.FileAt("HEAD", path)
+	if err != nil {
+		before = ""
+	}
+	after, err := os.ReadFile(filepath.Join(repo.Dir, filepath.FromSlash(path)))
+	if err != nil && !os.IsNotExist(err) {
+		return change, err
+	}
+
+	old, err := catalogItems([]byte(before))
+	if err != nil {
+		return change, fmt.Errorf("committed version: %w", err)
+	}
+	current, err := catalogItems(after)
+	if err != nil {
+		return change, err
+	}
+	for key, value := range current {
+		previous, ok := old[key]
+		switch {
+		case !ok:
+			change.Added++
+		case previous != value:
+			change.Updated++
+		}
+	}
+	for key := range old {
+		if _, ok := current[key]; !ok {
+			change.Removed++
+		}
+	}
+	return change, nil
+}
+
+// catalogItems returns the flattened items of the content of a catalog, none when it is empty
+func catalogItems(data []byte) (map[string]string, error) {
+	if strings.TrimSpace(string(data)) == "" {
+		return map[string]string{}, nil
+	}
+	content := parser.LocaleFileContent{LocaleItemsMap: map[string]string{}}
+	if err := content.ParseJSON(data); err != nil {
+		return nil, err
+	}
+	return content.LocaleItemsMap, nil
+}
+
+// catalogLanguage returns the language of a catalog the scan doesn't know, such as a
+// deleted one, from its file or directory name, empty when neither is a language code
+func catalogLanguage(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, code := range []string{name, filepath.Base(filepath.Dir(path))} {
+		if _, err := parser.LangCodeToName(code); err == nil {
+			return code
+		}
+	}
+	return ""
+}
+
+// commitMessage returns the commit message of the changes of catalogs translated by
+// run, which may be nil, with version of i18n-cli
+func commitMessage(changes []catalogChange, sourceLang string, run *journal.Run, version string) string {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Lang != changes[j].Lang {
+			return changes[i].Lang < changes[j].Lang
+		}
+		return changes[i].Path < changes[j].Path
+	})
+
+	targets := []string{}
+	var total catalogChange
+	for _, c := range changes {
+		if c.Lang != "" && c.Lang != sourceLang && !containsString(targets, c.Lang) {
+			targets = append(targets, c.Lang)
+		}
+		total.Added += c.Added
+		total.Updated += c.Updated
+		total.Removed += c.Removed
+	}
+
+	var b strings.Builder
+	switch {
+	case len(targets) == 1:
+		fmt.Fprintf(&b, "i18n: update %s translations\n\n", targets[0])
+	case len(targets) > 1:
+		fmt.Fprintf(&b, "i18n: update translations (%s)\n\n", strings.Join(targets, ", "))
+	case len(changes) > 0:
+		b.WriteString("i18n: update source strings\n\n")
+	default:
+		b.WriteString("i18n: update locale files\n\n")
+	}
+
+	if len(changes) > 0 {
+		if len(targets) > 0 {
+			fmt.Fprintf(&b, "Languages: %s\n", strings.Join(targets, ", "))
+		}
+		fmt.Fprintf(&b, "Keys: %s\n\n", keyCounts(total))
+		for _, c := range changes {
+			lang := c.Lang
+			switch {
+			case lang == "":
+				lang = "unknown language"
+			case lang == sourceLang:
+				lang += ", source"
+			}
+			fmt.Fprintf(&b, "- %s (%s): %s\n", c.Path, lang, keyCounts(c))
+		}
+		b.WriteString("\n")
+	}
+
+	if run != nil {
+		model := run.Model
+		if run.Provider != "" {
+			model = run.Provider + " " + model
+		}
+		fmt.Fprintf(&b, "Run: %s (%s, %s)\n", run.ID, run.Command, strings.TrimSpace(model))
+		if cost, ok := gpt.Cost(run.Model, run.Usage.PromptTokens, run.Usage.CompletionTokens); ok {
+			fmt.Fprintf(&b, "Cost: $%.4f (%d prompt and %d completion tokens)\n", cost, run.Usage.PromptTokens, run.Usage.CompletionTokens)
+		}
+	}
+	fmt.Fprintf(&b, "Tool: i18n-cli %s\n", version)
+	return b.String()
+}
+
+// keyCounts describes the keys added, updated and removed by c
+func keyCounts(c catalogChange) string {
+	parts := []string{}
+	for _, n := range []struct {
+		count int
+		what  string
+	}{{c.Added, "added"}, {c.Updated, "updated"}, {c.Removed, "removed"}} {
+		if n.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n.count, n.what))
+		}
+	}
+	if len(parts) == 0 {
+		return "no key changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// underPath reports whether path, relative to the repository root, is dir or under it
+func underPath(path, dir string) bool {
+	return dir == "." || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// toolVersion returns the version of i18n-cli as built, "devel" for local builds
+func toolVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+func init() {
+	commitCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	commitCmd.Flags().String("source", "en", "Source language code (default: en)")
+	commitCmd.Flags().String("config", "", "Path to configuration file")
+	commitCmd.Flags().String("run", "", "Run the changes come from, as shown by 'runs list' (default: the latest run)")
+	commitCmd.Flags().Bool("signoff", false, "Add a Signed-off-by trailer of the git user")
+	commitCmd.Flags().Bool("push", false, "Push the current branch after committing")
+	commitCmd.Flags().String("remote", "origin", "Remote to push to")
+	commitCmd.Flags().Bool("dry-run", false, "Print the commit message without staging or committing")
+
+	commitCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(commitCmd)
+}
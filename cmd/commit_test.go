@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommitMessage tests the subject, key counts, run and cost of commit messages
+func TestCommitMessage(t *testing.T) {
+	changes := []catalogChange{
+		{Path: "locales/fr/common.json", Lang: "fr", Added: 2, Updated: 1},
+		{Path: "locales/en/common.json", Lang: "en", Added: 2},
+		{Path: "locales/de/common.json", Lang: "de", Added: 2, Removed: 1},
+	}
+	run := &journal.Run{ID: "20240501-abcd", Command: "sync", Provider: "openai", Model: "gpt-4o-mini", Usage: gpt.Usage{PromptTokens: 1000000, CompletionTokens: 500000}}
+
+	assert.Equal(t, `i18n: update translations (de, fr)
+
+Languages: de, fr
+Keys: 6 added, 1 updated, 1 removed
+
+- locales/de/common.json (de): 2 added, 1 removed
+- locales/en/common.json (en, source): 2 added
+- locales/fr/common.json (fr): 2 added, 1 updated
+
+Run: 20240501-abcd (sync, openai gpt-4o-mini)
+Cost: $0.4500 (1000000 prompt and 500000 completion tokens)
+Tool: i18n-cli v1.2.0
+`, commitMessage(changes, "en", run, "v1.2.0"))
+
+	assert.Equal(t, `i18n: update fr translations
+
+Languages: fr
+Keys: no key changes
+
+- locales/fr.json (fr): no key changes
+
+Tool: i18n-cli devel
+`, commitMessage([]catalogChange{{Path: "locales/fr.json", Lang: "fr"}}, "en", nil, "devel"))
+}
+
+// TestDiffCommitted tests that catalogs are compared with their committed version,
+// new and deleted ones with an empty catalog
+func TestDiffCommitted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = dir
+		require.NoError(t, git.Run())
+	}
+	repo := &vcs.Repo{Dir: dir}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("fr.json", `{"a": "A", "b": "B", "c": "C"}`)
+	write("de.json", `{"a": "A"}`)
+	require.NoError(t, repo.Stage("."))
+	require.NoError(t, repo.Commit("initial"))
+
+	write("fr.json", `{"a": "A", "b": "B2", "d": {"e": "E"}}`)
+	write("es.json", `{"a": "A"}`)
+	require.NoError(t, os.Remove(filepath.Join(dir, "de.json")))
+
+	change, err := diffCommitted(repo, "fr.json")
+	require.NoError(t, err)
+	assert.Equal(t, catalogChange{Path: "fr.json", Added: 1, Updated: 1, Removed: 1}, change)
+
+	change, err = diffCommitted(repo, "es.json")
+	require.NoError(t, err)
+	assert.Equal(t, catalogChange{Path: "es.json", Added: 1}, change)
+
+	change, err = diffCommitted(repo, "de.json")
+	require.NoError(t, err)
+	assert.Equal(t, catalogChange{Path: "de.json", Removed: 1}, change)
+
+	assert.Equal(t, "de", catalogLanguage("locales/de.json"))
+	assert.Equal(t, "pt-BR", catalogLanguage("locales/pt-BR/common.json"))
+	assert.Equal(t, "", catalogLanguage("locales/strings/common.json"))
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/affix"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/dnt"
+	"github.com/pandodao/i18n-cli/internal/glossary"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/templating"
+	"github.com/spf13/cobra"
+)
+
+// loadOptionalConfig loads the configuration file given by the --config flag, if any.
+// A missing file is not an error, nil is returned instead.
+func loadOptionalConfig(cmd *cobra.Command) (*config.Config, error) {
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		return nil, nil
+	}
+
+	fmt.Printf("📝 Loading configuration from %s\n", configPath)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		if _, statErr := os.Stat(configPath); os.IsNotExist(statErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// scanCatalogs scans rootDir for catalogs in the layout configured in cfg
+func scanCatalogs(rootDir, sourceLang string, cfg *config.Config) (*scanner.DirectoryStructure, error) {
+	name := ""
+	if cfg != nil {
+		name = cfg.Layout
+	}
+	layout, err := scanner.ParseLayout(name)
+	if err != nil {
+		return nil, err
+	}
+	return scanner.ScanLayout(rootDir, sourceLang, layout)
+}
+
+// loadGlossary reads the glossary of the configuration, nil when there is none
+func loadGlossary(cfg *config.Config) (glossary.Glossary, error) {
+	if cfg == nil || cfg.Glossary == "" {
+		return nil, nil
+	}
+	return glossary.Load(cfg.Glossary)
+}
+
+// loadValues reads the values file of the configuration, nil outside templating mode
+func loadValues(cfg *config.Config) (templating.Values, error) {
+	if cfg == nil || cfg.Values == "" {
+		return nil, nil
+	}
+	return templating.Load(cfg.Values)
+}
+
+// selectTargetLanguages returns the sorted target languages of a directory structure,
+// restricted to the configured target languages when there are any
+func selectTargetLanguages(ds *scanner.DirectoryStructure, cfg *config.Config) []string {
+	targetLanguages := []string{}
+	for _, lang := range ds.Languages {
+		if lang == ds.SourceLang {
+			continue
+		}
+		if cfg != nil && len(cfg.TargetLangs) > 0 && !containsLanguage(cfg.TargetLangs, lang) {
+			continue
+		}
+		targetLanguages = append(targetLanguages, lang)
+	}
+	sort.Strings(targetLanguages)
+	return targetLanguages
+}
+
+// containsLanguage reports whether a language directory is in a list of languages,
+// ignoring case as Windows and macOS do
+func containsLanguage(list []string, lang string) bool {
+	for _, item := range list {
+		if scanner.SameName(item, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// needsAPIKeys reports whether the configured provider needs API keys: a local
+// Ollama server doesn't, and the keys of LibreTranslate and webhooks are optional
+func needsAPIKeys(cfg *config.Config) bool {
+	return cfg == nil || cfg.Provider == "" || cfg.Provider == gpt.ProviderOpenAI || cfg.Provider == gpt.ProviderGemini
+}
+
+// apiKeyEnv returns the environment variable holding the API keys of the configured provider
+func apiKeyEnv(cfg *config.Config) string {
+	if cfg != nil && cfg.Provider == gpt.ProviderGemini {
+		return "GEMINI_API_KEY"
+	}
+	return "OPENAI_API_KEY"
+}
+
+// configuredModel returns the model set in the settings of the configured provider,
+// empty for its default
+func configuredModel(cfg *config.Config) string {
+	switch {
+	case cfg == nil:
+		return ""
+	case (cfg.Provider == "" || cfg.Provider == gpt.ProviderOpenAI) && cfg.OpenAI != nil:
+		return cfg.OpenAI.Model
+	case cfg.Provider == gpt.ProviderGemini && cfg.Gemini != nil:
+		return cfg.Gemini.Model
+	case cfg.Provider == gpt.ProviderOllama && cfg.Ollama != nil:
+		return cfg.Ollama.Model
+	}
+	return ""
+}
+
+// translationModel returns the model the configured provider translates with
+func translationModel(cfg *config.Config) string {
+	if cfg == nil {
+		return gpt.DefaultModel
+	}
+	return gpt.ModelFor(cfg.Provider, configuredModel(cfg))
+}
+
+// newGPTHandler creates the GPT handler for the given keys, applying the TLS settings of the config
+func newGPTHandler(cfg *config.Config, apiKeys []string, timeout time.Duration) (*gpt.Handler, error) {
+	if readOnly {
+		return nil, fmt.Errorf("provider calls are %w", errReadOnly)
+	}
+	gptCfg := gpt.Config{
+		Keys:    apiKeys,
+		Timeout: timeout,
+		Safe:    safeMode,
+
+		Seed:          seed,
+		Deterministic: deterministic,
+
+		MaxTokens:    maxTokens,
+		BatchTokens:  batchTokens,
+		SystemPrompt: systemPrompt,
+		ErrorBudget:  config.DefaultErrorBudget(),
+		MaxCost:      maxCost,
+		Affixes:      &affix.Settings{},
+	}
+	if maxCost < 0 {
+		return nil, fmt.Errorf("max cost must not be negative, got %v", maxCost)
+	}
+	if err := gpt.ValidatePrompt(systemPrompt); err != nil {
+		return nil, err
+	}
+	if temperature != nil {
+		if *temperature < 0 || *temperature > 2 {
+			return nil, fmt.Errorf("temperature must be between 0 and 2, got %v", *temperature)
+		}
+		t := float32(*temperature)
+		gptCfg.Temperature = &t
+	}
+
+	if cfg != nil {
+		gptCfg.Provider = cfg.Provider
+		gptCfg.Model = configuredModel(cfg)
+		if cfg.Provider == gpt.ProviderOllama && cfg.Ollama != nil {
+			gptCfg.Endpoint = cfg.Ollama.Endpoint
+		}
+		if cfg.Provider == gpt.ProviderLibreTranslate {
+			gptCfg.Keys = nil
+			key := os.Getenv("LIBRETRANSLATE_API_KEY")
+			if cfg.LibreTranslate != nil {
+				gptCfg.Endpoint = cfg.LibreTranslate.Endpoint
+				if key == "" {
+					key = cfg.LibreTranslate.APIKey
+				}
+			}
+			if key != "" {
+				gptCfg.Keys = []string{key}
+			}
+		}
+		if cfg.Provider == gpt.ProviderWebhook {
+			gptCfg.Keys = nil
+			key := os.Getenv("WEBHOOK_API_KEY")
+			if cfg.Webhook != nil {
+				gptCfg.Endpoint = cfg.Webhook.URL
+				gptCfg.Headers = cfg.Webhook.Headers
+				if key == "" {
+					key = cfg.Webhook.APIKey
+				}
+			}
+			if key != "" {
+				gptCfg.Keys = []string{key}
+			}
+		}
+		if cfg.CABundle != "" {
+			pool, err := gpt.LoadCertPool(cfg.CABundle)
+			if err != nil {
+				return nil, fmt.Errorf("loading CA bundle: %w", err)
+			}
+			gptCfg.RootCAs = pool
+		}
+		if cfg.Proxy != "" {
+			proxy, err := gpt.ParseProxy(cfg.Proxy)
+			if err != nil {
+				return nil, err
+			}
+			gptCfg.Proxy = proxy
+			gptCfg.NoProxy = cfg.NoProxy
+		}
+		gptCfg.InsecureSkipVerify = cfg.InsecureSkipVerify
+		gptCfg.Secrets = cfg.Secrets
+		gptCfg.KeyContext = cfg.KeyContext
+		if cfg.ErrorBudget != nil {
+			gptCfg.ErrorBudget = *cfg.ErrorBudget
+		}
+		retry, err := cfg.Retry.Settings()
+		if err != nil {
+			return nil, err
+		}
+		gptCfg.Retry = retry
+		if cfg.DoNotTranslate != nil {
+			protect, err := dnt.Compile(*cfg.DoNotTranslate)
+			if err != nil {
+				return nil, err
+			}
+			gptCfg.Protect = protect
+		}
+		if cfg.Affixes != nil {
+			gptCfg.Affixes = cfg.Affixes
+		}
+		gptCfg.Registers = cfg.Registers()
+	}
+	// Template variables are hidden from providers like protected tokens
+	if templates != nil {
+		list := dnt.List{}
+		if cfg != nil && cfg.DoNotTranslate != nil {
+			list = *cfg.DoNotTranslate
+		}
+		list.Patterns = append(append([]string{}, list.Patterns...), templating.Pattern.String())
+		protect, err := dnt.Compile(list)
+		if err != nil {
+			return nil, err
+		}
+		gptCfg.Protect = protect
+	}
+	// The keys never translated and the results of batch jobs are handled here
+	protected = gptCfg.Protect
+
+	if politeMode || cfg != nil && cfg.Polite != nil {
+		var settings *config.Polite
+		if cfg != nil {
+			settings = cfg.Polite
+		}
+		polite, err := settings.Settings()
+		if err != nil {
+			return nil, err
+		}
+		gptCfg.Polite = &polite
+	}
+
+	h := gpt.New(gptCfg)
+	if _, ok := gpt.Cost(h.Model(), 0, 0); maxCost > 0 && !ok {
+		fmt.Printf("⚠️ No known price for %s, the cost limit of $%.2f can't be enforced\n", h.Model(), maxCost)
+	}
+	return h, nil
+}
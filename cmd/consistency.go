@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/audit"
+	"github.com/pandodao/i18n-cli/internal/consistency"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// manualProviders are the audit trail providers of commands editing catalogs by hand
+var manualProviders = []string{"mark", "unmark", "harmonize", "review"}
+
+var consistencyCmd = &cobra.Command{
+	Use:         "consistency",
+	Short:       "Report source strings translated differently across files",
+	Long:        `Find identical source strings that received different translations within a language, across all its files. Translations are ranked by how many of their keys were reviewed (written or edited by hand rather than by a provider, according to the change history), then by how many keys use them. With --harmonize, every key is set to the top-ranked translation.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		harmonize, _ := cmd.Flags().GetBool("harmonize")
+		format, _ := cmd.Flags().GetString("format")
+		if harmonize && readOnly {
+			fmt.Printf("❌ --harmonize is %v\n", errReadOnly)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+		if harmonize {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		results := map[string][]consistency.Group{}
+		total := 0
+		for _, lang := range targets {
+			catalogs, groups, err := findInconsistencies(pairs, lang)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			if len(groups) == 0 {
+				continue
+			}
+			results[lang] = groups
+			total += len(groups)
+
+			if harmonize {
+				if err := harmonizeCatalogs(catalogs, groups); err != nil {
+					fmt.Printf("❌ Error harmonizing %s: %v\n", lang, err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		for _, lang := range targets {
+			groups, ok := results[lang]
+			if !ok {
+				continue
+			}
+			fmt.Printf("🌐 %s\n", lang)
+			for _, g := range groups {
+				fmt.Printf("  %q is translated %d ways:\n", g.Source, len(g.Variants))
+				for i, v := range g.Variants {
+					mark := " "
+					if i == 0 {
+						mark = "✔"
+					}
+					keys := make([]string, len(v.Items))
+					for j, item := range v.Items {
+						keys[j] = item.File + " " + item.Key
+					}
+					fmt.Printf("    %s %q (%d keys, %d reviewed): %s\n", mark, v.Target, len(v.Items), v.Reviewed, strings.Join(keys, ", "))
+				}
+			}
+		}
+		switch {
+		case total == 0:
+			fmt.Println("✅ Shared source strings are translated consistently")
+		case harmonize:
+			fmt.Printf("✅ Harmonized %d source strings to their most reviewed translation\n", total)
+		default:
+			fmt.Printf("\n⚠️ Found %d source strings with diverging translations, rerun with --harmonize to apply the ✔ ones\n", total)
+		}
+	},
+}
+
+// findInconsistencies loads the catalogs of lang and returns them by path, with the
+// source strings they translate differently
+func findInconsistencies(pairs []scanner.FilePair, lang string) (map[string]*parser.LocaleFileContent, []consistency.Group, error) {
+	catalogs := map[string]*parser.LocaleFileContent{}
+	items := []consistency.Item{}
+	for _, pair := range pairs {
+		if !scanner.SameName(pair.TargetLang, lang) {
+			continue
+		}
+		if _, err := os.Stat(pair.TargetFile); os.IsNotExist(err) {
+			continue
+		}
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading pair: %w", err)
+		}
+		reviewed, err := reviewedKeys(target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading the history of %s: %w", target.Path, err)
+		}
+		catalogs[target.Path] = target
+		for k, v := range target.LocaleItemsMap {
+			items = append(items, consistency.Item{File: target.Path, Key: k, Source: source.LocaleItemsMap[k], Target: v, Reviewed: reviewed[k]})
+		}
+	}
+	return catalogs, consistency.Find(items), nil
+}
+
+// reviewedKeys returns the keys of target whose translation was not written by a
+// provider according to its change log: the last value a provider wrote was edited
+// since, or no provider wrote one
+func reviewedKeys(target *parser.LocaleFileContent) (map[string]bool, error) {
+	entries, err := audit.Load(audit.LogPath(target.Path))
+	if err != nil {
+		return nil, err
+	}
+	machine := map[string]string{}
+	for _, e := range entries {
+		if e.Provider != "" && !containsString(manualProviders, e.Provider) {
+			machine[e.Key] = e.New
+		}
+	}
+
+	reviewed := map[string]bool{}
+	for k, v := range target.LocaleItemsMap {
+		written, ok := machine[k]
+		reviewed[k] = !ok || written != v
+	}
+	return reviewed, nil
+}
+
+// harmonizeCatalogs sets every key of groups to the preferred translation of its group
+// and writes the catalogs that changed
+func harmonizeCatalogs(catalogs map[string]*parser.LocaleFileContent, groups []consistency.Group) error {
+	changed := map[string]bool{}
+	for _, g := range groups {
+		preferred := g.Preferred().Target
+		for _, v := range g.Variants[1:] {
+			for _, item := range v.Items {
+				catalogs[item.File].LocaleItemsMap[item.Key] = preferred
+				changed[item.File] = true
+			}
+		}
+	}
+
+	auditProvider = "harmonize"
+	for _, path := range orderedKeys(boolsToStrings(changed), nil) {
+		if err := writeLocaleFile(catalogs[path]); err != nil {
+			return err
+		}
+		fmt.Printf("📝 Harmonized %s\n", path)
+	}
+	return nil
+}
+
+// boolsToStrings returns the keys of set as a map usable with orderedKeys
+func boolsToStrings(set map[string]bool) map[string]string {
+	result := make(map[string]string, len(set))
+	for k := range set {
+		result[k] = ""
+	}
+	return result
+}
+
+func init() {
+	consistencyCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	consistencyCmd.Flags().String("source", "en", "Source language code (default: en)")
+	consistencyCmd.Flags().String("config", "", "Path to configuration file")
+	consistencyCmd.Flags().StringSlice("lang", nil, "Languages to check (default: every target language)")
+	consistencyCmd.Flags().Bool("harmonize", false, "Set every key to the most reviewed translation of its source string")
+	consistencyCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	rootCmd.AddCommand(consistencyCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/spf13/cobra"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert catalogs between flat and nested keys",
+	Long:  `Rewrite the catalogs of every language between flat keys ({"home.title": "Home"}) and nested objects ({"home": {"title": "Home"}}), keeping every value as it is. Every catalog is converted before any is written, so a conflict such as "home" being both a text and the parent of "home.title" leaves the catalogs untouched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		style, _ := cmd.Flags().GetString("to")
+		separator, _ := cmd.Flags().GetString("separator")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if rootDir == "" {
+			fmt.Println("❌ --root is required")
+			os.Exit(1)
+		}
+		if style != parser.StyleFlat && style != parser.StyleNested {
+			fmt.Printf("❌ --to must be one of %s\n", strings.Join(parser.Styles, ", "))
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !dryRun {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		// Convert every catalog first, so that a conflict in one doesn't leave the
+		// languages in different styles
+		converted := map[string][]byte{}
+		paths := []string{}
+		failed := false
+		for _, lang := range ds.Languages {
+			for _, fileType := range ds.FileTypes {
+				path := ds.Path(lang, fileType)
+				data, err := os.ReadFile(path)
+				if os.IsNotExist(err) {
+					continue
+				}
+				if err != nil {
+					fmt.Printf("❌ Error reading %s: %v\n", path, err)
+					os.Exit(1)
+				}
+				result, err := parser.Convert(data, style, separator)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", path, err)
+					failed = true
+					continue
+				}
+				if !bytes.Equal(bytes.TrimSpace(data), bytes.TrimSpace(result)) {
+					converted[path] = result
+					paths = append(paths, path)
+				}
+			}
+		}
+		if failed {
+			fmt.Println("❌ No catalog was converted, fix the conflicts first")
+			os.Exit(1)
+		}
+
+		for _, path := range paths {
+			if dryRun {
+				fmt.Printf("📝 Would convert %s\n", path)
+				continue
+			}
+			if err := writeFileAtomic(path, converted[path]); err != nil {
+				fmt.Printf("❌ Error writing %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Converted %s\n", path)
+		}
+
+		switch {
+		case len(paths) == 0:
+			fmt.Printf("✅ Every catalog already has %s keys\n", style)
+		case dryRun:
+			fmt.Printf("✅ %d catalogs would be converted to %s keys\n", len(paths), style)
+		default:
+			fmt.Printf("✅ Converted %d catalogs to %s keys\n", len(paths), style)
+		}
+	},
+}
+
+func init() {
+	convertCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	convertCmd.Flags().String("source", "en", "Source language code (default: en)")
+	convertCmd.Flags().String("config", "", "Path to configuration file")
+	convertCmd.Flags().String("to", "", "Key style to convert to: 'flat' or 'nested'")
+	convertCmd.Flags().String("separator", ".", "Separator between the segments of flat keys")
+	convertCmd.Flags().Bool("dry-run", false, "List the catalogs that would change without writing them")
+
+	rootCmd.AddCommand(convertCmd)
+}
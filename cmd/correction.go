@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pandodao/i18n-cli/internal/icu"
+	"github.com/pandodao/i18n-cli/internal/markup"
+	"github.com/pandodao/i18n-cli/internal/placeholder"
+	"github.com/pandodao/i18n-cli/internal/table"
+)
+
+// Kinds of validation failures
+const (
+	violationPlaceholders = "placeholders"
+	violationMarkup       = "markup"
+	violationLength       = "length"
+	violationGlossary     = "glossary"
+	violationGeography    = "geography"
+	violationRegister     = "register"
+	violationScript       = "script"
+	violationCommentary   = "commentary"
+	violationTemplate     = "template"
+	violationSyntax       = "syntax"
+)
+
+// validationError is a translation failing one of the checks of checkTranslation
+type validationError struct {
+	Kind string
+	Err  error
+}
+
+func (e *validationError) Error() string { return e.Err.Error() }
+
+func (e *validationError) Unwrap() error { return e.Err }
+
+// violationKind returns the kind of the check err failed, "other" for other errors
+func violationKind(err error) string {
+	var v *validationError
+	if errors.As(err, &v) {
+		return v.Kind
+	}
+	return "other"
+}
+
+// correctionFor returns what to tell the model to fix a translation failing validation
+// with err, quoting the violation
+func correctionFor(err error) string {
+	var args *icu.ArgumentsError
+	if errors.As(err, &args) {
+		return placeholderCorrection(args.Missing, args.Unexpected)
+	}
+	var placeholders *placeholder.Error
+	if errors.As(err, &placeholders) {
+		return placeholderCorrection(placeholders.Missing, placeholders.Unexpected)
+	}
+	var length *lengthError
+	if errors.As(err, &length) {
+		return fmt.Sprintf("Your translation has %d characters, over the limit of %d. Shorten it to %d characters at most, with shorter words or the abbreviations usual in the target language, keeping its meaning and placeholders.", length.Length, length.Limit, length.Limit)
+	}
+	var tags *markup.Error
+	if errors.As(err, &tags) {
+		return markupCorrection(tags)
+	}
+
+	switch violationKind(err) {
+	case violationPlaceholders:
+		return fmt.Sprintf("%s. Keep the braces and the plural and select syntax of the source exactly as written.", capitalize(err.Error()))
+	case violationGlossary:
+		return fmt.Sprintf("You didn't follow the terminology: %s.", err)
+	case violationGeography:
+		return fmt.Sprintf("%s. Render every city, region and country of the source that way.", capitalize(err.Error()))
+	case violationRegister:
+		return fmt.Sprintf("You used the wrong register: %s.", err)
+	case violationScript:
+		return fmt.Sprintf("%s. Write the whole translation in that script.", capitalize(err.Error()))
+	case violationTemplate:
+		return fmt.Sprintf("%s. Keep every {{variable}} of the source exactly as written.", capitalize(err.Error()))
+	case violationSyntax:
+		return fmt.Sprintf("Your translation %s. Escape literal characters as the framework requires and keep its syntax exactly as in the source.", err)
+	case violationCommentary:
+		return fmt.Sprintf("%s. Reply with the translated text only, without any comment, note or explanation.", capitalize(err.Error()))
+	}
+	return capitalize(err.Error()) + "."
+}
+
+// placeholderCorrection returns what to tell the model to fix a translation that lost
+// the missing placeholders of its source, or has unexpected ones
+func placeholderCorrection(missing, unexpected []string) string {
+	parts := []string{}
+	if len(missing) > 0 {
+		parts = append(parts, fmt.Sprintf("You removed %s.", strings.Join(missing, " ")))
+	}
+	if len(unexpected) > 0 {
+		parts = append(parts, fmt.Sprintf("You added %s, which the source doesn't have.", strings.Join(unexpected, " ")))
+	}
+	return strings.Join(parts, " ") + " Keep every placeholder of the source exactly as written, translating only the text around it."
+}
+
+// markupCorrection returns what to tell the model to fix a translation whose HTML tags or
+// Markdown constructs differ from those of its source
+func markupCorrection(err *markup.Error) string {
+	if err.Misnested {
+		return "You changed the nesting of the HTML tags of the source. Close each tag after those opened inside it, as in the source."
+	}
+	parts := []string{}
+	if len(err.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("You removed or altered %s.", strings.Join(err.Missing, " ")))
+	}
+	if len(err.Unexpected) > 0 {
+		parts = append(parts, fmt.Sprintf("You added %s, which the source doesn't have.", strings.Join(err.Unexpected, " ")))
+	}
+	return strings.Join(parts, " ") + " Keep every HTML tag, link target, inline code span and bold marker of the source exactly as written, translating only the text inside and around them."
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// correctionStats counts the translations retried with a corrective prompt, per
+// language and kind of violation, and how many the retries fixed
+type correctionStats struct {
+	mu     sync.Mutex
+	counts map[string]map[string]*correctionCount
+}
+
+type correctionCount struct {
+	Retried int
+	Fixed   int
+}
+
+// corrections are the corrective retries of this run
+var corrections = &correctionStats{}
+
+// record counts a translation into lang retried for a violation of kind, fixed or not
+func (s *correctionStats) record(lang, kind string, fixed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = map[string]map[string]*correctionCount{}
+	}
+	if s.counts[lang] == nil {
+		s.counts[lang] = map[string]*correctionCount{}
+	}
+	c := s.counts[lang][kind]
+	if c == nil {
+		c = &correctionCount{}
+		s.counts[lang][kind] = c
+	}
+	c.Retried++
+	if fixed {
+		c.Fixed++
+	}
+}
+
+// report returns the table of the fix rates per language and violation, empty when no
+// translation was retried
+func (s *correctionStats) report() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.counts) == 0 {
+		return ""
+	}
+	langs := make([]string, 0, len(s.counts))
+	for lang := range s.counts {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	tbl := table.New("Language", "Violation", "Retried", "Fixed", "Fix Rate")
+	for _, lang := range langs {
+		kinds := make([]string, 0, len(s.counts[lang]))
+		for kind := range s.counts[lang] {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			c := s.counts[lang][kind]
+			tbl.Add(lang, kind, c.Retried, c.Fixed, fmt.Sprintf("%.0f%%", float64(c.Fixed)/float64(c.Retried)*100))
+		}
+	}
+	return tbl.String()
+}
+
+// printCorrectionReport prints the fix rates of the corrective retries of the run, if any
+func printCorrectionReport() {
+	if report := corrections.report(); report != "" {
+		fmt.Print("\n🩹 Corrective retries:\n" + report)
+	}
+}
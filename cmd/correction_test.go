@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/escaping"
+	"github.com/pandodao/i18n-cli/internal/geo"
+	"github.com/pandodao/i18n-cli/internal/icu"
+	"github.com/pandodao/i18n-cli/internal/templating"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCorrectionFor tests that corrections quote the violation of the translation
+func TestCorrectionFor(t *testing.T) {
+	err := &validationError{Kind: violationPlaceholders, Err: icu.Check("{count} files", "{nombre} fichiers")}
+	assert.Equal(t, "You removed {count}. You added {nombre}, which the source doesn't have. Keep every placeholder of the source exactly as written, translating only the text around it.", correctionFor(err))
+
+	err = &validationError{Kind: violationGlossary, Err: fmt.Errorf(`glossary term "Cart" must be translated as "Panier"`)}
+	assert.Equal(t, `You didn't follow the terminology: glossary term "Cart" must be translated as "Panier".`, correctionFor(err))
+
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French", LocaleItemsMap: map[string]string{}}
+	violation := checkTranslation("Hello %s, you have %d messages", "Bonjour, vous avez %d messages", target)
+	assert.Equal(t, violationPlaceholders, violationKind(violation))
+	assert.Equal(t, "You removed %s. Keep every placeholder of the source exactly as written, translating only the text around it.", correctionFor(violation))
+
+	violation = checkTranslation("Read the <a href=\"/terms\">terms</a>", "Lisez les conditions", target)
+	assert.Equal(t, violationMarkup, violationKind(violation))
+	assert.Equal(t, "You removed or altered </a> <a href=/terms>. Keep every HTML tag, link target, inline code span and bold marker of the source exactly as written, translating only the text inside and around them.", correctionFor(violation))
+
+	geoNames = &geo.Settings{Policy: geo.Localize}
+	defer func() { geoNames = nil }()
+	violation = checkTranslation("Shipping from Munich", "Expédié depuis München", &parser.LocaleFileContent{Code: "es", Lang: "Spanish", LocaleItemsMap: map[string]string{}})
+	assert.Equal(t, violationGeography, violationKind(violation))
+	assert.Equal(t, "Geographic names changed in translation: render Munich as Múnich. Render every city, region and country of the source that way.", correctionFor(violation))
+
+	assert.Equal(t, "Received empty translation.", correctionFor(fmt.Errorf("received empty translation")))
+}
+
+// TestCorrectText tests that translations failing validation are retried with their
+// violation and that fixes are counted per language and violation
+func TestCorrectText(t *testing.T) {
+	defer func(c *correctionStats) { corrections = c }(corrections)
+	corrections = &correctionStats{}
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French", LocaleItemsMap: map[string]string{}}
+
+	answers := []string{"{nombre} fichiers", "{count} fichiers"}
+	h := &fakeTranslator{translate: func(ctx context.Context, src, lang string) (string, error) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}}
+	result, err := translateText(context.Background(), h, "{count} files", target, processOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{count} fichiers", result)
+
+	// Never fixed
+	h.translate = func(ctx context.Context, src, lang string) (string, error) { return "{n} fichiers", nil }
+	_, err = correctText(context.Background(), h, "{count} files", target, processOptions{}, "{x} fichiers", checkTranslation("{count} files", "{x} fichiers", target))
+	assert.Error(t, err)
+
+	assert.Equal(t, correctionCount{Retried: 2, Fixed: 1}, *corrections.counts["fr"][violationPlaceholders])
+	assert.Contains(t, corrections.report(), "50%")
+}
+
+// TestCheckTemplates tests that translations are validated with their template variables
+// resolved in templating mode, and must keep them
+func TestCheckTemplates(t *testing.T) {
+	defer func(v templating.Values) { templates = v }(templates)
+	templates = templating.Values{"supportEmail": "help@acme.com"}
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French", LocaleItemsMap: map[string]string{}}
+
+	assert.NoError(t, checkTranslation("Write to {{supportEmail}} about {count} files", "Écrivez à {{supportEmail}} au sujet de {count} fichiers", target))
+	err := checkTranslation("Write to {{supportEmail}}", "Écrivez-nous", target)
+	assert.Equal(t, violationTemplate, violationKind(err))
+	assert.Contains(t, correctionFor(err), "Keep every {{variable}} of the source")
+
+	// The other checks run on the resolved texts
+	err = checkTranslation("Write to {{supportEmail}} about {count} files", "Écrivez à {{supportEmail}} au sujet de {nombre} fichiers", target)
+	assert.Equal(t, violationPlaceholders, violationKind(err))
+}
+
+// TestCheckFramework tests that translations must keep the message syntax of the
+// framework valid, and that its escaped literals aren't read as placeholders
+func TestCheckFramework(t *testing.T) {
+	defer func(f escaping.Framework) { framework = f }(framework)
+	framework = escaping.Vue
+	target := &parser.LocaleFileContent{Code: "de", Lang: "German", LocaleItemsMap: map[string]string{}}
+
+	source := "Hello {name}, write to support{'@'}acme.com"
+	assert.NoError(t, checkTranslation(source, "Hallo {name}, schreib an support{'@'}acme.com", target))
+	err := checkTranslation(source, "Hallo {name}, schreib an support@acme.com", target)
+	assert.Equal(t, violationSyntax, violationKind(err))
+	assert.Contains(t, correctionFor(err), "Escape literal characters as the framework requires")
+
+	// Escapes added by the fix pass the placeholder checks
+	fixed := framework.Fix("Size or color", "Größe|Farbe")
+	assert.Equal(t, "Größe{'|'}Farbe", fixed)
+	assert.NoError(t, checkTranslation("Size or color", fixed, target))
+}
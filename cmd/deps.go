@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/secrets"
+)
+
+// Translator translates texts with a provider. *gpt.Handler is the real one; tests
+// process catalogs with fakes instead of calling an API.
+type Translator interface {
+	TranslateWithExamples(ctx context.Context, text string, lang string, examples []gpt.Example) (string, error)
+	AdaptDraft(ctx context.Context, text string, lang string, examples []gpt.Example, draft gpt.Example) (string, error)
+	BatchTranslateWithExamples(ctx context.Context, keys []string, texts []string, lang string, examples []gpt.Example) ([]string, error)
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Secrets(text string) []secrets.Finding
+}
+
+var _ Translator = (*gpt.Handler)(nil)
+
+// Clock tells the time stamped on change logs and error logs
+type Clock interface {
+	Now() time.Time
+}
+
+// FS reads and writes the files of a processed catalog: the catalog itself, its
+// retranslation sidecar and change log, and the error logs and failed key lists of
+// the project
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+	// WriteFile replaces the file at path, creating its directory if needed
+	WriteFile(path string, data []byte) error
+	// AppendFile appends data to the file at path, creating it and its directory if needed
+	AppendFile(path string, data []byte) error
+	// Remove deletes the file at path
+	Remove(path string) error
+}
+
+// systemClock is the clock of the machine
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// osFS is the file system of the machine. Files are replaced atomically.
+type osFS struct{}
+
+func (osFS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (osFS) WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func (osFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (osFS) AppendFile(path string, data []byte) error {
+	if err := checkWritable(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
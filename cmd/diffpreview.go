@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+)
+
+var dryRunDiff bool // Preview the changes of dry runs to each target file as a diff
+
+// ANSI escape sequences of the diff preview
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiCyan  = "\033[36m"
+)
+
+// keyChange is a change a run would make to a key of a target file
+type keyChange struct {
+	Key string
+	// Current value of the key, if it exists
+	Before string
+	Exists bool
+	// Source text the new value is translated from
+	Source string
+}
+
+// fileChanges are the changes a run would make to a target file
+type fileChanges struct {
+	Path    string
+	Changes []keyChange
+}
+
+// pendingChanges returns the changes a run would make to target, without changing it
+func pendingChanges(source, target, indep *parser.LocaleFileContent, opts processOptions) (fileChanges, error) {
+	keys, current, err := pendingKeys(source, target, indep, opts)
+	if err != nil {
+		return fileChanges{}, err
+	}
+	changes := fileChanges{Path: target.Path}
+	for _, k := range keys {
+		before, exists := current[k]
+		changes.Changes = append(changes.Changes, keyChange{Key: k, Before: before, Exists: exists, Source: source.LocaleItemsMap[k]})
+	}
+	return changes, nil
+}
+
+// renderDiff returns the changes of files as a unified diff, one hunk per key:
+// current values are removed and replaced by the translation of their source text,
+// which a dry run doesn't know yet. Files without changes are left out.
+func renderDiff(files []fileChanges, color bool) string {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var b strings.Builder
+	for _, f := range files {
+		if len(f.Changes) == 0 {
+			continue
+		}
+		added := 0
+		for _, c := range f.Changes {
+			if !c.Exists {
+				added++
+			}
+		}
+		b.WriteString(paint(ansiBold, fmt.Sprintf("--- %s\n+++ %s (%d added, %d changed)", f.Path, f.Path, added, len(f.Changes)-added)) + "\n")
+		for _, c := range f.Changes {
+			b.WriteString(paint(ansiCyan, "@@ "+c.Key+" @@") + "\n")
+			if c.Exists {
+				b.WriteString(paint(ansiRed, fmt.Sprintf("-%q: %q", c.Key, c.Before)) + "\n")
+			}
+			b.WriteString(paint(ansiGreen, fmt.Sprintf("+%q: translation of %q", c.Key, c.Source)) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "No target file would change\n"
+	}
+	return b.String()
+}
+
+// printDiffPreview prints the changes of files as a diff, colored on terminals unless
+// NO_COLOR is set, and paged when interactive
+func printDiffPreview(files []fileChanges) {
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	fmt.Println("\n🔍 Changes of the run:")
+	page(renderDiff(files, color), os.Stdin, os.Stdout, terminalHeight(), isTerminal(os.Stdin) && isTerminal(os.Stdout))
+}
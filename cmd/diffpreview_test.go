@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPendingChanges tests that added and retranslated keys are previewed with their
+// current value, leaving the target untouched
+func TestPendingChanges(t *testing.T) {
+	source := &parser.LocaleFileContent{LocaleItemsMap: map[string]string{"title": "New title", "save": "Save", "done": "Done"}}
+	target := &parser.LocaleFileContent{Path: "fr.json", Code: "fr", LocaleItemsMap: map[string]string{"title": "", "done": "Fait"}}
+
+	changes, err := pendingChanges(source, target, nil, processOptions{Mode: "full", Marker: marker.Default()})
+	require.NoError(t, err)
+	assert.Equal(t, fileChanges{Path: "fr.json", Changes: []keyChange{
+		{Key: "save", Source: "Save"},
+		{Key: "title", Exists: true, Source: "New title"},
+	}}, changes)
+	assert.Len(t, target.LocaleItemsMap, 2)
+}
+
+// TestRenderDiff tests the diff of the changes, colored on demand
+func TestRenderDiff(t *testing.T) {
+	files := []fileChanges{
+		{Path: "fr.json", Changes: []keyChange{{Key: "save", Source: "Save"}, {Key: "title", Before: "Ancien", Exists: true, Source: "New title"}}},
+		{Path: "de.json"},
+	}
+
+	diff := renderDiff(files, false)
+	assert.Equal(t, `--- fr.json
++++ fr.json (1 added, 1 changed)
+@@ save @@
++"save": translation of "Save"
+@@ title @@
+-"title": "Ancien"
++"title": translation of "New title"
+
+`, diff)
+	assert.NotContains(t, diff, "de.json")
+
+	colored := renderDiff(files, true)
+	assert.Contains(t, colored, ansiRed+`-"title": "Ancien"`+ansiReset)
+	assert.Contains(t, colored, ansiGreen+`+"save": translation of "Save"`+ansiReset)
+
+	assert.Equal(t, "No target file would change\n", renderDiff(files[1:], false))
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+)
+
+var dryRun bool // Estimate the pending work of a run instead of translating
+
+// printDryRun prints the estimate of translating the pending texts of a run into
+// each target language with the model of gptHandler
+func printDryRun(gptHandler *gpt.Handler, batch int, pending map[string][]string) {
+	samples, _ := forecast.LoadSamples()
+	model := gptHandler.Model()
+	opts := forecast.Options{
+		Model:       model,
+		BatchSize:   batch,
+		BatchTokens: gptHandler.BatchLimit(),
+		Expansion:   forecast.DefaultExpansion,
+		Throughput:  forecast.MeasureThroughput(samples, model, batch > 0),
+	}
+	fmt.Printf("🧪 Dry run: nothing is sent to the provider (model: %s, batch: %d)\n\n", model, batch)
+	fmt.Print(dryRunReport(pending, opts))
+}
+
+// dryRunReport returns the estimated requests, tokens, cost and time of translating the
+// pending texts by target language and in total, then the cost of the total with every
+// model of known price
+func dryRunReport(pending map[string][]string, opts forecast.Options) string {
+	langs := make([]string, 0, len(pending))
+	all := []string{}
+	for lang, texts := range pending {
+		langs = append(langs, lang)
+		all = append(all, texts...)
+	}
+	sort.Strings(langs)
+
+	tbl := table.New("Language", "Keys", "Requests", "Prompt Tokens", "Completion Tokens", "Cost", "Time")
+	total := forecast.Forecast{CostKnown: true}
+	for _, lang := range langs {
+		f := forecast.Estimate(pending[lang], opts)
+		tbl.Add(lang, f.Keys, f.Requests, f.PromptTokens, f.CompletionTokens, formatCost(f), f.Duration.Round(time.Second))
+
+		total.Keys += f.Keys
+		total.Requests += f.Requests
+		total.PromptTokens += f.PromptTokens
+		total.CompletionTokens += f.CompletionTokens
+		total.Cost += f.Cost
+		total.CostKnown = total.CostKnown && f.CostKnown
+		total.Duration += f.Duration
+	}
+	tbl.Add("**Total**", total.Keys, total.Requests, total.PromptTokens, total.CompletionTokens, formatCost(total), total.Duration.Round(time.Second))
+	if total.Keys == 0 {
+		return tbl.String() + "\n✅ Nothing to translate\n"
+	}
+
+	models := make([]string, 0, len(gpt.Prices))
+	for model := range gpt.Prices {
+		models = append(models, model)
+	}
+	// Cheapest first, then by name so equal prices keep a stable order
+	cost := func(model string) float64 {
+		c, _ := gpt.Cost(model, total.PromptTokens, total.CompletionTokens)
+		return c
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if ci, cj := cost(models[i]), cost(models[j]); ci != cj {
+			return ci < cj
+		}
+		return models[i] < models[j]
+	})
+	byModel := table.New("Model", "Cost")
+	for _, model := range models {
+		name := model
+		if model == opts.Model {
+			name += " (current)"
+		}
+		byModel.Add(name, fmt.Sprintf("$%.4f", cost(model)))
+	}
+
+	var b strings.Builder
+	b.WriteString(tbl.String())
+	b.WriteString("\n💵 Projected cost of the same tokens by model:\n")
+	b.WriteString(byModel.String())
+	return b.String()
+}
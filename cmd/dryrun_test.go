@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDryRunReport tests that dry runs estimate every target language, the total and
+// the cost with the other known models
+func TestDryRunReport(t *testing.T) {
+	pending := map[string][]string{
+		"fr": {"Save", "Cancel"},
+		"de": {"Save"},
+	}
+	opts := forecast.Options{Model: "gpt-4o-mini", Expansion: forecast.DefaultExpansion}
+	report := dryRunReport(pending, opts)
+
+	total := forecast.Estimate([]string{"Save", "Cancel", "Save"}, opts)
+	assert.Equal(t, 3, total.Requests)
+	assert.Less(t, strings.Index(report, "de"), strings.Index(report, "fr"))
+	assert.Contains(t, report, formatCost(total))
+	assert.Contains(t, report, "gpt-4o-mini (current)")
+	assert.Contains(t, report, "gpt-4o ")
+
+	assert.Contains(t, dryRunReport(map[string][]string{"fr": nil}, opts), "Nothing to translate")
+}
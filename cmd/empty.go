@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+)
+
+// maxListedEmptyKeys is the number of keys listed by the error of the error policy
+const maxListedEmptyKeys = 10
+
+// isBlank reports whether a source value is empty or whitespace only, and so has
+// nothing to translate
+func isBlank(v string) bool {
+	return strings.TrimSpace(v) == ""
+}
+
+// emptyPolicy returns the policy for empty source values of opts, skip by default
+func (o processOptions) emptyPolicy() string {
+	if o.EmptySource == "" {
+		return config.EmptySkip
+	}
+	return o.EmptySource
+}
+
+// applyEmptyPolicy gives the keys of target whose source value is blank the value of
+// the policy of opts, returning their number. With the error policy, the keys are
+// reported as an error instead.
+func (o processOptions) applyEmptyPolicy(source, target *parser.LocaleFileContent) (int, error) {
+	keys := []string{}
+	for k, v := range source.LocaleItemsMap {
+		if isBlank(v) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	switch o.emptyPolicy() {
+	case config.EmptyCopySource:
+		for _, k := range keys {
+			target.LocaleItemsMap[k] = source.LocaleItemsMap[k]
+		}
+	case config.EmptyTranslateAsEmpty:
+		for _, k := range keys {
+			target.LocaleItemsMap[k] = ""
+		}
+	case config.EmptyError:
+		if len(keys) > 0 {
+			listed := keys
+			if len(listed) > maxListedEmptyKeys {
+				listed = listed[:maxListedEmptyKeys]
+			}
+			return 0, fmt.Errorf("%d keys of %s have an empty source value: %s", len(keys), source.Path, strings.Join(listed, ", "))
+		}
+	}
+	return len(keys), nil
+}
+
+// countKeys returns the number of keys of source counted under policy and how many of
+// them target translates. Keys with a blank source value are left out under the skip
+// policy and count as translated when target has them under the others.
+func countKeys(source, target map[string]string, policy string) (total, translated int) {
+	for k, v := range source {
+		value, ok := target[k]
+		if isBlank(v) {
+			if policy == "" || policy == config.EmptySkip {
+				continue
+			}
+			total++
+			if ok {
+				translated++
+			}
+			continue
+		}
+		total++
+		if ok && value != "" {
+			translated++
+		}
+	}
+	return total, translated
+}
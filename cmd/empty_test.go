@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+)
+
+// emptySourceCatalogs returns a source with blank values and an empty target
+func emptySourceCatalogs() (*parser.LocaleFileContent, *parser.LocaleFileContent) {
+	source := &parser.LocaleFileContent{
+		Path: "/locales/en-US.json",
+		Code: "en-US",
+		Lang: "English",
+		LocaleItemsMap: map[string]string{
+			"greeting": "Hello",
+			"spacer":   " ",
+			"todo":     "",
+		},
+	}
+	target := &parser.LocaleFileContent{
+		Path:           "/locales/fr-FR.json",
+		Code:           "fr-FR",
+		Lang:           "français",
+		LocaleItemsMap: map[string]string{},
+	}
+	return source, target
+}
+
+// TestApplyEmptyPolicy tests the value each policy gives keys with a blank source
+func TestApplyEmptyPolicy(t *testing.T) {
+	source, target := emptySourceCatalogs()
+	blanks, err := processOptions{}.applyEmptyPolicy(source, target)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, blanks)
+	assert.Empty(t, target.LocaleItemsMap, "skip leaves the keys out")
+
+	source, target = emptySourceCatalogs()
+	_, err = processOptions{EmptySource: config.EmptyCopySource}.applyEmptyPolicy(source, target)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"spacer": " ", "todo": ""}, target.LocaleItemsMap)
+
+	source, target = emptySourceCatalogs()
+	_, err = processOptions{EmptySource: config.EmptyTranslateAsEmpty}.applyEmptyPolicy(source, target)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"spacer": "", "todo": ""}, target.LocaleItemsMap)
+
+	source, target = emptySourceCatalogs()
+	_, err = processOptions{EmptySource: config.EmptyError}.applyEmptyPolicy(source, target)
+	assert.EqualError(t, err, "2 keys of /locales/en-US.json have an empty source value: spacer, todo")
+}
+
+// TestCountKeys tests that keys with a blank source are only counted by the policies
+// writing them
+func TestCountKeys(t *testing.T) {
+	source := map[string]string{"greeting": "Hello", "farewell": "Goodbye", "spacer": " "}
+	target := map[string]string{"greeting": "Bonjour", "farewell": "", "spacer": " "}
+
+	total, translated := countKeys(source, target, "")
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, translated)
+
+	total, translated = countKeys(source, target, config.EmptyCopySource)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 2, translated)
+
+	total, translated = countKeys(source, map[string]string{}, config.EmptyTranslateAsEmpty)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 0, translated)
+}
+
+// TestSingleProcessEmptySource tests that blank sources are never sent and that the
+// error policy fails the file before translating
+func TestSingleProcessEmptySource(t *testing.T) {
+	source, target := emptySourceCatalogs()
+	translator := &fakeTranslator{translate: mockTranslate("français", map[string]string{"Hello": "Bonjour"})}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}, EmptySource: config.EmptyCopySource}
+	assert.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+	assert.Equal(t, []string{"Hello"}, translator.sent)
+	assert.Equal(t, map[string]string{"greeting": "Bonjour", "spacer": " ", "todo": ""}, target.LocaleItemsMap)
+
+	source, target = emptySourceCatalogs()
+	translator = &fakeTranslator{translate: mockTranslate("français", nil)}
+	opts.EmptySource = config.EmptyError
+	assert.Error(t, single_process(context.Background(), translator, source, target, nil, opts))
+	assert.Empty(t, translator.sent)
+}
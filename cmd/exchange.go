@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/glossary"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+var tmCmd = &cobra.Command{
+	Use:   "tm",
+	Short: "Exchange the translation memory with CAT tools in TMX",
+}
+
+var tmExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the translations of the catalogs as a TMX translation memory",
+	Long: `Write every translated key of the target catalogs as a translation unit of a TMX 1.4
+document, readable by CAT tools and other vendors. Units are identified by key, with
+a variant per target language. Keys flagged for retranslation are left out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		ds, cfg, targets := scanExchangeCatalogs(cmd)
+
+		entries := []tm.Entry{}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			marked, err := exchangeMarker(cfg).Extract(osFS{}, target)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			entries = append(entries, memoryEntries(source.LocaleItemsMap, target.LocaleItemsMap, marked, pair.TargetLang)...)
+		}
+
+		var buf bytes.Buffer
+		if err := tm.WriteTMX(&buf, ds.SourceLang, entries); err != nil {
+			fmt.Printf("❌ Error encoding the translation memory: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeFileAtomic(out, buf.Bytes()); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", out, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Exported %d translations to %s\n", len(entries), out)
+	},
+}
+
+var tmImportCmd = &cobra.Command{
+	Use:   "import <file.tmx>...",
+	Short: "Fill untranslated keys from TMX translation memories",
+	Long: `Seed the catalogs from translation memories we already own: keys without a
+translation get the translation of the TMX units whose source text is exactly theirs.
+Units of a regional variant ("fr-FR") are used for catalogs of the base language ("fr")
+without one of their own. Existing translations are always kept. Values written are
+recorded in the change history with the provider "tmx".`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		ds, _, targets := scanExchangeCatalogs(cmd)
+
+		entries := []tm.Entry{}
+		for _, path := range args {
+			f, err := os.Open(path)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			read, err := tm.ReadTMX(f, ds.SourceLang)
+			f.Close()
+			if err != nil {
+				fmt.Printf("❌ Error reading %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			entries = append(entries, read...)
+		}
+
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		if !dryRun {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		// Catalogs are written together once every one is seeded
+		tx := newTransaction()
+		opts := processOptions{FS: tx}
+		auditProvider = "tmx"
+		tbl := table.New("File", "Imported")
+		imported := 0
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			seeded := seedFromMemory(source.LocaleItemsMap, target.LocaleItemsMap, exchangeMemory(entries, pair.TargetLang), pair.TargetLang)
+			tbl.Add(pair.TargetFile, len(seeded))
+			imported += len(seeded)
+			if dryRun || len(seeded) == 0 {
+				continue
+			}
+			for k, v := range seeded {
+				target.LocaleItemsMap[k] = v
+			}
+			if err := opts.writeLocaleFile(target); err != nil {
+				fmt.Printf("❌ Error writing %s: %v\n", target.Path, err)
+				os.Exit(1)
+			}
+		}
+		if !dryRun {
+			if err := commitTransaction(context.Background(), tx); err != nil {
+				os.Exit(1)
+			}
+		}
+
+		fmt.Print(tbl.String())
+		if dryRun {
+			fmt.Printf("\n🧪 Dry run: %d translations would be imported\n", imported)
+			return
+		}
+		fmt.Printf("\n✅ Imported %d translations\n", imported)
+	},
+}
+
+var glossaryCmd = &cobra.Command{
+	Use:   "glossary",
+	Short: "Exchange the glossary with CAT tools in TBX",
+}
+
+var glossaryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the glossary of the configuration as a TBX term base",
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		path, cfg := glossaryPath(cmd)
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		terms, err := glossary.Load(path)
+		if err != nil {
+			fmt.Printf("❌ Error reading glossary: %v\n", err)
+			os.Exit(1)
+		}
+		var buf bytes.Buffer
+		if err := terms.WriteTBX(&buf, sourceLang); err != nil {
+			fmt.Printf("❌ Error encoding the glossary: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeFileAtomic(out, buf.Bytes()); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", out, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Exported %d terms to %s\n", len(terms), out)
+	},
+}
+
+var glossaryImportCmd = &cobra.Command{
+	Use:   "import <file.tbx>...",
+	Short: "Merge TBX term bases into the glossary of the configuration",
+	Long: `Add the terms of TBX term bases, TBX-Core or TBX-Basic, to the glossary of the
+configuration, which is created if needed, in JSON unless its name ends with .tbx. Translations of the glossary are kept:
+terms the term bases translate otherwise are reported.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourceLang, _ := cmd.Flags().GetString("source")
+		path, cfg := glossaryPath(cmd)
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		terms := glossary.Glossary{}
+		if _, err := os.Stat(path); err == nil {
+			if terms, err = glossary.Load(path); err != nil {
+				fmt.Printf("❌ Error reading glossary: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		added := 0
+		conflicts := []string{}
+		for _, file := range args {
+			f, err := os.Open(file)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			read, err := glossary.ReadTBX(f, sourceLang)
+			f.Close()
+			if err != nil {
+				fmt.Printf("❌ Error reading %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			n, c := terms.Merge(read)
+			added += n
+			conflicts = append(conflicts, c...)
+		}
+
+		if err := saveGlossary(path, terms, sourceLang); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		for _, term := range conflicts {
+			fmt.Printf("⚠️ %q is translated otherwise in the term base, ours kept\n", term)
+		}
+		fmt.Printf("✅ Imported %d translations of terms into %s\n", added, path)
+	},
+}
+
+// scanExchangeCatalogs scans the catalogs of the --root flag and returns them with the
+// configuration and the target languages to exchange, exiting on errors
+func scanExchangeCatalogs(cmd *cobra.Command) (*scanner.DirectoryStructure, *config.Config, []string) {
+	rootDir, _ := cmd.Flags().GetString("root")
+	sourceLang, _ := cmd.Flags().GetString("source")
+	langs, _ := cmd.Flags().GetStringSlice("lang")
+
+	cfg, err := loadOptionalConfig(cmd)
+	if err != nil {
+		fmt.Printf("❌ Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg != nil && !cmd.Flags().Changed("source") {
+		sourceLang = cfg.SourceLang
+	}
+	if cfg != nil {
+		applyOutputSettings(cfg)
+	}
+
+	ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+	if err != nil {
+		fmt.Printf("❌ Error scanning directory: %v\n", err)
+		os.Exit(1)
+	}
+	targets := selectTargetLanguages(ds, cfg)
+	if len(langs) > 0 {
+		targets = langs
+	}
+	return ds, cfg, targets
+}
+
+// exchangeMarker returns the retranslation marker of cfg
+func exchangeMarker(cfg *config.Config) marker.Marker {
+	if cfg == nil {
+		return marker.Default()
+	}
+	return cfg.Marker
+}
+
+// glossaryPath returns the glossary file of the --glossary flag, or of the
+// configuration, exiting when there is none
+func glossaryPath(cmd *cobra.Command) (string, *config.Config) {
+	cfg, err := loadOptionalConfig(cmd)
+	if err != nil {
+		fmt.Printf("❌ Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	path, _ := cmd.Flags().GetString("glossary")
+	if path == "" && cfg != nil {
+		path = cfg.Glossary
+	}
+	if path == "" {
+		fmt.Println("❌ No glossary: set --glossary or \"glossary\" in the configuration")
+		os.Exit(1)
+	}
+	return path, cfg
+}
+
+// saveGlossary writes terms to path, in TBX when its extension is .tbx and in JSON
+// otherwise
+func saveGlossary(path string, terms glossary.Glossary, sourceLang string) error {
+	var buf bytes.Buffer
+	if strings.EqualFold(filepath.Ext(path), ".tbx") {
+		if err := terms.WriteTBX(&buf, sourceLang); err != nil {
+			return err
+		}
+	} else {
+		data, err := json.MarshalIndent(terms, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// memoryEntries returns the translated keys of a target catalog into lang as memory
+// entries, sorted by key, leaving out blank values and the marked keys
+func memoryEntries(source, target map[string]string, marked map[string]struct{}, lang string) []tm.Entry {
+	keys := make([]string, 0, len(source))
+	for k := range source {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := []tm.Entry{}
+	for _, k := range keys {
+		translation, ok := target[k]
+		if _, isMarked := marked[k]; !ok || isMarked || isBlank(source[k]) || isBlank(translation) {
+			continue
+		}
+		entries = append(entries, tm.Entry{Key: k, Source: source[k], Target: translation, Lang: lang})
+	}
+	return entries
+}
+
+// exchangeMemory returns a memory of the entries into lang. Entries of its regional
+// variants ("fr-FR" for "fr") are added after those of lang itself, so they only
+// provide the texts lang has no translation for.
+func exchangeMemory(entries []tm.Entry, lang string) *tm.Memory {
+	memory := tm.New()
+	normalize := func(code string) string { return strings.ToLower(strings.ReplaceAll(code, "_", "-")) }
+	code := normalize(lang)
+	for _, regional := range []bool{false, true} {
+		for _, e := range entries {
+			entryCode := normalize(e.Lang)
+			if (!regional && entryCode == code) || (regional && strings.HasPrefix(entryCode, code+"-")) {
+				e.Lang = lang
+				memory.Add(e)
+			}
+		}
+	}
+	return memory
+}
+
+// seedFromMemory returns the translations into lang from memory of the keys of source
+// without a translation in target, by exact source text
+func seedFromMemory(source, target map[string]string, memory *tm.Memory, lang string) map[string]string {
+	seeded := map[string]string{}
+	for k, text := range source {
+		if !isBlank(target[k]) || isBlank(text) {
+			continue
+		}
+		if e, ok := memory.Exact(text, lang); ok {
+			seeded[k] = e.Target
+		}
+	}
+	return seeded
+}
+
+func init() {
+	for _, c := range []*cobra.Command{tmExportCmd, tmImportCmd} {
+		c.Flags().String("root", "", "Root directory containing language subdirectories")
+		c.Flags().String("source", "en", "Source language code (default: en)")
+		c.Flags().String("config", "", "Path to configuration file")
+		c.Flags().StringSlice("lang", nil, "Languages to exchange (default: every target language)")
+		c.MarkFlagRequired("root")
+	}
+	tmExportCmd.Flags().String("out", "memory.tmx", "TMX file to write")
+	tmImportCmd.Flags().Bool("dry-run", false, "Report what would be imported without writing the catalogs")
+
+	for _, c := range []*cobra.Command{glossaryExportCmd, glossaryImportCmd} {
+		c.Flags().String("glossary", "", "Glossary file (default: the glossary of the configuration)")
+		c.Flags().String("source", "en", "Source language code of the terms (default: en)")
+		c.Flags().String("config", "", "Path to configuration file")
+	}
+	glossaryExportCmd.Flags().String("out", "glossary.tbx", "TBX file to write")
+
+	tmCmd.AddCommand(tmExportCmd, tmImportCmd)
+	glossaryCmd.AddCommand(glossaryExportCmd, glossaryImportCmd)
+	rootCmd.AddCommand(tmCmd, glossaryCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryEntries tests which keys of a catalog are exported to the translation memory
+func TestMemoryEntries(t *testing.T) {
+	source := map[string]string{"title": "Cart", "empty": "", "flagged": "Pay", "missing": "Save"}
+	target := map[string]string{"title": "Panier", "empty": "", "flagged": "Payer"}
+	entries := memoryEntries(source, target, map[string]struct{}{"flagged": {}}, "fr")
+	assert.Equal(t, []tm.Entry{{Key: "title", Source: "Cart", Target: "Panier", Lang: "fr"}}, entries)
+}
+
+// TestSeedFromMemory tests that untranslated keys are filled from exact matches, in the
+// language of the catalog first and then in its regional variants
+func TestSeedFromMemory(t *testing.T) {
+	entries := []tm.Entry{
+		{Source: "Cart", Target: "Panier (CA)", Lang: "fr-CA"},
+		{Source: "Cart", Target: "Panier", Lang: "FR"},
+		{Source: "Save", Target: "Enregistrer", Lang: "fr_FR"},
+		{Source: "Cart", Target: "Warenkorb", Lang: "de"},
+	}
+	source := map[string]string{"a": "Cart", "b": "Save", "c": "Pay", "d": "Cart"}
+	target := map[string]string{"d": "Mon panier"}
+	seeded := seedFromMemory(source, target, exchangeMemory(entries, "fr"), "fr")
+	assert.Equal(t, map[string]string{"a": "Panier", "b": "Enregistrer"}, seeded)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var exportStoreCmd = &cobra.Command{
+	Use:   "export-store",
+	Short: "Export app store metadata for every locale",
+	Long:  `Export the app store listing keys (app name, subtitle, descriptions, keywords) of every language to per-locale directories in the App Store Connect and Google Play Console layouts used by fastlane, validating the store length limits. Exits with a non-zero status when a value exceeds its limit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		outDir, _ := cmd.Flags().GetString("out")
+		storeNames, _ := cmd.Flags().GetStringSlice("store")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		keys := store.DefaultKeys()
+		if cfg != nil {
+			for field, key := range cfg.StoreKeys {
+				if !containsString(store.Fields, field) {
+					fmt.Printf("❌ Unknown store field %s in storeKeys (expected one of %s)\n", field, strings.Join(store.Fields, ", "))
+					return
+				}
+				keys[field] = strings.ReplaceAll(key, ".", "/")
+			}
+		}
+
+		stores := []store.Store{}
+		for _, name := range storeNames {
+			s, ok := store.Stores[name]
+			if !ok {
+				fmt.Printf("❌ Unknown store %s (expected apple or google)\n", name)
+				return
+			}
+			stores = append(stores, s)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			return
+		}
+
+		languages := append([]string{sourceLang}, selectTargetLanguages(ds, cfg)...)
+		violations := []store.Violation{}
+		for _, lang := range languages {
+			values, err := storeValues(ds, lang, keys)
+			if err != nil {
+				fmt.Printf("❌ Error reading %s: %v\n", lang, err)
+				return
+			}
+			if len(values) == 0 {
+				fmt.Printf("⚠️ %s: no store metadata keys found, skipped\n", lang)
+				continue
+			}
+
+			for _, s := range stores {
+				v, err := s.Export(outDir, lang, values)
+				if err != nil {
+					fmt.Printf("❌ Error writing %s metadata for %s: %v\n", s.Name, lang, err)
+					return
+				}
+				violations = append(violations, v...)
+			}
+			fmt.Printf("✅ %s: exported %d fields\n", lang, len(values))
+		}
+
+		if len(violations) > 0 {
+			fmt.Printf("\n⚠️ %d values exceed their store limits:\n", len(violations))
+			for _, v := range violations {
+				fmt.Printf("- %s\n", v)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+// storeValues collects the store metadata fields of a language. Keys are looked up
+// in every file type, the first file in alphabetical order wins.
+func storeValues(ds *scanner.DirectoryStructure, lang string, keys map[string]string) (map[string]string, error) {
+	sorted := append([]string{}, ds.FileTypes...)
+	sort.Strings(sorted)
+
+	values := make(map[string]string)
+	for _, fileType := range sorted {
+		path := ds.Path(lang, fileType)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		content := &parser.LocaleFileContent{Path: path}
+		if err := content.ParseContent(); err != nil {
+			return nil, err
+		}
+		for field, key := range keys {
+			if _, found := values[field]; found {
+				continue
+			}
+			if v := content.LocaleItemsMap[key]; v != "" {
+				values[field] = v
+			}
+		}
+	}
+	return values, nil
+}
+
+func init() {
+	exportStoreCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	exportStoreCmd.Flags().String("source", "en", "Source language code (default: en)")
+	exportStoreCmd.Flags().String("config", "", "Path to configuration file")
+	exportStoreCmd.Flags().String("out", "store_metadata", "Directory to write the store metadata to")
+	exportStoreCmd.Flags().StringSlice("store", []string{"apple", "google"}, "Stores to export for: apple, google")
+
+	exportStoreCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(exportStoreCmd)
+}
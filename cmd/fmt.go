@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Rewrite catalogs with a deterministic key order and indentation",
+	Long: `Rewrite the catalogs of every language with the keys of each object in a deterministic
+order, every member on its own line with the same indentation, and a trailing newline, so
+that catalogs edited by different people and tools produce minimal diffs. --order
+alphabetical sorts keys with the "sort" options of the configuration file; --order source
+orders the keys of each target catalog as its source catalog does, keys the source lacks
+last. Values are kept exactly as written and no key is added or removed. Use --check in CI
+to list the catalogs that aren't formatted and fail without writing them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		order, _ := cmd.Flags().GetString("order")
+		indentFlag, _ := cmd.Flags().GetString("indent")
+		check, _ := cmd.Flags().GetBool("check")
+
+		if order != parser.OrderAlphabetical && order != parser.OrderSource {
+			fmt.Printf("❌ --order must be one of %s\n", strings.Join(parser.Orders, ", "))
+			os.Exit(1)
+		}
+		indent, err := parseIndent(indentFlag)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		sortOpts := keyorder.SortOptions{}
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			sortOpts = cfg.Sort
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !check {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		// Format every catalog first, so that an invalid one leaves the rest untouched
+		formatted := map[string][]byte{}
+		paths := []string{}
+		failed := false
+		for _, fileType := range ds.FileTypes {
+			source, err := os.ReadFile(ds.Path(ds.SourceLang, fileType))
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Printf("❌ Error reading %s: %v\n", ds.Path(ds.SourceLang, fileType), err)
+				os.Exit(1)
+			}
+			for _, lang := range ds.Languages {
+				path := ds.Path(lang, fileType)
+				data, err := os.ReadFile(path)
+				if os.IsNotExist(err) {
+					continue
+				}
+				if err != nil {
+					fmt.Printf("❌ Error reading %s: %v\n", path, err)
+					os.Exit(1)
+				}
+				opts := parser.FormatOptions{Order: order, Sort: sortOpts, Lang: lang, Source: source, Indent: indent}
+				if order == parser.OrderSource && source == nil {
+					// Catalogs without a source catalog have no order to follow
+					opts.Order = parser.OrderAlphabetical
+				}
+				result, err := parser.Format(data, opts)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", path, err)
+					failed = true
+					continue
+				}
+				if !bytes.Equal(data, result) {
+					formatted[path] = result
+					paths = append(paths, path)
+				}
+			}
+		}
+		if failed {
+			fmt.Println("❌ No catalog was formatted, fix the invalid ones first")
+			os.Exit(1)
+		}
+
+		for _, path := range paths {
+			if check {
+				fmt.Printf("📝 Not formatted: %s\n", path)
+				continue
+			}
+			if err := writeFileAtomic(path, formatted[path]); err != nil {
+				fmt.Printf("❌ Error writing %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Formatted %s\n", path)
+		}
+
+		switch {
+		case len(paths) == 0:
+			fmt.Println("✅ Every catalog is formatted")
+		case check:
+			fmt.Printf("❌ %d catalogs aren't formatted, run 'i18n-cli fmt' to format them\n", len(paths))
+			os.Exit(1)
+		default:
+			fmt.Printf("✅ Formatted %d catalogs\n", len(paths))
+		}
+	},
+}
+
+// parseIndent returns the indentation step named by --indent: a number of spaces, "tab",
+// or "keep" for that of each catalog
+func parseIndent(value string) (string, error) {
+	switch value {
+	case "keep":
+		return "", nil
+	case "tab":
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 8 {
+		return "", fmt.Errorf("--indent must be a number of spaces from 1 to 8, 'tab' or 'keep', got %q", value)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+func init() {
+	fmtCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	fmtCmd.Flags().String("source", "en", "Source language code (default: en)")
+	fmtCmd.Flags().String("config", "", "Path to configuration file")
+	fmtCmd.Flags().String("order", parser.OrderAlphabetical, "Key order: 'alphabetical' or 'source'")
+	fmtCmd.Flags().String("indent", "2", "Indentation: a number of spaces, 'tab', or 'keep' for that of each catalog")
+	fmtCmd.Flags().Bool("check", false, "List the catalogs that aren't formatted and fail, without writing them")
+
+	fmtCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(fmtCmd)
+}
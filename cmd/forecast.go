@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/forecast"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:         "forecast",
+	Short:       "Estimate the cost of adding a new language",
+	Long:        `Estimate the tokens, cost, and time needed to fully translate the current source catalog into a new language, based on token counts and the throughput measured in previous runs.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		lang, _ := cmd.Flags().GetString("lang")
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceFile, _ := cmd.Flags().GetString("file")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		configPath, _ := cmd.Flags().GetString("config")
+		batch, _ := cmd.Flags().GetInt("batch")
+		expansion, _ := cmd.Flags().GetFloat64("expansion")
+
+		var cfg *config.Config
+		if configPath != "" {
+			var err error
+			cfg, err = config.LoadConfig(configPath)
+			if err != nil {
+				fmt.Printf("❌ Error loading configuration: %v\n", err)
+				return
+			}
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			if !cmd.Flags().Changed("batch") {
+				batch = cfg.BatchSize
+			}
+		}
+
+		if _, err := parser.LangCodeToName(lang); err != nil {
+			fmt.Printf("❌ Invalid language code %s: %v\n", lang, err)
+			return
+		}
+
+		// Collect the source catalog
+		catalogs := make(map[string]map[string]string)
+		switch {
+		case sourceFile != "":
+			source := &parser.LocaleFileContent{Path: sourceFile}
+			if err := source.ParseContent(); err != nil {
+				fmt.Printf("❌ Error reading source file: %v\n", err)
+				return
+			}
+			catalogs[filepath.Base(sourceFile)] = source.LocaleItemsMap
+		case rootDir != "":
+			ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+			if err != nil {
+				fmt.Printf("❌ Error scanning directory: %v\n", err)
+				return
+			}
+			for _, fileType := range ds.FileTypes {
+				source := &parser.LocaleFileContent{Path: ds.Path(sourceLang, fileType)}
+				if err := source.ParseContent(); err != nil {
+					fmt.Printf("❌ Error reading source file %s: %v\n", source.Path, err)
+					return
+				}
+				catalogs[fileType] = source.LocaleItemsMap
+			}
+		default:
+			fmt.Println("❌ Either --root or --file is required")
+			return
+		}
+
+		samples, err := forecast.LoadSamples()
+		if err != nil {
+			fmt.Printf("⚠️ Could not read throughput history: %v\n", err)
+		}
+
+		opts := forecast.Options{
+			Model:      translationModel(cfg),
+			BatchSize:  batch,
+			Expansion:  expansion,
+			Throughput: forecast.MeasureThroughput(samples, translationModel(cfg), batch > 0),
+		}
+
+		fileTypes := make([]string, 0, len(catalogs))
+		for fileType := range catalogs {
+			fileTypes = append(fileTypes, fileType)
+		}
+		sort.Strings(fileTypes)
+
+		fmt.Printf("🔮 Forecast for adding %s (model: %s, batch: %d)\n\n", lang, opts.Model, batch)
+		tbl := table.New("File", "Keys", "Requests", "Prompt Tokens", "Completion Tokens", "Cost", "Time")
+
+		total := forecast.Forecast{CostKnown: true}
+		for _, fileType := range fileTypes {
+			texts := make([]string, 0, len(catalogs[fileType]))
+			for _, v := range catalogs[fileType] {
+				texts = append(texts, v)
+			}
+
+			f := forecast.Estimate(texts, opts)
+			tbl.Add(fileType, f.Keys, f.Requests, f.PromptTokens, f.CompletionTokens, formatCost(f), f.Duration.Round(time.Second))
+
+			total.Keys += f.Keys
+			total.Requests += f.Requests
+			total.PromptTokens += f.PromptTokens
+			total.CompletionTokens += f.CompletionTokens
+			total.Cost += f.Cost
+			total.CostKnown = total.CostKnown && f.CostKnown
+			total.Duration += f.Duration
+		}
+		tbl.Add("**Total**", total.Keys, total.Requests, total.PromptTokens, total.CompletionTokens, formatCost(total), total.Duration.Round(time.Second))
+		fmt.Println(tbl)
+
+		if opts.Throughput.Runs > 0 {
+			fmt.Printf("⏱️ Time based on %d measured runs (%.1fs per request)\n", opts.Throughput.Runs, opts.Throughput.RequestLatency.Seconds())
+		} else {
+			fmt.Printf("⏱️ No measured runs yet, assuming %.1fs per request\n", opts.Throughput.RequestLatency.Seconds())
+		}
+	},
+}
+
+func formatCost(f forecast.Forecast) string {
+	if !f.CostKnown {
+		return "unknown"
+	}
+	return fmt.Sprintf("$%.4f", f.Cost)
+}
+
+// recordThroughput stores the usage of a finished run so future forecasts can use it
+func recordThroughput(gptHandler *gpt.Handler, batch bool) {
+	usage := gptHandler.Usage()
+	if usage.Requests == 0 {
+		return
+	}
+
+	err := forecast.RecordSample(forecast.Sample{
+		Time:  time.Now(),
+		Model: gptHandler.Model(),
+		Batch: batch,
+		Usage: usage,
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Could not record throughput: %v\n", err)
+	}
+}
+
+func init() {
+	forecastCmd.Flags().String("lang", "", "Language code to forecast")
+	forecastCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	forecastCmd.Flags().String("file", "", "Single source language file (instead of --root)")
+	forecastCmd.Flags().String("source", "en", "Source language code (default: en)")
+	forecastCmd.Flags().String("config", "", "Path to configuration file")
+	forecastCmd.Flags().Int("batch", 0, "Batch size to forecast for. If 0, one request per key.")
+	forecastCmd.Flags().Float64("expansion", forecast.DefaultExpansion, "Expected ratio of translated tokens to source tokens")
+
+	forecastCmd.MarkFlagRequired("lang")
+
+	rootCmd.AddCommand(forecastCmd)
+}
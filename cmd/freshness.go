@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/quality"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+var freshnessCmd = &cobra.Command{
+	Use:   "freshness",
+	Short: "Re-audit a random sample of existing translations",
+	Long:  `Re-translate a small random sample of already translated keys per language, compare them with the stored values, and have the model grade the stored translations. Results are appended to the quality history shown by the status command. Locale files are never modified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		sampleSize, _ := cmd.Flags().GetInt("sample")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			return
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			return
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			return
+		}
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed))
+		ctx := context.Background()
+
+		fmt.Printf("🔍 Auditing %d keys per language (seed %d)\n", sampleSize, seed)
+
+		records := []quality.Record{}
+		for _, lang := range selectTargetLanguages(ds, cfg) {
+			// Collect translated keys of the language across all files
+			entries := []tm.Entry{}
+			for _, fileType := range ds.FileTypes {
+				pair := scanner.FilePair{
+					SourceFile: ds.Path(sourceLang, fileType),
+					TargetFile: ds.Path(lang, fileType),
+					SourceLang: sourceLang,
+					TargetLang: lang,
+					FileType:   fileType,
+				}
+				source, target, err := pair.LoadPair()
+				if err != nil {
+					fmt.Printf("❌ Error loading pair: %v\n", err)
+					continue
+				}
+				for k, v := range source.LocaleItemsMap {
+					if stored := target.LocaleItemsMap[k]; v != "" && stored != "" {
+						entries = append(entries, tm.Entry{Key: fileType + ":" + k, Source: v, Target: stored, Lang: lang})
+					}
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+
+			// Sort first so the same seed always picks the same keys
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+			rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+			if len(entries) > sampleSize {
+				entries = entries[:sampleSize]
+			}
+
+			langCtx := gpt.WithLanguages(ctx, sourceLang, lang)
+			record := quality.Record{Time: time.Now(), Lang: lang}
+			totalScore := 0
+			for _, e := range entries {
+				fresh, err := gptHandler.Translate(langCtx, e.Source, lang)
+				if err != nil {
+					fmt.Printf("⚠️ Error translating %s: %v\n", e.Key, err)
+					continue
+				}
+
+				grade, err := gptHandler.Grade(langCtx, e.Source, e.Target, fresh, lang)
+				if err != nil {
+					fmt.Printf("⚠️ Error grading %s: %v\n", e.Key, err)
+					continue
+				}
+
+				record.Samples++
+				totalScore += grade.Score
+				if tm.Similarity(e.Target, fresh) < threshold {
+					record.Changed++
+				}
+				if grade.Score <= 2 {
+					fmt.Printf("⚠️ %s [%s] scored %d: %s\n   stored: %s\n   fresh:  %s\n", e.Key, lang, grade.Score, grade.Reason, e.Target, fresh)
+				}
+			}
+
+			if record.Samples == 0 {
+				continue
+			}
+			record.Score = float64(totalScore) / float64(record.Samples)
+			records = append(records, record)
+		}
+
+		tbl := table.New("Language", "Samples", "Drift", "Average Score")
+		for _, r := range records {
+			tbl.Add(r.Lang, r.Samples, fmt.Sprintf("%.1f%%", r.Drift()*100), fmt.Sprintf("%.2f", r.Score))
+		}
+		fmt.Print("\n" + tbl.String())
+
+		if err := quality.AppendHistory(records...); err != nil {
+			fmt.Printf("❌ Error saving quality history: %v\n", err)
+			return
+		}
+		fmt.Println("\n✅ Quality history updated")
+	},
+}
+
+func init() {
+	freshnessCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	freshnessCmd.Flags().String("source", "en", "Source language code (default: en)")
+	freshnessCmd.Flags().String("config", "", "Path to configuration file")
+	freshnessCmd.Flags().Int("sample", 10, "Number of translated keys to audit per language")
+	freshnessCmd.Flags().Int64("seed", 0, "Random seed for sampling (default: current time)")
+	freshnessCmd.Flags().Float64("threshold", 0.9, "Similarity below which a fresh translation counts as drifted")
+
+	freshnessCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(freshnessCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+)
+
+var forceWrite bool                           // Write files even when the shrink guard objects
+var shrinkGuard = config.DefaultShrinkGuard() // Thresholds for refusing dramatically smaller files
+
+// checkShrink refuses to overwrite path when buf has dramatically fewer keys or
+// bytes than the file on disk, which usually means something went wrong upstream
+func checkShrink(path string, buf []byte) error {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		// nothing to protect
+		return nil
+	}
+	return guardShrink(path, existing, buf)
+}
+
+// guardShrink returns an error if replacing the existing content of path with buf
+// loses more keys or bytes than the shrink guard allows
+func guardShrink(path string, existing, buf []byte) error {
+	if forceWrite {
+		return nil
+	}
+
+	oldKeys := countLeafKeys(existing)
+	newKeys := countLeafKeys(buf)
+	if shrinkGuard.MaxKeyDrop > 0 && oldKeys > 0 && float64(oldKeys-newKeys)/float64(oldKeys) > shrinkGuard.MaxKeyDrop {
+		return fmt.Errorf("refusing to write %s: key count would drop from %d to %d (more than %.0f%%), use --force to override",
+			path, oldKeys, newKeys, shrinkGuard.MaxKeyDrop*100)
+	}
+
+	oldBytes, newBytes := len(existing), len(buf)
+	if shrinkGuard.MaxByteDrop > 0 && oldBytes > 0 && float64(oldBytes-newBytes)/float64(oldBytes) > shrinkGuard.MaxByteDrop {
+		return fmt.Errorf("refusing to write %s: size would drop from %d to %d bytes (more than %.0f%%), use --force to override",
+			path, oldBytes, newBytes, shrinkGuard.MaxByteDrop*100)
+	}
+
+	return nil
+}
+
+// countLeafKeys counts the non-object values of a JSON document, 0 if it can't be parsed
+func countLeafKeys(data []byte) int {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0
+	}
+	return countLeaves(doc)
+}
+
+func countLeaves(data map[string]interface{}) int {
+	count := 0
+	for _, v := range data {
+		if child, ok := v.(map[string]interface{}); ok {
+			count += countLeaves(child)
+		} else {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckShrink tests that writes losing most keys are refused unless forced
+func TestCheckShrink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fr.json")
+	err := os.WriteFile(path, []byte(`{"a": "1", "b": "2", "c": {"d": "3", "e": "4"}}`), 0644)
+	assert.NoError(t, err)
+
+	assert.NoError(t, checkShrink(path, []byte(`{"a": "1", "b": "2", "c": {"d": "3"}}`)))
+	assert.Error(t, checkShrink(path, []byte(`{"a": "1"}`)))
+
+	forceWrite = true
+	defer func() { forceWrite = false }()
+	assert.NoError(t, checkShrink(path, []byte(`{"a": "1"}`)))
+
+	// New files are never guarded
+	forceWrite = false
+	assert.NoError(t, checkShrink(filepath.Join(t.TempDir(), "new.json"), []byte(`{}`)))
+}
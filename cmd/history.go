@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/audit"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runID         = audit.NewRunID() // Identifies the changes made by this invocation in the audit trail
+	auditProvider string             // What produced the values written by this invocation
+)
+
+var historyCmd = &cobra.Command{
+	Use:         "history <key>",
+	Short:       "Show the change history of a key",
+	Long:        `Show every recorded change of a key's value across the catalogs under a directory: when, by which run and user, from which provider, and the old and new values.`,
+	Annotations: inspectOnly,
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		lang, _ := cmd.Flags().GetString("lang")
+		key := strings.ReplaceAll(args[0], ".", "/")
+
+		entries, err := audit.Find(rootDir, key)
+		if err != nil {
+			fmt.Printf("❌ Error reading change logs: %v\n", err)
+			return
+		}
+
+		if lang != "" {
+			filtered := entries[:0]
+			for _, e := range entries {
+				if catalogMatchesLang(e.Catalog, lang) {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("No recorded changes of %s under %s\n", args[0], rootDir)
+			return
+		}
+
+		fmt.Printf("# History of %s\n\n", args[0])
+		tbl := table.New("Time", "Catalog", "Run", "User", "Provider", "Old", "New")
+		tbl.MaxWidth = historyCellWidth
+		for _, e := range entries {
+			newValue := e.New
+			if e.Removed {
+				newValue = "(removed)"
+			}
+			tbl.Add(e.Time.Local().Format(time.RFC3339), e.Catalog, e.RunID, e.User, e.Provider, e.Old, newValue)
+		}
+		fmt.Print(tbl)
+	},
+}
+
+// recordChanges appends the values changed by writing target to its audit trail.
+// Failing to record is reported but doesn't fail the write.
+func (o processOptions) recordChanges(target *parser.LocaleFileContent) {
+	entries := audit.Changes(target.Path, target.Original(), target.LocaleItemsMap)
+	if len(entries) == 0 {
+		return
+	}
+	now := o.clock().Now().UTC()
+	user := audit.CurrentUser()
+	for i := range entries {
+		entries[i].Time = now
+		entries[i].RunID = runID
+		entries[i].User = user
+		entries[i].Provider = auditProvider
+	}
+
+	data, err := audit.Marshal(entries)
+	if err == nil {
+		err = o.fs().AppendFile(audit.LogPath(target.Path), data)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Could not record changes of %s: %v\n", target.Path, err)
+	}
+}
+
+// historyCellWidth is the width values are truncated to in the history table
+const historyCellWidth = 60
+
+// catalogMatchesLang reports whether a catalog path belongs to a language, either as
+// <lang>/<file>.json or <lang>.json, ignoring case
+func catalogMatchesLang(catalog, lang string) bool {
+	sep := "/"
+	path := strings.ToLower(strings.ReplaceAll(catalog, "\\", sep))
+	lang = strings.ToLower(lang)
+	return strings.Contains(sep+path, sep+lang+sep) || strings.HasSuffix(path, sep+lang+".json") || path == lang+".json"
+}
+
+func init() {
+	historyCmd.Flags().String("root", ".", "Directory containing the catalogs")
+	historyCmd.Flags().String("lang", "", "Only show changes of this language")
+
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// lengthError is a translation longer than the limit of its key
+type lengthError struct {
+	Length int
+	Limit  int
+}
+
+func (e *lengthError) Error() string {
+	return fmt.Sprintf("translation has %d characters, over the limit of %d", e.Length, e.Limit)
+}
+
+// checkLength verifies result has no more characters than the limit of key, if it has one
+func (o processOptions) checkLength(key, result string) error {
+	limit := o.Keys.Lookup(key).MaxLength
+	if n := utf8.RuneCountInString(result); limit > 0 && n > limit {
+		return &validationError{Kind: violationLength, Err: &lengthError{Length: n, Limit: limit}}
+	}
+	return nil
+}
+
+// lengthInstruction returns the prompt instruction of the limit of key, empty when it
+// has none
+func (o processOptions) lengthInstruction(key string) string {
+	limit := o.Keys.Lookup(key).MaxLength
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("The translation is shown in a limited space: keep it within %d characters, shortening it if needed.", limit)
+}
+
+// batchLengthInstruction returns the prompt instruction of the limits of the keys of a
+// batch, sorted by key, empty when none has one
+func (o processOptions) batchLengthInstruction(keys []string) string {
+	limits := []string{}
+	for _, key := range keys {
+		if limit := o.Keys.Lookup(key).MaxLength; limit > 0 {
+			limits = append(limits, fmt.Sprintf("%q: %d", key, limit))
+		}
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	sort.Strings(limits)
+	return fmt.Sprintf("The translations of these keys are shown in a limited space: keep each within its number of characters, shortening it if needed: %s.", strings.Join(limits, "; "))
+}
+
+// withLength returns instructions followed by the length instruction, if any
+func withLength(instructions, length string) string {
+	return strings.TrimSpace(instructions + " " + length)
+}
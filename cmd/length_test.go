@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/keymeta"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaxLength tests that translations over the limit of their key are retried to be
+// shortened, alone and in batches
+func TestMaxLength(t *testing.T) {
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"button/save": "Save changes", "intro": "Save changes"}}
+	keys := keymeta.Rules{"button/*": {MaxLength: 12}}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Keys: keys, Clock: fixedClock{testTime}, FS: memFS{}}
+	long, short := "Änderungen speichern", "Speichern"
+
+	calls := 0
+	translator := &fakeTranslator{translate: func(ctx context.Context, src, lang string) (string, error) {
+		calls++
+		if calls == 1 {
+			return long, nil
+		}
+		return short, nil
+	}}
+	target := &parser.LocaleFileContent{Path: "/locales/de.json", Code: "de", Lang: "German", LocaleItemsMap: map[string]string{"intro": long}}
+	require.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+	assert.Equal(t, short, target.LocaleItemsMap["button/save"])
+	assert.Equal(t, 2, calls)
+
+	translator = &fakeTranslator{
+		batch: func(ctx context.Context, srcs []string, lang string) ([]string, error) {
+			return []string{long, long}, nil
+		},
+		translate: func(ctx context.Context, src, lang string) (string, error) { return short, nil },
+	}
+	target = &parser.LocaleFileContent{Path: "/locales/de.json", Code: "de", Lang: "German", LocaleItemsMap: map[string]string{}}
+	require.NoError(t, batch_process(context.Background(), translator, source, target, nil, 10, opts))
+	assert.Equal(t, short, target.LocaleItemsMap["button/save"])
+	// Keys without a limit keep their translation
+	assert.Equal(t, long, target.LocaleItemsMap["intro"])
+
+	assert.Equal(t, "Your translation has 20 characters, over the limit of 12. Shorten it to 12 characters at most, with shorter words or the abbreviations usual in the target language, keeping its meaning and placeholders.", correctionFor(opts.checkLength("button/save", long)))
+	assert.NoError(t, opts.checkLength("intro", long))
+	assert.Equal(t, `The translations of these keys are shown in a limited space: keep each within its number of characters, shortening it if needed: "button/save": 12.`, opts.batchLengthInstruction([]string{"intro", "button/save"}))
+}
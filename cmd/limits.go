@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// applyFileLimits sets the limits locale files are parsed with from the configuration
+// file of cmd, if it has one that sets them. A configuration that can't be read is left
+// to the command to report.
+func applyFileLimits(cmd *cobra.Command) {
+	parser.FileLimits = parser.DefaultLimits
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		return
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err == nil && cfg.Limits != nil {
+		parser.FileLimits = *cfg.Limits
+	}
+}
+
+// warnLimits reports the file limits catalogs exceed, which aren't enforced outside
+// strict mode
+func warnLimits(catalogs ...*parser.LocaleFileContent) {
+	for _, catalog := range catalogs {
+		if len(catalog.Exceeded) == 0 {
+			continue
+		}
+		fmt.Printf("⚠️ %s doesn't look like a catalog: %s. Check the file, or raise the limits in the config file (\"limits\")\n", catalog.Path, strings.Join(catalog.Exceeded, "; "))
+	}
+}
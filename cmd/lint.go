@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/escaping"
+	"github.com/pandodao/i18n-cli/internal/lint"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:         "lint",
+	Short:       "Check the source catalog for strings that translate badly",
+	Long:        `Check the source catalog before translating it: fragments concatenated with other text, stray whitespace, embedded line breaks, inconsistent capitalization of labels, developer debug strings, keys defined twice in the same object, and values that look like secrets (API keys, tokens, credentials, emails, internal URLs). With --root, target catalogs are also checked for placeholders and ICU arguments not kept, HTML tags and Markdown dropped or misnested, values identical to their source or left empty, stale keys the source no longer has, double-escaped values, duplicate keys, secrets, distinct keys sharing a translation while their source texts differ, glossary terms translated otherwise than the configured glossary requires, values breaking the message syntax of the configured framework (i18next, Angular or vue-i18n), number and date examples copied in the source format where the target language writes them otherwise (1,234.56 for 1.234,56 in German), and template variables changed in translation or missing from the configured values file. Any file is checked for invalid JSON escapes first, which make it unreadable. Exits with a non-zero status when issues are found.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceFile, _ := cmd.Flags().GetString("file")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		format, _ := cmd.Flags().GetString("format")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+		secretOpts := secrets.Options{}
+		var syntax escaping.Framework
+		if cfg != nil {
+			secretOpts = cfg.Secrets
+			syntax = cfg.Framework
+		}
+		terms, err := loadGlossary(cfg)
+		if err != nil {
+			fmt.Printf("❌ Error reading glossary: %v\n", err)
+			os.Exit(1)
+		}
+		values, err := loadValues(cfg)
+		if err != nil {
+			fmt.Printf("❌ Error reading values file: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Collect the source files, and the target files to check for duplicates
+		files := []string{}
+		pairs := []scanner.FilePair{}
+		switch {
+		case sourceFile != "":
+			files = append(files, sourceFile)
+		case rootDir != "":
+			ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+			if err != nil {
+				fmt.Printf("❌ Error scanning directory: %v\n", err)
+				os.Exit(1)
+			}
+			for _, fileType := range ds.FileTypes {
+				files = append(files, ds.Path(sourceLang, fileType))
+			}
+			all, err := ds.GetPairs()
+			if err != nil {
+				fmt.Printf("❌ Error getting file pairs: %v\n", err)
+				os.Exit(1)
+			}
+			targets := selectTargetLanguages(ds, cfg)
+			for _, pair := range all {
+				if containsString(targets, pair.TargetLang) {
+					pairs = append(pairs, pair)
+				}
+			}
+		default:
+			fmt.Println("❌ Either --root or --file is required")
+			os.Exit(1)
+		}
+
+		results := make(map[string][]lint.Issue)
+		total := 0
+		// Files with invalid escapes can't be parsed, so their other checks are skipped
+		broken := map[string]bool{}
+		for _, file := range append(append([]string{}, files...), targetFiles(pairs)...) {
+			if issues := escapeIssues(file); len(issues) > 0 {
+				results[file] = issues
+				total += len(issues)
+				broken[file] = true
+			}
+		}
+		for _, file := range files {
+			if broken[file] {
+				continue
+			}
+			issues, err := lintSourceFile(file, secretOpts)
+			if err != nil {
+				fmt.Printf("❌ Error reading source file %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if len(issues) > 0 {
+				results[file] = issues
+				total += len(issues)
+			}
+		}
+
+		for _, pair := range pairs {
+			if broken[pair.SourceFile] || broken[pair.TargetFile] {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ Error loading pair: %v\n", err)
+				os.Exit(1)
+			}
+			issues := duplicateKeyIssues(target)
+			issues = append(issues, lint.Target(source.LocaleItemsMap, target.LocaleItemsMap)...)
+			issues = append(issues, lint.Duplicates(source.LocaleItemsMap, target.LocaleItemsMap)...)
+			issues = append(issues, lint.Secrets(target.LocaleItemsMap, secretOpts)...)
+			issues = append(issues, lint.Glossary(source.LocaleItemsMap, target.LocaleItemsMap, terms, pair.TargetLang)...)
+			issues = append(issues, lint.Framework(source.LocaleItemsMap, target.LocaleItemsMap, syntax)...)
+			issues = append(issues, lint.Formats(source.LocaleItemsMap, target.LocaleItemsMap, pair.SourceLang, pair.TargetLang)...)
+			issues = append(issues, lint.Templates(source.LocaleItemsMap, target.LocaleItemsMap, values)...)
+			if len(issues) > 0 {
+				results[pair.TargetFile] = issues
+				total += len(issues)
+			}
+		}
+		checked := len(files) + len(pairs)
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		} else {
+			paths := make([]string, 0, len(results))
+			for path := range results {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			for _, path := range paths {
+				fmt.Printf("📄 %s\n", path)
+				for _, issue := range results[path] {
+					fmt.Printf("  - %s [%s] %s\n", issue.Key, issue.Rule, issue.Message)
+				}
+			}
+			if total == 0 {
+				fmt.Printf("✅ No issues in %d files\n", checked)
+			} else {
+				fmt.Printf("\n⚠️ Found %d issues in %d of %d files\n", total, len(results), checked)
+			}
+		}
+
+		if total > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// lintSourceFile checks the values of a source file
+func lintSourceFile(path string, secretOpts secrets.Options) ([]lint.Issue, error) {
+	source := &parser.LocaleFileContent{Path: path}
+	if err := source.ParseContent(); err != nil {
+		return nil, err
+	}
+	issues := duplicateKeyIssues(source)
+	issues = append(issues, lint.Source(source.LocaleItemsMap)...)
+	return append(issues, lint.Secrets(source.LocaleItemsMap, secretOpts)...), nil
+}
+
+// escapeIssues reports the invalid JSON escapes of the file at path, none when it
+// can't be read
+func escapeIssues(path string) []lint.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return lint.Escapes(data)
+}
+
+// targetFiles returns the target files of pairs
+func targetFiles(pairs []scanner.FilePair) []string {
+	files := make([]string, len(pairs))
+	for i, pair := range pairs {
+		files[i] = pair.TargetFile
+	}
+	return files
+}
+
+// duplicateKeyIssues reports the keys defined more than once in a catalog, with their lines
+func duplicateKeyIssues(catalog *parser.LocaleFileContent) []lint.Issue {
+	issues := []lint.Issue{}
+	for _, d := range catalog.Duplicates {
+		issues = append(issues, lint.Issue{Key: d.Key, Rule: lint.RuleDuplicateKey, Message: fmt.Sprintf("defined on line %d and again on line %d, only the last value is kept", d.FirstLine, d.Line)})
+	}
+	return issues
+}
+
+func init() {
+	lintCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	lintCmd.Flags().String("file", "", "Single source file to check (instead of --root)")
+	lintCmd.Flags().String("source", "en", "Source language code (default: en)")
+	lintCmd.Flags().String("config", "", "Path to configuration file")
+	lintCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	rootCmd.AddCommand(lintCmd)
+}
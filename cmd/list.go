@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+)
+
+// listDelimiter returns the delimiter of the allowed values listed by the value of key,
+// empty when the key isn't a list or the value has a single item
+func (o processOptions) listDelimiter(key, value string) string {
+	delimiter := o.Keys.Lookup(key).ListDelimiter
+	if delimiter == "" || !strings.Contains(value, delimiter) {
+		return ""
+	}
+	return delimiter
+}
+
+// translateList translates the items of a list of allowed values one at a time and
+// joins them again in the same order, keeping the spacing around delimiters. An item
+// translated as several items fails the list, so translations always have as many
+// items as the source.
+func translateList(ctx context.Context, gptHandler Translator, value, delimiter string, target *parser.LocaleFileContent, opts processOptions) (string, error) {
+	items := strings.Split(value, delimiter)
+	for i, item := range items {
+		text := strings.TrimSpace(item)
+		if text == "" {
+			continue
+		}
+		translated, err := translateText(ctx, gptHandler, text, target, opts)
+		if err != nil {
+			return "", err
+		}
+		translated = strings.TrimSpace(translated)
+		if translated == "" || strings.Contains(translated, delimiter) {
+			return "", fmt.Errorf("item %d of %d (%q) translated as %q, not a single item", i+1, len(items), text, translated)
+		}
+		start := strings.Index(item, text)
+		items[i] = item[:start] + translated + item[start+len(text):]
+	}
+	return strings.Join(items, delimiter), nil
+}
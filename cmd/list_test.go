@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/keymeta"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTranslateList tests that lists of allowed values are translated item by item,
+// outside batches, and fail when an item is translated as several, counting the
+// failed list once in the run
+func TestTranslateList(t *testing.T) {
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{
+		"priority": "Low | Medium | High",
+		"size":     "Small|Large",
+		"title":    "Priority",
+	}}
+	translations := map[string]string{"Low": "Niedrig", "Medium": "Mittel", "High": "Hoch", "Small": "Klein", "Large": "Groß|Gross"}
+	keys := keymeta.Rules{"priority": {ListDelimiter: "|"}, "size": {ListDelimiter: "|"}}
+
+	for _, batch := range []int{0, 5} {
+		target := &parser.LocaleFileContent{Code: "de", Lang: "Deutsch", Path: "/locales/de.json", LocaleItemsMap: map[string]string{}}
+		translator := &fakeTranslator{translate: mockTranslate("Deutsch", translations), batch: mockBatchTranslate("Deutsch", nil)}
+		opts := processOptions{Mode: "missing", Marker: marker.Default(), Keys: keys, Clock: fixedClock{testTime}, FS: memFS{}, Journal: &runRecord{}}
+		var err error
+		if batch > 0 {
+			err = batch_process(context.Background(), translator, source, target, nil, batch, opts)
+		} else {
+			err = single_process(context.Background(), translator, source, target, nil, opts)
+		}
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Niedrig | Mittel | Hoch", target.LocaleItemsMap["priority"], "batch %d", batch)
+		assert.Empty(t, target.LocaleItemsMap["size"], "batch %d", batch)
+		assert.Equal(t, "TRANSLATED:Priority", target.LocaleItemsMap["title"], "batch %d", batch)
+		assert.Subset(t, translator.sent, []string{"Low", "Medium", "High", "Small", "Large"})
+		assert.NotContains(t, translator.sent, "Low | Medium | High")
+		assert.Equal(t, []journal.File{{Path: "/locales/de.json", Keys: 3, Translated: 2, Failed: []string{"size"}}}, opts.Journal.run.Files, "batch %d", batch)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pandodao/i18n-cli/internal/lock"
+)
+
+var forceUnlock bool // Remove an existing project lock before running
+
+// interruptRun cancels the context of the running command, set by Execute
+var interruptRun context.CancelFunc = func() {}
+
+// acquireProjectLock takes the lock of the project in dir so concurrent runs don't
+// clobber each other's writes. The lock is released by the returned function. While
+// it is held an interrupt cancels the context of the command instead of killing the
+// process, so that the command unwinds, finishing the files it is writing, and
+// releases the lock; a second interrupt kills it.
+func acquireProjectLock(dir string) (func(), error) {
+	path := filepath.Join(dir, lock.FileName)
+
+	if forceUnlock {
+		info, removed, err := lock.ForceUnlock(path)
+		if err != nil {
+			return nil, err
+		}
+		if removed {
+			fmt.Printf("🔓 Removed lock held by pid %d on %s\n", info.PID, info.Host)
+		}
+	}
+
+	l, err := lock.Acquire(path, strings.Join(os.Args, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Println("\n⚠️ Interrupted, stopping once the files being written are complete")
+			interruptRun()
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		l.Release()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into
+// place, so an interrupted run never leaves a half-written file behind
+func writeFileAtomic(path string, data []byte) error {
+	if err := checkWritable(path); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
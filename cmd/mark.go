@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/spf13/cobra"
+)
+
+var markCmd = &cobra.Command{
+	Use:   "mark <file> <key>...",
+	Short: "Flag keys for retranslation",
+	Long:  `Flag keys of a target locale file for retranslation using the configured marker style. Flagged keys are retranslated by the next run in full mode.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runMark(cmd, args, true)
+	},
+}
+
+var unmarkCmd = &cobra.Command{
+	Use:   "unmark <file> <key>...",
+	Short: "Remove retranslation flags from keys",
+	Long:  `Remove the retranslation flag from keys of a target locale file using the configured marker style.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runMark(cmd, args, false)
+	},
+}
+
+func runMark(cmd *cobra.Command, args []string, mark bool) {
+	configPath, _ := cmd.Flags().GetString("config")
+
+	m := marker.Default()
+	if configPath != "" {
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		m = cfg.Marker
+	}
+	if m.Style == marker.StyleNone {
+		fmt.Println("❌ Retranslation markers are disabled in the configuration")
+		return
+	}
+
+	target := &parser.LocaleFileContent{Path: args[0]}
+	if err := target.ParseContent(); err != nil {
+		fmt.Printf("❌ Error reading %s: %v\n", args[0], err)
+		return
+	}
+
+	keys := args[1:]
+	var skipped []string
+	var err error
+	if mark {
+		skipped, err = m.Mark(target, keys)
+	} else {
+		skipped, err = m.Unmark(target, keys)
+	}
+	if err != nil {
+		fmt.Printf("❌ Error updating markers: %v\n", err)
+		return
+	}
+
+	auditProvider = "unmark"
+	if mark {
+		auditProvider = "mark"
+	}
+
+	// Prefix and suffix markers live in the locale file itself
+	if m.Style != marker.StyleSidecar {
+		if err := writeLocaleFile(target); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", target.Path, err)
+			return
+		}
+	}
+
+	for _, k := range skipped {
+		if mark {
+			fmt.Printf("⚠️ Key %s not found in %s\n", k, target.Path)
+		} else {
+			fmt.Printf("⚠️ Key %s is not flagged in %s\n", k, target.Path)
+		}
+	}
+
+	changed := len(keys) - len(skipped)
+	if mark {
+		fmt.Printf("✅ Flagged %d keys for retranslation in %s\n", changed, target.Path)
+	} else {
+		fmt.Printf("✅ Removed the retranslation flag from %d keys in %s\n", changed, target.Path)
+	}
+}
+
+func init() {
+	markCmd.Flags().String("config", "", "Path to configuration file")
+	unmarkCmd.Flags().String("config", "", "Path to configuration file")
+
+	rootCmd.AddCommand(markCmd)
+	rootCmd.AddCommand(unmarkCmd)
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+var markdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Translate Markdown content files",
+	Long:  `Translate the Markdown files of content/<source>/ into localized copies under content/<lang>/, as used by Hugo and Docusaurus sites. Only the configured frontmatter fields (title and description by default) are translated, and the body with --body; everything else is copied as is. Fenced code blocks, shortcodes and HTML comments are never translated.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		contentDir, _ := cmd.Flags().GetString("content")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		targetLangs, _ := cmd.Flags().GetStringSlice("targets")
+		mode, _ := cmd.Flags().GetString("mode")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+
+		opts := markdown.Options{Fields: markdown.DefaultFields}
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			if len(targetLangs) == 0 {
+				targetLangs = cfg.TargetLangs
+			}
+			if len(cfg.Markdown.Fields) > 0 {
+				opts.Fields = cfg.Markdown.Fields
+			}
+			opts.Body = cfg.Markdown.Body
+		}
+		if cmd.Flags().Changed("fields") {
+			opts.Fields, _ = cmd.Flags().GetStringSlice("fields")
+		}
+		if cmd.Flags().Changed("body") {
+			opts.Body, _ = cmd.Flags().GetBool("body")
+		}
+		if len(targetLangs) == 0 {
+			fmt.Println("❌ No target languages: use --targets or set targetLangs in the config file")
+			return
+		}
+
+		sourceDir := filepath.Join(contentDir, sourceLang)
+		files, err := markdownFiles(sourceDir)
+		if err != nil {
+			fmt.Printf("❌ Error reading %s: %v\n", sourceDir, err)
+			return
+		}
+		fmt.Printf("✅ Found %d Markdown files in %s\n", len(files), sourceDir)
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			return
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			return
+		}
+
+		release, err := acquireProjectLock(contentDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		defer release()
+
+		ctx := cmd.Context()
+		written, skipped, failed := 0, 0, 0
+		for _, lang := range targetLangs {
+			langName, err := parser.LangCodeToName(lang)
+			if err != nil {
+				fmt.Printf("⚠️ %v. skip this language.\n", err)
+				continue
+			}
+
+			for _, rel := range files {
+				targetPath := filepath.Join(contentDir, lang, rel)
+				if _, err := os.Stat(targetPath); err == nil && mode != "full" {
+					skipped++
+					continue
+				}
+
+				target := &parser.LocaleFileContent{Code: lang, Lang: langName, Path: targetPath}
+				fmt.Printf("🔄 %s -> %s\n", filepath.Join(sourceDir, rel), targetPath)
+				if err := translateMarkdownFile(gpt.WithLanguages(ctx, sourceLang, lang), gptHandler, filepath.Join(sourceDir, rel), target, opts); err != nil {
+					fmt.Printf("❌ %s: %v\n", targetPath, err)
+					failed++
+					continue
+				}
+				written++
+			}
+		}
+
+		recordThroughput(gptHandler, false)
+		fmt.Printf("\n✅ %d files written, %d existing files skipped, %d failed\n", written, skipped, failed)
+	},
+}
+
+// markdownFiles returns the paths of the Markdown files under dir, relative to dir
+func markdownFiles(dir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown", ".mdx":
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// translateMarkdownFile writes the translation of a Markdown file to target
+func translateMarkdownFile(ctx context.Context, gptHandler *gpt.Handler, sourcePath string, target *parser.LocaleFileContent, opts markdown.Options) error {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	doc, err := markdown.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range opts.Fields {
+		value, ok := doc.Field(field)
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		translated, err := translateText(ctx, gptHandler, value, target, processOptions{})
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field, err)
+		}
+		doc.SetField(field, translated)
+	}
+
+	if opts.Body {
+		var body strings.Builder
+		for _, block := range markdown.Blocks(doc.Body) {
+			if block.Verbatim {
+				body.WriteString(block.Text)
+				continue
+			}
+			text := strings.TrimRight(block.Text, "\r\n")
+			translated, err := translateText(ctx, gptHandler, text, target, processOptions{})
+			if err != nil {
+				return fmt.Errorf("body: %w", err)
+			}
+			body.WriteString(translated + block.Text[len(text):])
+		}
+		doc.Body = body.String()
+	}
+
+	out, err := doc.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target.Path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(target.Path, out)
+}
+
+func init() {
+	markdownCmd.Flags().String("content", "content", "Content directory containing one subdirectory per language")
+	markdownCmd.Flags().String("source", "en", "Source language code (default: en)")
+	markdownCmd.Flags().StringSlice("targets", nil, "Target language codes (default: targetLangs of the config file)")
+	markdownCmd.Flags().StringSlice("fields", nil, "Frontmatter fields to translate, nested fields separated by dots (default: title,description)")
+	markdownCmd.Flags().Bool("body", false, "Translate the body of the documents too")
+	markdownCmd.Flags().String("config", "", "Path to configuration file")
+	markdownCmd.Flags().String("mode", "missing", "Translation mode: 'full' (translate all files) or 'missing' (only create missing localized copies)")
+	markdownCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the project lock left by another run before starting")
+
+	rootCmd.AddCommand(markdownCmd)
+}
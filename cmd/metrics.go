@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+// syncMetrics collects the metrics of watch mode, nil when they are not served
+var syncMetrics *metrics.Registry
+
+// Metrics served in watch mode
+const (
+	metricCompletion     = "i18n_catalog_completion_ratio"
+	metricSourceKeys     = "i18n_catalog_source_keys"
+	metricTranslatedKeys = "i18n_catalog_translated_keys"
+	metricKeysTranslated = "i18n_keys_translated_total"
+	metricRequests       = "i18n_provider_requests_total"
+	metricErrors         = "i18n_provider_errors_total"
+	metricTokens         = "i18n_provider_tokens_total"
+	metricCost           = "i18n_provider_cost_usd_total"
+	metricSyncs          = "i18n_syncs_total"
+	metricLastSync       = "i18n_last_sync_timestamp_seconds"
+)
+
+// newSyncMetrics returns a registry declaring the metrics of watch mode
+func newSyncMetrics() *metrics.Registry {
+	r := metrics.New()
+	r.Describe(metricCompletion, metrics.Gauge, "Share of source keys translated, by target language")
+	r.Describe(metricSourceKeys, metrics.Gauge, "Number of source keys to translate, by target language")
+	r.Describe(metricTranslatedKeys, metrics.Gauge, "Number of source keys translated, by target language")
+	r.Describe(metricKeysTranslated, metrics.Counter, "Keys newly translated by syncs, by target language")
+	r.Describe(metricRequests, metrics.Counter, "Successful provider requests")
+	r.Describe(metricErrors, metrics.Counter, "Failed provider requests, retries included")
+	r.Describe(metricTokens, metrics.Counter, "Tokens spent, by kind (prompt or completion)")
+	r.Describe(metricCost, metrics.Counter, "Cost of the tokens spent in USD, for models with a known price")
+	r.Describe(metricSyncs, metrics.Counter, "Syncs run")
+	r.Describe(metricLastSync, metrics.Gauge, "Unix time of the end of the last sync")
+	return r
+}
+
+// metricsAddr returns the listen address of the metrics endpoint from the
+// --metrics-addr flag, or from the config file when the flag isn't set
+func metricsAddr(cmd *cobra.Command, cfg *config.Config) string {
+	addr, _ := cmd.Flags().GetString("metrics-addr")
+	if !cmd.Flags().Changed("metrics-addr") && cfg.Watch != nil {
+		addr = cfg.Watch.MetricsAddr
+	}
+	return addr
+}
+
+// serveMetrics serves the metrics of r on addr at /metrics until the process exits
+func serveMetrics(addr string, r *metrics.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	fmt.Printf("📈 Serving Prometheus metrics on http://%s/metrics\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Printf("❌ Metrics server stopped: %v\n", err)
+	}
+}
+
+// recordSyncMetrics records the usage of a finished sync, the keys it translated by
+// target language, and the completion of every catalog of rootDir
+func recordSyncMetrics(r *metrics.Registry, rootDir string, cfg *config.Config, gptHandler *gpt.Handler, translated map[string]int) {
+	provider, model := gptHandler.Provider(), gptHandler.Model()
+	usage := gptHandler.Usage()
+	r.Add(metricRequests, float64(usage.Requests), "provider", provider, "model", model)
+	r.Add(metricErrors, float64(usage.Errors), "provider", provider, "model", model)
+	r.Add(metricTokens, float64(usage.PromptTokens), "provider", provider, "model", model, "kind", "prompt")
+	r.Add(metricTokens, float64(usage.CompletionTokens), "provider", provider, "model", model, "kind", "completion")
+	if cost, ok := gpt.Cost(model, usage.PromptTokens, usage.CompletionTokens); ok {
+		r.Add(metricCost, cost, "provider", provider, "model", model)
+	}
+	for lang, n := range translated {
+		r.Add(metricKeysTranslated, float64(n), "lang", lang)
+	}
+	r.Add(metricSyncs, 1)
+	r.Set(metricLastSync, float64(time.Now().Unix()))
+
+	recordCompletion(r, rootDir, cfg)
+}
+
+// recordCompletion sets the completion gauges of the target languages of rootDir.
+// Watch mode only syncs the changed files, so every catalog is read again.
+func recordCompletion(r *metrics.Registry, rootDir string, cfg *config.Config) {
+	ds, err := scanCatalogs(rootDir, cfg.SourceLang, cfg)
+	if err != nil {
+		fmt.Printf("⚠️ Could not update completion metrics: %v\n", err)
+		return
+	}
+	pairs, err := ds.GetPairs()
+	if err != nil {
+		fmt.Printf("⚠️ Could not update completion metrics: %v\n", err)
+		return
+	}
+
+	targets := selectTargetLanguages(ds, cfg)
+	sourceKeys, translatedKeys := map[string]int{}, map[string]int{}
+	for _, pair := range pairs {
+		if !containsString(targets, pair.TargetLang) {
+			continue
+		}
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			continue
+		}
+		sourceKeys[pair.TargetLang] += len(source.LocaleItemsMap)
+		translatedKeys[pair.TargetLang] += countTranslatedKeys(source.LocaleItemsMap, target.LocaleItemsMap)
+	}
+
+	// Languages removed since the last sync disappear rather than keep their last value
+	for _, name := range []string{metricCompletion, metricSourceKeys, metricTranslatedKeys} {
+		r.Reset(name)
+	}
+	for lang, total := range sourceKeys {
+		ratio := 1.0
+		if total > 0 {
+			ratio = float64(translatedKeys[lang]) / float64(total)
+		}
+		r.Set(metricCompletion, ratio, "lang", lang)
+		r.Set(metricSourceKeys, float64(total), "lang", lang)
+		r.Set(metricTranslatedKeys, float64(translatedKeys[lang]), "lang", lang)
+	}
+}
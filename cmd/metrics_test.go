@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecordSyncMetrics tests the completion gauges and the counters of a sync
+func TestRecordSyncMetrics(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"en/common.json": `{"a": "A", "b": "B", "c": "C", "d": "D"}`,
+		"fr/common.json": `{"a": "A-fr", "b": "B-fr", "c": ""}`,
+		"de/common.json": `{}`,
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0644))
+	}
+	cfg := config.DefaultConfig()
+	cfg.SourceLang = "en"
+
+	r := newSyncMetrics()
+	recordSyncMetrics(r, root, cfg, gpt.New(gpt.Config{}), map[string]int{"fr": 2})
+
+	assert.Equal(t, 0.5, r.Value(metricCompletion, "lang", "fr"))
+	assert.Equal(t, 0.0, r.Value(metricCompletion, "lang", "de"))
+	assert.Equal(t, 4.0, r.Value(metricSourceKeys, "lang", "de"))
+	assert.Equal(t, 2.0, r.Value(metricKeysTranslated, "lang", "fr"))
+	assert.Equal(t, 1.0, r.Value(metricSyncs))
+	assert.Equal(t, 0.0, r.Value(metricCompletion, "lang", "en"))
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/onboarding"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var approveSampleCmd = &cobra.Command{
+	Use:   "approve-sample <lang>...",
+	Short: "Sign off the translated sample of new languages",
+	Long:  `With onboarding enabled in the configuration, sync translates only a sample of the keys of a new language and exports it for review. Once the sample reads well, approve-sample lets the next sync translate the rest of the language.`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		records, err := onboarding.Load()
+		if err != nil {
+			fmt.Printf("❌ Error reading onboarding state: %v\n", err)
+			return
+		}
+
+		for _, lang := range args {
+			name, ok := onboardingRecord(records, lang)
+			if !ok || records[name].Status != onboarding.StatusPending {
+				fmt.Printf("❌ No sample of %s waits for sign-off\n", lang)
+				continue
+			}
+			record := records[name]
+			now := systemClock{}.Now()
+			record.Status, record.ApprovedAt = onboarding.StatusApproved, &now
+			records[name] = record
+			fmt.Printf("✅ Approved the sample of %s, the next sync translates the rest\n", name)
+		}
+
+		if err := onboarding.Save(records); err != nil {
+			fmt.Printf("❌ Error saving onboarding state: %v\n", err)
+		}
+	},
+}
+
+// onboardingRecord returns the language of records matching lang, regardless of case
+func onboardingRecord(records map[string]onboarding.Record, lang string) (string, bool) {
+	for name := range records {
+		if scanner.SameName(name, lang) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// onboardLanguages returns the pairs that may be translated in full. Languages without
+// any translation yet are new: a sample of their keys is translated and exported for
+// review, and their pairs are held back until the sample is signed off.
+func onboardLanguages(ctx context.Context, gptHandler Translator, pairs []scanner.FilePair, settings *config.Onboarding, batchSize int, opts processOptions) []scanner.FilePair {
+	records, err := onboarding.Load()
+	if err != nil {
+		fmt.Printf("❌ Error reading onboarding state: %v\n", err)
+		return nil
+	}
+
+	langs := []string{}
+	byLang := map[string][]scanner.FilePair{}
+	for _, pair := range pairs {
+		if _, ok := byLang[pair.TargetLang]; !ok {
+			langs = append(langs, pair.TargetLang)
+		}
+		byLang[pair.TargetLang] = append(byLang[pair.TargetLang], pair)
+	}
+
+	allowed := []scanner.FilePair{}
+	for _, lang := range langs {
+		if name, ok := onboardingRecord(records, lang); ok {
+			if records[name].Status == onboarding.StatusApproved {
+				allowed = append(allowed, byLang[lang]...)
+			} else {
+				fmt.Printf("⏸️ %s waits for sign-off of its sample (%s), run i18n-cli approve-sample %s\n", lang, records[name].Export, lang)
+			}
+			continue
+		}
+
+		record, isNew, err := translateSample(ctx, gptHandler, lang, byLang[lang], settings, batchSize, opts)
+		if err != nil {
+			fmt.Printf("❌ Error translating the sample of %s: %v\n", lang, err)
+			continue
+		}
+		if !isNew {
+			allowed = append(allowed, byLang[lang]...)
+			continue
+		}
+		records[lang] = record
+		if err := onboarding.Save(records); err != nil {
+			fmt.Printf("❌ Error saving onboarding state: %v\n", err)
+			return nil
+		}
+		fmt.Printf("📋 Translated a sample of %d keys of %s, exported to %s for review; run i18n-cli approve-sample %s to translate the rest\n", record.Keys, lang, record.Export, lang)
+	}
+	return allowed
+}
+
+// translateSample translates and exports a sample of the keys of lang when it has no
+// translation yet, reporting whether it did
+func translateSample(ctx context.Context, gptHandler Translator, lang string, pairs []scanner.FilePair, settings *config.Onboarding, batchSize int, opts processOptions) (onboarding.Record, bool, error) {
+	sources := map[string]*parser.LocaleFileContent{}
+	targets := map[string]*parser.LocaleFileContent{}
+	candidates := []onboarding.Key{}
+	for _, pair := range pairs {
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			return onboarding.Record{}, false, fmt.Errorf("error loading pair: %w", err)
+		}
+		if countTranslatedKeys(source.LocaleItemsMap, target.LocaleItemsMap) > 0 {
+			return onboarding.Record{}, false, nil
+		}
+		sources[target.Path], targets[target.Path] = source, target
+		for k, v := range source.LocaleItemsMap {
+			if v != "" {
+				candidates = append(candidates, onboarding.Key{File: target.Path, Key: k})
+			}
+		}
+	}
+
+	size := settings.SampleSize
+	if size == 0 {
+		size = onboarding.DefaultSampleSize
+	}
+	sample := onboarding.Sample(candidates, size, opts.Priority)
+	byFile := map[string]map[string]string{}
+	for _, k := range sample {
+		if byFile[k.File] == nil {
+			byFile[k.File] = map[string]string{}
+		}
+		byFile[k.File][k.Key] = sources[k.File].LocaleItemsMap[k.Key]
+	}
+
+	opts.Mode = "missing"
+	paths := make([]string, 0, len(byFile))
+	for path := range byFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		restricted := *sources[path]
+		restricted.LocaleItemsMap = byFile[path]
+		var err error
+		if batchSize > 0 {
+			err = batch_process(ctx, gptHandler, &restricted, targets[path], nil, batchSize, opts)
+		} else {
+			err = single_process(ctx, gptHandler, &restricted, targets[path], nil, opts)
+		}
+		if err != nil {
+			return onboarding.Record{}, false, err
+		}
+	}
+
+	export := filepath.Join(settings.ExportDir, lang+"-sample.csv")
+	if err := exportSample(export, sample, sources, targets); err != nil {
+		return onboarding.Record{}, false, fmt.Errorf("error exporting the sample: %w", err)
+	}
+	return onboarding.Record{Status: onboarding.StatusPending, Keys: len(sample), Export: export, SampledAt: opts.clock().Now()}, true, nil
+}
+
+// exportSample writes the sample as CSV: file, key, source text and translation
+func exportSample(path string, sample []onboarding.Key, sources, targets map[string]*parser.LocaleFileContent) error {
+	var output strings.Builder
+	w := csv.NewWriter(&output)
+	w.Write([]string{"file", "key", "source", "translation"})
+	for _, k := range sample {
+		w.Write([]string{k.File, k.Key, sources[k.File].LocaleItemsMap[k.Key], targets[k.File].LocaleItemsMap[k.Key]})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(output.String()), 0644)
+}
+
+func init() {
+	approveSampleCmd.Flags().String("root", "", "Root directory of the catalogs whose samples to approve")
+	approveSampleCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(approveSampleCmd)
+}
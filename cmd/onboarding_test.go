@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/onboarding"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOnboardLanguages tests that new languages get a sample translated and exported,
+// and are held back until it is approved, while existing languages go through
+func TestOnboardLanguages(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	root := filepath.Join(dir, "locales")
+	for lang, content := range map[string]string{
+		"en": `{"a": "One", "b": "Two", "c": "Three", "d": "Four"}`,
+		"de": `{"a": "Eins"}`,
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, lang), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, lang, "app.json"), []byte(content), 0644))
+	}
+	pairs := []scanner.FilePair{
+		{SourceFile: filepath.Join(root, "en", "app.json"), TargetFile: filepath.Join(root, "fr", "app.json"), SourceLang: "en", TargetLang: "fr"},
+		{SourceFile: filepath.Join(root, "en", "app.json"), TargetFile: filepath.Join(root, "de", "app.json"), SourceLang: "en", TargetLang: "de"},
+	}
+
+	translator := &fakeTranslator{translate: mockTranslate("fr", nil)}
+	settings := &config.Onboarding{SampleSize: 2, ExportDir: dir}
+	opts := processOptions{Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}}
+
+	allowed := onboardLanguages(context.Background(), translator, pairs, settings, 0, opts)
+	assert.Equal(t, pairs[1:], allowed)
+	assert.Equal(t, []string{"One", "Three"}, translator.sent)
+	export, err := os.ReadFile(filepath.Join(dir, "fr-sample.csv"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(export), "a,One,TRANSLATED:One\n")
+
+	// Held back without translating again until approved
+	assert.Equal(t, pairs[1:], onboardLanguages(context.Background(), translator, pairs, settings, 0, opts))
+	assert.Len(t, translator.sent, 2)
+
+	records, err := onboarding.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, onboarding.StatusPending, records["fr"].Status)
+	assert.Equal(t, testTime, records["fr"].SampledAt)
+	record := records["fr"]
+	record.Status = onboarding.StatusApproved
+	records["fr"] = record
+	assert.NoError(t, onboarding.Save(records))
+	assert.Equal(t, pairs, onboardLanguages(context.Background(), translator, pairs, settings, 0, opts))
+}
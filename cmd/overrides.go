@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// overridesAgainstMT compares overrides with a machine translation of the source
+	overridesAgainstMT = "mt"
+	// overridesAgainstSource compares overrides with the source, for overrides written
+	// in the source language
+	overridesAgainstSource = "source"
+)
+
+var overridesCmd = &cobra.Command{
+	Use:   "overrides",
+	Short: "Report the independent overrides that differ substantially from the machine translation or the source",
+	Long: `Compare the values of an independent file, the manual translations translate --independent
+puts in place of machine translations, with what the machine translation of the current
+source would be (--against mt) or with the source itself (--against source, for overrides
+written in the source language). Overrides less similar than --threshold are listed in a
+markdown report for review, along with the overrides of keys the source no longer has, to
+catch stale overrides that no longer match updated source copy. Locale files are never
+modified. Exits with a non-zero status when overrides are flagged.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		sourceFile, _ := cmd.Flags().GetString("source")
+		indepFile, _ := cmd.Flags().GetString("independent")
+		against, _ := cmd.Flags().GetString("against")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		batch, _ := cmd.Flags().GetInt("batch")
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		if against != overridesAgainstMT && against != overridesAgainstSource {
+			fmt.Printf("❌ Unknown comparison %q, use %s or %s\n", against, overridesAgainstMT, overridesAgainstSource)
+			os.Exit(1)
+		}
+		source, indep := &parser.LocaleFileContent{}, &parser.LocaleFileContent{}
+		if err := source.ParseFromJSONFile(sourceFile); err != nil {
+			fmt.Printf("❌ Error reading source file: %v\n", err)
+			os.Exit(1)
+		}
+		if err := indep.ParseFromJSONFile(indepFile); err != nil {
+			fmt.Printf("❌ Error reading independent file: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries, orphans := overrideEntries(source.LocaleItemsMap, indep.LocaleItemsMap, indep.Code)
+		var comparisons []overrideComparison
+		if against == overridesAgainstSource {
+			comparisons = compareOverrides(entries, nil)
+		} else {
+			cfg, err := loadOptionalConfig(cmd)
+			if err != nil {
+				fmt.Printf("❌ Error loading configuration: %v\n", err)
+				os.Exit(1)
+			}
+			apiKeys := resolveAPIKeys(cfg)
+			if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+				fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+				os.Exit(1)
+			}
+			gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+			if err != nil {
+				fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+				os.Exit(1)
+			}
+			defer printUsageReport(gptHandler)
+
+			fmt.Printf("🔁 Machine translating the source of %d overrides into %s\n", len(entries), indep.Code)
+			ctx := gpt.WithLanguages(context.Background(), source.Code, indep.Code)
+			translations, err := machineTranslations(ctx, gptHandler, entries, indep.Lang, batch)
+			if err != nil {
+				fmt.Printf("❌ Error translating: %v\n", err)
+				os.Exit(1)
+			}
+			comparisons = compareOverrides(entries, translations)
+		}
+
+		flagged := []overrideComparison{}
+		for _, c := range comparisons {
+			if c.Similarity < threshold {
+				flagged = append(flagged, c)
+			}
+		}
+		report := overridesReport(indepFile, against, len(comparisons), flagged, orphans)
+		if outputPath != "" {
+			if err := os.WriteFile(outputPath, []byte(report), 0644); err != nil {
+				fmt.Printf("❌ Error writing report: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Overrides report saved to %s\n", outputPath)
+		} else {
+			fmt.Print("\n" + report)
+		}
+
+		if len(flagged) > 0 || len(orphans) > 0 {
+			fmt.Printf("\n⚠️ %d of %d overrides differ substantially, %d override keys the source no longer has\n", len(flagged), len(comparisons), len(orphans))
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ %d overrides match their source\n", len(comparisons))
+	},
+}
+
+// overrideComparison is an override compared with the machine translation or the source
+type overrideComparison struct {
+	tm.Entry
+	// Machine translation of the source, empty when compared with the source
+	Translation string
+	Similarity  float64
+}
+
+// overrideEntries returns the overrides of the keys of source, sorted by key, and the
+// sorted keys of the overrides the source doesn't have
+func overrideEntries(source, overrides map[string]string, lang string) ([]tm.Entry, []string) {
+	entries := []tm.Entry{}
+	orphans := []string{}
+	for k, v := range overrides {
+		text, ok := source[k]
+		switch {
+		case !ok:
+			orphans = append(orphans, k)
+		case !isBlank(text) && !isBlank(v):
+			entries = append(entries, tm.Entry{Key: k, Source: text, Target: v, Lang: lang})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	sort.Strings(orphans)
+	return entries, orphans
+}
+
+// machineTranslations translates the sources of entries into langName, batch texts per request
+func machineTranslations(ctx context.Context, h Translator, entries []tm.Entry, langName string, batch int) ([]string, error) {
+	if batch < 1 {
+		batch = 1
+	}
+	translations := make([]string, 0, len(entries))
+	for start := 0; start < len(entries); start += batch {
+		end := start + batch
+		if end > len(entries) {
+			end = len(entries)
+		}
+		keys, texts := []string{}, []string{}
+		for _, e := range entries[start:end] {
+			keys, texts = append(keys, e.Key), append(texts, e.Source)
+		}
+		results, err := h.BatchTranslateWithExamples(ctx, keys, texts, langName, nil)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, results...)
+	}
+	return translations, nil
+}
+
+// compareOverrides returns the similarity of each override with its machine
+// translation, or with its source when translations is nil
+func compareOverrides(entries []tm.Entry, translations []string) []overrideComparison {
+	comparisons := make([]overrideComparison, len(entries))
+	for i, e := range entries {
+		c := overrideComparison{Entry: e}
+		if translations == nil {
+			c.Similarity = tm.Similarity(e.Target, e.Source)
+		} else {
+			c.Translation = translations[i]
+			c.Similarity = tm.Similarity(e.Target, c.Translation)
+		}
+		comparisons[i] = c
+	}
+	return comparisons
+}
+
+// overridesReport returns the overrides report in markdown: the flagged overrides,
+// least similar first, then the keys the source no longer has
+func overridesReport(file, against string, compared int, flagged []overrideComparison, orphans []string) string {
+	var report strings.Builder
+	report.WriteString("# Independent Overrides Review\n\n")
+	report.WriteString(fmt.Sprintf("File: %s\nCompared with: %s\n\n", file, map[string]string{
+		overridesAgainstMT:     "machine translation of the source",
+		overridesAgainstSource: "source",
+	}[against]))
+	report.WriteString(fmt.Sprintf("%d overrides compared, %d differ substantially, %d keys no longer in the source\n", compared, len(flagged), len(orphans)))
+
+	if len(flagged) > 0 {
+		sort.SliceStable(flagged, func(i, j int) bool { return flagged[i].Similarity < flagged[j].Similarity })
+		report.WriteString("\n## Overrides to Review\n\n")
+		headers := []string{"Key", "Source", "Override", "Machine Translation", "Similarity"}
+		if against == overridesAgainstSource {
+			headers = []string{"Key", "Source", "Override", "Similarity"}
+		}
+		tbl := table.New(headers...)
+		tbl.MaxWidth = historyCellWidth
+		for _, c := range flagged {
+			similarity := fmt.Sprintf("%.2f", c.Similarity)
+			if against == overridesAgainstSource {
+				tbl.Add(c.Key, c.Source, c.Target, similarity)
+			} else {
+				tbl.Add(c.Key, c.Source, c.Target, c.Translation, similarity)
+			}
+		}
+		report.WriteString(tbl.String())
+	}
+	if len(orphans) > 0 {
+		report.WriteString("\n## Keys No Longer in the Source\n\n")
+		for _, k := range orphans {
+			report.WriteString("- " + k + "\n")
+		}
+	}
+	return report.String()
+}
+
+func init() {
+	overridesCmd.Flags().String("source", "", "The source language file")
+	overridesCmd.Flags().String("independent", "", "The independent file of manual translations")
+	overridesCmd.Flags().String("config", "", "Path to configuration file")
+	overridesCmd.Flags().String("against", overridesAgainstMT, "Compare overrides with the machine translation of the source ('mt') or with the source ('source')")
+	overridesCmd.Flags().Float64("threshold", 0.5, "Similarity below which an override differs substantially")
+	overridesCmd.Flags().Int("batch", 20, "Number of source texts machine translated per request")
+	overridesCmd.Flags().String("output", "", "Save the report to a file")
+
+	overridesCmd.MarkFlagRequired("source")
+	overridesCmd.MarkFlagRequired("independent")
+
+	rootCmd.AddCommand(overridesCmd)
+}
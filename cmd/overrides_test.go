@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOverrides tests that overrides far from the machine translation or the source
+// are flagged, and that overrides of removed keys are reported
+func TestOverrides(t *testing.T) {
+	source := map[string]string{
+		"cart.empty": "Your cart is empty",
+		"cart.title": "Shopping cart",
+		"home.title": "Welcome",
+	}
+	overrides := map[string]string{
+		"cart.empty": "Ihr Warenkorb ist leer",
+		"cart.title": "Merkliste",
+		"old.promo":  "Sommerschlussverkauf",
+	}
+	entries, orphans := overrideEntries(source, overrides, "de")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "cart.empty", entries[0].Key)
+	assert.Equal(t, []string{"old.promo"}, orphans)
+
+	h := &fakeTranslator{batch: func(ctx context.Context, srcs []string, lang string) ([]string, error) {
+		mt := map[string]string{"Your cart is empty": "Ihr Warenkorb ist leer", "Shopping cart": "Warenkorb"}
+		results := make([]string, len(srcs))
+		for i, src := range srcs {
+			results[i] = mt[src]
+		}
+		return results, nil
+	}}
+	translations, err := machineTranslations(context.Background(), h, entries, "German", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Your cart is empty", "Shopping cart"}, h.sent)
+
+	comparisons := compareOverrides(entries, translations)
+	assert.Equal(t, 1.0, comparisons[0].Similarity)
+	assert.Less(t, comparisons[1].Similarity, 0.5)
+
+	report := overridesReport("de.json", overridesAgainstMT, 2, comparisons[1:], orphans)
+	assert.Contains(t, report, "## Overrides to Review")
+	assert.Contains(t, report, "Merkliste")
+	assert.Contains(t, report, "- old.promo")
+
+	// Overrides in the source language are compared with the source itself
+	comparisons = compareOverrides([]tm.Entry{{Key: "home.title", Source: "Welcome to the new store", Target: "Welcome to the store"}}, nil)
+	assert.Greater(t, comparisons[0].Similarity, 0.5)
+	report = overridesReport("en-GB.json", overridesAgainstSource, 1, nil, nil)
+	assert.NotContains(t, report, "## Overrides to Review")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/pack"
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Package translated catalogs into deployment artifacts",
+	Long:  `Merge the catalogs of every language into a single JSON file per language and write them to an output directory as plain JSON, gzip bundles or a Go package embedding them, along with a manifest listing the version, key count and SHA-256 hash of every artifact.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		outDir, _ := cmd.Flags().GetString("out")
+		format, _ := cmd.Flags().GetString("format")
+		version, _ := cmd.Flags().GetString("version")
+		minify, _ := cmd.Flags().GetBool("minify")
+		pkg, _ := cmd.Flags().GetString("package")
+		fallback, _ := cmd.Flags().GetBool("fallback")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+		if pkg == "" {
+			pkg = packageName(outDir)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			return
+		}
+
+		source, err := mergedCatalog(ds, ds.SourceLang)
+		if err != nil {
+			fmt.Printf("❌ Error reading %s: %v\n", ds.SourceLang, err)
+			return
+		}
+		catalogs := []pack.Catalog{{Lang: ds.SourceLang, Items: source}}
+		for _, lang := range selectTargetLanguages(ds, cfg) {
+			items, err := mergedCatalog(ds, lang)
+			if err != nil {
+				fmt.Printf("❌ Error reading %s: %v\n", lang, err)
+				return
+			}
+			if fallback {
+				if added := pack.Fill(items, source); added > 0 {
+					fmt.Printf("⚠️ %s: %d untranslated keys filled from %s\n", lang, added, ds.SourceLang)
+				}
+			}
+			catalogs = append(catalogs, pack.Catalog{Lang: lang, Items: items})
+		}
+
+		manifest, err := pack.Write(outDir, pack.Options{
+			Format:  format,
+			Version: version,
+			Source:  ds.SourceLang,
+			Minify:  minify,
+			Package: pkg,
+		}, catalogs)
+		if err != nil {
+			fmt.Printf("❌ Error writing pack: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, catalog := range catalogs {
+			entry := manifest.Languages[catalog.Lang]
+			fmt.Printf("📦 %s: %d keys, %d bytes\n", filepath.Join(outDir, entry.File), entry.Keys, entry.Bytes)
+		}
+		fmt.Printf("✅ Packed %d languages, manifest: %s\n", len(catalogs), filepath.Join(outDir, pack.ManifestName))
+	},
+}
+
+// mergedCatalog reads every catalog of a language and merges them, nesting keys under
+// the file type name (common.json -> common/...) when there are several
+func mergedCatalog(ds *scanner.DirectoryStructure, lang string) (map[string]string, error) {
+	namespaces := make(map[string]map[string]string)
+	for _, fileType := range ds.FileTypes {
+		namespace := strings.TrimSuffix(fileType, filepath.Ext(fileType))
+		path := ds.Path(lang, fileType)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			namespaces[namespace] = map[string]string{}
+			continue
+		}
+
+		content := &parser.LocaleFileContent{Path: path}
+		if err := content.ParseContent(); err != nil {
+			return nil, err
+		}
+		namespaces[namespace] = content.LocaleItemsMap
+	}
+	return pack.Merge(namespaces), nil
+}
+
+// packageName derives a Go package name from the output directory, e.g. dist/locales -> locales
+func packageName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, filepath.Base(filepath.Clean(dir)))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "locales" + name
+	}
+	return name
+}
+
+func init() {
+	packCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	packCmd.Flags().String("source", "en", "Source language code (default: en)")
+	packCmd.Flags().String("config", "", "Path to configuration file")
+	packCmd.Flags().String("out", "dist/locales", "Directory to write the artifacts and manifest to")
+	packCmd.Flags().String("format", pack.FormatJSON, "Artifact format: "+strings.Join(pack.Formats, ", "))
+	packCmd.Flags().String("version", "", "Version recorded in the manifest, e.g. the release tag")
+	packCmd.Flags().Bool("minify", false, "Write JSON without indentation")
+	packCmd.Flags().String("package", "", "Go package name with --format go (default: the output directory name)")
+	packCmd.Flags().Bool("fallback", false, "Fill untranslated keys with the source text")
+
+	packCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(packCmd)
+}
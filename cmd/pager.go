@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultTerminalHeight is the number of lines of a screen when the terminal doesn't tell
+const defaultTerminalHeight = 24
+
+// pagerPrompt is shown between screens of paged output
+const pagerPrompt = "-- More -- (Enter: next page, q: quit) "
+
+// terminalHeight returns the number of lines of the terminal, from $LINES
+func terminalHeight() int {
+	if lines, err := strconv.Atoi(os.Getenv("LINES")); err == nil && lines > 1 {
+		return lines
+	}
+	return defaultTerminalHeight
+}
+
+// page writes text to out a screen of height lines at a time, waiting for Enter on in
+// between screens; answering q skips the rest. Output that is not interactive is
+// written at once.
+func page(text string, in io.Reader, out io.Writer, height int, interactive bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if !interactive || len(lines) < height {
+		fmt.Fprint(out, text)
+		return
+	}
+
+	reader := bufio.NewReader(in)
+	screen := height - 1 // The last line shows the prompt
+	for start := 0; start < len(lines); start += screen {
+		end := start + screen
+		if end > len(lines) {
+			end = len(lines)
+		}
+		fmt.Fprint(out, strings.Join(lines[start:end], ""))
+		if end == len(lines) {
+			return
+		}
+		fmt.Fprint(out, pagerPrompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil || strings.EqualFold(strings.TrimSpace(answer), "q") {
+			fmt.Fprintln(out)
+			return
+		}
+	}
+}
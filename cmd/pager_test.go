@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPage tests that interactive output is paged, and that q skips the rest
+func TestPage(t *testing.T) {
+	text := "1\n2\n3\n4\n5\n"
+
+	var out strings.Builder
+	page(text, strings.NewReader("\n"), &out, 3, true)
+	assert.Equal(t, "1\n2\n"+pagerPrompt+"3\n4\n"+pagerPrompt+"\n", out.String())
+
+	out.Reset()
+	page(text, strings.NewReader("\n\n"), &out, 3, true)
+	assert.Equal(t, "1\n2\n"+pagerPrompt+"3\n4\n"+pagerPrompt+"5\n", out.String())
+
+	out.Reset()
+	page(text, strings.NewReader("q\n"), &out, 3, true)
+	assert.Equal(t, "1\n2\n"+pagerPrompt+"\n", out.String())
+
+	out.Reset()
+	page(text, strings.NewReader(""), &out, 3, false)
+	assert.Equal(t, text, out.String())
+}
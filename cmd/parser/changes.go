@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Change is an edit of a source catalog described by a change feed
+type Change struct {
+	// Flattened key, e.g. "home/title"; removals of an object apply to every key under it
+	Key string
+	// New value of the key
+	Value string
+	// Set when the key is removed
+	Removed bool
+}
+
+// patchOperation is an operation of a JSON Patch (RFC 6902)
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ParseChanges parses a JSON Patch (RFC 6902) array of add, replace and remove
+// operations, or a JSON Merge Patch (RFC 7386) object where null removes a key
+func ParseChanges(data []byte) ([]Change, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return parseJSONPatch(data)
+	}
+
+	var merge map[string]interface{}
+	if err := json.Unmarshal(data, &merge); err != nil {
+		return nil, fmt.Errorf("neither a JSON Patch array nor a JSON Merge Patch object: %w", err)
+	}
+	changes := []Change{}
+	mergeChanges(merge, "", &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+func parseJSONPatch(data []byte) ([]Change, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for i, op := range ops {
+		key, err := pointerKey(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		switch op.Op {
+		case "remove":
+			changes = append(changes, Change{Key: key, Removed: true})
+		case "add", "replace":
+			var value interface{}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("operation %d: invalid value: %w", i, err)
+			}
+			if object, ok := value.(map[string]interface{}); ok {
+				// Replacing an object drops the keys under it missing from the new one
+				if op.Op == "replace" {
+					changes = append(changes, Change{Key: key, Removed: true})
+				}
+				items := map[string]string{}
+				flatten(object, key, items)
+				for _, k := range orderedItemKeys(items) {
+					changes = append(changes, Change{Key: k, Value: items[k]})
+				}
+				continue
+			}
+			if value == nil {
+				return nil, fmt.Errorf("operation %d: null value for %s, use remove", i, op.Path)
+			}
+			changes = append(changes, Change{Key: key, Value: fmt.Sprint(value)})
+		default:
+			return nil, fmt.Errorf("operation %d: unsupported op %q, only add, replace and remove are", i, op.Op)
+		}
+	}
+	return changes, nil
+}
+
+// pointerKey returns the flattened key a JSON Pointer (RFC 6901) designates
+func pointerKey(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || pointer == "/" {
+		return "", fmt.Errorf("invalid path %q", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+func mergeChanges(patch map[string]interface{}, prefix string, changes *[]Change) {
+	for key, value := range patch {
+		if prefix != "" {
+			key = prefix + "/" + key
+		}
+		switch child := value.(type) {
+		case nil:
+			*changes = append(*changes, Change{Key: key, Removed: true})
+		case map[string]interface{}:
+			mergeChanges(child, key, changes)
+		default:
+			*changes = append(*changes, Change{Key: key, Value: fmt.Sprint(value)})
+		}
+	}
+}
+
+// ApplyChanges applies changes in order to the flattened items and returns the keys
+// that were set and those that were removed, sorted
+func ApplyChanges(items map[string]string, changes []Change) (set, removed []string) {
+	setKeys := map[string]bool{}
+	removedKeys := map[string]bool{}
+	for _, c := range changes {
+		if !c.Removed {
+			items[c.Key] = c.Value
+			setKeys[c.Key] = true
+			delete(removedKeys, c.Key)
+			continue
+		}
+		for k := range items {
+			if k == c.Key || strings.HasPrefix(k, c.Key+"/") {
+				delete(items, k)
+				delete(setKeys, k)
+				removedKeys[k] = true
+			}
+		}
+	}
+	return sortedSet(setKeys), sortedSet(removedKeys)
+}
+
+func orderedItemKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseJSONPatch tests that operations are turned into changes of flattened keys
+func TestParseJSONPatch(t *testing.T) {
+	changes, err := ParseChanges([]byte(`[
+		{"op": "replace", "path": "/home/title", "value": "Welcome back"},
+		{"op": "add", "path": "/home/cta", "value": {"buy": "Buy", "more": "Learn more"}},
+		{"op": "add", "path": "/a~1b", "value": "Slash"},
+		{"op": "remove", "path": "/legacy"}
+	]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Key: "home/title", Value: "Welcome back"},
+		{Key: "home/cta/buy", Value: "Buy"},
+		{Key: "home/cta/more", Value: "Learn more"},
+		{Key: "a/b", Value: "Slash"},
+		{Key: "legacy", Removed: true},
+	}, changes)
+
+	_, err = ParseChanges([]byte(`[{"op": "move", "from": "/a", "path": "/b"}]`))
+	assert.Error(t, err)
+	_, err = ParseChanges([]byte(`[{"op": "add", "path": "title", "value": "x"}]`))
+	assert.Error(t, err)
+}
+
+// TestParseMergePatch tests that nested objects are flattened and null removes keys
+func TestParseMergePatch(t *testing.T) {
+	changes, err := ParseChanges([]byte(`{"home": {"title": "Welcome back", "old": null}, "count": 3}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Key: "count", Value: "3"},
+		{Key: "home/old", Removed: true},
+		{Key: "home/title", Value: "Welcome back"},
+	}, changes)
+
+	_, err = ParseChanges([]byte(`"not a patch"`))
+	assert.Error(t, err)
+}
+
+// TestApplyChanges tests that removing an object removes every key under it
+func TestApplyChanges(t *testing.T) {
+	items := map[string]string{"home/title": "Welcome", "legacy/a": "A", "legacy/b": "B", "legacyKey": "C"}
+	set, removed := ApplyChanges(items, []Change{
+		{Key: "home/title", Value: "Welcome back"},
+		{Key: "legacy", Removed: true},
+		{Key: "new", Value: "New"},
+	})
+	assert.Equal(t, []string{"home/title", "new"}, set)
+	assert.Equal(t, []string{"legacy/a", "legacy/b"}, removed)
+	assert.Equal(t, map[string]string{"home/title": "Welcome back", "legacyKey": "C", "new": "New"}, items)
+}
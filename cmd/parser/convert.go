@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key styles of JSON catalogs
+const (
+	// StyleFlat catalogs are a single object whose keys are paths, e.g. {"home.title": "Home"}
+	StyleFlat = "flat"
+	// StyleNested catalogs nest an object per key segment, e.g. {"home": {"title": "Home"}}
+	StyleNested = "nested"
+)
+
+// Styles lists the key styles
+var Styles = []string{StyleFlat, StyleNested}
+
+// Convert returns a JSON catalog rewritten in the given key style, keys joined or
+// split on separator. Values are kept exactly as they are, including numbers, arrays
+// and empty objects; keys are sorted and the indentation of data is kept. Keys that would collide after conversion, such
+// as "home" being both a text and the parent of "home.title", are an error.
+func Convert(data []byte, style, separator string) ([]byte, error) {
+	if style != StyleFlat && style != StyleNested {
+		return nil, fmt.Errorf("unknown key style %q (expected one of %s)", style, strings.Join(Styles, ", "))
+	}
+	if separator == "" {
+		return nil, fmt.Errorf("key separator must not be empty")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root map[string]interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	leaves := map[string]interface{}{}
+	paths := map[string][]string{}
+	var walk func(obj map[string]interface{}, prefix []string) error
+	walk = func(obj map[string]interface{}, prefix []string) error {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			segments := []string{k}
+			if style == StyleNested {
+				segments = strings.Split(k, separator)
+			}
+			path := append(append([]string{}, prefix...), segments...)
+			if child, ok := obj[k].(map[string]interface{}); ok && len(child) > 0 {
+				if err := walk(child, path); err != nil {
+					return err
+				}
+				continue
+			}
+			key := strings.Join(path, separator)
+			if _, exists := leaves[key]; exists {
+				return fmt.Errorf("key %q is defined more than once", key)
+			}
+			leaves[key] = obj[k]
+			paths[key] = path
+		}
+		return nil
+	}
+	if err := walk(root, nil); err != nil {
+		return nil, err
+	}
+
+	result := leaves
+	if style == StyleNested {
+		var err error
+		if result, err = nest(leaves, paths, separator); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the indentation of the file
+	indent := "  "
+	sc := &jsonScanner{data: data}
+	sc.skipSpace()
+	if obj, err := sc.parseObject(""); err == nil {
+		indent = detectIndent(data, obj)
+	}
+	out, err := encodeJSON(result, "", indent)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out + "\n"), nil
+}
+
+// nest builds nested objects from leaves by their key paths
+func nest(leaves map[string]interface{}, paths map[string][]string, separator string) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(leaves))
+	for k := range leaves {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	root := map[string]interface{}{}
+	for _, key := range keys {
+		path := paths[key]
+		current := root
+		for i, segment := range path[:len(path)-1] {
+			switch child := current[segment].(type) {
+			case nil:
+				next := map[string]interface{}{}
+				current[segment] = next
+				current = next
+			case map[string]interface{}:
+				current = child
+			default:
+				return nil, fmt.Errorf("key %q is a text and the parent of %q", strings.Join(path[:i+1], separator), key)
+			}
+		}
+		last := path[len(path)-1]
+		if _, exists := current[last]; exists {
+			return nil, fmt.Errorf("key %q is a text and the parent of other keys", key)
+		}
+		current[last] = leaves[key]
+	}
+	return root, nil
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const convertNested = `{
+    "count": 3,
+    "home": {
+        "links": [
+            "a",
+            "b"
+        ],
+        "title": "<b>Home</b>"
+    },
+    "settings": {}
+}
+`
+
+const convertFlat = `{
+    "count": 3,
+    "home.links": [
+        "a",
+        "b"
+    ],
+    "home.title": "<b>Home</b>",
+    "settings": {}
+}
+`
+
+// TestConvert tests conversions in both directions keep values and indentation
+func TestConvert(t *testing.T) {
+	out, err := Convert([]byte(convertNested), StyleFlat, ".")
+	assert.NoError(t, err)
+	assert.Equal(t, convertFlat, string(out))
+
+	out, err = Convert([]byte(convertFlat), StyleNested, ".")
+	assert.NoError(t, err)
+	assert.Equal(t, convertNested, string(out))
+
+	out, err = Convert([]byte(convertNested), StyleNested, ".")
+	assert.NoError(t, err)
+	assert.Equal(t, convertNested, string(out))
+}
+
+// TestConvertConflicts tests keys that can't be converted without losing a value
+func TestConvertConflicts(t *testing.T) {
+	_, err := Convert([]byte(`{"home": "Home", "home.title": "Title"}`), StyleNested, ".")
+	assert.Error(t, err)
+
+	_, err = Convert([]byte(`{"home.title": "A", "home": {"title": "B"}}`), StyleFlat, ".")
+	assert.Error(t, err)
+
+	_, err = Convert([]byte(`{"a": "b"}`), "tree", ".")
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// DuplicateKey is a key defined more than once in the same object of a JSON file.
+// Standard JSON parsing silently keeps the last value and loses the others.
+type DuplicateKey struct {
+	// Flattened key, e.g. "home/title"
+	Key string `json:"key"`
+	// Line of the duplicate definition
+	Line int `json:"line"`
+	// Line of the first definition
+	FirstLine int `json:"firstLine"`
+}
+
+// String describes the duplicate with its lines
+func (d DuplicateKey) String() string {
+	return fmt.Sprintf("key %s on line %d is already defined on line %d", d.Key, d.Line, d.FirstLine)
+}
+
+// DuplicateKeys returns the keys defined more than once in an object of data, every
+// definition after the first one, sorted by line
+func DuplicateKeys(data []byte) ([]DuplicateKey, error) {
+	sc := &jsonScanner{data: data}
+	sc.skipSpace()
+	root, err := sc.parseObject("")
+	if err != nil {
+		return nil, err
+	}
+
+	lineOf := func(offset int) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	duplicates := []DuplicateKey{}
+	var walk func(obj *jsonObject)
+	walk = func(obj *jsonObject) {
+		first := map[string]*jsonMember{}
+		for _, m := range obj.members {
+			if prev, ok := first[m.key]; ok {
+				duplicates = append(duplicates, DuplicateKey{
+					Key:       joinPath(obj.path, m.key),
+					Line:      lineOf(m.keyStart),
+					FirstLine: lineOf(prev.keyStart),
+				})
+			} else {
+				first[m.key] = m
+			}
+			if m.object != nil {
+				walk(m.object)
+			}
+		}
+	}
+	walk(root)
+
+	sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].Line < duplicates[j].Line })
+	return duplicates, nil
+}
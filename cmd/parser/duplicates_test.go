@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDuplicateKeys tests that keys repeated in the same object are found with their lines
+func TestDuplicateKeys(t *testing.T) {
+	data := []byte(`{
+  "title": "Home",
+  "nested": {
+    "ok": "Yes",
+    "title": "Not a duplicate",
+    "ok": "Oui"
+  },
+  "title": "Accueil"
+}`)
+
+	duplicates, err := DuplicateKeys(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []DuplicateKey{
+		{Key: "nested/ok", Line: 6, FirstLine: 4},
+		{Key: "title", Line: 8, FirstLine: 2},
+	}, duplicates)
+	assert.Equal(t, "key title on line 8 is already defined on line 2", duplicates[1].String())
+
+	l := &LocaleFileContent{}
+	assert.NoError(t, l.ParseJSON(data))
+	assert.Equal(t, duplicates, l.Duplicates)
+	assert.Equal(t, "Accueil", l.LocaleItemsMap["title"])
+
+	duplicates, err = DuplicateKeys([]byte(`{"a": "1", "b": {"a": "2"}}`))
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+)
+
+// Key orders of formatted catalogs
+const (
+	// OrderAlphabetical sorts the keys of every object with the sort options
+	OrderAlphabetical = "alphabetical"
+	// OrderSource orders keys as the source catalog does, keys it lacks last and sorted
+	OrderSource = "source"
+)
+
+// Orders lists the key orders
+var Orders = []string{OrderAlphabetical, OrderSource}
+
+// FormatOptions controls how Format rewrites a catalog
+type FormatOptions struct {
+	Order string
+	// Sort orders keys with OrderAlphabetical, and those the source lacks with OrderSource
+	Sort keyorder.SortOptions
+	// Lang is the language of the catalog, collating keys unless Sort has a locale
+	Lang string
+	// Source is the source catalog whose key order OrderSource follows
+	Source []byte
+	// Indent is the indentation step, empty to keep that of the catalog
+	Indent string
+}
+
+// Format returns a JSON catalog with the keys of every object ordered by opts, each member
+// on its own line indented one step per level, and a trailing newline. Values are kept as
+// written, arrays reindented, and no key is added or removed.
+func Format(data []byte, opts FormatOptions) ([]byte, error) {
+	if opts.Order != OrderAlphabetical && opts.Order != OrderSource {
+		return nil, fmt.Errorf("unknown key order %q (expected one of %s)", opts.Order, strings.Join(Orders, ", "))
+	}
+	root, err := scanDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formatter{data: data, indent: opts.Indent, less: opts.Sort.Less(opts.Lang)}
+	if f.indent == "" {
+		f.indent = detectIndent(data, root)
+	}
+	if opts.Order == OrderSource {
+		source, err := scanDocument(opts.Source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the source catalog: %w", err)
+		}
+		f.rank = map[string]int{}
+		rankMembers(source, f.rank)
+	}
+
+	var buf bytes.Buffer
+	if err := f.writeObject(&buf, root, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// scanDocument locates the members of a JSON document holding a single object
+func scanDocument(data []byte) (*jsonObject, error) {
+	sc := &jsonScanner{data: data}
+	sc.skipSpace()
+	root, err := sc.parseObject("")
+	if err != nil {
+		return nil, err
+	}
+	sc.skipSpace()
+	if sc.pos != len(sc.data) {
+		return nil, fmt.Errorf("unexpected data after offset %d", sc.pos)
+	}
+	return root, nil
+}
+
+// rankMembers records the position of each member of obj, by path, among its siblings
+func rankMembers(obj *jsonObject, rank map[string]int) {
+	for i, m := range obj.members {
+		rank[joinPath(obj.path, m.key)] = i
+		if m.object != nil {
+			rankMembers(m.object, rank)
+		}
+	}
+}
+
+// formatter writes the objects of a document in key order
+type formatter struct {
+	data   []byte
+	indent string
+	less   func(a, b string) bool
+	// Positions of the members of the source by path, nil for alphabetical order
+	rank map[string]int
+}
+
+// sorted returns the members of obj in key order
+func (f *formatter) sorted(obj *jsonObject) []*jsonMember {
+	members := append([]*jsonMember{}, obj.members...)
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i].key, members[j].key
+		if f.rank != nil {
+			ra, aKnown := f.rank[joinPath(obj.path, a)]
+			rb, bKnown := f.rank[joinPath(obj.path, b)]
+			switch {
+			case aKnown && bKnown:
+				return ra < rb
+			case aKnown != bKnown:
+				return aKnown
+			}
+		}
+		return f.less(a, b)
+	})
+	return members
+}
+
+func (f *formatter) writeObject(buf *bytes.Buffer, obj *jsonObject, prefix string) error {
+	if len(obj.members) == 0 {
+		buf.WriteString("{}")
+		return nil
+	}
+
+	inner := prefix + f.indent
+	buf.WriteString("{\n")
+	for i, m := range f.sorted(obj) {
+		buf.WriteString(inner)
+		buf.Write(f.data[m.keyStart : m.keyStart+rawStringLength(f.data[m.keyStart:])])
+		buf.WriteString(": ")
+		value := f.data[m.valueStart:m.valueEnd]
+		switch {
+		case m.object != nil:
+			if err := f.writeObject(buf, m.object, inner); err != nil {
+				return err
+			}
+		case value[0] == '[':
+			if err := json.Indent(buf, value, inner, f.indent); err != nil {
+				return err
+			}
+		default:
+			buf.Write(value)
+		}
+		if i < len(obj.members)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(prefix + "}")
+	return nil
+}
+
+// rawStringLength returns the length of the JSON string data starts with
+func rawStringLength(data []byte) int {
+	sc := &jsonScanner{data: data}
+	if err := sc.skipString(); err != nil {
+		return len(data)
+	}
+	return sc.pos
+}
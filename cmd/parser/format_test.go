@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+	"github.com/stretchr/testify/assert"
+)
+
+const formatMessy = `{"title": "Accueil \u00e9" ,
+	"menu": {"open": "Ouvrir",   "close": "Fermer", "extra": "En plus"},
+	"links":["a",  "b"], "count": 3, "empty": {}}`
+
+// TestFormat tests that keys are ordered alphabetically or as the source orders them,
+// with consistent indentation and values kept as written
+func TestFormat(t *testing.T) {
+	out, err := Format([]byte(formatMessy), FormatOptions{Order: OrderAlphabetical, Indent: "  "})
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "count": 3,
+  "empty": {},
+  "links": [
+    "a",
+    "b"
+  ],
+  "menu": {
+    "close": "Fermer",
+    "extra": "En plus",
+    "open": "Ouvrir"
+  },
+  "title": "Accueil \u00e9"
+}
+`, string(out))
+
+	again, err := Format(out, FormatOptions{Order: OrderAlphabetical})
+	assert.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+
+	source := []byte(`{"title": "Home", "menu": {"open": "Open", "close": "Close"}, "count": 1}`)
+	out, err = Format([]byte(formatMessy), FormatOptions{Order: OrderSource, Source: source, Indent: "\t"})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n\t\"title\": \"Accueil \\u00e9\",\n\t\"menu\": {\n\t\t\"open\": \"Ouvrir\",\n\t\t\"close\": \"Fermer\",\n\t\t\"extra\": \"En plus\"\n\t},\n\t\"count\": 3,\n\t\"empty\": {},\n\t\"links\": [\n\t\t\"a\",\n\t\t\"b\"\n\t]\n}\n", string(out))
+
+	out, err = Format([]byte(formatMessy), FormatOptions{Order: OrderAlphabetical, Sort: keyorder.SortOptions{Pinned: []string{"title"}}, Indent: "  "})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "{\n  \"title\": \"Accueil \\u00e9\",\n  \"count\": 3,")
+
+	_, err = Format([]byte(formatMessy), FormatOptions{Order: "random"})
+	assert.Error(t, err)
+	_, err = Format([]byte(`{"a": "A"} {}`), FormatOptions{Order: OrderAlphabetical})
+	assert.Error(t, err)
+}
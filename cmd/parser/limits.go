@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits are sanity limits of locale files, catching files that aren't catalogs, such
+// as machine-generated JSON pointed at by mistake, before their values are sent for
+// translation. A limit of 0 disables it.
+type Limits struct {
+	// Keys a file may have
+	MaxKeys int `json:"maxKeys,omitempty"`
+	// Levels of nested objects a file may have, 1 for a flat file
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// Characters a value may have
+	MaxValueLength int `json:"maxValueLength,omitempty"`
+	// Fail to parse files exceeding a limit, instead of warning about them
+	Strict bool `json:"strict,omitempty"`
+}
+
+// DefaultLimits are the limits files are parsed with unless configured otherwise: far
+// above what hand-written catalogs reach, and only warned about
+var DefaultLimits = Limits{MaxKeys: 20000, MaxDepth: 10, MaxValueLength: 5000}
+
+// FileLimits are the limits files are parsed with
+var FileLimits = DefaultLimits
+
+// Validate checks the limits are not negative
+func (l Limits) Validate() error {
+	for name, value := range map[string]int{"maxKeys": l.MaxKeys, "maxDepth": l.MaxDepth, "maxValueLength": l.MaxValueLength} {
+		if value < 0 {
+			return fmt.Errorf("limits.%s must not be negative, got %d", name, value)
+		}
+	}
+	return nil
+}
+
+// LimitError is a file exceeding the limits it is parsed with in strict mode
+type LimitError struct {
+	Path string
+	// The limits exceeded, e.g. "48213 keys, over the limit of 20000"
+	Exceeded []string
+}
+
+func (e *LimitError) Error() string {
+	name := e.Path
+	if name == "" {
+		name = "file"
+	}
+	return fmt.Sprintf("%s exceeds the file limits: %s", name, strings.Join(e.Exceeded, "; "))
+}
+
+// exceeded returns the limits of l the flattened items of a file of nested data exceed
+func (l Limits) exceeded(data map[string]interface{}, items map[string]string) []string {
+	found := []string{}
+	if l.MaxKeys > 0 && len(items) > l.MaxKeys {
+		found = append(found, fmt.Sprintf("%d keys, over the limit of %d", len(items), l.MaxKeys))
+	}
+	if d := depth(data); l.MaxDepth > 0 && d > l.MaxDepth {
+		found = append(found, fmt.Sprintf("%d levels of nesting, over the limit of %d", d, l.MaxDepth))
+	}
+	if l.MaxValueLength > 0 {
+		longest, longestKey := 0, ""
+		for key, value := range items {
+			if n := utf8.RuneCountInString(value); n > longest || (n == longest && key < longestKey) {
+				longest, longestKey = n, key
+			}
+		}
+		if longest > l.MaxValueLength {
+			found = append(found, fmt.Sprintf("a value of %d characters (%s), over the limit of %d", longest, longestKey, l.MaxValueLength))
+		}
+	}
+	return found
+}
+
+// depth returns the levels of nested objects of data, 1 for flat data
+func depth(data map[string]interface{}) int {
+	deepest := 0
+	for _, value := range data {
+		if child, ok := value.(map[string]interface{}); ok {
+			if d := depth(child); d > deepest {
+				deepest = d
+			}
+		}
+	}
+	return deepest + 1
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLimits tests that files exceeding the file limits are warned about, or fail to
+// parse in strict mode
+func TestLimits(t *testing.T) {
+	defer func() { FileLimits = DefaultLimits }()
+	data := []byte(`{"a": {"b": {"c": "deep"}}, "d": "` + strings.Repeat("x", 30) + `", "e": "ok"}`)
+
+	catalog := &LocaleFileContent{Path: "blob.json"}
+	require.NoError(t, catalog.ParseJSON(data))
+	assert.Empty(t, catalog.Exceeded)
+
+	FileLimits = Limits{MaxKeys: 2, MaxDepth: 2, MaxValueLength: 20}
+	require.NoError(t, catalog.ParseJSON(data))
+	assert.Equal(t, []string{"3 keys, over the limit of 2", "3 levels of nesting, over the limit of 2", "a value of 30 characters (d), over the limit of 20"}, catalog.Exceeded)
+	assert.Equal(t, "x", catalog.LocaleItemsMap["d"][:1])
+
+	FileLimits.Strict = true
+	err := (&LocaleFileContent{Path: "blob.json"}).ParseJSON(data)
+	assert.EqualError(t, err, "blob.json exceeds the file limits: 3 keys, over the limit of 2; 3 levels of nesting, over the limit of 2; a value of 30 characters (d), over the limit of 20")
+	assert.NoError(t, (&LocaleFileContent{}).ParseJSON([]byte(`{"a": "b"}`)))
+
+	assert.EqualError(t, Limits{MaxDepth: -1}.Validate(), "limits.maxDepth must not be negative, got -1")
+}
@@ -18,6 +18,15 @@ type LocaleFileContent struct {
 	Path string
 
 	LocaleItemsMap map[string]string
+
+	// Keys defined more than once in the file, of which only the last value was kept
+	Duplicates []DuplicateKey
+
+	// Limits of FileLimits the file exceeds, when they are only warned about
+	Exceeded []string
+
+	// raw is the file content as read, used to patch the file in place
+	raw []byte
 }
 
 func (l *LocaleFileContent) ParseFromJSONFile(path string) error {
@@ -36,7 +45,7 @@ func (l *LocaleFileContent) ParseFromJSONFile(path string) error {
 
 	lang, err := langCodeToName(nameWithoutExt)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot detect language from file name %s: %w", name, err)
 	}
 
 	l.Code = nameWithoutExt
@@ -52,17 +61,7 @@ func (l *LocaleFileContent) ParseFromJSONFile(path string) error {
 	if err != nil {
 		return err
 	}
-
-	// convert
-	var data map[string]interface{}
-	if err := json.Unmarshal(sourceBytes, &data); err != nil {
-		return err
-	}
-	result := make(map[string]string)
-	flatten(data, "", result)
-
-	l.LocaleItemsMap = result
-	return nil
+	return l.ParseJSON(sourceBytes)
 }
 
 func (l *LocaleFileContent) JSON() ([]byte, error) {
@@ -128,12 +127,44 @@ func sortMapKeys(data interface{}) interface{} {
 	}
 }
 
+// langCodeToName resolves a language code (fr-FR, pt_BR, zh-Hant) to its name in
+// that language, e.g. "français (France)". Codes that don't name a known
+// language are rejected instead of producing a meaningless name.
 func langCodeToName(code string) (string, error) {
 	tag, err := language.Parse(code)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("unknown language code %q: %w", code, err)
 	}
-	return display.Self.Name(tag), nil
+
+	base, conf := tag.Base()
+	if conf != language.Exact {
+		return "", fmt.Errorf("unknown language code %q", code)
+	}
+
+	parts := []interface{}{base}
+	if script, conf := tag.Script(); conf == language.Exact {
+		parts = append(parts, script)
+	}
+	t, err := language.Compose(parts...)
+	if err != nil {
+		return "", fmt.Errorf("unknown language code %q: %w", code, err)
+	}
+	return nameWithRegion(t, tag)
+}
+
+// nameWithRegion names lang in itself, followed by the explicit region of tag if any
+func nameWithRegion(lang, tag language.Tag) (string, error) {
+	name := display.Self.Name(lang)
+	if name == "" {
+		return "", fmt.Errorf("no display name for language code %q", tag)
+	}
+
+	if region, conf := tag.Region(); conf == language.Exact {
+		if regionName := display.Regions(lang).Name(region); regionName != "" {
+			name = fmt.Sprintf("%s (%s)", name, regionName)
+		}
+	}
+	return name, nil
 }
 
 // LangCodeToName converts a language code to a display name
@@ -158,7 +189,11 @@ func (l *LocaleFileContent) ParseContent() error {
 	if err != nil {
 		return err
 	}
+	return l.ParseJSON(sourceBytes)
+}
 
+// ParseJSON parses the content of a JSON file, e.g. one read from another file system
+func (l *LocaleFileContent) ParseJSON(sourceBytes []byte) error {
 	// Convert to map
 	var data map[string]interface{}
 	if err := json.Unmarshal(sourceBytes, &data); err != nil {
@@ -168,6 +203,43 @@ func (l *LocaleFileContent) ParseContent() error {
 	result := make(map[string]string)
 	flatten(data, "", result)
 
+	l.Exceeded = FileLimits.exceeded(data, result)
+	if len(l.Exceeded) > 0 && FileLimits.Strict {
+		return &LimitError{Path: l.Path, Exceeded: l.Exceeded}
+	}
+
 	l.LocaleItemsMap = result
+	l.raw = sourceBytes
+	l.Duplicates, _ = DuplicateKeys(sourceBytes)
 	return nil
 }
+
+// Original returns the items of the file as they were read from disk, or nil if
+// the file was not read from disk
+func (l *LocaleFileContent) Original() map[string]string {
+	if l.raw == nil {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(l.raw, &data); err != nil {
+		return nil
+	}
+	result := make(map[string]string)
+	flatten(data, "", result)
+	return result
+}
+
+// Patch returns the file content with only the changed keys edited in place.
+// Files that were not read from disk, or can't be patched, are fully re-marshalled.
+func (l *LocaleFileContent) Patch() ([]byte, error) {
+	if l.raw == nil {
+		return l.JSON()
+	}
+
+	buf, err := PatchJSON(l.raw, l.LocaleItemsMap)
+	if err != nil {
+		return l.JSON()
+	}
+	return buf, nil
+}
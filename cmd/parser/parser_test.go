@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLangCodeToName tests resolving language names from file name codes
+func TestLangCodeToName(t *testing.T) {
+	tests := map[string]string{
+		"fr-FR":   "français (France)",
+		"fr_FR":   "français (France)",
+		"de":      "Deutsch",
+		"pt-BR":   "português (Brasil)",
+		"zh-Hant": "繁體中文",
+	}
+	for code, expected := range tests {
+		name, err := LangCodeToName(code)
+		assert.NoError(t, err, code)
+		assert.Equal(t, expected, name, code)
+	}
+
+	for _, code := range []string{"xx", "messages", "und", "common"} {
+		_, err := LangCodeToName(code)
+		assert.Error(t, err, code)
+	}
+}
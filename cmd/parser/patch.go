@@ -0,0 +1,511 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsonMember is a "key": value pair located in the original document
+type jsonMember struct {
+	key        string
+	keyStart   int // offset of the opening quote of the key
+	valueStart int
+	valueEnd   int
+	object     *jsonObject // set when the value is an object
+}
+
+// jsonObject is an object located in the original document
+type jsonObject struct {
+	path    string
+	open    int // offset of '{'
+	close   int // offset of '}'
+	members []*jsonMember
+}
+
+// patchEdit replaces original[start:end] with text
+type patchEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// PatchJSON applies the flattened items to an existing JSON document, touching only
+// the values that changed, inserting new keys and removing keys that are no longer
+// present. Untouched lines are preserved byte for byte.
+func PatchJSON(original []byte, items map[string]string) ([]byte, error) {
+	sc := &jsonScanner{data: original}
+	sc.skipSpace()
+	root, err := sc.parseObject("")
+	if err != nil {
+		return nil, err
+	}
+	sc.skipSpace()
+	if sc.pos != len(sc.data) {
+		return nil, fmt.Errorf("unexpected data after offset %d", sc.pos)
+	}
+
+	var existing map[string]interface{}
+	if err := json.Unmarshal(original, &existing); err != nil {
+		return nil, err
+	}
+	current := make(map[string]string)
+	flatten(existing, "", current)
+
+	p := &patcher{data: original, items: items, current: current, indent: detectIndent(original, root)}
+	if err := p.patchObject(root); err != nil {
+		return nil, err
+	}
+
+	// apply from the end so earlier offsets stay valid, removals before insertions at the same offset
+	sort.Slice(p.edits, func(i, j int) bool {
+		if p.edits[i].start != p.edits[j].start {
+			return p.edits[i].start > p.edits[j].start
+		}
+		return p.edits[i].end > p.edits[j].end
+	})
+	out := append([]byte{}, original...)
+	for _, e := range p.edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+
+	return out, nil
+}
+
+type patcher struct {
+	data    []byte
+	items   map[string]string
+	current map[string]string
+	indent  string
+	edits   []patchEdit
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "/" + key
+}
+
+// removed reports whether a member no longer has any value in items
+func (p *patcher) removed(parent string, m *jsonMember) bool {
+	path := joinPath(parent, m.key)
+	if m.object == nil {
+		_, ok := p.items[path]
+		return !ok
+	}
+	if len(m.object.members) == 0 {
+		// keep empty objects, they can't be expressed in items anyway
+		return false
+	}
+	for k := range p.items {
+		if strings.HasPrefix(k, path+"/") {
+			return false
+		}
+	}
+	for _, child := range m.object.members {
+		if !p.removed(path, child) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *patcher) patchObject(obj *jsonObject) error {
+	keep := make([]bool, len(obj.members))
+	kept := 0
+	existingKeys := make(map[string]*jsonMember)
+	for i, m := range obj.members {
+		existingKeys[m.key] = m
+		keep[i] = !p.removed(obj.path, m)
+		if keep[i] {
+			kept++
+		}
+	}
+
+	// Update the values of kept members
+	for i, m := range obj.members {
+		if !keep[i] {
+			continue
+		}
+		path := joinPath(obj.path, m.key)
+		if m.object != nil {
+			if _, ok := p.items[path]; ok {
+				return fmt.Errorf("key %s is an object in the original file", path)
+			}
+			if err := p.patchObject(m.object); err != nil {
+				return err
+			}
+			continue
+		}
+		value := p.items[path]
+		if value == p.current[path] {
+			continue
+		}
+		encoded, err := marshalValue(value)
+		if err != nil {
+			return err
+		}
+		p.edits = append(p.edits, patchEdit{start: m.valueStart, end: m.valueEnd, text: encoded})
+	}
+
+	// Insert new members, grouped by their first path segment below this object
+	prefix := ""
+	if obj.path != "" {
+		prefix = obj.path + "/"
+	}
+	added := make(map[string]interface{})
+	for k, v := range p.items {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(k, prefix)
+		parts := strings.Split(rest, "/")
+		if m, ok := existingKeys[parts[0]]; ok {
+			if m.object == nil && len(parts) > 1 {
+				return fmt.Errorf("key %s conflicts with existing value %s", k, joinPath(obj.path, parts[0]))
+			}
+			continue
+		}
+		insertNested(added, parts, v)
+	}
+	// Remove members that are gone, an emptied object is rewritten as a whole below
+	if kept == 0 {
+		if len(obj.members) > 0 && len(added) == 0 {
+			p.edits = append(p.edits, patchEdit{start: obj.open + 1, end: obj.close, text: ""})
+		}
+	} else {
+		// a leading run of removed members is cut up to the first kept key,
+		// any later one together with the separator in front of it
+		first := 0
+		for !keep[first] {
+			first++
+		}
+		if first > 0 {
+			p.edits = append(p.edits, patchEdit{start: obj.members[0].keyStart, end: obj.members[first].keyStart, text: ""})
+		}
+		for i := first + 1; i < len(obj.members); i++ {
+			if !keep[i] {
+				p.edits = append(p.edits, patchEdit{start: obj.members[i-1].valueEnd, end: obj.members[i].valueEnd, text: ""})
+			}
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	return p.insertMembers(obj, keep, kept, added)
+}
+
+func insertNested(data map[string]interface{}, parts []string, value string) {
+	current := data
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			current[part] = value
+			return
+		}
+		child, ok := current[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			current[part] = child
+		}
+		current = child
+	}
+}
+
+func (p *patcher) insertMembers(obj *jsonObject, keep []bool, kept int, added map[string]interface{}) error {
+	keys := make([]string, 0, len(added))
+	for k := range added {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	indent := p.memberIndent(obj)
+	compact := p.compact(obj)
+	space := " "
+	if compact {
+		space = ""
+	}
+	encode := func(key string) (string, error) {
+		k, err := marshalValue(key)
+		if err != nil {
+			return "", err
+		}
+		v, err := encodeJSON(added[key], indent, p.indent)
+		if err != nil {
+			return "", err
+		}
+		return k + ":" + space + v, nil
+	}
+
+	if kept == 0 {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			member, err := encode(key)
+			if err != nil {
+				return err
+			}
+			if compact {
+				parts = append(parts, member)
+			} else {
+				parts = append(parts, indent+member)
+			}
+		}
+		text := "\n" + strings.Join(parts, ",\n") + "\n" + strings.TrimSuffix(indent, p.indent)
+		if compact {
+			text = strings.Join(parts, ",")
+		}
+		p.edits = append(p.edits, patchEdit{start: obj.open + 1, end: obj.close, text: text})
+		return nil
+	}
+
+	keptMembers := make([]*jsonMember, 0, kept)
+	for i, m := range obj.members {
+		if keep[i] {
+			keptMembers = append(keptMembers, m)
+		}
+	}
+	sorted := sort.SliceIsSorted(keptMembers, func(i, j int) bool { return keptMembers[i].key < keptMembers[j].key })
+
+	// Collect the new members in front of each kept member, or after the last one
+	before := make(map[int][]string)
+	var after []string
+	for _, key := range keys {
+		member, err := encode(key)
+		if err != nil {
+			return err
+		}
+		pos := len(keptMembers)
+		if sorted {
+			pos = sort.Search(len(keptMembers), func(i int) bool { return keptMembers[i].key > key })
+		}
+		if pos == len(keptMembers) {
+			after = append(after, member)
+		} else {
+			before[pos] = append(before[pos], member)
+		}
+	}
+
+	sep := ",\n" + indent
+	if compact {
+		sep = ","
+	}
+
+	for pos, members := range before {
+		text := ""
+		for _, member := range members {
+			text += member + sep
+		}
+		at := keptMembers[pos].keyStart
+		p.edits = append(p.edits, patchEdit{start: at, end: at, text: text})
+	}
+	if len(after) > 0 {
+		text := ""
+		for _, member := range after {
+			text += sep + member
+		}
+		at := keptMembers[len(keptMembers)-1].valueEnd
+		p.edits = append(p.edits, patchEdit{start: at, end: at, text: text})
+	}
+
+	return nil
+}
+
+// compact reports whether obj keeps its members on the same line as its braces
+func (p *patcher) compact(obj *jsonObject) bool {
+	end := obj.close
+	if len(obj.members) > 0 {
+		end = obj.members[0].keyStart
+	}
+	return !bytes.ContainsRune(p.data[obj.open:end], '\n')
+}
+
+// memberIndent returns the indentation used for the members of obj
+func (p *patcher) memberIndent(obj *jsonObject) string {
+	if len(obj.members) > 0 {
+		return lineIndent(p.data, obj.members[0].keyStart)
+	}
+	return lineIndent(p.data, obj.open) + p.indent
+}
+
+func lineIndent(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < offset && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// detectIndent guesses the indentation step of the document, defaulting to two spaces
+func detectIndent(data []byte, root *jsonObject) string {
+	if len(root.members) > 0 {
+		if indent := lineIndent(data, root.members[0].keyStart); indent != "" && indent != lineIndent(data, root.open) {
+			return strings.TrimPrefix(indent, lineIndent(data, root.open))
+		}
+	}
+	return "  "
+}
+
+func marshalValue(v string) (string, error) {
+	return encodeJSON(v, "", "")
+}
+
+// encodeJSON marshals v without escaping HTML characters, so patched values stay readable
+func encodeJSON(v interface{}, prefix, indent string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonScanner locates objects, members, and values in a JSON document
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) expect(c byte) error {
+	s.skipSpace()
+	if s.pos >= len(s.data) || s.data[s.pos] != c {
+		return fmt.Errorf("expected '%c' at offset %d", c, s.pos)
+	}
+	s.pos++
+	return nil
+}
+
+func (s *jsonScanner) parseObject(path string) (*jsonObject, error) {
+	obj := &jsonObject{path: path, open: s.pos}
+	if err := s.expect('{'); err != nil {
+		return nil, err
+	}
+
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == '}' {
+		obj.close = s.pos
+		s.pos++
+		return obj, nil
+	}
+
+	for {
+		s.skipSpace()
+		m := &jsonMember{keyStart: s.pos}
+		start := s.pos
+		if err := s.skipString(); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(s.data[start:s.pos], &m.key); err != nil {
+			return nil, err
+		}
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+
+		s.skipSpace()
+		m.valueStart = s.pos
+		if s.pos < len(s.data) && s.data[s.pos] == '{' {
+			child, err := s.parseObject(joinPath(path, m.key))
+			if err != nil {
+				return nil, err
+			}
+			m.object = child
+		} else if err := s.skipValue(); err != nil {
+			return nil, err
+		}
+		m.valueEnd = s.pos
+		obj.members = append(obj.members, m)
+
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return nil, fmt.Errorf("unexpected end of data")
+		}
+		if s.data[s.pos] == ',' {
+			s.pos++
+			continue
+		}
+		if s.data[s.pos] == '}' {
+			obj.close = s.pos
+			s.pos++
+			return obj, nil
+		}
+		return nil, fmt.Errorf("unexpected '%c' at offset %d", s.data[s.pos], s.pos)
+	}
+}
+
+func (s *jsonScanner) skipString() error {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return fmt.Errorf("expected string at offset %d", s.pos)
+	}
+	s.pos++
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return nil
+		default:
+			s.pos++
+		}
+	}
+	return fmt.Errorf("unterminated string")
+}
+
+// skipValue skips any non-object value, including nested arrays
+func (s *jsonScanner) skipValue() error {
+	if s.pos >= len(s.data) {
+		return fmt.Errorf("unexpected end of data")
+	}
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '[', '{':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					s.pos++
+					return nil
+				}
+			}
+			s.pos++
+		}
+		return fmt.Errorf("unterminated value")
+	default:
+		start := s.pos
+		for s.pos < len(s.data) && !bytes.ContainsRune([]byte(",}] \t\r\n"), rune(s.data[s.pos])) {
+			s.pos++
+		}
+		if s.pos == start {
+			return fmt.Errorf("expected value at offset %d", s.pos)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+	"github.com/stretchr/testify/assert"
+)
+
+const patchOriginal = `{
+    "b": "Bonjour",
+    "c": 3,
+    "nested": {
+        "a": "Bienvenue",
+        "z": "Merci"
+    }
+}
+`
+
+// TestPatchJSONUnchanged tests that an unchanged catalog is written back byte for byte
+func TestPatchJSONUnchanged(t *testing.T) {
+	items := map[string]string{"b": "Bonjour", "c": "3", "nested/a": "Bienvenue", "nested/z": "Merci"}
+
+	out, err := PatchJSON([]byte(patchOriginal), items)
+	assert.NoError(t, err)
+	assert.Equal(t, patchOriginal, string(out))
+}
+
+// TestPatchJSONUpdateAndInsert tests value edits and sorted insertion with the file's indentation
+func TestPatchJSONUpdateAndInsert(t *testing.T) {
+	items := map[string]string{
+		"a":        "Au revoir",
+		"b":        "Salut",
+		"c":        "3",
+		"nested/a": "Bienvenue",
+		"nested/m": "<b>Oui</b>",
+		"nested/z": "Merci",
+		"new/deep": "Nouveau",
+	}
+
+	out, err := PatchJSON([]byte(patchOriginal), items)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+    "a": "Au revoir",
+    "b": "Salut",
+    "c": 3,
+    "nested": {
+        "a": "Bienvenue",
+        "m": "<b>Oui</b>",
+        "z": "Merci"
+    },
+    "new": {
+        "deep": "Nouveau"
+    }
+}
+`, string(out))
+}
+
+// TestPatchJSONRemove tests that removed keys take their separators with them
+func TestPatchJSONRemove(t *testing.T) {
+	out, err := PatchJSON([]byte(patchOriginal), map[string]string{"c": "3", "nested/z": "Merci"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+    "c": 3,
+    "nested": {
+        "z": "Merci"
+    }
+}
+`, string(out))
+
+	out, err = PatchJSON([]byte(patchOriginal), map[string]string{"b": "Bonjour"})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n    \"b\": \"Bonjour\"\n}\n", string(out))
+}
+
+// TestPatchJSONConflict tests that structural conflicts are reported instead of patched
+func TestPatchJSONConflict(t *testing.T) {
+	_, err := PatchJSON([]byte(patchOriginal), map[string]string{"b/x": "conflict"})
+	assert.Error(t, err)
+}
+
+// TestSortedJSONPinnedAndLocale tests pinned keys and locale-aware collation of rewritten files
+func TestSortedJSONPinnedAndLocale(t *testing.T) {
+	l := &LocaleFileContent{
+		Code: "de",
+		LocaleItemsMap: map[string]string{
+			"zebra":       "Zebra",
+			"Apfel":       "Apfel",
+			"äpfel":       "Äpfel",
+			"_meta/owner": "web",
+			"b/_meta":     "nested",
+			"b/a":         "a",
+		},
+	}
+
+	out, err := l.SortedJSON(keyorder.SortOptions{Collation: keyorder.CollationLocale, Pinned: []string{"_meta"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "_meta": {
+    "owner": "web"
+  },
+  "Apfel": "Apfel",
+  "äpfel": "Äpfel",
+  "b": {
+    "_meta": "nested",
+    "a": "a"
+  },
+  "zebra": "Zebra"
+}`, string(out))
+
+	// Default options keep the plain sorted output
+	plain, err := l.SortedJSON(keyorder.SortOptions{})
+	assert.NoError(t, err)
+	expected, _ := l.JSON()
+	assert.Equal(t, string(expected), string(plain))
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+)
+
+// SortedJSON returns the file content with keys ordered by opts
+func (l *LocaleFileContent) SortedJSON(opts keyorder.SortOptions) ([]byte, error) {
+	if opts.Binary() {
+		return l.JSON()
+	}
+
+	less := opts.Less(l.Code)
+	order := func(keys []string) {
+		sort.SliceStable(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	}
+
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, nestedInsertion(l.LocaleItemsMap), order, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeOrdered marshals data like json.MarshalIndent with two spaces, in the key order given by order
+func writeOrdered(buf *bytes.Buffer, data map[string]interface{}, order func([]string), indent string) error {
+	if len(data) == 0 {
+		buf.WriteString("{}")
+		return nil
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	order(keys)
+
+	inner := indent + "  "
+	buf.WriteString("{\n")
+	for i, k := range keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(inner)
+		buf.Write(key)
+		buf.WriteString(": ")
+
+		if child, ok := data[k].(map[string]interface{}); ok {
+			if err := writeOrdered(buf, child, order, inner); err != nil {
+				return err
+			}
+		} else {
+			value, err := json.Marshal(data[k])
+			if err != nil {
+				return err
+			}
+			buf.Write(value)
+		}
+
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent + "}")
+	return nil
+}
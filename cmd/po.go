@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gettext"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/spf13/cobra"
+)
+
+var poCmd = &cobra.Command{
+	Use:   "po",
+	Short: "Translate a gettext PO file",
+	Long:  `Translate a gettext PO file from a POT template or source PO file. Plural messages get one translation per plural form of the target language, following its CLDR plural rules, even when the source language has only two forms.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath, _ := cmd.Flags().GetString("source")
+		targetPath, _ := cmd.Flags().GetString("target")
+		lang, _ := cmd.Flags().GetString("lang")
+		mode, _ := cmd.Flags().GetString("mode")
+
+		if lang == "" {
+			base := filepath.Base(targetPath)
+			lang = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		langName, err := parser.LangCodeToName(lang)
+		if err != nil {
+			fmt.Printf("❌ Invalid target language %s (set it with --lang): %v\n", lang, err)
+			return
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+
+		source, err := readPO(sourcePath)
+		if err != nil {
+			fmt.Printf("❌ Error reading %s: %v\n", sourcePath, err)
+			return
+		}
+		existing := &gettext.File{}
+		if _, statErr := os.Stat(targetPath); statErr == nil {
+			if existing, err = readPO(targetPath); err != nil {
+				fmt.Printf("❌ Error reading %s: %v\n", targetPath, err)
+				return
+			}
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			return
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			return
+		}
+
+		release, err := acquireProjectLock(filepath.Dir(targetPath))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		defer release()
+
+		target := &parser.LocaleFileContent{Code: lang, Lang: langName, Path: targetPath}
+		rule := gettext.RuleFor(lang)
+		result, translated, failed := translatePO(gpt.WithLanguages(cmd.Context(), "", lang), gptHandler, source, existing, target, rule, mode == "full")
+
+		var buf bytes.Buffer
+		if err := result.Write(&buf); err != nil {
+			fmt.Printf("❌ Error encoding %s: %v\n", targetPath, err)
+			return
+		}
+		if err := writeFileAtomic(targetPath, buf.Bytes()); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", targetPath, err)
+			return
+		}
+
+		recordThroughput(gptHandler, false)
+		fmt.Printf("\n✅ %s: %d translated, %d failed (%d plural forms: %s)\n",
+			targetPath, translated, failed, rule.NPlurals, strings.Join(rule.Categories(lang), ", "))
+	},
+}
+
+// translatePO builds the target file from the source entries, keeping the existing
+// translations and translating the missing ones. Plural entries always end up with
+// the number of forms of the target language.
+func translatePO(ctx context.Context, gptHandler *gpt.Handler, source, existing *gettext.File, target *parser.LocaleFileContent, rule gettext.PluralRule, full bool) (*gettext.File, int, int) {
+	result := &gettext.File{Trailing: existing.Trailing}
+	if h := existing.Header(); h != nil {
+		result.Entries = append(result.Entries, h)
+	} else if h := source.Header(); h != nil {
+		header := *h
+		header.Str = append([]string{}, h.Str...)
+		result.Entries = append(result.Entries, &header)
+	}
+	result.SetHeaderField("Language", target.Code)
+	result.SetHeaderField("Plural-Forms", rule.Header())
+
+	forms := []gpt.PluralForm{}
+	for i, category := range rule.Categories(target.Code) {
+		forms = append(forms, gpt.PluralForm{Category: category, Example: rule.Examples[i]})
+	}
+
+	translated, failed := 0, 0
+	for _, src := range source.Entries {
+		if src.ID == "" && !src.HasContext {
+			continue
+		}
+
+		entry := &gettext.Entry{Comments: src.Comments, HasContext: src.HasContext, Context: src.Context, ID: src.ID, IDPlural: src.IDPlural}
+		if prev := existing.Lookup(src.HasContext, src.Context, src.ID); prev != nil {
+			entry.Comments = prev.Comments
+			entry.Str = prev.Str
+		}
+		result.Entries = append(result.Entries, entry)
+
+		wantForms := 1
+		if entry.Plural() {
+			wantForms = rule.NPlurals
+		}
+		if !full && entry.Translated() && len(entry.Str) == wantForms {
+			continue
+		}
+
+		var err error
+		if entry.Plural() {
+			entry.Str, err = translatePluralEntry(ctx, gptHandler, src, forms, target)
+		} else {
+			var text string
+			text, err = translateText(ctx, gptHandler, src.ID, target, processOptions{})
+			entry.Str = []string{text}
+		}
+		if err != nil {
+			fmt.Printf("\n⚠️ Error translating %q: %v\n", src.ID, err)
+			processOptions{}.logTranslationError(src.ID, src.ID, target.Lang, err)
+			entry.Str = make([]string, wantForms)
+			failed++
+			continue
+		}
+		translated++
+		fmt.Printf("\r🔄 %s: %d translated", target.Path, translated)
+	}
+	return result, translated, failed
+}
+
+// translatePluralEntry translates every plural form of an entry, retrying forms that fail validation
+func translatePluralEntry(ctx context.Context, gptHandler *gpt.Handler, src *gettext.Entry, forms []gpt.PluralForm, target *parser.LocaleFileContent) ([]string, error) {
+	var err error
+	for attempt := 0; attempt <= validationRetries; attempt++ {
+		var texts []string
+		texts, err = gptHandler.TranslatePlural(ctx, src.ID, src.IDPlural, forms, target.Lang)
+		if err != nil {
+			return nil, err
+		}
+
+		err = nil
+		for i, text := range texts {
+			source := src.IDPlural
+			if forms[i].Example == 1 {
+				source = src.ID
+			}
+			if err = checkTranslation(source, text, target); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return texts, nil
+		}
+	}
+	return nil, err
+}
+
+func readPO(path string) (*gettext.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gettext.Parse(f)
+}
+
+func init() {
+	poCmd.Flags().String("source", "", "Source POT template or PO file")
+	poCmd.Flags().String("target", "", "Target PO file, created if it doesn't exist")
+	poCmd.Flags().String("lang", "", "Target language code (default: the target file name)")
+	poCmd.Flags().String("config", "", "Path to configuration file")
+	poCmd.Flags().String("mode", "missing", "Translation mode: 'full' (translate all) or 'missing' (only translate missing entries)")
+	poCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the project lock left by another run before starting")
+
+	poCmd.MarkFlagRequired("source")
+	poCmd.MarkFlagRequired("target")
+
+	rootCmd.AddCommand(poCmd)
+}
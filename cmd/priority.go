@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadPriority reads a usage-frequency file mapping keys to hit counts, e.g. exported
+// from analytics. Keys may use "/" or "." as the nesting separator.
+func loadPriority(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits map[string]int
+	if err := json.Unmarshal(data, &hits); err != nil {
+		return nil, err
+	}
+
+	priority := make(map[string]int, len(hits))
+	for k, v := range hits {
+		priority[strings.ReplaceAll(k, ".", "/")] += v
+	}
+	return priority, nil
+}
+
+// orderedKeys returns the keys of items with the most used first. Keys without
+// usage data follow in alphabetical order.
+func orderedKeys(items map[string]string, priority map[string]int) []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := priority[keys[i]], priority[keys[j]]
+		if pi != pj {
+			return pi > pj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadPriority tests that hit counts are keyed with "/" whatever the separator of
+// the file, counts of the same key being added up
+func TestLoadPriority(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"home.title": 40, "home/title": 2, "menu/open": 7}`), 0644))
+
+	priority, err := loadPriority(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"home/title": 42, "menu/open": 7}, priority)
+
+	require.NoError(t, os.WriteFile(path, []byte(`["home/title"]`), 0644))
+	_, err = loadPriority(path)
+	assert.Error(t, err)
+	_, err = loadPriority(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+// TestOrderedKeys tests that the most used keys come first, and that keys without usage
+// data, or all of them without a usage file, follow in alphabetical order
+func TestOrderedKeys(t *testing.T) {
+	items := map[string]string{"b": "B", "a": "A", "d": "D", "c": "C", "e": "E"}
+
+	assert.Equal(t, []string{"d", "b", "e", "a", "c"}, orderedKeys(items, map[string]int{"d": 90, "b": 12, "e": 12, "unknown": 500}))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, orderedKeys(items, nil))
+	assert.Empty(t, orderedKeys(map[string]string{}, nil))
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/lock"
+	"github.com/pandodao/i18n-cli/internal/vcs"
+
+	"github.com/spf13/cobra"
+)
+
+// Ways of grouping proposed translations into branches
+const (
+	proposePerRun      = "run"
+	proposePerLanguage = "language"
+)
+
+var proposeCmd = &cobra.Command{
+	Use:   "propose",
+	Short: "Propose translations on a git branch for review",
+	Long:  `Run a sync on new git branches instead of the current one, commit the translated files and open a draft pull request for each branch, so translations are reviewed like any other change. Use --per language to get one branch and pull request per target language.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		configPath, _ := cmd.Flags().GetString("config")
+		per, _ := cmd.Flags().GetString("per")
+		base, _ := cmd.Flags().GetString("base")
+		remoteName, _ := cmd.Flags().GetString("remote")
+		prefix, _ := cmd.Flags().GetString("branch-prefix")
+		host, _ := cmd.Flags().GetString("host")
+		draft, _ := cmd.Flags().GetBool("draft")
+		noPR, _ := cmd.Flags().GetBool("no-pr")
+
+		if per != proposePerRun && per != proposePerLanguage {
+			fmt.Printf("❌ Invalid --per %q: expected run or language\n", per)
+			return
+		}
+
+		cfg, err := loadSyncConfig(cmd, configPath)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+
+		repo, err := vcs.Open(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		relRoot, err := repoPath(repo, rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if clean, err := repo.Clean(relRoot); err != nil || !clean {
+			fmt.Printf("❌ %s has uncommitted changes; commit or stash them before proposing translations\n", rootDir)
+			return
+		}
+
+		original, err := repo.CurrentBranch()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if base == "" {
+			base = original
+		}
+
+		var remote vcs.Remote
+		if !noPR {
+			url, err := repo.RemoteURL(remoteName)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			if remote, err = vcs.ParseRemote(url); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			if host == "" {
+				host = remote.Service()
+			}
+		}
+
+		release, err := acquireProjectLock(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		defer release()
+
+		// One proposal for the whole run, or one per target language
+		groups := [][]string{cfg.TargetLangs}
+		if per == proposePerLanguage {
+			ds, err := scanCatalogs(rootDir, cfg.SourceLang, cfg)
+			if err != nil {
+				fmt.Printf("❌ Error scanning directory: %v\n", err)
+				return
+			}
+			groups = nil
+			for _, lang := range selectTargetLanguages(ds, cfg) {
+				groups = append(groups, []string{lang})
+			}
+		}
+
+		ctx := cmd.Context()
+		opened := 0
+		for _, langs := range groups {
+			branch := prefix + runID
+			if per == proposePerLanguage {
+				branch = prefix + langs[0] + "-" + runID
+			}
+
+			p := proposal{repo: repo, root: rootDir, relRoot: relRoot, base: base, branch: branch, langs: langs}
+			files, err := p.run(ctx, cfg)
+			if checkoutErr := repo.Checkout(original); checkoutErr != nil {
+				fmt.Printf("❌ Error switching back to %s: %v\n", original, checkoutErr)
+				return
+			}
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", branch, err)
+				continue
+			}
+			if len(files) == 0 {
+				fmt.Printf("✅ %s: nothing to translate, no branch created\n", p.label())
+				repo.DeleteBranch(branch)
+				continue
+			}
+			fmt.Printf("📝 Committed %d files to %s\n", len(files), branch)
+
+			if noPR {
+				continue
+			}
+			if err := repo.Push(remoteName, branch); err != nil {
+				fmt.Printf("❌ Error pushing %s: %v\n", branch, err)
+				continue
+			}
+			pr := vcs.PullRequest{Title: p.title(), Body: p.body(files), Head: branch, Base: base, Draft: draft}
+			url, err := pr.Open(repo, remote, host)
+			if err != nil {
+				fmt.Printf("❌ Error opening pull request for %s: %v\n", branch, err)
+				continue
+			}
+			opened++
+			fmt.Printf("✅ Opened %s\n", url)
+		}
+
+		if !noPR {
+			fmt.Printf("\n✅ %d pull requests opened\n", opened)
+		}
+	},
+}
+
+// proposal is a branch with the translations of some target languages
+type proposal struct {
+	repo    *vcs.Repo
+	root    string
+	relRoot string
+	base    string
+	branch  string
+	langs   []string // empty for all target languages
+}
+
+// run syncs the translations on a new branch and commits them. It returns the
+// committed files, none if there was nothing to translate.
+func (p proposal) run(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if err := p.repo.CreateBranch(p.branch, p.base); err != nil {
+		return nil, err
+	}
+
+	langCfg := *cfg
+	if len(p.langs) > 0 {
+		langCfg.TargetLangs = p.langs
+	}
+	runSync(ctx, p.root, &langCfg)
+
+	if err := p.repo.Stage(p.relRoot, filepath.ToSlash(filepath.Join(p.relRoot, lock.FileName))); err != nil {
+		return nil, err
+	}
+	files, err := p.repo.StagedFiles()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	if err := p.repo.Commit(p.title() + "\n\nMachine translations by i18n-cli run " + runID + "."); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (p proposal) label() string {
+	if len(p.langs) == 0 {
+		return "all languages"
+	}
+	return strings.Join(p.langs, ", ")
+}
+
+func (p proposal) title() string {
+	if len(p.langs) == 1 {
+		return fmt.Sprintf("i18n: update %s translations", p.langs[0])
+	}
+	return fmt.Sprintf("i18n: update translations (%s)", p.label())
+}
+
+func (p proposal) body(files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Machine translations generated by i18n-cli (run `%s`) for review.\n\n", runID)
+	b.WriteString("Changed files:\n\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`\n", f)
+	}
+	b.WriteString("\nEdit the translations on this branch before merging; `i18n-cli history <key>` shows what changed for a key.\n")
+	return b.String()
+}
+
+// repoPath returns path relative to the root of repo, with forward slashes
+func repoPath(repo *vcs.Repo, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	top := repo.Dir
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func init() {
+	proposeCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	proposeCmd.Flags().String("source", "en", "Source language code (default: en)")
+	proposeCmd.Flags().StringVar(&translationMode, "mode", "missing", "Translation mode: 'full' (translate all) or 'missing' (only translate missing keys)")
+	proposeCmd.Flags().IntVar(&batchSize, "batch", 0, "Size of the batch for translations. If 0 or not provided, translates one at a time.")
+	proposeCmd.Flags().String("config", "", "Path to configuration file")
+	proposeCmd.Flags().BoolVar(&safeMode, "safe", false, "Treat source values as untrusted: neutralize and delimit them in prompts and reject translations with meta-commentary")
+	proposeCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Ask for reproducible translations: temperature 0, a fixed seed and identical prompts for identical inputs")
+	proposeCmd.Flags().IntVar(&seedFlag, "seed", 0, "Sampling seed sent to providers supporting one, so reruns pick the same words")
+	proposeCmd.Flags().Float64Var(&temperatureFlag, "temperature", 0.1, "Sampling temperature of translations, from 0 to 2; deterministic mode uses 0")
+	proposeCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Completion token cap of translation requests (default 1024, 2048 for batches)")
+	proposeCmd.Flags().StringVar(&systemPrompt, "system-prompt", "", "System prompt template replacing the default one; may use {{targetLang}}, {{sourceLang}}, {{key}} and {{glossary}}")
+	proposeCmd.Flags().IntVar(&fewShotExamples, "examples", defaultExamples, "Approved translations of similar texts sent as examples with each text (0 disables)")
+	proposeCmd.Flags().StringVar(&priorityFile, "priority", "", "Usage-frequency JSON file (key -> hit count); the most used keys are translated first")
+	proposeCmd.Flags().Float64Var(&confirmCost, "confirm-cost", defaultConfirmCost, "Estimated cost in USD above which confirmation is asked before starting (0 disables)")
+	proposeCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Start without asking for confirmation, whatever the estimated cost")
+	proposeCmd.Flags().BoolVar(&forceWrite, "force", false, "Write target files even if they would lose most of their keys or size")
+	proposeCmd.Flags().BoolVar(&rewriteOutput, "rewrite", false, "Rewrite whole target files with sorted keys instead of patching only changed keys")
+	proposeCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the project lock left by another run before starting")
+
+	proposeCmd.Flags().String("per", proposePerRun, "One branch and pull request per 'run' or per target 'language'")
+	proposeCmd.Flags().String("base", "", "Branch to start from and open pull requests against (default: the current branch)")
+	proposeCmd.Flags().String("remote", "origin", "Remote to push branches to")
+	proposeCmd.Flags().String("branch-prefix", "i18n/", "Prefix of the created branch names")
+	proposeCmd.Flags().String("host", "", "Hosting service: github (via gh) or gitlab (via GITLAB_TOKEN); default: guessed from the remote URL")
+	proposeCmd.Flags().Bool("draft", true, "Open pull requests as drafts")
+	proposeCmd.Flags().Bool("no-pr", false, "Only create and commit the branches, don't push or open pull requests")
+
+	proposeCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(proposeCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Inspect translation providers",
+}
+
+var providerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the health of each configured API key",
+	Long:  `Ping every configured API key and report model availability, request latency, and the remaining rate limit headroom.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configPath, _ := cmd.Flags().GetString("config")
+
+		var cfg *config.Config
+		if configPath != "" {
+			var err error
+			cfg, err = config.LoadConfig(configPath)
+			if err != nil {
+				fmt.Printf("❌ Error loading configuration: %v\n", err)
+				return
+			}
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			return
+		}
+
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(30)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			return
+		}
+
+		statuses := gptHandler.Ping(context.Background())
+		fmt.Printf("🩺 Checking %d API keys (%s)\n\n", len(statuses), gptHandler.Provider())
+		tbl := table.New("Key", "Model", "Available", "Latency", "Requests Left", "Tokens Left", "Status")
+
+		healthy := 0
+		for _, status := range statuses {
+			available := "❌"
+			if status.ModelAvailable {
+				available = "✅"
+			}
+
+			requests, tokens := "-", "-"
+			if rl := status.RateLimit; rl.LimitRequests > 0 {
+				requests = fmt.Sprintf("%d/%d (reset %s)", rl.RemainingRequests, rl.LimitRequests, rl.ResetRequests)
+				tokens = fmt.Sprintf("%d/%d (reset %s)", rl.RemainingTokens, rl.LimitTokens, rl.ResetTokens)
+			}
+
+			latency := "-"
+			if status.Latency > 0 {
+				latency = status.Latency.Round(time.Millisecond).String()
+			}
+
+			result := "ok"
+			if status.Err != nil {
+				result = status.Err.Error()
+			} else {
+				healthy++
+			}
+
+			tbl.Add(status.Key, status.Model, available, latency, requests, tokens, result)
+		}
+		fmt.Print(tbl)
+
+		fmt.Printf("\n✅ %d/%d keys healthy\n", healthy, len(statuses))
+	},
+}
+
+func init() {
+	providerStatusCmd.Flags().String("config", "", "Path to configuration file")
+
+	providerCmd.AddCommand(providerStatusCmd)
+	rootCmd.AddCommand(providerCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResolveAPIKeys tests that the environment variable of the provider takes
+// precedence over the config file, and is split on commas
+func TestResolveAPIKeys(t *testing.T) {
+	for name, c := range map[string]struct {
+		openai string
+		gemini string
+		cfg    *config.Config
+		want   []string
+	}{
+		"no key":             {"", "", nil, []string{}},
+		"environment":        {"sk-a", "", nil, []string{"sk-a"}},
+		"comma-separated":    {" sk-a, ,sk-b ", "", nil, []string{"sk-a", "sk-b"}},
+		"over config":        {"sk-env", "", &config.Config{APIKey: "sk-cfg"}, []string{"sk-env"}},
+		"config":             {"", "", &config.Config{APIKey: "sk-a", APIKeys: []string{"sk-b", "sk-a", ""}}, []string{"sk-a", "sk-b"}},
+		"gemini environment": {"sk-a", "g-env", &config.Config{Provider: gpt.ProviderGemini, Gemini: &config.Gemini{APIKey: "g-cfg"}}, []string{"g-env"}},
+		"gemini config":      {"sk-a", "", &config.Config{Provider: gpt.ProviderGemini, APIKey: "sk-b", Gemini: &config.Gemini{APIKey: "g-cfg"}}, []string{"g-cfg"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OPENAI_API_KEY", c.openai)
+			t.Setenv("GEMINI_API_KEY", c.gemini)
+			assert.Equal(t, c.want, resolveAPIKeys(c.cfg))
+		})
+	}
+}
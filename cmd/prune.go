@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+// backupSuffix is appended to the path of catalogs to name their backup
+const backupSuffix = ".bak"
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the keys of target catalogs that the source no longer has",
+	Long: `Remove from the target catalogs the keys their source catalog no longer has, which
+otherwise accumulate forever and skew the status report. Only the stale keys are removed,
+the rest of each file is kept as written, and removals are recorded in the change history
+with the provider "prune". Use --dry-run to list the stale keys without writing, and
+--backup to keep a copy of each catalog changed next to it, with the suffix .bak.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		backup, _ := cmd.Flags().GetBool("backup")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "text" && format != "json" {
+			fmt.Printf("❌ Unknown format %q, use text or json\n", format)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			applyOutputSettings(cfg)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		if !dryRun {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		// Catalogs and their backups are written together once every one is pruned
+		tx := newTransaction()
+		auditProvider = "prune"
+		results, err := pruneCatalogs(pairs, targets, dryRun, backup, processOptions{FS: tx})
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if !dryRun {
+			if err := commitTransaction(context.Background(), tx); err != nil {
+				os.Exit(1)
+			}
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Print(pruneReport(results, dryRun))
+	},
+}
+
+// pruneResult is the stale keys of a target catalog
+type pruneResult struct {
+	File string   `json:"file"`
+	Lang string   `json:"lang"`
+	Keys []string `json:"keys"`
+	// Copy of the catalog before pruning, empty without --backup
+	Backup string `json:"backup,omitempty"`
+}
+
+// staleKeys returns the sorted keys of target that source doesn't have
+func staleKeys(source, target map[string]string) []string {
+	keys := []string{}
+	for k := range target {
+		if _, ok := source[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pruneCatalogs removes the stale keys of the target catalogs of pairs in the languages
+// of targets and writes them with opts, after a copy of their current content when
+// backup is set. Nothing is written with dryRun.
+func pruneCatalogs(pairs []scanner.FilePair, targets []string, dryRun, backup bool, opts processOptions) ([]pruneResult, error) {
+	results := []pruneResult{}
+	for _, pair := range pairs {
+		if !containsLanguage(targets, pair.TargetLang) {
+			continue
+		}
+		if _, err := os.Stat(pair.TargetFile); os.IsNotExist(err) {
+			continue
+		}
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			return nil, err
+		}
+		keys := staleKeys(source.LocaleItemsMap, target.LocaleItemsMap)
+		if len(keys) == 0 {
+			continue
+		}
+		result := pruneResult{File: pair.TargetFile, Lang: pair.TargetLang, Keys: keys}
+		if backup {
+			result.Backup = pair.TargetFile + backupSuffix
+		}
+		results = append(results, result)
+		if dryRun {
+			continue
+		}
+
+		if backup {
+			existing, err := opts.fs().ReadFile(target.Path)
+			if err != nil {
+				return nil, err
+			}
+			if err := opts.fs().WriteFile(result.Backup, existing); err != nil {
+				return nil, fmt.Errorf("error writing backup %s: %w", result.Backup, err)
+			}
+		}
+		for _, k := range keys {
+			delete(target.LocaleItemsMap, k)
+		}
+		if err := opts.writeLocaleFile(target); err != nil {
+			return nil, fmt.Errorf("error writing %s: %w", target.Path, err)
+		}
+	}
+	return results, nil
+}
+
+// pruneReport returns the stale keys of each catalog
+func pruneReport(results []pruneResult, dryRun bool) string {
+	if len(results) == 0 {
+		return "✅ No stale keys in the target catalogs\n"
+	}
+	tbl := table.New("File", "Language", "Stale Keys")
+	removed := 0
+	for _, r := range results {
+		tbl.Add(r.File, r.Lang, len(r.Keys))
+		removed += len(r.Keys)
+	}
+	report := tbl.String()
+
+	if dryRun {
+		for _, r := range results {
+			report += fmt.Sprintf("\n%s:\n  - %s\n", r.File, strings.Join(r.Keys, "\n  - "))
+		}
+		return report + fmt.Sprintf("\n🧪 Dry run: %d stale keys would be removed\n", removed)
+	}
+	if results[0].Backup != "" {
+		report += fmt.Sprintf("\n📝 Backups saved next to each catalog with the suffix %s\n", backupSuffix)
+	}
+	return report + fmt.Sprintf("\n✅ Removed %d stale keys from %d catalogs\n", removed, len(results))
+}
+
+func init() {
+	pruneCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	pruneCmd.Flags().String("source", "en", "Source language code (default: en)")
+	pruneCmd.Flags().String("config", "", "Path to configuration file")
+	pruneCmd.Flags().StringSlice("lang", nil, "Languages to prune (default: every target language)")
+	pruneCmd.Flags().Bool("dry-run", false, "List the stale keys without writing the catalogs")
+	pruneCmd.Flags().Bool("backup", false, "Keep a copy of each catalog changed next to it, with the suffix "+backupSuffix)
+	pruneCmd.Flags().BoolVar(&forceWrite, "force", false, "Write target files even if they would lose most of their keys or size")
+	pruneCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	pruneCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(pruneCmd)
+}
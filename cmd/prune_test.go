@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPruneCatalogs tests that stale keys are listed on dry runs, and removed with a
+// backup of the catalog otherwise
+func TestPruneCatalogs(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("en/common.json", "{\n  \"a\": \"A\",\n  \"menu\": {\n    \"open\": \"Open\"\n  }\n}\n")
+	fr := "{\n  \"a\": \"A fr\",\n  \"old\": \"Ancien\",\n  \"menu\": {\n    \"open\": \"Ouvrir\",\n    \"close\": \"Fermer\"\n  }\n}\n"
+	write("fr/common.json", fr)
+	write("de/common.json", "{\n  \"a\": \"A de\"\n}\n")
+
+	ds, err := scanCatalogs(root, "en", nil)
+	require.NoError(t, err)
+	pairs, err := ds.GetPairs()
+	require.NoError(t, err)
+	frPath := filepath.Join(root, "fr", "common.json")
+
+	results, err := pruneCatalogs(pairs, []string{"de", "fr"}, true, true, processOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []pruneResult{{File: frPath, Lang: "fr", Keys: []string{"menu/close", "old"}, Backup: frPath + backupSuffix}}, results)
+	data, err := os.ReadFile(frPath)
+	require.NoError(t, err)
+	assert.Equal(t, fr, string(data))
+	assert.Contains(t, pruneReport(results, true), "  - menu/close\n  - old\n")
+
+	tx := newTransaction()
+	_, err = pruneCatalogs(pairs, []string{"de", "fr"}, false, true, processOptions{FS: tx})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	data, err = os.ReadFile(frPath)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": \"A fr\",\n  \"menu\": {\n    \"open\": \"Ouvrir\"\n  }\n}\n", string(data))
+	backup, err := os.ReadFile(frPath + backupSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, fr, string(backup))
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyEnv is the environment variable enabling the read-only mode
+const readOnlyEnv = "I18N_CLI_READ_ONLY"
+
+// readOnlyAnnotation marks the commands allowed in read-only mode: those that only
+// inspect catalogs, without writing them or calling providers
+const readOnlyAnnotation = "readOnly"
+
+var readOnly bool // Only allow the commands that inspect catalogs
+
+// errReadOnly is returned by writes and provider calls attempted in read-only mode
+var errReadOnly = errors.New("disabled in read-only mode")
+
+// inspectOnly is the annotation of the commands allowed in read-only mode
+var inspectOnly = map[string]string{readOnlyAnnotation: "true"}
+
+// readOnlyMode tells whether cmd runs in read-only mode, enabled by --read-only, the
+// I18N_CLI_READ_ONLY environment variable or "readOnly" in the configuration file.
+// A configuration that can't be read is left to the command to report.
+func readOnlyMode(cmd *cobra.Command) bool {
+	if readOnly {
+		return true
+	}
+	if enabled, err := strconv.ParseBool(os.Getenv(readOnlyEnv)); err == nil && enabled {
+		return true
+	}
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		return false
+	}
+	cfg, err := config.LoadConfig(configPath)
+	return err == nil && cfg.ReadOnly
+}
+
+// allowedReadOnly tells whether cmd may run in read-only mode
+func allowedReadOnly(cmd *cobra.Command) bool {
+	if cmd.Annotations[readOnlyAnnotation] == "true" {
+		return true
+	}
+	// Help and shell completions, generated by cobra
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "help" || c.Name() == "completion" || c.Name() == cobra.ShellCompRequestCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReadOnly refuses to run cmd in read-only mode unless it only inspects catalogs.
+// The mode is then kept on, so writes and provider calls are refused down the line too.
+func checkReadOnly(cmd *cobra.Command, args []string) error {
+	if !readOnlyMode(cmd) {
+		return nil
+	}
+	readOnly = true
+	if !allowedReadOnly(cmd) {
+		return fmt.Errorf("%s is %w", cmd.CommandPath(), errReadOnly)
+	}
+	return nil
+}
+
+// checkWritable refuses writes in read-only mode
+func checkWritable(path string) error {
+	if readOnly {
+		return fmt.Errorf("writing %s is %w", path, errReadOnly)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadOnlyCommands tests which commands run in read-only mode
+func TestReadOnlyCommands(t *testing.T) {
+	t.Cleanup(func() { readOnly = false })
+
+	readOnly = true
+	assert.NoError(t, checkReadOnly(statusCmd, nil))
+	assert.NoError(t, checkReadOnly(runsListCmd, nil))
+	assert.ErrorIs(t, checkReadOnly(syncCmd, nil), errReadOnly)
+	assert.ErrorIs(t, checkReadOnly(cacheClearCmd, nil), errReadOnly)
+
+	readOnly = false
+	assert.NoError(t, checkReadOnly(syncCmd, nil))
+}
+
+// TestReadOnlyConfig tests that read-only mode is enabled by the configuration and the
+// environment, and then refuses writes and provider calls
+func TestReadOnlyConfig(t *testing.T) {
+	t.Cleanup(func() { readOnly = false })
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "i18n-config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"sourceLang": "en", "targetLangs": ["fr"], "readOnly": true}`), 0644))
+
+	cmd := &cobra.Command{Use: "write"}
+	cmd.Flags().String("config", "", "")
+	assert.NoError(t, checkReadOnly(cmd, nil))
+	assert.NoError(t, cmd.Flags().Set("config", configPath))
+	assert.ErrorIs(t, checkReadOnly(cmd, nil), errReadOnly)
+
+	assert.ErrorIs(t, writeFileAtomic(filepath.Join(dir, "fr.json"), []byte("{}")), errReadOnly)
+	assert.NoFileExists(t, filepath.Join(dir, "fr.json"))
+	_, err := newGPTHandler(nil, []string{"sk-test"}, 0)
+	assert.ErrorIs(t, err, errReadOnly)
+
+	readOnly = false
+	t.Setenv(readOnlyEnv, "1")
+	assert.ErrorIs(t, checkReadOnly(&cobra.Command{Use: "write"}, nil), errReadOnly)
+}
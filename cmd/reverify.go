@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+// improvedScore is the grade of an existing translation, against the fresh one of the
+// new model, at or below which the new model counts as an improvement
+const improvedScore = 3
+
+var reverifyCmd = &cobra.Command{
+	Use:   "reverify",
+	Short: "Compare existing translations with the output of a new model after an upgrade",
+	Long: `After the configured provider or model changed, re-verify the catalogs translated with
+the previous one: sample the translations of each namespace (catalog file) per language,
+translate them again with the new model, and have it grade the existing translations
+against the fresh ones. Namespaces are sampled in rounds of --sample keys until the share
+of translations the new model improves is known within --margin, or --max-sample keys
+were compared. The report estimates, per language and namespace, how many translations
+retranslating would improve. With --queue, the keys of the worst namespaces of each
+language improving at least --queue-threshold of their samples are flagged for
+retranslation by the next full run. The previous model is that of the last run of the
+journal translating the catalogs, unless --previous names it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		previous, _ := cmd.Flags().GetString("previous")
+		plan := samplingPlan{}
+		plan.Round, _ = cmd.Flags().GetInt("sample")
+		plan.Max, _ = cmd.Flags().GetInt("max-sample")
+		plan.Margin, _ = cmd.Flags().GetFloat64("margin")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		queue, _ := cmd.Flags().GetInt("queue")
+		queueThreshold, _ := cmd.Flags().GetFloat64("queue-threshold")
+		format, _ := cmd.Flags().GetString("format")
+
+		if plan.Round < 1 || plan.Max < plan.Round {
+			fmt.Printf("❌ --sample must be at least 1 and --max-sample at least --sample, got %d and %d\n", plan.Round, plan.Max)
+			os.Exit(1)
+		}
+		if format != "text" && format != "json" {
+			fmt.Printf("❌ Unknown format %q, use text or json\n", format)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		mark := marker.Default()
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			mark = cfg.Marker
+			applyOutputSettings(cfg)
+		}
+		if queue > 0 && mark.Style == marker.StyleNone {
+			fmt.Println("❌ Retranslation markers are disabled in the configuration, --queue can't flag keys")
+			os.Exit(1)
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			os.Exit(1)
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			os.Exit(1)
+		}
+		current := strings.TrimSpace(gptHandler.Provider() + " " + gptHandler.Model())
+
+		if previous == "" {
+			runs, err := journal.Load()
+			if err != nil {
+				fmt.Printf("⚠️ Error reading the run journal: %v\n", err)
+			}
+			run, ok := lastTranslation(runs, rootDir)
+			switch {
+			case !ok:
+				fmt.Println("⚠️ No run of the journal translated these catalogs, comparing with the configured model anyway; --previous names the model they were translated with")
+			case run.Provider == gptHandler.Provider() && run.Model == gptHandler.Model():
+				fmt.Printf("✅ The catalogs were last translated with %s, the configured model (run %s): nothing to re-verify\n", current, run.ID)
+				return
+			default:
+				previous = strings.TrimSpace(run.Provider + " " + run.Model)
+			}
+		}
+		if previous != "" {
+			fmt.Printf("💡 Re-verifying translations of %s with %s\n", previous, current)
+		}
+		defer printUsageReport(gptHandler)
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed))
+		ctx := cmd.Context()
+		results := []namespaceVerification{}
+		catalogs := map[string]translatedCatalog{}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			entries := memoryEntries(source.LocaleItemsMap, target.LocaleItemsMap, nil, pair.TargetLang)
+			if len(entries) == 0 {
+				continue
+			}
+			keys := make([]string, len(entries))
+			for i, e := range entries {
+				keys[i] = e.Key
+			}
+			// Entries are sorted by key, so the same seed always samples the same keys
+			rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+
+			fmt.Printf("🔍 Sampling %s %s\n", pair.TargetLang, pair.FileType)
+			v := verifyNamespace(gpt.WithLanguages(ctx, source.Code, target.Code), gptHandler, entries, target, plan)
+			v.Lang, v.Namespace = pair.TargetLang, pair.FileType
+			results = append(results, v)
+			catalogs[pair.TargetLang+":"+pair.FileType] = translatedCatalog{target, keys}
+		}
+		if len(results) == 0 {
+			fmt.Println("⚠️ No translated keys to re-verify")
+			return
+		}
+
+		if queue > 0 {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+
+			tx := newTransaction()
+			opts := processOptions{FS: tx}
+			auditProvider = "reverify"
+			for i := range results {
+				r := &results[i]
+				if !queuedNamespace(results, *r, queue, queueThreshold) {
+					continue
+				}
+				c := catalogs[r.Lang+":"+r.Namespace]
+				target := c.target
+				if _, err := mark.Mark(target, c.keys); err != nil {
+					fmt.Printf("❌ Error flagging %s: %v\n", target.Path, err)
+					os.Exit(1)
+				}
+				if mark.Style != marker.StyleSidecar {
+					if err := opts.writeLocaleFile(target); err != nil {
+						fmt.Printf("❌ Error writing %s: %v\n", target.Path, err)
+						os.Exit(1)
+					}
+				}
+				r.Queued = len(c.keys)
+			}
+			if err := commitTransaction(ctx, tx); err != nil {
+				os.Exit(1)
+			}
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Print(reverificationReport(results))
+	},
+}
+
+// lastTranslation returns the latest run of runs translating catalogs under rootDir with
+// a known model
+func lastTranslation(runs []journal.Run, rootDir string) (journal.Run, bool) {
+	root, err := filepath.Abs(rootDir)
+	if err != nil {
+		return journal.Run{}, false
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.Model == "" {
+			continue
+		}
+		for _, f := range run.Files {
+			path, err := filepath.Abs(f.Path)
+			if err == nil && f.Translated > 0 && strings.HasPrefix(path, root+string(filepath.Separator)) {
+				return run, true
+			}
+		}
+	}
+	return journal.Run{}, false
+}
+
+// offerReverification suggests the reverify command when the catalogs under rootDir were
+// last translated with another model than that of gptHandler
+func offerReverification(rootDir string, gptHandler *gpt.Handler) {
+	runs, err := journal.Load()
+	if err != nil {
+		return
+	}
+	run, ok := lastTranslation(runs, rootDir)
+	if !ok || (run.Provider == gptHandler.Provider() && run.Model == gptHandler.Model()) {
+		return
+	}
+	fmt.Printf("💡 These catalogs were last translated with %s %s, the configured model is now %s %s: 'i18n-cli reverify --root %s' estimates what retranslating them would improve\n",
+		run.Provider, run.Model, gptHandler.Provider(), gptHandler.Model(), rootDir)
+}
+
+// translatedCatalog is a target catalog and its translated keys
+type translatedCatalog struct {
+	target *parser.LocaleFileContent
+	keys   []string
+}
+
+// samplingPlan is how many translations of a namespace are compared
+type samplingPlan struct {
+	// Translations compared per round, and at most
+	Round int
+	Max   int
+	// Half-width of the 95% confidence interval of the improved share at which
+	// sampling stops
+	Margin float64
+}
+
+// verifier translates texts anew and grades existing translations against them
+type verifier interface {
+	Translate(ctx context.Context, text string, lang string) (string, error)
+	Grade(ctx context.Context, source, stored, fresh, lang string) (gpt.Grade, error)
+}
+
+var _ verifier = (*gpt.Handler)(nil)
+
+// namespaceVerification is the comparison of the translations of a namespace with those
+// of the new model
+type namespaceVerification struct {
+	Lang      string `json:"lang"`
+	Namespace string `json:"namespace"`
+	// Translated keys of the namespace, and those compared
+	Keys    int `json:"keys"`
+	Samples int `json:"samples"`
+	// Samples the new model translates better, and those it translates failing validation
+	Improved int `json:"improved"`
+	Rejected int `json:"rejected,omitempty"`
+	// Average grade of the existing translations, from 1 to 5
+	Score float64 `json:"score"`
+	// Share of the samples improved, and the half-width of its 95% confidence interval
+	Share  float64 `json:"share"`
+	Margin float64 `json:"margin"`
+	// Keys flagged for retranslation
+	Queued int `json:"queued,omitempty"`
+}
+
+// Estimate returns the number of keys of the namespace retranslating would improve
+func (v namespaceVerification) Estimate() int {
+	return int(math.Round(v.Share * float64(v.Keys)))
+}
+
+// improvementMargin returns the half-width of the 95% confidence interval of the share
+// of n samples improved, smoothed so that a few identical outcomes aren't certain
+func improvementMargin(improved, n int) float64 {
+	p := float64(improved+1) / float64(n+2)
+	return 1.96 * math.Sqrt(p*(1-p)/float64(n))
+}
+
+// verifyNamespace compares entries, shuffled translations of target, with fresh
+// translations of h in rounds, until the improved share is known within the margin of
+// plan or the maximum number of samples was compared
+func verifyNamespace(ctx context.Context, h verifier, entries []tm.Entry, target *parser.LocaleFileContent, plan samplingPlan) namespaceVerification {
+	v := namespaceVerification{Keys: len(entries), Margin: 1}
+	total := 0
+	for _, e := range entries {
+		if v.Samples >= plan.Max || (v.Samples > 0 && v.Samples%plan.Round == 0 && v.Margin <= plan.Margin) {
+			break
+		}
+		fresh, err := h.Translate(ctx, e.Source, target.Lang)
+		if err != nil {
+			fmt.Printf("⚠️ Error translating %s: %v\n", e.Key, err)
+			continue
+		}
+		v.Samples++
+		if checkTranslation(e.Source, fresh, target) != nil {
+			// A fresh translation breaking placeholders or markup improves nothing
+			v.Rejected++
+			total += 5
+		} else {
+			grade, err := h.Grade(ctx, e.Source, e.Target, fresh, target.Lang)
+			if err != nil {
+				fmt.Printf("⚠️ Error grading %s: %v\n", e.Key, err)
+				v.Samples--
+				continue
+			}
+			total += grade.Score
+			if grade.Score <= improvedScore {
+				v.Improved++
+			}
+		}
+		v.Margin = improvementMargin(v.Improved, v.Samples)
+	}
+	if v.Samples > 0 {
+		v.Score = float64(total) / float64(v.Samples)
+		v.Share = float64(v.Improved) / float64(v.Samples)
+	}
+	return v
+}
+
+// queuedNamespace reports whether v is one of the n namespaces of its language the new
+// model improves most, improving at least threshold of their samples
+func queuedNamespace(results []namespaceVerification, v namespaceVerification, n int, threshold float64) bool {
+	if v.Samples == 0 || v.Share < threshold {
+		return false
+	}
+	better := 0
+	for _, other := range results {
+		if other.Lang != v.Lang || other.Namespace == v.Namespace || other.Samples == 0 || other.Share < threshold {
+			continue
+		}
+		if other.Share > v.Share || (other.Share == v.Share && (other.Score < v.Score || (other.Score == v.Score && other.Namespace < v.Namespace))) {
+			better++
+		}
+	}
+	return better < n
+}
+
+// reverificationReport returns the comparison of each namespace, then the estimate of
+// each language
+func reverificationReport(results []namespaceVerification) string {
+	tbl := table.New("Language", "Namespace", "Keys", "Samples", "Improved", "Average Score", "Estimated Improvement", "Queued")
+	type total struct{ keys, estimate int }
+	totals := map[string]*total{}
+	langs := []string{}
+	for _, v := range results {
+		queued := "-"
+		if v.Queued > 0 {
+			queued = fmt.Sprintf("%d keys", v.Queued)
+		}
+		tbl.Add(v.Lang, v.Namespace, v.Keys, v.Samples, v.Improved, fmt.Sprintf("%.2f", v.Score),
+			fmt.Sprintf("%.0f%% ±%.0f%% (~%d keys)", v.Share*100, v.Margin*100, v.Estimate()), queued)
+		if totals[v.Lang] == nil {
+			totals[v.Lang] = &total{}
+			langs = append(langs, v.Lang)
+		}
+		totals[v.Lang].keys += v.Keys
+		totals[v.Lang].estimate += v.Estimate()
+	}
+	report := tbl.String() + "\n"
+	sort.Strings(langs)
+	for _, lang := range langs {
+		t := totals[lang]
+		report += fmt.Sprintf("📈 %s: retranslating would improve about %d of %d translations (%.0f%%)\n", lang, t.estimate, t.keys, float64(t.estimate)/float64(t.keys)*100)
+	}
+	for _, v := range results {
+		if v.Queued > 0 {
+			return report + "\n✅ Worst namespaces flagged for retranslation: run sync in full mode to retranslate them\n"
+		}
+	}
+	return report
+}
+
+func init() {
+	reverifyCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	reverifyCmd.Flags().String("source", "en", "Source language code (default: en)")
+	reverifyCmd.Flags().String("config", "", "Path to configuration file")
+	reverifyCmd.Flags().StringSlice("lang", nil, "Languages to re-verify (default: every target language)")
+	reverifyCmd.Flags().String("previous", "", "Model the catalogs were translated with (default: that of the last run of the journal translating them)")
+	reverifyCmd.Flags().Int("sample", 5, "Translations compared per namespace and round")
+	reverifyCmd.Flags().Int("max-sample", 20, "Translations compared per namespace at most")
+	reverifyCmd.Flags().Float64("margin", 0.15, "Margin of the improved share of a namespace at which sampling it stops")
+	reverifyCmd.Flags().Int64("seed", 0, "Random seed for sampling (default: current time)")
+	reverifyCmd.Flags().Int("queue", 0, "Worst namespaces per language whose keys are flagged for retranslation (0 flags none)")
+	reverifyCmd.Flags().Float64("queue-threshold", 0.3, "Share of the samples a namespace must improve to be flagged")
+	reverifyCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	reverifyCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(reverifyCmd)
+}
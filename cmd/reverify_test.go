@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/stretchr/testify/assert"
+)
+
+// gradingVerifier translates every text as "fresh" and grades the existing translations
+// by their text
+type gradingVerifier map[string]int
+
+func (v gradingVerifier) Translate(_ context.Context, text string, _ string) (string, error) {
+	return "fresh " + text, nil
+}
+
+func (v gradingVerifier) Grade(_ context.Context, _, stored, _, _ string) (gpt.Grade, error) {
+	return gpt.Grade{Score: v[stored]}, nil
+}
+
+// TestLastTranslation tests that the latest run translating catalogs under the root with
+// a known model is found
+func TestLastTranslation(t *testing.T) {
+	root := t.TempDir()
+	runs := []journal.Run{
+		{ID: "1", Model: "gpt-3.5-turbo", Files: []journal.File{{Path: filepath.Join(root, "fr", "common.json"), Translated: 3}}},
+		{ID: "2", Model: "gpt-4o", Files: []journal.File{{Path: filepath.Join(t.TempDir(), "fr", "common.json"), Translated: 3}}},
+		{ID: "3", Model: "gpt-4o", Files: []journal.File{{Path: filepath.Join(root, "de", "common.json")}}},
+		{ID: "4", Files: []journal.File{{Path: filepath.Join(root, "de", "common.json"), Translated: 1}}},
+	}
+	run, ok := lastTranslation(runs, root)
+	assert.True(t, ok)
+	assert.Equal(t, "1", run.ID)
+
+	_, ok = lastTranslation(runs[1:], root)
+	assert.False(t, ok)
+}
+
+// TestVerifyNamespace tests that sampling stops once the improved share is known within
+// the margin, and continues up to the maximum otherwise
+func TestVerifyNamespace(t *testing.T) {
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French"}
+	entries := func(targets ...string) []tm.Entry {
+		list := make([]tm.Entry, len(targets))
+		for i, text := range targets {
+			list[i] = tm.Entry{Key: text, Source: "Source", Target: text, Lang: "fr"}
+		}
+		return list
+	}
+	good := entries("a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u", "v")
+	grades := gradingVerifier{}
+	for _, e := range good {
+		grades[e.Target] = 5
+	}
+
+	// Every translation as good as the new model's: the share is soon known
+	v := verifyNamespace(context.Background(), grades, good, target, samplingPlan{Round: 5, Max: 20, Margin: 0.15})
+	assert.Equal(t, 22, v.Keys)
+	assert.Equal(t, 15, v.Samples)
+	assert.Equal(t, 0, v.Improved)
+	assert.Equal(t, 5.0, v.Score)
+	assert.Equal(t, 0, v.Estimate())
+
+	// Half of them improved: sampling goes on to the maximum
+	for i, e := range good {
+		if i%2 == 0 {
+			grades[e.Target] = 2
+		}
+	}
+	v = verifyNamespace(context.Background(), grades, good, target, samplingPlan{Round: 5, Max: 20, Margin: 0.15})
+	assert.Equal(t, 20, v.Samples)
+	assert.Equal(t, 10, v.Improved)
+	assert.Equal(t, 0.5, v.Share)
+	assert.Equal(t, 11, v.Estimate())
+	assert.Greater(t, v.Margin, 0.15)
+}
+
+// TestQueuedNamespace tests that the worst namespaces of each language above the
+// threshold are queued
+func TestQueuedNamespace(t *testing.T) {
+	results := []namespaceVerification{
+		{Lang: "fr", Namespace: "common", Samples: 10, Share: 0.6, Score: 3},
+		{Lang: "fr", Namespace: "errors", Samples: 10, Share: 0.8, Score: 2},
+		{Lang: "fr", Namespace: "home", Samples: 10, Share: 0.1, Score: 4.5},
+		{Lang: "de", Namespace: "common", Samples: 10, Share: 0.4, Score: 3.5},
+	}
+	queued := []string{}
+	for _, v := range results {
+		if queuedNamespace(results, v, 1, 0.3) {
+			queued = append(queued, v.Lang+":"+v.Namespace)
+		}
+	}
+	assert.Equal(t, []string{"fr:errors", "de:common"}, queued)
+	assert.True(t, queuedNamespace(results, results[0], 2, 0.3))
+	assert.False(t, queuedNamespace(results, results[2], 3, 0.3))
+}
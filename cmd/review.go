@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Have the model audit existing translations for correctness and fluency issues",
+	Long: `Send the existing translations of the target catalogs with their source to the model,
+asking for accuracy, fluency, terminology and formatting issues, and write the findings
+to a markdown or JSON report. Useful to audit old machine translations before a release.
+Locale files are never modified. Exits with a non-zero status when issues at or above
+--severity are found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		sampleSize, _ := cmd.Flags().GetInt("sample")
+		batch, _ := cmd.Flags().GetInt("batch")
+		severity, _ := cmd.Flags().GetString("severity")
+		outputPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		if gpt.SeverityRank(severity) < 0 {
+			fmt.Printf("❌ Unknown severity %q, use %s\n", severity, strings.Join(gpt.Severities, ", "))
+			os.Exit(1)
+		}
+		if format == "" {
+			format = "markdown"
+			if strings.EqualFold(filepath.Ext(outputPath), ".json") {
+				format = "json"
+			}
+		}
+		if format != "markdown" && format != "json" {
+			fmt.Printf("❌ Unknown format %q, use markdown or json\n", format)
+			os.Exit(1)
+		}
+		if batch < 1 {
+			batch = 1
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			os.Exit(1)
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(120)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			os.Exit(1)
+		}
+		defer printUsageReport(gptHandler)
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		entries := map[string][]reviewEntry{}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range memoryEntries(source.LocaleItemsMap, target.LocaleItemsMap, nil, pair.TargetLang) {
+				entries[pair.TargetLang] = append(entries[pair.TargetLang], reviewEntry{File: pair.TargetFile, Entry: e})
+			}
+		}
+
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		ctx := context.Background()
+		reviewed := map[string]int{}
+		findings := []reviewFinding{}
+		langs = make([]string, 0, len(entries))
+		for lang := range entries {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			sample := entries[lang]
+			if sampleSize > 0 && len(sample) > sampleSize {
+				rng.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+				sample = sample[:sampleSize]
+				sort.Slice(sample, func(i, j int) bool {
+					if sample[i].File != sample[j].File {
+						return sample[i].File < sample[j].File
+					}
+					return sample[i].Key < sample[j].Key
+				})
+			}
+			langName, err := langCodeToName(lang)
+			if err != nil {
+				langName = lang
+			}
+			fmt.Printf("🔍 Reviewing %d %s translations\n", len(sample), lang)
+			langFindings, err := reviewTranslations(gpt.WithLanguages(ctx, sourceLang, lang), gptHandler, sample, langName, batch)
+			if err != nil {
+				fmt.Printf("❌ Error reviewing %s: %v\n", lang, err)
+				os.Exit(1)
+			}
+			reviewed[lang] = len(sample)
+			findings = append(findings, atSeverity(langFindings, severity)...)
+		}
+
+		var report string
+		if format == "json" {
+			data, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding report: %v\n", err)
+				os.Exit(1)
+			}
+			report = string(data) + "\n"
+		} else {
+			report = reviewReport(langs, reviewed, findings)
+		}
+		if outputPath != "" {
+			if err := os.WriteFile(outputPath, []byte(report), 0644); err != nil {
+				fmt.Printf("❌ Error writing report: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Review report saved to %s\n", outputPath)
+		} else {
+			fmt.Print("\n" + report)
+		}
+
+		total := 0
+		for _, n := range reviewed {
+			total += n
+		}
+		if len(findings) > 0 {
+			fmt.Printf("\n⚠️ Found %d issues in %d reviewed translations\n", len(findings), total)
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ No issues in %d reviewed translations\n", total)
+	},
+}
+
+// reviewer reviews translations with their source
+type reviewer interface {
+	Review(ctx context.Context, items []gpt.ReviewItem, lang string) ([][]gpt.Issue, error)
+}
+
+var _ reviewer = (*gpt.Handler)(nil)
+
+// reviewEntry is an existing translation of a target file
+type reviewEntry struct {
+	File string `json:"file"`
+	tm.Entry
+}
+
+// reviewFinding is an issue found in an existing translation
+type reviewFinding struct {
+	File        string `json:"file"`
+	Key         string `json:"key"`
+	Lang        string `json:"lang"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	gpt.Issue
+}
+
+// reviewTranslations reviews entries into langName, batch translations per request
+func reviewTranslations(ctx context.Context, h reviewer, entries []reviewEntry, langName string, batch int) ([]reviewFinding, error) {
+	findings := []reviewFinding{}
+	for start := 0; start < len(entries); start += batch {
+		end := start + batch
+		if end > len(entries) {
+			end = len(entries)
+		}
+		items := make([]gpt.ReviewItem, 0, end-start)
+		for _, e := range entries[start:end] {
+			items = append(items, gpt.ReviewItem{Key: e.Key, Source: e.Source, Translation: e.Target})
+		}
+		issues, err := h.Review(ctx, items, langName)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range entries[start:end] {
+			for _, issue := range issues[i] {
+				findings = append(findings, reviewFinding{File: e.File, Key: e.Key, Lang: e.Lang, Source: e.Source, Translation: e.Target, Issue: issue})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// atSeverity returns the findings at or above severity, the most serious first
+func atSeverity(findings []reviewFinding, severity string) []reviewFinding {
+	least := gpt.SeverityRank(severity)
+	kept := []reviewFinding{}
+	for _, f := range findings {
+		if gpt.SeverityRank(f.Severity) >= least {
+			kept = append(kept, f)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return gpt.SeverityRank(kept[i].Severity) > gpt.SeverityRank(kept[j].Severity)
+	})
+	return kept
+}
+
+// reviewReport returns the review report in markdown: the counts per language and
+// severity, then the findings with their suggestion
+func reviewReport(langs []string, reviewed map[string]int, findings []reviewFinding) string {
+	var report strings.Builder
+	report.WriteString("# Translation Review\n\n")
+
+	counts := map[string]map[string]int{}
+	for _, f := range findings {
+		if counts[f.Lang] == nil {
+			counts[f.Lang] = map[string]int{}
+		}
+		counts[f.Lang][f.Severity]++
+	}
+	summary := table.New("Language", "Reviewed", "Critical", "Major", "Minor")
+	for _, lang := range langs {
+		c := counts[lang]
+		summary.Add(lang, reviewed[lang], c[gpt.SeverityCritical], c[gpt.SeverityMajor], c[gpt.SeverityMinor])
+	}
+	report.WriteString(summary.String())
+
+	if len(findings) == 0 {
+		return report.String()
+	}
+	report.WriteString("\n## Findings\n\n")
+	tbl := table.New("Key", "Language", "Severity", "Category", "Source", "Translation", "Issue", "Suggestion")
+	tbl.MaxWidth = historyCellWidth
+	for _, f := range findings {
+		tbl.Add(f.File+":"+f.Key, f.Lang, f.Severity, f.Category, f.Source, f.Translation, f.Description, f.Suggestion)
+	}
+	report.WriteString(tbl.String())
+	return report.String()
+}
+
+func init() {
+	reviewCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	reviewCmd.Flags().String("source", "en", "Source language code (default: en)")
+	reviewCmd.Flags().String("config", "", "Path to configuration file")
+	reviewCmd.Flags().StringSlice("lang", nil, "Languages to review (default: every target language)")
+	reviewCmd.Flags().Int("sample", 0, "Number of translations to review per language, picked at random (0 reviews them all)")
+	reviewCmd.Flags().Int("batch", 10, "Number of translations reviewed per request")
+	reviewCmd.Flags().String("severity", gpt.SeverityMinor, "Least serious issues reported: minor, major or critical")
+	reviewCmd.Flags().String("output", "", "Save the report to a file")
+	reviewCmd.Flags().String("format", "", "Report format: markdown or json (default: json for a .json --output, markdown otherwise)")
+
+	reviewCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(reviewCmd)
+}
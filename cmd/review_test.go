@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReviewer reports an issue for the translations equal to their source
+type fakeReviewer struct {
+	batches int
+}
+
+func (f *fakeReviewer) Review(ctx context.Context, items []gpt.ReviewItem, lang string) ([][]gpt.Issue, error) {
+	f.batches++
+	issues := make([][]gpt.Issue, len(items))
+	for i, item := range items {
+		if item.Source == item.Translation {
+			issues[i] = []gpt.Issue{{Category: "accuracy", Severity: gpt.SeverityMajor, Description: "left untranslated in " + lang}}
+		}
+		if item.Key == "legal" {
+			issues[i] = append(issues[i], gpt.Issue{Category: "fluency", Severity: gpt.SeverityMinor, Description: "stiff"})
+		}
+	}
+	return issues, nil
+}
+
+// TestReviewTranslations tests that translations are reviewed in batches and reported
+// from the most serious issue
+func TestReviewTranslations(t *testing.T) {
+	entries := []reviewEntry{
+		{File: "fr/common.json", Entry: tm.Entry{Key: "cancel", Source: "Cancel", Target: "Annuler", Lang: "fr"}},
+		{File: "fr/common.json", Entry: tm.Entry{Key: "legal", Source: "Terms", Target: "Conditions", Lang: "fr"}},
+		{File: "fr/common.json", Entry: tm.Entry{Key: "save", Source: "Save", Target: "Save", Lang: "fr"}},
+	}
+	h := &fakeReviewer{}
+	findings, err := reviewTranslations(context.Background(), h, entries, "French", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.batches)
+
+	findings = atSeverity(findings, gpt.SeverityMinor)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "save", findings[0].Key)
+	assert.Equal(t, "left untranslated in French", findings[0].Description)
+	assert.Equal(t, "legal", findings[1].Key)
+	assert.Len(t, atSeverity(findings, gpt.SeverityMajor), 1)
+
+	report := reviewReport([]string{"fr"}, map[string]int{"fr": 3}, findings)
+	assert.Contains(t, report, "## Findings")
+	assert.Contains(t, report, "fr/common.json:save")
+	assert.NotContains(t, reviewReport([]string{"fr"}, map[string]int{"fr": 3}, nil), "## Findings")
+}
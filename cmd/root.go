@@ -4,9 +4,11 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/pandodao/i18n-cli/internal/state"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -17,21 +19,53 @@ var (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use: "translate",
+	Use:               "translate",
+	PersistentPreRunE: prepareRun,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interruptRun = cancel
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// prepareRun checks cmd may run in the current mode, then applies the file limits of
+// its configuration
+func prepareRun(cmd *cobra.Command, args []string) error {
+	if err := checkReadOnly(cmd, args); err != nil {
+		return err
+	}
+	applyFileLimits(cmd)
+	selectProject(cmd)
+	return nil
+}
+
+// projectFlags are the flags naming the catalog root of a command, on which the project
+// state is keyed
+var projectFlags = []string{"root", "dir", "content"}
+
+// selectProject keys the project state of the run on the catalog root of cmd, so that
+// running from another directory doesn't split the state of a project. Commands without
+// one use the working directory.
+func selectProject(cmd *cobra.Command) {
+	for _, name := range projectFlags {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() != "" {
+			state.SetProject(f.Value.String())
+			return
+		}
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initOpenAI, initLogging)
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "toggle debug mode")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Only allow the commands that inspect catalogs, never writing files or calling providers")
 }
 
 func initOpenAI() {
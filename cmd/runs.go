@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/audit"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// invocation is the command line of this process, recorded with each of its runs
+var invocation journal.Run
+
+// runPasses counts the runs of this process, as sync --watch makes one per change
+var runPasses int
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspect the journal of past translate and sync runs",
+}
+
+var runsListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List past runs, most recent first",
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetString("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+		format, _ := cmd.Flags().GetString("format")
+
+		runs, err := journal.Load()
+		if err != nil {
+			fmt.Printf("❌ Error reading the run journal: %v\n", err)
+			return
+		}
+		if since != "" {
+			from, err := parseSince(since, time.Now())
+			if err != nil {
+				fmt.Printf("❌ Invalid --since: %v\n", err)
+				return
+			}
+			runs = runsSince(runs, from)
+		}
+		runs = latestRuns(runs, limit)
+
+		if format == "json" {
+			data, err := json.MarshalIndent(runs, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding runs: %v\n", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+		if len(runs) == 0 {
+			fmt.Println("No recorded runs")
+			return
+		}
+		fmt.Print(runsTable(runs))
+	},
+}
+
+var runsShowCmd = &cobra.Command{
+	Use:         "show <id>",
+	Short:       "Show the settings and outcome of a run",
+	Long:        `Show the command line, settings, usage and per-file outcome of a run. The ID may be shortened to any unambiguous prefix.`,
+	Annotations: inspectOnly,
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+
+		runs, err := journal.Load()
+		if err != nil {
+			fmt.Printf("❌ Error reading the run journal: %v\n", err)
+			return
+		}
+		run, err := journal.Find(runs, args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(run, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding the run: %v\n", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Print(runDetails(run))
+	},
+}
+
+// runRecord collects the journal entry of a run as it goes. A nil record records nothing.
+type runRecord struct {
+	mu  sync.Mutex
+	run journal.Run
+}
+
+// startInvocation records the command line of cmd and its configuration file, if any,
+// for the runs it makes
+func startInvocation(cmd *cobra.Command, configPath string) {
+	flags := map[string]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	invocation = journal.Run{
+		Command: cmd.Name(),
+		Args:    os.Args[1:],
+		Flags:   flags,
+		Config:  configPath,
+		User:    audit.CurrentUser(),
+	}
+}
+
+// startRun starts recording a run of the invocation with gptHandler. The runs after
+// the first one of a process get their own ID, which their changes are logged with.
+func startRun(gptHandler *gpt.Handler) *runRecord {
+	runPasses++
+	if runPasses == 1 {
+		invocation.ID = runID
+	} else {
+		runID = fmt.Sprintf("%s-%d", invocation.ID, runPasses)
+	}
+
+	run := invocation
+	run.ID = runID
+	run.Provider = gptHandler.Provider()
+	run.Model = gptHandler.Model()
+	run.StartedAt = time.Now().UTC()
+	if run.Config != "" {
+		if data, err := os.ReadFile(run.Config); err == nil {
+			sum := sha256.Sum256(data)
+			run.ConfigHash = hex.EncodeToString(sum[:6])
+		}
+	}
+	return &runRecord{run: run}
+}
+
+// addFile records the outcome of a run for a target file
+func (r *runRecord) addFile(path string, keys, translated int, failed []string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Files = append(r.run.Files, journal.File{Path: path, Keys: keys, Translated: translated, Failed: append([]string(nil), failed...)})
+}
+
+// finish records the run in the journal with the usage of gptHandler. err is the error
+// ending the run early, an exhausted budget aborting it rather than failing it. Failing
+// to record is reported but doesn't fail the run.
+func (r *runRecord) finish(gptHandler *gpt.Handler, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Duration = time.Since(r.run.StartedAt).Round(time.Millisecond)
+	r.run.Usage = gptHandler.Usage()
+	r.run.Status = journal.StatusCompleted
+	switch {
+	case errors.Is(err, gpt.ErrBudgetExhausted):
+		r.run.Status = journal.StatusAborted
+	case err != nil:
+		r.run.Status = journal.StatusFailed
+	}
+	if err != nil {
+		r.run.Error = err.Error()
+	}
+	if err := journal.Record(r.run); err != nil {
+		fmt.Printf("⚠️ Error recording the run in the journal: %v\n", err)
+	}
+}
+
+// parseSince parses the start of the period of --since, either a duration before now
+// such as 72h or 7d, or a date such as 2024-05-01
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err == nil && fmt.Sprint(n) == days {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a duration (72h, 7d) nor a date (2006-01-02)", value)
+}
+
+// runsSince returns the runs started from t
+func runsSince(runs []journal.Run, t time.Time) []journal.Run {
+	filtered := []journal.Run{}
+	for _, r := range runs {
+		if !r.StartedAt.Before(t) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// latestRuns returns the limit most recent runs, most recent first, all of them when
+// limit is 0
+func latestRuns(runs []journal.Run, limit int) []journal.Run {
+	latest := make([]journal.Run, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		if limit > 0 && len(latest) == limit {
+			break
+		}
+		latest = append(latest, runs[i])
+	}
+	return latest
+}
+
+// runsTable returns the table listing runs
+func runsTable(runs []journal.Run) string {
+	tbl := table.New("ID", "Started", "Command", "Model", "Duration", "Files", "Translated", "Failed", "Status")
+	for _, r := range runs {
+		tbl.Add(r.ID, r.StartedAt.Local().Format("2006-01-02 15:04"), r.Command, r.Model, r.Duration.Round(time.Second), len(r.Files), r.Translated(), r.Failed(), runStatusIcon(r.Status)+" "+r.Status)
+	}
+	return tbl.String()
+}
+
+// runDetails returns the description of a run
+func runDetails(r journal.Run) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Run %s\n\n", r.ID)
+	fmt.Fprintf(&b, "Status:    %s %s\n", runStatusIcon(r.Status), r.Status)
+	if r.Error != "" {
+		fmt.Fprintf(&b, "Error:     %s\n", r.Error)
+	}
+	fmt.Fprintf(&b, "Command:   i18n-cli %s\n", strings.Join(r.Args, " "))
+	fmt.Fprintf(&b, "Started:   %s by %s\n", r.StartedAt.Local().Format(time.RFC3339), r.User)
+	fmt.Fprintf(&b, "Duration:  %s\n", r.Duration.Round(time.Second))
+	fmt.Fprintf(&b, "Provider:  %s/%s\n", r.Provider, r.Model)
+	if r.Config != "" {
+		fmt.Fprintf(&b, "Config:    %s (sha256 %s)\n", r.Config, r.ConfigHash)
+	}
+	if len(r.Flags) > 0 {
+		flags := make([]string, 0, len(r.Flags))
+		for _, name := range orderedKeys(r.Flags, nil) {
+			flags = append(flags, "--"+name+"="+r.Flags[name])
+		}
+		fmt.Fprintf(&b, "Flags:     %s\n", strings.Join(flags, " "))
+	}
+	fmt.Fprintf(&b, "Usage:     %d requests, %d prompt + %d completion tokens\n", r.Usage.Requests, r.Usage.PromptTokens, r.Usage.CompletionTokens)
+
+	if len(r.Files) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n")
+	tbl := table.New("File", "Keys", "Translated", "Failed")
+	for _, f := range r.Files {
+		tbl.Add(f.Path, f.Keys, f.Translated, len(f.Failed))
+	}
+	b.WriteString(tbl.String())
+	for _, f := range r.Files {
+		if len(f.Failed) > 0 {
+			fmt.Fprintf(&b, "\nFailed keys of %s:\n", f.Path)
+			for _, k := range f.Failed {
+				fmt.Fprintf(&b, "  - %s\n", k)
+			}
+		}
+	}
+	return b.String()
+}
+
+func runStatusIcon(status string) string {
+	switch status {
+	case journal.StatusCompleted:
+		return "✅"
+	case journal.StatusAborted:
+		return "🛑"
+	}
+	return "❌"
+}
+
+func init() {
+	for _, c := range []*cobra.Command{runsListCmd, runsShowCmd} {
+		c.Flags().String("root", "", "Root directory of the catalogs whose runs to show (default: the working directory)")
+	}
+	runsListCmd.Flags().String("since", "", "Only list the runs started since a duration ago (72h, 7d) or a date (2006-01-02)")
+	runsListCmd.Flags().Int("limit", 20, "Maximum number of runs to list (0 for all)")
+	runsListCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+	runsShowCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	runsCmd.AddCommand(runsListCmd, runsShowCmd)
+	rootCmd.AddCommand(runsCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSince tests durations, day counts and dates of --since
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local)
+
+	from, err := parseSince("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local), from)
+
+	from, err = parseSince("90m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), from)
+
+	from, err = parseSince("2024-05-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), from)
+
+	_, err = parseSince("last week", now)
+	assert.Error(t, err)
+}
+
+// TestLatestRuns tests that runs are listed most recent first, up to the limit
+func TestLatestRuns(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	runs := []journal.Run{{ID: "a", StartedAt: start}, {ID: "b", StartedAt: start.Add(time.Hour)}, {ID: "c", StartedAt: start.Add(2 * time.Hour)}}
+
+	latest := latestRuns(runsSince(runs, start.Add(time.Hour)), 0)
+	require.Len(t, latest, 2)
+	assert.Equal(t, "c", latest[0].ID)
+	assert.Equal(t, "b", latest[1].ID)
+
+	latest = latestRuns(runs, 1)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "c", latest[0].ID)
+}
+
+// TestRunRecord tests that runs are journaled with the outcome of their files, later
+// runs of a process getting their own ID
+func TestRunRecord(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defer func(id string, passes int, inv journal.Run) { runID, runPasses, invocation = id, passes, inv }(runID, runPasses, invocation)
+	runPasses = 0
+	invocation = journal.Run{Command: "sync", Args: []string{"sync", "--root", "locales"}, Flags: map[string]string{"root": "locales"}}
+	gptHandler := gpt.New(gpt.Config{})
+	first := runID
+
+	record := startRun(gptHandler)
+	record.addFile("locales/fr.json", 3, 2, []string{"title"})
+	record.finish(gptHandler, nil)
+
+	record = startRun(gptHandler)
+	assert.Equal(t, first+"-2", runID)
+	record.finish(gptHandler, gpt.ErrCostLimit)
+
+	runs, err := journal.Load()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, first, runs[0].ID)
+	assert.Equal(t, journal.StatusCompleted, runs[0].Status)
+	assert.Equal(t, 2, runs[0].Translated())
+	assert.Equal(t, []string{"title"}, runs[0].Files[0].Failed)
+	assert.Equal(t, first+"-2", runs[1].ID)
+	assert.Equal(t, journal.StatusAborted, runs[1].Status)
+
+	details := runDetails(runs[0])
+	assert.Contains(t, details, "i18n-cli sync --root locales")
+	assert.Contains(t, details, "--root=locales")
+	assert.Contains(t, details, "  - title")
+}
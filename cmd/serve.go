@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/lock"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/staging"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a review page and API to approve or reject translations held for review",
+	Long: `Serve the machine translations held in the staging store by sync --stage for review.
+Approved translations, possibly edited, are written to their catalogs; rejected ones
+are dropped and translated again by the next sync.
+
+  GET  /                          review page
+  GET  /api/pending               pending translations
+  POST /api/pending/<id>/approve  write a translation, {"translation": "..."} to edit it
+  POST /api/pending/<id>/reject   drop a translation
+
+Requests must be addressed to --addr, and decisions must be JSON carrying the token of
+the review page in the X-Review-Token header, so that other web pages can't make them.
+Decisions take the project lock of --root, and are refused with 409 Conflict while a
+run holds it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		configPath, _ := cmd.Flags().GetString("config")
+		addr, _ := cmd.Flags().GetString("addr")
+
+		m := marker.Default()
+		if configPath != "" {
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				fmt.Printf("❌ Error loading configuration: %v\n", err)
+				return
+			}
+			m = cfg.Marker
+		}
+
+		store, err := staging.Load()
+		if err != nil {
+			fmt.Printf("❌ Error loading the staging store: %v\n", err)
+			return
+		}
+		fmt.Printf("📋 Serving the review of %d pending translations on %s\n", len(store), addr)
+		server, err := newReviewServer(m, rootDir, addr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if err := http.ListenAndServe(addr, server); err != nil {
+			fmt.Printf("❌ Review server stopped: %v\n", err)
+		}
+	},
+}
+
+// tokenHeader is the header of decisions carrying the token of the review page
+const tokenHeader = "X-Review-Token"
+
+// reviewServer serves the staging store. Decisions are serialized, as they rewrite
+// the store and catalogs, and take the project lock so that runs don't overwrite them.
+type reviewServer struct {
+	mu     sync.Mutex
+	marker marker.Marker
+	// Root directory of the catalogs, holding the project lock
+	root string
+	mux  *http.ServeMux
+	// Host headers requests may carry, those of the served address
+	hosts map[string]bool
+	// Token of this process embedded in the review page, required by decisions
+	token string
+}
+
+// newReviewServer returns the handler of the review page and API of the catalogs in
+// root, served on addr
+func newReviewServer(m marker.Marker, root, addr string) (*reviewServer, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("error generating the review token: %w", err)
+	}
+	s := &reviewServer{marker: m, root: root, mux: http.NewServeMux(), hosts: servedHosts(addr), token: hex.EncodeToString(token)}
+	s.mux.HandleFunc("/", s.page)
+	s.mux.HandleFunc("/api/pending", s.list)
+	s.mux.HandleFunc("/api/pending/", s.decide)
+	return s, nil
+}
+
+// servedHosts returns the Host headers of requests addressed to addr: addr itself, and
+// the loopback names with its port when addr is a loopback or unspecified address
+func servedHosts(addr string) map[string]bool {
+	hosts := map[string]bool{addr: true}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return hosts
+	}
+	switch host {
+	case "", "0.0.0.0", "::", "localhost", "127.0.0.1", "::1":
+		for _, h := range []string{"localhost", "127.0.0.1", "::1"} {
+			hosts[net.JoinHostPort(h, port)] = true
+		}
+	}
+	return hosts
+}
+
+// ServeHTTP rejects requests addressed to another host, such as those of DNS
+// rebinding, or sent by pages of another origin
+func (s *reviewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.hosts[r.Host] {
+		http.Error(w, "unexpected host "+r.Host, http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !s.hosts[u.Host] {
+			http.Error(w, "unexpected origin "+origin, http.StatusForbidden)
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *reviewServer) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, strings.Replace(reviewPage, "{{token}}", s.token, 1))
+}
+
+func (s *reviewServer) list(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	store, err := staging.Load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.Entries())
+}
+
+// decide approves or rejects a pending translation, at /api/pending/<id>/<decision>
+func (s *reviewServer) decide(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/pending/"), "/")
+	if len(parts) != 2 || parts[1] != "approve" && parts[1] != "reject" {
+		http.NotFound(w, r)
+		return
+	}
+	// Pages of other origins can't send JSON or custom headers without a preflight
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "decisions must be sent as application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(tokenHeader)), []byte(s.token)) != 1 {
+		http.Error(w, "missing or invalid review token", http.StatusForbidden)
+		return
+	}
+	var body struct {
+		Translation *string `json:"translation"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, err := lock.Acquire(filepath.Join(s.root, lock.FileName), "i18n-cli serve")
+	var held *lock.HeldError
+	if errors.As(err, &held) {
+		http.Error(w, fmt.Sprintf("%q is running on these catalogs, decide again once it is done", held.Info.Command), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer l.Release()
+
+	store, err := staging.Load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	e, ok := store[parts[0]]
+	if !ok {
+		http.Error(w, "no pending translation "+parts[0], http.StatusNotFound)
+		return
+	}
+
+	if parts[1] == "approve" {
+		translation := e.Translation
+		if body.Translation != nil {
+			translation = *body.Translation
+		}
+		if strings.TrimSpace(translation) == "" {
+			http.Error(w, "empty translation", http.StatusBadRequest)
+			return
+		}
+		if err := approveStaged(e, translation, s.marker); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Printf("✅ Approved %s in %s\n", e.Key, e.File)
+	} else {
+		fmt.Printf("🗑️ Rejected %s in %s\n", e.Key, e.File)
+	}
+
+	delete(store, e.ID)
+	if err := store.Save(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// reviewPage lists the pending translations with approve and reject buttons
+const reviewPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="review-token" content="{{token}}">
+<title>i18n-cli review</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: .5em; text-align: left; vertical-align: top; }
+textarea { width: 100%; min-height: 3em; }
+.previous { color: #888; font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>Pending translations</h1>
+<p id="empty" hidden>Nothing to review.</p>
+<table>
+<thead><tr><th>File</th><th>Key</th><th>Source</th><th>Translation</th><th></th></tr></thead>
+<tbody id="pending"></tbody>
+</table>
+<script>
+const token = document.querySelector('meta[name="review-token"]').content;
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text;
+  if (className) td.className = className;
+  return td;
+}
+
+async function decide(id, decision, translation) {
+  const res = await fetch("/api/pending/" + id + "/" + decision, {
+    method: "POST",
+    headers: {"Content-Type": "application/json", "X-Review-Token": token},
+    body: decision === "approve" ? JSON.stringify({translation}) : "",
+  });
+  if (!res.ok) alert(await res.text());
+  load();
+}
+
+async function load() {
+  const entries = await (await fetch("/api/pending")).json();
+  const body = document.getElementById("pending");
+  body.innerHTML = "";
+  document.getElementById("empty").hidden = entries.length > 0;
+  for (const e of entries) {
+    const row = body.insertRow();
+    cell(row, e.file + " (" + e.lang + ")");
+    cell(row, e.key);
+    cell(row, e.source);
+    const td = row.insertCell();
+    const input = document.createElement("textarea");
+    input.value = e.translation;
+    td.appendChild(input);
+    if (e.previous) {
+      const prev = document.createElement("div");
+      prev.className = "previous";
+      prev.textContent = "Replaces: " + e.previous;
+      td.appendChild(prev);
+    }
+    const actions = row.insertCell();
+    for (const decision of ["approve", "reject"]) {
+      const button = document.createElement("button");
+      button.textContent = decision === "approve" ? "Approve" : "Reject";
+      button.onclick = () => decide(e.id, decision, input.value);
+      actions.appendChild(button);
+    }
+  }
+}
+
+load();
+</script>
+</body>
+</html>
+`
+
+func init() {
+	serveCmd.Flags().String("root", "", "Root directory of the catalogs whose staged translations to review")
+	serveCmd.Flags().String("config", "", "Path to configuration file, for the retranslation marker style")
+	serveCmd.Flags().String("addr", "localhost:8080", "Address to serve the review page and API on")
+
+	serveCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(serveCmd)
+}
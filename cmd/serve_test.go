@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/lock"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/staging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHoldForReview tests that staged runs write new translations to the staging store
+// only, and leave the keys pending review out of the next runs
+func TestHoldForReview(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"a": "One", "b": "Two"}}
+	target := &parser.LocaleFileContent{Code: "de", Lang: "Deutsch", Path: "/locales/de.json"}
+	require.NoError(t, target.ParseJSON([]byte(`{"a": "Eins"}`)))
+
+	translator := &fakeTranslator{translate: mockTranslate("Deutsch", nil)}
+	previous := auditProvider
+	auditProvider = "openai/gpt-4o"
+	defer func() { auditProvider = previous }()
+	fsys := memFS{}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: fsys, Stage: true}
+	require.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+
+	written := &parser.LocaleFileContent{}
+	require.NoError(t, written.ParseJSON(fsys[target.Path]))
+	assert.Equal(t, map[string]string{"a": "Eins"}, written.LocaleItemsMap)
+
+	store, err := staging.Load()
+	require.NoError(t, err)
+	e := store[staging.ID(target.Path, "b")]
+	assert.Equal(t, staging.Entry{ID: e.ID, File: target.Path, Key: "b", Lang: "de", Source: "Two", Translation: "TRANSLATED:Two", Provider: "openai/gpt-4o", StagedAt: testTime.UTC()}, e)
+
+	opts.Held = store.Keys(target.Path)
+	texts, err := pendingTexts(source, written, nil, opts)
+	require.NoError(t, err)
+	assert.Empty(t, texts)
+}
+
+// TestReviewServer tests that approved translations are written to their catalog,
+// with reviewer edits, and that rejected ones are dropped
+func TestReviewServer(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	catalog := filepath.Join(dir, "fr", "app.json")
+	store := staging.Store{}
+	store.Add(staging.Entry{File: catalog, Key: "a", Lang: "fr", Source: "One", Translation: "Un"})
+	store.Add(staging.Entry{File: catalog, Key: "b", Lang: "fr", Source: "Two", Translation: "Deux"})
+	store.Add(staging.Entry{File: catalog, Key: "c", Lang: "fr", Source: "Three", Translation: "Troi"})
+	require.NoError(t, store.Save())
+
+	server := httptest.NewUnstartedServer(nil)
+	review, err := newReviewServer(marker.Default(), dir, server.Listener.Addr().String())
+	require.NoError(t, err)
+	server.Config.Handler = review
+	server.Start()
+	defer server.Close()
+	post := func(id, decision, body string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/pending/"+id+"/"+decision, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tokenHeader, review.token)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// Decisions wait for the runs holding the project lock
+	held, err := lock.Acquire(filepath.Join(dir, lock.FileName), "i18n-cli sync")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, post(staging.ID(catalog, "a"), "approve", ""))
+	require.NoError(t, held.Release())
+
+	assert.Equal(t, http.StatusOK, post(staging.ID(catalog, "a"), "approve", ""))
+	assert.Equal(t, http.StatusOK, post(staging.ID(catalog, "c"), "approve", `{"translation": "Trois"}`))
+	assert.Equal(t, http.StatusOK, post(staging.ID(catalog, "b"), "reject", ""))
+	assert.Equal(t, http.StatusNotFound, post(staging.ID(catalog, "b"), "reject", ""))
+	assert.Equal(t, http.StatusNotFound, post("unknown", "approve", ""))
+
+	data, err := os.ReadFile(catalog)
+	require.NoError(t, err)
+	written := &parser.LocaleFileContent{}
+	require.NoError(t, written.ParseJSON(data))
+	assert.Equal(t, map[string]string{"a": "Un", "c": "Trois"}, written.LocaleItemsMap)
+
+	res, err := http.Get(server.URL + "/api/pending")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	pending, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(pending))
+}
+
+// TestReviewServerForgery tests that decisions without the token of the page, in another
+// content type, or from other origins and hosts are refused
+func TestReviewServerForgery(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	catalog := filepath.Join(dir, "fr", "app.json")
+	store := staging.Store{}
+	store.Add(staging.Entry{File: catalog, Key: "a", Lang: "fr", Source: "One", Translation: "Un"})
+	require.NoError(t, store.Save())
+	id := staging.ID(catalog, "a")
+
+	review, err := newReviewServer(marker.Default(), dir, "localhost:8080")
+	require.NoError(t, err)
+	decide := func(host string, header map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "http://"+host+"/api/pending/"+id+"/approve", strings.NewReader(`{"translation": "<script>"}`))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		review.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	valid := map[string]string{"Content-Type": "application/json", tokenHeader: review.token}
+	assert.Equal(t, http.StatusUnsupportedMediaType, decide("localhost:8080", map[string]string{"Content-Type": "text/plain", tokenHeader: review.token}))
+	assert.Equal(t, http.StatusForbidden, decide("localhost:8080", map[string]string{"Content-Type": "application/json"}))
+	assert.Equal(t, http.StatusForbidden, decide("localhost:8080", map[string]string{"Content-Type": "application/json", tokenHeader: "guess"}))
+	assert.Equal(t, http.StatusForbidden, decide("localhost:8080", map[string]string{"Content-Type": "application/json", tokenHeader: review.token, "Origin": "https://evil.example"}))
+	assert.Equal(t, http.StatusForbidden, decide("evil.example:8080", valid))
+	_, err = os.Stat(catalog)
+	assert.True(t, os.IsNotExist(err))
+
+	rec := httptest.NewRecorder()
+	review.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://127.0.0.1:8080/", nil))
+	assert.Contains(t, rec.Body.String(), review.token)
+
+	valid["Origin"] = "http://localhost:8080"
+	assert.Equal(t, http.StatusOK, decide("localhost:8080", valid))
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/staging"
+)
+
+var stageMode bool // Hold new translations for review with i18n-cli serve instead of writing them
+
+// holdForReview moves the translations target gained in this run to the staging store
+// when staging is on. It returns the catalog to write, where the staged keys keep their
+// previous value, and the retranslated keys whose flags can be cleared.
+func (o processOptions) holdForReview(source, target *parser.LocaleFileContent, retranslated []string) (*parser.LocaleFileContent, []string, error) {
+	if !o.Stage {
+		return target, retranslated, nil
+	}
+	store, err := staging.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading the staging store: %w", err)
+	}
+
+	original := target.Original()
+	written := *target
+	written.LocaleItemsMap = make(map[string]string, len(target.LocaleItemsMap))
+	for k, v := range target.LocaleItemsMap {
+		written.LocaleItemsMap[k] = v
+	}
+
+	now := o.clock().Now().UTC()
+	staged := map[string]bool{}
+	for k, v := range target.LocaleItemsMap {
+		text, ok := source.LocaleItemsMap[k]
+		previousKey, previous, existed := o.previousValue(original, k)
+		if !ok || v == "" || existed && previous == v {
+			continue
+		}
+		store.Add(staging.Entry{File: target.Path, Key: k, Lang: target.Code, Source: text, Translation: v, Previous: previous, Provider: auditProvider, StagedAt: now})
+		staged[k] = true
+		delete(written.LocaleItemsMap, k)
+		if existed {
+			written.LocaleItemsMap[previousKey] = previous
+		}
+	}
+	if len(staged) == 0 {
+		return target, retranslated, nil
+	}
+	if err := store.Save(); err != nil {
+		return nil, nil, fmt.Errorf("error saving the staging store: %w", err)
+	}
+	fmt.Printf("\n📋 Held %d translations of %s for review (i18n-cli serve)\n", len(staged), target.Path)
+
+	cleared := []string{}
+	for _, k := range retranslated {
+		if !staged[k] {
+			cleared = append(cleared, k)
+		}
+	}
+	return &written, cleared, nil
+}
+
+// previousValue returns the key and value key had on disk, under its suffixed name
+// when it was flagged for retranslation with a suffix marker
+func (o processOptions) previousValue(original map[string]string, key string) (string, string, bool) {
+	if v, ok := original[key]; ok {
+		return key, v, true
+	}
+	if o.Marker.Style == marker.StyleSuffix {
+		if v, ok := original[key+o.Marker.Suffix]; ok {
+			return key + o.Marker.Suffix, v, true
+		}
+	}
+	return "", "", false
+}
+
+// approveStaged writes translation, the pending translation of e possibly edited by
+// a reviewer, to the catalog of e and clears the retranslation flag of its key
+func approveStaged(e staging.Entry, translation string, m marker.Marker) error {
+	target := &parser.LocaleFileContent{Code: e.Lang, Path: e.File, LocaleItemsMap: map[string]string{}}
+	if err := target.ParseContent(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", e.File, err)
+	}
+	if m.Style == marker.StyleSuffix {
+		delete(target.LocaleItemsMap, e.Key+m.Suffix)
+	}
+	target.LocaleItemsMap[e.Key] = translation
+
+	auditProvider = e.Provider
+	if translation != e.Translation {
+		auditProvider = "review"
+	}
+	if err := os.MkdirAll(filepath.Dir(e.File), 0755); err != nil {
+		return err
+	}
+	if err := writeLocaleFile(target); err != nil {
+		return err
+	}
+	return m.Clear(osFS{}, target, []string{e.Key})
+}
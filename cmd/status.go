@@ -7,21 +7,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pandodao/i18n-cli/internal/blocked"
 	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/lint"
+	"github.com/pandodao/i18n-cli/internal/quality"
 	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
 	"github.com/spf13/cobra"
 )
 
+// statusCellWidth is the width keys and values are truncated to in the status report
+const statusCellWidth = 48
+
 var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "Show translation status",
-	Long:  `Display the status of translations for all languages and files.`,
+	Use:         "status",
+	Short:       "Show translation status",
+	Long:        `Display the status of translations for all languages and files.`,
+	Annotations: inspectOnly,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get command flags
 		rootDir, _ := cmd.Flags().GetString("root")
 		sourceLang, _ := cmd.Flags().GetString("source")
 		configPath, _ := cmd.Flags().GetString("config")
 		outputPath, _ := cmd.Flags().GetString("output")
+		policy, _ := cmd.Flags().GetString("empty-source")
+		minCompletion, _ := cmd.Flags().GetFloat64("min-completion")
 
 		// Load configuration file if provided
 		var cfg *config.Config
@@ -38,12 +48,19 @@ var statusCmd = &cobra.Command{
 				if !cmd.Flags().Changed("source") {
 					sourceLang = cfg.SourceLang
 				}
+				if !cmd.Flags().Changed("empty-source") {
+					policy = cfg.EmptySource
+				}
 			}
 		}
+		if !config.ValidEmptyPolicy(policy) {
+			fmt.Printf("❌ Invalid empty-source policy %q: use %s\n", policy, strings.Join(config.EmptyPolicies, ", "))
+			return
+		}
 
 		// Scan directory structure
 		fmt.Printf("🔍 Scanning directory: %s (source: %s)\n", rootDir, sourceLang)
-		ds, err := scanner.ScanDirectory(rootDir, sourceLang)
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
 		if err != nil {
 			fmt.Printf("❌ Error scanning directory: %v\n", err)
 			return
@@ -67,7 +84,7 @@ var statusCmd = &cobra.Command{
 		} else {
 			// Use all languages except source
 			for _, lang := range ds.Languages {
-				if lang != sourceLang {
+				if lang != ds.SourceLang {
 					targetLanguages = append(targetLanguages, lang)
 				}
 			}
@@ -97,6 +114,7 @@ var statusCmd = &cobra.Command{
 		// Group pairs by language and file type
 		langFileStats := make(map[string]map[string]*FileStats)
 		var totalSourceKeys int
+		duplicates := make(map[string]map[string][]lint.Issue)
 
 		// First pass: collect source file key counts
 		sourceKeyCounts := make(map[string]int)
@@ -108,11 +126,19 @@ var statusCmd = &cobra.Command{
 					fmt.Printf("❌ Error loading source file %s: %v\n", pair.SourceFile, err)
 					continue
 				}
-				sourceKeyCounts[pair.FileType] = len(source.LocaleItemsMap)
-				totalSourceKeys += len(source.LocaleItemsMap)
+				count, _ := countKeys(source.LocaleItemsMap, nil, policy)
+				sourceKeyCounts[pair.FileType] = count
+				totalSourceKeys += count
 			}
 		}
 
+		// Keys the provider refused to translate, to be translated by hand
+		blockedKeys, err := blocked.Load()
+		if err != nil {
+			fmt.Printf("⚠️ Could not read the blocked keys: %v\n", err)
+		}
+		refused := []blocked.Entry{}
+
 		// Second pass: collect stats for each language and file
 		for _, pair := range filteredPairs {
 			// Initialize language map if needed
@@ -127,25 +153,38 @@ var statusCmd = &cobra.Command{
 				continue
 			}
 
-			// Get missing keys
-			missingKeys := findMissingKeys(source.LocaleItemsMap, target.LocaleItemsMap)
-			missingCount := len(missingKeys)
+			for _, e := range blockedKeys[pair.TargetFile] {
+				if _, ok := source.LocaleItemsMap[e.Key]; ok && target.LocaleItemsMap[e.Key] == "" {
+					refused = append(refused, e)
+				}
+			}
 
-			// Get empty keys (keys that exist but have empty values)
+			// Keys counted under the empty-source policy and how many are translated
+			sourceCount, translatedCount := countKeys(source.LocaleItemsMap, target.LocaleItemsMap, policy)
+
+			// Get empty keys (keys that exist but have empty values for a source text)
 			emptyCount := 0
 			for k, v := range target.LocaleItemsMap {
-				if _, ok := source.LocaleItemsMap[k]; ok && v == "" {
+				if sv, ok := source.LocaleItemsMap[k]; ok && v == "" && !isBlank(sv) {
 					emptyCount++
 				}
 			}
+			missingCount := sourceCount - translatedCount - emptyCount
+
+			// Keys sharing a translation although their sources differ
+			if issues := lint.Duplicates(source.LocaleItemsMap, target.LocaleItemsMap); len(issues) > 0 {
+				if duplicates[pair.TargetLang] == nil {
+					duplicates[pair.TargetLang] = make(map[string][]lint.Issue)
+				}
+				duplicates[pair.TargetLang][pair.FileType] = issues
+			}
 
 			// Calculate statistics
-			translatedCount := len(source.LocaleItemsMap) - missingCount - emptyCount
-			percentComplete := float64(translatedCount) / float64(len(source.LocaleItemsMap)) * 100
+			percentComplete := float64(translatedCount) / float64(sourceCount) * 100
 
 			// Store statistics
 			langFileStats[pair.TargetLang][pair.FileType] = &FileStats{
-				SourceCount:   len(source.LocaleItemsMap),
+				SourceCount:   sourceCount,
 				MissingCount:  missingCount,
 				EmptyCount:    emptyCount,
 				Translated:    translatedCount,
@@ -166,10 +205,10 @@ var statusCmd = &cobra.Command{
 
 		// Summary table header
 		output.WriteString("## Summary\n\n")
-		output.WriteString("| Language | Total Keys | Translated | Missing | Empty | Percent Complete |\n")
-		output.WriteString("|----------|------------|------------|---------|-------|------------------|\n")
+		summary := table.New("Language", "Total Keys", "Translated", "Missing", "Empty", "Percent Complete")
 
 		// Overall stats by language
+		completion := make(map[string]float64)
 		for _, lang := range targetLanguages {
 			if fileStats, ok := langFileStats[lang]; ok {
 				totalKeys := 0
@@ -185,19 +224,23 @@ var statusCmd = &cobra.Command{
 				}
 
 				percentComplete := float64(totalTranslated) / float64(totalKeys) * 100
+				completion[lang] = percentComplete
 
-				output.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %.1f%% |\n",
-					lang, totalKeys, totalTranslated, totalMissing, totalEmpty, percentComplete))
+				label := lang
+				if cfg.Experimental(lang) {
+					label += " (experimental)"
+				}
+				summary.Add(label, totalKeys, totalTranslated, totalMissing, totalEmpty, fmt.Sprintf("%.1f%%", percentComplete))
 			}
 		}
+		output.WriteString(summary.String())
 
 		output.WriteString("\n## Details\n\n")
 
 		// Detailed stats
 		for _, lang := range targetLanguages {
 			output.WriteString(fmt.Sprintf("### %s\n\n", lang))
-			output.WriteString("| File | Total Keys | Translated | Missing | Empty | Percent Complete |\n")
-			output.WriteString("|------|------------|------------|---------|-------|------------------|\n")
+			details := table.New("File", "Total Keys", "Translated", "Missing", "Empty", "Percent Complete")
 
 			if fileStats, ok := langFileStats[lang]; ok {
 				// Get sorted file types
@@ -209,14 +252,64 @@ var statusCmd = &cobra.Command{
 
 				for _, fileType := range fileTypes {
 					stats := fileStats[fileType]
-					output.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %.1f%% |\n",
-						fileType, stats.SourceCount, stats.Translated, stats.MissingCount, stats.EmptyCount, stats.PercentDone))
+					details.Add(fileType, stats.SourceCount, stats.Translated, stats.MissingCount, stats.EmptyCount, fmt.Sprintf("%.1f%%", stats.PercentDone))
+				}
+			}
+			output.WriteString(details.String())
+
+			output.WriteString("\n")
+		}
+
+		// Duplicate translations for reviewer attention
+		if len(duplicates) > 0 {
+			output.WriteString("## Duplicate Translations\n\n")
+			output.WriteString("Distinct keys translated identically although their source texts differ.\n\n")
+			shared := table.New("Language", "File", "Key", "Translation shared with")
+			shared.MaxWidth = statusCellWidth
+			for _, lang := range targetLanguages {
+				fileTypes := make([]string, 0, len(duplicates[lang]))
+				for fileType := range duplicates[lang] {
+					fileTypes = append(fileTypes, fileType)
+				}
+				sort.Strings(fileTypes)
+
+				for _, fileType := range fileTypes {
+					for _, issue := range duplicates[lang][fileType] {
+						shared.Add(lang, fileType, issue.Key, issue.Message)
+					}
 				}
 			}
+			output.WriteString(shared.String())
+			output.WriteString("\n")
+		}
 
+		// Keys refused by the provider for humans to translate
+		if len(refused) > 0 {
+			sort.Slice(refused, func(i, j int) bool {
+				if refused[i].File != refused[j].File {
+					return refused[i].File < refused[j].File
+				}
+				return refused[i].Key < refused[j].Key
+			})
+			output.WriteString("## Blocked Keys\n\n")
+			output.WriteString(fmt.Sprintf("%d keys were refused by the provider for content policy reasons and are skipped by later runs; translate them by hand.\n\n", len(refused)))
+			list := table.New("Language", "File", "Key", "Reason")
+			list.MaxWidth = statusCellWidth
+			for _, e := range refused {
+				list.Add(e.Lang, e.File, e.Key, e.Reason)
+			}
+			output.WriteString(list.String())
 			output.WriteString("\n")
 		}
 
+		// Quality trend from freshness audits
+		history, err := quality.LoadHistory()
+		if err != nil {
+			fmt.Printf("⚠️ Could not read quality history: %v\n", err)
+		} else {
+			output.WriteString(qualityTrend(history, targetLanguages))
+		}
+
 		// Print to console
 		fmt.Println("\n" + output.String())
 
@@ -228,9 +321,45 @@ var statusCmd = &cobra.Command{
 				fmt.Printf("✅ Report saved to %s\n", outputPath)
 			}
 		}
+
+		if minCompletion > 0 {
+			if below := belowCompletion(completion, minCompletion, cfg); len(below) > 0 {
+				fmt.Printf("❌ Less than %.1f%% complete: %s\n", minCompletion, strings.Join(below, ", "))
+				os.Exit(1)
+			}
+			fmt.Printf("✅ Every language is at least %.1f%% complete\n", minCompletion)
+		}
 	},
 }
 
+// qualityTrend returns the Quality Trend section of the report: the last freshness
+// audits of each language, empty without any
+func qualityTrend(history []quality.Record, langs []string) string {
+	if len(history) == 0 {
+		return ""
+	}
+	trend := table.New("Language", "Date", "Samples", "Drift", "Average Score")
+	for _, lang := range langs {
+		for _, r := range quality.Trend(history, lang, 5) {
+			trend.Add(lang, r.Time.Format("2006-01-02"), r.Samples, fmt.Sprintf("%.1f%%", r.Drift()*100), fmt.Sprintf("%.2f", r.Score))
+		}
+	}
+	return "## Quality Trend\n\n" + trend.String() + "\n"
+}
+
+// belowCompletion returns the sorted languages of completion, by percent complete,
+// below min; the experimental languages of cfg are left out
+func belowCompletion(completion map[string]float64, min float64, cfg *config.Config) []string {
+	below := []string{}
+	for lang, percent := range completion {
+		if percent < min && !cfg.Experimental(lang) {
+			below = append(below, fmt.Sprintf("%s (%.1f%%)", lang, percent))
+		}
+	}
+	sort.Strings(below)
+	return below
+}
+
 // FileStats represents statistics for a file
 type FileStats struct {
 	SourceCount   int
@@ -247,6 +376,8 @@ func init() {
 	statusCmd.Flags().String("source", "en", "Source language code (default: en)")
 	statusCmd.Flags().String("config", "", "Path to configuration file")
 	statusCmd.Flags().String("output", "", "Save report to file (markdown format)")
+	statusCmd.Flags().Float64("min-completion", 0, "Fail when a language that isn't experimental is less than this percent complete (0 disables)")
+	statusCmd.Flags().String("empty-source", "", "What to do with keys whose source value is empty: skip (default), copy-source, translate-as-empty or error")
 
 	statusCmd.MarkFlagRequired("root")
 
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/quality"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBelowCompletion tests that the completion gate leaves out experimental languages
+func TestBelowCompletion(t *testing.T) {
+	completion := map[string]float64{"fr": 100, "de": 80, "ja": 42.5, "ko": 10}
+	cfg := &config.Config{ExperimentalLangs: []string{"ko"}}
+
+	assert.Equal(t, []string{"de (80.0%)", "ja (42.5%)"}, belowCompletion(completion, 90, cfg))
+	assert.Equal(t, []string{"ja (42.5%)", "ko (10.0%)"}, belowCompletion(completion, 50, nil))
+	assert.Empty(t, belowCompletion(completion, 5, cfg))
+}
+
+// TestQualityTrend tests that the Quality Trend section lists the last audits of each
+// language of the report, and is left out without any
+func TestQualityTrend(t *testing.T) {
+	assert.Empty(t, qualityTrend(nil, []string{"fr"}))
+
+	history := []quality.Record{}
+	for day := 1; day <= 7; day++ {
+		history = append(history, quality.Record{Time: time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC), Lang: "fr", Samples: 20, Changed: day, Score: 4.25})
+	}
+	history = append(history, quality.Record{Time: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC), Lang: "ja", Samples: 10, Changed: 1, Score: 3})
+
+	section := qualityTrend(history, []string{"de", "fr"})
+	assert.True(t, strings.HasPrefix(section, "## Quality Trend\n\n"))
+	assert.Contains(t, section, "Average Score")
+	assert.NotContains(t, section, "2024-03-02", "only the last five audits are listed")
+	for _, want := range []string{"2024-03-03", "2024-03-07", "35.0%", "4.25"} {
+		assert.Contains(t, section, want)
+	}
+	assert.NotContains(t, section, "ja")
+}
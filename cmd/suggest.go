@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Write alternative translations of selected keys for a human to choose from",
+	Long: `Ask the model for several alternative translations of the keys matching --key, such as
+marketing headlines whose wording matters, and write them to a suggestions file with the
+source and the current translation, instead of keeping the model's first answer.
+Alternatives failing the validation of translations (placeholders, markup, glossary,
+maxLength...) are left out. Locale files are never modified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		patterns, _ := cmd.Flags().GetStringSlice("key")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		count, _ := cmd.Flags().GetInt("count")
+		outputPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				fmt.Printf("❌ Invalid key pattern %q: %v\n", pattern, err)
+				os.Exit(1)
+			}
+		}
+		if count < 2 {
+			fmt.Printf("❌ --count must be at least 2, got %d\n", count)
+			os.Exit(1)
+		}
+		if format == "" {
+			format = "json"
+			if strings.EqualFold(filepath.Ext(outputPath), ".md") {
+				format = "markdown"
+			}
+		}
+		if format != "markdown" && format != "json" {
+			fmt.Printf("❌ Unknown format %q, use json or markdown\n", format)
+			os.Exit(1)
+		}
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		opts := processOptions{}
+		if cfg != nil {
+			if !cmd.Flags().Changed("source") {
+				sourceLang = cfg.SourceLang
+			}
+			safeMode = cfg.Safe
+			registers, langNotes, geoNames = cfg.Registers(), cfg.Instructions, cfg.GeoNames
+			framework = cfg.Framework
+			opts.Keys = cfg.Keys
+		}
+		if termbase, err = loadGlossary(cfg); err != nil {
+			fmt.Printf("❌ Error reading glossary: %v\n", err)
+			os.Exit(1)
+		}
+		if templates, err = loadValues(cfg); err != nil {
+			fmt.Printf("❌ Error reading values file: %v\n", err)
+			os.Exit(1)
+		}
+
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) {
+			fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+			os.Exit(1)
+		}
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(120)*time.Second)
+		if err != nil {
+			fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+			os.Exit(1)
+		}
+		defer printUsageReport(gptHandler)
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		ctx := context.Background()
+		suggestions := []suggestion{}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			keys := matchingKeys(source.LocaleItemsMap, patterns)
+			if len(keys) == 0 {
+				continue
+			}
+			fmt.Printf("💡 Asking for %d alternatives of %d %s keys\n", count, len(keys), pair.TargetLang)
+			found, err := suggestAlternatives(gpt.WithLanguages(ctx, source.Code, target.Code), gptHandler, source, target, keys, count, opts)
+			if err != nil {
+				fmt.Printf("❌ Error getting %s alternatives: %v\n", pair.TargetLang, err)
+				os.Exit(1)
+			}
+			suggestions = append(suggestions, found...)
+		}
+		if len(suggestions) == 0 {
+			fmt.Println("⚠️ No source key matches --key")
+			return
+		}
+
+		var report string
+		if format == "json" {
+			data, err := json.MarshalIndent(suggestions, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding suggestions: %v\n", err)
+				os.Exit(1)
+			}
+			report = string(data) + "\n"
+		} else {
+			report = suggestionsReport(suggestions)
+		}
+		if err := os.WriteFile(outputPath, []byte(report), 0644); err != nil {
+			fmt.Printf("❌ Error writing suggestions: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 Alternatives of %d translations saved to %s\n", len(suggestions), outputPath)
+	},
+}
+
+// alternator writes alternative translations of a text
+type alternator interface {
+	Alternatives(ctx context.Context, text string, lang string, n int) ([]string, error)
+}
+
+var _ alternator = (*gpt.Handler)(nil)
+
+// suggestion is the alternative translations of a key, for a human to choose from
+type suggestion struct {
+	File         string   `json:"file"`
+	Key          string   `json:"key"`
+	Lang         string   `json:"lang"`
+	Source       string   `json:"source"`
+	Current      string   `json:"current,omitempty"`
+	Alternatives []string `json:"alternatives"`
+	// Alternatives of the model failing validation, left out
+	Rejected int `json:"rejected,omitempty"`
+}
+
+// matchingKeys returns the sorted keys of items matching one of patterns
+func matchingKeys(items map[string]string, patterns []string) []string {
+	keys := []string{}
+	for key := range items {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, key); ok {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggestAlternatives asks h for n alternative translations of each of keys, with the
+// context of their translation, and keeps the valid ones
+func suggestAlternatives(ctx context.Context, h alternator, source, target *parser.LocaleFileContent, keys []string, n int, opts processOptions) ([]suggestion, error) {
+	suggestions := []suggestion{}
+	for _, key := range keys {
+		text := source.LocaleItemsMap[key]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		keyCtx := opts.withKey(ctx, key)
+		keyCtx = gpt.WithGlossary(keyCtx, termbase.Prompt([]string{text}, target.Code))
+		keyCtx = gpt.WithInstructions(keyCtx, withLength(instructionsFor(target.Code), opts.lengthInstruction(key)))
+		alternatives, err := h.Alternatives(keyCtx, text, target.Lang, n)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		s := suggestion{File: target.Path, Key: key, Lang: target.Code, Source: text, Current: target.LocaleItemsMap[key], Alternatives: []string{}}
+		for _, alt := range alternatives {
+			if checkTranslation(text, alt, target) != nil || opts.checkLength(key, alt) != nil {
+				s.Rejected++
+				continue
+			}
+			s.Alternatives = append(s.Alternatives, alt)
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, nil
+}
+
+// suggestionsReport returns the suggestions in markdown, a section per key
+func suggestionsReport(suggestions []suggestion) string {
+	var report strings.Builder
+	report.WriteString("# Translation Alternatives\n")
+	for _, s := range suggestions {
+		fmt.Fprintf(&report, "\n## %s: %s (%s)\n\n", s.Lang, s.Key, s.File)
+		fmt.Fprintf(&report, "Source: %s\n", s.Source)
+		if s.Current != "" {
+			fmt.Fprintf(&report, "Current: %s\n", s.Current)
+		}
+		report.WriteString("\n")
+		for i, alt := range s.Alternatives {
+			fmt.Fprintf(&report, "%d. %s\n", i+1, alt)
+		}
+		if len(s.Alternatives) == 0 {
+			report.WriteString("No valid alternative.\n")
+		}
+		if s.Rejected > 0 {
+			fmt.Fprintf(&report, "\n%d alternatives failing validation were left out.\n", s.Rejected)
+		}
+	}
+	return report.String()
+}
+
+func init() {
+	suggestCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	suggestCmd.Flags().String("source", "en", "Source language code (default: en)")
+	suggestCmd.Flags().String("config", "", "Path to configuration file")
+	suggestCmd.Flags().StringSlice("key", nil, "Keys to get alternatives of, or path.Match patterns such as 'hero/*' (repeatable)")
+	suggestCmd.Flags().StringSlice("lang", nil, "Languages to get alternatives in (default: every target language)")
+	suggestCmd.Flags().Int("count", 3, "Number of alternatives asked per key")
+	suggestCmd.Flags().String("output", "i18n-suggestions.json", "Suggestions file to write")
+	suggestCmd.Flags().String("format", "", "Suggestions format: json or markdown (default: markdown for a .md --output, json otherwise)")
+
+	suggestCmd.MarkFlagRequired("root")
+	suggestCmd.MarkFlagRequired("key")
+
+	rootCmd.AddCommand(suggestCmd)
+}
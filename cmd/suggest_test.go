@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/keymeta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAlternator answers fixed alternatives per text, recording the keys asked for
+type fakeAlternator struct {
+	alternatives map[string][]string
+	keys         []string
+}
+
+func (f *fakeAlternator) Alternatives(ctx context.Context, text string, lang string, n int) ([]string, error) {
+	f.keys = append(f.keys, gpt.KeyFrom(ctx))
+	return f.alternatives[text], nil
+}
+
+// TestSuggestAlternatives tests that alternatives are asked for the matching keys only,
+// with the context of their key, and that those failing validation are left out
+func TestSuggestAlternatives(t *testing.T) {
+	source := &parser.LocaleFileContent{Code: "en", LocaleItemsMap: map[string]string{
+		"hero/title":    "Ship faster",
+		"hero/subtitle": "Hello {name}",
+		"footer/legal":  "All rights reserved",
+	}}
+	target := &parser.LocaleFileContent{Code: "fr", Lang: "French", Path: "fr/common.json", LocaleItemsMap: map[string]string{
+		"hero/title": "Livrez plus vite",
+	}}
+	h := &fakeAlternator{alternatives: map[string][]string{
+		"Ship faster":  {"Livrez plus vite", "Expédiez plus rapidement que jamais", "Accélérez vos livraisons"},
+		"Hello {name}": {"Bonjour {name}", "Salut {nom}"},
+	}}
+	opts := processOptions{Keys: keymeta.Rules{"hero/title": {MaxLength: 25}}}
+
+	keys := matchingKeys(source.LocaleItemsMap, []string{"hero/*"})
+	assert.Equal(t, []string{"hero/subtitle", "hero/title"}, keys)
+
+	suggestions, err := suggestAlternatives(context.Background(), h, source, target, keys, 3, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []suggestion{
+		{File: "fr/common.json", Key: "hero/subtitle", Lang: "fr", Source: "Hello {name}", Alternatives: []string{"Bonjour {name}"}, Rejected: 1},
+		{File: "fr/common.json", Key: "hero/title", Lang: "fr", Source: "Ship faster", Current: "Livrez plus vite", Alternatives: []string{"Livrez plus vite", "Accélérez vos livraisons"}, Rejected: 1},
+	}, suggestions)
+	assert.Equal(t, keys, h.keys)
+
+	report := suggestionsReport(suggestions)
+	assert.Contains(t, report, "## fr: hero/title (fr/common.json)\n\nSource: Ship faster\nCurrent: Livrez plus vite\n\n1. Livrez plus vite\n2. Accélérez vos livraisons\n")
+	assert.Contains(t, report, "1 alternatives failing validation were left out.")
+}
@@ -2,14 +2,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/pandodao/i18n-cli/internal/align"
 	"github.com/pandodao/i18n-cli/internal/config"
 	"github.com/pandodao/i18n-cli/internal/gpt"
 	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/staging"
+	"github.com/pandodao/i18n-cli/internal/tracing"
 
 	"github.com/spf13/cobra"
 )
@@ -21,207 +27,579 @@ var syncCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get command flags
 		rootDir, _ := cmd.Flags().GetString("root")
-		sourceLang, _ := cmd.Flags().GetString("source")
-		mode, _ := cmd.Flags().GetString("mode")
-		batchSize, _ := cmd.Flags().GetInt("batch")
 		configPath, _ := cmd.Flags().GetString("config")
+		watch, _ := cmd.Flags().GetBool("watch")
+		startInvocation(cmd, configPath)
 
-		// Load configuration file if provided
-		var cfg *config.Config
-		var err error
+		cfg, err := loadSyncConfig(cmd, configPath)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		if !config.ValidEmptyPolicy(cfg.EmptySource) {
+			fmt.Printf("❌ Invalid --empty-source %q: use %s\n", cfg.EmptySource, strings.Join(config.EmptyPolicies, ", "))
+			return
+		}
 
-		if configPath != "" {
-			fmt.Printf("📝 Loading configuration from %s\n", configPath)
-			cfg, err = config.LoadConfig(configPath)
-			if err != nil {
-				// If config file doesn't exist, create a default one
-				if os.IsNotExist(err) {
-					fmt.Printf("⚠️ Configuration file not found, creating default at %s\n", configPath)
-					cfg = config.DefaultConfig()
-					if err := config.SaveConfig(cfg, configPath); err != nil {
-						fmt.Printf("❌ Error creating configuration file: %v\n", err)
-						return
-					}
-				} else {
-					fmt.Printf("❌ Error loading configuration: %v\n", err)
-					return
-				}
-			}
+		release, err := acquireProjectLock(rootDir)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		defer release()
 
-			// Override with command line arguments if provided
-			if cmd.Flags().Changed("source") {
-				cfg.SourceLang = sourceLang
-			} else {
-				sourceLang = cfg.SourceLang
-			}
+		// Create context
+		ctx := cmd.Context()
 
-			if cmd.Flags().Changed("mode") {
-				cfg.Mode = mode
+		if addr := metricsAddr(cmd, cfg); addr != "" {
+			if watch {
+				syncMetrics = newSyncMetrics()
+				go serveMetrics(addr, syncMetrics)
 			} else {
-				mode = cfg.Mode
+				fmt.Println("⚠️ Metrics are only served in watch mode (--watch)")
 			}
+		}
 
-			if cmd.Flags().Changed("batch") {
-				cfg.BatchSize = batchSize
-			} else {
-				batchSize = cfg.BatchSize
+		if dryRunDiff && !dryRun {
+			fmt.Println("⚠️ --diff only applies to dry runs (--dry-run)")
+		}
+		if dryRun && watch {
+			fmt.Println("❌ --dry-run can't be combined with --watch")
+			return
+		}
+
+		if patchPath, _ := cmd.Flags().GetString("patch"); patchPath != "" {
+			if watch {
+				fmt.Println("❌ --patch can't be combined with --watch")
+				return
 			}
-		} else {
-			// Use default config
-			cfg = config.DefaultConfig()
-			cfg.SourceLang = sourceLang
-			cfg.Mode = mode
-			cfg.BatchSize = batchSize
+			if dryRun {
+				fmt.Println("❌ --patch can't be combined with --dry-run, as it changes the source file")
+				return
+			}
+			fileType, _ := cmd.Flags().GetString("patch-file")
+			sourceFile, only, err := applySourcePatch(rootDir, cfg, patchPath, fileType)
+			if err != nil {
+				fmt.Printf("❌ Error applying patch: %v\n", err)
+				return
+			}
+			if len(only) > 0 {
+				runSyncFiles(ctx, rootDir, cfg, map[string]bool{sourceFile: true}, only)
+			}
+			return
 		}
 
-		// Get API key from config or environment
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" && cfg.APIKey != "" {
-			apiKey = cfg.APIKey
+		runSync(ctx, rootDir, cfg)
+
+		if watch {
+			interval, _ := cmd.Flags().GetDuration("interval")
+			watchSync(ctx, cmd, rootDir, configPath, cfg, interval)
 		}
+	},
+}
 
-		if apiKey == "" {
-			fmt.Println("❌ No API key provided. Set OPENAI_API_KEY environment variable or specify in config file.")
-			return
+// loadSyncConfig loads the configuration file if provided (creating a default one
+// if it doesn't exist) and applies the command line overrides
+func loadSyncConfig(cmd *cobra.Command, configPath string) (*config.Config, error) {
+	if configPath == "" {
+		// Use default config
+		cfg := config.DefaultConfig()
+		cfg.SourceLang, _ = cmd.Flags().GetString("source")
+		cfg.Mode, _ = cmd.Flags().GetString("mode")
+		cfg.BatchSize, _ = cmd.Flags().GetInt("batch")
+		cfg.BatchTokens = batchTokens
+		cfg.Rewrite = rewriteOutput
+		cfg.PriorityFile = priorityFile
+		cfg.Examples = &fewShotExamples
+		cfg.Safe = safeMode
+		cfg.LocalizeExamples = localizeExamples
+		cfg.Deterministic = deterministic
+		if cmd.Flags().Changed("seed") {
+			cfg.Seed = &seedFlag
 		}
+		if cmd.Flags().Changed("temperature") {
+			cfg.Temperature = &temperatureFlag
+		}
+		cfg.MaxTokens = maxTokens
+		cfg.SystemPrompt = systemPrompt
+		cfg.ConfirmCost = &confirmCost
+		cfg.MaxCost = maxCost
+		cfg.Staging = stageMode
+		cfg.EmptySource = emptySource
+		return cfg, nil
+	}
 
-		// Create GPT handler for translations
-		gptHandler := gpt.New(gpt.Config{
-			Keys:    []string{apiKey},
-			Timeout: time.Duration(60) * time.Second,
-		})
+	fmt.Printf("📝 Loading configuration from %s\n", configPath)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		// If config file doesn't exist, create a default one
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		fmt.Printf("⚠️ Configuration file not found, creating default at %s\n", configPath)
+		cfg = config.DefaultConfig()
+		if err := config.SaveConfig(cfg, configPath); err != nil {
+			return nil, fmt.Errorf("creating configuration file: %w", err)
+		}
+	}
 
-		// Create context
-		ctx := context.Background()
+	applySyncFlags(cmd, cfg)
+	return cfg, nil
+}
 
-		// Scan directory structure
-		fmt.Printf("🔍 Scanning directory: %s (source: %s)\n", rootDir, sourceLang)
-		ds, err := scanner.ScanDirectory(rootDir, sourceLang)
+// applySyncFlags overrides cfg with the command line arguments that were provided
+func applySyncFlags(cmd *cobra.Command, cfg *config.Config) {
+	if cmd.Flags().Changed("source") {
+		cfg.SourceLang, _ = cmd.Flags().GetString("source")
+	}
+	if cmd.Flags().Changed("mode") {
+		cfg.Mode, _ = cmd.Flags().GetString("mode")
+	}
+	if cmd.Flags().Changed("batch") {
+		cfg.BatchSize, _ = cmd.Flags().GetInt("batch")
+	}
+	if cmd.Flags().Changed("batch-tokens") {
+		cfg.BatchTokens = batchTokens
+	}
+	if cmd.Flags().Changed("priority") {
+		cfg.PriorityFile = priorityFile
+	}
+	if cmd.Flags().Changed("rewrite") {
+		cfg.Rewrite = rewriteOutput
+	}
+	if cmd.Flags().Changed("safe") {
+		cfg.Safe = safeMode
+	}
+	if cmd.Flags().Changed("localize-examples") {
+		cfg.LocalizeExamples = localizeExamples
+	}
+	if cmd.Flags().Changed("deterministic") {
+		cfg.Deterministic = deterministic
+	}
+	if cmd.Flags().Changed("seed") {
+		value := seedFlag
+		cfg.Seed = &value
+	}
+	if cmd.Flags().Changed("temperature") {
+		value := temperatureFlag
+		cfg.Temperature = &value
+	}
+	if cmd.Flags().Changed("max-tokens") {
+		cfg.MaxTokens = maxTokens
+	}
+	if cmd.Flags().Changed("system-prompt") {
+		cfg.SystemPrompt = systemPrompt
+	}
+	if cmd.Flags().Changed("examples") {
+		examples := fewShotExamples
+		cfg.Examples = &examples
+	}
+	if cmd.Flags().Changed("confirm-cost") {
+		threshold := confirmCost
+		cfg.ConfirmCost = &threshold
+	}
+	if cmd.Flags().Changed("max-cost") {
+		cfg.MaxCost = maxCost
+	}
+	if cmd.Flags().Changed("stage") {
+		cfg.Staging = stageMode
+	}
+	if cmd.Flags().Changed("empty-source") {
+		cfg.EmptySource = emptySource
+	}
+}
+
+// runSync translates every target file of rootDir once with the given configuration
+func runSync(ctx context.Context, rootDir string, cfg *config.Config) {
+	runSyncFiles(ctx, rootDir, cfg, nil, nil)
+}
+
+// runSyncFiles translates the target files of the given source files, or of every
+// source file when sourceFiles is nil, restricted to the only keys when not nil
+func runSyncFiles(ctx context.Context, rootDir string, cfg *config.Config, sourceFiles, only map[string]bool) {
+	sourceLang, mode, batchSize := cfg.SourceLang, cfg.Mode, cfg.BatchSize
+	// First error of the run, recorded in the journal and in the trace
+	var runErr error
+	ctx, endTrace := traceRun(withTracing(ctx, cfg), "sync", "root", rootDir)
+	defer func() { endTrace(runErr) }()
+	applyOutputSettings(cfg)
+	safeMode = cfg.Safe
+	if cfg.LocalizeExamples {
+		examplesFrom = cfg.SourceLang
+	}
+	deterministic, seed = cfg.Deterministic, cfg.Seed
+	temperature, maxTokens, systemPrompt = cfg.Temperature, cfg.MaxTokens, cfg.SystemPrompt
+	batchTokens = cfg.BatchTokens
+	maxCost = cfg.MaxCost
+	registers, langNotes, geoNames = cfg.Registers(), cfg.Instructions, cfg.GeoNames
+	framework = cfg.Framework
+	examples := defaultExamples
+	if cfg.Examples != nil {
+		examples = *cfg.Examples
+	}
+
+	var priority map[string]int
+	var err error
+	if cfg.PriorityFile != "" {
+		priority, err = loadPriority(cfg.PriorityFile)
 		if err != nil {
-			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			fmt.Printf("❌ Error reading priority file: %v\n", err)
 			return
 		}
+	}
 
-		fmt.Printf("✅ Found %d languages and %d file types\n", len(ds.Languages), len(ds.FileTypes))
-		fmt.Printf("🌍 Languages: %v\n", ds.Languages)
-		fmt.Printf("📄 File types: %v\n", ds.FileTypes)
-
-		// Filter target languages if specified in config
-		targetLanguages := []string{}
-		if len(cfg.TargetLangs) > 0 {
-			// Use only languages specified in config
-			for _, lang := range ds.Languages {
-				for _, targetLang := range cfg.TargetLangs {
-					if lang == targetLang {
-						targetLanguages = append(targetLanguages, lang)
-						break
-					}
-				}
-			}
-			fmt.Printf("🎯 Using target languages from config: %v\n", targetLanguages)
-		} else {
-			// Use all languages except source
-			for _, lang := range ds.Languages {
-				if lang != sourceLang {
+	if termbase, err = loadGlossary(cfg); err != nil {
+		fmt.Printf("❌ Error reading glossary: %v\n", err)
+		return
+	}
+	if templates, err = loadValues(cfg); err != nil {
+		fmt.Printf("❌ Error reading values file: %v\n", err)
+		return
+	}
+
+	// Get API keys from config or environment
+	apiKeys := resolveAPIKeys(cfg)
+	if len(apiKeys) == 0 && needsAPIKeys(cfg) && !dryRun {
+		fmt.Printf("❌ No API key provided. Set %s environment variable or specify in config file.\n", apiKeyEnv(cfg))
+		return
+	}
+
+	// Create GPT handler for translations
+	gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+	if err != nil {
+		fmt.Printf("❌ Error creating GPT handler: %v\n", err)
+		return
+	}
+	auditProvider = gptHandler.Provider() + "/" + gptHandler.Model()
+
+	// Scan directory structure
+	fmt.Printf("🔍 Scanning directory: %s (source: %s)\n", rootDir, sourceLang)
+	ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+	if err != nil {
+		fmt.Printf("❌ Error scanning directory: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Found %d languages and %d file types\n", len(ds.Languages), len(ds.FileTypes))
+	fmt.Printf("🌍 Languages: %v\n", ds.Languages)
+	fmt.Printf("📄 File types: %v\n", ds.FileTypes)
+	offerReverification(rootDir, gptHandler)
+
+	// Filter target languages if specified in config
+	targetLanguages := []string{}
+	if len(cfg.TargetLangs) > 0 {
+		// Use only languages specified in config
+		for _, lang := range ds.Languages {
+			for _, targetLang := range cfg.TargetLangs {
+				if scanner.SameName(lang, targetLang) {
 					targetLanguages = append(targetLanguages, lang)
+					break
 				}
 			}
 		}
+		fmt.Printf("🎯 Using target languages from config: %v\n", targetLanguages)
+	} else {
+		// Use all languages except source
+		for _, lang := range ds.Languages {
+			if lang != ds.SourceLang {
+				targetLanguages = append(targetLanguages, lang)
+			}
+		}
+	}
 
-		// Check for missing files (files that exist in source but not in target)
-		missingPairs := ds.FindMissingPairs()
-		if len(missingPairs) > 0 {
-			fmt.Printf("⚠️ Found %d missing files\n", len(missingPairs))
-			for _, pair := range missingPairs {
-				// Create target directory if it doesn't exist
-				targetDir := filepath.Dir(pair.TargetFile)
-				if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-					fmt.Printf("📁 Creating directory: %s\n", targetDir)
-					if err := os.MkdirAll(targetDir, 0755); err != nil {
-						fmt.Printf("❌ Error creating directory: %v\n", err)
-						continue
-					}
+	// Check for missing files (files that exist in source but not in target)
+	missingPairs := ds.FindMissingPairs()
+	if len(missingPairs) > 0 {
+		fmt.Printf("⚠️ Found %d missing files\n", len(missingPairs))
+		for _, pair := range missingPairs {
+			// Create target directory if it doesn't exist
+			targetDir := filepath.Dir(pair.TargetFile)
+			if _, err := os.Stat(targetDir); os.IsNotExist(err) && !dryRun {
+				fmt.Printf("📁 Creating directory: %s\n", targetDir)
+				if err := os.MkdirAll(targetDir, 0755); err != nil {
+					fmt.Printf("❌ Error creating directory: %v\n", err)
+					continue
 				}
 			}
 		}
+	}
 
-		// Get all file pairs
-		pairs, err := ds.GetPairs()
-		if err != nil {
-			fmt.Printf("❌ Error getting file pairs: %v\n", err)
-			return
-		}
+	// Get all file pairs
+	pairs, err := ds.GetPairs()
+	if err != nil {
+		fmt.Printf("❌ Error getting file pairs: %v\n", err)
+		return
+	}
 
-		// Filter pairs based on target languages
-		filteredPairs := []scanner.FilePair{}
-		for _, pair := range pairs {
-			for _, lang := range targetLanguages {
-				if pair.TargetLang == lang {
-					filteredPairs = append(filteredPairs, pair)
-					break
-				}
+	// Filter pairs based on target languages
+	filteredPairs := []scanner.FilePair{}
+	for _, pair := range pairs {
+		if sourceFiles != nil && !sourceFiles[pair.SourceFile] {
+			continue
+		}
+		for _, lang := range targetLanguages {
+			if pair.TargetLang == lang {
+				filteredPairs = append(filteredPairs, pair)
+				break
 			}
 		}
+	}
 
-		fmt.Printf("🔄 Processing %d file pairs\n", len(filteredPairs))
+	// Experimental languages are synced last, once the languages gated on are up to date
+	sort.SliceStable(filteredPairs, func(i, j int) bool {
+		return !cfg.Experimental(filteredPairs[i].TargetLang) && cfg.Experimental(filteredPairs[j].TargetLang)
+	})
 
-		// Statistics
-		totalFiles := len(filteredPairs)
-		completedFiles := 0
-		totalKeys := 0
-		translatedKeys := 0
-		failedKeys := 0
+	// Hold new languages back until a translated sample of their keys is signed off
+	if cfg.Onboarding != nil && !dryRun {
+		opts := processOptions{Marker: cfg.Marker, Priority: priority, Examples: examples, Keys: cfg.Keys}
+		filteredPairs = onboardLanguages(ctx, gptHandler, filteredPairs, cfg.Onboarding, batchSize, opts)
+	}
 
-		// Process each pair
-		for _, pair := range filteredPairs {
-			fmt.Printf("\n🔄 Processing: %s -> %s\n", pair.SourceFile, pair.TargetFile)
+	// Warn about source strings likely to translate badly before paying for them
+	linted := make(map[string]bool)
+	for _, pair := range filteredPairs {
+		if linted[pair.SourceFile] {
+			continue
+		}
+		linted[pair.SourceFile] = true
+		if issues, err := lintSourceFile(pair.SourceFile, cfg.Secrets); err == nil && len(issues) > 0 {
+			fmt.Printf("⚠️ %s: %d source lint issues (run i18n-cli lint for details)\n", pair.SourceFile, len(issues))
+		}
+	}
 
-			// Load source and target files
-			source, target, err := pair.LoadPair()
-			if err != nil {
-				fmt.Printf("❌ Error loading pair: %v\n", err)
-				continue
+	// Ask before starting runs that cost more than expected, e.g. an accidental full mode
+	threshold := defaultConfirmCost
+	if cfg.ConfirmCost != nil {
+		threshold = *cfg.ConfirmCost
+	}
+	// Keys with a translation pending review are not translated again
+	var pendingReview staging.Store
+	if cfg.Staging {
+		if pendingReview, err = staging.Load(); err != nil {
+			fmt.Printf("❌ Error loading the staging store: %v\n", err)
+			return
+		}
+	}
+
+	pending := []string{}
+	pendingByLang := map[string][]string{}
+	diffs := []fileChanges{}
+	for _, pair := range filteredPairs {
+		source, target, err := pair.LoadPair()
+		if err != nil {
+			// Reported when the pair is processed
+			continue
+		}
+		pendingOpts := processOptions{Mode: mode, Marker: cfg.Marker, Only: only, Held: pendingReview.Keys(pair.TargetFile)}
+		texts, err := pendingTexts(source, target, nil, pendingOpts)
+		if err != nil {
+			continue
+		}
+		pending = append(pending, texts...)
+		pendingByLang[pair.TargetLang] = append(pendingByLang[pair.TargetLang], texts...)
+		if dryRun && dryRunDiff {
+			if changes, err := pendingChanges(source, target, nil, pendingOpts); err == nil {
+				diffs = append(diffs, changes)
 			}
+		}
+	}
+	if dryRun {
+		printDryRun(gptHandler, batchSize, pendingByLang)
+		if dryRunDiff {
+			printDiffPreview(diffs)
+		}
+		return
+	}
+	if !approveRun(gptHandler, pending, batchSize, threshold) {
+		return
+	}
 
-			// Create target directory if needed
-			targetDir := filepath.Dir(pair.TargetFile)
-			if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-				if err := os.MkdirAll(targetDir, 0755); err != nil {
-					fmt.Printf("❌ Error creating directory: %v\n", err)
-					continue
-				}
+	if asyncMode {
+		opts := processOptions{Mode: mode, Marker: cfg.Marker, Examples: examples, Keys: cfg.Keys, Only: only}
+		if err := submitJobs(ctx, gptHandler, filteredPairs, opts); err != nil {
+			fmt.Printf("❌ Error submitting batch jobs: %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("🔄 Processing %d file pairs\n", len(filteredPairs))
+
+	// Statistics
+	totalFiles := len(filteredPairs)
+	completedFiles := 0
+	totalKeys := 0
+	translatedKeys := 0
+	failedKeys := 0
+	newlyTranslated := map[string]int{}
+	aborted := false
+	record := startRun(gptHandler)
+	corrections = &correctionStats{}
+	// Translations of array items are remembered once the catalogs are written
+	arrays, err := align.Load()
+	if err != nil {
+		fmt.Printf("⚠️ Could not read the array item memory: %v\n", err)
+	}
+	translations := openTranslationCache(gptHandler.Model())
+	// Catalogs are written together once every pair is processed
+	tx := newTransaction()
+
+	// Process each pair
+	for _, pair := range filteredPairs {
+		fmt.Printf("\n🔄 Processing: %s -> %s\n", pair.SourceFile, pair.TargetFile)
+		pairCtx, span := tracing.Start(ctx, "file.process", "source", pair.SourceFile, "target", pair.TargetFile, "lang", pair.TargetLang)
+
+		// Load source and target files
+		_, load := tracing.Start(pairCtx, "file.load", "source", pair.SourceFile, "target", pair.TargetFile)
+		source, target, err := pair.LoadPair()
+		load.Fail(err)
+		load.End()
+		if err != nil {
+			fmt.Printf("❌ Error loading pair: %v\n", err)
+			if runErr == nil {
+				runErr = err
 			}
+			span.Fail(err)
+			span.End()
+			continue
+		}
 
-			// Process the files
-			var processErr error
-			if batchSize > 0 {
-				processErr = batch_process(ctx, gptHandler, source, target, nil, batchSize, mode)
-			} else {
-				processErr = single_process(ctx, gptHandler, source, target, nil, mode)
+		// Create target directory if needed
+		targetDir := filepath.Dir(pair.TargetFile)
+		if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				fmt.Printf("❌ Error creating directory: %v\n", err)
+				span.Fail(err)
+				span.End()
+				continue
 			}
+		}
 
-			if processErr != nil {
-				fmt.Printf("❌ Error processing pair: %v\n", processErr)
+		// Process the files
+		opts := processOptions{Mode: mode, Marker: cfg.Marker, Priority: priority, Examples: examples, Keys: cfg.Keys, Only: only, Stage: cfg.Staging, Held: pendingReview.Keys(pair.TargetFile), FS: tx, Journal: record, EmptySource: cfg.EmptySource}
+		opts.Cache = translations
+		opts.KeyContext = cfg.KeyContext
+		opts.BatchTokens = gptHandler.BatchLimit()
+		if !cfg.Staging {
+			// Staged translations may be rejected, so they are not remembered
+			opts.Arrays = arrays
+		}
+		scope := opts.restrict(source).LocaleItemsMap
+		_, before := countKeys(scope, target.LocaleItemsMap, cfg.EmptySource)
+		if cfg.FuzzyMatch != nil {
+			opts.FuzzyThreshold = cfg.FuzzyMatch.Threshold
+		}
+		var processErr error
+		if batchSize > 0 {
+			processErr = batch_process(pairCtx, gptHandler, source, target, nil, batchSize, opts)
+		} else {
+			processErr = single_process(pairCtx, gptHandler, source, target, nil, opts)
+		}
+		span.Fail(processErr)
+		span.End()
+
+		if errors.Is(processErr, gpt.ErrBudgetExhausted) {
+			fmt.Printf("🛑 Aborting the run: %v. Translations so far were saved, rerun to resume.\n", processErr)
+			aborted = true
+			runErr = processErr
+		} else if processErr != nil && cfg.Experimental(pair.TargetLang) {
+			// Experimental languages are updated opportunistically and don't fail the run
+			fmt.Printf("⚠️ Error processing experimental language %s: %v\n", pair.TargetLang, processErr)
+		} else if processErr != nil {
+			fmt.Printf("❌ Error processing pair: %v\n", processErr)
+			if runErr == nil {
+				runErr = processErr
 			}
+		}
 
-			completedFiles++
+		completedFiles++
 
-			// Update statistics
-			totalKeys += len(source.LocaleItemsMap)
-			translatedCount := countTranslatedKeys(source.LocaleItemsMap, target.LocaleItemsMap)
-			translatedKeys += translatedCount
-			failedKeys += len(source.LocaleItemsMap) - translatedCount
+		// Update statistics
+		scopeKeys, translatedCount := countKeys(scope, target.LocaleItemsMap, cfg.EmptySource)
+		totalKeys += scopeKeys
+		translatedKeys += translatedCount
+		failedKeys += scopeKeys - translatedCount
+		if translatedCount > before {
+			newlyTranslated[pair.TargetLang] += translatedCount - before
 		}
+		if aborted {
+			break
+		}
+	}
 
-		// Print summary
-		fmt.Printf("\n📊 Summary:\n")
-		fmt.Printf("- Files processed: %d/%d\n", completedFiles, totalFiles)
-		fmt.Printf("- Total keys: %d\n", totalKeys)
-		fmt.Printf("- Translated keys: %d (%.1f%%)\n", translatedKeys, float64(translatedKeys)/float64(totalKeys)*100)
-		fmt.Printf("- Failed keys: %d (%.1f%%)\n", failedKeys, float64(failedKeys)/float64(totalKeys)*100)
+	if err := commitTransaction(ctx, tx); err != nil {
+		aborted = true
+		runErr = err
+	} else if err := arrays.Save(); err != nil {
+		fmt.Printf("⚠️ Could not save the array item memory: %v\n", err)
+	}
 
-		fmt.Println("\n✅ Sync completed")
-	},
+	recordThroughput(gptHandler, batchSize > 0)
+	record.finish(gptHandler, runErr)
+	saveTranslationCache(translations)
+	printUsageReport(gptHandler)
+	printKeyHealth(gptHandler)
+	printCorrectionReport()
+	if syncMetrics != nil {
+		recordSyncMetrics(syncMetrics, rootDir, cfg, gptHandler, newlyTranslated)
+	}
+
+	// Print summary
+	fmt.Printf("\n📊 Summary:\n")
+	fmt.Printf("- Files processed: %d/%d\n", completedFiles, totalFiles)
+	fmt.Printf("- Total keys: %d\n", totalKeys)
+	fmt.Printf("- Translated keys: %d (%.1f%%)\n", translatedKeys, float64(translatedKeys)/float64(totalKeys)*100)
+	fmt.Printf("- Failed keys: %d (%.1f%%)\n", failedKeys, float64(failedKeys)/float64(totalKeys)*100)
+
+	if aborted {
+		fmt.Println("\n🛑 Sync aborted")
+		return
+	}
+	fmt.Println("\n✅ Sync completed")
+}
+
+// applyOutputSettings sets how catalogs are written from cfg
+func applyOutputSettings(cfg *config.Config) {
+	rewriteOutput = cfg.Rewrite
+	shrinkGuard = config.DefaultShrinkGuard()
+	if cfg.ShrinkGuard != nil {
+		shrinkGuard = *cfg.ShrinkGuard
+	}
+	sortOptions = cfg.Sort
+}
+
+// resolveAPIKeys returns the API keys of the configured provider. The OPENAI_API_KEY
+// (or GEMINI_API_KEY) environment variable, comma-separated for several keys, takes
+// precedence over the config file.
+func resolveAPIKeys(cfg *config.Config) []string {
+	keys := []string{}
+	if env := os.Getenv(apiKeyEnv(cfg)); env != "" {
+		for _, key := range strings.Split(env, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	if cfg == nil {
+		return keys
+	}
+	if cfg.Provider == gpt.ProviderGemini {
+		if cfg.Gemini != nil && cfg.Gemini.APIKey != "" {
+			keys = append(keys, cfg.Gemini.APIKey)
+		}
+		return keys
+	}
+	if cfg.APIKey != "" {
+		keys = append(keys, cfg.APIKey)
+	}
+	for _, key := range cfg.APIKeys {
+		if key != "" && key != cfg.APIKey {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // countTranslatedKeys counts how many keys in source have translations in target
@@ -240,7 +618,38 @@ func init() {
 	syncCmd.Flags().String("source", "en", "Source language code (default: en)")
 	syncCmd.Flags().StringVar(&translationMode, "mode", "missing", "Translation mode: 'full' (translate all) or 'missing' (only translate missing keys)")
 	syncCmd.Flags().IntVar(&batchSize, "batch", 0, "Size of the batch for translations. If 0 or not provided, translates one at a time.")
+	syncCmd.Flags().IntVar(&batchTokens, "batch-tokens", 0, "Estimated tokens the texts of a batch may take, so their translations fit in --max-tokens (default: derived from the model)")
 	syncCmd.Flags().String("config", "", "Path to configuration file")
+	syncCmd.Flags().BoolVar(&retryBlocked, "retry-blocked", false, "Translate again the keys the provider refused for content policy reasons in earlier runs")
+	syncCmd.Flags().BoolVar(&safeMode, "safe", false, "Treat source values as untrusted: neutralize and delimit them in prompts and reject translations with meta-commentary")
+	syncCmd.Flags().BoolVar(&localizeExamples, "localize-examples", false, "Rewrite number and date examples copied from the source (e.g. 1,234.56) in the conventions of the target language")
+	syncCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Ask for reproducible translations: temperature 0, a fixed seed and identical prompts for identical inputs")
+	syncCmd.Flags().IntVar(&seedFlag, "seed", 0, "Sampling seed sent to providers supporting one, so reruns pick the same words")
+	syncCmd.Flags().Float64Var(&temperatureFlag, "temperature", 0.1, "Sampling temperature of translations, from 0 to 2; deterministic mode uses 0")
+	syncCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Completion token cap of translation requests (default 1024, 2048 for batches)")
+	syncCmd.Flags().StringVar(&systemPrompt, "system-prompt", "", "System prompt template replacing the default one; may use {{targetLang}}, {{sourceLang}}, {{key}} and {{glossary}}")
+	syncCmd.Flags().IntVar(&fewShotExamples, "examples", defaultExamples, "Approved translations of similar texts sent as examples with each text (0 disables)")
+	syncCmd.Flags().StringVar(&priorityFile, "priority", "", "Usage-frequency JSON file (key -> hit count); the most used keys are translated first")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the estimated requests, tokens and cost of the pending texts without translating")
+	syncCmd.Flags().StringVar(&emptySource, "empty-source", "", "What to do with keys whose source value is empty: skip (default), copy-source, translate-as-empty or error")
+	syncCmd.Flags().BoolVar(&noCache, "no-cache", false, "Translate every text anew instead of reusing the translations cached by earlier runs")
+	syncCmd.Flags().BoolVar(&dryRunDiff, "diff", false, "With --dry-run, preview the keys each target file would gain or change as a diff")
+	syncCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Estimated cost in USD from which the run stops translating (0 disables)")
+	syncCmd.Flags().Float64Var(&confirmCost, "confirm-cost", defaultConfirmCost, "Estimated cost in USD above which confirmation is asked before starting (0 disables)")
+	syncCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Start without asking for confirmation, whatever the estimated cost")
+	syncCmd.Flags().BoolVar(&forceWrite, "force", false, "Write target files even if they would lose most of their keys or size")
+	syncCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the project lock left by another run before starting")
+	syncCmd.Flags().BoolVar(&asyncMode, "async", false, "Submit pending strings to the OpenAI Batch API and return; fetch the results later with i18n-cli batch fetch")
+	syncCmd.Flags().BoolVar(&politeMode, "polite", false, "Throttle requests (1 per second and pauses during the business hours of the config) to leave the quota of shared API keys to production")
+	syncCmd.Flags().BoolVar(&stageMode, "stage", false, "Hold new translations for review with i18n-cli serve instead of writing them to catalogs")
+	syncCmd.Flags().BoolVar(&rewriteOutput, "rewrite", false, "Rewrite whole target files with sorted keys instead of patching only changed keys")
+
+	syncCmd.Flags().String("patch", "", "JSON Patch (RFC 6902) or JSON Merge Patch file of source changes to apply, translating exactly the changed keys")
+	syncCmd.Flags().String("patch-file", "", "Source catalog the patch applies to, e.g. common.json (needed when there are several)")
+	syncCmd.Flags().Bool("watch", false, "Keep running and sync again when source files or the configuration file change")
+	syncCmd.Flags().Duration("interval", 2*time.Second, "How often to check for changes in watch mode")
+	syncCmd.Flags().Duration("debounce", time.Second, "How long source files must stay unchanged in watch mode before syncing")
+	syncCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address in watch mode, e.g. :9090")
 
 	syncCmd.MarkFlagRequired("root")
 
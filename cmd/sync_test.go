@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSyncDefaultConfig tests that the flags configure a sync run without a
+// configuration file
+func TestSyncDefaultConfig(t *testing.T) {
+	t.Cleanup(func() { safeMode, localizeExamples = false, false })
+
+	safeMode, localizeExamples = true, true
+	cfg, err := loadSyncConfig(syncCmd, "")
+	require.NoError(t, err)
+	assert.True(t, cfg.Safe)
+	assert.True(t, cfg.LocalizeExamples)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/tracing"
+)
+
+// withTracing returns ctx carrying a tracer exporting spans as set in the tracing
+// section of cfg, or ctx itself when tracing isn't configured or ctx already has one
+func withTracing(ctx context.Context, cfg *config.Config) context.Context {
+	if cfg == nil || cfg.Tracing == nil || tracing.FromContext(ctx) != nil {
+		return ctx
+	}
+	t := tracing.New(*cfg.Tracing)
+	if !t.Exports() {
+		fmt.Println("⚠️ Tracing is enabled but has no endpoint nor file: set tracing.endpoint, tracing.file or OTEL_EXPORTER_OTLP_ENDPOINT")
+		return ctx
+	}
+	return tracing.WithTracer(ctx, t)
+}
+
+// traceRun starts the root span of a run named name, and returns ctx carrying it and
+// the function ending it with the error of the run and exporting the spans
+func traceRun(ctx context.Context, name string, attrs ...string) (context.Context, func(err error)) {
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	return ctx, func(err error) {
+		if span == nil {
+			return
+		}
+		span.Fail(err)
+		span.End()
+		// The spans are exported even when the run was canceled
+		if err := tracing.FromContext(ctx).Flush(context.Background()); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}
+}
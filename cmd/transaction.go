@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/tracing"
+)
+
+// transaction is the file system of runs updating many catalogs. Writes are queued in
+// memory and only reach the disk on Commit, together, so that a run failing or
+// interrupted half-way never leaves some languages updated and others not. Reads see
+// the queued contents.
+type transaction struct {
+	mu      sync.Mutex
+	writes  map[string][]byte
+	appends map[string][]byte
+	removes map[string]bool
+}
+
+func newTransaction() *transaction {
+	return &transaction{writes: map[string][]byte{}, appends: map[string][]byte{}, removes: map[string]bool{}}
+}
+
+func (t *transaction) ReadFile(path string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.removes[path] {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	if data, ok := t.writes[path]; ok {
+		return append([]byte(nil), data...), nil
+	}
+	data, err := os.ReadFile(path)
+	if pending, ok := t.appends[path]; ok {
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return append(data, pending...), nil
+	}
+	return data, err
+}
+
+func (t *transaction) WriteFile(path string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writes[path] = append([]byte(nil), data...)
+	delete(t.appends, path)
+	delete(t.removes, path)
+	return nil
+}
+
+func (t *transaction) Remove(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.writes, path)
+	delete(t.appends, path)
+	t.removes[path] = true
+	return nil
+}
+
+func (t *transaction) AppendFile(path string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if written, ok := t.writes[path]; ok {
+		t.writes[path] = append(written, data...)
+		return nil
+	}
+	if t.removes[path] {
+		delete(t.removes, path)
+		t.writes[path] = append([]byte(nil), data...)
+		return nil
+	}
+	t.appends[path] = append(t.appends[path], data...)
+	return nil
+}
+
+// Commit writes the queued files to disk. Every queued catalog must parse, and every
+// file is first written to a temporary file next to it; only then are they all renamed
+// into place. Should a rename fail, the files already replaced are restored, so either
+// every file changes or none does. Removals, such as emptied sidecars, and appends,
+// such as change logs, are made last.
+func (t *transaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	paths := make([]string, 0, len(t.writes))
+	for path := range t.writes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			if err := (&parser.LocaleFileContent{}).ParseJSON(t.writes[path]); err != nil {
+				return fmt.Errorf("invalid catalog %s: %w", path, err)
+			}
+		}
+	}
+
+	// Stage every file, removing the staged ones when one can't be written
+	temps := make(map[string]string, len(paths))
+	discard := func() {
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+	}
+	for _, path := range paths {
+		tmp, err := stageFile(path, t.writes[path])
+		if err != nil {
+			discard()
+			return fmt.Errorf("staging %s: %w", path, err)
+		}
+		temps[path] = tmp
+	}
+
+	// Keep the current contents to roll back to
+	previous := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			discard()
+			return err
+		}
+		if err == nil {
+			previous[path] = data
+		}
+	}
+
+	for i, path := range paths {
+		if err := os.Rename(temps[path], path); err != nil {
+			discard()
+			for _, done := range paths[:i] {
+				if data, ok := previous[done]; ok {
+					writeFileAtomic(done, data)
+				} else {
+					os.Remove(done)
+				}
+			}
+			return fmt.Errorf("replacing %s, changes rolled back: %w", path, err)
+		}
+	}
+
+	for path := range t.removes {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for path, data := range t.appends {
+		if err := (osFS{}).AppendFile(path, data); err != nil {
+			return err
+		}
+	}
+
+	t.writes, t.appends, t.removes = map[string][]byte{}, map[string][]byte{}, map[string]bool{}
+	return nil
+}
+
+// commitTransaction commits the writes of a run, reporting the error when none could
+// be made
+func commitTransaction(ctx context.Context, tx *transaction) error {
+	tx.mu.Lock()
+	files := len(tx.writes) + len(tx.appends)
+	tx.mu.Unlock()
+	_, span := tracing.Start(ctx, "files.commit", "files", strconv.Itoa(files))
+	defer span.End()
+
+	err := tx.Commit()
+	if err != nil {
+		span.Fail(err)
+		fmt.Printf("❌ No file written: %v\n", err)
+	}
+	return err
+}
+
+// stageFile writes data to a temporary file next to path, creating its directory if
+// needed, and returns the name of the temporary file
+func stageFile(path string, data []byte) (string, error) {
+	if err := checkWritable(path); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
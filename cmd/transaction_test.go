@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransaction tests that queued writes are visible to reads but reach the disk only
+// on commit, all together or not at all
+func TestTransaction(t *testing.T) {
+	dir := t.TempDir()
+	fr, de := filepath.Join(dir, "fr", "app.json"), filepath.Join(dir, "de", "app.json")
+	log := filepath.Join(dir, "fr", "app.json.log")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fr), 0755))
+	require.NoError(t, os.WriteFile(fr, []byte(`{"a": "Un"}`), 0644))
+	require.NoError(t, os.WriteFile(log, []byte("first\n"), 0644))
+
+	tx := newTransaction()
+	require.NoError(t, tx.WriteFile(fr, []byte(`{"a": "Un", "b": "Deux"}`)))
+	require.NoError(t, tx.WriteFile(de, []byte(`{"a": "Eins"}`)))
+	require.NoError(t, tx.AppendFile(log, []byte("second\n")))
+
+	data, err := tx.ReadFile(fr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": "Un", "b": "Deux"}`, string(data))
+	data, err = tx.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+	data, _ = os.ReadFile(fr)
+	assert.JSONEq(t, `{"a": "Un"}`, string(data), "nothing is written before the commit")
+	assert.NoFileExists(t, de)
+
+	require.NoError(t, tx.Commit())
+	data, _ = os.ReadFile(fr)
+	assert.JSONEq(t, `{"a": "Un", "b": "Deux"}`, string(data))
+	data, _ = os.ReadFile(de)
+	assert.JSONEq(t, `{"a": "Eins"}`, string(data))
+	data, _ = os.ReadFile(log)
+	assert.Equal(t, "first\nsecond\n", string(data))
+
+	// An invalid catalog or a file that can't be staged leaves every file as it was
+	tx = newTransaction()
+	require.NoError(t, tx.WriteFile(de, []byte(`{"a": "Zwei"}`)))
+	require.NoError(t, tx.WriteFile(fr, []byte(`{"a": `)))
+	assert.ErrorContains(t, tx.Commit(), "invalid catalog")
+
+	tx = newTransaction()
+	require.NoError(t, tx.WriteFile(de, []byte(`{"a": "Zwei"}`)))
+	require.NoError(t, tx.WriteFile(filepath.Join(fr, "nested.json"), []byte(`{}`)))
+	assert.ErrorContains(t, tx.Commit(), "staging")
+
+	data, _ = os.ReadFile(de)
+	assert.JSONEq(t, `{"a": "Eins"}`, string(data))
+	entries, err := os.ReadDir(filepath.Dir(de))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "staged files are removed")
+}
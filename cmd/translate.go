@@ -3,8 +3,8 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,100 +12,636 @@ import (
 	"time"
 
 	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/align"
+	"github.com/pandodao/i18n-cli/internal/cache"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/dnt"
+	"github.com/pandodao/i18n-cli/internal/escaping"
+	"github.com/pandodao/i18n-cli/internal/geo"
+	"github.com/pandodao/i18n-cli/internal/glossary"
 	"github.com/pandodao/i18n-cli/internal/gpt"
-
+	"github.com/pandodao/i18n-cli/internal/icu"
+	"github.com/pandodao/i18n-cli/internal/keymeta"
+	"github.com/pandodao/i18n-cli/internal/keyorder"
+	"github.com/pandodao/i18n-cli/internal/localefmt"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/markup"
+	"github.com/pandodao/i18n-cli/internal/placeholder"
+	"github.com/pandodao/i18n-cli/internal/register"
+	"github.com/pandodao/i18n-cli/internal/safety"
+	"github.com/pandodao/i18n-cli/internal/script"
+	"github.com/pandodao/i18n-cli/internal/state"
+	"github.com/pandodao/i18n-cli/internal/templating"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/pandodao/i18n-cli/internal/tracing"
+
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/language"
-	"golang.org/x/text/language/display"
 )
 
 var translateCmd = &cobra.Command{
 	Use: "translate",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			fmt.Println("environment variable OPENAI_API_KEY is empty")
+		opts := processOptions{Mode: translationMode, Marker: marker.Default(), Examples: fewShotExamples}
+		if cmd.Flags().Changed("seed") {
+			seed = &seedFlag
+		}
+		if cmd.Flags().Changed("temperature") {
+			temperature = &temperatureFlag
+		}
+		var cfg *config.Config
+		configPath, _ := cmd.Flags().GetString("config")
+		startInvocation(cmd, configPath)
+		if configPath != "" {
+			var err error
+			cfg, err = config.LoadConfig(configPath)
+			if err != nil {
+				cmd.PrintErrln("load config failed: ", err)
+				return
+			}
+			if !cmd.Flags().Changed("mode") {
+				opts.Mode = cfg.Mode
+			}
+			if !cmd.Flags().Changed("batch") {
+				batchSize = cfg.BatchSize
+			}
+			if !cmd.Flags().Changed("batch-tokens") {
+				batchTokens = cfg.BatchTokens
+			}
+			if !cmd.Flags().Changed("rewrite") {
+				rewriteOutput = cfg.Rewrite
+			}
+			if !cmd.Flags().Changed("safe") {
+				safeMode = cfg.Safe
+			}
+			if !cmd.Flags().Changed("localize-examples") {
+				localizeExamples = cfg.LocalizeExamples
+			}
+			if !cmd.Flags().Changed("deterministic") {
+				deterministic = cfg.Deterministic
+			}
+			if !cmd.Flags().Changed("seed") {
+				seed = cfg.Seed
+			}
+			if !cmd.Flags().Changed("temperature") {
+				temperature = cfg.Temperature
+			}
+			if !cmd.Flags().Changed("max-tokens") {
+				maxTokens = cfg.MaxTokens
+			}
+			if !cmd.Flags().Changed("system-prompt") {
+				systemPrompt = cfg.SystemPrompt
+			}
+			opts.Marker = cfg.Marker
+			if cfg.ShrinkGuard != nil {
+				shrinkGuard = *cfg.ShrinkGuard
+			}
+			sortOptions = cfg.Sort
+			if !cmd.Flags().Changed("priority") && cfg.PriorityFile != "" {
+				priorityFile = cfg.PriorityFile
+			}
+			if !cmd.Flags().Changed("examples") && cfg.Examples != nil {
+				opts.Examples = *cfg.Examples
+			}
+			if cfg.FuzzyMatch != nil {
+				opts.FuzzyThreshold = cfg.FuzzyMatch.Threshold
+			}
+			if !cmd.Flags().Changed("confirm-cost") && cfg.ConfirmCost != nil {
+				confirmCost = *cfg.ConfirmCost
+			}
+			if !cmd.Flags().Changed("max-cost") {
+				maxCost = cfg.MaxCost
+			}
+			if !cmd.Flags().Changed("empty-source") {
+				emptySource = cfg.EmptySource
+			}
+			opts.Keys = cfg.Keys
+			registers, langNotes, geoNames = cfg.Registers(), cfg.Instructions, cfg.GeoNames
+			framework = cfg.Framework
+		}
+		if !config.ValidEmptyPolicy(emptySource) {
+			cmd.PrintErrf("invalid empty-source policy %q: use %s\n", emptySource, strings.Join(config.EmptyPolicies, ", "))
+			return
+		}
+		opts.EmptySource = emptySource
+		var runErr error
+		ctx, endTrace := traceRun(withTracing(ctx, cfg), "translate")
+		defer func() { endTrace(runErr) }()
+		var err error
+		if termbase, err = loadGlossary(cfg); err != nil {
+			cmd.PrintErrln("read glossary failed: ", err)
+			return
+		}
+		if templates, err = loadValues(cfg); err != nil {
+			cmd.PrintErrln("read values file failed: ", err)
 			return
 		}
 
-		gptHandler := gpt.New(gpt.Config{
-			Keys:    []string{apiKey},
-			Timeout: time.Duration(60) * time.Second,
-		})
+		if priorityFile != "" {
+			priority, err := loadPriority(priorityFile)
+			if err != nil {
+				cmd.PrintErrln("read priority file failed: ", err)
+				return
+			}
+			opts.Priority = priority
+		}
 
+		apiKeys := resolveAPIKeys(cfg)
+		if len(apiKeys) == 0 && needsAPIKeys(cfg) && !dryRun {
+			fmt.Printf("environment variable %s is empty\n", apiKeyEnv(cfg))
+			return
+		}
+
+		gptHandler, err := newGPTHandler(cfg, apiKeys, time.Duration(60)*time.Second)
+		if err != nil {
+			cmd.PrintErrln("create gpt handler failed: ", err)
+			return
+		}
+		auditProvider = gptHandler.Provider() + "/" + gptHandler.Model()
+
+		if dryRunDiff && !dryRun {
+			fmt.Println("⚠️ --diff only applies to dry runs (--dry-run)")
+		}
+
+		dir, _ := cmd.Flags().GetString("dir")
+		if dir != "" {
+			release, err := acquireProjectLock(dir)
+			if err != nil {
+				cmd.PrintErrln("lock failed: ", err)
+				return
+			}
+			defer release()
+		}
+
+		_, load := tracing.Start(ctx, "files.load")
 		source, others, indep, err := provideFiles(cmd)
+		load.Fail(err)
+		load.End()
 		if err != nil {
-			cmd.PrintErrln("read files failed")
+			cmd.PrintErrln("read files failed: ", err)
+			return
+		}
+		if localizeExamples {
+			examplesFrom = source.Code
+		}
+
+		pending := []string{}
+		pendingByLang := map[string][]string{}
+		for _, item := range others {
+			texts, err := pendingTexts(source, item, indep, opts)
+			if err != nil {
+				cmd.PrintErrln("read markers failed: ", err)
+				return
+			}
+			pending = append(pending, texts...)
+			pendingByLang[item.Code] = append(pendingByLang[item.Code], texts...)
+		}
+		if dryRun {
+			printDryRun(gptHandler, batchSize, pendingByLang)
+			if dryRunDiff {
+				diffs := []fileChanges{}
+				for _, item := range others {
+					changes, err := pendingChanges(source, item, indep, opts)
+					if err != nil {
+						cmd.PrintErrln("read markers failed: ", err)
+						return
+					}
+					diffs = append(diffs, changes)
+				}
+				printDiffPreview(diffs)
+			}
+			return
+		}
+		if !approveRun(gptHandler, pending, batchSize, confirmCost) {
 			return
 		}
 
 		cmd.Printf("📝 source: %d records\n", len(source.LocaleItemsMap))
 		cmd.Println("🌐 Generating locale files:")
+		defer printUsageReport(gptHandler)
+		defer printKeyHealth(gptHandler)
+		defer printCorrectionReport()
+
+		record := startRun(gptHandler)
+		opts.Journal = record
+		defer func() { record.finish(gptHandler, runErr) }()
+
+		// Translations of array items are remembered once the catalogs are written
+		arrays, err := align.Load()
+		if err != nil {
+			fmt.Printf("⚠️ Could not read the array item memory: %v\n", err)
+		}
+		opts.Arrays = arrays
+		opts.Cache = openTranslationCache(gptHandler.Model())
+		opts.KeyContext = cfg != nil && cfg.KeyContext
+		defer saveTranslationCache(opts.Cache)
+		opts.BatchTokens = gptHandler.BatchLimit()
+
+		// Catalogs are written together once every language is done
+		tx := newTransaction()
+		opts.FS = tx
+		defer func() {
+			if err := commitTransaction(ctx, tx); err != nil {
+				runErr = err
+				return
+			}
+			if err := arrays.Save(); err != nil {
+				fmt.Printf("⚠️ Could not save the array item memory: %v\n", err)
+			}
+		}()
 
 		if batchSize == 0 {
 			for _, item := range others {
-				err = single_process(ctx, gptHandler, source, item, indep, translationMode)
+				itemCtx, span := tracing.Start(ctx, "file.process", "target", item.Path, "lang", item.Code)
+				err = single_process(itemCtx, gptHandler, source, item, indep, opts)
+				span.Fail(err)
+				span.End()
 				if err != nil {
 					cmd.PrintErrln("process failed: ", err)
+					runErr = err
 					return
 				}
 			}
 		} else {
 			for _, item := range others {
-				err = batch_process(ctx, gptHandler, source, item, indep, batchSize, translationMode)
+				itemCtx, span := tracing.Start(ctx, "file.process", "target", item.Path, "lang", item.Code)
+				err = batch_process(itemCtx, gptHandler, source, item, indep, batchSize, opts)
+				span.Fail(err)
+				span.End()
 				if err != nil {
 					cmd.PrintErrln("process failed: ", err)
+					runErr = err
 					return
 				}
 			}
 		}
+
+		recordThroughput(gptHandler, batchSize > 0)
 	},
 }
 
+// processOptions controls how a target file is processed
+type processOptions struct {
+	// Translation mode (full or missing)
+	Mode string
+	// How keys are flagged for retranslation
+	Marker marker.Marker
+	// Usage hit counts per key, the most used keys are translated first
+	Priority map[string]int
+	// Number of approved translations sent as few-shot examples with each text
+	Examples int
+	// Metadata of keys, such as the charset their translations are restricted to
+	Keys keymeta.Rules
+	// Embedding similarity from which an approved translation is offered as a draft, 0 disables
+	FuzzyThreshold float64
+	// Clock stamping logs, the system clock when nil
+	Clock Clock
+	// File system the catalog and logs are written to, the machine's when nil
+	FS FS
+	// Source keys the run is restricted to, all of them when nil
+	Only map[string]bool
+	// Hold new translations in the staging store for review instead of writing them
+	Stage bool
+	// Source keys left out of the run, such as the keys with a translation pending review
+	Held map[string]bool
+	// What to do with keys whose source value is empty, skip when empty
+	EmptySource string
+	// Translations of texts translated before, consulted before calling the provider;
+	// nil caches nothing
+	Cache *cache.Cache
+	// Keys and their descriptions are sent as context, so translations are cached per key
+	KeyContext bool
+	// Translate anew rather than from the cache, still caching the new translations
+	refresh bool
+	// Translations of the items of JSON array values, reused when items move; nil
+	// remembers nothing
+	Arrays *align.Memory
+	// Estimated tokens the texts of a batch may take, as counted by gpt.ItemTokens; batches
+	// are only bounded by their size when 0
+	BatchTokens int
+	// Run the outcome of each target file is recorded with, nothing is recorded when nil
+	Journal *runRecord
+	// Approved translations of the target the examples are picked from
+	memory *tm.Memory
+}
+
+func (o processOptions) clock() Clock {
+	if o.Clock == nil {
+		return systemClock{}
+	}
+	return o.Clock
+}
+
+// restrict returns source with only the keys the run is restricted to
+func (o processOptions) restrict(source *parser.LocaleFileContent) *parser.LocaleFileContent {
+	if o.Only == nil && len(o.Held) == 0 {
+		return source
+	}
+	restricted := *source
+	restricted.LocaleItemsMap = make(map[string]string, len(source.LocaleItemsMap))
+	for k, v := range source.LocaleItemsMap {
+		if (o.Only == nil || o.Only[k]) && !o.Held[k] {
+			restricted.LocaleItemsMap[k] = v
+		}
+	}
+	return &restricted
+}
+
+// withKey returns ctx carrying key and its metadata, the context of its translation
+func (o processOptions) withKey(ctx context.Context, key string) context.Context {
+	return o.withKeyContext(gpt.WithKey(ctx, key), []string{key})
+}
+
+// withKeyContext returns ctx carrying the descriptions and screenshots of the keys
+// that have some
+func (o processOptions) withKeyContext(ctx context.Context, keys []string) context.Context {
+	descriptions := map[string]string{}
+	screenshots := map[string]string{}
+	for _, k := range keys {
+		meta := o.Keys.Lookup(k)
+		if d := meta.Describe(); d != "" {
+			descriptions[k] = d
+		}
+		if meta.Screenshot != "" {
+			screenshots[k] = meta.Screenshot
+		}
+	}
+	return gpt.WithScreenshots(gpt.WithDescriptions(ctx, descriptions), screenshots)
+}
+
+func (o processOptions) fs() FS {
+	if o.FS == nil {
+		return osFS{}
+	}
+	return o.FS
+}
+
+// defaultExamples is the number of few-shot examples sent with each text by default
+const defaultExamples = 3
+
+// maxBatchExamples caps the few-shot examples sent with a batch
+const maxBatchExamples = 20
+
+// withMemory returns opts with the approved translations of target to pick examples
+// and drafts from: its existing translations, except the keys flagged for retranslation
+func (o processOptions) withMemory(ctx context.Context, gptHandler Translator, source, target *parser.LocaleFileContent, marked map[string]struct{}) processOptions {
+	if o.Examples <= 0 && o.FuzzyThreshold <= 0 {
+		return o
+	}
+	approved := make(map[string]string, len(target.LocaleItemsMap))
+	for k, v := range target.LocaleItemsMap {
+		if _, isMarked := marked[k]; !isMarked {
+			approved[k] = v
+		}
+	}
+	o.memory = tm.New()
+	o.memory.AddCatalog(source.LocaleItemsMap, approved, target.Code)
+
+	if o.FuzzyThreshold > 0 {
+		if err := embedSources(ctx, gptHandler, o.memory, source.LocaleItemsMap); err != nil {
+			fmt.Printf("⚠️ Fuzzy matching disabled for %s: %v\n", target.Path, err)
+		}
+	}
+	return o
+}
+
+// embedSources stores the embeddings of the source texts in memory, using the
+// embedding cache for texts embedded by previous runs
+func embedSources(ctx context.Context, gptHandler Translator, memory *tm.Memory, items map[string]string) error {
+	dir, err := state.CacheDir()
+	if err != nil {
+		return err
+	}
+	cache, err := tm.LoadEmbeddingCache(dir, string(gpt.EmbeddingModel))
+	if err != nil {
+		return err
+	}
+
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, text := range items {
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		if vector, ok := cache.Get(text); ok {
+			memory.SetVector(text, vector)
+		} else {
+			missing = append(missing, text)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	vectors, err := gptHandler.Embed(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for i, text := range missing {
+		memory.SetVector(text, vectors[i])
+		cache.Put(text, vectors[i])
+	}
+	return cache.Save()
+}
+
+// draftFor returns the approved translation of a near-identical text, if any is
+// similar enough to adapt
+func (o processOptions) draftFor(text string, target *parser.LocaleFileContent) (gpt.Example, bool) {
+	if o.memory == nil || o.FuzzyThreshold <= 0 {
+		return gpt.Example{}, false
+	}
+	match, ok := o.memory.Similar(text, target.Code)
+	if !ok || match.Score < o.FuzzyThreshold {
+		return gpt.Example{}, false
+	}
+	logrus.Debugf("fuzzy match %.2f for %q: %q", match.Score, text, match.Source)
+	return gpt.Example{Source: match.Source, Target: match.Target}, true
+}
+
+// enforceCharset returns result, or the source text when result has characters
+// outside the charset the key is restricted to
+func (o processOptions) enforceCharset(key, source, result string) string {
+	charset := o.Keys.Lookup(key).Charset
+	if keymeta.Allows(charset, result) {
+		return result
+	}
+	fmt.Printf("\n⚠️ Key %s: translation %q has characters outside charset %s, keeping the source text\n", key, result, charset)
+	return source
+}
+
+// finalize returns result, a translation of source into lang, with the number and date
+// examples it copies from the source rewritten in the conventions of lang when examples
+// are localized, and restricted to the charset of the key
+func (o processOptions) finalize(key, source, result, lang string) string {
+	if examplesFrom != "" {
+		result = localefmt.Rewrite(source, result, examplesFrom, lang)
+	}
+	return o.enforceCharset(key, source, result)
+}
+
+// examplesFor returns the approved translations whose sources are the most similar to text
+func (o processOptions) examplesFor(text string, target *parser.LocaleFileContent) []gpt.Example {
+	if o.memory == nil {
+		return nil
+	}
+	examples := []gpt.Example{}
+	for _, match := range o.memory.Nearest(text, target.Code, o.Examples) {
+		if match.Source != text {
+			examples = append(examples, gpt.Example{Source: match.Source, Target: match.Target})
+		}
+	}
+	return examples
+}
+
+// batchExamplesFor returns the examples of every text of a batch, without duplicates.
+// Batches can't carry drafts, so the drafts of their texts come first instead.
+func (o processOptions) batchExamplesFor(texts []string, target *parser.LocaleFileContent) []gpt.Example {
+	candidates := []gpt.Example{}
+	for _, text := range texts {
+		if draft, ok := o.draftFor(text, target); ok {
+			candidates = append(candidates, draft)
+		}
+	}
+	for _, text := range texts {
+		candidates = append(candidates, o.examplesFor(text, target)...)
+	}
+
+	examples := []gpt.Example{}
+	seen := map[string]bool{}
+	for _, e := range candidates {
+		if !seen[e.Source] && len(examples) < maxBatchExamples {
+			seen[e.Source] = true
+			examples = append(examples, e)
+		}
+	}
+	return examples
+}
+
 // logTranslationError logs translation errors to a file for later analysis
-func logTranslationError(key, sourceText, targetLang string, err error) {
-	// Create logs directory if it doesn't exist
-	logsDir := "translation_logs"
-	if _, statErr := os.Stat(logsDir); os.IsNotExist(statErr) {
-		os.Mkdir(logsDir, 0755)
+func (o processOptions) logTranslationError(key, sourceText, targetLang string, err error) {
+	stateDir, dirErr := state.ProjectDir()
+	if dirErr != nil {
+		fmt.Printf("Error opening log directory: %v\n", dirErr)
+		return
 	}
+	now := o.clock().Now()
+	logFile := filepath.Join(stateDir, "logs", fmt.Sprintf("translation_errors_%s.log", now.Format("2006-01-02")))
 
-	// Create or open log file
-	logFile := filepath.Join(logsDir, fmt.Sprintf("translation_errors_%s.log", time.Now().Format("2006-01-02")))
-	f, fileErr := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if fileErr != nil {
+	// Log the error with key, source text, target language, and error details
+	errMsg := fmt.Sprintf("%s Key: %s\nSource: %s\nTarget Language: %s\nError: %v\n---\n",
+		now.Format("2006/01/02 15:04:05"), key, sourceText, targetLang, err)
+	if fileErr := o.fs().AppendFile(logFile, []byte(errMsg)); fileErr != nil {
 		fmt.Printf("Error opening log file: %v\n", fileErr)
-		return
 	}
-	defer f.Close()
+}
 
-	// Create a logger
-	logger := log.New(f, "", log.LstdFlags)
+// warnSuspicious reports source values containing instructions to the model. In safe
+// mode they are translated as data like any other text.
+func warnSuspicious(source *parser.LocaleFileContent) {
+	for _, k := range orderedKeys(source.LocaleItemsMap, nil) {
+		if found := safety.Suspicious(source.LocaleItemsMap[k]); len(found) > 0 {
+			fmt.Printf("⚠️ Key %s contains instruction-like text (%s), translating it as data\n", k, strings.Join(found, ", "))
+		}
+	}
+}
 
-	// Log the error with key, source text, target language, and error details
-	errMsg := fmt.Sprintf("Key: %s\nSource: %s\nTarget Language: %s\nError: %v\n---\n",
-		key, sourceText, targetLang, err)
-	logger.Println(errMsg)
+// warnDuplicateKeys reports the keys defined more than once in catalogs. Only their
+// last value was read, the others are dropped when the catalog is written.
+func warnDuplicateKeys(catalogs ...*parser.LocaleFileContent) {
+	for _, catalog := range catalogs {
+		for _, d := range catalog.Duplicates {
+			fmt.Printf("⚠️ %s: %s, only the last value is kept\n", catalog.Path, d)
+		}
+	}
+}
+
+// withoutSecrets returns source without the values that look like they contain a
+// secret, reporting them: they are left untranslated rather than sent to the provider
+func withoutSecrets(gptHandler Translator, source *parser.LocaleFileContent) *parser.LocaleFileContent {
+	items := make(map[string]string, len(source.LocaleItemsMap))
+	for _, k := range orderedKeys(source.LocaleItemsMap, nil) {
+		v := source.LocaleItemsMap[k]
+		findings := gptHandler.Secrets(v)
+		if len(findings) == 0 {
+			items[k] = v
+			continue
+		}
+		found := make([]string, len(findings))
+		for i, f := range findings {
+			found[i] = f.String()
+		}
+		fmt.Printf("🔒 Key %s looks like it contains a secret (%s), not sending it to the provider\n", k, strings.Join(found, ", "))
+	}
+	if len(items) == len(source.LocaleItemsMap) {
+		return source
+	}
+	screened := *source
+	screened.LocaleItemsMap = items
+	return &screened
+}
+
+// saveFailedKeys saves the keys of target that failed to translate to a file in the
+// state directory for easier reference
+func (o processOptions) saveFailedKeys(target *parser.LocaleFileContent, failedKeys []string) {
+	stateDir, err := state.ProjectDir()
+	if err != nil {
+		fmt.Printf("Error saving failed keys: %v\n", err)
+		return
+	}
+	failedKeysFile := filepath.Join(stateDir, "failed_keys_"+filepath.Base(target.Path)+".txt")
+	content := strings.Join(failedKeys, "\n")
+	if err := o.fs().WriteFile(failedKeysFile, []byte(content)); err != nil {
+		fmt.Printf("Error saving failed keys: %v\n", err)
+		return
+	}
+	fmt.Printf("Full list of failed keys saved to %s\n", failedKeysFile)
 }
 
 // logEmptyTranslation logs when we receive empty translations
-func logEmptyTranslation(key, sourceText, targetLang string) {
+func (o processOptions) logEmptyTranslation(key, sourceText, targetLang string) {
 	// Log as an error but with specific error type
-	logTranslationError(key, sourceText, targetLang, fmt.Errorf("Empty translation received"))
+	o.logTranslationError(key, sourceText, targetLang, fmt.Errorf("Empty translation received"))
 }
 
-func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.LocaleFileContent, target *parser.LocaleFileContent, indep *parser.LocaleFileContent, mode string) error {
+func single_process(ctx context.Context, gptHandler Translator, source *parser.LocaleFileContent, target *parser.LocaleFileContent, indep *parser.LocaleFileContent, opts processOptions) error {
+	ctx = gpt.WithLanguages(ctx, source.Code, target.Code)
 	count := 1
 	failedKeys := []string{}
+	retranslatedKeys := []string{}
+	mode := opts.Mode
+	// Set when the provider failed too often, ending the run after saving the progress
+	var budgetErr error
+
+	// Find keys flagged for retranslation
+	marked, err := opts.Marker.Extract(opts.fs(), target)
+	if err != nil {
+		return err
+	}
+	warnDuplicateKeys(source, target)
+	warnLimits(source, target)
+	source = withoutSecrets(gptHandler, source)
+	opts = opts.withMemory(ctx, gptHandler, source, target, marked)
+	opts, blocks := opts.skipBlocked(target)
+	source = opts.restrict(source)
+	if safeMode {
+		warnSuspicious(source)
+	}
+
+	// Keys with an empty source value get the value of the policy
+	blanks, err := opts.applyEmptyPolicy(source, target)
+	if err != nil {
+		return err
+	}
 
 	// Find missing keys
 	missingKeys := findMissingKeys(source.LocaleItemsMap, target.LocaleItemsMap)
 	if len(missingKeys) > 0 {
 		fmt.Printf("Found %d missing keys for %s\n", len(missingKeys), target.Path)
 		for k := range missingKeys {
-			if _, ok := source.LocaleItemsMap[k]; ok {
+			if v, ok := source.LocaleItemsMap[k]; ok && !isBlank(v) {
 				target.LocaleItemsMap[k] = "" // Initialize with empty string to trigger translation
 			}
 		}
@@ -113,10 +649,16 @@ func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser
 
 	totalKeys := len(source.LocaleItemsMap)
 	translatedCount := 0
+	if opts.emptyPolicy() == config.EmptySkip {
+		totalKeys -= blanks
+	} else {
+		translatedCount = blanks
+	}
 
-	for k, v := range source.LocaleItemsMap {
+	for _, k := range orderedKeys(source.LocaleItemsMap, opts.Priority) {
+		v := source.LocaleItemsMap[k]
 		needToTranslate := false
-		if len(v) != 0 {
+		if !isBlank(v) {
 			if _, ok := target.LocaleItemsMap[k]; !ok {
 				// key does not exist, translate it
 				needToTranslate = true
@@ -128,12 +670,12 @@ func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser
 						target.LocaleItemsMap[k] = v
 					}
 				} else if mode == "full" {
-					// In full mode, also translate empty strings and strings starting with "!"
+					// In full mode, also translate empty strings and keys flagged for retranslation
 					if len(target.LocaleItemsMap[k]) == 0 {
 						// empty string, translate it
 						needToTranslate = true
-					} else if target.LocaleItemsMap[k][0] == '!' {
-						// value starts with "!", translate it
+					} else if _, isMarked := marked[k]; isMarked {
+						// key is flagged for retranslation, translate it
 						needToTranslate = true
 					}
 				} else if mode == "missing" {
@@ -143,71 +685,82 @@ func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser
 				}
 			}
 
+			if needToTranslate && protected.Key(k) {
+				// Keys that must never be translated get the source value
+				target.LocaleItemsMap[k] = v
+				translatedCount++
+				needToTranslate = false
+			}
 			if needToTranslate {
 				var translationSuccess bool = true
+				opts := opts
+				if _, isMarked := marked[k]; isMarked {
+					// Keys flagged for retranslation get a new translation, not the cached one
+					opts.refresh = true
+				}
+
+				// Lists of allowed values are translated item by item
+				delimiter := opts.listDelimiter(k, v)
+				if delimiter != "" {
+					result, err := translateList(opts.withKey(ctx, k), gptHandler, v, delimiter, target, opts)
+					if err != nil {
+						fmt.Printf("\n⚠️ Error translating list in key %s: %v\n", k, err)
+						opts.logTranslationError(k, v, target.Lang, err)
+						translationSuccess = false
+						if errors.Is(err, gpt.ErrBudgetExhausted) {
+							budgetErr = err
+						}
+					} else {
+						target.LocaleItemsMap[k] = opts.finalize(k, v, result, target.Code)
+					}
+				}
 
 				// Check if the value is a JSON array
 				isValidJSONArray := false
-				if strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+				if delimiter == "" && strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
 					var stringArray []string
 					if err := json.Unmarshal([]byte(v), &stringArray); err == nil {
 						isValidJSONArray = true
 
 						// This is actually a JSON array
-						translatedArray := make([]string, len(stringArray))
-						arrayTranslationFailed := false
-						for i, str := range stringArray {
-							translated, err := gptHandler.Translate(ctx, str, target.Lang)
-							if err != nil {
-								fmt.Printf("\n⚠️ Error translating array item in key %s: %v\n", k, err)
-								logTranslationError(k, str, target.Lang, err)
-								arrayTranslationFailed = true
-								break
-							}
-							// Check for empty translations
-							if translated == "" || translated == " " {
-								fmt.Printf("\n⚠️ Empty translation for array item in key %s\n", k)
-								logEmptyTranslation(k, str, target.Lang)
-								arrayTranslationFailed = true
-								break
-							}
-							translatedArray[i] = translated
-						}
-
-						if !arrayTranslationFailed {
-							// Convert back to JSON string
-							resultBytes, err := json.Marshal(translatedArray)
-							if err != nil {
-								fmt.Printf("\n⚠️ Error marshalling array for key %s: %v\n", k, err)
-								logTranslationError(k, v, target.Lang, err)
-								translationSuccess = false
-							} else {
-								target.LocaleItemsMap[k] = string(resultBytes)
+						_, isMarked := marked[k]
+						result, err := translateArray(ctx, gptHandler, k, stringArray, target, opts, isMarked)
+						if err != nil {
+							translationSuccess = false
+							if errors.Is(err, gpt.ErrBudgetExhausted) {
+								budgetErr = err
 							}
 						} else {
-							translationSuccess = false
+							target.LocaleItemsMap[k] = result
 						}
 					}
 				}
 
-				// If not a valid JSON array, translate as a regular string
-				if !isValidJSONArray {
-					result, err := gptHandler.Translate(ctx, v, target.Lang)
+				// If not a list or a valid JSON array, translate as a regular string
+				if delimiter == "" && !isValidJSONArray {
+					result, err := translateText(opts.withKey(ctx, k), gptHandler, v, target, opts)
 					if err != nil {
 						fmt.Printf("\n⚠️ Error translating key %s: %v\n", k, err)
-						logTranslationError(k, v, target.Lang, err)
+						opts.logTranslationError(k, v, target.Lang, err)
+						blocks.record(k, v, err)
 						translationSuccess = false
+						if errors.Is(err, gpt.ErrBudgetExhausted) {
+							budgetErr = err
+						}
 					} else if result == "" || result == " " {
 						fmt.Printf("\n⚠️ Empty translation for key %s\n", k)
-						logEmptyTranslation(k, v, target.Lang)
+						opts.logEmptyTranslation(k, v, target.Lang)
 						translationSuccess = false
 					} else {
-						target.LocaleItemsMap[k] = result
+						target.LocaleItemsMap[k] = opts.finalize(k, v, result, target.Code)
 					}
 				}
 
 				if translationSuccess {
 					translatedCount++
+					if _, isMarked := marked[k]; isMarked {
+						retranslatedKeys = append(retranslatedKeys, k)
+					}
 				} else {
 					failedKeys = append(failedKeys, k)
 				}
@@ -216,6 +769,9 @@ func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser
 			fmt.Printf("\r🔄 %s: %d/%d (Translated: %d)", target.Path, count, totalKeys, translatedCount)
 			count += 1
 		}
+		if budgetErr != nil {
+			break
+		}
 	}
 
 	// Report on failed translations
@@ -225,39 +781,70 @@ func single_process(ctx context.Context, gptHandler *gpt.Handler, source *parser
 			fmt.Println("Failed keys:", failedKeys)
 		}
 
-		// Save failed keys to a file for easier reference
-		failedKeysFile := "failed_keys_" + filepath.Base(target.Path) + ".txt"
-		content := strings.Join(failedKeys, "\n")
-		os.WriteFile(failedKeysFile, []byte(content), 0644)
-		fmt.Printf("Full list of failed keys saved to %s\n", failedKeysFile)
+		opts.saveFailedKeys(target, failedKeys)
+	}
+	if budgetErr != nil {
+		dropUntranslated(target, missingKeys)
 	}
 
-	buf, err := target.JSON()
+	blocks.save()
+	written, retranslatedKeys, err := opts.holdForReview(source, target, retranslatedKeys)
 	if err != nil {
 		return err
 	}
+	if err := opts.writeLocaleFile(written); err != nil {
+		return err
+	}
 
-	err = os.WriteFile(target.Path, buf, 0644)
-	if err != nil {
+	if err := opts.Marker.Clear(opts.fs(), written, retranslatedKeys); err != nil {
 		return err
 	}
 
 	fmt.Printf("\r✅ %s: %d/%d (Translated: %d, Failed: %d)\n", target.Path, totalKeys, totalKeys, translatedCount, len(failedKeys))
+	opts.Journal.addFile(target.Path, totalKeys, translatedCount, failedKeys)
 
-	return nil
+	return budgetErr
 }
 
-func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.LocaleFileContent, target *parser.LocaleFileContent, indep *parser.LocaleFileContent, batchSize int, mode string) error {
+func batch_process(ctx context.Context, gptHandler Translator, source *parser.LocaleFileContent, target *parser.LocaleFileContent, indep *parser.LocaleFileContent, batchSize int, opts processOptions) error {
+	ctx = gpt.WithLanguages(ctx, source.Code, target.Code)
 	var batch []string
 	var keys []string
+	// Estimated tokens of the texts of batch
+	var tokens int
 	var failedKeys []string
+	var retranslatedKeys []string
+	mode := opts.Mode
+	// Set when the provider failed too often, ending the run after saving the progress
+	var budgetErr error
+
+	// Find keys flagged for retranslation
+	marked, err := opts.Marker.Extract(opts.fs(), target)
+	if err != nil {
+		return err
+	}
+	warnDuplicateKeys(source, target)
+	warnLimits(source, target)
+	source = withoutSecrets(gptHandler, source)
+	opts = opts.withMemory(ctx, gptHandler, source, target, marked)
+	opts, blocks := opts.skipBlocked(target)
+	source = opts.restrict(source)
+	if safeMode {
+		warnSuspicious(source)
+	}
+
+	// Keys with an empty source value get the value of the policy
+	blanks, err := opts.applyEmptyPolicy(source, target)
+	if err != nil {
+		return err
+	}
 
 	// Find missing keys
 	missingKeys := findMissingKeys(source.LocaleItemsMap, target.LocaleItemsMap)
 	if len(missingKeys) > 0 {
 		fmt.Printf("Found %d missing keys for %s\n", len(missingKeys), target.Path)
 		for k := range missingKeys {
-			if _, ok := source.LocaleItemsMap[k]; ok {
+			if v, ok := source.LocaleItemsMap[k]; ok && !isBlank(v) {
 				target.LocaleItemsMap[k] = "" // Initialize with empty string to trigger translation
 			}
 		}
@@ -268,14 +855,25 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 			return nil
 		}
 
-		results, err := gptHandler.BatchTranslate(ctx, batch, target.Lang)
+		batchCtx := gpt.WithInstructions(gpt.WithGlossary(ctx, termbase.Prompt(batch, target.Code)), withLength(instructionsFor(target.Code), opts.batchLengthInstruction(keys)))
+		batchCtx = opts.withKeyContext(batchCtx, keys)
+		results, err := gptHandler.BatchTranslateWithExamples(batchCtx, keys, batch, target.Lang, opts.batchExamplesFor(batch, target))
+		var refusal *gpt.PolicyError
+		failed := map[int]bool{}
+		if errors.As(err, &refusal) && len(batch) > 1 {
+			results, failed, err = translateRefusedBatch(ctx, gptHandler, keys, batch, target, opts, blocks)
+		}
 		if err != nil {
 			// Don't fail immediately, record the error and continue
 			fmt.Printf("\n⚠️ Error translating batch: %v\n", err)
+			if errors.Is(err, gpt.ErrBudgetExhausted) {
+				budgetErr = err
+			}
 
 			// Log the error for each key in the batch
 			for i, src := range batch {
-				logTranslationError(keys[i], src, target.Lang, err)
+				opts.logTranslationError(keys[i], src, target.Lang, err)
+				blocks.record(keys[i], src, err)
 				failedKeys = append(failedKeys, keys[i])
 			}
 
@@ -283,29 +881,65 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 		}
 
 		for i, result := range results {
+			if failed[i] {
+				failedKeys = append(failedKeys, keys[i])
+				continue
+			}
 			// Check if the result is just a space or empty string (indicating a failed translation)
 			if result == " " || result == "" {
 				fmt.Printf("\n⚠️ Failed to translate key: %s\n", keys[i])
-				logEmptyTranslation(keys[i], batch[i], target.Lang)
+				opts.logEmptyTranslation(keys[i], batch[i], target.Lang)
 				failedKeys = append(failedKeys, keys[i])
 				// Don't update the target with an empty value
 				continue
 			}
-			target.LocaleItemsMap[keys[i]] = result
+			result = framework.Fix(batch[i], result)
+			err := checkTranslation(batch[i], result, target)
+			if err == nil {
+				err = opts.checkLength(keys[i], result)
+			}
+			if err != nil {
+				// Retry translations failing validation one at a time
+				fmt.Printf("\n⚠️ Key %s: %v, retrying\n", keys[i], err)
+				retried, err := correctText(opts.withKey(ctx, keys[i]), gptHandler, batch[i], target, opts, result, err)
+				if err != nil {
+					opts.logTranslationError(keys[i], batch[i], target.Lang, err)
+					failedKeys = append(failedKeys, keys[i])
+					if errors.Is(err, gpt.ErrBudgetExhausted) {
+						budgetErr = err
+					}
+					continue
+				}
+				result = retried
+			} else {
+				opts.Cache.Put(batch[i], target.Code, opts.cacheContext(keys[i], batch[i], target), result)
+			}
+			target.LocaleItemsMap[keys[i]] = opts.finalize(keys[i], batch[i], result, target.Code)
+			opts.rememberArray(target.Path, keys[i], batch[i], target.LocaleItemsMap[keys[i]])
+			if _, isMarked := marked[keys[i]]; isMarked {
+				retranslatedKeys = append(retranslatedKeys, keys[i])
+			}
 		}
 
 		batch = batch[:0] // Clear the batch
 		keys = keys[:0]   // Clear the keys
+		tokens = 0
 		return nil
 	}
 
 	count := 1
 	totalKeys := len(source.LocaleItemsMap)
 	translatedCount := 0
+	if opts.emptyPolicy() == config.EmptySkip {
+		totalKeys -= blanks
+	} else {
+		translatedCount = blanks
+	}
 
-	for k, v := range source.LocaleItemsMap {
+	for _, k := range orderedKeys(source.LocaleItemsMap, opts.Priority) {
+		v := source.LocaleItemsMap[k]
 		needToTranslate := false
-		if len(v) != 0 {
+		if !isBlank(v) {
 			if _, ok := target.LocaleItemsMap[k]; !ok {
 				needToTranslate = true
 			} else {
@@ -317,7 +951,7 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 					// In full mode, also check for empty strings and strings equal to source
 					if strings.EqualFold(target.LocaleItemsMap[k], v) || len(target.LocaleItemsMap[k]) == 0 {
 						needToTranslate = true
-					} else if target.LocaleItemsMap[k][0] == '!' {
+					} else if _, isMarked := marked[k]; isMarked {
 						needToTranslate = true
 					}
 				} else if mode == "missing" {
@@ -327,9 +961,65 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 				}
 			}
 
+			if needToTranslate && protected.Key(k) {
+				// Keys that must never be translated get the source value
+				target.LocaleItemsMap[k] = v
+				translatedCount++
+				needToTranslate = false
+			}
+			if delimiter := opts.listDelimiter(k, v); needToTranslate && delimiter != "" {
+				// Lists of allowed values are translated item by item rather than in batches
+				result, err := translateList(opts.withKey(ctx, k), gptHandler, v, delimiter, target, opts)
+				// Counted like batched keys, failures are subtracted in the summary
+				translatedCount++
+				if err != nil {
+					fmt.Printf("\n⚠️ Error translating list in key %s: %v\n", k, err)
+					opts.logTranslationError(k, v, target.Lang, err)
+					failedKeys = append(failedKeys, k)
+					if errors.Is(err, gpt.ErrBudgetExhausted) {
+						budgetErr = err
+					}
+				} else {
+					target.LocaleItemsMap[k] = opts.finalize(k, v, result, target.Code)
+					if _, isMarked := marked[k]; isMarked {
+						retranslatedKeys = append(retranslatedKeys, k)
+					}
+				}
+				needToTranslate = false
+			}
+			if items, ok := jsonArray(v); needToTranslate && ok && opts.remembersArray(target.Path, k, items, marked) {
+				// Arrays with remembered items only get their new items translated
+				result, err := translateArray(ctx, gptHandler, k, items, target, opts, false)
+				translatedCount++
+				if err != nil {
+					fmt.Printf("\n⚠️ Error translating array in key %s: %v\n", k, err)
+					opts.logTranslationError(k, v, target.Lang, err)
+					failedKeys = append(failedKeys, k)
+					if errors.Is(err, gpt.ErrBudgetExhausted) {
+						budgetErr = err
+					}
+				} else {
+					target.LocaleItemsMap[k] = result
+				}
+				needToTranslate = false
+			}
+			if _, isMarked := marked[k]; needToTranslate && !isMarked {
+				// Texts translated before aren't sent again
+				if cached, ok := opts.cached(k, v, target); ok {
+					target.LocaleItemsMap[k] = opts.finalize(k, v, cached, target.Code)
+					translatedCount++
+					needToTranslate = false
+				}
+			}
 			if needToTranslate {
+				// Batches are sent before their translations would outgrow the token cap
+				itemTokens := gpt.ItemTokens(k, v)
+				if opts.BatchTokens > 0 && len(batch) > 0 && tokens+itemTokens > opts.BatchTokens {
+					_ = sendBatch()
+				}
 				batch = append(batch, v)
 				keys = append(keys, k)
+				tokens += itemTokens
 				translatedCount++
 
 				if len(batch) >= batchSize {
@@ -341,10 +1031,13 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 			fmt.Printf("\r🔄 %s: %d/%d (Translated: %d)", target.Path, count, totalKeys, translatedCount)
 			count += 1
 		}
+		if budgetErr != nil {
+			break
+		}
 	}
 
 	// Process any remaining items
-	if len(batch) > 0 {
+	if len(batch) > 0 && budgetErr == nil {
 		_ = sendBatch()
 	}
 
@@ -355,24 +1048,265 @@ func batch_process(ctx context.Context, gptHandler *gpt.Handler, source *parser.
 			fmt.Println("Failed keys:", failedKeys)
 		}
 
-		// Save failed keys to a file for easier reference
-		failedKeysFile := "failed_keys_" + filepath.Base(target.Path) + ".txt"
-		content := strings.Join(failedKeys, "\n")
-		os.WriteFile(failedKeysFile, []byte(content), 0644)
-		fmt.Printf("Full list of failed keys saved to %s\n", failedKeysFile)
+		opts.saveFailedKeys(target, failedKeys)
+	}
+	if budgetErr != nil {
+		dropUntranslated(target, missingKeys)
 	}
 
-	buf, err := target.JSON()
+	blocks.save()
+	written, retranslatedKeys, err := opts.holdForReview(source, target, retranslatedKeys)
 	if err != nil {
 		return err
 	}
+	if err := opts.writeLocaleFile(written); err != nil {
+		return err
+	}
 
-	err = os.WriteFile(target.Path, buf, 0644)
-	if err != nil {
+	if err := opts.Marker.Clear(opts.fs(), written, retranslatedKeys); err != nil {
 		return err
 	}
 
 	fmt.Printf("\r✅ %s: %d/%d (Translated: %d, Failed: %d)\n", target.Path, totalKeys, totalKeys, translatedCount-len(failedKeys), len(failedKeys))
+	opts.Journal.addFile(target.Path, totalKeys, translatedCount-len(failedKeys), failedKeys)
+	return budgetErr
+}
+
+// remembersArray reports whether the array memory of opts has translations for some of
+// the items of the JSON array value of key k, which isn't flagged for retranslation
+func (o processOptions) remembersArray(catalog, k string, items []string, marked map[string]struct{}) bool {
+	if _, isMarked := marked[k]; isMarked {
+		return false
+	}
+	_, pending := o.Arrays.Align(catalog, k, items)
+	return len(pending) < len(items)
+}
+
+// rememberArray records the translation of the items of source, when source and
+// translation are JSON arrays of the same length
+func (o processOptions) rememberArray(catalog, k, source, translation string) {
+	items, ok := jsonArray(source)
+	if !ok {
+		return
+	}
+	if translated, ok := jsonArray(translation); ok {
+		o.Arrays.Remember(catalog, k, items, translated)
+	}
+}
+
+// translateArray translates the items of the JSON array value of key k one by one and
+// returns the translated array. Items the array memory of opts remembers a translation
+// for keep it, wherever they moved, unless retranslate is set; the translations are
+// remembered for the next time. Failures are logged.
+func translateArray(ctx context.Context, gptHandler Translator, k string, items []string, target *parser.LocaleFileContent, opts processOptions, retranslate bool) (string, error) {
+	translatedArray, pending := opts.Arrays.Align(target.Path, k, items)
+	if retranslate {
+		translatedArray, pending = make([]string, len(items)), make([]int, len(items))
+		for i := range items {
+			pending[i] = i
+		}
+	}
+	for _, i := range pending {
+		str := items[i]
+		translated, err := translateText(opts.withKey(ctx, k), gptHandler, str, target, opts)
+		if err != nil {
+			fmt.Printf("\n⚠️ Error translating array item in key %s: %v\n", k, err)
+			opts.logTranslationError(k, str, target.Lang, err)
+			return "", err
+		}
+		// Check for empty translations
+		if translated == "" || translated == " " {
+			fmt.Printf("\n⚠️ Empty translation for array item in key %s\n", k)
+			opts.logEmptyTranslation(k, str, target.Lang)
+			return "", fmt.Errorf("empty translation for array item %d", i)
+		}
+		translatedArray[i] = opts.finalize(k, str, translated, target.Code)
+	}
+
+	// Convert back to JSON string
+	resultBytes, err := json.Marshal(translatedArray)
+	if err != nil {
+		fmt.Printf("\n⚠️ Error marshalling array for key %s: %v\n", k, err)
+		opts.logTranslationError(k, strings.Join(items, "\n"), target.Lang, err)
+		return "", err
+	}
+	opts.Arrays.Remember(target.Path, k, items, translatedArray)
+	return string(resultBytes), nil
+}
+
+// validationRetries is how many times a translation failing validation is retried
+const validationRetries = 2
+
+// translateText translates text to the language of target, retrying translations
+// that fail validation. Approved translations of similar texts from the memory of
+// opts are sent as examples, and a near-identical one as a draft to adapt.
+func translateText(ctx context.Context, gptHandler Translator, text string, target *parser.LocaleFileContent, opts processOptions) (string, error) {
+	if cached, ok := opts.cached(gpt.KeyFrom(ctx), text, target); ok && opts.checkLength(gpt.KeyFrom(ctx), cached) == nil {
+		return cached, nil
+	}
+	return correctText(ctx, gptHandler, text, target, opts, "", nil)
+}
+
+// cached returns the translation of text, the value of key, into the language of target
+// from the cache of opts, if it has one that still passes validation
+func (o processOptions) cached(key, text string, target *parser.LocaleFileContent) (string, bool) {
+	if o.refresh {
+		return "", false
+	}
+	translation, ok := o.Cache.Get(text, target.Code, o.cacheContext(key, text, target))
+	if !ok || checkTranslation(text, translation, target) != nil {
+		return "", false
+	}
+	return translation, true
+}
+
+// cacheContext returns what the prompt of the translation of text, the value of key, into
+// the language of target says besides the text: its instructions and glossary, and its
+// key, description and screenshot with key context. Texts are only reused from the
+// cache for the same context, so that "Close" the verb isn't reused for "Close" the
+// adjective.
+func (o processOptions) cacheContext(key, text string, target *parser.LocaleFileContent) string {
+	parts := []string{withLength(instructionsFor(target.Code), o.lengthInstruction(key)), termbase.Prompt([]string{text}, target.Code)}
+	if o.KeyContext && key != "" {
+		meta := o.Keys.Lookup(key)
+		parts = append(parts, key, meta.Describe(), meta.Screenshot)
+	}
+	context := strings.Join(parts, "\x00")
+	if strings.Trim(context, "\x00") == "" {
+		return ""
+	}
+	return context
+}
+
+// correctText translates text like translateText, starting over from translation, a
+// translation that failed validation with violation, when violation is not nil. Each
+// retry quotes the violation of the previous translation so the model can fix it, and
+// counts towards the fix rates of the corrections report.
+func correctText(ctx context.Context, gptHandler Translator, text string, target *parser.LocaleFileContent, opts processOptions, translation string, violation error) (string, error) {
+	examples := opts.examplesFor(text, target)
+	draft, hasDraft := opts.draftFor(text, target)
+	ctx = gpt.WithGlossary(ctx, termbase.Prompt([]string{text}, target.Code))
+	key := gpt.KeyFrom(ctx)
+	ctx = gpt.WithInstructions(ctx, withLength(instructionsFor(target.Code), opts.lengthInstruction(key)))
+
+	// The first violation of the text is the one its fix is counted for
+	first, err := violation, violation
+	for attempt := 0; attempt <= validationRetries; attempt++ {
+		attemptCtx := ctx
+		if err != nil {
+			attemptCtx = gpt.WithCorrection(ctx, gpt.Correction{Translation: translation, Problem: correctionFor(err)})
+		}
+		var result string
+		var callErr error
+		if hasDraft {
+			result, callErr = gptHandler.AdaptDraft(attemptCtx, text, target.Lang, examples, draft)
+		} else {
+			result, callErr = gptHandler.TranslateWithExamples(attemptCtx, text, target.Lang, examples)
+		}
+		if callErr != nil {
+			return "", callErr
+		}
+		result = framework.Fix(text, result)
+		if err = checkTranslation(text, result, target); err == nil {
+			err = opts.checkLength(key, result)
+		}
+		if err == nil {
+			if first != nil {
+				corrections.record(target.Code, violationKind(first), true)
+			}
+			opts.Cache.Put(text, target.Code, opts.cacheContext(key, text, target), result)
+			return result, nil
+		}
+		if first == nil {
+			first = err
+		}
+		translation = result
+		if attempt < validationRetries {
+			fmt.Printf("\n⚠️ %v, retrying (attempt %d/%d)\n", err, attempt+1, validationRetries)
+		}
+	}
+	corrections.record(target.Code, violationKind(first), false)
+	return "", err
+}
+
+// checkTranslation validates a translation of source: it must keep the placeholders,
+// markup and framework syntax of the source, and follow the glossary, geographic names,
+// register and script of the target language, without meta-commentary in safe mode.
+// Texts kept verbatim are accepted; in templating mode the checks run on both texts
+// resolved with the values file.
+func checkTranslation(source, result string, target *parser.LocaleFileContent) error {
+	if result == source {
+		return nil
+	}
+	if safeMode {
+		if err := safety.Check(source, result); err != nil {
+			return &validationError{Kind: violationCommentary, Err: err}
+		}
+	}
+	if templates != nil {
+		if err := templates.Check(source, result); err != nil {
+			return &validationError{Kind: violationTemplate, Err: err}
+		}
+		source, result = templates.Resolve(source), templates.Resolve(result)
+	}
+	if err := framework.Check(source, result); err != nil {
+		return &validationError{Kind: violationSyntax, Err: err}
+	}
+	source, result = framework.Strip(source), framework.Strip(result)
+	if err := icu.Check(source, result); err != nil {
+		return &validationError{Kind: violationPlaceholders, Err: err}
+	}
+	if err := placeholder.Check(source, result); err != nil {
+		return &validationError{Kind: violationPlaceholders, Err: err}
+	}
+	if err := markup.Check(source, result); err != nil {
+		return &validationError{Kind: violationMarkup, Err: err}
+	}
+	if err := geoNames.Check(source, result, target.Code); err != nil {
+		return &validationError{Kind: violationGeography, Err: err}
+	}
+	if err := termbase.Check(source, result, target.Code); err != nil {
+		return &validationError{Kind: violationGlossary, Err: err}
+	}
+	if err := registers.Check(result, target.Code); err != nil {
+		return &validationError{Kind: violationRegister, Err: err}
+	}
+	if err := script.Check(result, target.Code); err != nil {
+		return &validationError{Kind: violationScript, Err: err}
+	}
+	return nil
+}
+
+// writeLocaleFile writes target with the system clock and file system
+func writeLocaleFile(target *parser.LocaleFileContent) error {
+	return processOptions{}.writeLocaleFile(target)
+}
+
+// writeLocaleFile writes target, patching only the changed keys unless whole-file
+// rewrites were requested, and records the changes in its change log
+func (o processOptions) writeLocaleFile(target *parser.LocaleFileContent) error {
+	var buf []byte
+	var err error
+	if rewriteOutput {
+		buf, err = target.SortedJSON(sortOptions)
+	} else {
+		buf, err = target.Patch()
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing, err := o.fs().ReadFile(target.Path); err == nil {
+		if err := guardShrink(target.Path, existing, buf); err != nil {
+			return err
+		}
+	}
+
+	if err := o.fs().WriteFile(target.Path, buf); err != nil {
+		return err
+	}
+
+	o.recordChanges(target)
 	return nil
 }
 
@@ -418,7 +1352,12 @@ func provideFiles(cmd *cobra.Command) (source *parser.LocaleFileContent, others
 	}
 	if dir != "" {
 		others = make([]*parser.LocaleFileContent, 0)
-		items, _ := os.ReadDir(dir)
+		skipped := 0
+		var items []os.DirEntry
+		items, err = os.ReadDir(dir)
+		if err != nil {
+			return
+		}
 		sourceBaseFile := filepath.Base(sourceFile)
 		for _, item := range items {
 			if !item.IsDir() {
@@ -434,14 +1373,20 @@ func provideFiles(cmd *cobra.Command) (source *parser.LocaleFileContent, others
 				}
 
 				localeContent := &parser.LocaleFileContent{}
-				if err = localeContent.ParseFromJSONFile(path.Join(dir, item.Name())); err != nil {
-					fmt.Println("parse file failed: ", err, ". skip this file.")
+				if parseErr := localeContent.ParseFromJSONFile(path.Join(dir, item.Name())); parseErr != nil {
+					fmt.Printf("⚠️ %v. skip this file.\n", parseErr)
+					skipped++
 					continue
 				}
 
 				others = append(others, localeContent)
 			}
 		}
+
+		if len(others) == 0 {
+			err = fmt.Errorf("no target language files found in %s (%d skipped)", dir, skipped)
+			return
+		}
 	} else {
 		err = fmt.Errorf("dir is required")
 		return
@@ -451,26 +1396,91 @@ func provideFiles(cmd *cobra.Command) (source *parser.LocaleFileContent, others
 }
 
 func langCodeToName(code string) (string, error) {
-	tag, err := language.Parse(code)
-	if err != nil {
-		return "", err
+	return parser.LangCodeToName(code)
+}
+
+// instructionsFor returns the prompt instructions of translations into lang: its
+// register, its geographic name policy, then the instructions set for it in the config
+func instructionsFor(lang string) string {
+	parts := []string{}
+	for _, text := range []string{registers.Instruction(lang), geoNames.Instruction(lang), langNotes.For(lang)} {
+		if text != "" {
+			parts = append(parts, text)
+		}
 	}
-	return display.Self.Name(tag), nil
+	return strings.Join(parts, " ")
 }
 
-var batchSize int          // Declare a variable to hold the batch size
-var translationMode string // Declare a variable to hold the translation mode
+var batchSize int                    // Declare a variable to hold the batch size
+var batchTokens int                  // Estimated tokens the texts of a batch may take, 0 to derive it from the model
+var translationMode string           // Declare a variable to hold the translation mode
+var rewriteOutput bool               // Rewrite whole files with sorted keys instead of patching changed keys
+var sortOptions keyorder.SortOptions // Key order used when rewriting whole files
+var priorityFile string              // Usage-frequency file used to translate the most used keys first
+var fewShotExamples int              // Approved translations sent as few-shot examples with each text
+var safeMode bool                    // Treat source values as untrusted user content
+var localizeExamples bool            // Value of the --localize-examples flag
+var examplesFrom string              // Source language whose number and date examples are rewritten, empty to keep them
+var deterministic bool               // Ask providers for reproducible translations
+var seedFlag int                     // Value of the --seed flag
+var seed *int                        // Sampling seed sent to providers, nil when unset
+var politeMode bool                  // Throttle requests to share the API keys with production features
+var temperatureFlag float64          // Value of the --temperature flag
+var temperature *float64             // Sampling temperature of translations, nil for the default
+var maxTokens int                    // Completion token cap of translation requests, 0 for the defaults
+var systemPrompt string              // System prompt template replacing the default one
+var termbase glossary.Glossary       // Terms always rendered the same way, nil without a glossary
+var registers register.Settings      // Register of translations per language
+var langNotes config.Instructions    // Extra prompt instructions per language
+var geoNames *geo.Settings           // Rendering of geographic names, nil to leave them to the model
+var framework escaping.Framework     // Frontend framework whose message syntax translations keep, "" for none
+var templates templating.Values      // Values of template variables, nil outside templating mode
+var protected *dnt.Protector         // Tokens and keys never translated, nil without a do-not-translate list
+var emptySource string               // Policy for keys whose source value is empty, skip when empty
 
 func init() {
 	translateCmd.Flags().String("dir", "", "the directory of language files")
 	translateCmd.Flags().String("source", "", "the source language file")
 	translateCmd.Flags().String("independent", "", "the independent language file")
+	translateCmd.Flags().String("config", "", "Path to configuration file")
 	translateCmd.Flags().IntVar(&batchSize, "batch", 0, "Size of the batch for translations. If 0 or not provided, translates one at a time.")
+	translateCmd.Flags().IntVar(&batchTokens, "batch-tokens", 0, "Estimated tokens the texts of a batch may take, so their translations fit in --max-tokens (default: derived from the model)")
 	translateCmd.Flags().StringVar(&translationMode, "mode", "full", "Translation mode: 'full' (translate all) or 'missing' (only translate missing keys)")
+	translateCmd.Flags().BoolVar(&retryBlocked, "retry-blocked", false, "Translate again the keys the provider refused for content policy reasons in earlier runs")
+	translateCmd.Flags().BoolVar(&safeMode, "safe", false, "Treat source values as untrusted: neutralize and delimit them in prompts and reject translations with meta-commentary")
+	translateCmd.Flags().BoolVar(&localizeExamples, "localize-examples", false, "Rewrite number and date examples copied from the source (e.g. 1,234.56) in the conventions of the target language")
+	translateCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Ask for reproducible translations: temperature 0, a fixed seed and identical prompts for identical inputs")
+	translateCmd.Flags().IntVar(&seedFlag, "seed", 0, "Sampling seed sent to providers supporting one, so reruns pick the same words")
+	translateCmd.Flags().Float64Var(&temperatureFlag, "temperature", 0.1, "Sampling temperature of translations, from 0 to 2; deterministic mode uses 0")
+	translateCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Completion token cap of translation requests (default 1024, 2048 for batches)")
+	translateCmd.Flags().StringVar(&systemPrompt, "system-prompt", "", "System prompt template replacing the default one; may use {{targetLang}}, {{sourceLang}}, {{key}} and {{glossary}}")
+	translateCmd.Flags().IntVar(&fewShotExamples, "examples", defaultExamples, "Approved translations of similar texts sent as examples with each text (0 disables)")
+	translateCmd.Flags().StringVar(&priorityFile, "priority", "", "Usage-frequency JSON file (key -> hit count); the most used keys are translated first")
+	translateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the estimated requests, tokens and cost of the pending texts without translating")
+	translateCmd.Flags().StringVar(&emptySource, "empty-source", "", "What to do with keys whose source value is empty: skip (default), copy-source, translate-as-empty or error")
+	translateCmd.Flags().BoolVar(&noCache, "no-cache", false, "Translate every text anew instead of reusing the translations cached by earlier runs")
+	translateCmd.Flags().BoolVar(&dryRunDiff, "diff", false, "With --dry-run, preview the keys each target file would gain or change as a diff")
+	translateCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Estimated cost in USD from which the run stops translating (0 disables)")
+	translateCmd.Flags().Float64Var(&confirmCost, "confirm-cost", defaultConfirmCost, "Estimated cost in USD above which confirmation is asked before starting (0 disables)")
+	translateCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Start without asking for confirmation, whatever the estimated cost")
+	translateCmd.Flags().BoolVar(&forceWrite, "force", false, "Write target files even if they would lose most of their keys or size")
+	translateCmd.Flags().BoolVar(&forceUnlock, "force-unlock", false, "Remove the project lock left by another run before starting")
+	translateCmd.Flags().BoolVar(&rewriteOutput, "rewrite", false, "Rewrite whole target files with sorted keys instead of patching only changed keys")
+	translateCmd.Flags().BoolVar(&politeMode, "polite", false, "Throttle requests (1 per second and pauses during the business hours of the config) to leave the quota of shared API keys to production")
 
 	rootCmd.AddCommand(translateCmd)
 }
 
+// dropUntranslated removes the missing keys still left empty from target, so that an
+// aborted run leaves them missing for the next one
+func dropUntranslated(target *parser.LocaleFileContent, missingKeys map[string]struct{}) {
+	for k := range missingKeys {
+		if target.LocaleItemsMap[k] == "" {
+			delete(target.LocaleItemsMap, k)
+		}
+	}
+}
+
 // Helper function to find missing keys in target compared to source
 func findMissingKeys(source, target map[string]string) map[string]struct{} {
 	missing := make(map[string]struct{})
@@ -3,15 +3,28 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/align"
+	"github.com/pandodao/i18n-cli/internal/audit"
+	"github.com/pandodao/i18n-cli/internal/cache"
+	"github.com/pandodao/i18n-cli/internal/config"
+	"github.com/pandodao/i18n-cli/internal/geo"
 	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/journal"
+	"github.com/pandodao/i18n-cli/internal/keymeta"
+	"github.com/pandodao/i18n-cli/internal/marker"
+	"github.com/pandodao/i18n-cli/internal/register"
+	"github.com/pandodao/i18n-cli/internal/secrets"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestFindMissingKeys tests the findMissingKeys function
@@ -137,7 +150,7 @@ func setupTestEnvironment(t *testing.T) (string, func()) {
 	return tempDir, cleanup
 }
 
-// MockTranslateFunc creates a function that can be used to patch the Translate method for testing
+// mockTranslate creates a function translating single texts for fakeTranslator
 func mockTranslate(expectedLang string, translations map[string]string) func(ctx context.Context, src, lang string) (string, error) {
 	return func(ctx context.Context, src, lang string) (string, error) {
 		if lang != expectedLang {
@@ -153,7 +166,7 @@ func mockTranslate(expectedLang string, translations map[string]string) func(ctx
 	}
 }
 
-// MockBatchTranslateFunc creates a function that can be used to patch the BatchTranslate method for testing
+// mockBatchTranslate creates a function translating batches for fakeTranslator
 func mockBatchTranslate(expectedLang string, translations map[string]string) func(ctx context.Context, srcs []string, lang string) ([]string, error) {
 	return func(ctx context.Context, srcs []string, lang string) ([]string, error) {
 		if lang != expectedLang {
@@ -173,6 +186,82 @@ func mockBatchTranslate(expectedLang string, translations map[string]string) fun
 	}
 }
 
+// fakeTranslator translates with mock functions instead of calling a provider
+type fakeTranslator struct {
+	translate func(ctx context.Context, src, lang string) (string, error)
+	batch     func(ctx context.Context, srcs []string, lang string) ([]string, error)
+	// Texts sent for translation
+	sent []string
+}
+
+func (f *fakeTranslator) TranslateWithExamples(ctx context.Context, text string, lang string, examples []gpt.Example) (string, error) {
+	f.sent = append(f.sent, text)
+	return f.translate(ctx, text, lang)
+}
+
+func (f *fakeTranslator) AdaptDraft(ctx context.Context, text string, lang string, examples []gpt.Example, draft gpt.Example) (string, error) {
+	f.sent = append(f.sent, text)
+	return f.translate(ctx, text, lang)
+}
+
+func (f *fakeTranslator) BatchTranslateWithExamples(ctx context.Context, keys []string, texts []string, lang string, examples []gpt.Example) ([]string, error) {
+	f.sent = append(f.sent, texts...)
+	return f.batch(ctx, texts, lang)
+}
+
+func (f *fakeTranslator) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, fmt.Errorf("embeddings are not supported")
+}
+
+func (f *fakeTranslator) Secrets(text string) []secrets.Finding {
+	return nil
+}
+
+// memFS keeps files in memory
+type memFS map[string][]byte
+
+func (m memFS) ReadFile(path string) ([]byte, error) {
+	if data, ok := m[path]; ok {
+		return data, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m memFS) WriteFile(path string, data []byte) error {
+	m[path] = append([]byte{}, data...)
+	return nil
+}
+
+func (m memFS) AppendFile(path string, data []byte) error {
+	m[path] = append(m[path], data...)
+	return nil
+}
+
+func (m memFS) Remove(path string) error {
+	if _, ok := m[path]; !ok {
+		return os.ErrNotExist
+	}
+	delete(m, path)
+	return nil
+}
+
+// fileNamed returns the content of the file of fsys with the given base name
+func (m memFS) fileNamed(name string) string {
+	for path, data := range m {
+		if filepath.Base(path) == name {
+			return string(data)
+		}
+	}
+	return ""
+}
+
+// fixedClock always tells the same time
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+var testTime = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
 // TestSingleProcess tests the single_process function with a small dataset
 func TestSingleProcess(t *testing.T) {
 	// Create source and target objects directly
@@ -215,6 +304,131 @@ func TestSingleProcess(t *testing.T) {
 
 	_, hasThanks := missingKeys["nested/thanks"]
 	assert.True(t, hasThanks, "nested/thanks should be missing")
+
+	// Only the missing keys are sent, and the catalog and its change log are written
+	target.Path = "/locales/fr-FR.json"
+	translator := &fakeTranslator{translate: mockTranslate("français", map[string]string{"Goodbye": "Au revoir"})}
+	fsys := memFS{}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: fsys}
+	assert.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+
+	assert.ElementsMatch(t, []string{"Goodbye", "Thank you"}, translator.sent)
+	assert.Equal(t, "Au revoir", target.LocaleItemsMap["farewell"])
+	assert.Equal(t, "TRANSLATED:Thank you", target.LocaleItemsMap["nested/thanks"])
+	assert.Equal(t, "Bonjour", target.LocaleItemsMap["greeting"])
+
+	written := &parser.LocaleFileContent{}
+	assert.NoError(t, written.ParseJSON(fsys[target.Path]))
+	assert.Equal(t, target.LocaleItemsMap, written.LocaleItemsMap)
+
+	history := string(fsys[audit.LogPath(target.Path)])
+	assert.Contains(t, history, `"key":"farewell"`)
+	assert.Contains(t, history, testTime.Format(time.RFC3339))
+}
+
+// TestSidecarTransaction tests that retranslation sidecars are cleared with the
+// catalogs on commit, and kept when the commit fails
+func TestSidecarTransaction(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fr.json")
+	sidecar := path + marker.SidecarExt
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": "Un"}`), 0644))
+	require.NoError(t, os.WriteFile(sidecar, []byte("a\n"), 0644))
+
+	run := func(tx *transaction) {
+		source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"a": "One", "b": "Two"}}
+		target := &parser.LocaleFileContent{Path: path, Code: "fr", Lang: "français", LocaleItemsMap: map[string]string{"a": "Un"}}
+		translator := &fakeTranslator{translate: mockTranslate("français", map[string]string{"One": "Une", "Two": "Deux"})}
+		opts := processOptions{Mode: "full", Marker: marker.Marker{Style: marker.StyleSidecar}, Clock: fixedClock{testTime}, FS: tx}
+		require.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+		assert.Equal(t, []string{"One", "Two"}, translator.sent)
+	}
+
+	// The commit fails on another catalog: the flag stays with the catalog it belongs to
+	tx := newTransaction()
+	run(tx)
+	_, err := tx.ReadFile(sidecar)
+	assert.True(t, os.IsNotExist(err), "the run sees the sidecar cleared")
+	require.NoError(t, tx.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"a": `)))
+	assert.Error(t, tx.Commit())
+	data, err := os.ReadFile(sidecar)
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(data))
+	data, _ = os.ReadFile(path)
+	assert.JSONEq(t, `{"a": "Un"}`, string(data))
+
+	tx = newTransaction()
+	run(tx)
+	require.NoError(t, tx.Commit())
+	assert.NoFileExists(t, sidecar)
+	data, _ = os.ReadFile(path)
+	assert.JSONEq(t, `{"a": "Une", "b": "Deux"}`, string(data))
+}
+
+// TestBatchProcess tests that batches are sent whole and failed keys are recorded
+func TestBatchProcess(t *testing.T) {
+	source := &parser.LocaleFileContent{
+		Code: "en-US",
+		Lang: "English",
+		LocaleItemsMap: map[string]string{
+			"a": "One",
+			"b": "Two",
+			"c": "Three",
+		},
+	}
+	target := &parser.LocaleFileContent{
+		Path:           "/locales/de-DE.json",
+		Code:           "de-DE",
+		Lang:           "Deutsch",
+		LocaleItemsMap: map[string]string{"a": "Eins"},
+	}
+
+	translator := &fakeTranslator{
+		translate: mockTranslate("Deutsch", nil),
+		batch:     mockBatchTranslate("Deutsch", map[string]string{"Two": "Zwei", "Three": ""}),
+	}
+	fsys := memFS{}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: fsys}
+	assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, 5, opts))
+
+	assert.Equal(t, []string{"Two", "Three"}, translator.sent)
+	assert.Equal(t, "Zwei", target.LocaleItemsMap["b"])
+	assert.Equal(t, "", target.LocaleItemsMap["c"])
+	assert.Equal(t, "c", fsys.fileNamed("failed_keys_de-DE.json.txt"))
+	assert.Contains(t, fsys.fileNamed("translation_errors_2024-03-01.log"), "2024/03/01 12:30:00 Key: c\n")
+}
+
+// TestBudgetAbort tests that an exhausted error budget stops the file, keeping the
+// translations so far and leaving the rest missing for the next run
+func TestBudgetAbort(t *testing.T) {
+	source := &parser.LocaleFileContent{
+		Code:           "en-US",
+		Lang:           "English",
+		LocaleItemsMap: map[string]string{"a": "One", "b": "Two", "c": "Three"},
+	}
+	target := &parser.LocaleFileContent{
+		Path:           "/locales/de-DE.json",
+		Code:           "de-DE",
+		Lang:           "Deutsch",
+		LocaleItemsMap: map[string]string{},
+	}
+
+	translator := &fakeTranslator{translate: func(ctx context.Context, src, lang string) (string, error) {
+		if src == "One" {
+			return "Eins", nil
+		}
+		return "", fmt.Errorf("%w: 2 of the last 2 provider calls failed", gpt.ErrBudgetExhausted)
+	}}
+	fsys := memFS{}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: fsys}
+	err := single_process(context.Background(), translator, source, target, nil, opts)
+	assert.True(t, errors.Is(err, gpt.ErrBudgetExhausted))
+
+	assert.Equal(t, []string{"One", "Two"}, translator.sent)
+	written := &parser.LocaleFileContent{}
+	assert.NoError(t, written.ParseJSON(fsys[target.Path]))
+	assert.Equal(t, map[string]string{"a": "Eins"}, written.LocaleItemsMap)
 }
 
 // TestMissingMode tests that the missing mode only translates missing keys
@@ -280,10 +494,15 @@ func TestFullMode(t *testing.T) {
 	assert.Equal(t, "", empty.LocaleItemsMap["farewell"])
 
 	// In full mode, empty strings should be translated
-	// We can't directly test the result of single_process without a GPT mock,
-	// but we can verify our test data is set up correctly
 	assert.NotEqual(t, "", source.LocaleItemsMap["greeting"])
 	assert.NotEqual(t, "", source.LocaleItemsMap["farewell"])
+
+	empty.Path = "/locales/de-DE.json"
+	translator := &fakeTranslator{translate: mockTranslate("Deutsch", map[string]string{"Hello": "Hallo"})}
+	opts := processOptions{Mode: "full", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}}
+	assert.NoError(t, single_process(context.Background(), translator, source, empty, nil, opts))
+	assert.Equal(t, "Hallo", empty.LocaleItemsMap["greeting"])
+	assert.Equal(t, "TRANSLATED:Goodbye", empty.LocaleItemsMap["farewell"])
 }
 
 // TestIndependentFile tests that the independent file values override translations
@@ -300,4 +519,165 @@ func TestIndependentFile(t *testing.T) {
 	// Verify the independent file has the expected values
 	assert.Equal(t, "Custom Greeting", indep.LocaleItemsMap["greeting"])
 	assert.Equal(t, "Custom Thanks", indep.LocaleItemsMap["nested/thanks"])
+
+	// Independent values replace existing translations without asking the provider
+	source := &parser.LocaleFileContent{
+		Code:           "en-US",
+		Lang:           "English",
+		LocaleItemsMap: map[string]string{"greeting": "Hello", "nested/thanks": "Thank you"},
+	}
+	target := &parser.LocaleFileContent{
+		Path:           "/locales/fr-FR.json",
+		Code:           "fr-FR",
+		Lang:           "français",
+		LocaleItemsMap: map[string]string{"greeting": "Bonjour", "nested/thanks": "Merci"},
+	}
+	translator := &fakeTranslator{translate: mockTranslate("français", nil)}
+	opts := processOptions{Mode: "full", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}}
+	assert.NoError(t, single_process(context.Background(), translator, source, target, indep, opts))
+	assert.Empty(t, translator.sent)
+	assert.Equal(t, "Custom Greeting", target.LocaleItemsMap["greeting"])
+	assert.Equal(t, "Custom Thanks", target.LocaleItemsMap["nested/thanks"])
+}
+
+// TestArrayAlignment tests that array items keep their remembered translation when an
+// item is inserted before them, so that only the new item is translated, in single
+// and batch runs
+func TestArrayAlignment(t *testing.T) {
+	for _, batchSize := range []int{0, 10} {
+		source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"steps": `["Open","Edit","Save"]`}}
+		target := &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "français", LocaleItemsMap: map[string]string{}}
+		arrays := &align.Memory{}
+		arrays.Remember(target.Path, "steps", []string{"Open", "Save"}, []string{"Ouvrir", "Enregistrer"})
+
+		translator := &fakeTranslator{translate: mockTranslate("français", map[string]string{"Edit": "Modifier"})}
+		opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}, Arrays: arrays}
+		if batchSize == 0 {
+			assert.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+		} else {
+			assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, batchSize, opts))
+		}
+		assert.Equal(t, []string{"Edit"}, translator.sent)
+		assert.Equal(t, `["Ouvrir","Modifier","Enregistrer"]`, target.LocaleItemsMap["steps"])
+
+		translations, pending := arrays.Align(target.Path, "steps", []string{"Save", "Edit"})
+		assert.Equal(t, []string{"Enregistrer", "Modifier"}, translations)
+		assert.Empty(t, pending)
+	}
+}
+
+// TestArrayAlignmentFailure tests that a remembered array failing in a batch run is
+// reported, logged and counted once as failed
+func TestArrayAlignmentFailure(t *testing.T) {
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"steps": `["Open","Edit","Save"]`, "title": "Steps"}}
+	target := &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "français", LocaleItemsMap: map[string]string{}}
+	arrays := &align.Memory{}
+	arrays.Remember(target.Path, "steps", []string{"Open", "Save"}, []string{"Ouvrir", "Enregistrer"})
+
+	translator := &fakeTranslator{
+		translate: func(ctx context.Context, src, lang string) (string, error) {
+			return "", errors.New("provider unavailable")
+		},
+		batch: mockBatchTranslate("français", nil),
+	}
+	fsys := memFS{}
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: fsys, Arrays: arrays, Journal: &runRecord{}}
+	assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, 10, opts))
+
+	assert.Empty(t, target.LocaleItemsMap["steps"])
+	assert.Equal(t, []journal.File{{Path: target.Path, Keys: 2, Translated: 1, Failed: []string{"steps"}}}, opts.Journal.run.Files)
+	assert.Contains(t, fsys.fileNamed("translation_errors_2024-03-01.log"), "Key: steps\nSource: [\"Open\",\"Edit\",\"Save\"]\n")
+}
+
+// TestTranslationCache tests that cached translations are reused instead of sent, in
+// single and batch runs, except for keys flagged for retranslation
+func TestTranslationCache(t *testing.T) {
+	for _, batchSize := range []int{0, 10} {
+		translations, err := cache.Open(t.TempDir(), "gpt-4o")
+		assert.NoError(t, err)
+		translations.Put("Hello", "fr", "", "Salut")
+
+		source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{"greeting": "Hello", "farewell": "Goodbye"}}
+		target := &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "français", LocaleItemsMap: map[string]string{}}
+		translator := &fakeTranslator{
+			translate: mockTranslate("français", map[string]string{"Goodbye": "Au revoir"}),
+			batch:     mockBatchTranslate("français", map[string]string{"Goodbye": "Au revoir"}),
+		}
+		opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}, Cache: translations}
+		if batchSize == 0 {
+			assert.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+		} else {
+			assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, batchSize, opts))
+		}
+		assert.Equal(t, []string{"Goodbye"}, translator.sent)
+		assert.Equal(t, "Salut", target.LocaleItemsMap["greeting"])
+		cached, ok := translations.Get("Goodbye", "fr", "")
+		assert.True(t, ok, "new translations are cached")
+		assert.Equal(t, "Au revoir", cached)
+
+		// Keys flagged for retranslation are translated anew
+		target.LocaleItemsMap["greeting"] = marker.DefaultPrefix + "Salut"
+		translator.sent = nil
+		opts.Mode = "full"
+		if batchSize == 0 {
+			assert.NoError(t, single_process(context.Background(), translator, source, target, nil, opts))
+		} else {
+			assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, batchSize, opts))
+		}
+		assert.Equal(t, []string{"Hello"}, translator.sent)
+	}
+}
+
+// TestCacheContext tests that cached translations are only reused for the same key
+// context and instructions
+func TestCacheContext(t *testing.T) {
+	defer func() { registers = nil }()
+	target := &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "français"}
+	opts := processOptions{Keys: keymeta.Rules{"door/state": {Description: "Whether the door is open"}}}
+	assert.Empty(t, opts.cacheContext("dialog/close", "Close", target))
+
+	opts.KeyContext = true
+	assert.NotEqual(t, opts.cacheContext("dialog/close", "Close", target), opts.cacheContext("door/state", "Close", target))
+
+	registers = register.Settings{"fr": "formal"}
+	formal := opts.cacheContext("dialog/close", "Close", target)
+	registers = register.Settings{"fr": "informal"}
+	assert.NotEqual(t, formal, opts.cacheContext("dialog/close", "Close", target))
+}
+
+// TestTokenBatching tests that batches are sent before their texts outgrow the token
+// budget, whatever the batch size
+func TestTokenBatching(t *testing.T) {
+	long := strings.Repeat("A long paragraph of help text. ", 20)
+	source := &parser.LocaleFileContent{Code: "en", Lang: "English", LocaleItemsMap: map[string]string{
+		"a": "Hello", "b": long, "c": "Goodbye", "d": long, "e": "Save",
+	}}
+	target := &parser.LocaleFileContent{Path: "/locales/fr.json", Code: "fr", Lang: "français", LocaleItemsMap: map[string]string{}}
+	translations := map[string]string{"Hello": "Bonjour", "Goodbye": "Au revoir", "Save": "Enregistrer", long: "Un long paragraphe"}
+	sizes := []int{}
+	translator := &fakeTranslator{batch: func(ctx context.Context, srcs []string, lang string) ([]string, error) {
+		sizes = append(sizes, len(srcs))
+		return mockBatchTranslate("français", translations)(ctx, srcs, lang)
+	}}
+
+	opts := processOptions{Mode: "missing", Marker: marker.Default(), Clock: fixedClock{testTime}, FS: memFS{}, BatchTokens: gpt.ItemTokens("b", long) + 10}
+	assert.NoError(t, batch_process(context.Background(), translator, source, target, nil, 10, opts))
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Equal(t, "Un long paragraphe", target.LocaleItemsMap["d"])
+	assert.Equal(t, "Enregistrer", target.LocaleItemsMap["e"])
+}
+
+// TestInstructionsFor tests that the instructions of a language follow its register
+func TestInstructionsFor(t *testing.T) {
+	defer func() { registers, langNotes, geoNames = nil, nil, nil }()
+	registers = register.Settings{"ja": "formal"}
+	langNotes = config.Instructions{"ja": "Write dates as YYYY年M月D日.", "zh": "Put no spaces between Chinese and Latin characters."}
+
+	assert.Equal(t, registers.Instruction("ja")+" Write dates as YYYY年M月D日.", instructionsFor("ja"))
+	assert.Equal(t, "Put no spaces between Chinese and Latin characters.", instructionsFor("zh-TW"))
+	assert.Empty(t, instructionsFor("fr"))
+
+	geoNames = &geo.Settings{Policy: geo.Keep}
+	assert.Equal(t, geoNames.Instruction("fr"), instructionsFor("fr"))
+	assert.Equal(t, geoNames.Instruction("zh")+" Put no spaces between Chinese and Latin characters.", instructionsFor("zh-TW"))
 }
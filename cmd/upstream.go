@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/upstream"
+	"github.com/spf13/cobra"
+)
+
+// maxListedConflicts is the number of conflicts listed per catalog in text output
+const maxListedConflicts = 10
+
+var importUpstreamCmd = &cobra.Command{
+	Use:   "import-upstream",
+	Short: "Merge the translations of an upstream project into the catalogs by key",
+	Long: `Import the translations of an upstream project, such as the open-source app this one
+was forked from, for the keys our catalogs have no translation for, so that strings the
+upstream already localized are not translated again. The upstream directory uses the
+layout of the catalogs. Our translations are always kept: keys translated differently
+upstream are reported as conflicts. Upstream translations of source texts that differ
+from ours, or that lose ICU arguments, are left out. Values written are recorded in the
+change history with the provider "upstream".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		upstreamDir, _ := cmd.Flags().GetString("upstream")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		upstreamSourceLang, _ := cmd.Flags().GetString("upstream-source")
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		format, _ := cmd.Flags().GetString("format")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+		if upstreamSourceLang == "" {
+			upstreamSourceLang = sourceLang
+		}
+		if cfg != nil {
+			applyOutputSettings(cfg)
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			os.Exit(1)
+		}
+		pairs, err := ds.GetPairs()
+		if err != nil {
+			fmt.Printf("❌ Error getting file pairs: %v\n", err)
+			os.Exit(1)
+		}
+		targets := selectTargetLanguages(ds, cfg)
+		if len(langs) > 0 {
+			targets = langs
+		}
+
+		if !dryRun {
+			release, err := acquireProjectLock(rootDir)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer release()
+		}
+
+		// Catalogs are written together once every one is merged
+		tx := newTransaction()
+		opts := processOptions{FS: tx}
+		auditProvider = "upstream"
+		merges := []upstreamMerge{}
+		for _, pair := range pairs {
+			if !containsLanguage(targets, pair.TargetLang) {
+				continue
+			}
+			theirsPath := ds.Layout.Path(upstreamDir, pair.TargetLang, pair.FileType)
+			if _, err := os.Stat(theirsPath); os.IsNotExist(err) {
+				continue
+			}
+			source, target, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			theirs := &parser.LocaleFileContent{Path: theirsPath}
+			if err := theirs.ParseContent(); err != nil {
+				fmt.Printf("❌ Error reading upstream %s: %v\n", theirsPath, err)
+				os.Exit(1)
+			}
+			upstreamSource, err := readUpstreamSource(ds.Layout.Path(upstreamDir, upstreamSourceLang, pair.FileType), format)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+
+			result := upstream.Merge(source.LocaleItemsMap, target.LocaleItemsMap, upstreamSource, theirs.LocaleItemsMap)
+			merges = append(merges, upstreamMerge{File: pair.TargetFile, Upstream: theirsPath, Result: result})
+			if dryRun || len(result.Imported) == 0 {
+				continue
+			}
+			for k, v := range result.Imported {
+				target.LocaleItemsMap[k] = v
+			}
+			if err := opts.writeLocaleFile(target); err != nil {
+				fmt.Printf("❌ Error writing %s: %v\n", target.Path, err)
+				os.Exit(1)
+			}
+		}
+		if !dryRun {
+			if err := commitTransaction(context.Background(), tx); err != nil {
+				os.Exit(1)
+			}
+		}
+
+		if format == "json" {
+			data, err := json.MarshalIndent(merges, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error encoding results: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		if len(merges) == 0 {
+			fmt.Printf("⚠️ No upstream catalog found in %s for the target languages\n", upstreamDir)
+			return
+		}
+		fmt.Print(upstreamReport(merges, dryRun))
+	},
+}
+
+// upstreamMerge is the merge of an upstream catalog into one of ours
+type upstreamMerge struct {
+	File     string `json:"file"`
+	Upstream string `json:"upstream"`
+	upstream.Result
+}
+
+// readUpstreamSource reads the upstream source catalog at path, nil when it doesn't
+// exist, in which case translations are imported by key alone
+func readUpstreamSource(path, format string) (map[string]string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if format != "json" {
+			fmt.Printf("⚠️ No upstream source catalog %s, importing by key without comparing source texts\n", path)
+		}
+		return nil, nil
+	}
+	source := &parser.LocaleFileContent{Path: path}
+	if err := source.ParseContent(); err != nil {
+		return nil, fmt.Errorf("error reading upstream source %s: %w", path, err)
+	}
+	return source.LocaleItemsMap, nil
+}
+
+// upstreamReport returns the counts of each merge, followed by its first conflicts
+func upstreamReport(merges []upstreamMerge, dryRun bool) string {
+	tbl := table.New("File", "Imported", "Conflicts", "Source Changed", "Rejected")
+	imported := 0
+	for _, m := range merges {
+		tbl.Add(m.File, len(m.Imported), len(m.Conflicts), len(m.SourceChanged), len(m.Rejected))
+		imported += len(m.Imported)
+	}
+	report := tbl.String()
+
+	for _, m := range merges {
+		if len(m.Conflicts) == 0 {
+			continue
+		}
+		report += fmt.Sprintf("\n⚠️ %d conflicts in %s, ours kept:\n", len(m.Conflicts), m.File)
+		conflicts := table.New("Key", "Ours", "Upstream")
+		conflicts.MaxWidth = historyCellWidth
+		for i, c := range m.Conflicts {
+			if i == maxListedConflicts {
+				break
+			}
+			conflicts.Add(c.Key, c.Ours, c.Theirs)
+		}
+		report += conflicts.String()
+		if more := len(m.Conflicts) - maxListedConflicts; more > 0 {
+			report += fmt.Sprintf("(%d more, see --format json)\n", more)
+		}
+	}
+
+	if dryRun {
+		return report + fmt.Sprintf("\n🧪 Dry run: %d translations would be imported\n", imported)
+	}
+	return report + fmt.Sprintf("\n✅ Imported %d translations\n", imported)
+}
+
+func init() {
+	importUpstreamCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	importUpstreamCmd.Flags().String("upstream", "", "Directory of the upstream catalogs, laid out like ours")
+	importUpstreamCmd.Flags().String("source", "en", "Source language code (default: en)")
+	importUpstreamCmd.Flags().String("upstream-source", "", "Source language code of the upstream catalogs (default: --source)")
+	importUpstreamCmd.Flags().String("config", "", "Path to configuration file")
+	importUpstreamCmd.Flags().StringSlice("lang", nil, "Languages to import (default: every target language)")
+	importUpstreamCmd.Flags().Bool("dry-run", false, "Report what would be imported without writing the catalogs")
+	importUpstreamCmd.Flags().String("format", "text", "Output format: 'text' or 'json'")
+
+	importUpstreamCmd.MarkFlagRequired("root")
+	importUpstreamCmd.MarkFlagRequired("upstream")
+
+	rootCmd.AddCommand(importUpstreamCmd)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/table"
+)
+
+var maxCost float64 // Estimated cost in USD from which a run stops translating
+
+// printUsageReport prints the tokens spent by the run of gptHandler and their
+// estimated cost, by target language and in total
+func printUsageReport(gptHandler *gpt.Handler) {
+	if report := usageReport(gptHandler.Model(), gptHandler.UsageByLanguage(), gptHandler.Usage()); report != "" {
+		fmt.Print("\n💰 Token usage:\n" + report)
+	}
+}
+
+// printKeyHealth prints the health of the API keys of gptHandler when the run rotated
+// several keys and some of them failed
+func printKeyHealth(gptHandler *gpt.Handler) {
+	if report := keyHealthReport(gptHandler.KeyHealth(), time.Now()); report != "" {
+		fmt.Print("\n🔑 API keys:\n" + report)
+	}
+}
+
+// keyHealthReport returns the table of the calls, failures and benches of each key at
+// now, empty for a single key or when no call failed
+func keyHealthReport(health []gpt.KeyHealth, now time.Time) string {
+	failed := false
+	for _, k := range health {
+		failed = failed || k.Failures > 0
+	}
+	if len(health) < 2 || !failed {
+		return ""
+	}
+	tbl := table.New("Key", "Requests", "Failures", "Rate Limited", "Unauthorized", "Status")
+	for _, k := range health {
+		status := "healthy"
+		if now.Before(k.BenchedUntil) {
+			status = fmt.Sprintf("benched for %s", k.BenchedUntil.Sub(now).Round(time.Second))
+		}
+		tbl.Add(k.Key, k.Requests, k.Failures, k.RateLimited, k.Unauthorized, status)
+	}
+	return tbl.String()
+}
+
+// usageReport returns the table of the usage of model by target language and in total,
+// empty when no request was made
+func usageReport(model string, byLang map[string]gpt.Usage, total gpt.Usage) string {
+	if total.Requests == 0 {
+		return ""
+	}
+	langs := make([]string, 0, len(byLang))
+	for lang := range byLang {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	tbl := table.New("Language", "Requests", "Prompt Tokens", "Completion Tokens", "Cost")
+	for _, lang := range langs {
+		u := byLang[lang]
+		tbl.Add(lang, u.Requests, u.PromptTokens, u.CompletionTokens, formatUsageCost(model, u))
+	}
+	tbl.Add("**Total**", total.Requests, total.PromptTokens, total.CompletionTokens, formatUsageCost(model, total))
+	return tbl.String()
+}
+
+// formatUsageCost returns the estimated cost of u, "unknown" for models without a known price
+func formatUsageCost(model string, u gpt.Usage) string {
+	cost, ok := gpt.Cost(model, u.PromptTokens, u.CompletionTokens)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
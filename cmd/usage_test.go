@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUsageReport tests that the usage report lists the languages in order with their
+// estimated cost, and is empty without requests
+func TestUsageReport(t *testing.T) {
+	byLang := map[string]gpt.Usage{
+		"fr": {Requests: 2, PromptTokens: 1_000_000, CompletionTokens: 100_000},
+		"de": {Requests: 1, PromptTokens: 500_000},
+	}
+	total := gpt.Usage{Requests: 3, PromptTokens: 1_500_000, CompletionTokens: 100_000}
+
+	report := usageReport("gpt-4o-mini", byLang, total)
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	assert.Contains(t, lines[len(lines)-1], "$0.2850")
+	assert.Less(t, strings.Index(report, "de"), strings.Index(report, "fr"))
+	assert.Contains(t, report, "$0.2100")
+	assert.Contains(t, report, "$0.0750")
+
+	assert.Contains(t, usageReport("custom-model", byLang, total), "unknown")
+	assert.Empty(t, usageReport("gpt-4o-mini", nil, gpt.Usage{}))
+}
+
+// TestKeyHealthReport tests that the health of keys is only reported when several keys
+// were rotated and some failed
+func TestKeyHealthReport(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	health := []gpt.KeyHealth{
+		{Key: "sk-...aaaa", Requests: 10, Failures: 2, RateLimited: 2, BenchedUntil: now.Add(30 * time.Second)},
+		{Key: "sk-...bbbb", Requests: 12},
+	}
+	report := keyHealthReport(health, now)
+	assert.Contains(t, report, "benched for 30s")
+	assert.Contains(t, report, "healthy")
+
+	assert.Empty(t, keyHealthReport(health[:1], now))
+	assert.Empty(t, keyHealthReport([]gpt.KeyHealth{{Requests: 1}, {Requests: 2}}, now))
+}
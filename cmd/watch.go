@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// watchSync polls the source language files and the configuration file, syncing
+// again when a source file changes. Changes are debounced: the sync starts once the
+// files have stayed unchanged for the debounce window, and only covers the source
+// files changed since the last sync. A changed configuration file is reloaded and
+// applied to the next sync without restarting; if it fails to load, the previous
+// configuration is kept.
+func watchSync(ctx context.Context, cmd *cobra.Command, rootDir, configPath string, cfg *config.Config, interval time.Duration) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	sources := snapshotSources(rootDir, cfg)
+	configMod := modTime(configPath)
+	pending := newDebouncer(watchDebounce(cmd, cfg))
+
+	fmt.Printf("\n👀 Watching %s for changes (Ctrl+C to stop)\n", rootDir)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+
+		if configPath != "" {
+			if mod := modTime(configPath); !mod.Equal(configMod) {
+				configMod = mod
+				if reloaded, ok := reloadSyncConfig(cmd, configPath, cfg); ok {
+					if reloaded.SourceLang != cfg.SourceLang {
+						sources = nil
+					}
+					cfg = reloaded
+					pending.window = watchDebounce(cmd, cfg)
+					// New settings may affect every file
+					pending.change(now, nil)
+				}
+			}
+		}
+
+		current := snapshotSources(rootDir, cfg)
+		if changed := changedSources(sources, current); len(changed) > 0 {
+			sources = current
+			pending.change(now, changed)
+		}
+
+		if pending.ready(now) {
+			files := pending.take()
+			fmt.Printf("\n🔄 Change detected at %s, syncing\n", now.Format("15:04:05"))
+			runSyncFiles(ctx, rootDir, cfg, files, nil)
+			fmt.Printf("\n👀 Watching %s for changes (Ctrl+C to stop)\n", rootDir)
+		}
+	}
+}
+
+// watchDebounce returns the debounce window of the --debounce flag, or of the config
+// file when the flag isn't set
+func watchDebounce(cmd *cobra.Command, cfg *config.Config) time.Duration {
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	if cmd.Flags().Changed("debounce") {
+		return debounce
+	}
+	return cfg.Watch.DebounceDuration(debounce)
+}
+
+// debouncer coalesces the source files changed in a burst of edits into one sync
+type debouncer struct {
+	window time.Duration
+	// last is when the latest change was seen, zero if nothing is pending
+	last time.Time
+	// files changed since the last sync, nil when every file must be synced
+	files map[string]bool
+	all   bool
+}
+
+func newDebouncer(window time.Duration) *debouncer {
+	return &debouncer{window: window}
+}
+
+// change records changed source files at now; nil files means every file
+func (d *debouncer) change(now time.Time, files []string) {
+	if d.last.IsZero() {
+		d.files = make(map[string]bool)
+		d.all = false
+	}
+	d.last = now
+	if files == nil {
+		d.all = true
+	}
+	for _, file := range files {
+		d.files[file] = true
+	}
+}
+
+// ready reports whether changes are pending and none was seen for the debounce window
+func (d *debouncer) ready(now time.Time) bool {
+	return !d.last.IsZero() && now.Sub(d.last) >= d.window
+}
+
+// take returns the files to sync, nil for all of them, and clears the pending changes
+func (d *debouncer) take() map[string]bool {
+	files := d.files
+	if d.all {
+		files = nil
+	}
+	d.last = time.Time{}
+	d.files = nil
+	d.all = false
+	return files
+}
+
+// reloadSyncConfig loads the changed configuration file and logs what changed. It
+// reports false if nothing changed or the file can't be loaded.
+func reloadSyncConfig(cmd *cobra.Command, configPath string, current *config.Config) (*config.Config, bool) {
+	reloaded, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Printf("❌ Error reloading configuration, keeping the previous one: %v\n", err)
+		return nil, false
+	}
+	applySyncFlags(cmd, reloaded)
+
+	changes := config.Diff(current, reloaded)
+	if len(changes) == 0 {
+		return nil, false
+	}
+
+	fmt.Printf("\n📝 Reloaded configuration from %s:\n", configPath)
+	for _, change := range changes {
+		fmt.Printf("- %s\n", change)
+	}
+	return reloaded, true
+}
+
+// snapshotSources returns the modification time of every source language catalog
+func snapshotSources(rootDir string, cfg *config.Config) map[string]time.Time {
+	snapshot := make(map[string]time.Time)
+	ds, err := scanCatalogs(rootDir, cfg.SourceLang, cfg)
+	if err != nil {
+		return snapshot
+	}
+	for _, fileType := range ds.FileTypes {
+		path := ds.Path(cfg.SourceLang, fileType)
+		if mod := modTime(path); !mod.IsZero() {
+			snapshot[path] = mod
+		}
+	}
+	return snapshot
+}
+
+// changedSources returns the files of snapshot b that are new or modified since a
+func changedSources(a, b map[string]time.Time) []string {
+	changed := []string{}
+	for path, mod := range b {
+		if before, ok := a[path]; !ok || !before.Equal(mod) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// modTime returns the modification time of path, or the zero time if it doesn't exist
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDebouncer tests that a burst of changes gives a single sync of the changed files
+func TestDebouncer(t *testing.T) {
+	start := time.Now()
+	d := newDebouncer(time.Second)
+	assert.False(t, d.ready(start))
+
+	d.change(start, []string{"en/common.json"})
+	d.change(start.Add(500*time.Millisecond), []string{"en/auth.json"})
+	assert.False(t, d.ready(start.Add(time.Second)))
+	assert.True(t, d.ready(start.Add(1500*time.Millisecond)))
+	assert.Equal(t, map[string]bool{"en/common.json": true, "en/auth.json": true}, d.take())
+	assert.False(t, d.ready(start.Add(time.Hour)))
+
+	// A configuration change syncs every file
+	d.change(start, []string{"en/common.json"})
+	d.change(start, nil)
+	assert.True(t, d.ready(start.Add(time.Second)))
+	assert.Nil(t, d.take())
+
+	d.change(start, []string{"en/auth.json"})
+	assert.Equal(t, map[string]bool{"en/auth.json": true}, d.take())
+}
+
+// TestChangedSources tests the detection of new and modified source files
+func TestChangedSources(t *testing.T) {
+	now := time.Now()
+	before := map[string]time.Time{"a.json": now, "b.json": now, "gone.json": now}
+	after := map[string]time.Time{"a.json": now, "b.json": now.Add(time.Second), "c.json": now}
+	assert.Equal(t, []string{"b.json", "c.json"}, changedSources(before, after))
+	assert.Empty(t, changedSources(after, after))
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/scanner"
+	"github.com/pandodao/i18n-cli/internal/table"
+	"github.com/pandodao/i18n-cli/internal/tm"
+	"github.com/pandodao/i18n-cli/internal/wordcount"
+	"github.com/spf13/cobra"
+)
+
+var wordcountCmd = &cobra.Command{
+	Use:         "wordcount",
+	Short:       "Count untranslated words per language",
+	Long:        `Compute per-language word and character counts of untranslated content, with CAT-tool-style weighted counts based on fuzzy matches against existing translations, for human translation vendor quotes.`,
+	Annotations: inspectOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, _ := cmd.Flags().GetString("root")
+		sourceLang, _ := cmd.Flags().GetString("source")
+		extraLangs, _ := cmd.Flags().GetStringSlice("lang")
+		format, _ := cmd.Flags().GetString("format")
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		cfg, err := loadOptionalConfig(cmd)
+		if err != nil {
+			fmt.Printf("❌ Error loading configuration: %v\n", err)
+			return
+		}
+		if cfg != nil && !cmd.Flags().Changed("source") {
+			sourceLang = cfg.SourceLang
+		}
+
+		ds, err := scanCatalogs(rootDir, sourceLang, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error scanning directory: %v\n", err)
+			return
+		}
+
+		targetLanguages := selectTargetLanguages(ds, cfg)
+		for _, lang := range extraLangs {
+			if !containsString(targetLanguages, lang) && lang != ds.SourceLang {
+				targetLanguages = append(targetLanguages, lang)
+			}
+		}
+
+		// Load the source catalogs once
+		sources := make(map[string]map[string]string)
+		for _, fileType := range ds.FileTypes {
+			pair := scanner.FilePair{
+				SourceFile: ds.Path(sourceLang, fileType),
+				SourceLang: sourceLang,
+				FileType:   fileType,
+			}
+			source, _, err := pair.LoadPair()
+			if err != nil {
+				fmt.Printf("❌ Error loading source file %s: %v\n", pair.SourceFile, err)
+				return
+			}
+			sources[fileType] = source.LocaleItemsMap
+		}
+
+		stats := make(map[string]*wordcount.Stats)
+		for _, lang := range targetLanguages {
+			// Existing translations of the language form its translation memory
+			memory := tm.New()
+			targets := make(map[string]map[string]string)
+			for _, fileType := range ds.FileTypes {
+				pair := scanner.FilePair{
+					SourceFile: ds.Path(sourceLang, fileType),
+					TargetFile: ds.Path(lang, fileType),
+					SourceLang: sourceLang,
+					TargetLang: lang,
+					FileType:   fileType,
+				}
+				_, target, err := pair.LoadPair()
+				if err != nil {
+					fmt.Printf("❌ Error loading target file %s: %v\n", pair.TargetFile, err)
+					continue
+				}
+				targets[fileType] = target.LocaleItemsMap
+				memory.AddCatalog(sources[fileType], target.LocaleItemsMap, lang)
+			}
+
+			langStats := wordcount.NewStats()
+			seen := make(map[string]struct{})
+			for _, fileType := range ds.FileTypes {
+				for k, v := range sources[fileType] {
+					if v == "" || targets[fileType][k] != "" {
+						continue
+					}
+
+					score := 0.0
+					if match, ok := memory.Best(v, lang); ok {
+						score = match.Score
+					}
+					_, repeated := seen[v]
+					seen[v] = struct{}{}
+					langStats.Add(v, score, repeated)
+				}
+			}
+			stats[lang] = langStats
+		}
+
+		var report string
+		if format == "csv" {
+			report, err = wordcountCSV(targetLanguages, stats)
+			if err != nil {
+				fmt.Printf("❌ Error writing CSV: %v\n", err)
+				return
+			}
+		} else {
+			report = wordcountTable(sourceLang, targetLanguages, stats)
+		}
+
+		fmt.Println(report)
+
+		if outputPath != "" {
+			if err := os.WriteFile(outputPath, []byte(report), 0644); err != nil {
+				fmt.Printf("❌ Error writing output to file: %v\n", err)
+			} else {
+				fmt.Printf("✅ Word counts saved to %s\n", outputPath)
+			}
+		}
+	},
+}
+
+func wordcountTable(sourceLang string, languages []string, stats map[string]*wordcount.Stats) string {
+	var output strings.Builder
+
+	output.WriteString("# Untranslated Word Counts\n\n")
+	output.WriteString(fmt.Sprintf("Source Language: %s\n\n", sourceLang))
+
+	header := []string{"Language", "Keys", "Words", "Characters", "Repetitions"}
+	for _, band := range wordcount.Bands {
+		header = append(header, band.Name)
+	}
+	tbl := table.New(append(header, "Weighted Words")...)
+
+	for _, lang := range languages {
+		s := stats[lang]
+		row := []interface{}{lang, s.Keys, s.Words, s.Chars, s.Repetitions}
+		for _, words := range s.BandWords {
+			row = append(row, words)
+		}
+		tbl.Add(append(row, fmt.Sprintf("%.0f", s.Weighted()))...)
+	}
+	output.WriteString(tbl.String())
+
+	output.WriteString("\nWeights: ")
+	weights := []string{fmt.Sprintf("%s %.0f%%", wordcount.Repetitions.Name, wordcount.Repetitions.Weight*100)}
+	for _, band := range wordcount.Bands {
+		weights = append(weights, fmt.Sprintf("%s %.0f%%", band.Name, band.Weight*100))
+	}
+	output.WriteString(strings.Join(weights, ", ") + "\n")
+
+	return output.String()
+}
+
+func wordcountCSV(languages []string, stats map[string]*wordcount.Stats) (string, error) {
+	var output strings.Builder
+	w := csv.NewWriter(&output)
+
+	header := []string{"language", "keys", "words", "characters", "repetitions"}
+	for _, band := range wordcount.Bands {
+		header = append(header, band.Name)
+	}
+	header = append(header, "weighted")
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, lang := range languages {
+		s := stats[lang]
+		row := []string{lang, fmt.Sprint(s.Keys), fmt.Sprint(s.Words), fmt.Sprint(s.Chars), fmt.Sprint(s.Repetitions)}
+		for _, words := range s.BandWords {
+			row = append(row, fmt.Sprint(words))
+		}
+		row = append(row, fmt.Sprintf("%.0f", s.Weighted()))
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	return output.String(), w.Error()
+}
+
+func init() {
+	wordcountCmd.Flags().String("root", "", "Root directory containing language subdirectories")
+	wordcountCmd.Flags().String("source", "en", "Source language code (default: en)")
+	wordcountCmd.Flags().String("config", "", "Path to configuration file")
+	wordcountCmd.Flags().StringSlice("lang", []string{}, "Additional languages to count, e.g. languages not added yet")
+	wordcountCmd.Flags().String("format", "table", "Output format: 'table' (markdown) or 'csv'")
+	wordcountCmd.Flags().String("output", "", "Save the counts to a file")
+
+	wordcountCmd.MarkFlagRequired("root")
+
+	rootCmd.AddCommand(wordcountCmd)
+}
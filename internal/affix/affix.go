@@ -0,0 +1,206 @@
+package affix
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// Settings are the rules keeping the prefixes and suffixes of texts out of translation:
+// bullets before a text, and ellipses, colons and units after it, which models often
+// drop. They are stripped before translating and attached again to the translation,
+// rendered as the target language writes them.
+type Settings struct {
+	// Send texts whole, affixes included
+	Disabled bool `json:"disabled,omitempty"`
+	// Units kept out of translation after a number or a placeholder, besides DefaultUnits
+	Units []string `json:"units,omitempty"`
+	// Rendering of suffixes per language code, over DefaultRenderings, e.g.
+	// {"fr": {"MB": "Mo"}}. A rendering starting with a space replaces the spaces before
+	// the suffix too.
+	Languages map[string]map[string]string `json:"languages,omitempty"`
+}
+
+// DefaultUnits are the units kept out of translation after a number or a placeholder
+var DefaultUnits = []string{"%", "KB", "kB", "MB", "GB", "TB", "ms"}
+
+// punctuation is the trailing punctuation kept out of translation, longest first
+var punctuation = []string{"...", "…", ":", "："}
+
+// variants are the ways of writing each trailing punctuation
+var variants = map[string][]string{
+	"...": {"...", "…"},
+	"…":   {"...", "…"},
+	":":   {":", "："},
+	"：":   {":", "："},
+}
+
+// DefaultRenderings are the renderings of suffixes per language: French puts a
+// no-break space before colons and percent signs, Chinese and Japanese write full-width
+// colons and Chinese doubles the ellipsis
+var DefaultRenderings = map[string]map[string]string{
+	"fr": {":": "\u00a0:", "%": "\u00a0%"},
+	"zh": {":": "：", "…": "……", "...": "……"},
+	"ja": {":": "：", "...": "…"},
+}
+
+// prefixPattern matches the leading spaces and bullet of a text
+var prefixPattern = regexp.MustCompile(`^\s*(?:[•·▪‣◦→»*-]\s+)?`)
+
+// Validate checks that units and renderings are not blank
+func (s *Settings) Validate() error {
+	for _, unit := range s.Units {
+		if strings.TrimSpace(unit) == "" {
+			return fmt.Errorf("affixes: blank unit")
+		}
+	}
+	for lang, renderings := range s.Languages {
+		for suffix := range renderings {
+			if strings.TrimSpace(suffix) == "" {
+				return fmt.Errorf("affixes: blank suffix rendered for %s", lang)
+			}
+		}
+	}
+	return nil
+}
+
+// Split returns the prefix, the core to translate and the suffix of text. Texts whose
+// core would have no letter are returned whole as their core, and so are all texts
+// with nil or disabled settings.
+func (s *Settings) Split(text string) (prefix, core, suffix string) {
+	if s == nil || s.Disabled {
+		return "", text, ""
+	}
+	prefix = prefixPattern.FindString(text)
+	rest := text[len(prefix):]
+
+	cut := len(strings.TrimRightFunc(rest, unicode.IsSpace))
+	for _, p := range punctuation {
+		if strings.HasSuffix(rest[:cut], p) {
+			cut -= len(p)
+			break
+		}
+	}
+	cut = len(strings.TrimRightFunc(rest[:cut], unicode.IsSpace))
+	for _, unit := range s.units() {
+		if !strings.HasSuffix(rest[:cut], unit) {
+			continue
+		}
+		// Units only follow numbers and placeholders: "{size} MB", "50%", not "Size in MB"
+		before := strings.TrimRightFunc(rest[:cut-len(unit)], unicode.IsSpace)
+		if before == "" {
+			break
+		}
+		if last := before[len(before)-1]; last == '}' || (last >= '0' && last <= '9') {
+			cut = len(before)
+		}
+		break
+	}
+
+	core, suffix = rest[:cut], rest[cut:]
+	if !hasLetter(core) || (prefix == "" && suffix == "") {
+		return "", text, ""
+	}
+	return prefix, core, suffix
+}
+
+// Join attaches the prefix and suffix split from a text to the translation of its
+// core into lang, rendering the suffix as lang writes it. Punctuation of the suffix
+// the translation ends with anyway isn't doubled.
+func (s *Settings) Join(prefix, translation, suffix, lang string) string {
+	if prefix == "" && suffix == "" {
+		return translation
+	}
+	renderings := s.renderings(lang)
+	t := strings.TrimSpace(translation)
+	for _, p := range punctuation {
+		if !strings.Contains(suffix, p) {
+			continue
+		}
+		for _, end := range append([]string{strings.TrimSpace(renderings[p])}, variants[p]...) {
+			if end != "" {
+				t = strings.TrimRightFunc(strings.TrimSuffix(t, end), unicode.IsSpace)
+			}
+		}
+	}
+	return prefix + t + render(suffix, renderings)
+}
+
+// units returns the units kept out of translation, longest first so that "kB" isn't
+// taken for "B"
+func (s *Settings) units() []string {
+	units := append(append([]string{}, DefaultUnits...), s.Units...)
+	sort.SliceStable(units, func(i, j int) bool { return len(units[i]) > len(units[j]) })
+	return units
+}
+
+// renderings returns the renderings of suffixes in lang: those of its base language,
+// then of lang itself, the configured ones over the default ones
+func (s *Settings) renderings(lang string) map[string]string {
+	codes := []string{}
+	if base, confidence := language.Make(lang).Base(); confidence != language.No && !strings.EqualFold(base.String(), lang) {
+		codes = append(codes, base.String())
+	}
+	codes = append(codes, lang)
+
+	renderings := map[string]string{}
+	for _, sources := range []map[string]map[string]string{DefaultRenderings, s.Languages} {
+		for _, code := range codes {
+			for c, r := range sources {
+				if strings.EqualFold(c, code) {
+					for suffix, rendering := range r {
+						renderings[suffix] = rendering
+					}
+				}
+			}
+		}
+	}
+	return renderings
+}
+
+// render renders the units and punctuation of suffix
+func render(suffix string, renderings map[string]string) string {
+	tokens := make([]string, 0, len(renderings))
+	for token := range renderings {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if len(tokens[i]) != len(tokens[j]) {
+			return len(tokens[i]) > len(tokens[j])
+		}
+		return tokens[i] < tokens[j]
+	})
+
+	rendered := ""
+	for suffix != "" {
+		matched := false
+		for _, token := range tokens {
+			if !strings.HasPrefix(suffix, token) {
+				continue
+			}
+			rendering := renderings[token]
+			if strings.IndexFunc(rendering, unicode.IsSpace) == 0 {
+				rendered = strings.TrimRightFunc(rendered, unicode.IsSpace)
+			}
+			rendered += rendering
+			suffix = suffix[len(token):]
+			matched = true
+			break
+		}
+		if !matched {
+			r := []rune(suffix)[0]
+			rendered += string(r)
+			suffix = suffix[len(string(r)):]
+		}
+	}
+	return rendered
+}
+
+// hasLetter reports whether text has a letter
+func hasLetter(text string) bool {
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
@@ -0,0 +1,61 @@
+package affix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// split returns the parts of text split by s
+func split(s *Settings, text string) []string {
+	prefix, core, suffix := s.Split(text)
+	return []string{prefix, core, suffix}
+}
+
+// TestSplit tests the affixes kept out of translation
+func TestSplit(t *testing.T) {
+	s := &Settings{Units: []string{"fps"}}
+	assert.Equal(t, []string{"", "Loading", "…"}, split(s, "Loading…"))
+	assert.Equal(t, []string{"", "Save as", "..."}, split(s, "Save as..."))
+	assert.Equal(t, []string{"", "Name", ": "}, split(s, "Name: "))
+	assert.Equal(t, []string{"• ", "First step", ""}, split(s, "• First step"))
+	assert.Equal(t, []string{"", "Uploaded {size}", " MB"}, split(s, "Uploaded {size} MB"))
+	assert.Equal(t, []string{"", "Progress 50", "%:"}, split(s, "Progress 50%:"))
+	assert.Equal(t, []string{"", "Running at 60", " fps"}, split(s, "Running at 60 fps"))
+
+	// Units follow numbers and placeholders, and texts without letters are left whole
+	assert.Equal(t, []string{"", "Size in MB", ""}, split(s, "Size in MB"))
+	assert.Equal(t, []string{"", "Remove items", ""}, split(s, "Remove items"))
+	assert.Equal(t, []string{"", "50%", ""}, split(s, "50%"))
+	assert.Equal(t, []string{"", "...", ""}, split(s, "..."))
+	assert.Equal(t, []string{"", "Hello", ""}, split(s, "Hello"))
+
+	assert.Equal(t, []string{"", "Name:", ""}, split(&Settings{Disabled: true}, "Name:"))
+	assert.Equal(t, []string{"", "Name:", ""}, split(nil, "Name:"))
+}
+
+// TestJoin tests that affixes are attached to translations as the target language writes them
+func TestJoin(t *testing.T) {
+	s := &Settings{Languages: map[string]map[string]string{"fr": {"MB": "Mo"}}}
+	assert.Equal(t, "Chargement…", s.Join("", "Chargement", "…", "fr"))
+	assert.Equal(t, "Nom\u00a0:", s.Join("", "Nom", ":", "fr-CA"))
+	assert.Equal(t, "{size} Mo téléversés", s.Join("", "{size} Mo téléversés", "", "fr"))
+	assert.Equal(t, "Téléversé {size} Mo", s.Join("", "Téléversé {size}", " MB", "fr"))
+	assert.Equal(t, "Progression 50\u00a0%", s.Join("", "Progression 50", "%", "fr"))
+	assert.Equal(t, "名前：", s.Join("", "名前", ":", "ja"))
+	assert.Equal(t, "加载中……", s.Join("", "加载中", "…", "zh-CN"))
+	assert.Equal(t, "• Erster Schritt", s.Join("• ", "Erster Schritt", "", "de"))
+
+	// Punctuation the translation kept anyway isn't doubled
+	assert.Equal(t, "Name:", s.Join("", "Name:", ":", "de"))
+	assert.Equal(t, "Nom\u00a0:", s.Join("", "Nom\u00a0:", ":", "fr"))
+	assert.Equal(t, "Laden...", s.Join("", "Laden …", "...", ""))
+
+	// Configured renderings replace the default ones
+	s.Languages["fr"][":"] = ":"
+	assert.Equal(t, "Nom:", s.Join("", "Nom", ":", "fr"))
+
+	assert.Error(t, (&Settings{Units: []string{" "}}).Validate())
+	assert.Error(t, (&Settings{Languages: map[string]map[string]string{"fr": {"": "x"}}}).Validate())
+	assert.NoError(t, s.Validate())
+}
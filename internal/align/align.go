@@ -0,0 +1,98 @@
+package align
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+// stateFile is the state file of the memory of the project
+const stateFile = "arrays.json"
+
+// Item is an item of a translated JSON array value
+type Item struct {
+	// Hash of the source text of the item
+	Hash        string `json:"hash"`
+	Translation string `json:"translation"`
+}
+
+// Memory remembers the translation of each item of the JSON array values of target
+// catalogs by the hash of its source text, so that items keep their translation when
+// items are inserted, removed or reordered around them
+type Memory struct {
+	mu sync.Mutex
+	// Items of the translated array values per target catalog and key
+	Catalogs map[string]map[string][]Item `json:"catalogs"`
+}
+
+// Load reads the memory of the project; a missing memory is empty
+func Load() (*Memory, error) {
+	m := &Memory{}
+	if err := state.Load(stateFile, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save writes the memory of the project. A nil memory is not saved.
+func (m *Memory) Save() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return state.Save(stateFile, m)
+}
+
+// Hash returns the hash items are remembered by
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Align returns the remembered translation of each of the source items of key in
+// catalog, and the indexes of the items without one, which are to be translated. A
+// nil memory remembers nothing.
+func (m *Memory) Align(catalog, key string, sources []string) ([]string, []int) {
+	known := map[string]string{}
+	if m != nil {
+		m.mu.Lock()
+		for _, item := range m.Catalogs[catalog][key] {
+			known[item.Hash] = item.Translation
+		}
+		m.mu.Unlock()
+	}
+	translations := make([]string, len(sources))
+	pending := []int{}
+	for i, source := range sources {
+		if t, ok := known[Hash(source)]; ok && t != "" {
+			translations[i] = t
+			continue
+		}
+		pending = append(pending, i)
+	}
+	return translations, pending
+}
+
+// Remember records the translations of the source items of key in catalog, replacing
+// the items remembered before
+func (m *Memory) Remember(catalog, key string, sources, translations []string) {
+	if m == nil || len(sources) != len(translations) {
+		return
+	}
+	items := make([]Item, len(sources))
+	for i, source := range sources {
+		items[i] = Item{Hash: Hash(source), Translation: translations[i]}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Catalogs == nil {
+		m.Catalogs = map[string]map[string][]Item{}
+	}
+	if m.Catalogs[catalog] == nil {
+		m.Catalogs[catalog] = map[string][]Item{}
+	}
+	m.Catalogs[catalog][key] = items
+}
@@ -0,0 +1,46 @@
+package align
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAlign tests that remembered items keep their translation wherever they move and
+// that only new items are pending
+func TestAlign(t *testing.T) {
+	m := &Memory{}
+	m.Remember("fr.json", "steps", []string{"Open", "Save", "Close"}, []string{"Ouvrir", "Enregistrer", "Fermer"})
+
+	translations, pending := m.Align("fr.json", "steps", []string{"Open", "Edit", "Save", "Close"})
+	assert.Equal(t, []string{"Ouvrir", "", "Enregistrer", "Fermer"}, translations)
+	assert.Equal(t, []int{1}, pending)
+
+	translations, pending = m.Align("fr.json", "steps", []string{"Close", "Open"})
+	assert.Equal(t, []string{"Fermer", "Ouvrir"}, translations)
+	assert.Empty(t, pending)
+
+	_, pending = m.Align("de.json", "steps", []string{"Open"})
+	assert.Equal(t, []int{0}, pending, "memories are per catalog")
+
+	var none *Memory
+	_, pending = none.Align("fr.json", "steps", []string{"Open", "Save"})
+	assert.Equal(t, []int{0, 1}, pending)
+	none.Remember("fr.json", "steps", []string{"Open"}, []string{"Ouvrir"})
+	assert.NoError(t, none.Save())
+}
+
+// TestSaveLoad tests that the memory is kept in the state of the project
+func TestSaveLoad(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m, err := Load()
+	assert.NoError(t, err)
+	m.Remember("fr.json", "steps", []string{"Open"}, []string{"Ouvrir"})
+	assert.NoError(t, m.Save())
+
+	loaded, err := Load()
+	assert.NoError(t, err)
+	translations, pending := loaded.Align("fr.json", "steps", []string{"Open"})
+	assert.Equal(t, []string{"Ouvrir"}, translations)
+	assert.Empty(t, pending)
+}
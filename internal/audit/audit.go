@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DirName is the directory, next to each catalog, holding the change logs of its files
+const DirName = ".history"
+
+// Entry is one change of a key value
+type Entry struct {
+	Time     time.Time `json:"time"`
+	RunID    string    `json:"runId"`
+	User     string    `json:"user,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Catalog  string    `json:"catalog"`
+	Key      string    `json:"key"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+	Removed  bool      `json:"removed,omitempty"`
+}
+
+// NewRunID returns an identifier for the changes made by one invocation
+func NewRunID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// CurrentUser returns the name of the user running the tool
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// LogPath returns the change log of a catalog file
+func LogPath(catalog string) string {
+	return filepath.Join(filepath.Dir(catalog), DirName, filepath.Base(catalog)+".jsonl")
+}
+
+// Changes returns an entry, without run details, for every key whose value
+// differs between old and new, sorted by key
+func Changes(catalog string, old, new map[string]string) []Entry {
+	entries := []Entry{}
+	for k, v := range new {
+		if prev, ok := old[k]; !ok || prev != v {
+			entries = append(entries, Entry{Catalog: catalog, Key: k, Old: prev, New: v})
+		}
+	}
+	for k, prev := range old {
+		if _, ok := new[k]; !ok {
+			entries = append(entries, Entry{Catalog: catalog, Key: k, Old: prev, Removed: true})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Append adds entries to the change log of their catalog. Existing entries are never rewritten.
+func Append(catalog string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	data, err := Marshal(entries)
+	if err != nil {
+		return err
+	}
+	path := LogPath(catalog)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	return err
+}
+
+// Marshal renders entries as change log lines, one JSON object per line
+func Marshal(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Load reads a change log, oldest entry first. A missing log has no entries.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Find returns the entries of key in every change log under root, oldest first
+func Find(root, key string) ([]Entry, error) {
+	found := []Entry{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Base(filepath.Dir(path)) != DirName || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+
+		entries, err := Load(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Key == key {
+				found = append(found, e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Time.Before(found[j].Time)
+	})
+	return found, nil
+}
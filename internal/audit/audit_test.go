@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChanges tests computing the changed keys of a catalog
+func TestChanges(t *testing.T) {
+	old := map[string]string{"a": "Bonjour", "b": "Salut", "c": "Au revoir"}
+	updated := map[string]string{"a": "Bonjour", "b": "Coucou", "d": "Merci"}
+
+	assert.Equal(t, []Entry{
+		{Catalog: "fr.json", Key: "b", Old: "Salut", New: "Coucou"},
+		{Catalog: "fr.json", Key: "c", Old: "Au revoir", Removed: true},
+		{Catalog: "fr.json", Key: "d", New: "Merci"},
+	}, Changes("fr.json", old, updated))
+}
+
+// TestAppendFind tests appending to change logs and querying a key
+func TestAppendFind(t *testing.T) {
+	root := t.TempDir()
+	fr := filepath.Join(root, "fr", "common.json")
+	de := filepath.Join(root, "de", "common.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assert.NoError(t, Append(fr, []Entry{{Time: now, RunID: "1", Catalog: fr, Key: "greeting", New: "Salut"}}))
+	assert.NoError(t, Append(de, []Entry{{Time: now.Add(time.Minute), RunID: "1", Catalog: de, Key: "greeting", New: "Hallo"}}))
+	assert.NoError(t, Append(fr, []Entry{{Time: now.Add(2 * time.Minute), RunID: "2", Catalog: fr, Key: "greeting", Old: "Salut", New: "Bonjour"}}))
+	assert.NoError(t, Append(fr, []Entry{{Time: now, RunID: "1", Catalog: fr, Key: "other", New: "x"}}))
+
+	entries, err := Find(root, "greeting")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []string{"Salut", "Hallo", "Bonjour"}, []string{entries[0].New, entries[1].New, entries[2].New})
+	assert.Equal(t, filepath.Join(root, "fr", DirName, "common.json.jsonl"), LogPath(fr))
+}
@@ -0,0 +1,164 @@
+package benchmark
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// N-gram orders of the scores: characters up to 6 for chrF, words up to 4 for BLEU
+const (
+	chrOrder  = 6
+	bleuOrder = 4
+	// chrF weighs recall twice as much as precision
+	chrBeta = 2
+)
+
+// Corpus accumulates the n-gram statistics of machine translations and their reference
+// translations, so that scores are computed over a whole catalog rather than averaged
+// per text
+type Corpus struct {
+	Segments int
+
+	chrMatches, chrHyp, chrRef [chrOrder]int
+	bleuMatches, bleuTotal     [bleuOrder]int
+	hypWords, refWords         int
+	exact                      int
+}
+
+// Add adds a translation and its reference translation to the corpus
+func (c *Corpus) Add(translation, reference string) {
+	c.Segments++
+	if translation == reference {
+		c.exact++
+	}
+
+	hyp, ref := chars(translation), chars(reference)
+	for n := 1; n <= chrOrder; n++ {
+		m, h, r := overlap(ngrams(hyp, n), ngrams(ref, n))
+		c.chrMatches[n-1] += m
+		c.chrHyp[n-1] += h
+		c.chrRef[n-1] += r
+	}
+
+	hyp, ref = words(translation), words(reference)
+	c.hypWords += len(hyp)
+	c.refWords += len(ref)
+	for n := 1; n <= bleuOrder; n++ {
+		m, h, _ := overlap(ngrams(hyp, n), ngrams(ref, n))
+		c.bleuMatches[n-1] += m
+		c.bleuTotal[n-1] += h
+	}
+}
+
+// Exact returns the share of translations identical to their reference, from 0 to 1
+func (c *Corpus) Exact() float64 {
+	if c.Segments == 0 {
+		return 0
+	}
+	return float64(c.exact) / float64(c.Segments)
+}
+
+// ChrF returns the character n-gram F-score of the corpus from 0 to 100. Whitespace is
+// ignored and orders longer than the texts are left out, so it suits short UI strings
+// and languages written without spaces.
+func (c *Corpus) ChrF() float64 {
+	precision, recall, orders := 0.0, 0.0, 0
+	for n := 0; n < chrOrder; n++ {
+		if c.chrHyp[n] == 0 || c.chrRef[n] == 0 {
+			continue
+		}
+		precision += float64(c.chrMatches[n]) / float64(c.chrHyp[n])
+		recall += float64(c.chrMatches[n]) / float64(c.chrRef[n])
+		orders++
+	}
+	if orders == 0 {
+		if c.Segments > 0 && c.exact == c.Segments {
+			return 100
+		}
+		return 0
+	}
+	precision /= float64(orders)
+	recall /= float64(orders)
+	if precision+recall == 0 {
+		return 0
+	}
+	b2 := float64(chrBeta * chrBeta)
+	return 100 * (1 + b2) * precision * recall / (b2*precision + recall)
+}
+
+// BLEU returns the word n-gram BLEU score of the corpus from 0 to 100, with add-one
+// smoothing of the orders above unigrams so that short texts don't score 0 outright
+func (c *Corpus) BLEU() float64 {
+	if c.hypWords == 0 || c.bleuMatches[0] == 0 {
+		return 0
+	}
+	logSum := 0.0
+	for n := 0; n < bleuOrder; n++ {
+		matches, total := float64(c.bleuMatches[n]), float64(c.bleuTotal[n])
+		if n > 0 {
+			matches++
+			total++
+		}
+		logSum += math.Log(matches / total)
+	}
+	brevity := 1.0
+	if c.hypWords < c.refWords {
+		brevity = math.Exp(1 - float64(c.refWords)/float64(c.hypWords))
+	}
+	return 100 * brevity * math.Exp(logSum/bleuOrder)
+}
+
+// ChrF returns the character n-gram F-score of a single translation from 0 to 100
+func ChrF(translation, reference string) float64 {
+	var c Corpus
+	c.Add(translation, reference)
+	return c.ChrF()
+}
+
+// chars returns the characters of text, without whitespace
+func chars(text string) []string {
+	out := []string{}
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			out = append(out, string(r))
+		}
+	}
+	return out
+}
+
+// wordPattern matches a word, a punctuation mark or symbol, or a single character of
+// the scripts written without spaces between words
+var wordPattern = regexp.MustCompile(`[\p{Han}\p{Hiragana}\p{Katakana}\p{Thai}]|[^\s\p{Han}\p{Hiragana}\p{Katakana}\p{Thai}\pP\pS]+|[\pP\pS]`)
+
+// words returns the tokens of text
+func words(text string) []string {
+	return wordPattern.FindAllString(text, -1)
+}
+
+// ngrams counts the n-grams of tokens
+func ngrams(tokens []string, n int) map[string]int {
+	counts := map[string]int{}
+	for i := 0; i+n <= len(tokens); i++ {
+		counts[strings.Join(tokens[i:i+n], "\x00")]++
+	}
+	return counts
+}
+
+// overlap returns the clipped matches of the n-grams of a translation in those of its
+// reference, and the number of n-grams of both
+func overlap(hyp, ref map[string]int) (matches, hypTotal, refTotal int) {
+	for g, n := range hyp {
+		hypTotal += n
+		if r := ref[g]; r < n {
+			matches += r
+		} else {
+			matches += n
+		}
+	}
+	for _, n := range ref {
+		refTotal += n
+	}
+	return matches, hypTotal, refTotal
+}
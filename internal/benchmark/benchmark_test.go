@@ -0,0 +1,38 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScores tests that identical translations score 100 and unrelated ones close to 0
+func TestScores(t *testing.T) {
+	var same Corpus
+	same.Add("Le chat est sur le tapis", "Le chat est sur le tapis")
+	same.Add("OK", "OK")
+	assert.InDelta(t, 100, same.ChrF(), 0.001)
+	assert.InDelta(t, 100, same.BLEU(), 0.001)
+	assert.Equal(t, 1.0, same.Exact())
+
+	var unrelated Corpus
+	unrelated.Add("Bonjour", "Xyz")
+	assert.Equal(t, 0.0, unrelated.ChrF())
+	assert.Equal(t, 0.0, unrelated.BLEU())
+
+	near := ChrF("Le chat est sur le tapis", "Le chat est assis sur le tapis")
+	assert.Greater(t, near, 60.0)
+	assert.Less(t, near, 100.0)
+	assert.Greater(t, near, ChrF("Un chien dort", "Le chat est assis sur le tapis"))
+
+	var empty Corpus
+	assert.Equal(t, 0.0, empty.ChrF())
+	assert.Equal(t, 0.0, empty.Exact())
+}
+
+// TestWords tests that scripts without spaces are split into characters
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"Save", "changes", "?"}, words("Save changes?"))
+	assert.Equal(t, []string{"変", "更", "を", "保", "存"}, words("変更を保存"))
+	assert.Equal(t, []string{"저장", "하기"}, words("저장 하기"))
+}
@@ -0,0 +1,90 @@
+package blocked
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const stateFile = "blocked.json"
+
+// Entry is a key the provider refused to translate for content policy reasons
+type Entry struct {
+	File string `json:"file"`
+	Key  string `json:"key"`
+	// Language code of the catalog
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+	// Provider and model that refused the key, and the reason they gave
+	Provider  string    `json:"provider,omitempty"`
+	Reason    string    `json:"reason"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
+
+// Store holds the blocked keys by file, then key
+type Store map[string]map[string]Entry
+
+// Load returns the blocked keys of the project
+func Load() (Store, error) {
+	s := Store{}
+	if err := state.Load(stateFile, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the blocked keys of the project
+func (s Store) Save() error {
+	return state.Save(stateFile, s)
+}
+
+// Add records e, replacing the entry of the same key
+func (s Store) Add(e Entry) {
+	if s[e.File] == nil {
+		s[e.File] = map[string]Entry{}
+	}
+	s[e.File][e.Key] = e
+}
+
+// Keys returns the blocked keys of file
+func (s Store) Keys(file string) map[string]bool {
+	keys := make(map[string]bool, len(s[file]))
+	for key := range s[file] {
+		keys[key] = true
+	}
+	return keys
+}
+
+// Resolve removes the keys of file that have a translation in translations, by the
+// provider on a retry or by hand, and returns how many there were
+func (s Store) Resolve(file string, translations map[string]string) int {
+	resolved := 0
+	for key := range s[file] {
+		if translations[key] != "" {
+			delete(s[file], key)
+			resolved++
+		}
+	}
+	if len(s[file]) == 0 {
+		delete(s, file)
+	}
+	return resolved
+}
+
+// Entries returns the blocked keys sorted by file, then key
+func (s Store) Entries() []Entry {
+	entries := []Entry{}
+	for _, keys := range s {
+		for _, e := range keys {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
@@ -0,0 +1,29 @@
+package blocked
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStore tests that blocked keys are forgotten once they have a translation
+func TestStore(t *testing.T) {
+	s := Store{}
+	s.Add(Entry{File: "fr/common.json", Key: "title", Reason: "violence"})
+	s.Add(Entry{File: "fr/common.json", Key: "body", Reason: "violence"})
+	s.Add(Entry{File: "de/common.json", Key: "title", Reason: "hate"})
+	s.Add(Entry{File: "fr/common.json", Key: "title", Reason: "self-harm"})
+
+	assert.Equal(t, map[string]bool{"title": true, "body": true}, s.Keys("fr/common.json"))
+	assert.Empty(t, s.Keys("es/common.json"))
+	entries := s.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "de/common.json", entries[0].File)
+	assert.Equal(t, "body", entries[1].Key)
+	assert.Equal(t, "self-harm", entries[2].Reason)
+
+	assert.Equal(t, 1, s.Resolve("fr/common.json", map[string]string{"title": "Titre", "body": ""}))
+	assert.Equal(t, map[string]bool{"body": true}, s.Keys("fr/common.json"))
+	assert.Equal(t, 1, s.Resolve("fr/common.json", map[string]string{"body": "Corps"}))
+	assert.NotContains(t, s, "fr/common.json")
+}
@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirName is the directory of the translation caches in the cache directory
+const DirName = "translations"
+
+// Entry is a cached translation
+type Entry struct {
+	Lang        string    `json:"lang"`
+	Translation string    `json:"translation"`
+	Time        time.Time `json:"time"`
+}
+
+// file is the cache of the translations of one model
+type file struct {
+	Model   string           `json:"model"`
+	Entries map[string]Entry `json:"entries"` // hash of language, source text and context -> entry
+}
+
+// Cache keeps the translations of a model on disk, keyed by source text, target
+// language and the context the text was translated with, so that no text is paid for
+// twice, across runs and projects
+type Cache struct {
+	mu     sync.Mutex
+	path   string
+	file   file
+	dirty  bool
+	hits   int
+	misses int
+	now    func() time.Time
+}
+
+// Open reads the cache of model in dir. A missing or corrupt cache is empty.
+func Open(dir, model string) (*Cache, error) {
+	c := &Cache{
+		path: filepath.Join(dir, DirName, fileName(model)),
+		file: file{Model: model, Entries: map[string]Entry{}},
+		now:  time.Now,
+	}
+	f, err := read(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.Model == model {
+		c.file = f
+	}
+	return c, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName returns the name of the cache file of model
+func fileName(model string) string {
+	return unsafeChars.ReplaceAllString(model, "_") + ".json"
+}
+
+// read reads a cache file; a corrupt one is empty rather than an error
+func read(path string) (file, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file{}, err
+	}
+	f := file{}
+	if err := json.Unmarshal(data, &f); err != nil || f.Entries == nil {
+		return file{Entries: map[string]Entry{}}, nil
+	}
+	return f, nil
+}
+
+// write writes f to path, creating its directory if needed
+func write(path string, f file) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// key returns the key of the translation of text into lang with context, the other
+// inputs of its prompt such as its key and instructions
+func key(text, lang, context string) string {
+	data := strings.ToLower(lang) + "\x00" + text
+	if context != "" {
+		data += "\x00" + context
+	}
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached translation of text into lang with context. A nil cache has
+// none.
+func (c *Cache) Get(text, lang, context string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.file.Entries[key(text, lang, context)]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return e.Translation, true
+}
+
+// Put caches the translation of text into lang with context. A nil cache caches nothing.
+func (c *Cache) Put(text, lang, context, translation string) {
+	if c == nil || translation == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.Entries[key(text, lang, context)] = Entry{Lang: lang, Translation: translation, Time: c.now().UTC()}
+	c.dirty = true
+}
+
+// Hits returns how many translations were served from the cache, and how many were not
+func (c *Cache) Hits() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Save writes the cache if translations were added. Entries added by other runs in
+// the meantime are kept.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	if f, err := read(c.path); err == nil && f.Model == c.file.Model {
+		for k, e := range f.Entries {
+			if _, ok := c.file.Entries[k]; !ok {
+				c.file.Entries[k] = e
+			}
+		}
+	}
+	if err := write(c.path, c.file); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// Stat describes the cache of a model
+type Stat struct {
+	Model string
+	// Cached translations per language
+	Languages map[string]int
+	Entries   int
+	Bytes     int64
+	// Times of the oldest and newest translations cached
+	Oldest time.Time
+	Newest time.Time
+}
+
+// Stats returns the caches of dir, sorted by model
+func Stats(dir string) ([]Stat, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, DirName, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	stats := []Stat{}
+	for _, path := range paths {
+		f, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		s := Stat{Model: f.Model, Languages: map[string]int{}, Entries: len(f.Entries), Bytes: info.Size()}
+		for _, e := range f.Entries {
+			s.Languages[e.Lang]++
+			if s.Oldest.IsZero() || e.Time.Before(s.Oldest) {
+				s.Oldest = e.Time
+			}
+			if e.Time.After(s.Newest) {
+				s.Newest = e.Time
+			}
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Model < stats[j].Model })
+	return stats, nil
+}
+
+// Clear removes the cached translations of dir, only those of model and of lang when
+// set, and returns how many were removed. Caches left empty are deleted.
+func Clear(dir, model, lang string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, DirName, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range paths {
+		f, err := read(path)
+		if err != nil {
+			return removed, err
+		}
+		if model != "" && f.Model != model {
+			continue
+		}
+		for k, e := range f.Entries {
+			if lang == "" || strings.EqualFold(e.Lang, lang) {
+				delete(f.Entries, k)
+				removed++
+			}
+		}
+		if len(f.Entries) == 0 {
+			err = os.Remove(path)
+		} else {
+			err = write(path, f)
+		}
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCache tests that translations are kept per model and language across runs
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, "gpt-4o")
+	assert.NoError(t, err)
+	_, ok := c.Get("Hello", "fr", "")
+	assert.False(t, ok)
+	c.Put("Hello", "fr", "", "Bonjour")
+	c.Put("Hello", "de", "", "Hallo")
+	assert.NoError(t, c.Save())
+
+	other, err := Open(dir, "gpt-4o")
+	assert.NoError(t, err)
+	translation, ok := other.Get("Hello", "FR", "")
+	assert.True(t, ok)
+	assert.Equal(t, "Bonjour", translation)
+	hits, misses := other.Hits()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 0, misses)
+
+	local, err := Open(dir, "llama3.1:8b")
+	assert.NoError(t, err)
+	_, ok = local.Get("Hello", "fr", "")
+	assert.False(t, ok, "caches are per model")
+	local.Put("Hello", "fr", "", "Salut")
+	assert.NoError(t, local.Save())
+
+	// Saving keeps the translations other runs added meanwhile
+	c.Put("Goodbye", "fr", "", "Au revoir")
+	other.Put("Thanks", "fr", "", "Merci")
+	assert.NoError(t, other.Save())
+	assert.NoError(t, c.Save())
+	reopened, err := Open(dir, "gpt-4o")
+	assert.NoError(t, err)
+	_, ok = reopened.Get("Thanks", "fr", "")
+	assert.True(t, ok)
+
+	// Translations with another context are kept apart
+	reopened.Put("Close", "fr", "key: dialog/close", "Fermer")
+	_, ok = reopened.Get("Close", "fr", "")
+	assert.False(t, ok)
+	_, ok = reopened.Get("Close", "fr", "key: status/close")
+	assert.False(t, ok)
+	translation, ok = reopened.Get("Close", "fr", "key: dialog/close")
+	assert.True(t, ok)
+	assert.Equal(t, "Fermer", translation)
+
+	var none *Cache
+	none.Put("Hello", "fr", "", "Bonjour")
+	_, ok = none.Get("Hello", "fr", "")
+	assert.False(t, ok)
+	assert.NoError(t, none.Save())
+}
+
+// TestStatsClear tests the statistics of the caches and clearing them by model and language
+func TestStatsClear(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, model := range []string{"gpt-4o", "gemini-2.0-flash"} {
+		c, err := Open(dir, model)
+		assert.NoError(t, err)
+		c.now = func() time.Time { return now }
+		c.Put("Hello", "fr", "", "Bonjour")
+		c.Put("Hello", "de", "", "Hallo")
+		c.Put("Goodbye", "de", "", "Tschüss")
+		assert.NoError(t, c.Save())
+	}
+
+	stats, err := Stats(dir)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "gemini-2.0-flash", stats[0].Model)
+	assert.Equal(t, 3, stats[1].Entries)
+	assert.Equal(t, map[string]int{"fr": 1, "de": 2}, stats[1].Languages)
+	assert.Equal(t, now, stats[1].Oldest)
+	assert.Positive(t, stats[1].Bytes)
+
+	removed, err := Clear(dir, "gpt-4o", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	removed, err = Clear(dir, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, removed)
+	stats, err = Stats(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// secretFields are reported as changed without printing their values
+var secretFields = map[string]bool{"apiKey": true, "apiKeys": true}
+
+// Diff summarizes the settings that differ between two configurations, one
+// line per changed field, e.g. "targetLangs: +de -fr" or "mode: missing -> full"
+func Diff(old, new *Config) []string {
+	before := toFields(old)
+	after := toFields(new)
+
+	names := map[string]struct{}{}
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	changes := []string{}
+	for _, name := range sorted {
+		a, b := before[name], after[name]
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+
+		if secretFields[name] {
+			changes = append(changes, fmt.Sprintf("%s: changed", name))
+			continue
+		}
+
+		if added, removed, ok := listDiff(a, b); ok {
+			parts := []string{}
+			for _, v := range added {
+				parts = append(parts, "+"+v)
+			}
+			for _, v := range removed {
+				parts = append(parts, "-"+v)
+			}
+			changes = append(changes, fmt.Sprintf("%s: %s", name, strings.Join(parts, " ")))
+			continue
+		}
+
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, describe(a), describe(b)))
+	}
+	return changes
+}
+
+// toFields returns the JSON fields of a configuration by name
+func toFields(c *Config) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if c == nil {
+		return fields
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// listDiff returns the values added to and removed from a list of strings
+func listDiff(a, b interface{}) (added, removed []string, ok bool) {
+	before, okA := toStrings(a)
+	after, okB := toStrings(b)
+	if !okA || !okB {
+		return nil, nil, false
+	}
+
+	inBefore := map[string]bool{}
+	for _, v := range before {
+		inBefore[v] = true
+	}
+	inAfter := map[string]bool{}
+	for _, v := range after {
+		inAfter[v] = true
+		if !inBefore[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range before {
+		if !inAfter[v] {
+			removed = append(removed, v)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		// Same values in a different order
+		return nil, nil, false
+	}
+	return added, removed, true
+}
+
+// toStrings converts a decoded JSON list of strings, treating a missing field as empty
+func toStrings(v interface{}) ([]string, bool) {
+	if v == nil {
+		return []string{}, true
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, s)
+	}
+	return result, true
+}
+
+// describe renders a decoded JSON value for a diff line
+func describe(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiff tests the summary of changed settings logged when a config is reloaded
+func TestDiff(t *testing.T) {
+	old := DefaultConfig()
+	old.TargetLangs = []string{"fr", "de"}
+	old.APIKey = "sk-old"
+
+	updated := DefaultConfig()
+	updated.TargetLangs = []string{"de", "ja"}
+	updated.APIKey = "sk-new"
+	updated.Mode = "full"
+
+	assert.Equal(t, []string{
+		"apiKey: changed",
+		`mode: "missing" -> "full"`,
+		"targetLangs: +ja -fr",
+	}, Diff(old, updated))
+
+	assert.Empty(t, Diff(old, old))
+}
@@ -0,0 +1,27 @@
+package config
+
+import "strings"
+
+// Instructions holds extra prompt instructions per target language code, such as
+// spacing or date format rules, appended to the system prompt of that language only
+type Instructions map[string]string
+
+// For returns the instructions of lang: those of its base language (zh) first, then
+// those of lang itself (zh-TW), empty when none are set
+func (i Instructions) For(lang string) string {
+	parts := []string{}
+	base := strings.FieldsFunc(lang, func(r rune) bool { return r == '-' || r == '_' })
+	if len(base) > 1 {
+		for code, text := range i {
+			if strings.EqualFold(code, base[0]) && strings.TrimSpace(text) != "" {
+				parts = append(parts, strings.TrimSpace(text))
+			}
+		}
+	}
+	for code, text := range i {
+		if strings.EqualFold(code, lang) && strings.TrimSpace(text) != "" {
+			parts = append(parts, strings.TrimSpace(text))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstructionsFor tests that regional variants get the instructions of their base
+// language before their own
+func TestInstructionsFor(t *testing.T) {
+	i := Instructions{
+		"zh":    "Put no spaces between Chinese and Latin characters.",
+		"zh-TW": "Use Traditional Chinese punctuation.",
+		"de":    "  ",
+	}
+	assert.Equal(t, "Put no spaces between Chinese and Latin characters.", i.For("zh"))
+	assert.Equal(t, "Put no spaces between Chinese and Latin characters. Use Traditional Chinese punctuation.", i.For("zh-tw"))
+	assert.Equal(t, "Put no spaces between Chinese and Latin characters.", i.For("zh_CN"))
+	assert.Empty(t, i.For("de"))
+	assert.Empty(t, i.For("fr"))
+	assert.Empty(t, Instructions(nil).For("fr"))
+}
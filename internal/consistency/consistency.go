@@ -0,0 +1,94 @@
+package consistency
+
+import (
+	"sort"
+	"strings"
+)
+
+// Item is a translated key of a catalog
+type Item struct {
+	File   string `json:"file"`
+	Key    string `json:"key"`
+	Source string `json:"-"`
+	Target string `json:"-"`
+	// Reviewed is set when the translation was not written by a provider: written or
+	// edited by hand, or harmonized
+	Reviewed bool `json:"reviewed,omitempty"`
+}
+
+// Variant is one of the translations of a source string
+type Variant struct {
+	Target string `json:"translation"`
+	Items  []Item `json:"keys"`
+	// Number of items whose translation was reviewed
+	Reviewed int `json:"reviewed"`
+}
+
+// Group is a source string translated differently by the keys of a language
+type Group struct {
+	Source string `json:"source"`
+	// Variants, the most reviewed first; the first one is the one to harmonize to
+	Variants []Variant `json:"variants"`
+}
+
+// Preferred returns the variant to harmonize the group to
+func (g Group) Preferred() Variant {
+	return g.Variants[0]
+}
+
+// Find returns the source strings of items, all from catalogs of the same language,
+// that received different translations. Variants are ranked by reviewed keys, then
+// by keys, so that the translation people settled on wins. Groups are sorted by source.
+func Find(items []Item) []Group {
+	bySource := map[string]map[string]*Variant{}
+	for _, item := range items {
+		if strings.TrimSpace(item.Source) == "" || strings.TrimSpace(item.Target) == "" {
+			continue
+		}
+		variants, ok := bySource[item.Source]
+		if !ok {
+			variants = map[string]*Variant{}
+			bySource[item.Source] = variants
+		}
+		v, ok := variants[item.Target]
+		if !ok {
+			v = &Variant{Target: item.Target}
+			variants[item.Target] = v
+		}
+		v.Items = append(v.Items, item)
+		if item.Reviewed {
+			v.Reviewed++
+		}
+	}
+
+	groups := []Group{}
+	for source, variants := range bySource {
+		if len(variants) < 2 {
+			continue
+		}
+		g := Group{Source: source}
+		for _, v := range variants {
+			sort.Slice(v.Items, func(i, j int) bool {
+				if v.Items[i].File != v.Items[j].File {
+					return v.Items[i].File < v.Items[j].File
+				}
+				return v.Items[i].Key < v.Items[j].Key
+			})
+			g.Variants = append(g.Variants, *v)
+		}
+		sort.Slice(g.Variants, func(i, j int) bool {
+			a, b := g.Variants[i], g.Variants[j]
+			if a.Reviewed != b.Reviewed {
+				return a.Reviewed > b.Reviewed
+			}
+			if len(a.Items) != len(b.Items) {
+				return len(a.Items) > len(b.Items)
+			}
+			return a.Target < b.Target
+		})
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Source < groups[j].Source })
+	return groups
+}
@@ -0,0 +1,34 @@
+package consistency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFind tests that diverging translations are grouped and ranked by review, then use
+func TestFind(t *testing.T) {
+	items := []Item{
+		{File: "fr/common.json", Key: "save", Source: "Save", Target: "Enregistrer"},
+		{File: "fr/settings.json", Key: "save", Source: "Save", Target: "Sauvegarder"},
+		{File: "fr/editor.json", Key: "actions/save", Source: "Save", Target: "Sauvegarder"},
+		{File: "fr/auth.json", Key: "submit", Source: "Save", Target: "Enregistrer", Reviewed: true},
+		{File: "fr/common.json", Key: "cancel", Source: "Cancel", Target: "Annuler"},
+		{File: "fr/auth.json", Key: "cancel", Source: "Cancel", Target: "Annuler"},
+		{File: "fr/auth.json", Key: "empty", Source: "Cancel", Target: ""},
+	}
+
+	groups := Find(items)
+	assert.Len(t, groups, 1)
+	g := groups[0]
+	assert.Equal(t, "Save", g.Source)
+	assert.Len(t, g.Variants, 2)
+	assert.Equal(t, "Enregistrer", g.Preferred().Target)
+	assert.Equal(t, 1, g.Preferred().Reviewed)
+	assert.Equal(t, "fr/auth.json", g.Preferred().Items[0].File)
+
+	// Without reviews, the most used translation wins
+	preferred := Find(items[:3])[0].Preferred()
+	assert.Equal(t, "Sauvegarder", preferred.Target)
+	assert.Equal(t, []Item{items[2], items[1]}, preferred.Items)
+}
@@ -0,0 +1,132 @@
+package dnt
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// List is what must never be translated
+type List struct {
+	// Terms kept verbatim wherever they appear as whole words, e.g. brand names
+	Terms []string `json:"terms,omitempty"`
+	// Regular expressions of tokens kept verbatim, e.g. SKU codes like `SKU-\d+`
+	Patterns []string `json:"patterns,omitempty"`
+	// Key patterns (path.Match syntax, e.g. "legal/*") whose values are copied untranslated
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Protector hides the protected tokens of texts from providers and puts them back in
+// translations. The nil Protector protects nothing.
+type Protector struct {
+	patterns []*regexp.Regexp
+	keys     []string
+}
+
+// Compile returns the protector of a list
+func Compile(l List) (*Protector, error) {
+	p := &Protector{keys: l.Keys}
+	terms := append([]string{}, l.Terms...)
+	// Longer terms first, so that "Acme Cloud" wins over "Acme"
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	for _, term := range terms {
+		if strings.TrimSpace(term) == "" {
+			return nil, fmt.Errorf("empty do-not-translate term")
+		}
+		p.patterns = append(p.patterns, regexp.MustCompile(`(?:^|\b)`+regexp.QuoteMeta(term)+`(?:\b|$)`))
+	}
+	for _, pattern := range l.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid do-not-translate pattern %q: %w", pattern, err)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	for _, key := range l.Keys {
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("invalid do-not-translate key pattern %q: %w", key, err)
+		}
+	}
+	return p, nil
+}
+
+// Key reports whether the value of key must be copied untranslated
+func (p *Protector) Key(key string) bool {
+	if p == nil {
+		return false
+	}
+	for _, pattern := range p.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholder stands for the i-th protected token of a text. Braces are kept
+// unchanged by providers, as they mark variables.
+func placeholder(i int) string {
+	return fmt.Sprintf("{DNT_%d}", i)
+}
+
+// tokens returns the protected tokens of text in order, as [start, end] offsets
+func (p *Protector) tokens(text string) [][]int {
+	if p == nil {
+		return nil
+	}
+	found := [][]int{}
+	for _, re := range p.patterns {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			if m[0] == m[1] {
+				continue
+			}
+			overlaps := false
+			for _, f := range found {
+				overlaps = overlaps || m[0] < f[1] && f[0] < m[1]
+			}
+			if !overlaps {
+				found = append(found, m)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i][0] < found[j][0] })
+	return found
+}
+
+// Protect returns text with its protected tokens replaced by placeholders
+func (p *Protector) Protect(text string) string {
+	tokens := p.tokens(text)
+	if len(tokens) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for i, t := range tokens {
+		b.WriteString(text[last:t[0]])
+		b.WriteString(placeholder(i))
+		last = t[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Restore puts the protected tokens of source back in the translation of its
+// protected text, and verifies that none of them was lost: a token is either still
+// behind its placeholder or was written verbatim.
+func (p *Protector) Restore(source, translation string) (string, error) {
+	tokens := p.tokens(source)
+	for i, t := range tokens {
+		token := source[t[0]:t[1]]
+		if strings.Contains(translation, placeholder(i)) {
+			translation = strings.ReplaceAll(translation, placeholder(i), token)
+		} else if !strings.Contains(translation, token) {
+			return "", fmt.Errorf("protected term %q was lost in translation", token)
+		}
+	}
+	if strings.Contains(translation, "{DNT_") {
+		return "", fmt.Errorf("translation has placeholders of protected terms left: %q", translation)
+	}
+	return translation, nil
+}
@@ -0,0 +1,50 @@
+package dnt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProtect tests that terms and patterns are hidden behind placeholders and put back
+func TestProtect(t *testing.T) {
+	p, err := Compile(List{Terms: []string{"Acme", "Acme Cloud"}, Patterns: []string{`SKU-\d+`}})
+	assert.NoError(t, err)
+
+	masked := p.Protect("Try Acme Cloud with SKU-1234, by Acme. Acmeish stays.")
+	assert.Equal(t, "Try {DNT_0} with {DNT_1}, by {DNT_2}. Acmeish stays.", masked)
+
+	restored, err := p.Restore("Try Acme Cloud with SKU-1234, by Acme.", "Essayez {DNT_0} avec {DNT_1}, par {DNT_2}.")
+	assert.NoError(t, err)
+	assert.Equal(t, "Essayez Acme Cloud avec SKU-1234, par Acme.", restored)
+
+	// Tokens written verbatim are accepted, lost ones are not
+	restored, err = p.Restore("Buy SKU-1234", "Achetez SKU-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "Achetez SKU-1234", restored)
+	_, err = p.Restore("Buy SKU-1234", "Achetez")
+	assert.EqualError(t, err, `protected term "SKU-1234" was lost in translation`)
+	_, err = p.Restore("Buy", "Achetez {DNT_3}")
+	assert.Error(t, err)
+
+	var none *Protector
+	assert.Equal(t, "Buy Acme", none.Protect("Buy Acme"))
+	assert.False(t, none.Key("legal/terms"))
+}
+
+// TestKey tests key patterns and the validation of lists
+func TestKey(t *testing.T) {
+	p, err := Compile(List{Keys: []string{"legal/*", "brand"}})
+	assert.NoError(t, err)
+	assert.True(t, p.Key("legal/terms"))
+	assert.True(t, p.Key("brand"))
+	assert.False(t, p.Key("legal/terms/title"))
+	assert.False(t, p.Key("home/title"))
+
+	_, err = Compile(List{Patterns: []string{"("}})
+	assert.Error(t, err)
+	_, err = Compile(List{Keys: []string{"["}})
+	assert.Error(t, err)
+	_, err = Compile(List{Terms: []string{" "}})
+	assert.Error(t, err)
+}
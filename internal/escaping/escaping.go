@@ -0,0 +1,389 @@
+package escaping
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Framework is a frontend i18n framework whose message syntax translations must keep
+// valid, "" for none
+type Framework string
+
+const (
+	// I18next interpolates {{name}} and nests $t(key, {"option": value}), whose options
+	// are parsed as JSON
+	I18next Framework = "i18next"
+	// Angular interpolates {{expression}} and nests ICU messages in braces; a literal
+	// brace is written {{ '{' }}
+	Angular Framework = "angular"
+	// Vue (vue-i18n) interpolates {name}, links messages with @:key and separates plural
+	// forms with |; literal braces, @ and | are written {'@'}
+	Vue Framework = "vue"
+)
+
+// Frameworks are the supported frameworks
+var Frameworks = []Framework{I18next, Angular, Vue}
+
+// Parse returns the framework named name, ignoring case, "" for an empty name
+func Parse(name string) (Framework, error) {
+	if name == "" {
+		return "", nil
+	}
+	names := make([]string, len(Frameworks))
+	for i, f := range Frameworks {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown framework %q (expected one of %s)", name, strings.Join(names, ", "))
+}
+
+// Error is a translation breaking the message syntax of a framework
+type Error struct {
+	Framework Framework
+	Problem   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("breaks the %s message syntax: %s", e.Framework, e.Problem)
+}
+
+// rule is a mistake against the message syntax of a framework
+type rule struct {
+	// find returns the occurrences of the mistake in text, a translation of source
+	find func(source, text string) []string
+	// fix returns text without the mistake, nil when it can't be fixed
+	fix func(source, text string) string
+}
+
+var rules = map[Framework][]rule{
+	I18next: {
+		{find: i18nextInterpolation},
+		{find: i18nextNesting, fix: fixI18nextNesting},
+	},
+	Angular: {
+		{find: angularExpressions, fix: fixAngularExpressions},
+		{find: angularBraces, fix: fixAngularBraces},
+	},
+	Vue: {
+		{find: vueMustaches, fix: fixVueMustaches},
+		{find: vueBraces, fix: fixVueBraces},
+		{find: vueLinks, fix: fixVueLinks},
+		{find: vuePipes, fix: fixVuePipes},
+	},
+}
+
+// Check returns an *Error when translation makes a mistake against the message syntax
+// of f that source doesn't make
+func (f Framework) Check(source, translation string) error {
+	for _, r := range rules[f] {
+		if len(r.find(source, source)) > 0 {
+			continue
+		}
+		if found := r.find(source, translation); len(found) > 0 {
+			return &Error{Framework: f, Problem: found[0]}
+		}
+	}
+	return nil
+}
+
+// Fix returns translation with the mistakes against the message syntax of f fixed
+// where they can be: literal characters escaped, mustaches and expressions of the
+// source restored, nesting options requoted. Mistakes source makes too are left alone.
+func (f Framework) Fix(source, translation string) string {
+	for _, r := range rules[f] {
+		if r.fix == nil || len(r.find(source, source)) > 0 || len(r.find(source, translation)) == 0 {
+			continue
+		}
+		translation = r.fix(source, translation)
+	}
+	return translation
+}
+
+// Strip returns text without the escaped literals of f, so that placeholder checks
+// don't read them as arguments
+func (f Framework) Strip(text string) string {
+	switch f {
+	case Angular:
+		return angularLiteral.ReplaceAllString(text, "")
+	case Vue:
+		return vueLiteral.ReplaceAllString(text, "")
+	}
+	return text
+}
+
+// i18nextInterpolation finds {{ not closed and }} not opened
+func i18nextInterpolation(_, text string) []string {
+	found := []string{}
+	open := false
+	for i := 0; i+1 < len(text); i++ {
+		switch text[i : i+2] {
+		case "{{":
+			if open {
+				found = append(found, "{{ opened inside another {{")
+			}
+			open = true
+			i++
+		case "}}":
+			if !open {
+				found = append(found, "}} without an opening {{")
+			}
+			open = false
+			i++
+		}
+	}
+	if open {
+		found = append(found, "{{ not closed")
+	}
+	return found
+}
+
+var (
+	i18nextNestingPattern = regexp.MustCompile(`\$t\(([^(),]*),([^()]*)\)`)
+	i18nextInterpolated   = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+)
+
+// validOptions reports whether nesting options are JSON once interpolated
+func validOptions(options string) bool {
+	return json.Valid([]byte(i18nextInterpolated.ReplaceAllString(options, "0")))
+}
+
+// i18nextNesting finds nesting options, $t(key, {...}), that aren't valid JSON
+func i18nextNesting(_, text string) []string {
+	found := []string{}
+	for _, m := range i18nextNestingPattern.FindAllStringSubmatch(text, -1) {
+		if !validOptions(m[2]) {
+			found = append(found, fmt.Sprintf("the options of $t(%s) must be JSON with double quotes", strings.TrimSpace(m[1])))
+		}
+	}
+	return found
+}
+
+// quotes are the quotes models write instead of the double quotes of JSON
+var quotes = strings.NewReplacer("'", `"`, "‘", `"`, "’", `"`, "“", `"`, "”", `"`, "„", `"`, "«", `"`, "»", `"`)
+
+// fixI18nextNesting writes the quotes of nesting options as double quotes
+func fixI18nextNesting(_, text string) string {
+	return i18nextNestingPattern.ReplaceAllStringFunc(text, func(nesting string) string {
+		m := i18nextNestingPattern.FindStringSubmatch(nesting)
+		options := quotes.Replace(m[2])
+		if !validOptions(options) {
+			return nesting
+		}
+		return "$t(" + m[1] + "," + options + ")"
+	})
+}
+
+var (
+	angularLiteral    = regexp.MustCompile(`\{\{\s*'[{}]'\s*\}\}`)
+	angularExpression = regexp.MustCompile(`\{\{(.*?)\}\}`)
+)
+
+// expressions returns the interpolated expressions of text, escaped literals left out
+func expressions(text string) []string {
+	found := []string{}
+	for _, m := range angularExpression.FindAllStringSubmatch(angularLiteral.ReplaceAllString(text, ""), -1) {
+		found = append(found, strings.TrimSpace(m[1]))
+	}
+	return found
+}
+
+// angularExpressions finds interpolated expressions the source doesn't have, which
+// translated property names break at runtime
+func angularExpressions(source, text string) []string {
+	known := map[string]bool{}
+	for _, e := range expressions(source) {
+		known[e] = true
+	}
+	found := []string{}
+	for _, e := range expressions(text) {
+		if !known[e] {
+			found = append(found, fmt.Sprintf("{{%s}} is not an expression of the source", e))
+		}
+	}
+	return found
+}
+
+// fixAngularExpressions restores the expressions of the source in order, when the
+// translation has as many
+func fixAngularExpressions(source, text string) string {
+	want := expressions(source)
+	if len(expressions(text)) != len(want) {
+		return text
+	}
+	known := map[string]bool{}
+	for _, e := range want {
+		known[e] = true
+	}
+	i := 0
+	return outside(text, angularLiteral, func(part string) string {
+		return angularExpression.ReplaceAllStringFunc(part, func(m string) string {
+			e := want[i]
+			i++
+			if known[strings.TrimSpace(m[2:len(m)-2])] {
+				return m
+			}
+			return "{{" + e + "}}"
+		})
+	})
+}
+
+// angularBraces finds braces without a counterpart, which the template compiler rejects
+func angularBraces(_, text string) []string {
+	return strayBraces(text, angularLiteral, "{{ '%c' }}")
+}
+
+// fixAngularBraces escapes the braces without a counterpart
+func fixAngularBraces(_, text string) string {
+	return escapeBraces(text, angularLiteral, "{{ '%c' }}")
+}
+
+var (
+	vueLiteral  = regexp.MustCompile(`\{'[^']*'\}`)
+	vueMustache = regexp.MustCompile(`\{\{\s*(\w+(?:\.\w+)*)\s*\}\}`)
+	vueLink     = regexp.MustCompile(`^@(?:\.\w+)?:`)
+)
+
+// vueMustaches finds {{name}} interpolations, which vue-i18n writes {name}
+func vueMustaches(_, text string) []string {
+	found := []string{}
+	for _, m := range vueMustache.FindAllStringSubmatch(vueLiteral.ReplaceAllString(text, ""), -1) {
+		found = append(found, fmt.Sprintf("%s is not vue-i18n interpolation, write {%s}", m[0], m[1]))
+	}
+	return found
+}
+
+// fixVueMustaches writes {{name}} interpolations {name}
+func fixVueMustaches(_, text string) string {
+	return outside(text, vueLiteral, func(part string) string {
+		return vueMustache.ReplaceAllString(part, "{$1}")
+	})
+}
+
+// vueBraces finds braces without a counterpart
+func vueBraces(_, text string) []string {
+	return strayBraces(text, vueLiteral, "{'%c'}")
+}
+
+// fixVueBraces escapes the braces without a counterpart
+func fixVueBraces(_, text string) string {
+	return escapeBraces(text, vueLiteral, "{'%c'}")
+}
+
+// links returns the indexes of the @ of part that start no linked message
+func links(part string) []int {
+	found := []int{}
+	for i := strings.IndexByte(part, '@'); i >= 0; {
+		if !vueLink.MatchString(part[i:]) {
+			found = append(found, i)
+		}
+		next := strings.IndexByte(part[i+1:], '@')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return found
+}
+
+// vueLinks finds @ starting no linked message, such as those of email addresses
+func vueLinks(_, text string) []string {
+	found := []string{}
+	for range links(vueLiteral.ReplaceAllString(text, "")) {
+		found = append(found, "@ starts a linked message, write {'@'} for a literal @")
+	}
+	return found
+}
+
+// fixVueLinks escapes the @ starting no linked message
+func fixVueLinks(_, text string) string {
+	return outside(text, vueLiteral, func(part string) string {
+		return escapeAt(part, links(part), "{'%c'}")
+	})
+}
+
+// vuePipes finds a translation with another number of plural forms than its source
+func vuePipes(source, text string) []string {
+	want := strings.Count(vueLiteral.ReplaceAllString(source, ""), "|")
+	if got := strings.Count(vueLiteral.ReplaceAllString(text, ""), "|"); got != want {
+		return []string{fmt.Sprintf("| separates plural forms, the translation has %d where the source has %d, write {'|'} for a literal |", got, want)}
+	}
+	return nil
+}
+
+// fixVuePipes escapes the | of translations of sources without plural forms
+func fixVuePipes(source, text string) string {
+	if strings.Contains(vueLiteral.ReplaceAllString(source, ""), "|") {
+		return text
+	}
+	return outside(text, vueLiteral, func(part string) string {
+		return strings.ReplaceAll(part, "|", "{'|'}")
+	})
+}
+
+// unmatched returns the indexes of the braces of text without a counterpart
+func unmatched(text string) []int {
+	open, stray := []int{}, []int{}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			open = append(open, i)
+		case '}':
+			if len(open) == 0 {
+				stray = append(stray, i)
+			} else {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	stray = append(stray, open...)
+	sort.Ints(stray)
+	return stray
+}
+
+// strayBraces describes the braces of text, literals left out, without a counterpart
+func strayBraces(text string, literal *regexp.Regexp, escape string) []string {
+	found := []string{}
+	stripped := literal.ReplaceAllString(text, "")
+	for _, i := range unmatched(stripped) {
+		found = append(found, fmt.Sprintf("unmatched %c, write "+escape+" for a literal brace", stripped[i], stripped[i]))
+	}
+	return found
+}
+
+// escapeBraces escapes the braces of text without a counterpart, leaving literals alone
+func escapeBraces(text string, literal *regexp.Regexp, escape string) string {
+	return outside(text, literal, func(part string) string {
+		return escapeAt(part, unmatched(part), escape)
+	})
+}
+
+// escapeAt writes the characters of text at indexes, in order, with escape
+func escapeAt(text string, indexes []int, escape string) string {
+	var b strings.Builder
+	last := 0
+	for _, i := range indexes {
+		b.WriteString(text[last:i])
+		fmt.Fprintf(&b, escape, text[i])
+		last = i + 1
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// outside returns text with fn applied to its parts between the matches of literal
+func outside(text string, literal *regexp.Regexp, fn func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range literal.FindAllStringIndex(text, -1) {
+		b.WriteString(fn(text[last:loc[0]]))
+		b.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(fn(text[last:]))
+	return b.String()
+}
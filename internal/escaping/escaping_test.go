@@ -0,0 +1,76 @@
+package escaping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests reading framework names
+func TestParse(t *testing.T) {
+	f, err := Parse("Vue")
+	assert.NoError(t, err)
+	assert.Equal(t, Vue, f)
+	f, err = Parse("")
+	assert.NoError(t, err)
+	assert.Equal(t, Framework(""), f)
+	_, err = Parse("ember")
+	assert.EqualError(t, err, `unknown framework "ember" (expected one of i18next, angular, vue)`)
+}
+
+// TestI18next tests interpolation and nesting options of i18next
+func TestI18next(t *testing.T) {
+	source := `You have $t(items, {"count": {{count}}}) in {{place}}`
+	assert.NoError(t, I18next.Check(source, `Vous avez $t(items, {"count": {{count}}}) dans {{place}}`))
+	assert.EqualError(t, I18next.Check(source, `Vous avez $t(items, {"count": {{count}}}) dans {{place}`),
+		"breaks the i18next message syntax: {{ not closed")
+
+	broken := `Vous avez $t(items, {'count': {{count}}}) dans {{place}}`
+	assert.EqualError(t, I18next.Check(source, broken), "breaks the i18next message syntax: the options of $t(items) must be JSON with double quotes")
+	assert.Equal(t, `Vous avez $t(items, {"count": {{count}}}) dans {{place}}`, I18next.Fix(source, broken))
+
+	assert.Equal(t, `$t(a, {"x": 1})`, I18next.Fix(`$t(a, {"x": 1})`, `$t(a, {“x”: 1})`))
+}
+
+// TestAngular tests interpolated expressions and literal braces of Angular
+func TestAngular(t *testing.T) {
+	source := "Hello {{ user.name }}, {count, plural, =1 {one message} other {# messages}}"
+	assert.NoError(t, Angular.Check(source, "Hallo {{ user.name }}, {count, plural, =1 {eine Nachricht} other {# Nachrichten}}"))
+
+	translated := "Hallo {{ benutzer.name }}, {count, plural, =1 {eine Nachricht} other {# Nachrichten}}"
+	assert.EqualError(t, Angular.Check(source, translated), "breaks the angular message syntax: {{benutzer.name}} is not an expression of the source")
+	assert.Equal(t, "Hallo {{user.name}}, {count, plural, =1 {eine Nachricht} other {# Nachrichten}}", Angular.Fix(source, translated))
+
+	stray := "Code: {{ code }} (siehe Abschnitt }"
+	assert.EqualError(t, Angular.Check("Code: {{ code }} (see section)", stray), "breaks the angular message syntax: unmatched }, write {{ '}' }} for a literal brace")
+	fixed := Angular.Fix("Code: {{ code }} (see section)", stray)
+	assert.Equal(t, "Code: {{ code }} (siehe Abschnitt {{ '}' }}", fixed)
+	assert.NoError(t, Angular.Check("Code: {{ code }} (see section)", fixed))
+	assert.Equal(t, "Code: {{ code }} (siehe Abschnitt ", Angular.Strip(fixed))
+}
+
+// TestVue tests literals, links, plural forms and interpolation of vue-i18n
+func TestVue(t *testing.T) {
+	source := "Write to {name} at support{'@'}example.com"
+	assert.NoError(t, Vue.Check(source, "Schreiben Sie {name} an support{'@'}example.com"))
+
+	translated := "Schreiben Sie {{name}} an support@example.com"
+	assert.EqualError(t, Vue.Check(source, translated), "breaks the vue message syntax: {{name}} is not vue-i18n interpolation, write {name}")
+	assert.Equal(t, "Schreiben Sie {name} an support{'@'}example.com", Vue.Fix(source, translated))
+
+	// Linked messages and plural forms of the source are kept
+	assert.NoError(t, Vue.Check("@:brand | @.lower:brand apps", "@:brand | @.lower:brand Apps"))
+	assert.EqualError(t, Vue.Check("no apple | one apple | {count} apples", "keine Äpfel | ein Apfel"),
+		"breaks the vue message syntax: | separates plural forms, the translation has 1 where the source has 2, write {'|'} for a literal |")
+	assert.Equal(t, "Größe{'|'}Farbe", Vue.Fix("Size or color", "Größe|Farbe"))
+	assert.Equal(t, "Antwort {'{'}beta", Vue.Fix("Answer (beta)", "Antwort {beta"))
+
+	// Mistakes the source makes too aren't reported
+	assert.NoError(t, Vue.Check("Mail us@example.com", "Mail uns@example.com"))
+	assert.Equal(t, "Mail uns@example.com", Vue.Fix("Mail us@example.com", "Mail uns@example.com"))
+	assert.Equal(t, "Hallo {name}", Vue.Strip("Hallo {name}{'!'}"))
+
+	// Without a framework, nothing is checked
+	assert.NoError(t, Framework("").Check(source, translated))
+	assert.Equal(t, translated, Framework("").Fix(source, translated))
+}
@@ -0,0 +1,127 @@
+package forecast
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+)
+
+// DefaultExpansion is the usual ratio of translated to source tokens
+const DefaultExpansion = 1.2
+
+// Options controls how a forecast is computed
+type Options struct {
+	Model string
+	// Number of texts per request, 0 means one request per text
+	BatchSize int
+	// Estimated tokens the texts of a request may take, as counted by gpt.ItemTokens;
+	// requests are only bounded by BatchSize when 0
+	BatchTokens int
+	// Ratio of output tokens to input tokens for the target language
+	Expansion  float64
+	Throughput Throughput
+}
+
+// Forecast is the estimated effort of translating a catalog into one language
+type Forecast struct {
+	Keys             int
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	CostKnown        bool
+	Duration         time.Duration
+}
+
+// Estimate forecasts the requests, tokens, cost, and time needed to translate texts
+func Estimate(texts []string, opts Options) Forecast {
+	f := Forecast{}
+	batch := opts.BatchSize > 0
+	overhead := gpt.PromptOverhead(batch)
+
+	pending, pendingTokens := 0, 0
+	for _, text := range texts {
+		if len(text) == 0 {
+			continue
+		}
+		f.Keys++
+
+		if !batch {
+			// single mode translates JSON arrays one item at a time
+			for _, item := range splitArray(text) {
+				tokens := gpt.CountTokens(item)
+				f.Requests++
+				f.PromptTokens += overhead + tokens
+				f.CompletionTokens += int(float64(tokens) * opts.Expansion)
+			}
+			continue
+		}
+
+		tokens := gpt.CountTokens(text)
+		f.PromptTokens += tokens
+		f.CompletionTokens += int(float64(tokens) * opts.Expansion)
+		itemTokens := gpt.ItemTokens("", text)
+		if opts.BatchTokens > 0 && pending > 0 && pendingTokens+itemTokens > opts.BatchTokens {
+			f.Requests++
+			pending, pendingTokens = 0, 0
+		}
+		pending++
+		pendingTokens += itemTokens
+		if pending == opts.BatchSize {
+			f.Requests++
+			pending, pendingTokens = 0, 0
+		}
+	}
+
+	if batch {
+		if pending > 0 {
+			f.Requests++
+		}
+		f.PromptTokens += f.Requests * overhead
+	}
+
+	f.Cost, f.CostKnown = gpt.Cost(opts.Model, f.PromptTokens, f.CompletionTokens)
+	f.Duration = time.Duration(f.Requests) * opts.Throughput.RequestLatency
+
+	return f
+}
+
+func splitArray(text string) []string {
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		var items []string
+		if err := json.Unmarshal([]byte(text), &items); err == nil {
+			return items
+		}
+	}
+	return []string{text}
+}
+
+// Pending returns the source texts a run will translate into target: keys missing from
+// target and, in full mode, keys whose translation is empty or flagged for retranslation
+func Pending(source, target map[string]string, marked map[string]struct{}, full bool) []string {
+	texts := []string{}
+	for _, k := range PendingKeys(source, target, marked, full) {
+		texts = append(texts, source[k])
+	}
+	return texts
+}
+
+// PendingKeys returns the sorted keys whose source texts Pending returns
+func PendingKeys(source, target map[string]string, marked map[string]struct{}, full bool) []string {
+	keys := []string{}
+	for k, v := range source {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		translated, exists := target[k]
+		_, isMarked := marked[k]
+		if !exists || full && (translated == "" || isMarked) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
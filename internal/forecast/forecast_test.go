@@ -0,0 +1,67 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEstimateBatching tests that batching shares the prompt overhead across texts
+func TestEstimateBatching(t *testing.T) {
+	texts := []string{"Hello", "Goodbye", "", "Save changes"}
+	throughput := Throughput{RequestLatency: time.Second}
+
+	single := Estimate(texts, Options{Model: gpt.DefaultModel, Expansion: 1, Throughput: throughput})
+	assert.Equal(t, 3, single.Keys)
+	assert.Equal(t, 3, single.Requests)
+	assert.Equal(t, 3*time.Second, single.Duration)
+	assert.True(t, single.CostKnown)
+
+	batch := Estimate(texts, Options{Model: gpt.DefaultModel, BatchSize: 2, Expansion: 1, Throughput: throughput})
+	assert.Equal(t, 3, batch.Keys)
+	assert.Equal(t, 2, batch.Requests)
+	assert.Equal(t, single.CompletionTokens, batch.CompletionTokens)
+
+	many := make([]string, 40)
+	for i := range many {
+		many[i] = "Save changes"
+	}
+	single = Estimate(many, Options{Model: gpt.DefaultModel, Expansion: 1, Throughput: throughput})
+	batch = Estimate(many, Options{Model: gpt.DefaultModel, BatchSize: 20, Expansion: 1, Throughput: throughput})
+	assert.Equal(t, 2, batch.Requests)
+	assert.Less(t, batch.PromptTokens, single.PromptTokens)
+
+	// Requests are also bounded by the tokens of their texts
+	batch = Estimate(many, Options{Model: gpt.DefaultModel, BatchSize: 20, BatchTokens: 5 * gpt.ItemTokens("", "Save changes"), Expansion: 1, Throughput: throughput})
+	assert.Equal(t, 8, batch.Requests)
+}
+
+// TestMeasureThroughput tests that only matching samples are averaged
+func TestMeasureThroughput(t *testing.T) {
+	samples := []Sample{
+		{Model: "m", Usage: gpt.Usage{Requests: 2, Duration: 2 * time.Second}},
+		{Model: "m", Usage: gpt.Usage{Requests: 2, Duration: 6 * time.Second}},
+		{Model: "m", Batch: true, Usage: gpt.Usage{Requests: 1, Duration: 10 * time.Second}},
+		{Model: "other", Usage: gpt.Usage{Requests: 1, Duration: time.Minute}},
+	}
+
+	tp := MeasureThroughput(samples, "m", false)
+	assert.Equal(t, 2, tp.Runs)
+	assert.Equal(t, 2*time.Second, tp.RequestLatency)
+
+	tp = MeasureThroughput(nil, "m", true)
+	assert.Equal(t, 0, tp.Runs)
+	assert.Equal(t, defaultBatchLatency, tp.RequestLatency)
+}
+
+// TestPending tests which texts a run translates in each mode
+func TestPending(t *testing.T) {
+	source := map[string]string{"new": "New", "empty": "Empty", "marked": "Marked", "done": "Done", "blank": ""}
+	target := map[string]string{"empty": "", "marked": "Marquée", "done": "Fait"}
+	marked := map[string]struct{}{"marked": {}}
+
+	assert.ElementsMatch(t, []string{"New"}, Pending(source, target, marked, false))
+	assert.ElementsMatch(t, []string{"New", "Empty", "Marked"}, Pending(source, target, marked, true))
+}
@@ -0,0 +1,85 @@
+package forecast
+
+import (
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const throughputFile = "throughput.json"
+
+// maxSamples bounds how many past runs are kept for throughput measurement
+const maxSamples = 50
+
+// Sample is the usage measured during a single translation run
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Model string    `json:"model"`
+	Batch bool      `json:"batch"`
+	gpt.Usage
+}
+
+// LoadSamples returns the throughput samples recorded by previous runs
+func LoadSamples() ([]Sample, error) {
+	var samples []Sample
+	if err := state.Load(throughputFile, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// RecordSample appends a sample to the throughput history, dropping the oldest ones
+func RecordSample(sample Sample) error {
+	samples, err := LoadSamples()
+	if err != nil {
+		return err
+	}
+
+	samples = append(samples, sample)
+	if len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+
+	return state.Save(throughputFile, samples)
+}
+
+// Throughput is the measured speed of the provider
+type Throughput struct {
+	// Average latency of a single request
+	RequestLatency time.Duration
+	// Number of runs the measurement is based on, 0 means defaults are used
+	Runs int
+}
+
+// Default latencies used when no run has been measured yet
+const (
+	defaultSingleLatency = 2 * time.Second
+	defaultBatchLatency  = 6 * time.Second
+)
+
+// MeasureThroughput averages the recorded samples for the given model and request style
+func MeasureThroughput(samples []Sample, model string, batch bool) Throughput {
+	total := gpt.Usage{}
+	runs := 0
+	for _, s := range samples {
+		if s.Model != model || s.Batch != batch || s.Requests == 0 {
+			continue
+		}
+		total = total.Add(s.Usage)
+		runs++
+	}
+
+	if runs == 0 {
+		latency := defaultSingleLatency
+		if batch {
+			latency = defaultBatchLatency
+		}
+		return Throughput{RequestLatency: latency}
+	}
+
+	return Throughput{
+		RequestLatency: total.Duration / time.Duration(total.Requests),
+		Runs:           runs,
+	}
+}
@@ -0,0 +1,41 @@
+package geo
+
+// place is a city, region or country of the gazetteer
+type place struct {
+	// English name
+	Name string
+	// Names in other languages, by base language code. Russian names are their stems, as
+	// they are declined (Москва, из Москвы).
+	exonyms map[string][]string
+}
+
+// gazetteer holds the places most often named in UI strings (shipping origins, store
+// locations, regions), with their names in the languages translations most often go to
+var gazetteer = []place{
+	{"Berlin", map[string][]string{"de": {"Berlin"}, "fr": {"Berlin"}, "es": {"Berlín"}, "it": {"Berlino"}, "pt": {"Berlim"}, "ru": {"Берлин"}, "ja": {"ベルリン"}, "zh": {"柏林"}, "ko": {"베를린"}}},
+	{"Munich", map[string][]string{"de": {"München"}, "fr": {"Munich"}, "es": {"Múnich"}, "it": {"Monaco di Baviera"}, "pt": {"Munique"}, "ru": {"Мюнхен"}, "ja": {"ミュンヘン"}, "zh": {"慕尼黑"}, "ko": {"뮌헨"}}},
+	{"Cologne", map[string][]string{"de": {"Köln"}, "fr": {"Cologne"}, "es": {"Colonia"}, "it": {"Colonia"}, "pt": {"Colônia", "Colónia"}, "ru": {"Кёльн", "Кельн"}, "ja": {"ケルン"}, "zh": {"科隆"}, "ko": {"쾰른"}}},
+	{"Vienna", map[string][]string{"de": {"Wien"}, "fr": {"Vienne"}, "es": {"Viena"}, "it": {"Vienna"}, "pt": {"Viena"}, "ru": {"Вен"}, "ja": {"ウィーン"}, "zh": {"维也纳", "維也納"}, "ko": {"빈"}}},
+	{"Geneva", map[string][]string{"de": {"Genf"}, "fr": {"Genève"}, "es": {"Ginebra"}, "it": {"Ginevra"}, "pt": {"Genebra"}, "ru": {"Женев"}, "ja": {"ジュネーブ"}, "zh": {"日内瓦", "日內瓦"}, "ko": {"제네바"}}},
+	{"Brussels", map[string][]string{"de": {"Brüssel"}, "fr": {"Bruxelles"}, "es": {"Bruselas"}, "it": {"Bruxelles"}, "pt": {"Bruxelas"}, "ru": {"Брюссел"}, "ja": {"ブリュッセル"}, "zh": {"布鲁塞尔", "布魯塞爾"}, "ko": {"브뤼셀"}}},
+	{"Paris", map[string][]string{"de": {"Paris"}, "fr": {"Paris"}, "es": {"París"}, "it": {"Parigi"}, "pt": {"Paris"}, "ru": {"Париж"}, "ja": {"パリ"}, "zh": {"巴黎"}, "ko": {"파리"}}},
+	{"London", map[string][]string{"de": {"London"}, "fr": {"Londres"}, "es": {"Londres"}, "it": {"Londra"}, "pt": {"Londres"}, "ru": {"Лондон"}, "ja": {"ロンドン"}, "zh": {"伦敦", "倫敦"}, "ko": {"런던"}}},
+	{"Rome", map[string][]string{"de": {"Rom"}, "fr": {"Rome"}, "es": {"Roma"}, "it": {"Roma"}, "pt": {"Roma"}, "ru": {"Рим"}, "ja": {"ローマ"}, "zh": {"罗马", "羅馬"}, "ko": {"로마"}}},
+	{"Milan", map[string][]string{"de": {"Mailand"}, "fr": {"Milan"}, "es": {"Milán"}, "it": {"Milano"}, "pt": {"Milão"}, "ru": {"Милан"}, "ja": {"ミラノ"}, "zh": {"米兰", "米蘭"}, "ko": {"밀라노"}}},
+	{"Madrid", map[string][]string{"de": {"Madrid"}, "fr": {"Madrid"}, "es": {"Madrid"}, "it": {"Madrid"}, "pt": {"Madri", "Madrid"}, "ru": {"Мадрид"}, "ja": {"マドリード", "マドリッド"}, "zh": {"马德里", "馬德里"}, "ko": {"마드리드"}}},
+	{"Lisbon", map[string][]string{"de": {"Lissabon"}, "fr": {"Lisbonne"}, "es": {"Lisboa"}, "it": {"Lisbona"}, "pt": {"Lisboa"}, "ru": {"Лиссабон"}, "ja": {"リスボン"}, "zh": {"里斯本"}, "ko": {"리스본"}}},
+	{"Warsaw", map[string][]string{"de": {"Warschau"}, "fr": {"Varsovie"}, "es": {"Varsovia"}, "it": {"Varsavia"}, "pt": {"Varsóvia"}, "ru": {"Варшав"}, "ja": {"ワルシャワ"}, "zh": {"华沙", "華沙"}, "ko": {"바르샤바"}}},
+	{"Prague", map[string][]string{"de": {"Prag"}, "fr": {"Prague"}, "es": {"Praga"}, "it": {"Praga"}, "pt": {"Praga"}, "ru": {"Праг"}, "ja": {"プラハ"}, "zh": {"布拉格"}, "ko": {"프라하"}}},
+	{"Moscow", map[string][]string{"de": {"Moskau"}, "fr": {"Moscou"}, "es": {"Moscú"}, "it": {"Mosca"}, "pt": {"Moscou", "Moscovo"}, "ru": {"Москв"}, "ja": {"モスクワ"}, "zh": {"莫斯科"}, "ko": {"모스크바"}}},
+	{"Beijing", map[string][]string{"de": {"Peking"}, "fr": {"Pékin"}, "es": {"Pekín"}, "it": {"Pechino"}, "pt": {"Pequim"}, "ru": {"Пекин"}, "ja": {"北京"}, "zh": {"北京"}, "ko": {"베이징"}}},
+	{"Tokyo", map[string][]string{"de": {"Tokio"}, "fr": {"Tokyo"}, "es": {"Tokio"}, "it": {"Tokyo"}, "pt": {"Tóquio"}, "ru": {"Токио"}, "ja": {"東京"}, "zh": {"东京", "東京"}, "ko": {"도쿄"}}},
+	{"Seoul", map[string][]string{"de": {"Seoul"}, "fr": {"Séoul"}, "es": {"Seúl"}, "it": {"Seul"}, "pt": {"Seul"}, "ru": {"Сеул"}, "ja": {"ソウル"}, "zh": {"首尔", "首爾"}, "ko": {"서울"}}},
+	{"New York", map[string][]string{"de": {"New York"}, "fr": {"New York"}, "es": {"Nueva York"}, "it": {"New York"}, "pt": {"Nova York", "Nova Iorque"}, "ru": {"Нью-Йорк"}, "ja": {"ニューヨーク"}, "zh": {"纽约", "紐約"}, "ko": {"뉴욕"}}},
+	{"Germany", map[string][]string{"de": {"Deutschland"}, "fr": {"Allemagne"}, "es": {"Alemania"}, "it": {"Germania"}, "pt": {"Alemanha"}, "ru": {"Германи"}, "ja": {"ドイツ"}, "zh": {"德国", "德國"}, "ko": {"독일"}}},
+	{"France", map[string][]string{"de": {"Frankreich"}, "fr": {"France"}, "es": {"Francia"}, "it": {"Francia"}, "pt": {"França"}, "ru": {"Франци"}, "ja": {"フランス"}, "zh": {"法国", "法國"}, "ko": {"프랑스"}}},
+	{"Spain", map[string][]string{"de": {"Spanien"}, "fr": {"Espagne"}, "es": {"España"}, "it": {"Spagna"}, "pt": {"Espanha"}, "ru": {"Испани"}, "ja": {"スペイン"}, "zh": {"西班牙"}, "ko": {"스페인"}}},
+	{"Italy", map[string][]string{"de": {"Italien"}, "fr": {"Italie"}, "es": {"Italia"}, "it": {"Italia"}, "pt": {"Itália"}, "ru": {"Итали"}, "ja": {"イタリア"}, "zh": {"意大利", "義大利"}, "ko": {"이탈리아"}}},
+	{"Japan", map[string][]string{"de": {"Japan"}, "fr": {"Japon"}, "es": {"Japón"}, "it": {"Giappone"}, "pt": {"Japão"}, "ru": {"Япони"}, "ja": {"日本"}, "zh": {"日本"}, "ko": {"일본"}}},
+	{"China", map[string][]string{"de": {"China"}, "fr": {"Chine"}, "es": {"China"}, "it": {"Cina"}, "pt": {"China"}, "ru": {"Кита"}, "ja": {"中国"}, "zh": {"中国", "中國"}, "ko": {"중국"}}},
+	{"United States", map[string][]string{"de": {"Vereinigte Staaten", "Vereinigten Staaten", "USA"}, "fr": {"États-Unis"}, "es": {"Estados Unidos", "EE. UU.", "EE.UU."}, "it": {"Stati Uniti"}, "pt": {"Estados Unidos", "EUA"}, "ru": {"США", "Соединённые Штаты", "Соединенные Штаты", "Соединённых Штат", "Соединенных Штат"}, "ja": {"アメリカ", "米国"}, "zh": {"美国", "美國"}, "ko": {"미국"}}},
+}
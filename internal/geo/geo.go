@@ -0,0 +1,191 @@
+package geo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Policies for the names of cities, regions and countries in translations
+const (
+	// Localize renders names as they are known in the target language: München, Pékin
+	Localize = "localize"
+	// Keep keeps names as written in the source
+	Keep = "keep"
+	// Auto leaves the choice to the model, localizing well-known names only
+	Auto = "auto"
+)
+
+// Policies are the valid geographic name policies
+var Policies = []string{Localize, Keep, Auto}
+
+// Settings of the rendering of geographic names
+type Settings struct {
+	// Policy of all languages: localize, keep or auto
+	Policy string `json:"policy"`
+	// Policy per target language code overriding Policy, e.g. {"ja": "localize"}
+	Languages map[string]string `json:"languages,omitempty"`
+}
+
+// Validate checks the policies of s
+func (s *Settings) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if err := validate("geoNames.policy", s.Policy); err != nil {
+		return err
+	}
+	for lang, policy := range s.Languages {
+		if err := validate("geoNames.languages."+lang, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(field, policy string) error {
+	for _, p := range Policies {
+		if policy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of %s, got %q", field, strings.Join(Policies, ", "), policy)
+}
+
+// PolicyFor returns the policy of translations into lang: that set for lang, then for
+// its base language (zh for zh-TW), then Policy. It is empty when s is nil.
+func (s *Settings) PolicyFor(lang string) string {
+	if s == nil {
+		return ""
+	}
+	for _, code := range []string{lang, base(lang)} {
+		for l, policy := range s.Languages {
+			if strings.EqualFold(strings.ReplaceAll(l, "_", "-"), strings.ReplaceAll(code, "_", "-")) {
+				return policy
+			}
+		}
+	}
+	return s.Policy
+}
+
+// base returns the base language of a language code, lowercased
+func base(lang string) string {
+	code, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	return strings.ToLower(code)
+}
+
+// Instruction returns the prompt instruction of the policy of lang, empty without one
+func (s *Settings) Instruction(lang string) string {
+	switch s.PolicyFor(lang) {
+	case Localize:
+		return "Write the names of cities, regions and countries as they are commonly known in the target language (München for Munich in German, Pékin for Beijing in French), translating each of them."
+	case Keep:
+		return "Keep the names of cities, regions and countries exactly as written in the source, without translating or transliterating them."
+	case Auto:
+		return "Write well-known names of cities, regions and countries as they are commonly known in the target language, and keep lesser-known ones as written. Never leave a place name out."
+	}
+	return ""
+}
+
+// Check verifies that the places the source names are rendered in the translation as
+// the policy of lang asks, at least as often as in the source. Only the places of the
+// gazetteer are checked, and their localized forms only in the languages it covers;
+// names may be inflected (из Берлина).
+func (s *Settings) Check(source, translation, lang string) error {
+	policy := s.PolicyFor(lang)
+	if policy == "" {
+		return nil
+	}
+	var places []Place
+	for _, p := range gazetteer {
+		n := len(wholeName(p.Name).FindAllString(source, -1))
+		if n == 0 {
+			continue
+		}
+		forms := p.forms(lang, policy)
+		if forms == nil {
+			continue
+		}
+		found := 0
+		for _, form := range forms {
+			found += count(form, translation)
+		}
+		if found < n {
+			places = append(places, Place{Name: p.Name, Forms: forms})
+		}
+	}
+	if len(places) == 0 {
+		return nil
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].Name < places[j].Name })
+	return &Error{Policy: policy, Places: places}
+}
+
+// forms returns the forms p may take in translations into lang under policy, nil when
+// they aren't known
+func (p place) forms(lang, policy string) []string {
+	localized := p.exonyms[base(lang)]
+	switch policy {
+	case Keep:
+		return []string{p.Name}
+	case Localize:
+		return localized
+	}
+	if localized == nil {
+		return nil
+	}
+	forms := []string{p.Name}
+	for _, form := range localized {
+		if form != p.Name {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// wholeName matches name as a whole word
+func wholeName(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^\pL])` + regexp.QuoteMeta(name) + `(?:$|[^\pL])`)
+}
+
+// count returns how many times form appears in text, as the start of a word so that
+// inflected forms count, or anywhere for scripts written without spaces
+func count(form, text string) int {
+	if cjk.MatchString(form) {
+		return strings.Count(text, form)
+	}
+	return len(regexp.MustCompile(`(?:^|[^\pL])`+regexp.QuoteMeta(form)).FindAllString(text, -1))
+}
+
+var cjk = regexp.MustCompile(`[\p{Han}\p{Hiragana}\p{Katakana}\p{Hangul}]`)
+
+// Place is a place of the source a translation doesn't render as its policy asks
+type Place struct {
+	// English name of the place, as written in the source
+	Name string
+	// Forms the translation may use
+	Forms []string
+}
+
+// Error is a translation that drops geographic names of its source, or doesn't
+// render them as the policy of its language asks
+type Error struct {
+	Policy string
+	Places []Place
+}
+
+func (e *Error) Error() string {
+	parts := []string{}
+	for _, p := range e.Places {
+		switch e.Policy {
+		case Keep:
+			parts = append(parts, fmt.Sprintf("keep %s as written", p.Name))
+		case Localize:
+			parts = append(parts, fmt.Sprintf("render %s as %s", p.Name, strings.Join(p.Forms, " or ")))
+		default:
+			parts = append(parts, fmt.Sprintf("%s is missing", p.Name))
+		}
+	}
+	return fmt.Sprintf("geographic names changed in translation: %s", strings.Join(parts, ", "))
+}
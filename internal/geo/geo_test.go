@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPolicyFor tests that language policies win over the default one
+func TestPolicyFor(t *testing.T) {
+	s := &Settings{Policy: Keep, Languages: map[string]string{"ja": Localize, "zh-TW": Auto}}
+	assert.Equal(t, Keep, s.PolicyFor("fr"))
+	assert.Equal(t, Localize, s.PolicyFor("ja-JP"))
+	assert.Equal(t, Auto, s.PolicyFor("zh_TW"))
+	assert.Equal(t, Keep, s.PolicyFor("zh"))
+
+	var unset *Settings
+	assert.Empty(t, unset.PolicyFor("fr"))
+	assert.Empty(t, unset.Instruction("fr"))
+	assert.NoError(t, unset.Check("Shipping from Berlin", "Expédié", "fr"))
+
+	assert.NoError(t, s.Validate())
+	assert.EqualError(t, (&Settings{Policy: "translate"}).Validate(), `geoNames.policy must be one of localize, keep, auto, got "translate"`)
+}
+
+// TestCheck tests that geographic names are rendered as the policy of the language asks
+func TestCheck(t *testing.T) {
+	localize := &Settings{Policy: Localize}
+	assert.NoError(t, localize.Check("Shipping from Munich", "Versand aus München", "de"))
+	assert.NoError(t, localize.Check("Shipping from Moscow", "Доставка из Москвы", "ru"))
+	assert.NoError(t, localize.Check("Offices in Tokyo and Seoul", "東京とソウルのオフィス", "ja"))
+	err := localize.Check("Shipping from Munich", "Versand aus Munich", "de")
+	assert.Equal(t, &Error{Policy: Localize, Places: []Place{{Name: "Munich", Forms: []string{"München"}}}}, err)
+	assert.EqualError(t, err, "geographic names changed in translation: render Munich as München")
+	// Languages the gazetteer doesn't cover aren't checked
+	assert.NoError(t, localize.Check("Shipping from Munich", "Verzending vanuit Munich", "nl"))
+
+	keep := &Settings{Policy: Keep}
+	assert.NoError(t, keep.Check("Shipping from Berlin", "Expédié depuis Berlin", "fr"))
+	assert.EqualError(t, keep.Check("Shipping from Munich and Vienna", "Versand aus München und Wien", "de"), "geographic names changed in translation: keep Munich as written, keep Vienna as written")
+
+	auto := &Settings{Policy: Auto}
+	assert.NoError(t, auto.Check("Munich or Berlin", "Munich ou Berlin", "fr"))
+	assert.NoError(t, auto.Check("Shipping from Beijing", "Expédié depuis Pékin", "fr"))
+	// Each occurrence counts
+	assert.EqualError(t, auto.Check("From Paris to Paris", "De Paris", "fr"), "geographic names changed in translation: Paris is missing")
+	// Names are whole words of the source
+	assert.NoError(t, auto.Check("Parisian style", "Style parisien", "fr"))
+}
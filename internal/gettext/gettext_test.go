@@ -0,0 +1,83 @@
+package gettext
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const samplePO = `# Translation of demo
+msgid ""
+msgstr ""
+"Project-Id-Version: demo\n"
+"Language: en\n"
+
+#: main.go:10
+msgid "Hello"
+msgstr ""
+
+#, fuzzy
+msgctxt "menu"
+msgid "Open"
+msgstr "Open"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+msgid ""
+"Line one\n"
+"Line \"two\""
+msgstr ""
+
+#~ msgid "Obsolete"
+#~ msgstr "Old"
+`
+
+// TestParseWrite tests that parsing and writing a PO file round-trips
+func TestParseWrite(t *testing.T) {
+	f, err := Parse(strings.NewReader(samplePO))
+	assert.NoError(t, err)
+	assert.Len(t, f.Entries, 5)
+	assert.Equal(t, "en", f.HeaderField("Language"))
+
+	open := f.Lookup(true, "menu", "Open")
+	assert.NotNil(t, open)
+	assert.True(t, open.Fuzzy())
+	plural := f.Entries[3]
+	assert.True(t, plural.Plural())
+	assert.Equal(t, []string{"", ""}, plural.Str)
+	assert.Equal(t, "Line one\nLine \"two\"", f.Entries[4].ID)
+	assert.Equal(t, []string{`#~ msgid "Obsolete"`, `#~ msgstr "Old"`}, f.Trailing)
+
+	var buf bytes.Buffer
+	assert.NoError(t, f.Write(&buf))
+	assert.Equal(t, samplePO, buf.String())
+
+	f.SetHeaderField("Language", "ru")
+	f.SetHeaderField("Plural-Forms", RuleFor("ru").Header())
+	assert.Equal(t, "Project-Id-Version: demo\nLanguage: ru\nPlural-Forms: "+RuleFor("ru").Header()+"\n", f.Header().Str[0])
+}
+
+// TestPluralRules tests that the gettext forms of each language match distinct CLDR categories
+func TestPluralRules(t *testing.T) {
+	for lang, rule := range pluralRules {
+		assert.Len(t, rule.Examples, rule.NPlurals, lang)
+
+		categories := rule.Categories(lang)
+		seen := map[string]bool{}
+		for _, c := range categories {
+			assert.False(t, seen[c], "%s has two forms for %s", lang, c)
+			seen[c] = true
+		}
+	}
+
+	assert.Equal(t, []string{"one", "few", "many"}, RuleFor("ru").Categories("ru"))
+	assert.Equal(t, []string{"zero", "one", "two", "few", "many", "other"}, RuleFor("ar").Categories("ar"))
+	assert.Equal(t, []string{"other"}, RuleFor("ja").Categories("ja"))
+	assert.Equal(t, RuleFor("pt-BR"), RuleFor("pt_BR"))
+	assert.Equal(t, 2, RuleFor("xx").NPlurals)
+}
@@ -0,0 +1,100 @@
+package gettext
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// PluralRule is the gettext plural rule of a language
+type PluralRule struct {
+	// Number of msgstr[] forms
+	NPlurals int
+	// C expression selecting the form for n
+	Expression string
+	// A count of each form, in msgstr[] order
+	Examples []int
+}
+
+// Header returns the rule as a Plural-Forms header value
+func (r PluralRule) Header() string {
+	return "nplurals=" + strconv.Itoa(r.NPlurals) + "; plural=" + r.Expression + ";"
+}
+
+// Categories returns the CLDR plural category (zero, one, two, few, many, other) of
+// each form of the rule for a language
+func (r PluralRule) Categories(lang string) []string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	categories := make([]string, len(r.Examples))
+	for i, n := range r.Examples {
+		categories[i] = formName(plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0))
+	}
+	return categories
+}
+
+var (
+	noPlural   = PluralRule{1, "0", []int{1}}
+	oneOther   = PluralRule{2, "(n != 1)", []int{1, 2}}
+	oneOtherFr = PluralRule{2, "(n > 1)", []int{1, 2}}
+	slavic     = PluralRule{3, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)", []int{1, 2, 5}}
+)
+
+// pluralRules holds the gettext rules of languages by base language code, or by full
+// code where a region differs
+var pluralRules = map[string]PluralRule{
+	"ja": noPlural, "zh": noPlural, "ko": noPlural, "vi": noPlural, "th": noPlural, "id": noPlural, "ms": noPlural,
+
+	"en": oneOther, "de": oneOther, "nl": oneOther, "sv": oneOther, "da": oneOther, "nb": oneOther, "nn": oneOther,
+	"no": oneOther, "fi": oneOther, "es": oneOther, "it": oneOther, "pt": oneOther, "el": oneOther, "hu": oneOther,
+	"bg": oneOther, "et": oneOther, "tr": oneOther, "ca": oneOther, "eo": oneOther, "he": oneOther,
+
+	"fr": oneOtherFr, "pt-BR": oneOtherFr,
+
+	"ru": slavic, "uk": slavic, "be": slavic, "sr": slavic, "hr": slavic, "bs": slavic,
+	"pl": {3, "(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)", []int{1, 2, 5}},
+	"cs": {3, "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2", []int{1, 2, 5}},
+	"sk": {3, "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2", []int{1, 2, 5}},
+	"lt": {3, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2)", []int{1, 2, 10}},
+	"lv": {3, "(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2)", []int{1, 2, 0}},
+	"ro": {3, "(n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2)", []int{1, 2, 20}},
+	"sl": {4, "(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3)", []int{1, 2, 3, 5}},
+	"ga": {5, "(n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4)", []int{1, 2, 3, 7, 11}},
+	"ar": {6, "(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5)", []int{0, 1, 2, 3, 11, 100}},
+}
+
+// RuleFor returns the plural rule of a language code such as "ru" or "pt_BR".
+// Unknown languages get the English one/other rule.
+func RuleFor(lang string) PluralRule {
+	code := strings.ReplaceAll(lang, "_", "-")
+	if rule, ok := pluralRules[code]; ok {
+		return rule
+	}
+	if tag, err := language.Parse(code); err == nil {
+		base, _ := tag.Base()
+		if rule, ok := pluralRules[base.String()]; ok {
+			return rule
+		}
+	}
+	return oneOther
+}
+
+func formName(f plural.Form) string {
+	switch f {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	}
+	return "other"
+}
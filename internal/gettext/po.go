@@ -0,0 +1,323 @@
+package gettext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Entry is a message of a PO file
+type Entry struct {
+	// Comment lines, including the leading "#", kept verbatim
+	Comments   []string
+	HasContext bool
+	Context    string
+	ID         string
+	IDPlural   string
+	// Translations; one for singular messages, one per plural form otherwise
+	Str []string
+}
+
+// Plural reports whether the entry has plural forms
+func (e *Entry) Plural() bool {
+	return e.IDPlural != ""
+}
+
+// Translated reports whether every form of the entry has a translation
+func (e *Entry) Translated() bool {
+	if len(e.Str) == 0 {
+		return false
+	}
+	for _, s := range e.Str {
+		if s == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Fuzzy reports whether the entry is flagged as needing review
+func (e *Entry) Fuzzy() bool {
+	for _, c := range e.Comments {
+		if strings.HasPrefix(c, "#,") && strings.Contains(c, "fuzzy") {
+			return true
+		}
+	}
+	return false
+}
+
+// File is a parsed PO or POT file. The header is the entry with an empty msgid.
+type File struct {
+	Entries []*Entry
+	// Comment lines after the last entry, e.g. obsolete "#~" messages
+	Trailing []string
+}
+
+// Header returns the header entry, or nil if the file has none
+func (f *File) Header() *Entry {
+	for _, e := range f.Entries {
+		if e.ID == "" && !e.HasContext {
+			return e
+		}
+	}
+	return nil
+}
+
+// HeaderField returns a field of the header, e.g. "Plural-Forms"
+func (f *File) HeaderField(name string) string {
+	h := f.Header()
+	if h == nil || len(h.Str) == 0 {
+		return ""
+	}
+	for _, line := range strings.Split(h.Str[0], "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// SetHeaderField sets a field of the header, adding the header if needed
+func (f *File) SetHeaderField(name, value string) {
+	h := f.Header()
+	if h == nil {
+		h = &Entry{Str: []string{""}}
+		f.Entries = append([]*Entry{h}, f.Entries...)
+	}
+	if len(h.Str) == 0 {
+		h.Str = []string{""}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(h.Str[0], "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	field := name + ": " + value
+	replaced := false
+	for i, line := range lines {
+		if k, _, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			lines[i] = field
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, field)
+	}
+	h.Str[0] = strings.Join(lines, "\n") + "\n"
+}
+
+// Lookup returns the entry with the given context and msgid
+func (f *File) Lookup(hasContext bool, context, id string) *Entry {
+	for _, e := range f.Entries {
+		if e.HasContext == hasContext && e.Context == context && e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Parse reads a PO or POT file
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	var cur *Entry
+	// The string being continued by quoted lines: "msgid", "msgstr[1]", ...
+	field := ""
+	lineNo := 0
+
+	flush := func() {
+		if cur != nil {
+			f.Entries = append(f.Entries, cur)
+			cur = nil
+		}
+		field = ""
+	}
+	// entry starts an entry unless the current one hasn't reached its msgid yet
+	entry := func() *Entry {
+		if cur == nil {
+			cur = &Entry{}
+		}
+		return cur
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+			// A comment after the strings of an entry starts the next one
+			if cur != nil && (field == "msgstr" || strings.HasPrefix(field, "msgstr[")) {
+				flush()
+			}
+			entry().Comments = append(entry().Comments, line)
+		case strings.HasPrefix(line, `"`):
+			if cur == nil || field == "" {
+				return nil, fmt.Errorf("line %d: string without a keyword", lineNo)
+			}
+			s, err := unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			appendField(cur, field, s)
+		default:
+			keyword, rest, _ := strings.Cut(line, " ")
+			s, err := unquote(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			// A new msgctxt or msgid after the strings of an entry starts the next one
+			if (keyword == "msgctxt" || keyword == "msgid") && cur != nil && (field == "msgstr" || strings.HasPrefix(field, "msgstr[")) {
+				flush()
+			}
+			e := entry()
+			switch {
+			case keyword == "msgctxt":
+				e.HasContext = true
+				e.Context = s
+			case keyword == "msgid":
+				e.ID = s
+			case keyword == "msgid_plural":
+				e.IDPlural = s
+			case keyword == "msgstr":
+				e.Str = []string{s}
+			case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+				n, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("line %d: invalid plural index %s", lineNo, keyword)
+				}
+				for len(e.Str) <= n {
+					e.Str = append(e.Str, "")
+				}
+				e.Str[n] = s
+			default:
+				return nil, fmt.Errorf("line %d: unknown keyword %s", lineNo, keyword)
+			}
+			field = keyword
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	// Comment-only blocks at the end (obsolete messages) are kept as trailing comments
+	for len(f.Entries) > 0 {
+		last := f.Entries[len(f.Entries)-1]
+		if last.ID != "" || last.HasContext || last.Str != nil {
+			break
+		}
+		f.Trailing = append(last.Comments, f.Trailing...)
+		f.Entries = f.Entries[:len(f.Entries)-1]
+	}
+	return f, nil
+}
+
+func appendField(e *Entry, field, s string) {
+	switch {
+	case field == "msgctxt":
+		e.Context += s
+	case field == "msgid":
+		e.ID += s
+	case field == "msgid_plural":
+		e.IDPlural += s
+	case field == "msgstr":
+		e.Str[0] += s
+	default:
+		n, _ := strconv.Atoi(field[len("msgstr[") : len(field)-1])
+		e.Str[n] += s
+	}
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	var b strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' || i == len(body)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// writeString writes a keyword and its string, splitting multi-line strings after
+// each line break the way msgcat does
+func writeString(w *bufio.Writer, keyword, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		fmt.Fprintf(w, "%s %s\n", keyword, quote(s))
+		return
+	}
+	fmt.Fprintf(w, "%s \"\"\n", keyword)
+	for _, line := range lines {
+		fmt.Fprintln(w, quote(line))
+	}
+}
+
+// Write writes the file in PO format
+func (f *File) Write(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	for i, e := range f.Entries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		for _, c := range e.Comments {
+			fmt.Fprintln(w, c)
+		}
+		if e.HasContext {
+			writeString(w, "msgctxt", e.Context)
+		}
+		writeString(w, "msgid", e.ID)
+		if e.Plural() {
+			writeString(w, "msgid_plural", e.IDPlural)
+			for n, s := range e.Str {
+				writeString(w, fmt.Sprintf("msgstr[%d]", n), s)
+			}
+			if len(e.Str) == 0 {
+				writeString(w, "msgstr[0]", "")
+			}
+		} else {
+			s := ""
+			if len(e.Str) > 0 {
+				s = e.Str[0]
+			}
+			writeString(w, "msgstr", s)
+		}
+	}
+	if len(f.Trailing) > 0 {
+		fmt.Fprintln(w)
+		for _, c := range f.Trailing {
+			fmt.Fprintln(w, c)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,125 @@
+package glossary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Glossary maps protected source terms to their translation per language code, e.g.
+// {"Cart": {"fr": "Panier", "de": "Warenkorb"}}
+type Glossary map[string]map[string]string
+
+// Entry is a term and its required translation into a language
+type Entry struct {
+	Term        string `json:"term"`
+	Translation string `json:"translation"`
+}
+
+// Load reads a glossary file, in JSON or, with a .tbx extension, in TBX
+func Load(path string) (Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g Glossary
+	if strings.EqualFold(filepath.Ext(path), ".tbx") {
+		if g, err = ReadTBX(bytes.NewReader(data), ""); err != nil {
+			return nil, fmt.Errorf("error parsing glossary %s: %w", path, err)
+		}
+	} else if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("error parsing glossary %s: %w", path, err)
+	}
+	for term := range g {
+		if strings.TrimSpace(term) == "" {
+			return nil, fmt.Errorf("glossary %s has an empty term", path)
+		}
+	}
+	return g, nil
+}
+
+// translation returns the translation of term into lang. Languages match regardless
+// of case, and a translation into the base language ("pt") covers regional variants
+// ("pt-BR") without their own.
+func (g Glossary) translation(term, lang string) (string, bool) {
+	translations := g[term]
+	for code, t := range translations {
+		if strings.EqualFold(code, lang) {
+			return t, true
+		}
+	}
+	base, _ := language.Make(lang).Base()
+	for code, t := range translations {
+		if b, _ := language.Make(code).Base(); b == base && !strings.ContainsAny(code, "-_") {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Terms returns the entries of the terms text contains that have a translation into
+// lang, sorted by term
+func (g Glossary) Terms(text, lang string) []Entry {
+	entries := []Entry{}
+	for term := range g {
+		t, ok := g.translation(term, lang)
+		if !ok || !contains(text, term) {
+			continue
+		}
+		entries = append(entries, Entry{Term: term, Translation: t})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Term < entries[j].Term })
+	return entries
+}
+
+// Prompt returns the terminology of the texts translated into lang as prompt
+// instructions, empty when they contain no glossary term
+func (g Glossary) Prompt(texts []string, lang string) string {
+	seen := map[string]bool{}
+	lines := []string{}
+	for _, text := range texts {
+		for _, e := range g.Terms(text, lang) {
+			if seen[e.Term] {
+				continue
+			}
+			seen[e.Term] = true
+			lines = append(lines, fmt.Sprintf("%q must be translated as %q", e.Term, e.Translation))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "; ")
+}
+
+// Check returns an error when a translation of source into lang doesn't render one
+// of the glossary terms of source as required
+func (g Glossary) Check(source, result, lang string) error {
+	if missing := g.Violations(source, result, lang); len(missing) > 0 {
+		return fmt.Errorf("glossary term %q must be translated as %q", missing[0].Term, missing[0].Translation)
+	}
+	return nil
+}
+
+// Violations returns the entries of the glossary terms of source that result
+// doesn't contain
+func (g Glossary) Violations(source, result, lang string) []Entry {
+	missing := []Entry{}
+	for _, e := range g.Terms(source, lang) {
+		if !strings.Contains(strings.ToLower(result), strings.ToLower(e.Translation)) {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
+// contains reports whether text contains term as a whole word, regardless of case
+func contains(text, term string) bool {
+	pattern := `(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(term) + `($|[^\pL\pN])`
+	return regexp.MustCompile(pattern).MatchString(text)
+}
@@ -0,0 +1,49 @@
+package glossary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTerms tests that whole-word terms are found regardless of case, with the
+// translation of the language or of its base language
+func TestTerms(t *testing.T) {
+	g := Glossary{
+		"Cart":     {"fr": "Panier", "pt-BR": "Carrinho", "pt": "Cesto"},
+		"Checkout": {"fr": "Paiement"},
+	}
+
+	assert.Equal(t, []Entry{{Term: "Cart", Translation: "Panier"}, {Term: "Checkout", Translation: "Paiement"}}, g.Terms("Your cart, then checkout", "FR"))
+	assert.Empty(t, g.Terms("Cartography", "fr"))
+	assert.Equal(t, []Entry{{Term: "Cart", Translation: "Carrinho"}}, g.Terms("Cart", "pt-BR"))
+	assert.Equal(t, []Entry{{Term: "Cart", Translation: "Cesto"}}, g.Terms("Cart", "pt-PT"))
+	assert.Empty(t, g.Terms("Cart", "de"))
+
+	assert.Equal(t, `"Cart" must be translated as "Panier"; "Checkout" must be translated as "Paiement"`, g.Prompt([]string{"Checkout", "Cart", "My cart"}, "fr"))
+	assert.Equal(t, "", g.Prompt([]string{"Hello"}, "fr"))
+}
+
+// TestCheck tests that translations must contain the required renderings
+func TestCheck(t *testing.T) {
+	g := Glossary{"Cart": {"fr": "Panier"}}
+	assert.NoError(t, g.Check("Open the cart", "Ouvrir le panier", "fr"))
+	assert.EqualError(t, g.Check("Open the cart", "Ouvrir le chariot", "fr"), `glossary term "Cart" must be translated as "Panier"`)
+	assert.NoError(t, Glossary(nil).Check("Open the cart", "Ouvrir le chariot", "fr"))
+}
+
+// TestLoad tests that glossary files are read and empty terms refused
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "glossary.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Cart": {"fr": "Panier"}}`), 0644))
+	g, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Glossary{"Cart": {"fr": "Panier"}}, g)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{" ": {"fr": "Panier"}}`), 0644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package glossary
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// tbxNamespace is the namespace of TBX documents (ISO 30042:2019)
+const tbxNamespace = "urn:iso:std:iso:30042:ed-2"
+
+// xmlNamespace is the namespace of the xml:lang attribute
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+type tbxDocument struct {
+	XMLName xml.Name     `xml:"tbx"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Type    string       `xml:"type,attr"`
+	Style   string       `xml:"style,attr"`
+	Lang    string       `xml:"xml:lang,attr"`
+	Title   string       `xml:"tbxHeader>fileDesc>sourceDesc>p"`
+	Entries []tbxConcept `xml:"text>body>conceptEntry"`
+}
+
+type tbxConcept struct {
+	ID       string       `xml:"id,attr"`
+	Sections []tbxSection `xml:"langSec"`
+}
+
+type tbxSection struct {
+	Lang string `xml:"xml:lang,attr"`
+	Term string `xml:"termSec>term"`
+}
+
+// WriteTBX writes the glossary as a TBX-Core document, the term base exchange format
+// of CAT tools: a concept per term of sourceLang, with a language section per
+// translation
+func (g Glossary) WriteTBX(w io.Writer, sourceLang string) error {
+	terms := make([]string, 0, len(g))
+	for term := range g {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	doc := tbxDocument{XMLNS: tbxNamespace, Type: "TBX-Core", Style: "dca", Lang: sourceLang, Title: "i18n-cli glossary"}
+	for i, term := range terms {
+		concept := tbxConcept{ID: fmt.Sprintf("c%d", i+1), Sections: []tbxSection{{Lang: sourceLang, Term: term}}}
+		langs := make([]string, 0, len(g[term]))
+		for lang := range g[term] {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			concept.Sections = append(concept.Sections, tbxSection{Lang: lang, Term: g[term][lang]})
+		}
+		doc.Entries = append(doc.Entries, concept)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadTBX reads the concepts of a TBX document as a glossary of the terms of sourceLang,
+// or of the language of the document when empty. Both TBX-Core (conceptEntry, langSec,
+// termSec) and the older TBX-Basic (termEntry, langSet, tig) are read. The first term of
+// a language is kept, and concepts without a source term are left out.
+func ReadTBX(r io.Reader, sourceLang string) (Glossary, error) {
+	dec := xml.NewDecoder(r)
+	g := Glossary{}
+	var (
+		terms map[string]string // language -> first term of the concept
+		order []string
+		lang  string
+		term  *strings.Builder
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid TBX: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tbx", "martif":
+				if sourceLang == "" {
+					sourceLang = xmlLang(t)
+				}
+			case "conceptEntry", "termEntry":
+				terms, order = map[string]string{}, nil
+			case "langSec", "langSet":
+				lang = xmlLang(t)
+			case "term":
+				term = &strings.Builder{}
+			}
+		case xml.CharData:
+			if term != nil {
+				term.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "term":
+				if text := strings.TrimSpace(term.String()); text != "" && terms != nil {
+					if _, ok := terms[lang]; !ok {
+						terms[lang] = text
+						order = append(order, lang)
+					}
+				}
+				term = nil
+			case "conceptEntry", "termEntry":
+				addConcept(g, terms, order, sourceLang)
+				terms = nil
+			}
+		}
+	}
+	if sourceLang == "" {
+		return nil, fmt.Errorf("invalid TBX: no source language")
+	}
+	return g, nil
+}
+
+// addConcept adds the translations of the source term of a concept to g
+func addConcept(g Glossary, terms map[string]string, order []string, sourceLang string) {
+	source := ""
+	for _, lang := range order {
+		if strings.EqualFold(lang, sourceLang) {
+			source = terms[lang]
+		}
+	}
+	if source == "" {
+		return
+	}
+	for _, lang := range order {
+		if strings.EqualFold(lang, sourceLang) {
+			continue
+		}
+		if g[source] == nil {
+			g[source] = map[string]string{}
+		}
+		g[source][lang] = terms[lang]
+	}
+}
+
+// xmlLang returns the xml:lang attribute of e
+func xmlLang(e xml.StartElement) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == "lang" && (a.Name.Space == xmlNamespace || a.Name.Space == "") {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Merge adds the terms of other to g, and the translations of other into languages g
+// has none for. Translations of g are kept; the terms translated otherwise by other
+// are returned, sorted.
+func (g Glossary) Merge(other Glossary) (added int, conflicts []string) {
+	for term, translations := range other {
+		if g[term] == nil {
+			g[term] = map[string]string{}
+		}
+		conflict := false
+		for lang, t := range translations {
+			ours, ok := g[term][lang]
+			if !ok {
+				g[term][lang] = t
+				added++
+			} else if ours != t {
+				conflict = true
+			}
+		}
+		if conflict {
+			conflicts = append(conflicts, term)
+		}
+	}
+	sort.Strings(conflicts)
+	return added, conflicts
+}
@@ -0,0 +1,48 @@
+package glossary
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTBXRoundTrip tests that a glossary written as TBX is read back, also by Load
+func TestTBXRoundTrip(t *testing.T) {
+	g := Glossary{"Cart": {"fr": "Panier", "de": "Warenkorb"}, "Checkout": {"fr": "Paiement"}}
+	var buf bytes.Buffer
+	assert.NoError(t, g.WriteTBX(&buf, "en"))
+	assert.Contains(t, buf.String(), `<langSec xml:lang="de">`)
+
+	path := filepath.Join(t.TempDir(), "terms.tbx")
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, g, loaded)
+}
+
+// TestReadTBXBasic tests reading the older TBX-Basic format
+func TestReadTBXBasic(t *testing.T) {
+	doc := `<martif type="TBX-Basic" xml:lang="en"><text><body>
+  <termEntry id="1">
+    <langSet xml:lang="en"><tig><term>Sign in</term></tig></langSet>
+    <langSet xml:lang="fr"><tig><term>Se connecter</term></tig><tig><term>Connexion</term></tig></langSet>
+  </termEntry>
+  <termEntry id="2"><langSet xml:lang="fr"><tig><term>Orphelin</term></tig></langSet></termEntry>
+</body></text></martif>`
+	g, err := ReadTBX(strings.NewReader(doc), "")
+	assert.NoError(t, err)
+	assert.Equal(t, Glossary{"Sign in": {"fr": "Se connecter"}}, g)
+}
+
+// TestMerge tests that merging keeps our translations and reports the others
+func TestMerge(t *testing.T) {
+	g := Glossary{"Cart": {"fr": "Panier"}}
+	added, conflicts := g.Merge(Glossary{"Cart": {"fr": "Chariot", "de": "Warenkorb"}, "Save": {"fr": "Enregistrer"}})
+	assert.Equal(t, 2, added)
+	assert.Equal(t, []string{"Cart"}, conflicts)
+	assert.Equal(t, Glossary{"Cart": {"fr": "Panier", "de": "Warenkorb"}, "Save": {"fr": "Enregistrer"}}, g)
+}
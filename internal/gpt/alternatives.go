@@ -0,0 +1,71 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/safety"
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+const alternativesSystemPrompt = " Instead of a single translation, write %d alternative translations that differ in wording, tone or structure while each staying faithful to the text, best first. Return ONLY a JSON object in this exact format: {\"alternatives\": [\"translation 1\", \"translation 2\", ...]}"
+
+// alternativesTemperature is the temperature of alternatives requests, higher than the
+// one of translations so the alternatives differ
+const alternativesTemperature = 0.8
+
+// Alternatives asks the model for n distinct translations of text into lang in a single
+// request, best first, for texts such as marketing headlines a human picks the wording
+// of. Alternatives losing protected tokens, and duplicates, are left out, so fewer than
+// n may be returned.
+func (h *Handler) Alternatives(ctx context.Context, text string, lang string, n int) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("the number of alternatives must be at least 1, got %d", n)
+	}
+	prefix, core, suffix := h.cfg.Affixes.Split(text)
+	masked := h.cfg.Protect.Protect(core)
+	if err := h.screen(masked); err != nil {
+		return nil, err
+	}
+
+	req, tag := h.translationRequest(ctx, masked, lang, nil, nil)
+	req.Messages[0].Content += fmt.Sprintf(alternativesSystemPrompt, n)
+	req.Temperature = h.temperature(alternativesTemperature)
+	req.MaxTokens = h.maxTokens(batchMaxTokens)
+	req.ResponseFormat = &gogpt.ChatCompletionResponseFormat{Type: gogpt.ChatCompletionResponseFormatTypeJSONObject}
+
+	content, err := h.chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Alternatives []string `json:"alternatives"`
+	}
+	if err := json.Unmarshal([]byte(content), &resp); err != nil {
+		return nil, fmt.Errorf("invalid alternatives response: %w", err)
+	}
+
+	alternatives := []string{}
+	seen := map[string]bool{}
+	for _, alt := range resp.Alternatives {
+		alt = strings.TrimSpace(alt)
+		if tag != "" {
+			alt = safety.Unwrap(alt, tag)
+		}
+		restored, err := h.cfg.Protect.Restore(core, alt)
+		if alt == "" || err != nil {
+			continue
+		}
+		restored = h.cfg.Affixes.Join(prefix, restored, suffix, targetCode(ctx))
+		if !seen[restored] && len(alternatives) < n {
+			seen[restored] = true
+			alternatives = append(alternatives, restored)
+		}
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("no usable alternatives in response")
+	}
+	return alternatives, nil
+}
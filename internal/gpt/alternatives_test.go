@@ -0,0 +1,42 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAlternatives tests that alternatives are asked in one request, trimmed and
+// deduplicated, and capped to the number asked
+func TestAlternatives(t *testing.T) {
+	var req gogpt.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		content := `{"alternatives": [" Livrez plus vite ", "Livrez plus vite", "", "Accélérez vos livraisons", "Expédiez en un éclair"]}`
+		data, _ := json.Marshal(content)
+		w.Write([]byte(`{"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": ` + string(data) + `}}]}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Keys: []string{"sk-test-key"}})
+	clientCfg := gogpt.DefaultConfig("sk-test-key")
+	clientCfg.BaseURL = server.URL
+	clientCfg.HTTPClient = h.http
+	h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+	alternatives, err := h.Alternatives(context.Background(), "Ship faster", "French", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Livrez plus vite", "Accélérez vos livraisons"}, alternatives)
+	assert.Contains(t, req.Messages[0].Content, "write 2 alternative translations")
+	assert.Contains(t, req.Messages[len(req.Messages)-1].Content, "Ship faster")
+	assert.Equal(t, float32(alternativesTemperature), req.Temperature)
+
+	_, err = h.Alternatives(context.Background(), "Ship faster", "French", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package gpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// batchPayload returns the JSON sent to the model: an object of key to text when
+// keys are given, a plain array of texts otherwise
+func batchPayload(keys []string, texts []string) (string, error) {
+	var data []byte
+	var err error
+	if len(keys) == len(texts) {
+		items := make(map[string]string, len(keys))
+		for i, k := range keys {
+			items[k] = texts[i]
+		}
+		data, err = json.Marshal(items)
+	} else {
+		data, err = json.Marshal(texts)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// batchExampleMessages renders examples as a batch request and its answer, keyed
+// example1, example2, ... when the batch is keyed
+func batchExampleMessages(examples []Example, lang string, keyed bool) ([]gogpt.ChatCompletionMessage, error) {
+	texts := make([]string, len(examples))
+	var keys []string
+	for i, e := range examples {
+		texts[i] = e.Source
+		if keyed {
+			keys = append(keys, fmt.Sprintf("example%d", i+1))
+		}
+	}
+	payload, err := batchPayload(keys, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := batchUserPrompt
+	var translations interface{}
+	if keyed {
+		prompt = keyedBatchUserPrompt
+		byKey := make(map[string]string, len(examples))
+		for i, e := range examples {
+			byKey[keys[i]] = e.Target
+		}
+		translations = byKey
+	} else {
+		list := make([]string, len(examples))
+		for i, e := range examples {
+			list[i] = e.Target
+		}
+		translations = list
+	}
+	data, err := json.Marshal(map[string]interface{}{"translations": translations})
+	if err != nil {
+		return nil, err
+	}
+
+	return []gogpt.ChatCompletionMessage{
+		{Role: "user", Content: fmt.Sprintf(prompt, lang, payload)},
+		{Role: "assistant", Content: string(data)},
+	}, nil
+}
+
+// parseBatchResponse maps a batch response back to the order of texts. A response
+// keyed by the request keys is preferred; providers that answer with a plain array
+// are mapped by index instead.
+func parseBatchResponse(content string, keys []string, texts []string) ([]string, error) {
+	content = stripCodeFence(content)
+	candidates := []string{content}
+	// Some responses wrap the JSON in explanations or code fences
+	if startIdx, endIdx := strings.Index(content, "{"), strings.LastIndex(content, "}"); startIdx > 0 && endIdx > startIdx {
+		candidates = append(candidates, content[startIdx:endIdx+1])
+	}
+
+	for _, candidate := range candidates {
+		if len(keys) == len(texts) {
+			var keyed struct {
+				Translations map[string]string `json:"translations"`
+			}
+			if err := json.Unmarshal([]byte(candidate), &keyed); err == nil && len(keyed.Translations) > 0 {
+				translations := make([]string, len(keys))
+				complete := true
+				for i, k := range keys {
+					v, ok := keyed.Translations[k]
+					if !ok {
+						complete = false
+						break
+					}
+					translations[i] = v
+				}
+				if complete {
+					return translations, nil
+				}
+			}
+		}
+
+		// Index mapping: {"translations": [...]}
+		var indexed struct {
+			Translations []string `json:"translations"`
+		}
+		if err := json.Unmarshal([]byte(candidate), &indexed); err == nil && len(indexed.Translations) == len(texts) {
+			return indexed.Translations, nil
+		}
+	}
+
+	// Index mapping: a direct array
+	if strings.HasPrefix(content, "[") && strings.HasSuffix(content, "]") {
+		var translations []string
+		if err := json.Unmarshal([]byte(content), &translations); err != nil || len(translations) != len(texts) {
+			return nil, fmt.Errorf("failed to parse response as JSON array: %v", err)
+		}
+		return translations, nil
+	}
+
+	return nil, fmt.Errorf("response did not contain valid JSON with %d translations", len(texts))
+}
+
+// stripCodeFence returns the content of a response wrapped in a markdown code block,
+// as Gemini models tend to answer, e.g. "```json\n[...]\n```"
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") || !strings.HasSuffix(content, "```") || len(content) < 6 {
+		return content
+	}
+	inner := strings.TrimSuffix(content[3:], "```")
+	if newline := strings.Index(inner, "\n"); newline >= 0 && !strings.ContainsAny(inner[:newline], "{[") {
+		inner = inner[newline+1:]
+	}
+	return strings.TrimSpace(inner)
+}
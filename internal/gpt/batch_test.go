@@ -0,0 +1,74 @@
+package gpt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseBatchResponseKeyed tests that keyed responses are mapped by key, not by order
+func TestParseBatchResponseKeyed(t *testing.T) {
+	keys := []string{"greeting", "farewell"}
+	texts := []string{"Hello", "Goodbye"}
+
+	translations, err := parseBatchResponse(`{"translations": {"farewell": "Au revoir", "greeting": "Bonjour"}}`, keys, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+
+	// A keyed response wrapped in prose is still found
+	translations, err = parseBatchResponse("Sure!\n{\"translations\": {\"greeting\": \"Bonjour\", \"farewell\": \"Au revoir\"}}", keys, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+}
+
+// TestParseBatchResponseIndexFallback tests the index mapping used by providers that ignore keys
+func TestParseBatchResponseIndexFallback(t *testing.T) {
+	keys := []string{"greeting", "farewell"}
+	texts := []string{"Hello", "Goodbye"}
+
+	translations, err := parseBatchResponse(`{"translations": ["Bonjour", "Au revoir"]}`, keys, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+
+	translations, err = parseBatchResponse(`["Bonjour", "Au revoir"]`, nil, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+
+	// Missing keys and wrong lengths are rejected
+	_, err = parseBatchResponse(`{"translations": {"greeting": "Bonjour"}}`, keys, texts)
+	assert.Error(t, err)
+}
+
+// TestBatchExampleMessages tests examples are sent as a batch answered in the expected format
+func TestBatchExampleMessages(t *testing.T) {
+	examples := []Example{{Source: "Save changes", Target: "Enregistrer les modifications"}, {Source: "Cancel", Target: "Annuler"}}
+
+	for _, keyed := range []bool{true, false} {
+		messages, err := batchExampleMessages(examples, "French", keyed)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "user", messages[0].Role)
+		assert.Contains(t, messages[0].Content, "Save changes")
+
+		var keys []string
+		if keyed {
+			keys = []string{"example1", "example2"}
+		}
+		translations, err := parseBatchResponse(messages[1].Content, keys, []string{"Save changes", "Cancel"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Enregistrer les modifications", "Annuler"}, translations)
+	}
+}
+
+// TestParseBatchResponseCodeFence tests responses wrapped in markdown code blocks
+func TestParseBatchResponseCodeFence(t *testing.T) {
+	texts := []string{"Hello", "Goodbye"}
+
+	translations, err := parseBatchResponse("```json\n[\"Bonjour\", \"Au revoir\"]\n```", nil, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+
+	translations, err = parseBatchResponse("```\n{\"translations\": {\"a\": \"Bonjour\", \"b\": \"Au revoir\"}}\n```", []string{"a", "b"}, texts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+}
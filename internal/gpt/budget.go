@@ -0,0 +1,78 @@
+package gpt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExhausted is returned by every call once too many of the latest provider
+// calls failed, so that runs stop instead of retrying for hours
+var ErrBudgetExhausted = errors.New("error budget exhausted")
+
+// ErrCostLimit is returned by every call once the estimated cost of the run reached
+// Config.MaxCost. It matches ErrBudgetExhausted, so runs stop the same way.
+var ErrCostLimit error = costLimitError{}
+
+type costLimitError struct{}
+
+func (costLimitError) Error() string        { return "cost limit reached" }
+func (costLimitError) Is(target error) bool { return target == ErrBudgetExhausted }
+
+// ErrorBudget is the share of failing provider calls a run tolerates
+type ErrorBudget struct {
+	// Number of latest calls, retries included, the error rate is computed over;
+	// 0 disables the budget
+	Window int `json:"window"`
+	// Error rate (0-1) of a full window from which calls are refused
+	MaxErrorRate float64 `json:"maxErrorRate"`
+}
+
+// errorWindow keeps the outcomes of the latest calls
+type errorWindow struct {
+	failures []bool
+	next     int
+	full     bool
+	failed   int
+	// Set once the budget is exhausted, for good
+	exhausted bool
+}
+
+// recordOutcome adds the outcome of a call to the window of the handler. Callers hold the lock.
+func (h *Handler) recordOutcome(failed bool) {
+	b, w := h.cfg.ErrorBudget, &h.window
+	if b.Window <= 0 || w.exhausted {
+		return
+	}
+	if w.failures == nil {
+		w.failures = make([]bool, b.Window)
+	}
+	if w.full && w.failures[w.next] {
+		w.failed--
+	}
+	w.failures[w.next] = failed
+	if failed {
+		w.failed++
+	}
+	w.next = (w.next + 1) % b.Window
+	w.full = w.full || w.next == 0
+	if w.full && float64(w.failed) >= b.MaxErrorRate*float64(b.Window) {
+		w.exhausted = true
+	}
+}
+
+// checkBudget returns ErrBudgetExhausted once the error budget is exhausted, and
+// ErrCostLimit once the cost limit is reached
+func (h *Handler) checkBudget() error {
+	h.Lock()
+	defer h.Unlock()
+	if h.cfg.MaxCost > 0 {
+		cost, ok := Cost(h.Model(), h.usage.PromptTokens, h.usage.CompletionTokens)
+		if ok && cost >= h.cfg.MaxCost {
+			return fmt.Errorf("%w: an estimated $%.4f spent of the $%.2f limit", ErrCostLimit, cost, h.cfg.MaxCost)
+		}
+	}
+	if !h.window.exhausted {
+		return nil
+	}
+	return fmt.Errorf("%w: %d of the last %d provider calls failed", ErrBudgetExhausted, h.window.failed, h.cfg.ErrorBudget.Window)
+}
@@ -0,0 +1,76 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrorBudget tests that calls are refused once the error rate of a full window
+// reaches the budget, and for good
+func TestErrorBudget(t *testing.T) {
+	h := New(Config{Keys: []string{"key"}, ErrorBudget: ErrorBudget{Window: 4, MaxErrorRate: 0.5}})
+
+	h.recordError()
+	h.recordError()
+	h.recordUsage(context.Background(), gogpt.Usage{}, 0)
+	assert.NoError(t, h.checkBudget(), "the window isn't full yet")
+	h.recordUsage(context.Background(), gogpt.Usage{}, 0)
+	assert.True(t, errors.Is(h.checkBudget(), ErrBudgetExhausted))
+	assert.EqualError(t, h.checkBudget(), "error budget exhausted: 2 of the last 4 provider calls failed")
+
+	h.recordUsage(context.Background(), gogpt.Usage{}, 0)
+	assert.Error(t, h.checkBudget())
+
+	// Failures sliding out of the window are forgotten
+	h = New(Config{Keys: []string{"key"}, ErrorBudget: ErrorBudget{Window: 4, MaxErrorRate: 0.5}})
+	h.recordError()
+	for i := 0; i < 6; i++ {
+		h.recordUsage(context.Background(), gogpt.Usage{}, 0)
+	}
+	h.recordError()
+	assert.NoError(t, h.checkBudget())
+
+	// Disabled budgets never trip
+	h = New(Config{Keys: []string{"key"}})
+	for i := 0; i < 30; i++ {
+		h.recordError()
+	}
+	assert.NoError(t, h.checkBudget())
+}
+
+// TestCostLimit tests that calls are refused once the estimated cost reaches the limit,
+// and never for models without a known price
+func TestCostLimit(t *testing.T) {
+	h := New(Config{Keys: []string{"key"}, Model: "gpt-4o-mini", MaxCost: 0.01})
+	h.recordUsage(context.Background(), gogpt.Usage{PromptTokens: 20_000, CompletionTokens: 5_000}, 0)
+	assert.NoError(t, h.checkBudget())
+	h.recordUsage(context.Background(), gogpt.Usage{PromptTokens: 20_000, CompletionTokens: 5_000}, 0)
+	err := h.checkBudget()
+	assert.True(t, errors.Is(err, ErrCostLimit))
+	assert.True(t, errors.Is(err, ErrBudgetExhausted), "runs stop as for the error budget")
+	assert.EqualError(t, err, "cost limit reached: an estimated $0.0120 spent of the $0.01 limit")
+
+	h = New(Config{Keys: []string{"key"}, Model: "custom-model", MaxCost: 0.01})
+	h.recordUsage(context.Background(), gogpt.Usage{PromptTokens: 1_000_000}, 0)
+	assert.NoError(t, h.checkBudget())
+}
+
+// TestUsageByLanguage tests that usage is accumulated by the target language of the
+// calls, and in total
+func TestUsageByLanguage(t *testing.T) {
+	h := New(Config{Keys: []string{"key"}})
+	h.recordUsage(WithLanguages(context.Background(), "en", "fr"), gogpt.Usage{PromptTokens: 10, CompletionTokens: 4}, 0)
+	h.recordUsage(WithLanguages(context.Background(), "en", "fr"), gogpt.Usage{PromptTokens: 6, CompletionTokens: 2}, 0)
+	h.recordUsage(WithLanguages(context.Background(), "en", "de"), gogpt.Usage{PromptTokens: 8, CompletionTokens: 3}, 0)
+	h.recordUsage(context.Background(), gogpt.Usage{PromptTokens: 1, CompletionTokens: 1}, 0)
+
+	assert.Equal(t, map[string]Usage{
+		"fr": {Requests: 2, PromptTokens: 16, CompletionTokens: 6},
+		"de": {Requests: 1, PromptTokens: 8, CompletionTokens: 3},
+	}, h.UsageByLanguage())
+	assert.Equal(t, Usage{Requests: 4, PromptTokens: 25, CompletionTokens: 10}, h.Usage())
+}
@@ -0,0 +1,52 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// chat sends a chat completion, retrying rate limits, server errors and timeouts
+// the same way the translation calls do, and returns the trimmed message content
+func (h *Handler) chat(ctx context.Context, req gogpt.ChatCompletionRequest) (string, error) {
+	if err := h.screenMessages(req.Messages); err != nil {
+		return "", err
+	}
+	if len(h.clients) == 0 {
+		return "", fmt.Errorf("chat completions are %w %s", errUnsupported, h.Provider())
+	}
+
+	var content string
+	err := h.withRetries(ctx, func(ctx context.Context, client *Client) error {
+		choice, err := h.complete(ctx, client, req)
+		if err != nil {
+			return err
+		}
+		content = strings.TrimSpace(choice.Message.Content)
+		return nil
+	})
+	return content, err
+}
+
+// complete sends the chat completion req with client, records its usage and returns its
+// first choice. A response without choices is a bad response, and a completion refused
+// by the provider or the model a *PolicyError.
+func (h *Handler) complete(ctx context.Context, client *Client, req gogpt.ChatCompletionRequest) (gogpt.ChatCompletionChoice, error) {
+	start := time.Now()
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return gogpt.ChatCompletionChoice{}, err
+	}
+	h.recordUsage(ctx, resp.Usage, time.Since(start))
+
+	if len(resp.Choices) == 0 {
+		return gogpt.ChatCompletionChoice{}, &badResponse{fmt.Errorf("no choices in response")}
+	}
+	if refusal := filtered(resp.Choices[0]); refusal != nil {
+		return gogpt.ChatCompletionChoice{}, refusal
+	}
+	return resp.Choices[0], nil
+}
@@ -0,0 +1,72 @@
+package gpt
+
+import "context"
+
+// batchMaxTokens is the completion token cap of batches when none is configured
+const batchMaxTokens = 2048
+
+// defaultContextWindow is the context window assumed for models not listed in ContextWindows
+const defaultContextWindow = 16_384
+
+// ContextWindows lists the tokens the known models can take per request, prompt and
+// completion together
+var ContextWindows = map[string]int{
+	"gpt-4o-2024-11-20": 128_000,
+	"gpt-4o":            128_000,
+	"gpt-4o-mini":       128_000,
+	"gpt-4.1":           1_047_576,
+	"gpt-4.1-mini":      1_047_576,
+	"gemini-2.0-flash":  1_048_576,
+	"gemini-2.5-flash":  1_048_576,
+	"gemini-2.5-pro":    1_048_576,
+}
+
+// ItemTokens estimates the completion tokens a text of a batch takes once translated,
+// with its key and JSON punctuation. Translations may take twice the tokens of their
+// source, when the target script is tokenized less efficiently.
+func ItemTokens(key, text string) int {
+	return 2*CountTokens(text) + CountTokens(key) + 4
+}
+
+// BatchLimit returns the estimated tokens, as counted by ItemTokens, the texts of a
+// batch may take so that their translations fit in the completion token cap, leaving a
+// tenth of it spare, and the request in the context window of the model
+func (h *Handler) BatchLimit() int {
+	if h.cfg.BatchTokens > 0 {
+		return h.cfg.BatchTokens
+	}
+	completion := h.maxTokens(batchMaxTokens)
+	limit := completion * 9 / 10
+
+	window, ok := ContextWindows[h.Model()]
+	if !ok {
+		window = defaultContextWindow
+	}
+	// Sent, texts take no more tokens than ItemTokens estimates for their translations
+	if room := window - completion - PromptOverhead(true); room < limit {
+		limit = room
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return limit
+}
+
+// splitBatch translates both halves of a batch whose translations didn't fit in the
+// completion token cap, in order
+func (h *Handler) splitBatch(ctx context.Context, keys []string, texts []string, lang string, examples []Example) ([]string, error) {
+	half := len(texts) / 2
+	firstKeys, secondKeys := keys, keys
+	if len(keys) == len(texts) {
+		firstKeys, secondKeys = keys[:half], keys[half:]
+	}
+	first, err := h.batchTranslateMasked(ctx, firstKeys, texts[:half], lang, examples)
+	if err != nil {
+		return nil, err
+	}
+	second, err := h.batchTranslateMasked(ctx, secondKeys, texts[half:], lang, examples)
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
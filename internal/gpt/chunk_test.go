@@ -0,0 +1,58 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBatchLimit tests the token budget of batches
+func TestBatchLimit(t *testing.T) {
+	assert.Equal(t, 1843, New(Config{}).BatchLimit())
+	assert.Equal(t, 7200, New(Config{MaxTokens: 8000}).BatchLimit())
+	assert.Equal(t, 500, New(Config{MaxTokens: 8000, BatchTokens: 500}).BatchLimit())
+
+	// The prompt must fit in the context window too
+	h := New(Config{Provider: ProviderOpenAI, Model: "small-model", MaxTokens: 15_000})
+	assert.Equal(t, defaultContextWindow-15_000-PromptOverhead(true), h.BatchLimit())
+
+	assert.Greater(t, ItemTokens("home.title", "Welcome home"), 2*CountTokens("Welcome home"))
+}
+
+// TestTruncatedBatch tests that a batch cut at the token cap is translated again in halves
+func TestTruncatedBatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req gogpt.ChatCompletionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt := req.Messages[len(req.Messages)-1].Content
+		if strings.Contains(prompt, "one") && strings.Contains(prompt, "two") {
+			w.Write([]byte(`{"choices": [{"finish_reason": "length", "message": {"role": "assistant", "content": "{\"translations\": [\"un"}}]}`))
+			return
+		}
+		translation := "un"
+		if strings.Contains(prompt, "two") {
+			translation = "deux"
+		}
+		w.Write([]byte(`{"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": "{\"translations\": [\"` + translation + `\"]}"}}]}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Keys: []string{"sk-test-key"}})
+	clientCfg := gogpt.DefaultConfig("sk-test-key")
+	clientCfg.BaseURL = server.URL
+	clientCfg.HTTPClient = h.http
+	h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+	translations, err := h.BatchTranslate(context.Background(), nil, []string{"one", "two"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"un", "deux"}, translations)
+	assert.Equal(t, 3, requests)
+}
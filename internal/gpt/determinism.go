@@ -0,0 +1,53 @@
+package gpt
+
+import (
+	"math"
+
+	"github.com/pandodao/i18n-cli/internal/safety"
+)
+
+// zeroTemperature asks for a temperature of 0: the client omits a zero temperature
+// from requests, which the API then reads as its default of 1
+const zeroTemperature = math.SmallestNonzeroFloat32
+
+// translationTemperature is the temperature of translation requests outside of
+// deterministic mode, low to keep the wording close to the source
+const translationTemperature = 0.1
+
+// temperature returns the temperature of a translation request: 0 in deterministic
+// mode, else the configured one or t
+func (h *Handler) temperature(t float32) float32 {
+	if h.cfg.Deterministic {
+		return zeroTemperature
+	}
+	if h.cfg.Temperature != nil {
+		t = *h.cfg.Temperature
+	}
+	if t == 0 {
+		return zeroTemperature
+	}
+	return t
+}
+
+// seed returns the sampling seed sent with requests: the configured one, 0 in
+// deterministic mode without one, nil otherwise
+func (h *Handler) seed() *int {
+	switch {
+	case h.cfg.Seed != nil:
+		seed := *h.cfg.Seed
+		return &seed
+	case h.cfg.Deterministic:
+		seed := 0
+		return &seed
+	}
+	return nil
+}
+
+// tag returns the safe mode delimiter tag of text: random, or derived from the seed
+// in deterministic mode so that reruns send identical prompts
+func (h *Handler) tag(text string) string {
+	if h.cfg.Deterministic {
+		return safety.SeededTag(*h.seed(), text)
+	}
+	return safety.Tag()
+}
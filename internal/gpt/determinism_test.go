@@ -0,0 +1,45 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeterministic tests the temperature, seed and prompts sent in deterministic mode
+func TestDeterministic(t *testing.T) {
+	bodies := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Bonjour"}}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	seed := 42
+	h := New(Config{Provider: ProviderOllama, Endpoint: server.URL, Safe: true, Deterministic: true, Seed: &seed})
+	for i := 0; i < 2; i++ {
+		_, err := h.Translate(ctx, "Hello", "French")
+		assert.NoError(t, err)
+	}
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0]["messages"], bodies[1]["messages"])
+	assert.Equal(t, float64(42), bodies[0]["seed"])
+	assert.Less(t, bodies[0]["temperature"], 0.001)
+
+	// Without deterministic mode no seed is sent and tags are random
+	bodies = bodies[:0]
+	h = New(Config{Provider: ProviderOllama, Endpoint: server.URL, Safe: true})
+	for i := 0; i < 2; i++ {
+		_, err := h.Translate(ctx, "Hello", "French")
+		assert.NoError(t, err)
+	}
+	assert.NotContains(t, bodies[0], "seed")
+	assert.NotEqual(t, bodies[0]["messages"], bodies[1]["messages"])
+}
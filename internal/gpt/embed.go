@@ -0,0 +1,73 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// EmbeddingModel is the model used to embed texts for translation memory matching
+const EmbeddingModel = gogpt.SmallEmbedding3
+
+// embedBatchSize is the number of texts embedded per request
+const embedBatchSize = 100
+
+// Embed returns the embedding vectors of texts, in order. Embeddings are not counted
+// in the chat usage, they are billed separately and much cheaper.
+func (h *Handler) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if h.Provider() != ProviderOpenAI {
+		return nil, fmt.Errorf("embeddings are %w %s", errUnsupported, h.Provider())
+	}
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := h.embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (h *Handler) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := h.screen(texts...); err != nil {
+		return nil, err
+	}
+
+	// Newlines are documented to degrade embedding quality
+	input := make([]string, len(texts))
+	for i, text := range texts {
+		input[i] = strings.ReplaceAll(text, "\n", " ")
+	}
+
+	var vectors [][]float32
+	err := h.withRetries(ctx, func(ctx context.Context, client *Client) error {
+		resp, err := client.CreateEmbeddings(ctx, gogpt.EmbeddingRequestStrings{Input: input, Model: EmbeddingModel})
+		if err != nil {
+			return err
+		}
+
+		vectors = make([][]float32, len(texts))
+		for _, d := range resp.Data {
+			if d.Index >= 0 && d.Index < len(vectors) {
+				vectors[d.Index] = d.Embedding
+			}
+		}
+		for i, v := range vectors {
+			if v == nil {
+				return &badResponse{fmt.Errorf("no embedding returned for text %d", i)}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating embeddings: %w", err)
+	}
+	return vectors, nil
+}
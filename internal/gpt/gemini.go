@@ -0,0 +1,17 @@
+package gpt
+
+import "strings"
+
+// DefaultGeminiModel is the Gemini model used for translations
+const DefaultGeminiModel = "gemini-2.0-flash"
+
+// defaultGeminiEndpoint is the OpenAI-compatible API of Gemini
+const defaultGeminiEndpoint = "https://generativelanguage.googleapis.com/v1beta/openai"
+
+// geminiBaseURL returns the base URL of a Gemini OpenAI-compatible endpoint
+func geminiBaseURL(endpoint string) string {
+	if endpoint == "" {
+		endpoint = defaultGeminiEndpoint
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
@@ -0,0 +1,31 @@
+package gpt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGeminiBatch tests that Gemini is called through its OpenAI-compatible API with
+// the configured key and model, and that fenced batch answers are parsed
+func TestGeminiBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/openai/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer gemini-key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "` + "```json\\n" + `{\"translations\": {\"a\": \"Bonjour\", \"b\": \"Au revoir\"}}` + "\\n```" + `"}}]}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Provider: ProviderGemini, Endpoint: server.URL + "/v1beta/openai/", Keys: []string{"gemini-key"}})
+	assert.Equal(t, DefaultGeminiModel, h.Model())
+
+	translations, err := h.BatchTranslate(context.Background(), []string{"a", "b"}, []string{"Hello", "Goodbye"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour", "Au revoir"}, translations)
+
+	_, known := Cost(h.Model(), 1000, 1000)
+	assert.True(t, known)
+}
@@ -0,0 +1,71 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+const gradeSystemPrompt = "You are a senior localization reviewer. You compare an existing translation of a source text with a fresh machine translation of the same text. Rate the existing translation from 1 (wrong or misleading) to 5 (as good as or better than the fresh one), considering meaning, fluency, and terminology. Return ONLY a JSON object in this exact format: {\"score\": <1-5>, \"reason\": \"short reason\"}"
+
+const gradeUserPrompt = "Target language: %s\n\nSource text:\n%s\n\nExisting translation:\n%s\n\nFresh translation:\n%s"
+
+const adequacySystemPrompt = "You are a senior localization reviewer. You compare a machine translation of a source text with a professional reference translation of the same text. Rate the machine translation from 1 (wrong or misleading) to 5 (conveys the meaning as fully and naturally as the reference), considering meaning, fluency, and terminology; wording that differs from the reference but means the same is fine. Return ONLY a JSON object in this exact format: {\"score\": <1-5>, \"reason\": \"short reason\"}"
+
+const adequacyUserPrompt = "Target language: %s\n\nSource text:\n%s\n\nReference translation:\n%s\n\nMachine translation:\n%s"
+
+const equivalenceSystemPrompt = "You are a senior localization reviewer. You compare a source text with the back-translation of its translation, made by translating the translation back into the language of the source. Rate from 1 (the meaning changed: information lost, added or reversed) to 5 (the same meaning), ignoring differences of wording, word order and style. Return ONLY a JSON object in this exact format: {\"score\": <1-5>, \"reason\": \"short reason\"}"
+
+const equivalenceUserPrompt = "Language of the translation: %s\n\nSource text:\n%s\n\nBack-translation:\n%s"
+
+// Grade is a model judgement of an existing translation
+type Grade struct {
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// Grade asks the model to rate a stored translation against a fresh one
+func (h *Handler) Grade(ctx context.Context, source, stored, fresh, lang string) (Grade, error) {
+	return h.grade(ctx, gradeSystemPrompt, fmt.Sprintf(gradeUserPrompt, lang, source, stored, fresh))
+}
+
+// Adequacy asks the model to rate a machine translation against a reference translation
+func (h *Handler) Adequacy(ctx context.Context, source, translation, reference, lang string) (Grade, error) {
+	return h.grade(ctx, adequacySystemPrompt, fmt.Sprintf(adequacyUserPrompt, lang, source, reference, translation))
+}
+
+// Equivalence asks the model to rate whether the back-translation of a translation into
+// lang keeps the meaning of its source
+func (h *Handler) Equivalence(ctx context.Context, source, back, lang string) (Grade, error) {
+	return h.grade(ctx, equivalenceSystemPrompt, fmt.Sprintf(equivalenceUserPrompt, lang, source, back))
+}
+
+func (h *Handler) grade(ctx context.Context, system, user string) (Grade, error) {
+	content, err := h.chat(ctx, gogpt.ChatCompletionRequest{
+		Model: h.Model(),
+		Messages: []gogpt.ChatCompletionMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Temperature: zeroTemperature,
+		Seed:        h.seed(),
+		MaxTokens:   256,
+		ResponseFormat: &gogpt.ChatCompletionResponseFormat{
+			Type: gogpt.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return Grade{}, err
+	}
+
+	var grade Grade
+	if err := json.Unmarshal([]byte(content), &grade); err != nil {
+		return Grade{}, fmt.Errorf("invalid grade response: %w", err)
+	}
+	if grade.Score < 1 || grade.Score > 5 {
+		return Grade{}, fmt.Errorf("grade score %d out of range", grade.Score)
+	}
+	return grade, nil
+}
@@ -0,0 +1,42 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGrade tests that grades are parsed from the JSON answer of the model, and that
+// invalid answers and scores out of range are errors
+func TestGrade(t *testing.T) {
+	var content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := json.Marshal(content)
+		w.Write([]byte(`{"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": ` + string(data) + `}}]}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Keys: []string{"sk-test-key"}, Retry: RetryPolicy{MaxAttempts: 1}})
+	clientCfg := gogpt.DefaultConfig("sk-test-key")
+	clientCfg.BaseURL = server.URL
+	clientCfg.HTTPClient = h.http
+	h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+	content = ` {"score": 4, "reason": "minor wording"} `
+	grade, err := h.Grade(context.Background(), "Save", "Enregistrer", "Sauvegarder", "French")
+	assert.NoError(t, err)
+	assert.Equal(t, Grade{Score: 4, Reason: "minor wording"}, grade)
+
+	content = `{"score": 0, "reason": "none"}`
+	_, err = h.Adequacy(context.Background(), "Save", "Enregistrer", "Sauvegarder", "French")
+	assert.EqualError(t, err, "grade score 0 out of range")
+
+	content = `Score: 5`
+	_, err = h.Equivalence(context.Background(), "Save", "Save", "French")
+	assert.ErrorContains(t, err, "invalid grade response")
+}
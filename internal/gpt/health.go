@@ -0,0 +1,76 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// KeyStatus is the health of a single API key
+type KeyStatus struct {
+	// Masked API key, safe to print
+	Key            string
+	Model          string
+	ModelAvailable bool
+	Latency        time.Duration
+	RateLimit      gogpt.RateLimitHeaders
+	Err            error
+}
+
+// Ping checks every configured key: whether the model is available to it, the
+// latency of a minimal completion, and the rate limit headroom reported by the API
+func (h *Handler) Ping(ctx context.Context) []KeyStatus {
+	switch h.Provider() {
+	case ProviderLibreTranslate:
+		return []KeyStatus{h.pingLibre(ctx)}
+	case ProviderWebhook:
+		return []KeyStatus{h.pingWebhook(ctx)}
+	}
+
+	statuses := make([]KeyStatus, len(h.clients))
+	for i, client := range h.clients {
+		status := KeyStatus{Key: maskKey(h.cfg.Keys[i]), Model: h.Model()}
+
+		pingCtx := ctx
+		cancel := func() {}
+		if h.cfg.Timeout > 0 {
+			pingCtx, cancel = context.WithTimeout(ctx, h.cfg.Timeout)
+		}
+
+		if _, err := client.GetModel(pingCtx, h.Model()); err != nil {
+			cancel()
+			status.Err = fmt.Errorf("model lookup failed: %w", err)
+			statuses[i] = status
+			continue
+		}
+		status.ModelAvailable = true
+
+		start := time.Now()
+		resp, err := client.CreateChatCompletion(pingCtx, gogpt.ChatCompletionRequest{
+			Model: h.Model(),
+			Messages: []gogpt.ChatCompletionMessage{
+				{Role: "user", Content: "ping"},
+			},
+			MaxTokens: 1,
+		})
+		status.Latency = time.Since(start)
+		cancel()
+		if err != nil {
+			status.Err = fmt.Errorf("completion failed: %w", err)
+		} else {
+			status.RateLimit = resp.GetRateLimitHeaders()
+		}
+
+		statuses[i] = status
+	}
+	return statuses
+}
+
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
@@ -0,0 +1,67 @@
+package gpt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPing tests that every key is checked for the model and a completion, reporting
+// the rate limit headers, masked keys and the step that failed
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/models/"):
+			if key == "sk-unknown-model" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error": {"message": "The model does not exist"}}`))
+				return
+			}
+			w.Write([]byte(`{"id": "gpt-4o", "object": "model"}`))
+		case r.URL.Path == "/chat/completions":
+			if key == "sk-no-completion" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"error": {"message": "You exceeded your current quota"}}`))
+				return
+			}
+			w.Header().Set("x-ratelimit-limit-requests", "500")
+			w.Header().Set("x-ratelimit-remaining-requests", "499")
+			w.Write([]byte(`{"choices": [{"finish_reason": "length", "message": {"role": "assistant", "content": "p"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	keys := []string{"sk-working-key", "sk-unknown-model", "sk-no-completion"}
+	h := New(Config{Keys: keys, Model: "gpt-4o"})
+	for i, key := range keys {
+		clientCfg := gogpt.DefaultConfig(key)
+		clientCfg.BaseURL = server.URL
+		clientCfg.HTTPClient = h.http
+		h.clients[i].Client = gogpt.NewClientWithConfig(clientCfg)
+	}
+
+	statuses := h.Ping(context.Background())
+	assert.Len(t, statuses, 3)
+
+	assert.Equal(t, "sk-...-key", statuses[0].Key)
+	assert.Equal(t, "gpt-4o", statuses[0].Model)
+	assert.True(t, statuses[0].ModelAvailable)
+	assert.NoError(t, statuses[0].Err)
+	assert.Equal(t, 500, statuses[0].RateLimit.LimitRequests)
+	assert.Equal(t, 499, statuses[0].RateLimit.RemainingRequests)
+
+	assert.False(t, statuses[1].ModelAvailable)
+	assert.ErrorContains(t, statuses[1].Err, "model lookup failed")
+
+	assert.True(t, statuses[2].ModelAvailable)
+	assert.ErrorContains(t, statuses[2].Err, "completion failed")
+	assert.Equal(t, "****", maskKey("sk-short"))
+}
@@ -0,0 +1,224 @@
+package gpt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/safety"
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// MaxJobRequests is the number of requests the OpenAI Batch API accepts in one batch
+const MaxJobRequests = 50000
+
+// Statuses of batch jobs that won't change anymore
+const (
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobExpired   = "expired"
+	JobCancelled = "cancelled"
+)
+
+// JobRequest is a text to translate in a batch job, identified by ID
+type JobRequest struct {
+	ID string
+	// Key of the text, for system prompt templates and key context
+	Key      string
+	Text     string
+	Lang     string
+	Examples []Example
+	// Terminology the translation must follow and instructions, for the system prompt
+	Glossary     string
+	Instructions string
+	// Description and screenshot of the key, sent as context when key context is enabled
+	Description string
+	Screenshot  string
+}
+
+// Job is a batch of translations submitted to the OpenAI Batch API, answered within
+// 24 hours at half the price of realtime requests
+type Job struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+	// Files holding the answers and the errors, once the job is done
+	OutputFileID string `json:"outputFileId,omitempty"`
+	ErrorFileID  string `json:"errorFileId,omitempty"`
+	// Delimiter tags of the texts by request ID, in safe mode
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Done reports whether the job won't make progress anymore. Expired jobs may still
+// have answered some of their requests.
+func (j Job) Done() bool {
+	switch j.Status {
+	case JobCompleted, JobFailed, JobExpired, JobCancelled:
+		return true
+	}
+	return false
+}
+
+// SubmitJob uploads the translation requests and starts a batch job
+func (h *Handler) SubmitJob(ctx context.Context, requests []JobRequest) (Job, error) {
+	if h.Provider() != ProviderOpenAI {
+		return Job{}, fmt.Errorf("batch jobs are %w %s", errUnsupported, h.Provider())
+	}
+	if len(requests) > MaxJobRequests {
+		return Job{}, fmt.Errorf("a batch job takes at most %d requests, got %d", MaxJobRequests, len(requests))
+	}
+
+	tags := map[string]string{}
+	upload := gogpt.UploadBatchFileRequest{FileName: "i18n-cli-translations.jsonl"}
+	for _, r := range requests {
+		if err := h.screen(r.Text); err != nil {
+			return Job{}, err
+		}
+		reqCtx := WithInstructions(WithGlossary(WithKey(ctx, r.Key), r.Glossary), r.Instructions)
+		if r.Description != "" {
+			reqCtx = WithDescriptions(reqCtx, map[string]string{r.Key: r.Description})
+		}
+		if r.Screenshot != "" {
+			reqCtx = WithScreenshots(reqCtx, map[string]string{r.Key: r.Screenshot})
+		}
+		req, tag := h.translationRequest(reqCtx, h.cfg.Protect.Protect(r.Text), r.Lang, h.screenExamples(r.Examples), nil)
+		if tag != "" {
+			tags[r.ID] = tag
+		}
+		upload.AddChatCompletion(r.ID, req)
+	}
+
+	resp, err := h.clients[0].CreateBatchWithUploadFile(ctx, gogpt.CreateBatchWithUploadFileRequest{
+		Endpoint:               gogpt.BatchEndpointChatCompletions,
+		CompletionWindow:       "24h",
+		UploadBatchFileRequest: upload,
+	})
+	if err != nil {
+		h.recordError()
+		return Job{}, fmt.Errorf("error creating batch job: %w", err)
+	}
+	job := jobFromBatch(resp.Batch)
+	if len(tags) > 0 {
+		job.Tags = tags
+	}
+	return job, nil
+}
+
+// PollJob returns the progress of a batch job
+func (h *Handler) PollJob(ctx context.Context, job Job) (Job, error) {
+	if len(h.clients) == 0 {
+		return job, fmt.Errorf("batch jobs are %w %s", errUnsupported, h.Provider())
+	}
+	resp, err := h.clients[0].RetrieveBatch(ctx, job.ID)
+	if err != nil {
+		h.recordError()
+		return job, fmt.Errorf("error retrieving batch job %s: %w", job.ID, err)
+	}
+	polled := jobFromBatch(resp.Batch)
+	polled.Tags = job.Tags
+	return polled, nil
+}
+
+func jobFromBatch(b gogpt.Batch) Job {
+	job := Job{
+		ID:        b.ID,
+		Status:    b.Status,
+		Total:     b.RequestCounts.Total,
+		Completed: b.RequestCounts.Completed,
+		Failed:    b.RequestCounts.Failed,
+	}
+	if b.OutputFileID != nil {
+		job.OutputFileID = *b.OutputFileID
+	}
+	if b.ErrorFileID != nil {
+		job.ErrorFileID = *b.ErrorFileID
+	}
+	return job
+}
+
+// jobLine is a line of the output or error file of a batch job
+type jobLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int                          `json:"status_code"`
+		Body       gogpt.ChatCompletionResponse `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// JobResults downloads the answers of a done job: the translations and the errors
+// by request ID. Requests missing from both were not answered.
+func (h *Handler) JobResults(ctx context.Context, job Job) (map[string]string, map[string]error, error) {
+	if len(h.clients) == 0 {
+		return nil, nil, fmt.Errorf("batch jobs are %w %s", errUnsupported, h.Provider())
+	}
+	translations := map[string]string{}
+	failures := map[string]error{}
+	for _, fileID := range []string{job.OutputFileID, job.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		content, err := h.clients[0].GetFileContent(ctx, fileID)
+		if err != nil {
+			h.recordError()
+			return nil, nil, fmt.Errorf("error downloading results of batch job %s: %w", job.ID, err)
+		}
+		data := new(bytes.Buffer)
+		_, err = data.ReadFrom(content)
+		content.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		scanner := bufio.NewScanner(data)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var line jobLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				return nil, nil, fmt.Errorf("invalid result line: %w", err)
+			}
+			h.jobResult(job, line, translations, failures)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return translations, failures, nil
+}
+
+// jobResult records the translation or error answered to a request of job
+func (h *Handler) jobResult(job Job, line jobLine, translations map[string]string, failures map[string]error) {
+	switch {
+	case line.Error != nil:
+		failures[line.CustomID] = fmt.Errorf("%s: %s", line.Error.Code, line.Error.Message)
+	case line.Response == nil:
+		failures[line.CustomID] = fmt.Errorf("no response")
+	case line.Response.StatusCode != 200:
+		failures[line.CustomID] = fmt.Errorf("status %d", line.Response.StatusCode)
+	case len(line.Response.Body.Choices) == 0:
+		failures[line.CustomID] = fmt.Errorf("no choices in response")
+	default:
+		h.recordUsage(context.Background(), line.Response.Body.Usage, time.Duration(0))
+		result := strings.TrimSpace(line.Response.Body.Choices[0].Message.Content)
+		if tag := job.Tags[line.CustomID]; tag != "" {
+			result = safety.Unwrap(result, tag)
+		}
+		if result == "" {
+			failures[line.CustomID] = fmt.Errorf("received empty translation")
+			return
+		}
+		translations[line.CustomID] = result
+	}
+}
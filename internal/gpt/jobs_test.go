@@ -0,0 +1,70 @@
+package gpt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJobs tests that batch jobs upload one chat completion per text, report their
+// progress and return translations and failures by request ID
+func TestJobs(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, _, err := r.FormFile("file")
+			assert.NoError(t, err)
+			data, _ := io.ReadAll(file)
+			uploaded = string(data)
+			w.Write([]byte(`{"id": "file-in", "purpose": "batch"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			w.Write([]byte(`{"id": "batch-1", "status": "validating", "input_file_id": "file-in", "request_counts": {"total": 2}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/batches/batch-1":
+			w.Write([]byte(`{"id": "batch-1", "status": "completed", "output_file_id": "file-out", "error_file_id": "file-err", "request_counts": {"total": 2, "completed": 1, "failed": 1}}`))
+		case r.URL.Path == "/files/file-out/content":
+			w.Write([]byte(`{"custom_id": "0", "response": {"status_code": 200, "body": {"choices": [{"message": {"role": "assistant", "content": " Bonjour "}}], "usage": {"prompt_tokens": 10, "completion_tokens": 2}}}}` + "\n"))
+		case r.URL.Path == "/files/file-err/content":
+			w.Write([]byte(`{"custom_id": "1", "error": {"code": "rate_limit", "message": "too many tokens"}}` + "\n"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	h := New(Config{Keys: []string{"key"}})
+	clientCfg := gogpt.DefaultConfig("key")
+	clientCfg.BaseURL = server.URL
+	h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+	ctx := context.Background()
+	job, err := h.SubmitJob(ctx, []JobRequest{{ID: "0", Text: "Hello", Lang: "French"}, {ID: "1", Text: "Goodbye", Lang: "French"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "batch-1", job.ID)
+	assert.False(t, job.Done())
+	lines := strings.Split(strings.TrimSpace(uploaded), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"custom_id":"0"`)
+	assert.Contains(t, lines[1], "Goodbye")
+
+	job, err = h.PollJob(ctx, job)
+	assert.NoError(t, err)
+	assert.True(t, job.Done())
+	assert.Equal(t, "file-out", job.OutputFileID)
+
+	translations, failures, err := h.JobResults(ctx, job)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"0": "Bonjour"}, translations)
+	assert.Len(t, failures, 1)
+	assert.Contains(t, failures["1"].Error(), "rate_limit")
+	assert.Equal(t, 10, h.Usage().PromptTokens)
+
+	_, err = New(Config{Provider: ProviderOllama}).SubmitJob(ctx, nil)
+	assert.Error(t, err)
+}
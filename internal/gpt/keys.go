@@ -0,0 +1,178 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+const (
+	// rateLimitBench is how long a rate-limited key is benched when the provider doesn't
+	// say; it doubles with every consecutive rate limit of the key
+	rateLimitBench = 15 * time.Second
+	// authBench is how long a key the provider rejected as unauthorized is benched
+	authBench = 10 * time.Minute
+	// maxBench is the longest a rate-limited key is benched
+	maxBench = 5 * time.Minute
+	// errorRateWeight is the weight of the latest outcome of a key in its error rate
+	errorRateWeight = 0.2
+	// minKeyWeight is the share of the calls a key failing every call still gets, so
+	// that it can recover
+	minKeyWeight = 0.1
+)
+
+// KeyHealth is the health of an API key over the run
+type KeyHealth struct {
+	// Masked API key, safe to print
+	Key      string
+	Requests int
+	Failures int
+	// Decaying error rate (0-1) of the latest calls, which weights the share of the
+	// calls the key gets
+	ErrorRate float64
+	// Times the key was benched for rate limits and rejections
+	RateLimited  int
+	Unauthorized int
+	// Time until which the key is benched, zero when it isn't
+	BenchedUntil time.Time
+}
+
+// keyState is the health of a key and its place in the rotation
+type keyState struct {
+	KeyHealth
+	// Consecutive rate limits, doubling the bench each time
+	strikes int
+	// Credit of the smooth weighted round-robin
+	credit float64
+}
+
+// weight returns the share of the calls the key should get, from its error rate
+func (k *keyState) weight() float64 {
+	if w := 1 - k.ErrorRate; w > minKeyWeight {
+		return w
+	}
+	return minKeyWeight
+}
+
+// pickClient returns the client of the next call: keys are rotated in proportion to
+// their health, leaving out the benched ones. When every key is benched, the one
+// back the soonest is used.
+func (h *Handler) pickClient() *Client {
+	h.Lock()
+	defer h.Unlock()
+	h.initKeys()
+	now := h.now()
+	total := 0.0
+	picked := -1
+	for i := range h.keys {
+		k := &h.keys[i]
+		if now.Before(k.BenchedUntil) {
+			continue
+		}
+		w := k.weight()
+		k.credit += w
+		total += w
+		if picked < 0 || k.credit > h.keys[picked].credit {
+			picked = i
+		}
+	}
+	if picked < 0 {
+		for i := range h.keys {
+			if picked < 0 || h.keys[i].BenchedUntil.Before(h.keys[picked].BenchedUntil) {
+				picked = i
+			}
+		}
+		return h.clients[picked]
+	}
+	h.keys[picked].credit -= total
+	return h.clients[picked]
+}
+
+// reportKey records the outcome of a call with client: rate limits bench the key for
+// as long as the provider asked, or increasingly long, and rejections of the key for
+// authBench. It reports whether the key was benched while another one is available,
+// in which case the call can be retried right away.
+func (h *Handler) reportKey(client *Client, err error, hint *retryHint) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	h.Lock()
+	defer h.Unlock()
+	h.initKeys()
+	k := &h.keys[client.id]
+	k.Requests++
+	failed := 0.0
+	if err != nil {
+		k.Failures++
+		failed = 1
+	}
+	k.ErrorRate += errorRateWeight * (failed - k.ErrorRate)
+
+	var apiErr *gogpt.APIError
+	if !errors.As(err, &apiErr) {
+		if err == nil {
+			k.strikes = 0
+		}
+		return false
+	}
+	now := h.now()
+	switch apiErr.HTTPStatusCode {
+	case 429:
+		k.RateLimited++
+		k.strikes++
+		bench := hint.after
+		if bench <= 0 {
+			bench = rateLimitBench << (k.strikes - 1)
+			if bench > maxBench || bench <= 0 {
+				bench = maxBench
+			}
+		}
+		k.BenchedUntil = now.Add(bench)
+	case 401:
+		k.Unauthorized++
+		k.BenchedUntil = now.Add(authBench)
+	default:
+		return false
+	}
+	for i := range h.keys {
+		if i != client.id && !now.Before(h.keys[i].BenchedUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// initKeys sets up the health of the keys of the handler. Callers hold the lock.
+func (h *Handler) initKeys() {
+	if h.keys != nil {
+		return
+	}
+	h.keys = make([]keyState, len(h.clients))
+	for i := range h.keys {
+		if i < len(h.cfg.Keys) {
+			h.keys[i].Key = maskKey(h.cfg.Keys[i])
+		}
+	}
+}
+
+// now returns the current time, of the clock of the handler when set
+func (h *Handler) now() time.Time {
+	if h.clock != nil {
+		return h.clock()
+	}
+	return time.Now()
+}
+
+// KeyHealth returns the health of each configured key over the calls made so far
+func (h *Handler) KeyHealth() []KeyHealth {
+	h.Lock()
+	defer h.Unlock()
+	h.initKeys()
+	health := make([]KeyHealth, len(h.keys))
+	for i, k := range h.keys {
+		health[i] = k.KeyHealth
+	}
+	return health
+}
@@ -0,0 +1,97 @@
+package gpt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKeyRotation tests that keys are rotated in proportion to their error rates and
+// that benched keys are left out until their bench ends
+func TestKeyRotation(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := New(Config{Keys: []string{"key-a", "key-b", "key-c"}})
+	h.clock = func() time.Time { return now }
+
+	picks := map[int]int{}
+	for i := 0; i < 30; i++ {
+		picks[h.pickClient().id]++
+	}
+	assert.Equal(t, map[int]int{0: 10, 1: 10, 2: 10}, picks, "healthy keys are rotated evenly")
+
+	// The first key fails often, the second is rate limited
+	for i := 0; i < 10; i++ {
+		h.reportKey(h.clients[0], &gogpt.APIError{HTTPStatusCode: 500}, &retryHint{})
+	}
+	assert.True(t, h.reportKey(h.clients[1], &gogpt.APIError{HTTPStatusCode: 429}, &retryHint{after: time.Minute}))
+	picks = map[int]int{}
+	for i := 0; i < 30; i++ {
+		picks[h.pickClient().id]++
+	}
+	assert.Zero(t, picks[1], "benched keys get no calls")
+	assert.Greater(t, picks[2], 3*picks[0], "healthy keys get most calls")
+
+	now = now.Add(2 * time.Minute)
+	picks = map[int]int{}
+	for i := 0; i < 30; i++ {
+		picks[h.pickClient().id]++
+	}
+	assert.NotZero(t, picks[1], "keys are back once their bench ends")
+
+	// Once every key is benched, the one back the soonest is used
+	h.reportKey(h.clients[0], &gogpt.APIError{HTTPStatusCode: 401}, &retryHint{})
+	h.reportKey(h.clients[1], &gogpt.APIError{HTTPStatusCode: 429}, &retryHint{after: time.Minute})
+	assert.False(t, h.reportKey(h.clients[2], &gogpt.APIError{HTTPStatusCode: 429}, &retryHint{after: time.Second}))
+	assert.Equal(t, 2, h.pickClient().id)
+
+	health := h.KeyHealth()
+	assert.Equal(t, "****", health[0].Key)
+	assert.Equal(t, 11, health[0].Failures)
+	assert.Equal(t, 1, health[0].Unauthorized)
+	assert.Equal(t, 2, health[1].RateLimited)
+	assert.Equal(t, now.Add(authBench), health[0].BenchedUntil)
+}
+
+// TestRateLimitedKeySwitch tests that a call rate limited on one key is retried on
+// another right away, without waiting
+func TestRateLimitedKeySwitch(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		requests[key]++
+		if key == "sk-limited-key" {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "rate limited"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Bonjour"}}]}`))
+	}))
+	defer server.Close()
+
+	retry := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Minute, MaxDelay: time.Minute}
+	h := New(Config{Keys: []string{"sk-limited-key", "sk-healthy-key"}, Retry: retry})
+	for i, key := range h.cfg.Keys {
+		clientCfg := gogpt.DefaultConfig(key)
+		clientCfg.BaseURL = server.URL
+		clientCfg.HTTPClient = h.http
+		h.clients[i].Client = gogpt.NewClientWithConfig(clientCfg)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		translation, err := h.Translate(context.Background(), "Hello", "French")
+		assert.NoError(t, err)
+		assert.Equal(t, "Bonjour", translation)
+	}
+	assert.Less(t, time.Since(start), 10*time.Second, "the rate-limited key is skipped instead of waited for")
+	assert.Equal(t, 1, requests["sk-limited-key"], "the benched key gets no more calls")
+	assert.Equal(t, 3, requests["sk-healthy-key"])
+	assert.Equal(t, 1, h.KeyHealth()[0].RateLimited)
+}
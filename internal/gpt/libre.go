@@ -0,0 +1,138 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLibreTranslateEndpoint is where LibreTranslate listens by default
+const DefaultLibreTranslateEndpoint = "http://localhost:5000"
+
+// libreModel names the translation models of LibreTranslate in audits and forecasts
+const libreModel = "argos"
+
+// errUnsupported is returned for features a provider doesn't have
+var errUnsupported = errors.New("not supported by the provider")
+
+type languagesKey struct{}
+
+type languages struct {
+	source string
+	target string
+}
+
+// WithLanguages returns ctx carrying the codes of the source and target languages of
+// the texts translated with it. Machine translation providers need codes, while
+// prompts use language names.
+func WithLanguages(ctx context.Context, source, target string) context.Context {
+	return context.WithValue(ctx, languagesKey{}, languages{source: source, target: target})
+}
+
+// targetCode returns the code of the target language carried by ctx, empty when unknown
+func targetCode(ctx context.Context) string {
+	langs, _ := ctx.Value(languagesKey{}).(languages)
+	return langs.target
+}
+
+// libreCode returns the LibreTranslate code of a language tag: its base language,
+// "auto" when unknown
+func libreCode(code string) string {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return "auto"
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// libreLanguages returns the LibreTranslate codes of the languages carried by ctx
+func libreLanguages(ctx context.Context) (string, string, error) {
+	langs, ok := ctx.Value(languagesKey{}).(languages)
+	if !ok || langs.target == "" {
+		return "", "", fmt.Errorf("no target language code for %s", ProviderLibreTranslate)
+	}
+	source := "auto"
+	if langs.source != "" {
+		source = libreCode(langs.source)
+	}
+	return source, libreCode(langs.target), nil
+}
+
+type libreRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreResponse struct {
+	TranslatedText []string `json:"translatedText"`
+}
+
+// libreTranslate translates texts with LibreTranslate in a single request. Texts
+// containing tags are sent as HTML so their markup is kept.
+func (h *Handler) libreTranslate(ctx context.Context, texts []string) ([]string, error) {
+	source, target, err := libreLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "text"
+	for _, text := range texts {
+		if strings.Contains(text, "<") && strings.Contains(text, ">") {
+			format = "html"
+		}
+	}
+	body, err := h.libreBody(texts, source, target, format)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling texts: %w", err)
+	}
+
+	return h.retryService(ctx, "LibreTranslate", len(texts), func(ctx context.Context) ([]string, error) {
+		return h.librePost(ctx, body)
+	})
+}
+
+// libreBody returns the JSON body of a translation request, with the API key if any
+func (h *Handler) libreBody(texts []string, source, target, format string) ([]byte, error) {
+	req := libreRequest{Q: texts, Source: source, Target: target, Format: format}
+	if len(h.cfg.Keys) > 0 {
+		req.APIKey = h.cfg.Keys[0]
+	}
+	return json.Marshal(req)
+}
+
+// librePost sends a translation request to the /translate endpoint
+func (h *Handler) librePost(ctx context.Context, body []byte) ([]string, error) {
+	endpoint := h.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultLibreTranslateEndpoint
+	}
+	data, err := h.postJSON(ctx, strings.TrimSuffix(endpoint, "/")+"/translate", nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var result libreResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return result.TranslatedText, nil
+}
+
+// pingLibre checks the LibreTranslate server with a one-word translation
+func (h *Handler) pingLibre(ctx context.Context) KeyStatus {
+	return h.pingService(ctx, func(ctx context.Context) error {
+		body, err := h.libreBody([]string{"ping"}, "en", "es", "text")
+		if err != nil {
+			return err
+		}
+		_, err = h.librePost(ctx, body)
+		return err
+	})
+}
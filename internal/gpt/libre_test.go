@@ -0,0 +1,73 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLibreTranslate tests batch requests, language codes, the API key and retries
+// of server errors
+func TestLibreTranslate(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "/translate", r.URL.Path)
+		var req libreRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "en", req.Source)
+		assert.Equal(t, "pt", req.Target)
+		assert.Equal(t, "secret-key", req.APIKey)
+		assert.Equal(t, "html", req.Format)
+
+		translated := make([]string, len(req.Q))
+		for i, q := range req.Q {
+			translated[i] = strings.ToUpper(q)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"translatedText": translated})
+	}))
+	defer server.Close()
+
+	h := New(Config{Provider: ProviderLibreTranslate, Endpoint: server.URL + "/", Keys: []string{"secret-key"}})
+	ctx := WithLanguages(context.Background(), "en-US", "pt-BR")
+
+	translations, err := h.BatchTranslate(ctx, nil, []string{"hello", "<b>bye</b>"}, "Português")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HELLO", "<B>BYE</B>"}, translations)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, h.Usage().Requests)
+	assert.Equal(t, "argos", h.Model())
+
+	// Without language codes nothing is sent
+	_, err = h.Translate(context.Background(), "hello", "Português")
+	assert.Error(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+// TestLibreTranslateError tests that client errors are reported without retrying
+func TestLibreTranslateError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "xx is not supported"}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Provider: ProviderLibreTranslate, Endpoint: server.URL})
+	_, err := h.Translate(WithLanguages(context.Background(), "en", "xx"), "hello", "xx")
+	assert.ErrorContains(t, err, "xx is not supported")
+	assert.Equal(t, 1, requests)
+
+	_, err = h.Grade(context.Background(), "a", "b", "c", "French")
+	assert.Error(t, err)
+}
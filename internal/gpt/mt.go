@@ -0,0 +1,120 @@
+package gpt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// machineTranslation reports whether the provider is a translation service rather
+// than a chat model: it takes texts and language codes, no prompts nor examples
+func (h *Handler) machineTranslation() bool {
+	return h.Provider() == ProviderLibreTranslate || h.Provider() == ProviderWebhook
+}
+
+// serviceTranslate translates texts with the translation service of the provider
+func (h *Handler) serviceTranslate(ctx context.Context, texts []string) ([]string, error) {
+	if h.Provider() == ProviderWebhook {
+		return h.webhookTranslate(ctx, texts)
+	}
+	return h.libreTranslate(ctx, texts)
+}
+
+// statusError is an unsuccessful response of a translation service
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.message)
+}
+
+// postJSON posts a JSON body to url and returns the body of a successful response.
+// Unsuccessful responses are a *statusError, with the "error" field of their body as
+// message when there is one.
+func (h *Handler) postJSON(ctx context.Context, url string, headers map[string]string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &result) == nil && result.Error != "" {
+			message = result.Error
+		}
+		return nil, &statusError{status: resp.StatusCode, message: message}
+	}
+	return data, nil
+}
+
+// retryService sends the translations of n texts to a translation service with post,
+// retrying rate limits, server errors and timeouts like the chat calls
+func (h *Handler) retryService(ctx context.Context, service string, n int, post func(ctx context.Context) ([]string, error)) ([]string, error) {
+	var translations []string
+	err := h.withRetries(ctx, func(ctx context.Context, _ *Client) error {
+		start := time.Now()
+		var err error
+		if translations, err = post(ctx); err != nil {
+			return fmt.Errorf("error calling %s: %w", service, err)
+		}
+		h.recordUsage(ctx, gogpt.Usage{}, time.Since(start))
+
+		if len(translations) != n {
+			return &badResponse{fmt.Errorf("expected %d translations, got %d", n, len(translations))}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// pingService checks a translation service with a one-word translation sent by post
+func (h *Handler) pingService(ctx context.Context, post func(ctx context.Context) error) KeyStatus {
+	status := KeyStatus{Key: "-", Model: h.Model()}
+	if len(h.cfg.Keys) > 0 {
+		status.Key = maskKey(h.cfg.Keys[0])
+	}
+	if h.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := post(ctx)
+	status.Latency = time.Since(start)
+	if err != nil {
+		status.Err = fmt.Errorf("translation failed: %w", err)
+		return status
+	}
+	status.ModelAvailable = true
+	return status
+}
@@ -0,0 +1,42 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Defaults of the Ollama provider
+const (
+	DefaultOllamaEndpoint = "http://localhost:11434"
+	DefaultOllamaModel    = "llama3.1"
+)
+
+// ollamaKey is sent as the API key to Ollama, which requires one but ignores it
+const ollamaKey = "ollama"
+
+// ollamaBaseURL returns the URL of the OpenAI-compatible API of an Ollama endpoint
+func ollamaBaseURL(endpoint string) string {
+	if endpoint == "" {
+		endpoint = DefaultOllamaEndpoint
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1") {
+		endpoint += "/v1"
+	}
+	return endpoint
+}
+
+// sequentialTranslate translates texts one request at a time, for providers whose
+// models can't be relied on to answer a batch as JSON
+func (h *Handler) sequentialTranslate(ctx context.Context, texts []string, lang string, examples []Example) ([]string, error) {
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		translated, err := h.translateMasked(ctx, text, lang, examples, nil)
+		if err != nil {
+			return nil, fmt.Errorf("text %d of %d: %w", i+1, len(texts), err)
+		}
+		translations[i] = translated
+	}
+	return translations, nil
+}
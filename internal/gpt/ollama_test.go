@@ -0,0 +1,58 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOllamaBaseURL tests the OpenAI-compatible API URL of Ollama endpoints
+func TestOllamaBaseURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:11434/v1", ollamaBaseURL(""))
+	assert.Equal(t, "http://gpu-box:11434/v1", ollamaBaseURL("http://gpu-box:11434/"))
+	assert.Equal(t, "http://gpu-box:11434/v1", ollamaBaseURL("http://gpu-box:11434/v1"))
+}
+
+// TestOllamaBatch tests that batches are translated one text per request with the
+// configured model, without API keys
+func TestOllamaBatch(t *testing.T) {
+	models := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		var req gogpt.ChatCompletionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		models = append(models, req.Model)
+
+		text := req.Messages[len(req.Messages)-1].Content
+		text = text[strings.LastIndex(text, "\n")+1:]
+		json.NewEncoder(w).Encode(gogpt.ChatCompletionResponse{
+			Choices: []gogpt.ChatCompletionChoice{{Message: gogpt.ChatCompletionMessage{Role: "assistant", Content: "fr:" + text}}},
+		})
+	}))
+	defer server.Close()
+
+	h := New(Config{Provider: ProviderOllama, Endpoint: server.URL, Model: "qwen2.5"})
+	assert.Equal(t, ProviderOllama, h.Provider())
+	assert.Equal(t, "qwen2.5", h.Model())
+
+	translations, err := h.BatchTranslate(context.Background(), []string{"a", "b"}, []string{"Hello", "Goodbye"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fr:Hello", "fr:Goodbye"}, translations)
+	assert.Equal(t, []string{"qwen2.5", "qwen2.5"}, models)
+
+	_, err = h.Embed(context.Background(), []string{"Hello"})
+	assert.Error(t, err)
+}
+
+// TestDefaultModel tests the models used when none is configured
+func TestDefaultModel(t *testing.T) {
+	assert.Equal(t, ProviderOpenAI, New(Config{}).Provider())
+	assert.Equal(t, DefaultModel, New(Config{}).Model())
+	assert.Equal(t, DefaultOllamaModel, New(Config{Provider: ProviderOllama}).Model())
+}
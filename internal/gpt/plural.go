@@ -0,0 +1,63 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+const pluralSystemPrompt = "You are a professional translator. You translate a message that has plural forms. For each CLDR plural category listed, write the translation used for counts in that category; an example count is given for each. Keep placeholders such as %d, %s or {n} and all formatting unchanged. Return ONLY a JSON object in this exact format: {\"forms\": {\"<category>\": \"translated text\", ...}} containing every category exactly as given."
+
+const pluralUserPrompt = "Target language: %s\n\nSingular source: %s\nPlural source: %s\n\nPlural categories:\n%s"
+
+// PluralForm is a plural category to translate, with a count that falls in it
+type PluralForm struct {
+	Category string
+	Example  int
+}
+
+// TranslatePlural translates a singular/plural source pair into one text per plural
+// form of the target language, asking for each category explicitly
+func (h *Handler) TranslatePlural(ctx context.Context, singular, plural string, forms []PluralForm, lang string) ([]string, error) {
+	var categories strings.Builder
+	for _, f := range forms {
+		fmt.Fprintf(&categories, "- %s (e.g. %d)\n", f.Category, f.Example)
+	}
+
+	content, err := h.chat(ctx, gogpt.ChatCompletionRequest{
+		Model: h.Model(),
+		Messages: []gogpt.ChatCompletionMessage{
+			{Role: "system", Content: pluralSystemPrompt},
+			{Role: "user", Content: fmt.Sprintf(pluralUserPrompt, lang, singular, plural, categories.String())},
+		},
+		Temperature: h.temperature(translationTemperature),
+		Seed:        h.seed(),
+		MaxTokens:   h.maxTokens(1024),
+		ResponseFormat: &gogpt.ChatCompletionResponseFormat{
+			Type: gogpt.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Forms map[string]string `json:"forms"`
+	}
+	if err := json.Unmarshal([]byte(content), &resp); err != nil {
+		return nil, fmt.Errorf("invalid plural response: %w", err)
+	}
+
+	results := make([]string, len(forms))
+	for i, f := range forms {
+		text := strings.TrimSpace(resp.Forms[f.Category])
+		if text == "" {
+			return nil, fmt.Errorf("plural response has no %s form", f.Category)
+		}
+		results[i] = text
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package gpt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// PolicyError is a text the provider refused to translate for content policy reasons.
+// Refusals are not retried, as the same text would be refused again.
+type PolicyError struct {
+	// Reason given by the provider
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("refused by the content policy of the provider: %s", e.Reason)
+}
+
+// policyCodes are the error codes of content policy refusals of OpenAI and Azure OpenAI
+var policyCodes = map[string]bool{"content_policy_violation": true, "content_filter": true, "ResponsibleAIPolicyViolation": true}
+
+// policyRefusal returns the refusal err is, nil for other errors
+func policyRefusal(err error) *PolicyError {
+	var apiErr *gogpt.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	code, _ := apiErr.Code.(string)
+	refused := policyCodes[code] || (apiErr.InnerError != nil && policyCodes[apiErr.InnerError.Code])
+	if !refused && apiErr.HTTPStatusCode == 400 {
+		message := strings.ToLower(apiErr.Message)
+		refused = strings.Contains(message, "content management policy") || strings.Contains(message, "content policy")
+	}
+	if !refused {
+		return nil
+	}
+	reason := apiErr.Message
+	if reason == "" {
+		reason = code
+	}
+	return &PolicyError{Reason: reason}
+}
+
+// filtered returns the refusal of a completion stopped by the content filter of the
+// provider, or declined by the model, nil for other completions
+func filtered(choice gogpt.ChatCompletionChoice) *PolicyError {
+	if choice.FinishReason == gogpt.FinishReasonContentFilter {
+		return &PolicyError{Reason: "the completion was stopped by the content filter"}
+	}
+	if refusal := strings.TrimSpace(choice.Message.Refusal); refusal != "" {
+		return &PolicyError{Reason: refusal}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPolicyRefusal tests that content policy refusals are reported as such, without
+// retrying them
+func TestPolicyRefusal(t *testing.T) {
+	for name, answer := range map[string]struct {
+		status int
+		body   string
+		reason string
+	}{
+		"error code":     {400, `{"error": {"code": "content_policy_violation", "message": "Your request was rejected as a result of our safety system."}}`, "Your request was rejected as a result of our safety system."},
+		"azure filter":   {400, `{"error": {"code": "content_filter", "message": "The response was filtered due to the prompt triggering Azure OpenAI's content management policy."}}`, "The response was filtered due to the prompt triggering Azure OpenAI's content management policy."},
+		"content filter": {200, `{"choices": [{"finish_reason": "content_filter", "message": {"role": "assistant", "content": ""}}]}`, "the completion was stopped by the content filter"},
+		"refusal":        {200, `{"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": "", "refusal": "I can't help with that."}}]}`, "I can't help with that."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(answer.status)
+				w.Write([]byte(answer.body))
+			}))
+			defer server.Close()
+
+			h := New(Config{Keys: []string{"sk-test-key"}})
+			clientCfg := gogpt.DefaultConfig("sk-test-key")
+			clientCfg.BaseURL = server.URL
+			clientCfg.HTTPClient = h.http
+			h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+			_, err := h.Translate(context.Background(), "Some text", "French")
+			var refusal *PolicyError
+			assert.True(t, errors.As(err, &refusal))
+			assert.Equal(t, answer.reason, refusal.Reason)
+			_, err = h.BatchTranslate(context.Background(), nil, []string{"Some text", "Other text"}, "French")
+			assert.True(t, errors.As(err, &refusal))
+			assert.Equal(t, 2, requests)
+		})
+	}
+
+	assert.Nil(t, policyRefusal(&gogpt.APIError{HTTPStatusCode: 400, Message: "Invalid model"}))
+	assert.Nil(t, policyRefusal(errors.New("timeout")))
+}
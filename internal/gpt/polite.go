@@ -0,0 +1,131 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/tracing"
+)
+
+// Polite throttles the requests of bulk runs so that they leave the quota of API keys
+// shared with production features mostly to them
+type Polite struct {
+	// Requests per second across all keys, unlimited when 0
+	RPS float64
+	// Business hours, as offsets from midnight, during which requests wait on weekdays.
+	// None when Start and End are equal.
+	Start, End time.Duration
+	// Time zone of the business hours, the local one when nil
+	Location *time.Location
+}
+
+// Resume returns when a request due at t may start: t outside business hours,
+// their end inside
+func (p Polite) Resume(t time.Time) time.Time {
+	if p.Start == p.End {
+		return t
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	local := t.In(loc)
+	if wd := local.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return t
+	}
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	start, end := midnight.Add(p.Start), midnight.Add(p.End)
+	if local.Before(start) || !local.Before(end) {
+		return t
+	}
+	return end
+}
+
+// politeTransport delays requests according to a Polite policy. Waiting doesn't count
+// toward the timeout, which the transport applies once the request starts.
+type politeTransport struct {
+	base    http.RoundTripper
+	polite  Polite
+	timeout time.Duration
+
+	mu sync.Mutex
+	// Earliest start of the next request
+	next time.Time
+	// End of the business hours last announced
+	announced time.Time
+}
+
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// wait blocks until the request may start, or ctx is done
+func (t *politeTransport) wait(ctx context.Context) error {
+	now := time.Now()
+	d := t.reserve(now).Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	_, span := tracing.Start(ctx, "ratelimit.wait", "reason", "polite", "delay", d.String())
+	defer span.End()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve returns when a request made at now may start, and books that slot
+func (t *politeTransport) reserve(now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	at := now
+	if t.next.After(at) {
+		at = t.next
+	}
+	if resume := t.polite.Resume(at); resume.After(at) {
+		if !resume.Equal(t.announced) {
+			t.announced = resume
+			fmt.Printf("\n⏸️ Polite mode: pausing during business hours until %s\n", resume.Format("2006-01-02 15:04 MST"))
+		}
+		at = resume
+	}
+	if t.polite.RPS > 0 {
+		t.next = at.Add(time.Duration(float64(time.Second) / t.polite.RPS))
+	}
+	return at
+}
+
+// cancelBody releases the timeout of a request once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,40 @@
+package gpt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPoliteResume tests that requests wait for the end of business hours on
+// weekdays only, in the configured time zone
+func TestPoliteResume(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	p := Polite{Start: 9 * time.Hour, End: 18 * time.Hour, Location: tokyo}
+
+	// Monday 10:30 in Tokyo
+	monday := time.Date(2026, 10, 12, 10, 30, 0, 0, tokyo)
+	assert.Equal(t, time.Date(2026, 10, 12, 18, 0, 0, 0, tokyo), p.Resume(monday))
+	assert.Equal(t, monday.Add(-2*time.Hour), p.Resume(monday.Add(-2*time.Hour)))
+	assert.Equal(t, monday.Add(8*time.Hour), p.Resume(monday.Add(8*time.Hour)))
+	// Same instant seen from UTC
+	assert.Equal(t, time.Date(2026, 10, 12, 18, 0, 0, 0, tokyo).UTC(), p.Resume(monday.UTC()).UTC())
+
+	saturday := time.Date(2026, 10, 17, 10, 30, 0, 0, tokyo)
+	assert.Equal(t, saturday, p.Resume(saturday))
+	assert.Equal(t, monday, Polite{}.Resume(monday))
+}
+
+// TestPoliteReserve tests that requests are spaced by the rate ceiling and pushed
+// past business hours
+func TestPoliteReserve(t *testing.T) {
+	now := time.Date(2026, 10, 12, 8, 59, 59, 0, time.UTC)
+	transport := &politeTransport{polite: Polite{RPS: 2, Start: 9 * time.Hour, End: 18 * time.Hour, Location: time.UTC}}
+
+	assert.Equal(t, now, transport.reserve(now))
+	assert.Equal(t, now.Add(500*time.Millisecond), transport.reserve(now))
+	end := time.Date(2026, 10, 12, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, end, transport.reserve(now))
+	assert.Equal(t, end.Add(500*time.Millisecond), transport.reserve(now))
+}
@@ -0,0 +1,205 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Placeholders of system prompt templates
+const (
+	// PlaceholderTargetLang is the name of the language translated to
+	PlaceholderTargetLang = "{{targetLang}}"
+	// PlaceholderSourceLang is the code of the language translated from, when known
+	PlaceholderSourceLang = "{{sourceLang}}"
+	// PlaceholderKey is the key of the translated text, empty in batches
+	PlaceholderKey = "{{key}}"
+	// PlaceholderGlossary is the terminology to follow, empty when there is none
+	PlaceholderGlossary = "{{glossary}}"
+	// PlaceholderDescription is the description of the key of the translated text, empty
+	// when it has none and in batches
+	PlaceholderDescription = "{{description}}"
+)
+
+// Placeholders lists the placeholders system prompt templates may use
+var Placeholders = []string{PlaceholderTargetLang, PlaceholderSourceLang, PlaceholderKey, PlaceholderGlossary, PlaceholderDescription}
+
+// Key context appended to the default prompts when Config.KeyContext is set
+const (
+	keyContextPrompt        = " The text is shown in the application under the key %q; use the key as context to disambiguate the text, but never translate it."
+	descriptionPrompt       = " Description of the text: %s."
+	batchDescriptionsPrompt = " Descriptions of some keys: %s."
+)
+
+// Format instructions appended to templated system prompts of batches, which must be
+// answered in the JSON format the handler parses
+const (
+	batchFormatPrompt      = " You receive a JSON array of texts. Return your response ONLY as a valid JSON object in this exact format: {\"translations\": [\"translated text 1\", \"translated text 2\", ...]}"
+	keyedBatchFormatPrompt = " You receive a JSON object mapping message keys to texts. The keys describe where each text is used in the application; use them as context but never translate them. Return your response ONLY as a valid JSON object in this exact format: {\"translations\": {\"<key>\": \"translated text\", ...}} containing every key exactly as given."
+)
+
+var placeholderPattern = regexp.MustCompile(`{{\s*[^}]*}}`)
+
+// ValidatePrompt checks that a system prompt template only uses known placeholders
+func ValidatePrompt(template string) error {
+	for _, p := range placeholderPattern.FindAllString(template, -1) {
+		known := false
+		for _, name := range Placeholders {
+			known = known || p == name
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder %s in system prompt, supported: %s", p, strings.Join(Placeholders, ", "))
+		}
+	}
+	return nil
+}
+
+type keyKey struct{}
+
+type glossaryKey struct{}
+
+type instructionsKey struct{}
+
+type descriptionsKey struct{}
+
+type correctionKey struct{}
+
+// Correction is a translation that failed validation and what to fix in it
+type Correction struct {
+	Translation string
+	// What the translation got wrong and how to fix it, e.g. "You removed {count}."
+	Problem string
+}
+
+// WithKey returns ctx carrying the key of the text translated with it
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyKey{}, key)
+}
+
+// KeyFrom returns the key of the text translated with ctx, empty when it has none
+func KeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey{}).(string)
+	return key
+}
+
+// WithGlossary returns ctx carrying the terminology the texts translated with it must follow
+func WithGlossary(ctx context.Context, glossary string) context.Context {
+	return context.WithValue(ctx, glossaryKey{}, glossary)
+}
+
+// WithInstructions returns ctx carrying instructions added to the system prompt of
+// the texts translated with it, such as the register to use
+func WithInstructions(ctx context.Context, instructions string) context.Context {
+	return context.WithValue(ctx, instructionsKey{}, instructions)
+}
+
+// WithDescriptions returns ctx carrying descriptions of the keys of the texts translated
+// with it, such as where and how their text is shown
+func WithDescriptions(ctx context.Context, descriptions map[string]string) context.Context {
+	return context.WithValue(ctx, descriptionsKey{}, descriptions)
+}
+
+// WithCorrection returns ctx asking the text translated with it to be translated again,
+// fixing the problem of a previous translation
+func WithCorrection(ctx context.Context, c Correction) context.Context {
+	return context.WithValue(ctx, correctionKey{}, c)
+}
+
+// description returns the description of the key of ctx, empty when it has none
+func description(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey{}).(string)
+	descriptions, _ := ctx.Value(descriptionsKey{}).(map[string]string)
+	return descriptions[key]
+}
+
+// keyContext returns the key context of the text of ctx for the default prompts, empty
+// unless key context is enabled
+func (h *Handler) keyContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey{}).(string)
+	if !h.cfg.KeyContext || key == "" {
+		return ""
+	}
+	prompt := fmt.Sprintf(keyContextPrompt, key)
+	if d := description(ctx); d != "" {
+		prompt += fmt.Sprintf(descriptionPrompt, d)
+	}
+	return prompt
+}
+
+// batchDescriptions returns the descriptions of the keys of a batch for its system
+// prompt, sorted by key, empty unless key context is enabled
+func (h *Handler) batchDescriptions(ctx context.Context) string {
+	descriptions, _ := ctx.Value(descriptionsKey{}).(map[string]string)
+	if !h.cfg.KeyContext || len(descriptions) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(descriptions))
+	for key, d := range descriptions {
+		lines = append(lines, fmt.Sprintf("%q: %s", key, d))
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf(batchDescriptionsPrompt, strings.Join(lines, "; "))
+}
+
+// glossaryPrompt introduces the terminology of the context in prompts that don't place it
+const glossaryPrompt = " Always use this terminology: %s."
+
+// systemPrompt returns the configured system prompt template filled in for a
+// translation into lang, or fallback when none is configured. The terminology of the
+// context is appended unless the template places it, followed by its instructions.
+func (h *Handler) systemPrompt(ctx context.Context, lang, fallback string) string {
+	if h.cfg.SystemPrompt == "" {
+		return withContext(ctx, fallback+h.keyContext(ctx), true)
+	}
+	source := ""
+	if langs, ok := ctx.Value(languagesKey{}).(languages); ok {
+		source = langs.source
+	}
+	key, _ := ctx.Value(keyKey{}).(string)
+	glossary, _ := ctx.Value(glossaryKey{}).(string)
+	prompt := strings.NewReplacer(
+		PlaceholderTargetLang, lang,
+		PlaceholderSourceLang, source,
+		PlaceholderKey, key,
+		PlaceholderGlossary, glossary,
+		PlaceholderDescription, description(ctx),
+	).Replace(h.cfg.SystemPrompt)
+	return withContext(ctx, prompt, !strings.Contains(h.cfg.SystemPrompt, PlaceholderGlossary))
+}
+
+// withContext appends the glossary of ctx to prompt when asked and there is one, then
+// the instructions of ctx
+func withContext(ctx context.Context, prompt string, glossary bool) string {
+	if terms, _ := ctx.Value(glossaryKey{}).(string); glossary && terms != "" {
+		prompt += fmt.Sprintf(glossaryPrompt, terms)
+	}
+	if instructions, _ := ctx.Value(instructionsKey{}).(string); instructions != "" {
+		prompt += " " + instructions
+	}
+	return prompt
+}
+
+// batchPrompt returns the system prompt of a batch into lang
+func (h *Handler) batchPrompt(ctx context.Context, lang string, keyed bool) string {
+	if h.cfg.SystemPrompt == "" {
+		if keyed {
+			return withContext(ctx, keyedBatchSystemPrompt+h.batchDescriptions(ctx), true)
+		}
+		return withContext(ctx, batchSystemPrompt, true)
+	}
+	prompt := h.systemPrompt(WithKey(ctx, ""), lang, "")
+	if keyed {
+		return prompt + keyedBatchFormatPrompt + h.batchDescriptions(ctx)
+	}
+	return prompt + batchFormatPrompt
+}
+
+// maxTokens returns the configured completion token cap, or fallback when none is
+func (h *Handler) maxTokens(fallback int) int {
+	if h.cfg.MaxTokens > 0 {
+		return h.cfg.MaxTokens
+	}
+	return fallback
+}
@@ -0,0 +1,93 @@
+package gpt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSystemPromptTemplate tests that templates are filled in from the context and
+// that the configured temperature and token cap are sent
+func TestSystemPromptTemplate(t *testing.T) {
+	temperature := float32(0.7)
+	h := New(Config{
+		Keys:         []string{"key"},
+		Temperature:  &temperature,
+		MaxTokens:    300,
+		SystemPrompt: "Translate from {{sourceLang}} to {{targetLang}} in a playful tone. Key: {{key}}. Terms: {{glossary}}",
+	})
+
+	ctx := WithGlossary(WithKey(WithLanguages(context.Background(), "en", "fr"), "home/title"), "Cart = Panier")
+	req, _ := h.translationRequest(ctx, "Hello", "French", nil, nil)
+	assert.Equal(t, "Translate from en to French in a playful tone. Key: home/title. Terms: Cart = Panier", req.Messages[0].Content)
+	assert.Equal(t, float32(0.7), req.Temperature)
+	assert.Equal(t, 300, req.MaxTokens)
+
+	batch := h.batchPrompt(ctx, "French", true)
+	assert.True(t, strings.HasPrefix(batch, "Translate from en to French in a playful tone. Key: . "))
+	assert.Contains(t, batch, `{"translations": {"<key>"`)
+
+	// Defaults are untouched without a template, the terminology is appended
+	h = New(Config{Keys: []string{"key"}})
+	req, _ = h.translationRequest(ctx, "Hello", "French", nil, nil)
+	assert.Equal(t, translateSystemPrompt+" Always use this terminology: Cart = Panier.", req.Messages[0].Content)
+	assert.Equal(t, float32(translationTemperature), req.Temperature)
+	assert.Equal(t, 1024, req.MaxTokens)
+	assert.Equal(t, batchSystemPrompt+" Always use this terminology: Cart = Panier.", h.batchPrompt(ctx, "French", false))
+	assert.Equal(t, batchSystemPrompt, h.batchPrompt(context.Background(), "French", false))
+
+	// Templates without the placeholder get the terminology appended too
+	h = New(Config{Keys: []string{"key"}, SystemPrompt: "Translate to {{targetLang}}."})
+	req, _ = h.translationRequest(ctx, "Hello", "French", nil, nil)
+	assert.Equal(t, "Translate to French. Always use this terminology: Cart = Panier.", req.Messages[0].Content)
+
+	// Instructions come last
+	req, _ = h.translationRequest(WithInstructions(ctx, "Address the user formally."), "Hello", "French", nil, nil)
+	assert.Equal(t, "Translate to French. Always use this terminology: Cart = Panier. Address the user formally.", req.Messages[0].Content)
+}
+
+// TestKeyContext tests that keys and their descriptions are only sent when enabled
+func TestKeyContext(t *testing.T) {
+	ctx := WithDescriptions(WithKey(context.Background(), "cart/close"), map[string]string{"cart/close": "Button closing the cart drawer"})
+
+	h := New(Config{Keys: []string{"key"}})
+	req, _ := h.translationRequest(ctx, "Close", "French", nil, nil)
+	assert.Equal(t, translateSystemPrompt, req.Messages[0].Content)
+	assert.Equal(t, keyedBatchSystemPrompt, h.batchPrompt(ctx, "French", true))
+
+	h = New(Config{Keys: []string{"key"}, KeyContext: true})
+	req, _ = h.translationRequest(ctx, "Close", "French", nil, nil)
+	assert.Equal(t, translateSystemPrompt+` The text is shown in the application under the key "cart/close"; use the key as context to disambiguate the text, but never translate it. Description of the text: Button closing the cart drawer.`, req.Messages[0].Content)
+	assert.Equal(t, keyedBatchSystemPrompt+` Descriptions of some keys: "cart/close": Button closing the cart drawer.`, h.batchPrompt(ctx, "French", true))
+
+	// Templates place the description themselves
+	h = New(Config{Keys: []string{"key"}, SystemPrompt: "Translate to {{targetLang}} ({{key}}: {{description}})."})
+	req, _ = h.translationRequest(ctx, "Close", "French", nil, nil)
+	assert.Equal(t, "Translate to French (cart/close: Button closing the cart drawer).", req.Messages[0].Content)
+}
+
+// TestValidatePrompt tests that templates may only use known placeholders
+func TestValidatePrompt(t *testing.T) {
+	assert.NoError(t, ValidatePrompt(""))
+	assert.NoError(t, ValidatePrompt("To {{targetLang}}, see {{glossary}}"))
+	assert.Error(t, ValidatePrompt("To {{target}}"))
+}
+
+// TestCorrectionPrompt tests that a correction answers the rejected translation with
+// what to fix
+func TestCorrectionPrompt(t *testing.T) {
+	h := New(Config{Keys: []string{"key"}})
+	ctx := WithCorrection(context.Background(), Correction{Translation: "{nombre} fichiers", Problem: "You removed {count}."})
+
+	req, _ := h.translationRequest(ctx, "{count} files", "French", nil, nil)
+	assert.Len(t, req.Messages, 4)
+	assert.Equal(t, "assistant", req.Messages[2].Role)
+	assert.Equal(t, "{nombre} fichiers", req.Messages[2].Content)
+	assert.Equal(t, "user", req.Messages[3].Role)
+	assert.Equal(t, "Your translation was rejected. You removed {count}. Translate the text to French again, fixing this, and reply with the corrected translation only.", req.Messages[3].Content)
+
+	req, _ = h.translationRequest(context.Background(), "{count} files", "French", nil, nil)
+	assert.Len(t, req.Messages, 2)
+}
@@ -0,0 +1,256 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/tracing"
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// RetryPolicy controls how calls failing with rate limits, server errors and timeouts
+// are retried: each retry waits twice as long as the previous one, up to MaxDelay
+type RetryPolicy struct {
+	// Attempts per call, the first one included
+	MaxAttempts int
+	// Delay before the first retry
+	InitialDelay time.Duration
+	// Longest delay between attempts, unless the provider asks for a longer one
+	MaxDelay time.Duration
+	// Share (0-1) of each delay drawn at random, so that concurrent runs don't retry in step
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the retry policy of calls when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: 30 * time.Second, Jitter: 0.2}
+}
+
+// withDefaults returns p with the default policy for its unset fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p == (RetryPolicy{}) {
+		return d
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = d.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = d.MaxDelay
+	}
+	if p.MaxDelay < p.InitialDelay {
+		p.MaxDelay = p.InitialDelay
+	}
+	return p
+}
+
+// Delay returns how long to wait after the failed attempt (0 for the first one) before
+// the next. retryAfter, the delay asked by the provider, is honored when set.
+func (p RetryPolicy) Delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	delay := p.InitialDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		spread := float64(delay) * p.Jitter
+		delay += time.Duration(spread*rand.Float64()*2 - spread)
+	}
+	return delay
+}
+
+// retryHint receives the delay a provider asked for with the Retry-After header of an
+// unsuccessful response
+type retryHint struct {
+	after time.Duration
+}
+
+type retryHintKey struct{}
+
+// withRetryHint returns ctx recording the Retry-After header of the responses to its
+// requests in hint
+func withRetryHint(ctx context.Context) (context.Context, *retryHint) {
+	hint := &retryHint{}
+	return context.WithValue(ctx, retryHintKey{}, hint), hint
+}
+
+// retryAfterTransport records the Retry-After header of unsuccessful responses in the
+// retry hint of their request, if any, as the API clients don't expose headers
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < 400 {
+		return resp, err
+	}
+	if hint, ok := req.Context().Value(retryHintKey{}).(*retryHint); ok {
+		hint.after = retryAfter(resp.Header, time.Now())
+	}
+	return resp, nil
+}
+
+// retryAfter returns the delay asked by the headers of a response, 0 when none:
+// retry-after-ms as sent by OpenAI, or Retry-After in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// backoff waits before retrying the failed attempt of a call, unless it was the last,
+// returning early with the error of ctx when it is done
+func (h *Handler) backoff(ctx context.Context, attempt int, hint *retryHint) error {
+	if attempt+1 >= h.cfg.Retry.MaxAttempts {
+		return nil
+	}
+	delay := h.cfg.Retry.Delay(attempt, hint.after)
+	_, span := tracing.Start(ctx, "provider.backoff", "attempt", strconv.Itoa(attempt+1), "delay", delay.String(), "retry_after", hint.after.String())
+	defer span.End()
+	hint.after = 0
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimited announces the wait before retrying a rate-limited attempt
+func (h *Handler) rateLimited(attempt int) {
+	if attempt+1 < h.cfg.Retry.MaxAttempts {
+		fmt.Printf("Rate limit exceeded, waiting before retry (attempt %d/%d)...\n", attempt+1, h.cfg.Retry.MaxAttempts)
+	}
+}
+
+// badResponse is the error of a call answered with an unusable response, such as an
+// empty translation, which is retried right away
+type badResponse struct {
+	err error
+}
+
+func (e *badResponse) Error() string { return e.err.Error() }
+
+func (e *badResponse) Unwrap() error { return e.err }
+
+// withRetries calls do as often as the retry policy allows, until it succeeds. Each
+// attempt checks the budget, picks a client (nil for translation services, which have
+// none) and reports how its key fared. Rate limits, server errors and timeouts are
+// retried after a backoff, rejected keys and bad responses right away; refusals and
+// other client errors fail at once.
+func (h *Handler) withRetries(ctx context.Context, do func(ctx context.Context, client *Client) error) error {
+	var lastErr error
+
+	for attempt := 0; attempt < h.cfg.Retry.MaxAttempts; attempt++ {
+		if err := h.checkBudget(); err != nil {
+			return err
+		}
+		var client *Client
+		if len(h.clients) > 0 {
+			client = h.pickClient()
+		}
+
+		reqCtx, hint := withRetryHint(ctx)
+		err := do(reqCtx, client)
+		var bad *badResponse
+		var refusal *PolicyError
+		answered := err == nil || errors.As(err, &bad) || errors.As(err, &refusal)
+		switchKey := false
+		if client != nil {
+			if answered {
+				switchKey = h.reportKey(client, nil, hint)
+			} else {
+				switchKey = h.reportKey(client, err, hint)
+			}
+		}
+		switch {
+		case err == nil:
+			return nil
+		case refusal != nil:
+			return refusal
+		case bad != nil:
+			lastErr = bad.err
+			continue
+		}
+		if refusal := policyRefusal(err); refusal != nil {
+			return refusal
+		}
+		h.recordError()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		switch status := statusOf(err); {
+		case status == 429:
+			lastErr = fmt.Errorf("API rate limit exceeded: %w", err)
+			if switchKey {
+				// Another key takes the retry right away
+				continue
+			}
+			h.rateLimited(attempt)
+		case status == 401 && client != nil:
+			// The key was rejected, and benched
+			lastErr = fmt.Errorf("API key rejected: %w", err)
+			continue
+		case status >= 500:
+			lastErr = fmt.Errorf("server error: %w", err)
+		case status >= 400:
+			return fmt.Errorf("request failed: %w", err)
+		case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "timeout"):
+			lastErr = fmt.Errorf("request timed out: %w", err)
+		default:
+			lastErr = err
+			continue
+		}
+		if err := h.backoff(ctx, attempt, hint); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", h.cfg.Retry.MaxAttempts, lastErr)
+}
+
+// statusOf returns the HTTP status of the unsuccessful response err reports, 0 for
+// errors without a response
+func statusOf(err error) int {
+	var apiErr *gogpt.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryDelay tests that delays double up to the maximum, stay within the jitter,
+// and give way to the delay asked by the provider
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Delay(0, 0))
+	assert.Equal(t, 2*time.Second, p.Delay(1, 0))
+	assert.Equal(t, 4*time.Second, p.Delay(2, 0))
+	assert.Equal(t, 5*time.Second, p.Delay(3, 0))
+	assert.Equal(t, 5*time.Second, p.Delay(40, 0))
+	assert.Equal(t, 20*time.Second, p.Delay(0, 20*time.Second))
+
+	p.Jitter = 0.5
+	for i := 0; i < 50; i++ {
+		d := p.Delay(1, 0)
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.LessOrEqual(t, d, 3*time.Second)
+	}
+
+	assert.Equal(t, DefaultRetryPolicy(), RetryPolicy{}.withDefaults())
+	assert.Equal(t, 5, RetryPolicy{MaxAttempts: 5}.withDefaults().MaxAttempts)
+}
+
+// TestRetryAfter tests that the Retry-After headers of responses are parsed
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	header := func(name, value string) http.Header {
+		h := http.Header{}
+		h.Set(name, value)
+		return h
+	}
+	assert.Equal(t, 7*time.Second, retryAfter(header("Retry-After", "7"), now))
+	assert.Equal(t, 1500*time.Millisecond, retryAfter(header("retry-after-ms", "1500"), now))
+	assert.Equal(t, 30*time.Second, retryAfter(header("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat)), now))
+	assert.Equal(t, time.Duration(0), retryAfter(header("Retry-After", "soon"), now))
+	assert.Equal(t, time.Duration(0), retryAfter(http.Header{}, now))
+}
+
+// TestRetryPolicy tests that rate limits are retried as often as the policy allows,
+// waiting as long as the provider asks
+func TestRetryPolicy(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 4 {
+			w.Header().Set("Retry-After-Ms", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"translations": []string{"Bonjour"}})
+	}))
+	defer server.Close()
+
+	ctx := WithLanguages(context.Background(), "en", "fr")
+	retry := RetryPolicy{MaxAttempts: 4, InitialDelay: time.Minute, MaxDelay: time.Minute}
+	h := New(Config{Provider: ProviderWebhook, Endpoint: server.URL, Retry: retry})
+	start := time.Now()
+	translations, err := h.BatchTranslate(ctx, nil, []string{"Hello"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour"}, translations)
+	assert.Less(t, time.Since(start), 10*time.Second, "the Retry-After delay replaces the policy's")
+
+	requests = 0
+	retry.MaxAttempts = 2
+	h = New(Config{Provider: ProviderWebhook, Endpoint: server.URL, Retry: retry})
+	_, err = h.BatchTranslate(ctx, nil, []string{"Hello"}, "French")
+	assert.ErrorContains(t, err, "after 2 attempts")
+	assert.Equal(t, 2, requests)
+}
+
+// TestWithRetries tests that chat calls retry server errors and bad responses, and
+// fail at once on refusals and other client errors
+func TestWithRetries(t *testing.T) {
+	for name, answer := range map[string]struct {
+		statuses []int
+		bodies   []string
+		requests int
+		err      string
+	}{
+		"server error": {[]int{503, 200}, []string{`{}`, `{"choices": [{"message": {"role": "assistant", "content": " Oui "}}]}`}, 2, ""},
+		"no choices":   {[]int{200, 200}, []string{`{"choices": []}`, `{"choices": [{"message": {"role": "assistant", "content": "Oui"}}]}`}, 2, ""},
+		"client error": {[]int{400}, []string{`{"error": {"message": "Invalid model"}}`}, 1, "Invalid model"},
+		"refusal":      {[]int{400}, []string{`{"error": {"code": "content_policy_violation", "message": "Rejected"}}`}, 1, "Rejected"},
+		"exhausted":    {[]int{500, 500}, []string{`{}`, `{}`}, 2, "after 2 attempts"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(answer.statuses[requests])
+				w.Write([]byte(answer.bodies[requests]))
+				requests++
+			}))
+			defer server.Close()
+
+			retry := RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+			h := New(Config{Keys: []string{"sk-test-key"}, Retry: retry})
+			clientCfg := gogpt.DefaultConfig("sk-test-key")
+			clientCfg.BaseURL = server.URL
+			clientCfg.HTTPClient = h.http
+			h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+			content, err := h.chat(context.Background(), gogpt.ChatCompletionRequest{Messages: []gogpt.ChatCompletionMessage{{Role: "user", Content: "Oui ?"}}})
+			assert.Equal(t, answer.requests, requests)
+			if answer.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, "Oui", content)
+				return
+			}
+			assert.ErrorContains(t, err, answer.err)
+		})
+	}
+}
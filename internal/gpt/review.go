@@ -0,0 +1,107 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+const reviewSystemPrompt = "You are a senior localization reviewer auditing existing translations into %s. For each numbered item, compare the translation with its source and report its problems: mistranslations, omissions or additions (accuracy), unnatural or ungrammatical wording (fluency), wrong or inconsistent terms (terminology), and broken placeholders, markup or punctuation (formatting). Report no issue for a correct translation; preferences of style are not issues. Return ONLY a JSON object in this exact format: {\"reviews\": [{\"id\": <item number>, \"issues\": [{\"category\": \"accuracy|fluency|terminology|formatting\", \"severity\": \"minor|major|critical\", \"description\": \"what is wrong\", \"suggestion\": \"corrected translation\"}]}]}"
+
+// Severities of review issues, from the least to the most serious
+const (
+	SeverityMinor    = "minor"
+	SeverityMajor    = "major"
+	SeverityCritical = "critical"
+)
+
+// Severities are the severities of review issues, from the least to the most serious
+var Severities = []string{SeverityMinor, SeverityMajor, SeverityCritical}
+
+// ReviewItem is an existing translation to review, with its source
+type ReviewItem struct {
+	Key         string `json:"key,omitempty"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+}
+
+// Issue is a problem the model found in a translation
+type Issue struct {
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Suggestion  string `json:"suggestion,omitempty"`
+}
+
+// SeverityRank returns the rank of a severity in Severities, -1 when unknown
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Review asks the model for the correctness and fluency issues of translations into
+// lang, in a single request. The issues of each item are returned in order, none for
+// the translations the model found correct.
+func (h *Handler) Review(ctx context.Context, items []ReviewItem, lang string) ([][]Issue, error) {
+	type numbered struct {
+		ID int `json:"id"`
+		ReviewItem
+	}
+	payload := make([]numbered, len(items))
+	for i, item := range items {
+		payload[i] = numbered{ID: i + 1, ReviewItem: item}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.chat(ctx, gogpt.ChatCompletionRequest{
+		Model: h.Model(),
+		Messages: []gogpt.ChatCompletionMessage{
+			{Role: "system", Content: fmt.Sprintf(reviewSystemPrompt, lang)},
+			{Role: "user", Content: string(data)},
+		},
+		Temperature: zeroTemperature,
+		Seed:        h.seed(),
+		MaxTokens:   h.maxTokens(batchMaxTokens),
+		ResponseFormat: &gogpt.ChatCompletionResponseFormat{
+			Type: gogpt.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Reviews []struct {
+			ID     int     `json:"id"`
+			Issues []Issue `json:"issues"`
+		} `json:"reviews"`
+	}
+	if err := json.Unmarshal([]byte(content), &resp); err != nil {
+		return nil, fmt.Errorf("invalid review response: %w", err)
+	}
+	issues := make([][]Issue, len(items))
+	for _, r := range resp.Reviews {
+		if r.ID < 1 || r.ID > len(items) {
+			continue
+		}
+		for _, issue := range r.Issues {
+			issue.Severity = strings.ToLower(strings.TrimSpace(issue.Severity))
+			if SeverityRank(issue.Severity) < 0 {
+				issue.Severity = SeverityMinor
+			}
+			issue.Category = strings.ToLower(strings.TrimSpace(issue.Category))
+			issues[r.ID-1] = append(issues[r.ID-1], issue)
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,45 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReview tests that review issues are mapped back to their item by number
+func TestReview(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req gogpt.ChatCompletionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt = req.Messages[len(req.Messages)-1].Content
+		content := `{"reviews": [{"id": 2, "issues": [{"category": "Accuracy", "severity": "CRITICAL", "description": "meaning reversed", "suggestion": "Enregistrer"}, {"category": "fluency", "severity": "odd", "description": "stiff"}]}, {"id": 9, "issues": [{"severity": "major"}]}]}`
+		data, _ := json.Marshal(content)
+		w.Write([]byte(`{"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": ` + string(data) + `}}]}`))
+	}))
+	defer server.Close()
+
+	h := New(Config{Keys: []string{"sk-test-key"}})
+	clientCfg := gogpt.DefaultConfig("sk-test-key")
+	clientCfg.BaseURL = server.URL
+	clientCfg.HTTPClient = h.http
+	h.clients[0].Client = gogpt.NewClientWithConfig(clientCfg)
+
+	issues, err := h.Review(context.Background(), []ReviewItem{
+		{Key: "home.title", Source: "Welcome", Translation: "Bienvenue"},
+		{Key: "actions.save", Source: "Save", Translation: "Supprimer"},
+	}, "French")
+	require.NoError(t, err)
+	assert.Contains(t, prompt, `"id":2,"key":"actions.save","source":"Save","translation":"Supprimer"`)
+	assert.Empty(t, issues[0])
+	assert.Equal(t, []Issue{
+		{Category: "accuracy", Severity: SeverityCritical, Description: "meaning reversed", Suggestion: "Enregistrer"},
+		{Category: "fluency", Severity: SeverityMinor, Description: "stiff"},
+	}, issues[1])
+}
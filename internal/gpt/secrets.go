@@ -0,0 +1,49 @@
+package gpt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pandodao/i18n-cli/internal/secrets"
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// ErrSecret is returned instead of sending a text that looks like it contains a secret
+var ErrSecret = errors.New("text looks like it contains a secret")
+
+// Secrets returns the secrets found in text; texts containing any are never sent
+func (h *Handler) Secrets(text string) []secrets.Finding {
+	return h.cfg.Secrets.Scan(text)
+}
+
+// screen returns ErrSecret when one of texts contains a secret
+func (h *Handler) screen(texts ...string) error {
+	for _, text := range texts {
+		if findings := h.Secrets(text); len(findings) > 0 {
+			return fmt.Errorf("%w: %s", ErrSecret, findings[0])
+		}
+	}
+	return nil
+}
+
+// screenMessages returns ErrSecret when one of messages contains a secret
+func (h *Handler) screenMessages(messages []gogpt.ChatCompletionMessage) error {
+	for _, m := range messages {
+		if err := h.screen(m.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// screenExamples drops the examples containing a secret: unlike the texts to
+// translate they are optional, so the request can go without them
+func (h *Handler) screenExamples(examples []Example) []Example {
+	kept := make([]Example, 0, len(examples))
+	for _, e := range examples {
+		if h.screen(e.Source, e.Target) == nil {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,27 @@
+package gpt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScreen tests that texts containing secrets are refused before any request is made
+func TestScreen(t *testing.T) {
+	h := New(Config{})
+	ctx := context.Background()
+
+	_, err := h.Translate(ctx, "Your key: sk-abcdefghijklmnopqrstuvwxyz", "French")
+	assert.ErrorIs(t, err, ErrSecret)
+	assert.NotContains(t, err.Error(), "qrstuvwxyz")
+
+	_, err = h.BatchTranslate(ctx, nil, []string{"Hello", "Mail ops@acme.io"}, "French")
+	assert.ErrorIs(t, err, ErrSecret)
+
+	_, err = h.Embed(ctx, []string{"http://10.0.0.1/admin"})
+	assert.ErrorIs(t, err, ErrSecret)
+
+	examples := h.screenExamples([]Example{{Source: "Hi", Target: "Salut"}, {Source: "ops@acme.io", Target: "ops@acme.io"}})
+	assert.Equal(t, []Example{{Source: "Hi", Target: "Salut"}}, examples)
+}
@@ -0,0 +1,73 @@
+package gpt
+
+import (
+	"unicode"
+)
+
+// Price is the cost in USD per million tokens for a model
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Prices lists the known per-model token prices
+var Prices = map[string]Price{
+	"gpt-4o-2024-11-20": {Input: 2.50, Output: 10.00},
+	"gpt-4o":            {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+	"gpt-4.1":           {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+	"gemini-2.0-flash":  {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash":  {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":    {Input: 1.25, Output: 10.00},
+}
+
+// Cost returns the USD cost of the given token counts, or false if the model has no known price
+func Cost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := Prices[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1_000_000, true
+}
+
+// CountTokens approximates the number of tokens the model's tokenizer produces for text.
+// Latin words are counted as roughly one token per four characters, while
+// ideographic, kana and hangul characters and punctuation count as a token each.
+func CountTokens(text string) int {
+	tokens := 0
+	wordLen := 0
+	flush := func() {
+		if wordLen > 0 {
+			tokens += (wordLen + 3) / 4
+			wordLen = 0
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r), unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r), unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			wordLen++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// PromptOverhead returns the approximate number of prompt tokens a request
+// spends on instructions, excluding the texts being translated
+func PromptOverhead(batch bool) int {
+	if batch {
+		return CountTokens(keyedBatchSystemPrompt) + CountTokens(keyedBatchUserPrompt)
+	}
+	return CountTokens(translateSystemPrompt) + CountTokens(translateUserPrompt)
+}
@@ -0,0 +1,159 @@
+package gpt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/tracing"
+)
+
+// newHTTPClient builds the HTTP client shared by all API clients. Requests go through
+// the proxy of cfg when set, and else through the proxy of the HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY environment variables.
+func newHTTPClient(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if cfg.Proxy != nil {
+		transport.Proxy = proxyFunc(cfg.Proxy, cfg.NoProxy)
+	}
+
+	if cfg.RootCAs != nil || cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:            cfg.RootCAs,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		}
+	}
+
+	if cfg.InsecureSkipVerify {
+		fmt.Println("⚠️ ⚠️ ⚠️ TLS certificate verification is DISABLED. Traffic to the API can be intercepted. Do not use this outside of debugging. ⚠️ ⚠️ ⚠️")
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+	if cfg.Polite != nil {
+		client.Transport = &politeTransport{base: transport, polite: *cfg.Polite, timeout: cfg.Timeout}
+		client.Timeout = 0
+	}
+	client.Transport = &retryAfterTransport{base: client.Transport}
+	client.Transport = &tracingTransport{base: client.Transport, provider: cfg.Provider, model: cfg.Model}
+	return client
+}
+
+// tracingTransport records a span per request sent to the provider, in the trace of
+// the request context when it has one
+type tracingTransport struct {
+	base            http.RoundTripper
+	provider, model string
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider := t.provider
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+	ctx, span := tracing.StartClient(req.Context(), "provider.request", "provider", provider, "model", t.model, "http.request.method", req.Method, "server.address", req.URL.Host, "url.path", req.URL.Path)
+	if span == nil {
+		return t.base.RoundTrip(req)
+	}
+	defer span.End()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.Fail(err)
+		return nil, err
+	}
+	span.Set("http.response.status_code", strconv.Itoa(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.Fail(fmt.Errorf("%s", resp.Status))
+	}
+	return resp, nil
+}
+
+// proxyFunc returns the proxy selection sending requests through proxy, except those
+// to loopback hosts and to the hosts of noProxy
+func proxyFunc(proxy *url.URL, noProxy []string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// bypassProxy reports whether requests to host are sent directly: loopback hosts, and
+// hosts matching an entry of noProxy, a host name that also matches its subdomains, a
+// domain with a leading dot, an IP address or a CIDR range, or "*" for every host
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	if host == "localhost" || ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		case host == entry || strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProxy parses the URL of an outbound proxy: an http, https or socks5 URL,
+// optionally with credentials, or a host and port taken as an http proxy
+func ParseProxy(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https or socks5)", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return proxy, nil
+}
+
+// LoadCertPool returns the system certificate pool extended with the PEM
+// certificates of a CA bundle file
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
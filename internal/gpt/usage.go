@@ -0,0 +1,76 @@
+package gpt
+
+import (
+	"context"
+	"time"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// Usage accumulates the token usage and time spent on successful API calls, and the
+// number of failed ones
+type Usage struct {
+	Requests         int           `json:"requests"`
+	PromptTokens     int           `json:"promptTokens"`
+	CompletionTokens int           `json:"completionTokens"`
+	Duration         time.Duration `json:"duration"`
+	Errors           int           `json:"errors,omitempty"`
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Requests:         u.Requests + other.Requests,
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Duration:         u.Duration + other.Duration,
+		Errors:           u.Errors + other.Errors,
+	}
+}
+
+// Usage returns the usage accumulated by the handler so far
+func (h *Handler) Usage() Usage {
+	h.Lock()
+	defer h.Unlock()
+	return h.usage
+}
+
+// UsageByLanguage returns the usage accumulated so far by target language code, for
+// the calls made with a context carrying their languages
+func (h *Handler) UsageByLanguage() map[string]Usage {
+	h.Lock()
+	defer h.Unlock()
+	out := make(map[string]Usage, len(h.byLang))
+	for lang, u := range h.byLang {
+		out[lang] = u
+	}
+	return out
+}
+
+// Cost returns the estimated USD cost of the usage so far, or false if the model has no
+// known price
+func (h *Handler) Cost() (float64, bool) {
+	u := h.Usage()
+	return Cost(h.Model(), u.PromptTokens, u.CompletionTokens)
+}
+
+func (h *Handler) recordUsage(ctx context.Context, u gogpt.Usage, elapsed time.Duration) {
+	call := Usage{Requests: 1, PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, Duration: elapsed}
+	h.Lock()
+	defer h.Unlock()
+	h.usage = h.usage.Add(call)
+	if langs, ok := ctx.Value(languagesKey{}).(languages); ok && langs.target != "" {
+		if h.byLang == nil {
+			h.byLang = map[string]Usage{}
+		}
+		h.byLang[langs.target] = h.byLang[langs.target].Add(call)
+	}
+	h.recordOutcome(false)
+}
+
+func (h *Handler) recordError() {
+	h.Lock()
+	defer h.Unlock()
+	h.usage.Errors++
+	h.recordOutcome(true)
+}
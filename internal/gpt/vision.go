@@ -0,0 +1,106 @@
+package gpt
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gogpt "github.com/sashabaranov/go-openai"
+)
+
+// visionModels are the prefixes of the models accepting images along with texts
+var visionModels = []string{"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-5", "o1", "o3", "o4", "chatgpt-4o", "gemini-", "llava", "llama3.2-vision", "gemma3", "qwen2.5vl", "minicpm-v"}
+
+// textOnlyModels are the models among them that don't accept images
+var textOnlyModels = []string{"o1-mini", "o1-preview", "o3-mini"}
+
+// SupportsImages reports whether model accepts images along with texts
+func SupportsImages(model string) bool {
+	model = strings.ToLower(model)
+	for _, prefix := range textOnlyModels {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	for _, prefix := range visionModels {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type screenshotsKey struct{}
+
+// WithScreenshots returns ctx carrying screenshots showing the texts of keys, as image
+// URLs or files
+func WithScreenshots(ctx context.Context, screenshots map[string]string) context.Context {
+	return context.WithValue(ctx, screenshotsKey{}, screenshots)
+}
+
+// screenshotParts returns the screenshots of keys as message parts, labelled with their
+// key in batches. They are only sent with key context enabled, to models accepting
+// images; screenshots that can't be read are left out with a warning.
+func (h *Handler) screenshotParts(ctx context.Context, keys []string, batch bool) []gogpt.ChatMessagePart {
+	screenshots, _ := ctx.Value(screenshotsKey{}).(map[string]string)
+	if !h.cfg.KeyContext || len(screenshots) == 0 || !SupportsImages(h.Model()) {
+		return nil
+	}
+	parts := []gogpt.ChatMessagePart{}
+	for _, key := range keys {
+		ref := screenshots[key]
+		if ref == "" {
+			continue
+		}
+		url, err := imageURL(ref)
+		if err != nil {
+			fmt.Printf("⚠️ Screenshot of %s left out: %v\n", key, err)
+			continue
+		}
+		label := "Screenshot of the screen showing the text."
+		if batch {
+			label = fmt.Sprintf("Screenshot of the screen showing %q.", key)
+		}
+		parts = append(parts,
+			gogpt.ChatMessagePart{Type: gogpt.ChatMessagePartTypeText, Text: label},
+			gogpt.ChatMessagePart{Type: gogpt.ChatMessagePartTypeImageURL, ImageURL: &gogpt.ChatMessageImageURL{URL: url, Detail: gogpt.ImageURLDetailLow}},
+		)
+	}
+	return parts
+}
+
+// imageURL returns the URL of an image message part showing ref: URLs are sent as they
+// are, files inline as data URLs
+func imageURL(ref string) (string, error) {
+	for _, scheme := range []string{"http://", "https://", "data:"} {
+		if strings.HasPrefix(ref, scheme) {
+			return ref, nil
+		}
+	}
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(ref)))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("%s is not an image", ref)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// userMessage returns the user message of prompt, with parts attached after it
+func userMessage(prompt string, parts []gogpt.ChatMessagePart) gogpt.ChatCompletionMessage {
+	if len(parts) == 0 {
+		return gogpt.ChatCompletionMessage{Role: "user", Content: prompt}
+	}
+	content := append([]gogpt.ChatMessagePart{{Type: gogpt.ChatMessagePartTypeText, Text: prompt}}, parts...)
+	return gogpt.ChatCompletionMessage{Role: "user", MultiContent: content}
+}
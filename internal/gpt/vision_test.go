@@ -0,0 +1,45 @@
+package gpt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogpt "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScreenshots tests that screenshots are attached to the requests of models
+// accepting images, with key context enabled
+func TestScreenshots(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cart.png")
+	require.NoError(t, os.WriteFile(file, []byte("\x89PNG\r\n\x1a\n"), 0644))
+	ctx := WithScreenshots(WithKey(context.Background(), "cart/close"), map[string]string{"cart/close": file, "cart/open": "https://example.com/open.png"})
+
+	h := New(Config{Keys: []string{"key"}, KeyContext: true})
+	req, _ := h.translationRequest(ctx, "Close", "French", nil, nil)
+	parts := req.Messages[len(req.Messages)-1].MultiContent
+	require.Len(t, parts, 3)
+	assert.Equal(t, fmt.Sprintf(translateUserPrompt, "French", "Close"), parts[0].Text)
+	assert.Equal(t, gogpt.ChatMessagePartTypeImageURL, parts[2].Type)
+	assert.True(t, strings.HasPrefix(parts[2].ImageURL.URL, "data:image/png;base64,"))
+
+	batch := h.screenshotParts(ctx, []string{"cart/close", "cart/open", "cart/total"}, true)
+	require.Len(t, batch, 4)
+	assert.Equal(t, `Screenshot of the screen showing "cart/open".`, batch[2].Text)
+	assert.Equal(t, "https://example.com/open.png", batch[3].ImageURL.URL)
+
+	// Nothing is attached for models without vision or without key context
+	h = New(Config{Keys: []string{"key"}, KeyContext: true, Model: "gpt-3.5-turbo"})
+	req, _ = h.translationRequest(ctx, "Close", "French", nil, nil)
+	assert.Nil(t, req.Messages[len(req.Messages)-1].MultiContent)
+	h = New(Config{Keys: []string{"key"}})
+	assert.Empty(t, h.screenshotParts(ctx, []string{"cart/close"}, false))
+
+	assert.True(t, SupportsImages("gemini-2.0-flash"))
+	assert.False(t, SupportsImages("o3-mini"))
+}
@@ -0,0 +1,82 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pandodao/i18n-cli/internal/register"
+)
+
+// webhookModel names the translations of a webhook in audits and forecasts
+const webhookModel = "webhook"
+
+type webhookRequest struct {
+	Texts      []string `json:"texts"`
+	SourceLang string   `json:"sourceLang"`
+	TargetLang string   `json:"targetLang"`
+	// DeepL formality parameter of the register of the target language
+	Formality string `json:"formality,omitempty"`
+}
+
+type webhookResponse struct {
+	Translations []string `json:"translations"`
+}
+
+// webhookTranslate translates texts by posting them with their language codes to the
+// configured URL, which answers with a translation per text in the same order
+func (h *Handler) webhookTranslate(ctx context.Context, texts []string) ([]string, error) {
+	if h.cfg.Endpoint == "" {
+		return nil, fmt.Errorf("no URL configured for the %s provider", ProviderWebhook)
+	}
+	langs, ok := ctx.Value(languagesKey{}).(languages)
+	if !ok || langs.target == "" {
+		return nil, fmt.Errorf("no target language code for %s", ProviderWebhook)
+	}
+
+	formality := register.Formality(h.cfg.Registers.Level(langs.target))
+	body, err := json.Marshal(webhookRequest{Texts: texts, SourceLang: langs.source, TargetLang: langs.target, Formality: formality})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling texts: %w", err)
+	}
+	return h.retryService(ctx, "webhook", len(texts), func(ctx context.Context) ([]string, error) {
+		return h.webhookPost(ctx, body)
+	})
+}
+
+// webhookPost sends a translation request to the webhook, authenticated with the
+// API key as a bearer token if there is one
+func (h *Handler) webhookPost(ctx context.Context, body []byte) ([]string, error) {
+	headers := map[string]string{}
+	if len(h.cfg.Keys) > 0 {
+		headers["Authorization"] = "Bearer " + h.cfg.Keys[0]
+	}
+	for name, value := range h.cfg.Headers {
+		headers[name] = value
+	}
+
+	data, err := h.postJSON(ctx, h.cfg.Endpoint, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	var result webhookResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return result.Translations, nil
+}
+
+// pingWebhook checks the webhook with a one-word translation
+func (h *Handler) pingWebhook(ctx context.Context) KeyStatus {
+	return h.pingService(ctx, func(ctx context.Context) error {
+		if h.cfg.Endpoint == "" {
+			return fmt.Errorf("no URL configured for the %s provider", ProviderWebhook)
+		}
+		body, err := json.Marshal(webhookRequest{Texts: []string{"ping"}, SourceLang: "en", TargetLang: "es"})
+		if err != nil {
+			return err
+		}
+		_, err = h.webhookPost(ctx, body)
+		return err
+	})
+}
@@ -0,0 +1,148 @@
+package gpt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/internal/affix"
+	"github.com/pandodao/i18n-cli/internal/dnt"
+	"github.com/pandodao/i18n-cli/internal/register"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhook tests the request body, authentication, headers and retries of server errors
+func TestWebhook(t *testing.T) {
+	requests := 0
+	var req webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		assert.Equal(t, "/mt", r.URL.Path)
+		assert.Equal(t, "Bearer secret-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "web", r.Header.Get("X-Team"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		translated := make([]string, len(req.Texts))
+		for i, text := range req.Texts {
+			translated[i] = strings.ToUpper(text)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"translations": translated})
+	}))
+	defer server.Close()
+
+	h := New(Config{Provider: ProviderWebhook, Endpoint: server.URL + "/mt", Keys: []string{"secret-key"}, Headers: map[string]string{"X-Team": "web"}})
+	ctx := WithLanguages(context.Background(), "en-US", "pt-BR")
+
+	translations, err := h.BatchTranslate(ctx, nil, []string{"hello", "bye"}, "Português")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HELLO", "BYE"}, translations)
+	assert.Equal(t, webhookRequest{Texts: []string{"hello", "bye"}, SourceLang: "en-US", TargetLang: "pt-BR"}, req)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, h.Usage().Requests)
+	assert.Equal(t, 1, h.Usage().Errors)
+	assert.Equal(t, "webhook", h.Model())
+
+	statuses := h.Ping(context.Background())
+	assert.Len(t, statuses, 1)
+	assert.True(t, statuses[0].ModelAvailable)
+
+	// The register of the target language is sent as the DeepL formality parameter
+	h.cfg.Registers = register.Settings{"pt": register.Formal}
+	_, err = h.Translate(ctx, "hello", "Português")
+	assert.NoError(t, err)
+	assert.Equal(t, "prefer_more", req.Formality)
+}
+
+// TestWebhookError tests client errors and answers missing translations
+func TestWebhookError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "missing token"}`))
+			return
+		}
+		w.Write([]byte(`{"translations": ["only one"]}`))
+	}))
+	defer server.Close()
+
+	ctx := WithLanguages(context.Background(), "en", "fr")
+	_, err := New(Config{Provider: ProviderWebhook, Endpoint: server.URL}).Translate(ctx, "hello", "French")
+	assert.ErrorContains(t, err, "missing token")
+	assert.Equal(t, 1, requests)
+
+	h := New(Config{Provider: ProviderWebhook, Endpoint: server.URL, Keys: []string{"key"}})
+	_, err = h.BatchTranslate(ctx, nil, []string{"a", "b"}, "French")
+	assert.ErrorContains(t, err, "expected 2 translations, got 1")
+
+	_, err = New(Config{Provider: ProviderWebhook}).Translate(ctx, "hello", "French")
+	assert.ErrorContains(t, err, "no URL")
+}
+
+// TestWebhookProtectedTokens tests that protected tokens never reach the provider and
+// that texts losing them are translated again, then failed
+func TestWebhookProtectedTokens(t *testing.T) {
+	sent := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req webhookRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		translated := make([]string, len(req.Texts))
+		for i, text := range req.Texts {
+			sent = append(sent, text)
+			translated[i] = strings.ToUpper(strings.ReplaceAll(text, "{DNT_0} ", ""))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"translations": translated})
+	}))
+	defer server.Close()
+
+	protect, err := dnt.Compile(dnt.List{Terms: []string{"Acme"}})
+	assert.NoError(t, err)
+	h := New(Config{Provider: ProviderWebhook, Endpoint: server.URL, Protect: protect})
+
+	ctx := WithLanguages(context.Background(), "en", "fr")
+	translations, err := h.BatchTranslate(ctx, nil, []string{"buy Acme", "Acme rocks"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BUY Acme", ""}, translations)
+	assert.Equal(t, []string{"buy {DNT_0}", "{DNT_0} rocks", "{DNT_0} rocks", "{DNT_0} rocks"}, sent)
+
+	_, err = h.Translate(ctx, "Acme rocks", "French")
+	assert.EqualError(t, err, `protected term "Acme" was lost in translation`)
+}
+
+// TestWebhookAffixes tests that affixes never reach the provider and are attached to
+// translations as the target language writes them
+func TestWebhookAffixes(t *testing.T) {
+	sent := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req webhookRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		translated := make([]string, len(req.Texts))
+		for i, text := range req.Texts {
+			sent = append(sent, text)
+			translated[i] = strings.ToUpper(text)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"translations": translated})
+	}))
+	defer server.Close()
+
+	affixes := &affix.Settings{Languages: map[string]map[string]string{"fr": {"MB": "Mo"}}}
+	h := New(Config{Provider: ProviderWebhook, Endpoint: server.URL, Affixes: affixes})
+
+	ctx := WithLanguages(context.Background(), "en", "fr")
+	translations, err := h.BatchTranslate(ctx, nil, []string{"Loading…", "Name:", "used {size} MB"}, "French")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"LOADING…", "NAME\u00a0:", "USED {SIZE} Mo"}, translations)
+
+	translation, err := h.Translate(ctx, "• Step one", "French")
+	assert.NoError(t, err)
+	assert.Equal(t, "• STEP ONE", translation)
+	assert.Equal(t, []string{"Loading", "Name", "used {size}", "Step one"}, sent)
+}
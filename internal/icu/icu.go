@@ -0,0 +1,236 @@
+package icu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Argument is a placeholder of an ICU MessageFormat message, e.g. {count, number}
+type Argument struct {
+	Name  string
+	Type  string
+	Style string
+}
+
+// String renders the argument the way it appears in the message, without sub-messages
+func (a Argument) String() string {
+	switch {
+	case a.Type == "":
+		return "{" + a.Name + "}"
+	case a.Style == "" || complexType(a.Type):
+		return "{" + a.Name + ", " + a.Type + "}"
+	}
+	return "{" + a.Name + ", " + a.Type + ", " + a.Style + "}"
+}
+
+// complexType reports whether arguments of a type hold sub-messages rather than a style
+func complexType(t string) bool {
+	return t == "plural" || t == "select" || t == "selectordinal"
+}
+
+// Arguments returns the arguments of a message, including the ones nested in plural
+// and select sub-messages, in order of appearance
+func Arguments(msg string) ([]Argument, error) {
+	p := &msgParser{src: []rune(msg)}
+	if err := p.message(false); err != nil {
+		return nil, err
+	}
+	return p.args, nil
+}
+
+// Check verifies the translation keeps the argument names and types of the source,
+// and the styles of number, date and time arguments. Sources that aren't ICU
+// messages are not checked.
+func Check(source, translation string) error {
+	want, err := Arguments(source)
+	if err != nil || len(want) == 0 {
+		return nil
+	}
+	got, err := Arguments(translation)
+	if err != nil {
+		return fmt.Errorf("translation is not a valid ICU message: %w", err)
+	}
+
+	wanted := signatures(want)
+	found := signatures(got)
+	var missing, unexpected []string
+	for sig := range wanted {
+		if _, ok := found[sig]; !ok {
+			missing = append(missing, sig)
+		}
+	}
+	for sig := range found {
+		if _, ok := wanted[sig]; !ok {
+			unexpected = append(unexpected, sig)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return &ArgumentsError{Missing: missing, Unexpected: unexpected}
+}
+
+// ArgumentsError is a translation whose arguments differ from those of its source
+type ArgumentsError struct {
+	// Arguments of the source the translation lacks, e.g. {count, number}
+	Missing []string
+	// Arguments of the translation not in the source
+	Unexpected []string
+}
+
+func (e *ArgumentsError) Error() string {
+	parts := []string{}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, " "))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Unexpected, " "))
+	}
+	return fmt.Sprintf("ICU arguments changed in translation: %s", strings.Join(parts, ", "))
+}
+
+// signatures returns the distinct arguments of a message. Plural branches differ
+// between languages, so arguments are compared as a set.
+func signatures(args []Argument) map[string]struct{} {
+	set := make(map[string]struct{}, len(args))
+	for _, a := range args {
+		set[a.String()] = struct{}{}
+	}
+	return set
+}
+
+type msgParser struct {
+	src  []rune
+	pos  int
+	args []Argument
+}
+
+// message parses message text up to the end, or up to the closing brace of a
+// sub-message when nested
+func (p *msgParser) message(nested bool) error {
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch r {
+		case '\'':
+			p.quoted()
+		case '{':
+			p.pos++
+			if err := p.argument(); err != nil {
+				return err
+			}
+		case '}':
+			if nested {
+				p.pos++
+				return nil
+			}
+			return fmt.Errorf("unmatched '}' at offset %d", p.pos)
+		default:
+			p.pos++
+		}
+	}
+	if nested {
+		return fmt.Errorf("unclosed sub-message")
+	}
+	return nil
+}
+
+// quoted skips an apostrophe escape: a doubled apostrophe is a literal one and an apostrophe
+// before a syntax character quotes text up to the next single apostrophe
+func (p *msgParser) quoted() {
+	p.pos++
+	if p.pos >= len(p.src) {
+		return
+	}
+	switch p.src[p.pos] {
+	case '\'':
+		p.pos++
+	case '{', '}', '#', '|':
+		for p.pos < len(p.src) {
+			if p.src[p.pos] == '\'' {
+				if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+					p.pos += 2
+					continue
+				}
+				p.pos++
+				return
+			}
+			p.pos++
+		}
+	}
+}
+
+// argument parses an argument after its opening brace
+func (p *msgParser) argument() error {
+	start := p.pos - 1
+	name := strings.TrimSpace(p.until(",}"))
+	if name == "" || strings.ContainsAny(name, " \t\n{") {
+		return fmt.Errorf("invalid argument name at offset %d", start)
+	}
+	if p.pos >= len(p.src) {
+		return fmt.Errorf("unclosed argument %q", name)
+	}
+	if p.src[p.pos] == '}' {
+		p.pos++
+		p.args = append(p.args, Argument{Name: name})
+		return nil
+	}
+
+	p.pos++
+	argType := strings.TrimSpace(p.until(",}"))
+	if argType == "" || p.pos >= len(p.src) {
+		return fmt.Errorf("unclosed argument %q", name)
+	}
+	arg := Argument{Name: name, Type: argType}
+	if p.src[p.pos] == '}' {
+		p.pos++
+		p.args = append(p.args, arg)
+		return nil
+	}
+
+	p.pos++
+	if !complexType(argType) {
+		arg.Style = strings.TrimSpace(p.until("}"))
+		if p.pos >= len(p.src) {
+			return fmt.Errorf("unclosed argument %q", name)
+		}
+		p.pos++
+		p.args = append(p.args, arg)
+		return nil
+	}
+
+	// Selectors followed by sub-messages, up to the closing brace of the argument
+	p.args = append(p.args, arg)
+	for {
+		selector := strings.TrimSpace(p.until("{}"))
+		if p.pos >= len(p.src) {
+			return fmt.Errorf("unclosed argument %q", name)
+		}
+		if p.src[p.pos] == '}' {
+			if selector != "" {
+				return fmt.Errorf("selector %q of argument %q has no sub-message", selector, name)
+			}
+			p.pos++
+			return nil
+		}
+		if selector == "" {
+			return fmt.Errorf("sub-message of argument %q has no selector", name)
+		}
+		p.pos++
+		if err := p.message(true); err != nil {
+			return err
+		}
+	}
+}
+
+// until consumes runes up to, not including, one of the stop runes
+func (p *msgParser) until(stop string) string {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(stop, p.src[p.pos]) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
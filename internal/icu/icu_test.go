@@ -0,0 +1,49 @@
+package icu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestArguments tests extracting arguments, including nested ones
+func TestArguments(t *testing.T) {
+	args, err := Arguments("{count, number} files on {date, date, long} by {user}")
+	assert.NoError(t, err)
+	assert.Equal(t, []Argument{
+		{Name: "count", Type: "number"},
+		{Name: "date", Type: "date", Style: "long"},
+		{Name: "user"},
+	}, args)
+
+	args, err = Arguments("{count, plural, offset:1 =0 {No files} one {# file in {folder}} other {# files}} '{literal}' it''s")
+	assert.NoError(t, err)
+	assert.Equal(t, []Argument{
+		{Name: "count", Type: "plural"},
+		{Name: "folder"},
+	}, args)
+
+	_, err = Arguments("{count, plural, one {# file}")
+	assert.Error(t, err)
+}
+
+// TestCheck tests comparing argument names and types between source and translation
+func TestCheck(t *testing.T) {
+	source := "{count, number} files on {date, date, long}"
+
+	assert.NoError(t, Check(source, "{count, number} fichiers le {date, date, long}"))
+	assert.NoError(t, Check(source, "{date, date, long} に {count, number} 個のファイル"))
+	assert.NoError(t, Check("Plain text", "Texte simple"))
+	assert.NoError(t, Check("{{name}} is not ICU", "{{nom}}"))
+
+	// Plural branches differ between languages
+	assert.NoError(t, Check("{n, plural, one {# file} other {# files}}", "{n, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}"))
+
+	assert.EqualError(t, Check(source, "{count} fichiers le {date, date, short}"),
+		"ICU arguments changed in translation: missing {count, number} {date, date, long}, unexpected {count} {date, date, short}")
+	var argsErr *ArgumentsError
+	assert.ErrorAs(t, Check(source, "{nombre, number} fichiers le {date, date, long}"), &argsErr)
+	assert.Equal(t, []string{"{count, number}"}, argsErr.Missing)
+	assert.Equal(t, []string{"{nombre, number}"}, argsErr.Unexpected)
+	assert.Error(t, Check(source, "{count, number} fichiers le {date, date, long"))
+}
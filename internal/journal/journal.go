@@ -0,0 +1,120 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/gpt"
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const stateFile = "runs.json"
+
+// maxRuns is the number of runs kept in the journal, the oldest ones are dropped
+const maxRuns = 1000
+
+// Run statuses
+const (
+	StatusCompleted = "completed"
+	// The run stopped early, e.g. once the error budget or the cost limit was exhausted
+	StatusAborted = "aborted"
+	// Some files of the run, or writing them, failed
+	StatusFailed = "failed"
+)
+
+// Run is the record of a run translating catalogs
+type Run struct {
+	// ID of the run, the one of its changes in the change logs
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	// Command line arguments and the flags set on it
+	Args  []string          `json:"args"`
+	Flags map[string]string `json:"flags,omitempty"`
+	// Configuration file and the hash of its content, to tell apart runs with other settings
+	Config     string `json:"config,omitempty"`
+	ConfigHash string `json:"configHash,omitempty"`
+	User       string `json:"user,omitempty"`
+	Provider   string `json:"provider,omitempty"`
+	Model      string `json:"model,omitempty"`
+
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Usage     gpt.Usage     `json:"usage"`
+	Files     []File        `json:"files,omitempty"`
+}
+
+// File is the outcome of a run for a target file
+type File struct {
+	Path string `json:"path"`
+	// Source keys processed
+	Keys int `json:"keys"`
+	// Keys the run translated
+	Translated int `json:"translated"`
+	// Keys left untranslated
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Translated returns the number of keys the run translated
+func (r Run) Translated() int {
+	n := 0
+	for _, f := range r.Files {
+		n += f.Translated
+	}
+	return n
+}
+
+// Failed returns the number of keys the run left untranslated
+func (r Run) Failed() int {
+	n := 0
+	for _, f := range r.Files {
+		n += len(f.Failed)
+	}
+	return n
+}
+
+// Load returns the runs of the project, oldest first
+func Load() ([]Run, error) {
+	var runs []Run
+	if err := state.Load(stateFile, &runs); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.Before(runs[j].StartedAt) })
+	return runs, nil
+}
+
+// Record appends a run to the journal, dropping the oldest runs
+func Record(run Run) error {
+	runs, err := Load()
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > maxRuns {
+		runs = runs[len(runs)-maxRuns:]
+	}
+	return state.Save(stateFile, runs)
+}
+
+// Find returns the run of runs whose ID is id or starts with it, which must be unambiguous
+func Find(runs []Run, id string) (Run, error) {
+	var found []Run
+	for _, r := range runs {
+		if r.ID == id {
+			return r, nil
+		}
+		if strings.HasPrefix(r.ID, id) {
+			found = append(found, r)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Run{}, fmt.Errorf("no run %s", id)
+	case 1:
+		return found[0], nil
+	}
+	return Run{}, fmt.Errorf("%d runs start with %s", len(found), id)
+}
@@ -0,0 +1,63 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecord tests that runs are kept oldest first, up to maxRuns
+func TestRecord(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	runs, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	require.NoError(t, Record(Run{ID: "b", StartedAt: start.Add(time.Hour)}))
+	require.NoError(t, Record(Run{ID: "a", StartedAt: start}))
+	runs, err = Load()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "a", runs[0].ID)
+	assert.Equal(t, "b", runs[1].ID)
+
+	for i := 0; i < maxRuns; i++ {
+		require.NoError(t, Record(Run{ID: "c", StartedAt: start.Add(2 * time.Hour)}))
+	}
+	runs, err = Load()
+	require.NoError(t, err)
+	assert.Len(t, runs, maxRuns)
+	assert.Equal(t, "c", runs[0].ID)
+}
+
+// TestFind tests lookups by ID and unambiguous prefix
+func TestFind(t *testing.T) {
+	runs := []Run{{ID: "20240501T120000-aaaa"}, {ID: "20240501T120000-aaaa-2"}, {ID: "20240502T080000-bbbb"}}
+
+	run, err := Find(runs, "20240501T120000-aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, "20240501T120000-aaaa", run.ID)
+
+	run, err = Find(runs, "20240502")
+	require.NoError(t, err)
+	assert.Equal(t, "20240502T080000-bbbb", run.ID)
+
+	_, err = Find(runs, "20240501T")
+	assert.Error(t, err)
+	_, err = Find(runs, "2023")
+	assert.Error(t, err)
+}
+
+// TestRunTotals tests the totals of the files of a run
+func TestRunTotals(t *testing.T) {
+	run := Run{Files: []File{
+		{Path: "fr.json", Keys: 3, Translated: 2, Failed: []string{"a"}},
+		{Path: "de.json", Keys: 3, Translated: 3},
+	}}
+	assert.Equal(t, 5, run.Translated())
+	assert.Equal(t, 1, run.Failed())
+}
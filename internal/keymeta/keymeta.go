@@ -0,0 +1,193 @@
+package keymeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Named character sets a key's translations may be restricted to
+const (
+	// CharsetASCII allows printable ASCII characters and whitespace
+	CharsetASCII = "ascii"
+	// CharsetLatin1 allows the characters of ISO 8859-1
+	CharsetLatin1 = "latin1"
+	// CharsetIdentifier allows ASCII letters, digits, "-", "_" and "."
+	CharsetIdentifier = "identifier"
+)
+
+// Meta is the metadata of a catalog key
+type Meta struct {
+	// Characters translations are restricted to: a named set (ascii, latin1,
+	// identifier) or a regular expression character class such as "[A-Z0-9-]".
+	// Translations with other characters are replaced by the source text.
+	Charset string `json:"charset,omitempty"`
+	// What the text is and where it is shown, e.g. "Button closing the cart drawer",
+	// sent as context with its translations when key context is enabled
+	Description string `json:"description,omitempty"`
+	// Page of the application showing the text, sent as context like the description
+	URL string `json:"url,omitempty"`
+	// Screenshot showing the text: an image URL or file, sent to models accepting images
+	// when key context is enabled
+	Screenshot string `json:"screenshot,omitempty"`
+	// Separator of values listing allowed values, such as "|" in "Low|Medium|High".
+	// Each item is translated on its own, and the translation keeps their number and order.
+	ListDelimiter string `json:"listDelimiter,omitempty"`
+	// Characters translations may take at most, for texts shown in a limited space such
+	// as buttons or push notifications (0 = no limit)
+	MaxLength int `json:"maxLength,omitempty"`
+}
+
+// Describe returns the description of the key followed by the page showing it
+func (m Meta) Describe() string {
+	switch {
+	case m.URL == "":
+		return m.Description
+	case m.Description == "":
+		return "Shown on " + m.URL
+	}
+	return m.Description + " (shown on " + m.URL + ")"
+}
+
+// Rules maps key patterns to metadata. Patterns are flattened keys ("product/sku")
+// or path.Match globs ("product/*/sku"). When several patterns match a key, the
+// fields of the longest pattern win.
+type Rules map[string]Meta
+
+// Load reads a key metadata file, an object of rules like the "keys" of the
+// configuration. Screenshot files are relative to the metadata file.
+func Load(file string) (Rules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("error parsing key metadata %s: %w", file, err)
+	}
+	if r == nil {
+		r = Rules{}
+	}
+	for pattern, meta := range r {
+		if meta.Screenshot != "" && !isURL(meta.Screenshot) && !filepath.IsAbs(meta.Screenshot) {
+			meta.Screenshot = filepath.Join(filepath.Dir(file), meta.Screenshot)
+			r[pattern] = meta
+		}
+	}
+	return r, nil
+}
+
+// isURL reports whether a screenshot is an image URL rather than a file
+func isURL(ref string) bool {
+	for _, scheme := range []string{"http://", "https://", "data:"} {
+		if strings.HasPrefix(ref, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the patterns and charsets of the rules
+func (r Rules) Validate() error {
+	for pattern, meta := range r {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+		if meta.Charset != "" {
+			if _, err := compileCharset(meta.Charset); err != nil {
+				return fmt.Errorf("key pattern %q: %w", pattern, err)
+			}
+		}
+		if meta.MaxLength < 0 {
+			return fmt.Errorf("key pattern %q: maxLength must not be negative, got %d", pattern, meta.MaxLength)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the merged metadata of the patterns matching key
+func (r Rules) Lookup(key string) Meta {
+	patterns := make([]string, 0, len(r))
+	for pattern := range r {
+		if ok, _ := path.Match(pattern, key); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	// Shortest first, so longer (more specific) patterns override
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) < len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	meta := Meta{}
+	for _, pattern := range patterns {
+		m := r[pattern]
+		if m.Charset != "" {
+			meta.Charset = m.Charset
+		}
+		if m.Description != "" {
+			meta.Description = m.Description
+		}
+		if m.URL != "" {
+			meta.URL = m.URL
+		}
+		if m.Screenshot != "" {
+			meta.Screenshot = m.Screenshot
+		}
+		if m.ListDelimiter != "" {
+			meta.ListDelimiter = m.ListDelimiter
+		}
+		if m.MaxLength > 0 {
+			meta.MaxLength = m.MaxLength
+		}
+	}
+	return meta
+}
+
+// Allows reports whether every character of value belongs to the charset. An empty
+// charset allows everything.
+func Allows(charset, value string) bool {
+	if charset == "" {
+		return true
+	}
+	allowed, err := compileCharset(charset)
+	if err != nil {
+		return false
+	}
+	for _, r := range value {
+		if !allowed(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func compileCharset(charset string) (func(rune) bool, error) {
+	switch strings.ToLower(charset) {
+	case CharsetASCII:
+		return func(r rune) bool { return r < unicode.MaxASCII && (unicode.IsPrint(r) || unicode.IsSpace(r)) }, nil
+	case CharsetLatin1:
+		return func(r rune) bool { return r <= unicode.MaxLatin1 && (unicode.IsPrint(r) || unicode.IsSpace(r)) }, nil
+	case CharsetIdentifier:
+		return func(r rune) bool {
+			return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.')
+		}, nil
+	}
+
+	if !strings.HasPrefix(charset, "[") || !strings.HasSuffix(charset, "]") {
+		return nil, fmt.Errorf("unknown charset %q (expected ascii, latin1, identifier or a character class like [A-Z0-9-])", charset)
+	}
+	re, err := regexp.Compile("^" + charset + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid charset %q: %w", charset, err)
+	}
+	return func(r rune) bool { return re.MatchString(string(r)) }, nil
+}
@@ -0,0 +1,66 @@
+package keymeta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLookup tests patterns are merged with the most specific one winning
+func TestLookup(t *testing.T) {
+	rules := Rules{
+		"product/*":     {Charset: CharsetLatin1},
+		"product/*/sku": {Charset: "[A-Z0-9-]"},
+		"product/code":  {Charset: CharsetIdentifier},
+	}
+	assert.NoError(t, rules.Validate())
+
+	assert.Equal(t, CharsetIdentifier, rules.Lookup("product/code").Charset)
+	assert.Equal(t, CharsetLatin1, rules.Lookup("product/name").Charset)
+	assert.Equal(t, "[A-Z0-9-]", rules.Lookup("product/shoe/sku").Charset)
+	assert.Equal(t, "", rules.Lookup("home/title").Charset)
+
+	limits := Rules{"push/*": {MaxLength: 110}, "push/*/title": {MaxLength: 40}, "push/promo": {Description: "Weekly promotion"}}
+	assert.Equal(t, 40, limits.Lookup("push/sale/title").MaxLength)
+	assert.Equal(t, Meta{Description: "Weekly promotion", MaxLength: 110}, limits.Lookup("push/promo"))
+	assert.EqualError(t, Rules{"button/*": {MaxLength: -1}}.Validate(), `key pattern "button/*": maxLength must not be negative, got -1`)
+
+	// Fields are merged separately
+	rules["product/*"] = Meta{Charset: CharsetLatin1, Description: "Field of the product page"}
+	assert.Equal(t, Meta{Charset: CharsetIdentifier, Description: "Field of the product page"}, rules.Lookup("product/code"))
+
+	assert.Error(t, Rules{"a/[": {}}.Validate())
+	assert.Error(t, Rules{"a": {Charset: "emoji"}}.Validate())
+}
+
+// TestAllows tests the charsets
+func TestAllows(t *testing.T) {
+	assert.True(t, Allows("", "日本語"))
+	assert.True(t, Allows(CharsetASCII, "SKU 1042, blue"))
+	assert.False(t, Allows(CharsetASCII, "Café"))
+	assert.True(t, Allows(CharsetLatin1, "Café"))
+	assert.False(t, Allows(CharsetLatin1, "Кафе"))
+	assert.True(t, Allows(CharsetIdentifier, "shoe_blue-42.v2"))
+	assert.False(t, Allows(CharsetIdentifier, "shoe blue"))
+	assert.True(t, Allows("[A-Z0-9-]", "AB-1042"))
+	assert.False(t, Allows("[A-Z0-9-]", "ab-1042"))
+}
+
+// TestLoad tests that screenshot files are relative to the metadata file
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "keys.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{
+		"cart/close": {"description": "Button closing the cart", "url": "https://shop.example/cart", "screenshot": "shots/cart.png"},
+		"cart/open": {"screenshot": "https://cdn.example/open.png"}
+	}`), 0644))
+
+	rules, err := Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "shots", "cart.png"), rules["cart/close"].Screenshot)
+	assert.Equal(t, "https://cdn.example/open.png", rules["cart/open"].Screenshot)
+	assert.Equal(t, "Button closing the cart (shown on https://shop.example/cart)", rules["cart/close"].Describe())
+	assert.Equal(t, "Shown on https://shop.example/cart", Meta{URL: "https://shop.example/cart"}.Describe())
+}
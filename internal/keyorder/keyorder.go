@@ -0,0 +1,63 @@
+package keyorder
+
+import (
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collations for sorted output
+const (
+	// CollationBinary orders keys by their bytes
+	CollationBinary = "binary"
+	// CollationLocale orders keys by the collation rules of a language
+	CollationLocale = "locale"
+)
+
+// SortOptions controls the key order of rewritten files
+type SortOptions struct {
+	// Collation is binary (default) or locale
+	Collation string `json:"collation,omitempty"`
+	// Locale used for locale collation, defaults to the file's language
+	Locale string `json:"locale,omitempty"`
+	// Keys placed first in every object, in this order, e.g. "_meta"
+	Pinned []string `json:"pinned,omitempty"`
+}
+
+// Binary reports whether opts orders keys by their bytes, without pinned keys
+func (opts SortOptions) Binary() bool {
+	return (opts.Collation == "" || opts.Collation == CollationBinary) && len(opts.Pinned) == 0
+}
+
+// Less returns the key order of opts, pinned keys first, collating by the language of
+// code unless opts has a locale
+func (opts SortOptions) Less(code string) func(a, b string) bool {
+	collated := func(a, b string) bool { return a < b }
+	if opts.Collation == CollationLocale {
+		locale := opts.Locale
+		if locale == "" {
+			locale = code
+		}
+		tag, err := language.Parse(locale)
+		if err != nil {
+			tag = language.Und
+		}
+		c := collate.New(tag)
+		collated = func(a, b string) bool { return c.CompareString(a, b) < 0 }
+	}
+
+	pinned := make(map[string]int, len(opts.Pinned))
+	for i, k := range opts.Pinned {
+		pinned[k] = i
+	}
+	return func(a, b string) bool {
+		pa, aPinned := pinned[a]
+		pb, bPinned := pinned[b]
+		switch {
+		case aPinned && bPinned:
+			return pa < pb
+		case aPinned != bPinned:
+			return aPinned
+		}
+		return collated(a, b)
+	}
+}
@@ -0,0 +1,26 @@
+package keyorder
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLess tests that pinned keys come first in their order, followed by the others in
+// byte or locale order
+func TestLess(t *testing.T) {
+	sorted := func(opts SortOptions, code string) []string {
+		keys := []string{"zebra", "Élan", "_meta", "apple", "id"}
+		less := opts.Less(code)
+		sort.SliceStable(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+		return keys
+	}
+
+	assert.True(t, SortOptions{}.Binary())
+	assert.False(t, SortOptions{Pinned: []string{"id"}}.Binary())
+	assert.Equal(t, []string{"_meta", "apple", "id", "zebra", "Élan"}, sorted(SortOptions{}, "fr"))
+	assert.Equal(t, []string{"id", "_meta", "apple", "zebra", "Élan"}, sorted(SortOptions{Pinned: []string{"id", "_meta"}}, "fr"))
+	assert.Equal(t, []string{"_meta", "apple", "Élan", "id", "zebra"}, sorted(SortOptions{Collation: CollationLocale}, "fr"))
+	assert.Equal(t, []string{"_meta", "apple", "Élan", "id", "zebra"}, sorted(SortOptions{Collation: CollationLocale, Locale: "xx-invalid-"}, "fr"))
+}
@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RuleDuplicate flags distinct keys sharing a translation while their source texts
+// differ, a sign of copy-paste or of the model repeating itself
+const RuleDuplicate = "duplicate"
+
+// RuleDuplicateKey flags keys defined more than once in the same object of a file:
+// parsers keep one of the values and silently drop the others
+const RuleDuplicateKey = "duplicate-key"
+
+// Duplicates checks a target catalog against its source for keys translated
+// identically although their source texts differ. Source texts differing only in
+// case or surrounding whitespace are considered the same. Issues are sorted by key.
+func Duplicates(source, target map[string]string) []Issue {
+	byValue := map[string][]string{}
+	for k, v := range target {
+		if _, ok := source[k]; !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		byValue[v] = append(byValue[v], k)
+	}
+
+	issues := []Issue{}
+	for value, keys := range byValue {
+		if len(keys) < 2 {
+			continue
+		}
+		sources := map[string]bool{}
+		for _, k := range keys {
+			sources[normalize(source[k])] = true
+		}
+		if len(sources) < 2 {
+			continue
+		}
+
+		sort.Strings(keys)
+		for _, k := range keys {
+			others := make([]string, 0, len(keys)-1)
+			for _, other := range keys {
+				if other != k && normalize(source[other]) != normalize(source[k]) {
+					others = append(others, other)
+				}
+			}
+			issues = append(issues, Issue{Key: k, Rule: RuleDuplicate, Message: fmt.Sprintf("%q is also the translation of %s, whose source differs", value, strings.Join(others, ", "))})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,31 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/localefmt"
+)
+
+// RuleFormat flags number and date examples copied from the source ("e.g. 1,234.56")
+// where the target language writes them otherwise
+const RuleFormat = "format"
+
+// Formats checks a target catalog in language to against its source in language from
+// for number and date examples written in the source conventions. Issues are sorted by
+// key.
+func Formats(source, target map[string]string, from, to string) []Issue {
+	issues := []Issue{}
+	for k, v := range target {
+		if v == "" || source[k] == "" || v == source[k] {
+			continue
+		}
+		for _, ex := range localefmt.Examples(source[k], v, from, to) {
+			issues = append(issues, Issue{Key: k, Rule: RuleFormat, Message: fmt.Sprintf("%q is written %q in %s", ex.Found, ex.Expected, to)})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
@@ -0,0 +1,29 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/escaping"
+)
+
+// RuleSyntax flags translations breaking the message syntax of the frontend framework
+// that their source keeps valid, such as an unescaped @ for vue-i18n
+const RuleSyntax = "syntax"
+
+// Framework checks a target catalog against its source for values breaking the message
+// syntax of f, which crash or garble the frontend at runtime. Issues are sorted by key.
+func Framework(source, target map[string]string, f escaping.Framework) []Issue {
+	issues := []Issue{}
+	for k, v := range target {
+		if v == "" || source[k] == "" {
+			continue
+		}
+		if err := f.Check(source[k], v); err != nil {
+			issues = append(issues, Issue{Key: k, Rule: RuleSyntax, Message: err.Error()})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
@@ -0,0 +1,30 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/glossary"
+)
+
+// RuleGlossary flags translations not rendering a glossary term of their source as
+// the glossary requires
+const RuleGlossary = "glossary"
+
+// Glossary checks a target catalog in lang against its source for glossary terms
+// translated otherwise than required. Issues are sorted by key.
+func Glossary(source, target map[string]string, g glossary.Glossary, lang string) []Issue {
+	issues := []Issue{}
+	for k, v := range target {
+		if v == "" || source[k] == "" {
+			continue
+		}
+		for _, e := range g.Violations(source[k], v, lang) {
+			issues = append(issues, Issue{Key: k, Rule: RuleGlossary, Message: fmt.Sprintf("%q must be translated as %q", e.Term, e.Translation)})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
@@ -0,0 +1,32 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/internal/secrets"
+)
+
+// RuleSecret flags values that look like they contain a secret: API keys, tokens,
+// credentials, email addresses or internal URLs committed by mistake
+const RuleSecret = "secret"
+
+// Secrets checks a catalog for values containing secrets. Messages are redacted, so
+// reports can be shared without leaking them further. Issues are sorted by key.
+func Secrets(items map[string]string, opts secrets.Options) []Issue {
+	issues := []Issue{}
+	for k, v := range items {
+		findings := opts.Scan(v)
+		if len(findings) == 0 {
+			continue
+		}
+		found := make([]string, len(findings))
+		for i, f := range findings {
+			found[i] = f.String()
+		}
+		issues = append(issues, Issue{Key: k, Rule: RuleSecret, Message: fmt.Sprintf("value looks like it contains a secret (%s); it is never sent to providers", strings.Join(found, ", "))})
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
@@ -0,0 +1,153 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Source lint rules
+const (
+	// RuleFragment flags values starting or ending with a space, a sign they are
+	// concatenated with other strings ("Hello " + name) instead of using a placeholder
+	RuleFragment = "fragment"
+	// RuleWhitespace flags other leading or trailing whitespace (tabs, repeated spaces)
+	RuleWhitespace = "whitespace"
+	// RuleNewline flags values with embedded line breaks
+	RuleNewline = "newline"
+	// RuleCase flags labels whose capitalization differs from the rest of the catalog
+	RuleCase = "case"
+	// RuleDebug flags developer placeholders and debug strings
+	RuleDebug = "debug"
+)
+
+// Issue is a problem found in a catalog value
+type Issue struct {
+	Key     string `json:"key"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// debugValues are whole values that are never meant for users
+var debugValues = map[string]bool{
+	"test": true, "testing": true, "todo": true, "fixme": true, "tbd": true, "xxx": true,
+	"foo": true, "bar": true, "baz": true, "asdf": true, "placeholder": true,
+	"undefined": true, "null": true, "nil": true, "nan": true, "[object object]": true,
+}
+
+// debugMarkers are contained in debug strings
+var debugMarkers = regexp.MustCompile(`(?i)\b(todo|fixme)\b|lorem ipsum|\[object object\]`)
+
+// identifiers match values that are message keys rather than text, e.g. "home.title"
+var identifiers = regexp.MustCompile(`^[a-z0-9]+([._/][a-zA-Z0-9]+)+$`)
+
+// placeholders are ignored when looking at words
+var placeholders = regexp.MustCompile(`\{[^{}]*\}|%[-+# 0-9.]*[a-zA-Z]|<[^<>]*>`)
+
+// Capitalization styles of labels
+const (
+	titleCase    = "Title Case"
+	sentenceCase = "Sentence case"
+)
+
+// Source checks a source catalog for strings that are likely to translate badly.
+// Issues are sorted by key then rule.
+func Source(items map[string]string) []Issue {
+	issues := []Issue{}
+	styles := map[string]string{}
+
+	for k, v := range items {
+		if v == "" {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(v)
+		switch {
+		case trimmed != "" && (strings.HasPrefix(v, " ") && !strings.HasPrefix(v, "  ") || strings.HasSuffix(v, " ") && !strings.HasSuffix(v, "  ")):
+			issues = append(issues, Issue{Key: k, Rule: RuleFragment, Message: fmt.Sprintf("%q looks like a fragment concatenated with other text; use a placeholder instead", v)})
+		case trimmed != v:
+			issues = append(issues, Issue{Key: k, Rule: RuleWhitespace, Message: fmt.Sprintf("%q has leading or trailing whitespace", v)})
+		}
+
+		if strings.ContainsAny(trimmed, "\r\n") {
+			issues = append(issues, Issue{Key: k, Rule: RuleNewline, Message: "value contains a line break; translators and layouts may not preserve it"})
+		}
+
+		if debugValues[strings.ToLower(trimmed)] || debugMarkers.MatchString(v) || identifiers.MatchString(trimmed) {
+			issues = append(issues, Issue{Key: k, Rule: RuleDebug, Message: fmt.Sprintf("%q looks like a developer placeholder", v)})
+		}
+
+		if style := caseStyle(trimmed); style != "" {
+			styles[k] = style
+		}
+	}
+
+	// Flag labels that don't follow the dominant capitalization style
+	counts := map[string]int{}
+	for _, style := range styles {
+		counts[style]++
+	}
+	dominant := ""
+	if counts[titleCase] >= 2*counts[sentenceCase] && counts[titleCase] > 0 {
+		dominant = titleCase
+	} else if counts[sentenceCase] >= 2*counts[titleCase] && counts[sentenceCase] > 0 {
+		dominant = sentenceCase
+	}
+	if dominant != "" {
+		for k, style := range styles {
+			if style != dominant {
+				issues = append(issues, Issue{Key: k, Rule: RuleCase, Message: fmt.Sprintf("%q is in %s while most labels are in %s", items[k], style, dominant)})
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+	return issues
+}
+
+// caseStyle returns the capitalization style of a short label, or "" for sentences
+// and values whose style can't be told
+func caseStyle(v string) string {
+	if strings.ContainsAny(v, ".!?:;") {
+		return ""
+	}
+
+	fields := strings.Fields(placeholders.ReplaceAllString(v, ""))
+	if len(fields) < 2 || len(fields) > 6 {
+		return ""
+	}
+	if first := []rune(fields[0])[0]; !unicode.IsUpper(first) {
+		return ""
+	}
+
+	// Short words such as "of" or "to" stay lower case in titles
+	words, upper := 0, 0
+	for _, w := range fields[1:] {
+		r := []rune(w)
+		if len(r) <= 3 || !unicode.IsLetter(r[0]) {
+			continue
+		}
+		words++
+		if unicode.IsUpper(r[0]) {
+			upper++
+		}
+	}
+	if words == 0 {
+		return ""
+	}
+
+	switch upper {
+	case words:
+		return titleCase
+	case 0:
+		return sentenceCase
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pandodao/i18n-cli/internal/icu"
+	"github.com/pandodao/i18n-cli/internal/markup"
+	"github.com/pandodao/i18n-cli/internal/placeholder"
+)
+
+// Target lint rules
+const (
+	// RulePlaceholder flags translations not keeping the ICU arguments or the other
+	// placeholders of their source
+	RulePlaceholder = "placeholder"
+	// RuleMarkup flags translations dropping, renaming or misnesting the HTML tags and
+	// Markdown of their source
+	RuleMarkup = "markup"
+	// RuleUntranslated flags translations identical to a source text of several words
+	RuleUntranslated = "untranslated"
+	// RuleEmpty flags translations left empty while their source isn't
+	RuleEmpty = "empty"
+	// RuleStale flags keys of the target the source no longer has
+	RuleStale = "stale"
+	// RuleEscape flags invalid JSON escapes, which make the file unreadable, and escape
+	// sequences written out literally ("\\n") where the source has the character
+	RuleEscape = "escape"
+)
+
+// literalEscapes match escape sequences spelled out in a value, a sign it went through
+// JSON encoding twice
+var literalEscapes = regexp.MustCompile(`\\[nrt"]|\\u[0-9a-fA-F]{4}`)
+
+// Target checks a target catalog against its source for broken placeholders and markup,
+// values left untranslated or empty, stale keys and double-escaped values. Issues are
+// sorted by key then rule.
+func Target(source, target map[string]string) []Issue {
+	issues := []Issue{}
+	for k, v := range target {
+		src, ok := source[k]
+		switch {
+		case !ok:
+			issues = append(issues, Issue{Key: k, Rule: RuleStale, Message: "not in the source catalog anymore"})
+			continue
+		case strings.TrimSpace(v) == "":
+			if strings.TrimSpace(src) != "" {
+				issues = append(issues, Issue{Key: k, Rule: RuleEmpty, Message: "empty translation"})
+			}
+			continue
+		case v == src:
+			if words(src) > 1 {
+				issues = append(issues, Issue{Key: k, Rule: RuleUntranslated, Message: fmt.Sprintf("same as the source %q", src)})
+			}
+			continue
+		}
+
+		if err := icu.Check(src, v); err != nil {
+			issues = append(issues, Issue{Key: k, Rule: RulePlaceholder, Message: err.Error()})
+		} else if err := placeholder.Check(src, v); err != nil {
+			issues = append(issues, Issue{Key: k, Rule: RulePlaceholder, Message: err.Error()})
+		}
+		if err := markup.Check(src, v); err != nil {
+			issues = append(issues, Issue{Key: k, Rule: RuleMarkup, Message: err.Error()})
+		}
+		if seq := literalEscapes.FindString(v); seq != "" && !strings.Contains(src, seq) {
+			issues = append(issues, Issue{Key: k, Rule: RuleEscape, Message: fmt.Sprintf("contains %s written out, the value looks escaped twice", seq)})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+	return issues
+}
+
+// words returns the number of words of text with letters, placeholders and tags left out
+func words(text string) int {
+	n := 0
+	for _, w := range strings.Fields(placeholders.ReplaceAllString(text, " ")) {
+		if strings.IndexFunc(w, unicode.IsLetter) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Escapes checks the raw content of a JSON file for invalid escape sequences in its
+// strings, such as "\x41" or "\'", which make the whole file fail to parse. Issues have
+// the line of the sequence as key.
+func Escapes(data []byte) []Issue {
+	issues := []Issue{}
+	line, inString := 1, false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '\n':
+			line++
+		case c == '"':
+			inString = !inString
+		case c == '\\' && inString:
+			if i+1 >= len(data) {
+				break
+			}
+			if seq, ok := validEscape(data[i+1:]); !ok {
+				issues = append(issues, Issue{Key: fmt.Sprintf("line %d", line), Rule: RuleEscape, Message: fmt.Sprintf("invalid JSON escape %q", `\`+seq)})
+			}
+			// The escaped character can't end the string
+			i++
+		}
+	}
+	return issues
+}
+
+// validEscape returns the escape sequence at the start of rest, which follows a
+// backslash, and whether JSON allows it
+func validEscape(rest []byte) (string, bool) {
+	switch rest[0] {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		return string(rest[:1]), true
+	case 'u':
+		end := 5
+		if len(rest) < end {
+			end = len(rest)
+		}
+		for _, c := range rest[1:end] {
+			if !strings.ContainsRune("0123456789abcdefABCDEF", rune(c)) {
+				return string(rest[:end]), false
+			}
+		}
+		return string(rest[:end]), end == 5
+	}
+	return string(rest[:1]), false
+}
@@ -0,0 +1,32 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/templating"
+)
+
+// RuleTemplate flags translations not keeping the template variables of their source,
+// and sources using variables the values file doesn't define
+const RuleTemplate = "template"
+
+// Templates checks a target catalog against its source for template variables
+// changed in translation or missing from values. Issues are sorted by key.
+func Templates(source, target map[string]string, values templating.Values) []Issue {
+	issues := []Issue{}
+	if values == nil {
+		return issues
+	}
+	for k, v := range target {
+		if v == "" || source[k] == "" {
+			continue
+		}
+		if err := values.Check(source[k], v); err != nil {
+			issues = append(issues, Issue{Key: k, Rule: RuleTemplate, Message: err.Error()})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+	return issues
+}
@@ -0,0 +1,346 @@
+package localefmt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Example is a number or date example of a source, copied as written into a
+// translation although the target language writes it otherwise
+type Example struct {
+	// As written in the source and the translation
+	Found string
+	// As the target language writes it
+	Expected string
+}
+
+// Error is a translation copying number or date examples of its source where the
+// target language writes them otherwise
+type Error struct {
+	Lang     string
+	Examples []Example
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Examples))
+	for i, ex := range e.Examples {
+		parts[i] = fmt.Sprintf("%s as %s", ex.Found, ex.Expected)
+	}
+	return fmt.Sprintf("examples copied in the source format, %s writes %s", e.Lang, strings.Join(parts, ", "))
+}
+
+// Check returns an *Error when translation, into language to, copies number or date
+// examples of source, written in language from, that to writes otherwise
+func Check(source, translation, from, to string) error {
+	examples := Examples(source, translation, from, to)
+	if len(examples) == 0 {
+		return nil
+	}
+	return &Error{Lang: to, Examples: examples}
+}
+
+// Examples returns the number and date examples of source that translation, into
+// language to, copies as written although to writes them otherwise. Numbers are those
+// with digit grouping ("1,234", "1.234.567,8") or two decimals ("9.99"), formatted
+// with the CLDR conventions of to; dates are numeric ones in the short format of from,
+// the source language ("12/31/2024" in English). Languages written with other digits
+// than 0-9 aren't checked, nor dates where either language has no known format.
+func Examples(source, translation, from, to string) []Example {
+	examples := []Example{}
+	seen := map[string]bool{}
+	add := func(literal, expected string) {
+		if expected == "" || expected == literal || seen[literal] || findLiteral(translation, literal, 0) < 0 {
+			return
+		}
+		seen[literal] = true
+		examples = append(examples, Example{Found: literal, Expected: expected})
+	}
+	for _, literal := range numberLiterals(source) {
+		add(literal, formatNumber(literal, to))
+	}
+	if fromFormat, ok := dateFormatOf(from); ok {
+		if toFormat, ok := dateFormatOf(to); ok {
+			for _, literal := range fromFormat.literals(source) {
+				add(literal, fromFormat.convert(literal, toFormat))
+			}
+		}
+	}
+	return examples
+}
+
+// Rewrite returns translation with the examples of source it copies written as
+// language to does
+func Rewrite(source, translation, from, to string) string {
+	examples := Examples(source, translation, from, to)
+	// Longer literals first, so that 1,234 doesn't rewrite part of 1,234.56
+	for i := 1; i < len(examples); i++ {
+		for j := i; j > 0 && len(examples[j].Found) > len(examples[j-1].Found); j-- {
+			examples[j], examples[j-1] = examples[j-1], examples[j]
+		}
+	}
+	for _, ex := range examples {
+		var b strings.Builder
+		start := 0
+		for at := findLiteral(translation, ex.Found, 0); at >= 0; at = findLiteral(translation, ex.Found, start) {
+			b.WriteString(translation[start:at])
+			b.WriteString(ex.Expected)
+			start = at + len(ex.Found)
+		}
+		b.WriteString(translation[start:])
+		translation = b.String()
+	}
+	return translation
+}
+
+// findLiteral returns the index of the first occurrence of literal in text from from
+// on that isn't part of a longer number, -1 when there is none
+func findLiteral(text, literal string, from int) int {
+	for from <= len(text) {
+		i := strings.Index(text[from:], literal)
+		if i < 0 {
+			return -1
+		}
+		start, end := from+i, from+i+len(literal)
+		if !continuesNumber(text[:start], true) && !continuesNumber(text[end:], false) {
+			return start
+		}
+		from = start + 1
+	}
+	return -1
+}
+
+// continuesNumber reports whether text, before a literal when before is true and after
+// it otherwise, extends the number: a digit, a Latin letter as in v1.20, or a separator
+// next to a digit
+func continuesNumber(text string, before bool) bool {
+	next := func(s string) (rune, string) {
+		if before {
+			r, size := utf8.DecodeLastRuneInString(s)
+			return r, s[:len(s)-size]
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		return r, s[size:]
+	}
+	if text == "" {
+		return false
+	}
+	r, rest := next(text)
+	if unicode.IsDigit(r) || (r < utf8.RuneSelf && unicode.IsLetter(r)) {
+		return true
+	}
+	if (r == '.' || r == ',' || r == '/' || r == '-') && rest != "" {
+		if r2, _ := next(rest); unicode.IsDigit(r2) {
+			return true
+		}
+	}
+	return false
+}
+
+var numberPattern = regexp.MustCompile(`\d+(?:[.,]\d+)+`)
+
+// numberLiterals returns the number examples of text
+func numberLiterals(text string) []string {
+	literals := []string{}
+	for _, loc := range numberPattern.FindAllStringIndex(text, -1) {
+		literal := text[loc[0]:loc[1]]
+		if continuesNumber(text[:loc[0]], true) || continuesNumber(text[loc[1]:], false) {
+			continue
+		}
+		if _, _, ok := parseNumber(literal); ok {
+			literals = append(literals, literal)
+		}
+	}
+	return literals
+}
+
+// parseNumber returns the integer and fraction digits of a number literal, telling its
+// grouping and decimal separators apart
+func parseNumber(literal string) (integer, fraction string, ok bool) {
+	decimal := byte(0)
+	last := strings.LastIndexAny(literal, ".,")
+	switch {
+	case strings.Contains(literal, ".") && strings.Contains(literal, ","):
+		// The last separator is the decimal one, and appears once
+		decimal = literal[last]
+		if strings.IndexByte(literal, decimal) != last {
+			return "", "", false
+		}
+	case strings.Count(literal, ".")+strings.Count(literal, ",") == 1:
+		integerPart, after := literal[:last], literal[last+1:]
+		switch {
+		case len(after) == 3 && integerPart != "0" && len(integerPart) <= 3:
+			// A thousands separator
+		case len(after) == 2:
+			decimal = literal[last]
+		default:
+			return "", "", false
+		}
+	}
+
+	grouped := literal
+	if decimal != 0 {
+		grouped, fraction = literal[:last], literal[last+1:]
+	}
+	groups := strings.FieldsFunc(grouped, func(r rune) bool { return r == '.' || r == ',' })
+	for i, g := range groups {
+		if (i == 0 && len(g) > 3) || (i > 0 && len(g) != 3) {
+			return "", "", false
+		}
+	}
+	return strings.Join(groups, ""), fraction, true
+}
+
+// formatNumber returns a number literal as lang writes it, empty when lang isn't known
+// or isn't written with the digits 0-9
+func formatNumber(literal, lang string) string {
+	integer, fraction, ok := parseNumber(literal)
+	if !ok {
+		return ""
+	}
+	tag, err := language.Parse(strings.ReplaceAll(lang, "_", "-"))
+	if err != nil {
+		return ""
+	}
+	value, err := strconv.ParseFloat(integer+"."+fraction+"0", 64)
+	if err != nil {
+		return ""
+	}
+	formatted := message.NewPrinter(tag).Sprint(number.Decimal(value, number.Scale(len(fraction))))
+	for _, r := range formatted {
+		if unicode.IsDigit(r) && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return formatted
+}
+
+// dateFormat is the short numeric date format of a language
+type dateFormat struct {
+	// Order of the day, month and year: DMY, MDY or YMD
+	order string
+	sep   string
+	// Days and months written with two digits
+	pad bool
+	// Written after the year, such as the final "." of Korean dates
+	suffix string
+}
+
+// dateFormats are the short date formats of CLDR of common languages, by lowercase
+// language code. English outside the United States goes by en-gb.
+var dateFormats = map[string]dateFormat{
+	"en":    {order: "MDY", sep: "/"},
+	"en-gb": {order: "DMY", sep: "/", pad: true},
+	"en-ca": {order: "YMD", sep: "-", pad: true},
+	"de":    {order: "DMY", sep: ".", pad: true},
+	"fr":    {order: "DMY", sep: "/", pad: true},
+	"es":    {order: "DMY", sep: "/"},
+	"it":    {order: "DMY", sep: "/", pad: true},
+	"pt":    {order: "DMY", sep: "/", pad: true},
+	"nl":    {order: "DMY", sep: "-", pad: true},
+	"ru":    {order: "DMY", sep: ".", pad: true},
+	"uk":    {order: "DMY", sep: ".", pad: true},
+	"pl":    {order: "DMY", sep: ".", pad: true},
+	"tr":    {order: "DMY", sep: ".", pad: true},
+	"da":    {order: "DMY", sep: ".", pad: true},
+	"nb":    {order: "DMY", sep: ".", pad: true},
+	"fi":    {order: "DMY", sep: "."},
+	"sv":    {order: "YMD", sep: "-", pad: true},
+	"ja":    {order: "YMD", sep: "/", pad: true},
+	"zh":    {order: "YMD", sep: "/"},
+	"ko":    {order: "YMD", sep: ". ", suffix: "."},
+	"hu":    {order: "YMD", sep: ". ", pad: true, suffix: "."},
+}
+
+// dateFormatOf returns the date format of lang: that of its code, then of its base
+// language
+func dateFormatOf(lang string) (dateFormat, bool) {
+	code := strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	if f, ok := dateFormats[code]; ok {
+		return f, true
+	}
+	base, region, _ := strings.Cut(code, "-")
+	if base == "en" && region != "" && region != "us" {
+		return dateFormats["en-gb"], true
+	}
+	f, ok := dateFormats[base]
+	return f, ok
+}
+
+// pattern matches dates written in f
+func (f dateFormat) pattern() *regexp.Regexp {
+	parts := make([]string, len(f.order))
+	for i, c := range f.order {
+		parts[i] = `(\d{1,2})`
+		if c == 'Y' {
+			parts[i] = `(\d{4})`
+		}
+	}
+	return regexp.MustCompile(strings.Join(parts, regexp.QuoteMeta(f.sep)) + regexp.QuoteMeta(f.suffix))
+}
+
+// literals returns the dates of text written in f
+func (f dateFormat) literals(text string) []string {
+	literals := []string{}
+	for _, loc := range f.pattern().FindAllStringIndex(text, -1) {
+		literal := text[loc[0]:loc[1]]
+		if continuesNumber(text[:loc[0]], true) || continuesNumber(text[loc[1]:], false) {
+			continue
+		}
+		if _, _, _, ok := f.parse(literal); ok {
+			literals = append(literals, literal)
+		}
+	}
+	return literals
+}
+
+// parse returns the year, month and day of a date written in f
+func (f dateFormat) parse(literal string) (year, month, day int, ok bool) {
+	match := f.pattern().FindStringSubmatch(literal)
+	if match == nil {
+		return 0, 0, 0, false
+	}
+	for i, c := range f.order {
+		n, _ := strconv.Atoi(match[i+1])
+		switch c {
+		case 'Y':
+			year = n
+		case 'M':
+			month = n
+		case 'D':
+			day = n
+		}
+	}
+	return year, month, day, month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
+
+// convert returns a date written in f as to writes it
+func (f dateFormat) convert(literal string, to dateFormat) string {
+	year, month, day, ok := f.parse(literal)
+	if !ok {
+		return ""
+	}
+	digits := "%d"
+	if to.pad {
+		digits = "%02d"
+	}
+	parts := make([]string, len(to.order))
+	for i, c := range to.order {
+		switch c {
+		case 'Y':
+			parts[i] = strconv.Itoa(year)
+		case 'M':
+			parts[i] = fmt.Sprintf(digits, month)
+		case 'D':
+			parts[i] = fmt.Sprintf(digits, day)
+		}
+	}
+	return strings.Join(parts, to.sep) + to.suffix
+}
@@ -0,0 +1,66 @@
+package localefmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExamples tests finding number and date examples copied in the source format
+func TestExamples(t *testing.T) {
+	source := "Enter an amount, e.g. 1,234.56, and a date such as 12/31/2024. Version 2.1 needs 9,000 users and 3.5 GB."
+
+	assert.Equal(t, []Example{
+		{Found: "1,234.56", Expected: "1.234,56"},
+		{Found: "9,000", Expected: "9.000"},
+		{Found: "12/31/2024", Expected: "31.12.2024"},
+	}, Examples(source, "Betrag eingeben, z. B. 1,234.56, und ein Datum wie 12/31/2024. Version 2.1 braucht 9,000 Nutzer und 3.5 GB.", "en", "de"))
+
+	// Examples the translation already localizes aren't reported
+	assert.Empty(t, Examples(source, "Betrag eingeben, z. B. 1.234,56, und ein Datum wie 31.12.2024. Version 2.1 braucht 9.000 Nutzer.", "en", "de"))
+	// Nor those of targets writing them the same way
+	assert.Empty(t, Examples("Pay 1,234.56 by 12/31/2024", "Pay 1,234.56 by 12/31/2024", "en", "en-US"))
+	// Nor numbers of languages written with other digits
+	assert.Empty(t, Examples("Pay 1,234.56", "ادفع 1,234.56", "en", "ar"))
+
+	assert.Equal(t, []Example{{Found: "1,234.56", Expected: "1 234,56"}, {Found: "1/5/2024", Expected: "05/01/2024"}},
+		Examples("Pay 1,234.56 by 1/5/2024", "Payez 1,234.56 avant le 1/5/2024", "en", "fr"))
+	assert.Equal(t, []Example{{Found: "1.234,56", Expected: "1,234.56"}, {Found: "31.12.2024", Expected: "2024/12/31"}},
+		Examples("Zum Beispiel 1.234,56 am 31.12.2024", "例えば 1.234,56 を 31.12.2024 に", "de", "ja"))
+	assert.Equal(t, []Example{{Found: "12/31/2024", Expected: "2024. 12. 31."}},
+		Examples("Due 12/31/2024", "마감 12/31/2024", "en", "ko"))
+}
+
+// TestParseNumber tests telling grouping from decimal separators apart
+func TestParseNumber(t *testing.T) {
+	for literal, want := range map[string][2]string{
+		"1,234":        {"1234", ""},
+		"1.234.567":    {"1234567", ""},
+		"1,234.56":     {"1234", "56"},
+		"1.234,5":      {"1234", "5"},
+		"9.99":         {"9", "99"},
+		"12,50":        {"12", "50"},
+		"1,234,567.89": {"1234567", "89"},
+	} {
+		integer, fraction, ok := parseNumber(literal)
+		assert.True(t, ok, literal)
+		assert.Equal(t, want, [2]string{integer, fraction}, literal)
+	}
+	for _, literal := range []string{"3.5", "0.125", "1234.567", "1,23,456", "1.2.3", "1,234.5.6"} {
+		_, _, ok := parseNumber(literal)
+		assert.False(t, ok, literal)
+	}
+}
+
+// TestRewrite tests writing copied examples in the target format
+func TestRewrite(t *testing.T) {
+	source := "Between 1,234 and 1,234.56, before 12/31/2024"
+	assert.Equal(t, "Entre 1.234 y 1.234,56, antes del 31/12/2024",
+		Rewrite(source, "Entre 1,234 y 1,234.56, antes del 12/31/2024", "en", "es"))
+	// Longer numbers containing an example are left alone
+	assert.Equal(t, "Entre 1.234 y 11,234", Rewrite("Between 1,234 and more", "Entre 1,234 y 11,234", "en", "es"))
+
+	err := Check(source, "Entre 1,234 y 1,234.56", "en", "es")
+	assert.EqualError(t, err, "examples copied in the source format, es writes 1,234 as 1.234, 1,234.56 as 1.234,56")
+	assert.NoError(t, Check(source, "Entre 1.234 y 1.234,56", "en", "es"))
+}
@@ -0,0 +1,160 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileName is the name of the lock file created in a project directory
+const FileName = ".i18n-cli.lock"
+
+// StaleAfter is the age after which a lock is considered abandoned even if its
+// process can't be proven dead, e.g. when it was taken on another machine
+const StaleAfter = 12 * time.Hour
+
+// Info describes the run holding a lock
+type Info struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+// HeldError is returned when another live run holds the lock
+type HeldError struct {
+	Path string
+	Info Info
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("%s is held by %q (pid %d on %s) since %s; remove it with --force-unlock if that run is gone",
+		e.Path, e.Info.Command, e.Info.PID, e.Info.Host, e.Info.Started.Local().Format(time.RFC3339))
+}
+
+// Lock is a held lock file
+type Lock struct {
+	Path string
+}
+
+// Acquire creates the lock file at path. A lock left behind by a run that no longer
+// exists, or older than StaleAfter, is taken over.
+func Acquire(path, command string) (*Lock, error) {
+	host, _ := os.Hostname()
+	info := Info{PID: os.Getpid(), Host: host, Command: command, Started: time.Now()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, werr := f.Write(data)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(path)
+				return nil, errors.Join(werr, cerr)
+			}
+			return &Lock{Path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		held, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+		if !Stale(held, host) {
+			return nil, &HeldError{Path: path, Info: held}
+		}
+		if err := takeOver(path, held, host); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("could not acquire %s", path)
+}
+
+// takeOver moves the stale lock file at path, read as held, out of the way so that it
+// can be created anew. Another run may have taken the lock over since it was read, so
+// the file is renamed away atomically and put back unless it is still a stale lock.
+func takeOver(path string, held Info, host string) error {
+	moved := fmt.Sprintf("%s.stale-%d", path, os.Getpid())
+	if err := os.Rename(path, moved); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(moved)
+
+	current, err := Read(moved)
+	if err != nil {
+		return err
+	}
+	if current.same(held) || Stale(current, host) {
+		return nil
+	}
+	// Yet another run may hold the lock by now, whose file is kept
+	if err := os.Link(moved, path); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return &HeldError{Path: path, Info: current}
+}
+
+// same reports whether i and other describe the same run
+func (i Info) same(other Info) bool {
+	return i.PID == other.PID && i.Host == other.Host && i.Command == other.Command && i.Started.Equal(other.Started)
+}
+
+// Read returns the run holding the lock file at path. A corrupt lock file is
+// reported as an empty lock, which is stale.
+func Read(path string) (Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, nil
+	}
+	return info, nil
+}
+
+// Stale reports whether a lock was abandoned: its process is gone (when taken on
+// this host) or it is older than StaleAfter
+func Stale(info Info, host string) bool {
+	if info.PID == 0 || time.Since(info.Started) > StaleAfter {
+		return true
+	}
+	if info.Host == host && !processAlive(info.PID) {
+		return true
+	}
+	return false
+}
+
+// Release removes the lock file
+func (l *Lock) Release() error {
+	if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ForceUnlock removes a lock file whoever holds it. It returns the removed lock's holder.
+func ForceUnlock(path string) (Info, bool, error) {
+	info, err := Read(path)
+	if os.IsNotExist(err) {
+		return Info{}, false, nil
+	}
+	if err != nil {
+		return Info{}, false, err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return Info{}, false, err
+	}
+	return info, true, nil
+}
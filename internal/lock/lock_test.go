@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAcquire tests that a held lock blocks a second run until it is released
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	l, err := Acquire(path, "sync")
+	assert.NoError(t, err)
+
+	_, err = Acquire(path, "sync")
+	var held *HeldError
+	assert.True(t, errors.As(err, &held))
+	assert.Equal(t, os.Getpid(), held.Info.PID)
+
+	assert.NoError(t, l.Release())
+	l, err = Acquire(path, "sync")
+	assert.NoError(t, err)
+	assert.NoError(t, l.Release())
+}
+
+// TestAcquireStale tests taking over locks of dead or old runs
+func TestAcquireStale(t *testing.T) {
+	host, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), FileName)
+
+	for _, info := range []Info{
+		// A process that no longer exists on this host
+		{PID: 1 << 30, Host: host, Started: time.Now()},
+		// An old lock from another machine
+		{PID: 1, Host: "ci-runner-7", Started: time.Now().Add(-2 * StaleAfter)},
+	} {
+		data, _ := json.Marshal(info)
+		assert.NoError(t, os.WriteFile(path, data, 0644))
+
+		l, err := Acquire(path, "sync")
+		assert.NoError(t, err)
+		assert.NoError(t, l.Release())
+	}
+
+	// A recent lock from another machine is respected
+	data, _ := json.Marshal(Info{PID: 1, Host: "ci-runner-7", Started: time.Now()})
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	_, err := Acquire(path, "sync")
+	assert.Error(t, err)
+
+	info, removed, err := ForceUnlock(path)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, "ci-runner-7", info.Host)
+}
+
+// TestTakeOver tests that a stale lock is moved away, but the lock of a run that took
+// it over in the meantime is put back
+func TestTakeOver(t *testing.T) {
+	host, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), FileName)
+	stale := Info{PID: 1 << 30, Host: host, Started: time.Now()}
+	data, _ := json.Marshal(stale)
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+
+	assert.NoError(t, takeOver(path, stale, host))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	live := Info{PID: os.Getpid(), Host: host, Command: "sync", Started: time.Now()}
+	data, _ = json.Marshal(live)
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	err = takeOver(path, stale, host)
+	var held *HeldError
+	assert.True(t, errors.As(err, &held))
+	assert.Equal(t, "sync", held.Info.Command)
+	info, err := Read(path)
+	assert.NoError(t, err)
+	assert.True(t, info.same(live))
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,189 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options selects the parts of Markdown documents that are translated
+type Options struct {
+	// Frontmatter fields to translate, nested fields separated by dots ("params.summary")
+	Fields []string `json:"fields,omitempty"`
+	// Translate the body of documents too
+	Body bool `json:"body,omitempty"`
+}
+
+// DefaultFields are the frontmatter fields translated when none are configured
+var DefaultFields = []string{"title", "description"}
+
+// Document is a Markdown file with an optional YAML frontmatter
+type Document struct {
+	frontmatter *yaml.Node
+	Body        string
+}
+
+// Parse splits a Markdown file into its YAML frontmatter, delimited by "---" lines
+// at the top of the file, and its body
+func Parse(data []byte) (*Document, error) {
+	text := string(data)
+	first, rest, found := cutLine(text)
+	if !found || strings.TrimSpace(first) != "---" {
+		return &Document{Body: text}, nil
+	}
+
+	var yamlText strings.Builder
+	for rest != "" {
+		var line string
+		line, rest, _ = cutLine(rest)
+		if trimmed := strings.TrimSpace(line); trimmed == "---" || trimmed == "..." {
+			node := &yaml.Node{}
+			if err := yaml.Unmarshal([]byte(yamlText.String()), node); err != nil {
+				return nil, fmt.Errorf("invalid frontmatter: %w", err)
+			}
+			if len(node.Content) == 0 {
+				node = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+			}
+			if node.Content[0].Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("invalid frontmatter: expected a mapping")
+			}
+			return &Document{frontmatter: node, Body: rest}, nil
+		}
+		yamlText.WriteString(line)
+	}
+	return nil, fmt.Errorf("invalid frontmatter: missing closing ---")
+}
+
+// cutLine returns the first line of s including its line ending, and the rest
+func cutLine(s string) (string, string, bool) {
+	i := strings.IndexByte(s, '\n')
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i+1], s[i+1:], true
+}
+
+// Field returns the string value of a frontmatter field
+func (d *Document) Field(name string) (string, bool) {
+	node := d.lookup(name)
+	if node == nil || node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// SetField replaces the value of an existing string frontmatter field
+func (d *Document) SetField(name, value string) bool {
+	node := d.lookup(name)
+	if node == nil || node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		return false
+	}
+	node.Value = value
+	return true
+}
+
+func (d *Document) lookup(name string) *yaml.Node {
+	if d.frontmatter == nil {
+		return nil
+	}
+	node := d.frontmatter.Content[0]
+	for _, part := range strings.Split(name, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// Bytes renders the document. Frontmatter fields keep their order and comments.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if d.frontmatter != nil {
+		buf.WriteString("---\n")
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(d.frontmatter); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+	}
+	buf.WriteString(d.Body)
+	return buf.Bytes(), nil
+}
+
+// Block is a part of a Markdown body: a paragraph-like run of lines, or text that
+// must be kept verbatim (blank lines, fenced code, shortcodes and HTML comments)
+type Block struct {
+	Text     string
+	Verbatim bool
+}
+
+// Blocks splits a body into blocks. Concatenating the texts of the blocks gives the body back.
+func Blocks(body string) []Block {
+	blocks := []Block{}
+	var current strings.Builder
+	verbatim := false
+	fence := ""
+
+	flush := func() {
+		if current.Len() > 0 {
+			blocks = append(blocks, Block{Text: current.String(), Verbatim: verbatim})
+			current.Reset()
+		}
+	}
+	start := func(v bool) {
+		if current.Len() > 0 && verbatim != v {
+			flush()
+		}
+		verbatim = v
+	}
+
+	for rest := body; rest != ""; {
+		var line string
+		line, rest, _ = cutLine(rest)
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fence != "":
+			current.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			verbatim = true
+			fence = trimmed[:3]
+		case trimmed == "" || isMarkup(trimmed):
+			start(true)
+		default:
+			start(false)
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return blocks
+}
+
+// isMarkup reports whether a line holds no translatable text of its own
+func isMarkup(line string) bool {
+	return strings.HasPrefix(line, "{{") && strings.HasSuffix(line, "}}") ||
+		strings.HasPrefix(line, "<!--") && strings.HasSuffix(line, "-->") ||
+		strings.Trim(line, "-*_ ") == ""
+}
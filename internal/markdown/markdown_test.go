@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `---
+title: Getting started # shown in the menu
+description: "Install the CLI, then run it"
+weight: 10
+params:
+  summary: A short tour
+---
+# Getting started
+
+Install the CLI with:
+
+` + "```bash\ngo install ./...\n\n# done\n```" + `
+
+{{< note >}}
+Run it from the project root.
+`
+
+// TestParse tests frontmatter fields are read and written back in place
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	require.NoError(t, err)
+
+	title, ok := doc.Field("title")
+	assert.True(t, ok)
+	assert.Equal(t, "Getting started", title)
+	summary, ok := doc.Field("params.summary")
+	assert.True(t, ok)
+	assert.Equal(t, "A short tour", summary)
+	_, ok = doc.Field("weight")
+	assert.False(t, ok, "numbers are not translatable")
+	_, ok = doc.Field("missing")
+	assert.False(t, ok)
+
+	assert.True(t, doc.SetField("title", "Premiers pas"))
+	assert.False(t, doc.SetField("weight", "dix"))
+
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "---\ntitle: Premiers pas # shown in the menu\n"))
+	assert.Contains(t, string(out), "weight: 10\n")
+	assert.True(t, strings.HasSuffix(string(out), "---\n"+doc.Body))
+	assert.True(t, strings.HasPrefix(doc.Body, "# Getting started\n"))
+
+	plain, err := Parse([]byte("# No frontmatter\n"))
+	require.NoError(t, err)
+	_, ok = plain.Field("title")
+	assert.False(t, ok)
+	out, err = plain.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, "# No frontmatter\n", string(out))
+
+	_, err = Parse([]byte("---\ntitle: x\n"))
+	assert.Error(t, err)
+}
+
+// TestBlocks tests bodies are split into translatable and verbatim blocks
+func TestBlocks(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	require.NoError(t, err)
+
+	blocks := Blocks(doc.Body)
+	joined := ""
+	translatable := []string{}
+	for _, b := range blocks {
+		joined += b.Text
+		if !b.Verbatim {
+			translatable = append(translatable, strings.TrimSpace(b.Text))
+		}
+	}
+	assert.Equal(t, doc.Body, joined)
+	assert.Equal(t, []string{"# Getting started", "Install the CLI with:", "Run it from the project root."}, translatable)
+}
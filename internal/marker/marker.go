@@ -0,0 +1,272 @@
+package marker
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+)
+
+// Marker styles
+const (
+	// StylePrefix flags a key by prefixing its translated value, e.g. "!Bonjour"
+	StylePrefix = "prefix"
+	// StyleSuffix flags a key by appending a suffix to its name, e.g. "greeting:retranslate"
+	StyleSuffix = "suffix"
+	// StyleSidecar lists flagged keys in a separate file next to the locale file
+	StyleSidecar = "sidecar"
+	// StyleNone disables retranslation markers
+	StyleNone = "none"
+)
+
+// Defaults for each style
+const (
+	DefaultPrefix = "!"
+	DefaultSuffix = ":retranslate"
+)
+
+// SidecarExt is appended to a locale file path to get its sidecar file
+const SidecarExt = ".retranslate"
+
+// FS reads and writes the sidecar files of locale files, so that runs writing their
+// catalogs together can write the sidecars along with them
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+	Remove(path string) error
+}
+
+// OS is the file system of the machine
+type OS struct{}
+
+func (OS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+func (OS) WriteFile(path string, data []byte) error { return os.WriteFile(path, data, 0644) }
+
+func (OS) Remove(path string) error { return os.Remove(path) }
+
+// Marker describes how keys are flagged for retranslation in target files
+type Marker struct {
+	Style  string `json:"style"`
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// Default returns the historical "!"-prefix marker
+func Default() Marker {
+	return Marker{Style: StylePrefix, Prefix: DefaultPrefix}
+}
+
+// Normalize fills in the defaults for the marker style
+func (m Marker) Normalize() Marker {
+	if m.Style == "" {
+		m.Style = StylePrefix
+	}
+	if m.Style == StylePrefix && m.Prefix == "" {
+		m.Prefix = DefaultPrefix
+	}
+	if m.Style == StyleSuffix && m.Suffix == "" {
+		m.Suffix = DefaultSuffix
+	}
+	return m
+}
+
+// Validate checks the marker style is known
+func (m Marker) Validate() error {
+	switch m.Style {
+	case StylePrefix, StyleSuffix, StyleSidecar, StyleNone:
+		return nil
+	}
+	return fmt.Errorf("unknown marker style %q (expected prefix, suffix, sidecar or none)", m.Style)
+}
+
+// Extract returns the keys of target flagged for retranslation, reading sidecars from
+// fsys. Suffixed keys are renamed back to their plain key so they are written without
+// the suffix.
+func (m Marker) Extract(fsys FS, target *parser.LocaleFileContent) (map[string]struct{}, error) {
+	marked := make(map[string]struct{})
+
+	switch m.Style {
+	case StylePrefix:
+		for k, v := range target.LocaleItemsMap {
+			if strings.HasPrefix(v, m.Prefix) {
+				marked[k] = struct{}{}
+			}
+		}
+	case StyleSuffix:
+		for k, v := range target.LocaleItemsMap {
+			if strings.HasSuffix(k, m.Suffix) {
+				plain := strings.TrimSuffix(k, m.Suffix)
+				delete(target.LocaleItemsMap, k)
+				target.LocaleItemsMap[plain] = v
+				marked[plain] = struct{}{}
+			}
+		}
+	case StyleSidecar:
+		keys, err := readSidecar(fsys, target.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			marked[k] = struct{}{}
+		}
+	}
+
+	return marked, nil
+}
+
+// Clear removes the flags of keys that were retranslated. Prefixed values and
+// suffixed keys are replaced by the translation already, only sidecars need updating,
+// in fsys.
+func (m Marker) Clear(fsys FS, target *parser.LocaleFileContent, keys []string) error {
+	if m.Style != StyleSidecar || len(keys) == 0 {
+		return nil
+	}
+
+	existing, err := readSidecar(fsys, target.Path)
+	if err != nil {
+		return err
+	}
+
+	cleared := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		cleared[k] = struct{}{}
+	}
+
+	remaining := []string{}
+	for _, k := range existing {
+		if _, ok := cleared[k]; !ok {
+			remaining = append(remaining, k)
+		}
+	}
+
+	return writeSidecar(fsys, target.Path, remaining)
+}
+
+// Mark flags keys of target for retranslation. It returns the keys that don't exist in target.
+func (m Marker) Mark(target *parser.LocaleFileContent, keys []string) ([]string, error) {
+	var unknown []string
+	var sidecar []string
+
+	for _, k := range keys {
+		v, ok := target.LocaleItemsMap[k]
+		if !ok {
+			unknown = append(unknown, k)
+			continue
+		}
+
+		switch m.Style {
+		case StylePrefix:
+			if !strings.HasPrefix(v, m.Prefix) {
+				target.LocaleItemsMap[k] = m.Prefix + v
+			}
+		case StyleSuffix:
+			delete(target.LocaleItemsMap, k)
+			target.LocaleItemsMap[k+m.Suffix] = v
+		case StyleSidecar:
+			sidecar = append(sidecar, k)
+		}
+	}
+
+	if len(sidecar) > 0 {
+		existing, err := readSidecar(OS{}, target.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeSidecar(OS{}, target.Path, append(existing, sidecar...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return unknown, nil
+}
+
+// Unmark removes the retranslation flag from keys of target. It returns the keys that weren't flagged.
+func (m Marker) Unmark(target *parser.LocaleFileContent, keys []string) ([]string, error) {
+	var unmarked []string
+
+	switch m.Style {
+	case StylePrefix:
+		for _, k := range keys {
+			v, ok := target.LocaleItemsMap[k]
+			if !ok || !strings.HasPrefix(v, m.Prefix) {
+				unmarked = append(unmarked, k)
+				continue
+			}
+			target.LocaleItemsMap[k] = strings.TrimPrefix(v, m.Prefix)
+		}
+	case StyleSuffix:
+		for _, k := range keys {
+			v, ok := target.LocaleItemsMap[k+m.Suffix]
+			if !ok {
+				unmarked = append(unmarked, k)
+				continue
+			}
+			delete(target.LocaleItemsMap, k+m.Suffix)
+			target.LocaleItemsMap[k] = v
+		}
+	case StyleSidecar:
+		existing, err := readSidecar(OS{}, target.Path)
+		if err != nil {
+			return nil, err
+		}
+		flagged := make(map[string]struct{}, len(existing))
+		for _, k := range existing {
+			flagged[k] = struct{}{}
+		}
+		for _, k := range keys {
+			if _, ok := flagged[k]; !ok {
+				unmarked = append(unmarked, k)
+			}
+		}
+		if err := m.Clear(OS{}, target, keys); err != nil {
+			return nil, err
+		}
+	default:
+		unmarked = keys
+	}
+
+	return unmarked, nil
+}
+
+func readSidecar(fsys FS, localePath string) ([]string, error) {
+	data, err := fsys.ReadFile(localePath + SidecarExt)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+func writeSidecar(fsys FS, localePath string, keys []string) error {
+	path := localePath + SidecarExt
+	if len(keys) == 0 {
+		if err := fsys.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	unique := make(map[string]struct{}, len(keys))
+	sorted := []string{}
+	for _, k := range keys {
+		if _, ok := unique[k]; !ok {
+			unique[k] = struct{}{}
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Strings(sorted)
+
+	return fsys.WriteFile(path, []byte(strings.Join(sorted, "\n")+"\n"))
+}
@@ -0,0 +1,64 @@
+package marker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func target(path string, items map[string]string) *parser.LocaleFileContent {
+	return &parser.LocaleFileContent{Path: path, Code: "fr", LocaleItemsMap: items}
+}
+
+// TestExtractPrefix tests that values starting with the prefix are flagged
+func TestExtractPrefix(t *testing.T) {
+	m := Default()
+	fr := target("fr.json", map[string]string{"title": "!Accueil", "menu": "Menu"})
+
+	marked, err := m.Extract(OS{}, fr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"title": {}}, marked)
+	assert.NoError(t, m.Clear(OS{}, fr, []string{"title"}))
+	assert.Equal(t, "!Accueil", fr.LocaleItemsMap["title"], "the retranslation replaces the value")
+}
+
+// TestExtractSuffix tests that suffixed keys are flagged under their plain name
+func TestExtractSuffix(t *testing.T) {
+	m := Marker{Style: StyleSuffix}.Normalize()
+	fr := target("fr.json", map[string]string{"title:retranslate": "Accueil", "menu": "Menu"})
+
+	marked, err := m.Extract(OS{}, fr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"title": {}}, marked)
+	assert.Equal(t, map[string]string{"title": "Accueil", "menu": "Menu"}, fr.LocaleItemsMap)
+	assert.NoError(t, m.Clear(OS{}, fr, []string{"title"}))
+}
+
+// TestExtractSidecar tests that the keys listed in the sidecar file are flagged, and
+// removed from it once retranslated
+func TestExtractSidecar(t *testing.T) {
+	m := Marker{Style: StyleSidecar}
+	path := filepath.Join(t.TempDir(), "fr.json")
+	fr := target(path, map[string]string{"title": "Accueil", "menu": "Menu", "footer": "Pied"})
+
+	marked, err := m.Extract(OS{}, fr)
+	assert.NoError(t, err)
+	assert.Empty(t, marked)
+
+	assert.NoError(t, os.WriteFile(path+SidecarExt, []byte("title\n\n menu \n"), 0644))
+	marked, err = m.Extract(OS{}, fr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"title": {}, "menu": {}}, marked)
+
+	assert.NoError(t, m.Clear(OS{}, fr, []string{"title"}))
+	data, err := os.ReadFile(path + SidecarExt)
+	assert.NoError(t, err)
+	assert.Equal(t, "menu\n", string(data))
+
+	assert.NoError(t, m.Clear(OS{}, fr, []string{"menu"}))
+	_, err = os.Stat(path + SidecarExt)
+	assert.True(t, os.IsNotExist(err), "an empty sidecar is removed")
+}
@@ -0,0 +1,166 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// tagPattern matches HTML opening, closing and self-closing tags. A "<" followed by
+	// a space or a digit ("a < b", "<3") is no tag.
+	tagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)((?:\s+[^<>]*?)?)\s*(/?)>`)
+	// urlAttrPattern matches the attributes of tags whose values are addresses, kept
+	// verbatim, unlike titles and alternative texts that are translated
+	urlAttrPattern = regexp.MustCompile(`\b(href|src)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+	// codePattern matches Markdown inline code, whose content is kept verbatim
+	codePattern = regexp.MustCompile("`[^`\n]+`")
+	// linkPattern matches the targets of Markdown links and images, [text](url)
+	linkPattern = regexp.MustCompile(`\]\(\s*([^()\s]+)(?:\s+"[^"]*")?\s*\)`)
+	// strongPattern matches Markdown bold markers
+	strongPattern = regexp.MustCompile(`\*\*|__`)
+)
+
+// voidElements are the HTML elements that have no closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// tag is an HTML tag of a text
+type tag struct {
+	name    string
+	closing bool
+	// Self-closing tags and void elements have no closing tag
+	void bool
+	// The tag as compared between texts
+	token string
+}
+
+// parseTags returns the HTML tags of text outside of inline code
+func parseTags(text string) []tag {
+	tags := []tag{}
+	for _, m := range tagPattern.FindAllStringSubmatch(codePattern.ReplaceAllString(text, ""), -1) {
+		t := tag{name: strings.ToLower(m[2]), closing: m[1] != ""}
+		t.void = m[4] != "" || voidElements[t.name]
+		t.token = "<" + m[1] + t.name
+		for _, attr := range urlAttrPattern.FindAllStringSubmatch(m[3], -1) {
+			t.token += " " + strings.ToLower(attr[1]) + "=" + strings.Trim(attr[2], `"'`)
+		}
+		if m[4] != "" {
+			t.token += "/"
+		}
+		t.token += ">"
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// Tags returns the HTML tags of text in order of appearance, written <name>, </name> or
+// <name/> followed by their href and src attributes, if any, as other attributes may be
+// translated
+func Tags(text string) []string {
+	tokens := []string{}
+	for _, t := range parseTags(text) {
+		tokens = append(tokens, t.token)
+	}
+	return tokens
+}
+
+// Markdown returns the Markdown constructs of text whose form must survive translation:
+// inline code spans, link and image targets written ](url), and bold markers
+func Markdown(text string) []string {
+	found := codePattern.FindAllString(text, -1)
+	prose := codePattern.ReplaceAllString(text, "")
+	for _, m := range linkPattern.FindAllStringSubmatch(prose, -1) {
+		found = append(found, "]("+m[1]+")")
+	}
+	return append(found, strongPattern.FindAllString(prose, -1)...)
+}
+
+// Check verifies the translation has the HTML tags and Markdown constructs of the
+// source, each as often and written the same way, and that its tags are nested like
+// those of the source. Tags may change places, as word order differs between
+// languages, as long as elements still open before they close.
+func Check(source, translation string) error {
+	missing, unexpected := diff(append(Tags(source), Markdown(source)...), append(Tags(translation), Markdown(translation)...))
+	if len(missing) > 0 || len(unexpected) > 0 {
+		return &Error{Missing: missing, Unexpected: unexpected}
+	}
+	if nested(parseTags(source)) && !nested(parseTags(translation)) {
+		return &Error{Misnested: true}
+	}
+	return nil
+}
+
+// diff returns the items of want missing from got and those of got not in want, once
+// per occurrence
+func diff(want, got []string) (missing, unexpected []string) {
+	wantCounts, gotCounts := counts(want), counts(got)
+	for item, n := range wantCounts {
+		for i := gotCounts[item]; i < n; i++ {
+			missing = append(missing, item)
+		}
+	}
+	for item, n := range gotCounts {
+		for i := wantCounts[item]; i < n; i++ {
+			unexpected = append(unexpected, item)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
+
+func counts(items []string) map[string]int {
+	n := make(map[string]int, len(items))
+	for _, item := range items {
+		n[item]++
+	}
+	return n
+}
+
+// nested reports whether each closing tag of tags closes the last element left open
+func nested(tags []tag) bool {
+	open := []string{}
+	for _, t := range tags {
+		switch {
+		case t.void:
+		case t.closing:
+			if len(open) == 0 || open[len(open)-1] != t.name {
+				return false
+			}
+			open = open[:len(open)-1]
+		default:
+			open = append(open, t.name)
+		}
+	}
+	return len(open) == 0
+}
+
+// Error is a translation whose markup differs from that of its source
+type Error struct {
+	// Tags and Markdown constructs of the source the translation lacks, once per missing
+	// occurrence
+	Missing []string
+	// Tags and Markdown constructs of the translation not in the source, or more often
+	// than in it
+	Unexpected []string
+	// The translation closes tags before those opened after them
+	Misnested bool
+}
+
+func (e *Error) Error() string {
+	if e.Misnested {
+		return "markup changed in translation: tags are no longer nested as in the source"
+	}
+	parts := []string{}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, " "))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Unexpected, " "))
+	}
+	return fmt.Sprintf("markup changed in translation: %s", strings.Join(parts, ", "))
+}
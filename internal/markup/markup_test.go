@@ -0,0 +1,44 @@
+package markup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTags tests that tags are compared by name and address attributes only
+func TestTags(t *testing.T) {
+	assert.Equal(t, []string{"<b>", "</b>", "<br/>", "<a href=/terms>", "</a>"}, Tags(`<B>Save</b><br /><a class="link" title="Terms" href="/terms">terms</a>`))
+	assert.Equal(t, []string{"<img src=logo.png/>"}, Tags(`<img alt="Logo" src='logo.png'/>`))
+
+	// Comparisons and code are no tags
+	assert.Empty(t, Tags("a < b and c > d, <3"))
+	assert.Empty(t, Tags("Use `<b>` for bold"))
+}
+
+// TestMarkdown tests the Markdown constructs kept verbatim
+func TestMarkdown(t *testing.T) {
+	assert.Equal(t, []string{"`npm install`", "](https://example.com)", "](logo.png)", "**", "**"}, Markdown("Run `npm install`, see [the docs](https://example.com \"Docs\") ![logo](logo.png) **now**"))
+	assert.Empty(t, Markdown("Plain text (with parentheses) and [brackets]"))
+}
+
+// TestCheck tests that translations must keep the markup of their source
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("Click <b>Save</b> to <i>continue</i>", "Klicken Sie auf <b>Speichern</b>, um <i>fortzufahren</i>"))
+	// Elements may trade places
+	assert.NoError(t, Check("<b>Save</b> or <i>cancel</i>", "<i>キャンセル</i>または<b>保存</b>"))
+	assert.NoError(t, Check(`<a title="Terms" href="/terms">Terms</a>`, `<a title="Conditions" href="/terms">Conditions</a>`))
+	assert.NoError(t, Check("Read [the guide](/guide) **first**", "Lisez d'abord [le guide](/guide) **en premier**"))
+
+	assert.EqualError(t, Check("Click <b>Save</b>", "Cliquez sur Enregistrer"), "markup changed in translation: missing </b> <b>")
+	assert.Equal(t, &Error{Missing: []string{"</b>", "<b>"}, Unexpected: []string{"</gras>", "<gras>"}}, Check("<b>Save</b>", "<gras>Enregistrer</gras>"))
+	assert.Equal(t, &Error{Missing: []string{"](/guide)"}, Unexpected: []string{"](/guide-fr)"}}, Check("See [the guide](/guide)", "Voir [le guide](/guide-fr)"))
+	assert.Equal(t, &Error{Missing: []string{"`--force`"}, Unexpected: []string{"`--forcer`"}}, Check("Pass `--force`", "Passez `--forcer`"))
+	assert.Equal(t, &Error{Missing: []string{"**", "**"}}, Check("**Warning**: unsaved", "Attention : non enregistré"))
+
+	err := Check("<b><i>New</i></b>", "<b><i>Nouveau</b></i>")
+	assert.Equal(t, &Error{Misnested: true}, err)
+	assert.EqualError(t, err, "markup changed in translation: tags are no longer nested as in the source")
+	// Fragments whose tags aren't nested to begin with are only compared by their tags
+	assert.NoError(t, Check("</b> then <b>", "<b> puis </b>"))
+}
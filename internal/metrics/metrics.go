@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kinds of metrics
+const (
+	// Counter is a value that only goes up, such as a number of requests
+	Counter = "counter"
+	// Gauge is a value that goes up and down, such as a completion ratio
+	Gauge = "gauge"
+)
+
+// Registry holds metrics and renders them in the Prometheus text exposition format
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family is a metric and its values by label set
+type family struct {
+	kind   string
+	help   string
+	values map[string]float64
+}
+
+// New returns an empty registry
+func New() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// Describe declares a metric of the given kind. Values of undeclared metrics are
+// ignored, so that a typo doesn't silently create a new series.
+func (r *Registry) Describe(name, kind, help string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; !ok {
+		r.families[name] = &family{kind: kind, help: help, values: map[string]float64{}}
+	}
+}
+
+// Set sets the value of a metric for labels, given as name/value pairs
+func (r *Registry) Set(name string, v float64, labels ...string) {
+	r.update(name, labels, func(float64) float64 { return v })
+}
+
+// Add adds v to the value of a metric for labels, given as name/value pairs
+func (r *Registry) Add(name string, v float64, labels ...string) {
+	r.update(name, labels, func(old float64) float64 { return old + v })
+}
+
+// Reset removes every value of a metric, e.g. before setting the gauges of the
+// languages that still exist
+func (r *Registry) Reset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		f.values = map[string]float64{}
+	}
+}
+
+// Value returns the value of a metric for labels
+func (r *Registry) Value(name string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		return f.values[labelSet(labels)]
+	}
+	return 0
+}
+
+func (r *Registry) update(name string, labels []string, fn func(float64) float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		return
+	}
+	key := labelSet(labels)
+	f.values[key] = fn(f.values[key])
+}
+
+// labelSet renders label name/value pairs as {name="value",...}, sorted by name
+func labelSet(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escape(labels[i+1])+`"`)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes a label value or help text for the exposition format
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatValue renders a sample value, with the special values Prometheus expects
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// WriteTo writes every metric with values, sorted by name then labels
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name, f := range r.families {
+		if len(f.values) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, escape(f.help), name, f.kind)
+		keys := make([]string, 0, len(f.values))
+		for key := range f.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s%s %s\n", name, key, formatValue(f.values[key]))
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics to Prometheus scrapers
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
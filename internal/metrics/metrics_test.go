@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistry tests counters, gauges and the exposition format
+func TestRegistry(t *testing.T) {
+	r := New()
+	r.Describe("i18n_keys_translated_total", Counter, "Keys translated")
+	r.Describe("i18n_catalog_completion_ratio", Gauge, "Translated share of source keys")
+	r.Describe("i18n_unused", Gauge, "Never set")
+
+	r.Add("i18n_keys_translated_total", 3, "lang", "fr")
+	r.Add("i18n_keys_translated_total", 2, "lang", "fr")
+	r.Add("i18n_keys_translated_total", 1, "lang", `d"e`)
+	r.Set("i18n_catalog_completion_ratio", 0.5, "lang", "fr")
+	r.Set("i18n_catalog_completion_ratio", 0.75, "lang", "fr")
+	r.Add("i18n_undeclared", 1)
+	assert.Equal(t, 5.0, r.Value("i18n_keys_translated_total", "lang", "fr"))
+
+	var b strings.Builder
+	r.WriteTo(&b)
+	assert.Equal(t, `# HELP i18n_catalog_completion_ratio Translated share of source keys
+# TYPE i18n_catalog_completion_ratio gauge
+i18n_catalog_completion_ratio{lang="fr"} 0.75
+# HELP i18n_keys_translated_total Keys translated
+# TYPE i18n_keys_translated_total counter
+i18n_keys_translated_total{lang="d\"e"} 1
+i18n_keys_translated_total{lang="fr"} 5
+`, b.String())
+
+	r.Reset("i18n_catalog_completion_ratio")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, rec.Body.String(), "completion_ratio")
+	assert.Contains(t, rec.Header().Get("Content-Type"), "version=0.0.4")
+}
+
+// TestLabelSet tests that labels are rendered sorted by name
+func TestLabelSet(t *testing.T) {
+	assert.Equal(t, "", labelSet(nil))
+	assert.Equal(t, `{kind="prompt",model="gpt-4o"}`, labelSet([]string{"model", "gpt-4o", "kind", "prompt"}))
+}
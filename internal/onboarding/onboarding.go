@@ -0,0 +1,89 @@
+package onboarding
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const stateFile = "onboarding.json"
+
+// DefaultSampleSize is the number of keys translated for sign-off when none is configured
+const DefaultSampleSize = 100
+
+// Statuses of languages being onboarded
+const (
+	// The sample is translated and waits for sign-off
+	StatusPending = "pending"
+	// The sample was signed off, the full backfill may run
+	StatusApproved = "approved"
+)
+
+// Record is the onboarding of a new language
+type Record struct {
+	Status string `json:"status"`
+	// Number of keys of the sample
+	Keys int `json:"keys"`
+	// File the sample was exported to for review
+	Export     string     `json:"export"`
+	SampledAt  time.Time  `json:"sampledAt"`
+	ApprovedAt *time.Time `json:"approvedAt,omitempty"`
+}
+
+// Load returns the onboarding records by language
+func Load() (map[string]Record, error) {
+	records := map[string]Record{}
+	if err := state.Load(stateFile, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Save writes the onboarding records by language
+func Save(records map[string]Record) error {
+	return state.Save(stateFile, records)
+}
+
+// Key is a key of a catalog a sample can be drawn from
+type Key struct {
+	File string `json:"file"`
+	Key  string `json:"key"`
+}
+
+// Sample returns up to n of keys that represent the catalogs: the most used ones when
+// usage hit counts are known, otherwise keys spread evenly across the files. The
+// result is sorted by file, then key, and is the same for the same input.
+func Sample(keys []Key, n int, priority map[string]int) []Key {
+	sorted := append([]Key(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	if n <= 0 || len(sorted) <= n {
+		return sorted
+	}
+
+	sample := make([]Key, 0, n)
+	if len(priority) > 0 {
+		byUse := append([]Key(nil), sorted...)
+		sort.SliceStable(byUse, func(i, j int) bool {
+			return priority[byUse[i].Key] > priority[byUse[j].Key]
+		})
+		sample = append(sample, byUse[:n]...)
+	} else {
+		for i := 0; i < n; i++ {
+			sample = append(sample, sorted[i*len(sorted)/n])
+		}
+	}
+
+	sort.Slice(sample, func(i, j int) bool {
+		if sample[i].File != sample[j].File {
+			return sample[i].File < sample[j].File
+		}
+		return sample[i].Key < sample[j].Key
+	})
+	return sample
+}
@@ -0,0 +1,22 @@
+package onboarding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSample tests that samples spread across files, or favour the most used keys
+// when usage is known
+func TestSample(t *testing.T) {
+	keys := []Key{
+		{File: "fr/b.json", Key: "two"},
+		{File: "fr/a.json", Key: "one"},
+		{File: "fr/a.json", Key: "three"},
+		{File: "fr/b.json", Key: "four"},
+	}
+
+	assert.Equal(t, []Key{{File: "fr/a.json", Key: "one"}, {File: "fr/b.json", Key: "four"}}, Sample(keys, 2, nil))
+	assert.Equal(t, []Key{{File: "fr/a.json", Key: "three"}, {File: "fr/b.json", Key: "two"}}, Sample(keys, 2, map[string]int{"two": 50, "three": 10, "one": 1}))
+	assert.Len(t, Sample(keys, 10, nil), 4)
+}
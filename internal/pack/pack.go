@@ -0,0 +1,221 @@
+package pack
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+)
+
+// Artifact formats
+const (
+	// FormatJSON writes one merged <lang>.json per language
+	FormatJSON = "json"
+	// FormatGzip writes one gzip compressed <lang>.json.gz per language
+	FormatGzip = "gzip"
+	// FormatGo writes the <lang>.json files and a Go file embedding them
+	FormatGo = "go"
+)
+
+// Formats lists every artifact format
+var Formats = []string{FormatJSON, FormatGzip, FormatGo}
+
+// ManifestName is the file name of the manifest written next to the artifacts
+const ManifestName = "manifest.json"
+
+// Catalog is the merged catalog of a language
+type Catalog struct {
+	Lang  string
+	Items map[string]string
+}
+
+// Options describes the artifacts to write
+type Options struct {
+	Format string
+	// Version recorded in the manifest, e.g. the release tag
+	Version string
+	// Source language, recorded in the manifest
+	Source string
+	// Minify writes the JSON without indentation
+	Minify bool
+	// Package is the name of the generated Go package
+	Package string
+}
+
+// Entry describes the artifact of a language in the manifest
+type Entry struct {
+	File   string `json:"file"`
+	Keys   int    `json:"keys"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the artifacts of a pack with their hashes
+type Manifest struct {
+	Version   string           `json:"version,omitempty"`
+	Format    string           `json:"format"`
+	Source    string           `json:"source,omitempty"`
+	Languages map[string]Entry `json:"languages"`
+}
+
+// Write writes the artifacts of catalogs and their manifest to dir. The output only
+// depends on its inputs, so packing the same catalogs twice gives identical files.
+func Write(dir string, opts Options, catalogs []Catalog) (*Manifest, error) {
+	switch opts.Format {
+	case FormatJSON, FormatGzip:
+	case FormatGo:
+		if !token.IsIdentifier(opts.Package) {
+			return nil, fmt.Errorf("%q is not a valid Go package name", opts.Package)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %s (expected json, gzip or go)", opts.Format)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:   opts.Version,
+		Format:    opts.Format,
+		Source:    opts.Source,
+		Languages: make(map[string]Entry, len(catalogs)),
+	}
+	for _, catalog := range catalogs {
+		data, err := catalogJSON(catalog.Items, opts.Minify)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", catalog.Lang, err)
+		}
+
+		name := catalog.Lang + ".json"
+		if opts.Format == FormatGzip {
+			name += ".gz"
+			if data, err = compress(name, data); err != nil {
+				return nil, fmt.Errorf("%s: %w", catalog.Lang, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest.Languages[catalog.Lang] = Entry{
+			File:   name,
+			Keys:   len(catalog.Items),
+			Bytes:  len(data),
+			SHA256: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+
+	if opts.Format == FormatGo {
+		if err := os.WriteFile(filepath.Join(dir, opts.Package+".go"), goSource(opts.Package, manifest), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// catalogJSON renders flattened items as nested JSON with sorted keys
+func catalogJSON(items map[string]string, minify bool) ([]byte, error) {
+	data, err := (&parser.LocaleFileContent{LocaleItemsMap: items}).JSON()
+	if err != nil {
+		return nil, err
+	}
+	if minify {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return append(data, '\n'), nil
+}
+
+// compress gzips data without a modification time, keeping the output reproducible
+func compress(name string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	w.Name = name[:len(name)-len(".gz")]
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// goSource returns a Go file embedding the catalogs and the manifest of a pack
+func goSource(pkg string, manifest *Manifest) []byte {
+	langs := make([]string, 0, len(manifest.Languages))
+	for lang := range manifest.Languages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by i18n-cli pack. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "// Package %s embeds the translated catalogs of every language.\n", pkg)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"embed\"\n\n")
+	fmt.Fprintf(&buf, "// FS holds <lang>.json for every language and %s\n", ManifestName)
+	fmt.Fprintf(&buf, "//\n//go:embed *.json\nvar FS embed.FS\n\n")
+	fmt.Fprintf(&buf, "// Version is the version the catalogs were packed for\n")
+	fmt.Fprintf(&buf, "const Version = %q\n\n", manifest.Version)
+	fmt.Fprintf(&buf, "// Languages lists the packed languages\n")
+	fmt.Fprintf(&buf, "var Languages = []string{")
+	for i, lang := range langs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%q", lang)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// Merge merges the catalogs of a language, by namespace, into one catalog. With more
+// than one namespace, keys are nested under the namespace, e.g. common/title.
+func Merge(namespaces map[string]map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for namespace, items := range namespaces {
+		for key, value := range items {
+			if len(namespaces) > 1 {
+				key = namespace + "/" + key
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// Fill adds the values of fallback missing or empty in items, returning how many were added
+func Fill(items, fallback map[string]string) int {
+	added := 0
+	for key, value := range fallback {
+		if items[key] == "" && value != "" {
+			items[key] = value
+			added++
+		}
+	}
+	return added
+}
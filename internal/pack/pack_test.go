@@ -0,0 +1,100 @@
+package pack
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var catalogs = []Catalog{
+	{Lang: "en", Items: map[string]string{"common/title": "Hello", "auth/login": "Log in"}},
+	{Lang: "fr", Items: map[string]string{"common/title": "Bonjour"}},
+}
+
+// TestWriteJSON tests merged JSON artifacts and their manifest
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := Write(dir, Options{Format: FormatJSON, Version: "v1.2.0", Source: "en", Minify: true}, catalogs)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "fr.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"common":{"title":"Bonjour"}}`, string(data))
+
+	sum := sha256.Sum256(data)
+	assert.Equal(t, Entry{File: "fr.json", Keys: 1, Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}, manifest.Languages["fr"])
+
+	var read Manifest
+	data, err = os.ReadFile(filepath.Join(dir, ManifestName))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &read))
+	assert.Equal(t, *manifest, read)
+	assert.Equal(t, "v1.2.0", read.Version)
+}
+
+// TestWriteGzip tests that gzip bundles decompress to the catalog and are reproducible
+func TestWriteGzip(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Write(dir, Options{Format: FormatGzip}, catalogs)
+	assert.NoError(t, err)
+	second, err := Write(t.TempDir(), Options{Format: FormatGzip}, catalogs)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	data, err := os.ReadFile(filepath.Join(dir, "en.json.gz"))
+	assert.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "en.json", r.Name)
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"login": "Log in"`)
+}
+
+// TestWriteGo tests the generated Go package
+func TestWriteGo(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Write(dir, Options{Format: FormatGo, Package: "locales", Version: "v2"}, catalogs)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "locales.go"))
+	assert.NoError(t, err)
+	source := string(data)
+	assert.True(t, strings.HasPrefix(source, "// Code generated by i18n-cli pack. DO NOT EDIT."))
+	assert.Contains(t, source, "package locales\n")
+	assert.Contains(t, source, "//go:embed *.json\nvar FS embed.FS")
+	assert.Contains(t, source, `const Version = "v2"`)
+	assert.Contains(t, source, `var Languages = []string{"en", "fr"}`)
+	assert.FileExists(t, filepath.Join(dir, "fr.json"))
+
+	_, err = Write(t.TempDir(), Options{Format: FormatGo, Package: "my-locales"}, catalogs)
+	assert.Error(t, err)
+	_, err = Write(t.TempDir(), Options{Format: "zip"}, catalogs)
+	assert.Error(t, err)
+}
+
+// TestMergeAndFill tests merging namespaces and filling untranslated keys
+func TestMergeAndFill(t *testing.T) {
+	assert.Equal(t, map[string]string{"title": "Hello"}, Merge(map[string]map[string]string{"common": {"title": "Hello"}}))
+
+	merged := Merge(map[string]map[string]string{
+		"common": {"title": "Bonjour", "empty": ""},
+		"auth":   {},
+	})
+	assert.Equal(t, map[string]string{"common/title": "Bonjour", "common/empty": ""}, merged)
+
+	added := Fill(merged, map[string]string{"common/title": "Hello", "common/empty": "Empty", "auth/login": "Log in"})
+	assert.Equal(t, 2, added)
+	assert.Equal(t, "Bonjour", merged["common/title"])
+	assert.Equal(t, "Empty", merged["common/empty"])
+	assert.Equal(t, "Log in", merged["auth/login"])
+}
@@ -0,0 +1,120 @@
+package placeholder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pandodao/i18n-cli/internal/icu"
+)
+
+// pattern matches the placeholders of the common i18n formats, longest forms first:
+// {{name}} (Mustache, i18next, Angular), printf verbs (%s, %d, %1$s, %.2f, %@), Ruby
+// %{name} and Python %(name)s, ${name}, and {name} or {0} (ICU, .NET, Python format).
+// A space is no printf flag here, so that "50% off" has none.
+var pattern = regexp.MustCompile(`\{\{[^{}]*\}\}|%\{\w+\}|%\(\w+\)[-+#0]*\d*(?:\.\d+)?[a-zA-Z]|%(?:\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|h|ll|l|L|q|j|z|t)?[sdifFuxXoeEgGcpqvtTb@]|\$\{\w+(?:\.\w+)*\}|\{\w+(?:\.\w+)*\}`)
+
+// Find returns the placeholders of text in order of appearance. The braces of ICU
+// messages with plural or select arguments hold sub-messages rather than placeholders,
+// so only their other placeholders are returned; icu.Check compares their arguments.
+func Find(text string) []string {
+	var found []string
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if !inWord(text, loc[0], loc[1]) {
+			found = append(found, text[loc[0]:loc[1]])
+		}
+	}
+	if !hasSubMessages(text) {
+		return found
+	}
+	kept := found[:0]
+	for _, p := range found {
+		if strings.HasPrefix(p, "{") && !strings.HasPrefix(p, "{{") {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// inWord reports whether the printf verb text[start:end] is a percent sign of prose
+// within a word, right after a number and followed by a letter, as in the German
+// "20%iger Rabatt" or "100%ige"
+func inWord(text string, start, end int) bool {
+	if text[start] != '%' || end-start < 2 || text[start+1] == '{' || text[start+1] == '(' {
+		return false
+	}
+	if start == 0 || text[start-1] < '0' || text[start-1] > '9' {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[end:])
+	return unicode.IsLetter(next)
+}
+
+// hasSubMessages reports whether text is an ICU message with plural or select arguments
+func hasSubMessages(text string) bool {
+	args, err := icu.Arguments(text)
+	if err != nil {
+		return false
+	}
+	for _, a := range args {
+		if a.Type == "plural" || a.Type == "select" || a.Type == "selectordinal" {
+			return true
+		}
+	}
+	return false
+}
+
+// Check verifies the translation has the placeholders of the source, each as often and
+// written exactly the same way, and no other
+func Check(source, translation string) error {
+	want := counts(Find(source))
+	got := counts(Find(translation))
+	var missing, unexpected []string
+	for p, n := range want {
+		for i := got[p]; i < n; i++ {
+			missing = append(missing, p)
+		}
+	}
+	for p, n := range got {
+		for i := want[p]; i < n; i++ {
+			unexpected = append(unexpected, p)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return &Error{Missing: missing, Unexpected: unexpected}
+}
+
+func counts(placeholders []string) map[string]int {
+	n := make(map[string]int, len(placeholders))
+	for _, p := range placeholders {
+		n[p]++
+	}
+	return n
+}
+
+// Error is a translation whose placeholders differ from those of its source
+type Error struct {
+	// Placeholders of the source the translation lacks, once per missing occurrence
+	Missing []string
+	// Placeholders of the translation not in the source, or more often than in it
+	Unexpected []string
+}
+
+func (e *Error) Error() string {
+	parts := []string{}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, " "))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Unexpected, " "))
+	}
+	return fmt.Sprintf("placeholders changed in translation: %s", strings.Join(parts, ", "))
+}
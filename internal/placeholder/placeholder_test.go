@@ -0,0 +1,45 @@
+package placeholder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFind tests the placeholders found in the common i18n formats
+func TestFind(t *testing.T) {
+	assert.Equal(t, []string{"{{name}}", "{{ count }}"}, Find("Hi {{name}}, you have {{ count }} messages"))
+	assert.Equal(t, []string{"%s", "%d", "%1$s", "%.2f", "%@", "%-5d", "%lld"}, Find("%s has %d items, %1$s paid %.2f %@ %-5d %lld"))
+	assert.Equal(t, []string{"%{name}", "%(count)d", "${user.name}"}, Find("%{name} has %(count)d, ${user.name}"))
+	assert.Equal(t, []string{"{0}", "{name}"}, Find("{0} invited {name}"))
+
+	// Percent signs of prose are no placeholders
+	assert.Empty(t, Find("50% off, 100 % sure, 20%%"))
+	assert.Empty(t, Find("{ not a placeholder }"))
+	assert.Empty(t, Find("20%iger Rabatt auf 100%ige Baumwolle, 5%ouvert"))
+	assert.Equal(t, []string{"%d", "%s"}, Find("%dx zoom, 10%s"))
+
+	// Sub-messages of plural and select arguments are left to the ICU check
+	assert.Equal(t, []string{"%s"}, Find("{count, plural, one {file} other {files}} in %s"))
+	assert.Equal(t, []string{"{count}"}, Find("{count} files"))
+}
+
+// TestCheck tests that translations must keep every placeholder of their source
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("Hello %s, you have %d messages", "%s, vous avez %d messages"))
+	assert.NoError(t, Check("%1$s sent %2$s", "%2$s envoyé par %1$s"))
+	assert.NoError(t, Check("Hi {{name}}", "Salut {{name}}"))
+	assert.NoError(t, Check("{count, plural, one {# file} other {# files}}", "{count, plural, one {# fichier} other {# fichiers}}"))
+	assert.NoError(t, Check("20% discount on 100% cotton", "20%iger Rabatt auf 100%ige Baumwolle"))
+
+	err := Check("Hello %s, you have %d messages", "Bonjour, vous avez %s messages")
+	assert.EqualError(t, err, "placeholders changed in translation: missing %d")
+
+	err = Check("Hi {{name}}", "Salut {{ nom }}")
+	assert.Equal(t, &Error{Missing: []string{"{{name}}"}, Unexpected: []string{"{{ nom }}"}}, err)
+	assert.EqualError(t, err, "placeholders changed in translation: missing {{name}}, unexpected {{ nom }}")
+
+	// Each occurrence counts
+	assert.Equal(t, &Error{Missing: []string{"{0}"}}, Check("{0} and {0}", "{0} et lui"))
+	assert.Equal(t, &Error{Unexpected: []string{"%s"}}, Check("Save", "Enregistrer %s"))
+}
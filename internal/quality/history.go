@@ -0,0 +1,61 @@
+package quality
+
+import (
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const historyFile = "quality_history.json"
+
+// Record is the result of one freshness audit of a language
+type Record struct {
+	Time time.Time `json:"time"`
+	Lang string    `json:"lang"`
+	// Number of sampled keys that were graded
+	Samples int `json:"samples"`
+	// Number of sampled keys whose fresh translation differs from the stored one
+	Changed int `json:"changed"`
+	// Average model grade of the stored translations, from 1 to 5
+	Score float64 `json:"score"`
+}
+
+// Drift returns the share of sampled keys whose fresh translation differs
+func (r Record) Drift() float64 {
+	if r.Samples == 0 {
+		return 0
+	}
+	return float64(r.Changed) / float64(r.Samples)
+}
+
+// LoadHistory returns all recorded audits, oldest first
+func LoadHistory() ([]Record, error) {
+	var records []Record
+	if err := state.Load(historyFile, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// AppendHistory adds audit records to the history
+func AppendHistory(records ...Record) error {
+	history, err := LoadHistory()
+	if err != nil {
+		return err
+	}
+	return state.Save(historyFile, append(history, records...))
+}
+
+// Trend returns the last n records of a language, oldest first
+func Trend(history []Record, lang string, n int) []Record {
+	trend := []Record{}
+	for _, r := range history {
+		if r.Lang == lang {
+			trend = append(trend, r)
+		}
+	}
+	if len(trend) > n {
+		trend = trend[len(trend)-n:]
+	}
+	return trend
+}
@@ -0,0 +1,44 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistory tests that audits are appended to the history of the project, oldest
+// first, and that the trend of a language keeps its last records
+func TestHistory(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	state.SetProject(t.TempDir())
+	defer state.SetProject("")
+
+	history, err := LoadHistory()
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 4; day++ {
+		at := start.AddDate(0, 0, day)
+		require.NoError(t, AppendHistory(
+			Record{Time: at, Lang: "fr", Samples: 10, Changed: day, Score: 4},
+			Record{Time: at, Lang: "de", Samples: 20, Changed: 5, Score: 3.5},
+		))
+	}
+	history, err = LoadHistory()
+	require.NoError(t, err)
+	assert.Len(t, history, 8)
+	assert.True(t, history[0].Time.Equal(start))
+
+	trend := Trend(history, "fr", 2)
+	require.Len(t, trend, 2)
+	assert.Equal(t, 2, trend[0].Changed)
+	assert.Equal(t, 3, trend[1].Changed)
+	assert.Equal(t, 0.3, trend[1].Drift())
+	assert.Len(t, Trend(history, "de", 5), 4)
+	assert.Empty(t, Trend(history, "ja", 5))
+	assert.Equal(t, 0.0, Record{}.Drift())
+}
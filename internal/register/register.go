@@ -0,0 +1,197 @@
+package register
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Registers translations may be held to
+const (
+	// Informal addresses the user familiarly: du, tu, plain Japanese forms, 해요체
+	Informal = "informal"
+	// Formal addresses the user politely: Sie, vous, usted, です/ます, 합쇼체
+	Formal = "formal"
+	// Honorific adds respectful keigo to the formal register, for Japanese and Korean
+	Honorific = "honorific"
+)
+
+// synonyms are the other names of the registers, as formality settings and DeepL's
+// formality parameter call them
+var synonyms = map[string]string{
+	"casual":      Informal,
+	"less":        Informal,
+	"prefer_less": Informal,
+	"polite":      Formal,
+	"more":        Formal,
+	"prefer_more": Formal,
+	"keigo":       Honorific,
+}
+
+// Normalize returns the register a level names, itself when it isn't a synonym:
+// polite, more and prefer_more are formal, casual, less and prefer_less informal, and
+// keigo is honorific
+func Normalize(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if r, ok := synonyms[level]; ok {
+		return r
+	}
+	return level
+}
+
+// Formality returns the DeepL formality parameter of a register, empty when none is
+// set. The prefer_ values fall back to the default for languages without formality.
+func Formality(level string) string {
+	switch Normalize(level) {
+	case Informal:
+		return "prefer_less"
+	case Formal, Honorific:
+		return "prefer_more"
+	}
+	return ""
+}
+
+// Settings maps language codes to the register of their translations, e.g.
+// {"ja": "honorific", "de": "formal"}
+type Settings map[string]string
+
+// rule is how a register is asked for and checked in a language
+type rule struct {
+	instruction string
+	// Matches words or endings that belong to another register
+	offending *regexp.Regexp
+	// Checks the offending matches, all of them when nil
+	accept func(text string, match []int) bool
+}
+
+// word matches one of words as a whole word, regardless of case
+func word(words ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[^\pL])(` + strings.Join(words, "|") + `)(?:$|[^\pL])`)
+}
+
+// ending matches sentences ending with one of endings
+func ending(endings ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(` + strings.Join(endings, "|") + `)[\s。．.！!？?」』)）]*(?:$|\n)`)
+}
+
+// midSentence accepts matches that don't start a sentence, where German "Sie" and
+// "Ihr" may mean "they" and "their"
+func midSentence(text string, match []int) bool {
+	before := strings.TrimRight(text[:match[2]], " \t\"'„“«")
+	last, _ := utf8.DecodeLastRuneInString(before)
+	return before != "" && !strings.ContainsRune(".!?:\n", last)
+}
+
+// rules are the per-language registers, by base language
+var rules = map[string]map[string]rule{
+	"ja": {
+		Informal:  {instruction: "Use the plain form (だ/である調) consistently, never です/ます.", offending: ending("です", "ます", "でした", "ました", "ません", "ましょう", "ください")},
+		Formal:    {instruction: "Use polite teineigo (です/ます調) consistently, never the plain form.", offending: ending("だ", "である", "だよ", "だね", "じゃない")},
+		Honorific: {instruction: "Use respectful keigo consistently: sonkeigo for the user's actions, kenjōgo for ours, with です/ます endings and never the plain form.", offending: ending("だ", "である", "だよ", "だね", "じゃない")},
+	},
+	"ko": {
+		Informal:  {instruction: "Use the polite informal style (해요체, ending in -요) consistently, never 합쇼체.", offending: ending("니다", "니까")},
+		Formal:    {instruction: "Use the formal polite style (합쇼체, ending in -습니다/-ㅂ니다) consistently, never 해요체 or 반말.", offending: ending("요")},
+		Honorific: {instruction: "Use the formal polite style (합쇼체) with honorific verb forms (-시-) for the user consistently, never 해요체 or 반말.", offending: ending("요")},
+	},
+	"de": {
+		Informal: {instruction: "Address the user informally with du (dich, dir, dein), lowercase, never Sie.", offending: regexp.MustCompile(`(?:^|[^\pL])(Sie|Ihnen|Ihr|Ihre|Ihren|Ihrem|Ihrer|Ihres)(?:$|[^\pL])`), accept: midSentence},
+		Formal:   {instruction: "Address the user formally with Sie, capitalizing Sie, Ihnen and Ihr, never du.", offending: word("du", "dich", "dir", "dein", "deine", "deinen", "deinem", "deiner", "deines")},
+	},
+	"fr": {
+		Informal: {instruction: "Address the user informally with tu (toi, ton, ta, tes), never vous.", offending: word("vous", "votre", "vos")},
+		Formal:   {instruction: "Address the user formally with vous (votre, vos), never tu.", offending: word("tu", "toi", "ton", "ta", "tes", "te")},
+	},
+	"es": {
+		Informal: {instruction: "Address the user informally with tú, never usted.", offending: word("usted", "ustedes")},
+		Formal:   {instruction: "Address the user formally with usted, never tú.", offending: word("tú", "tu", "tus", "ti", "te", "contigo")},
+	},
+}
+
+// Validate checks that level is a register that can be asked for in lang
+func Validate(lang, level string) error {
+	switch level = Normalize(level); level {
+	case Informal, Formal:
+		return nil
+	case Honorific:
+		if b := base(lang); b == "ja" || b == "ko" {
+			return nil
+		}
+		return fmt.Errorf("the %s register is only available for Japanese and Korean, not %s", Honorific, lang)
+	}
+	return fmt.Errorf("unknown register %q for %s, use %s, %s or %s", level, lang, Informal, Formal, Honorific)
+}
+
+// Merge returns the settings of s overridden by those of other, for the same language
+// codes regardless of case
+func (s Settings) Merge(other Settings) Settings {
+	if len(other) == 0 {
+		return s
+	}
+	merged := Settings{}
+	for code, level := range s {
+		merged[code] = level
+	}
+	for code, level := range other {
+		for existing := range merged {
+			if strings.EqualFold(existing, code) {
+				delete(merged, existing)
+			}
+		}
+		merged[code] = level
+	}
+	return merged
+}
+
+// Level returns the register of lang, matching languages regardless of case and
+// falling back to the setting of the base language ("de" for "de-AT")
+func (s Settings) Level(lang string) string {
+	for code, level := range s {
+		if strings.EqualFold(code, lang) {
+			return Normalize(level)
+		}
+	}
+	for code, level := range s {
+		if !strings.ContainsAny(code, "-_") && base(code) == base(lang) {
+			return Normalize(level)
+		}
+	}
+	return ""
+}
+
+// Instruction returns the prompt instruction of the register of lang, empty when none is set
+func (s Settings) Instruction(lang string) string {
+	level := s.Level(lang)
+	if level == "" {
+		return ""
+	}
+	if r, ok := rules[base(lang)][level]; ok {
+		return r.instruction
+	}
+	return fmt.Sprintf("Use the %s register consistently.", level)
+}
+
+// Check spot-checks that a translation into lang keeps to the register of lang. Only
+// the forms of address and sentence endings of Japanese, Korean, German, French and
+// Spanish are checked.
+func (s Settings) Check(result, lang string) error {
+	level := s.Level(lang)
+	r, ok := rules[base(lang)][level]
+	if !ok {
+		return nil
+	}
+	for _, m := range r.offending.FindAllStringSubmatchIndex(result, -1) {
+		if r.accept == nil || r.accept(result, m) {
+			return fmt.Errorf("%q doesn't belong to the %s register", result[m[2]:m[3]], level)
+		}
+	}
+	return nil
+}
+
+func base(lang string) string {
+	b, _ := language.Make(lang).Base()
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package register
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheck tests that forms of address and sentence endings of another register
+// are caught
+func TestCheck(t *testing.T) {
+	s := Settings{"ja": Honorific, "ko": Formal, "de": Formal, "fr-CA": Informal, "fr": Formal, "es": Informal}
+
+	assert.NoError(t, s.Check("ファイルを保存しました。", "ja-JP"))
+	assert.EqualError(t, s.Check("ファイルを保存したよ。完了だ。", "ja-JP"), `"だ" doesn't belong to the honorific register`)
+	assert.NoError(t, s.Check("저장되었습니다.", "ko"))
+	assert.Error(t, s.Check("저장했어요.", "ko"))
+	assert.NoError(t, s.Check("Speichern Sie Ihre Änderungen.", "de"))
+	assert.EqualError(t, s.Check("Speichere deine Änderungen, bevor du gehst.", "de-AT"), `"deine" doesn't belong to the formal register`)
+	assert.NoError(t, s.Check("Enregistrez vos modifications.", "fr"))
+	assert.Error(t, s.Check("Enregistre tes modifications.", "fr"))
+	assert.NoError(t, s.Check("Enregistre tes modifications.", "fr-CA"))
+	assert.Error(t, s.Check("¿Puede usted confirmar?", "es"))
+	assert.NoError(t, s.Check("Whatever you like", "en"))
+
+	// Sie starting a sentence may mean "they"
+	informal := Settings{"de": Informal}
+	assert.NoError(t, informal.Check("Sie wurden eingeladen. Speichere deine Änderungen.", "de"))
+	assert.Error(t, informal.Check("Speichern Sie die Änderungen.", "de"))
+}
+
+// TestInstruction tests that registers are asked for in the terms of the language
+func TestInstruction(t *testing.T) {
+	s := Settings{"ja": Formal, "nl": Formal}
+	assert.Contains(t, s.Instruction("ja"), "です/ます")
+	assert.Equal(t, "Use the formal register consistently.", s.Instruction("nl-BE"))
+	assert.Equal(t, "", s.Instruction("de"))
+}
+
+// TestValidate tests that honorifics are only available for Japanese and Korean
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("ko-KR", Honorific))
+	assert.NoError(t, Validate("de", Informal))
+	assert.Error(t, Validate("de", Honorific))
+	assert.Error(t, Validate("fr", "royal"))
+}
+
+// TestFormality tests the synonyms of registers and their DeepL formality parameter
+func TestFormality(t *testing.T) {
+	s := Settings{"ja": "polite", "de": "More", "fr": "casual", "ko": "keigo"}
+	assert.Equal(t, Formal, s.Level("ja-JP"))
+	assert.Equal(t, Formal, s.Level("de"))
+	assert.Equal(t, Honorific, s.Level("ko"))
+	assert.Contains(t, s.Instruction("ja"), "です/ます")
+	assert.NoError(t, Validate("ja", "polite"))
+	assert.Error(t, Validate("de", "keigo"))
+
+	assert.Equal(t, "prefer_more", Formality(s.Level("de")))
+	assert.Equal(t, "prefer_less", Formality(s.Level("fr")))
+	assert.Equal(t, "prefer_more", Formality(s.Level("ko")))
+	assert.Equal(t, "", Formality(s.Level("es")))
+
+	merged := s.Merge(Settings{"DE": Informal})
+	assert.Equal(t, Informal, merged.Level("de"))
+	assert.Equal(t, Formal, merged.Level("ja"))
+	assert.Equal(t, Formal, s.Level("de"))
+}
@@ -0,0 +1,94 @@
+package safety
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// chatTokens are chat template tokens and role markers that could make a source
+// value read as a new turn of the conversation
+var chatTokens = regexp.MustCompile(`(?i)<\|[a-z_]+\|>|\[/?(INST|SYS)\]|<</?SYS>>|(^|\n)\s*#{0,3}\s*(system|assistant|user)\s*:`)
+
+// instructions are phrases addressing the model rather than the reader of the text
+var instructions = regexp.MustCompile(`(?i)\b(ignore|disregard|forget) (all |any )?(the )?(previous|prior|above|earlier) (instructions|prompts?|rules|messages)\b` +
+	`|\byou are (now |no longer )?(a|an|chatgpt|an ai)\b` +
+	`|\b(do not|don't) translate (this|the following)\b` +
+	`|\binstead of translating\b` +
+	`|\b(respond|reply|answer) (only )?with\b` +
+	`|\bnew instructions?\b` +
+	`|\bsystem prompt\b`)
+
+// Sanitize neutralizes chat template tokens and role markers in text so they can't
+// be mistaken for the structure of the conversation
+func Sanitize(text string) string {
+	return chatTokens.ReplaceAllStringFunc(text, func(m string) string {
+		// Keep the line break of role markers, drop the marker
+		if strings.HasPrefix(m, "\n") {
+			return "\n"
+		}
+		return ""
+	})
+}
+
+// Suspicious returns the phrases of text that look like instructions to the model
+func Suspicious(text string) []string {
+	found := instructions.FindAllString(text, -1)
+	found = append(found, chatTokens.FindAllString(text, -1)...)
+	for i, f := range found {
+		found[i] = strings.TrimSpace(f)
+	}
+	return found
+}
+
+// Tag returns a random delimiter tag, so texts can't close the delimiters themselves
+func Tag() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "untrusted-" + hex.EncodeToString(b)
+}
+
+// SeededTag returns a delimiter tag derived from seed and text, for reproducible
+// prompts. Texts can't guess it without knowing the seed.
+func SeededTag(seed int, text string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s", seed, text)))
+	return "untrusted-" + hex.EncodeToString(sum[:4])
+}
+
+// Wrap puts text between the delimiters of tag
+func Wrap(text, tag string) string {
+	return "<" + tag + ">\n" + text + "\n</" + tag + ">"
+}
+
+// Unwrap removes delimiters of tag echoed back by the model
+func Unwrap(text, tag string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "<"+tag+">")
+	text = strings.TrimSuffix(text, "</"+tag+">")
+	return strings.TrimSpace(text)
+}
+
+// metaPrefixes start answers talking to the user instead of translating
+var metaPrefixes = regexp.MustCompile(`(?i)^\s*(sure|certainly|of course|here is|here's|below is|i'm sorry|i am sorry|i cannot|i can't|i won't|as an ai|translation\s*:|translated text\s*:|note\s*:)`)
+
+// metaPhrases are commentary anywhere in an answer
+var metaPhrases = regexp.MustCompile(`(?i)as an ai\b|language model|\bi cannot (translate|help|comply)|\(note:|\btranslation note\b|<\/?untrusted-[0-9a-f]+>`)
+
+// Check reports translations containing meta-commentary that is not in the source,
+// or much longer than the source, a sign the model followed instructions in the text
+func Check(source, translation string) error {
+	if m := metaPrefixes.FindString(translation); m != "" && !metaPrefixes.MatchString(source) {
+		return fmt.Errorf("translation starts like commentary (%q)", strings.TrimSpace(m))
+	}
+	if m := metaPhrases.FindString(translation); m != "" && !metaPhrases.MatchString(source) {
+		return fmt.Errorf("translation contains commentary (%q)", m)
+	}
+	if s, t := utf8.RuneCountInString(source), utf8.RuneCountInString(translation); t > 3*s+40 {
+		return fmt.Errorf("translation is %d characters for a %d character source", t, s)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pandodao/i18n-cli/cmd/parser"
+)
+
+// Layout presets
+const (
+	// LayoutLangDir keeps each language in its own directory: <lang>/<file>.json
+	LayoutLangDir = "lang-dir"
+	// LayoutFlat keeps one file per language in the root: <lang>.json
+	LayoutFlat = "flat"
+	// LayoutNamespaceDir keeps each namespace in its own directory: <namespace>/<lang>.json
+	LayoutNamespaceDir = "namespace-dir"
+)
+
+// Template placeholders
+const (
+	langPlaceholder      = "{lang}"
+	namespacePlaceholder = "{namespace}"
+)
+
+var presets = map[string]string{
+	LayoutLangDir:      "{lang}/{namespace}",
+	LayoutFlat:         "{lang}.json",
+	LayoutNamespaceDir: "{namespace}/{lang}.json",
+}
+
+// Layout maps languages and file types to catalog paths
+type Layout struct {
+	// Template of catalog paths relative to the root directory, e.g. "{namespace}/{lang}.json"
+	Template string
+
+	pattern *regexp.Regexp
+}
+
+// ParseLayout returns the layout of a preset name (lang-dir, flat, namespace-dir) or a
+// custom path template such as "src/{namespace}/i18n/{lang}.json". An empty name is lang-dir.
+func ParseLayout(name string) (Layout, error) {
+	if name == "" {
+		name = LayoutLangDir
+	}
+	template, ok := presets[name]
+	if !ok {
+		// Templates written on Windows use backslashes, which filepath.ToSlash only
+		// converts on Windows itself
+		template = strings.ReplaceAll(name, `\`, "/")
+	}
+
+	if strings.Count(template, langPlaceholder) != 1 {
+		return Layout{}, fmt.Errorf("layout %q must be lang-dir, flat, namespace-dir or a template with exactly one %s", name, langPlaceholder)
+	}
+	if strings.Count(template, namespacePlaceholder) > 1 {
+		return Layout{}, fmt.Errorf("layout %q has more than one %s", name, namespacePlaceholder)
+	}
+	if template != presets[LayoutLangDir] && !isJSON(template) {
+		return Layout{}, fmt.Errorf("layout %q must end with .json", name)
+	}
+
+	expr := regexp.QuoteMeta(template)
+	expr = strings.Replace(expr, regexp.QuoteMeta(langPlaceholder), `(?P<lang>[^/]+)`, 1)
+	expr = strings.Replace(expr, regexp.QuoteMeta(namespacePlaceholder), `(?P<namespace>[^/]+)`, 1)
+
+	// Windows and macOS file systems ignore case, so EN.JSON is a {lang}.json catalog
+	return Layout{Template: template, pattern: regexp.MustCompile("(?i)^" + expr + "$")}, nil
+}
+
+// langDir reports whether the layout is the historical one language per directory layout
+func (l Layout) langDir() bool {
+	return l.Template == "" || l.Template == presets[LayoutLangDir]
+}
+
+// Path returns the catalog path of a language and file type
+func (l Layout) Path(root, lang, fileType string) string {
+	if l.langDir() {
+		return filepath.Join(root, lang, fileType)
+	}
+	rel := strings.Replace(l.Template, langPlaceholder, lang, 1)
+	rel = strings.Replace(rel, namespacePlaceholder, fileType, 1)
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
+
+// FileType returns the file type of the catalog named name in the layout: the file
+// name itself with one language per directory, the name without its extension as the
+// namespace of templates, the template for layouts without namespaces
+func (l Layout) FileType(name string) string {
+	switch {
+	case l.langDir():
+		return name
+	case strings.Contains(l.Template, namespacePlaceholder):
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return l.Template
+}
+
+// match returns the language and file type of a path relative to the root
+func (l Layout) match(rel string) (lang, fileType string, ok bool) {
+	m := l.pattern.FindStringSubmatch(filepath.ToSlash(rel))
+	if m == nil {
+		return "", "", false
+	}
+	lang = m[l.pattern.SubexpIndex("lang")]
+	// Layouts without namespaces have a single file type, named after the template
+	fileType = l.Template
+	if i := l.pattern.SubexpIndex("namespace"); i >= 0 {
+		fileType = m[i]
+	}
+	return lang, fileType, true
+}
+
+// scanTemplate finds the catalogs of a template layout by walking the root directory.
+// Only path segments that are valid language codes are taken as languages.
+func scanTemplate(ds *DirectoryStructure) error {
+	fileTypes := make(map[string]map[string]struct{})
+	// Languages by lower case name, to catch en.json next to EN.json
+	languages := make(map[string]string)
+
+	err := filepath.Walk(ds.RootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Hidden directories (e.g. .git or change logs) never hold catalogs
+			if path != ds.RootDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(ds.RootDir, path)
+		if err != nil {
+			return err
+		}
+		lang, fileType, ok := ds.Layout.match(rel)
+		if !ok {
+			return nil
+		}
+		if _, err := parser.LangCodeToName(lang); err != nil {
+			return nil
+		}
+
+		if other, ok := languages[strings.ToLower(lang)]; ok && other != lang {
+			return fmt.Errorf("languages '%s' and '%s' differ only in case; merge their catalogs", other, lang)
+		}
+		if _, ok := fileTypes[lang]; !ok {
+			fileTypes[lang] = make(map[string]struct{})
+			languages[strings.ToLower(lang)] = lang
+			ds.Languages = append(ds.Languages, lang)
+		}
+		fileTypes[lang][fileType] = struct{}{}
+		ds.LanguageFiles[lang] = append(ds.LanguageFiles[lang], path)
+		ds.FilesByType[fileType] = append(ds.FilesByType[fileType], path)
+		ds.paths[pathKey(lang, fileType)] = path
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(ds.Languages)
+
+	lang, exists := languages[strings.ToLower(ds.SourceLang)]
+	if !exists {
+		return fmt.Errorf("no %s catalogs of source language '%s' found", ds.Layout.Template, ds.SourceLang)
+	}
+	ds.SourceLang = lang
+	for fileType := range fileTypes[ds.SourceLang] {
+		ds.FileTypes = append(ds.FileTypes, fileType)
+	}
+	sort.Strings(ds.FileTypes)
+	return nil
+}
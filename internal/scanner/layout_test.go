@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCatalogs creates small catalog files at the given paths under root
+func writeCatalogs(t *testing.T, root string, paths ...string) {
+	for _, p := range paths {
+		path := filepath.Join(root, filepath.FromSlash(p))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(`{"a":"b"}`), 0644))
+	}
+}
+
+// TestScanLayout tests scanning the flat, namespace-dir and custom template layouts
+func TestScanLayout(t *testing.T) {
+	tests := []struct {
+		layout    string
+		files     []string
+		fileTypes []string
+		path      string
+	}{
+		{
+			layout:    LayoutFlat,
+			files:     []string{"en.json", "fr-FR.json", "package.json"},
+			fileTypes: []string{"{lang}.json"},
+			path:      "fr-FR.json",
+		},
+		{
+			layout:    LayoutNamespaceDir,
+			files:     []string{"common/en.json", "common/fr-FR.json", "auth/en.json", ".history/en.json"},
+			fileTypes: []string{"auth", "common"},
+			path:      "auth/fr-FR.json",
+		},
+		{
+			layout:    "src/{namespace}/i18n/{lang}.json",
+			files:     []string{"src/common/i18n/en.json", "src/common/i18n/fr-FR.json", "src/README.json"},
+			fileTypes: []string{"common"},
+			path:      "src/common/i18n/fr-FR.json",
+		},
+	}
+
+	for _, test := range tests {
+		root := t.TempDir()
+		writeCatalogs(t, root, test.files...)
+
+		layout, err := ParseLayout(test.layout)
+		assert.NoError(t, err, test.layout)
+		ds, err := ScanLayout(root, "en", layout)
+		assert.NoError(t, err, test.layout)
+
+		assert.Equal(t, []string{"en", "fr-FR"}, ds.Languages, test.layout)
+		assert.Equal(t, test.fileTypes, ds.FileTypes, test.layout)
+		assert.Equal(t, filepath.Join(root, filepath.FromSlash(test.path)), ds.Path("fr-FR", test.fileTypes[0]), test.layout)
+
+		pairs, err := ds.GetPairs()
+		assert.NoError(t, err, test.layout)
+		assert.Len(t, pairs, len(test.fileTypes), test.layout)
+	}
+
+	_, err := ParseLayout("{namespace}.json")
+	assert.Error(t, err)
+}
+
+// TestFileType tests the file types of catalogs named after a file in each layout
+func TestFileType(t *testing.T) {
+	for layout, fileType := range map[string]string{
+		LayoutLangDir:                      "common.json",
+		LayoutFlat:                         "{lang}.json",
+		LayoutNamespaceDir:                 "common",
+		"src/{namespace}/i18n/{lang}.json": "common",
+	} {
+		l, err := ParseLayout(layout)
+		assert.NoError(t, err, layout)
+		assert.Equal(t, fileType, l.FileType("common.json"), layout)
+	}
+}
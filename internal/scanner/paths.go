@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// reservedNames are device names Windows refuses as file or directory names, with or
+// without an extension (con.json is as unusable as con)
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// SameName reports whether two file or directory names designate the same entry on a
+// case-insensitive file system such as the default ones of Windows and macOS
+func SameName(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// isJSON reports whether a file name has a .json extension, in any case
+func isJSON(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".json")
+}
+
+// CheckPortable returns an error if a path relative to the catalog root can't be
+// created on Windows: segments that are reserved device names, contain <>:"|?* or
+// control characters, or end with a dot or a space. Both separators are accepted.
+func CheckPortable(rel string) error {
+	for _, segment := range strings.Split(strings.ReplaceAll(rel, `\`, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+
+		base := strings.ToLower(segment)
+		if i := strings.IndexByte(base, '.'); i >= 0 {
+			base = base[:i]
+		}
+		if reservedNames[base] {
+			return fmt.Errorf("%q is a reserved name on Windows", segment)
+		}
+		if strings.HasSuffix(segment, ".") || strings.HasSuffix(segment, " ") {
+			return fmt.Errorf("%q ends with a dot or a space, which Windows drops", segment)
+		}
+		for _, r := range segment {
+			if r < 0x20 || strings.ContainsRune(`<>:"|?*`, r) {
+				return fmt.Errorf("%q contains %q, which is not allowed in Windows file names", segment, r)
+			}
+		}
+	}
+	return nil
+}
+
+// pathKey is the case-insensitive key of a catalog of a language and file type
+func pathKey(lang, fileType string) string {
+	return strings.ToLower(lang) + "\x00" + strings.ToLower(fileType)
+}
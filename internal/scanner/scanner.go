@@ -18,10 +18,19 @@ type DirectoryStructure struct {
 	LanguageDirs  map[string]string   // Map of language code to directory
 	FilesByType   map[string][]string // Map of file type to files
 	LanguageFiles map[string][]string // Map of language code to files
+	Layout        Layout              // How catalog paths are laid out under the root
+
+	paths map[string]string // Catalogs found on disk, by case-insensitive language and file type
 }
 
-// ScanDirectory scans a directory for language files
+// ScanDirectory scans a directory with one subdirectory per language for language files
 func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, error) {
+	layout, _ := ParseLayout(LayoutLangDir)
+	return ScanLayout(rootDir, sourceLang, layout)
+}
+
+// ScanLayout scans a directory for language files laid out as described by layout
+func ScanLayout(rootDir string, sourceLang string, layout Layout) (*DirectoryStructure, error) {
 	// Check if directory exists
 	if _, err := os.Stat(rootDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory %s does not exist", rootDir)
@@ -35,6 +44,15 @@ func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, erro
 		LanguageDirs:  make(map[string]string),
 		FilesByType:   make(map[string][]string),
 		LanguageFiles: make(map[string][]string),
+		Layout:        layout,
+		paths:         make(map[string]string),
+	}
+
+	if !layout.langDir() {
+		if err := scanTemplate(ds); err != nil {
+			return nil, err
+		}
+		return ds, nil
 	}
 
 	// List all subdirectories (language directories)
@@ -45,8 +63,14 @@ func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, erro
 
 	// First, find all language directories
 	for _, entry := range entries {
-		if entry.IsDir() {
+		// Hidden directories (e.g. .git or the .i18n-cli state) are never languages
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 			langCode := entry.Name()
+			// fr and FR are one directory on Windows and macOS, so a tree holding both
+			// can't be checked out there
+			if other, ok := ds.findLanguage(langCode); ok {
+				return nil, fmt.Errorf("language directories '%s' and '%s' differ only in case; merge them", other, langCode)
+			}
 			langPath := filepath.Join(rootDir, langCode)
 			ds.Languages = append(ds.Languages, langCode)
 			ds.LanguageDirs[langCode] = langPath
@@ -54,21 +78,26 @@ func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, erro
 		}
 	}
 
-	// Make sure source language exists
-	if _, exists := ds.LanguageDirs[sourceLang]; !exists {
+	// Make sure source language exists, using the directory name found on disk
+	if lang, exists := ds.findLanguage(sourceLang); exists {
+		ds.SourceLang = lang
+	} else {
 		return nil, fmt.Errorf("source language directory '%s' not found", sourceLang)
 	}
 
 	// Scan source language directory to identify file types
-	sourceFiles, err := os.ReadDir(ds.LanguageDirs[sourceLang])
+	sourceFiles, err := os.ReadDir(ds.LanguageDirs[ds.SourceLang])
 	if err != nil {
 		return nil, err
 	}
 
 	// Identify all JSON files in source directory
 	for _, file := range sourceFiles {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+		if !file.IsDir() && isJSON(file.Name()) {
 			fileType := file.Name()
+			if other, ok := ds.findFileType(fileType); ok {
+				return nil, fmt.Errorf("source files '%s' and '%s' differ only in case; merge them", other, fileType)
+			}
 			ds.FileTypes = append(ds.FileTypes, fileType)
 			ds.FilesByType[fileType] = []string{}
 		}
@@ -82,12 +111,18 @@ func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, erro
 		}
 
 		for _, file := range files {
-			if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			if !file.IsDir() && isJSON(file.Name()) {
 				filePath := filepath.Join(langDir, file.Name())
+				// Target files named Common.json and common.json are the same file type
+				fileType, ok := ds.findFileType(file.Name())
+				if !ok {
+					fileType = file.Name()
+				}
 				// Add file to language files
 				ds.LanguageFiles[lang] = append(ds.LanguageFiles[lang], filePath)
 				// Add file to file types
-				ds.FilesByType[file.Name()] = append(ds.FilesByType[file.Name()], filePath)
+				ds.FilesByType[fileType] = append(ds.FilesByType[fileType], filePath)
+				ds.paths[pathKey(lang, fileType)] = filePath
 			}
 		}
 	}
@@ -95,6 +130,65 @@ func ScanDirectory(rootDir string, sourceLang string) (*DirectoryStructure, erro
 	return ds, nil
 }
 
+// findLanguage returns the scanned language whose name matches lang regardless of case
+func (ds *DirectoryStructure) findLanguage(lang string) (string, bool) {
+	if _, ok := ds.LanguageDirs[lang]; ok {
+		return lang, true
+	}
+	for _, other := range ds.Languages {
+		if SameName(other, lang) {
+			return other, true
+		}
+	}
+	return "", false
+}
+
+// findFileType returns the source file type whose name matches name regardless of case
+func (ds *DirectoryStructure) findFileType(name string) (string, bool) {
+	for _, fileType := range ds.FileTypes {
+		if SameName(fileType, name) {
+			return fileType, true
+		}
+	}
+	return "", false
+}
+
+// Path returns the catalog path of a language and file type. Catalogs found on disk
+// keep their spelling, so that fr/Common.json is not recreated next to FR/common.json.
+func (ds *DirectoryStructure) Path(lang, fileType string) string {
+	if path, ok := ds.paths[pathKey(lang, fileType)]; ok {
+		return path
+	}
+	if ds.Layout.langDir() {
+		if found, ok := ds.findLanguage(lang); ok {
+			lang = found
+		}
+	}
+	return ds.Layout.Path(ds.RootDir, lang, fileType)
+}
+
+// exists reports whether the catalog of a language and file type is on disk
+func (ds *DirectoryStructure) exists(lang, fileType string) bool {
+	_, err := os.Stat(ds.Path(lang, fileType))
+	return err == nil
+}
+
+// checkPortable returns an error if the catalog path of a language and file type can't
+// be created on Windows. Catalogs that already exist are never rejected.
+func (ds *DirectoryStructure) checkPortable(lang, fileType string) error {
+	if ds.exists(lang, fileType) {
+		return nil
+	}
+	rel, err := filepath.Rel(ds.RootDir, ds.Path(lang, fileType))
+	if err != nil {
+		return err
+	}
+	if err := CheckPortable(rel); err != nil {
+		return fmt.Errorf("cannot create %s: %w", rel, err)
+	}
+	return nil
+}
+
 // GetPairs returns pairs of source and target files that need to be processed
 func (ds *DirectoryStructure) GetPairs() ([]FilePair, error) {
 	pairs := []FilePair{}
@@ -108,14 +202,17 @@ func (ds *DirectoryStructure) GetPairs() ([]FilePair, error) {
 		// For each file type
 		for _, fileType := range ds.FileTypes {
 			// Get source file path
-			sourcePath := filepath.Join(ds.LanguageDirs[ds.SourceLang], fileType)
+			sourcePath := ds.Path(ds.SourceLang, fileType)
 			if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
 				// Source file doesn't exist, skip
 				continue
 			}
 
 			// Get or create target file path
-			targetPath := filepath.Join(ds.LanguageDirs[lang], fileType)
+			if err := ds.checkPortable(lang, fileType); err != nil {
+				return nil, err
+			}
+			targetPath := ds.Path(lang, fileType)
 
 			// Create the pair
 			pair := FilePair{
@@ -189,14 +286,19 @@ func (ds *DirectoryStructure) FindMissingPairs() []FilePair {
 		}
 
 		for _, fileType := range ds.FileTypes {
-			sourcePath := filepath.Join(ds.LanguageDirs[ds.SourceLang], fileType)
-			targetPath := filepath.Join(ds.LanguageDirs[lang], fileType)
+			sourcePath := ds.Path(ds.SourceLang, fileType)
+			targetPath := ds.Path(lang, fileType)
 
 			if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
 				// Source file doesn't exist, skip
 				continue
 			}
 
+			if ds.checkPortable(lang, fileType) != nil {
+				// GetPairs reports catalogs that can't be created
+				continue
+			}
+
 			if _, err := os.Stat(targetPath); os.IsNotExist(err) {
 				// Target file doesn't exist, add to missing
 				pair := FilePair{
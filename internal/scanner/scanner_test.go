@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScanDirectoryCase tests that language directories and file names are matched
+// regardless of case, so that sync doesn't create fr/Common.json next to fr/common.json
+func TestScanDirectoryCase(t *testing.T) {
+	root := t.TempDir()
+	writeCatalogs(t, root, "EN/Common.json", "EN/auth.JSON", "fr/common.json")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "de"), 0755))
+
+	ds, err := ScanDirectory(root, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, "EN", ds.SourceLang)
+	assert.ElementsMatch(t, []string{"Common.json", "auth.JSON"}, ds.FileTypes)
+
+	assert.Equal(t, filepath.Join(root, "fr", "common.json"), ds.Path("fr", "Common.json"))
+	assert.Equal(t, filepath.Join(root, "fr", "common.json"), ds.Path("FR", "common.json"))
+	assert.Equal(t, filepath.Join(root, "EN", "Common.json"), ds.Path("en", "Common.json"))
+	assert.Equal(t, filepath.Join(root, "de", "Common.json"), ds.Path("DE", "Common.json"))
+
+	pairs, err := ds.GetPairs()
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 4)
+
+	missing := ds.FindMissingPairs()
+	targets := []string{}
+	for _, pair := range missing {
+		targets = append(targets, pair.TargetFile)
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(root, "de", "Common.json"),
+		filepath.Join(root, "de", "auth.JSON"),
+		filepath.Join(root, "fr", "auth.JSON"),
+	}, targets)
+}
+
+// TestScanDirectoryCaseConflict tests that directories differing only in case are reported
+func TestScanDirectoryCaseConflict(t *testing.T) {
+	root := t.TempDir()
+	writeCatalogs(t, root, "en/common.json", "fr/common.json", "FR/common.json")
+	if entries, _ := os.ReadDir(root); len(entries) < 3 {
+		t.Skip("case-insensitive file system")
+	}
+
+	_, err := ScanDirectory(root, "en")
+	assert.ErrorContains(t, err, "differ only in case")
+}
+
+// TestScanLayoutCase tests that template layouts match catalogs regardless of case
+func TestScanLayoutCase(t *testing.T) {
+	root := t.TempDir()
+	writeCatalogs(t, root, "EN.JSON", "fr.json")
+
+	layout, err := ParseLayout(LayoutFlat)
+	assert.NoError(t, err)
+	ds, err := ScanLayout(root, "en", layout)
+	assert.NoError(t, err)
+	assert.Equal(t, "EN", ds.SourceLang)
+	assert.Equal(t, filepath.Join(root, "EN.JSON"), ds.Path("en", ds.FileTypes[0]))
+	assert.Equal(t, filepath.Join(root, "fr.json"), ds.Path("fr", ds.FileTypes[0]))
+}
+
+// TestParseLayoutBackslashes tests that templates written with Windows separators work everywhere
+func TestParseLayoutBackslashes(t *testing.T) {
+	layout, err := ParseLayout(`src\{namespace}\{lang}.json`)
+	assert.NoError(t, err)
+	assert.Equal(t, "src/{namespace}/{lang}.json", layout.Template)
+	assert.Equal(t, filepath.Join("root", "src", "common", "fr.json"), layout.Path("root", "fr", "common"))
+
+	lang, fileType, ok := layout.match(filepath.Join("src", "common", "fr.json"))
+	assert.True(t, ok)
+	assert.Equal(t, "fr", lang)
+	assert.Equal(t, "common", fileType)
+}
+
+// TestCheckPortable tests the detection of paths Windows can't create
+func TestCheckPortable(t *testing.T) {
+	for _, rel := range []string{"fr/common.json", `fr\common.json`, "pt-BR/console.json", "zh-Hant/a.b.json"} {
+		assert.NoError(t, CheckPortable(rel), rel)
+	}
+	for _, rel := range []string{"fr/con.json", "AUX/common.json", "fr/nul", "com1.json", "fr/what?.json", "fr/a:b.json", "fr./common.json", "fr/common.json "} {
+		assert.Error(t, CheckPortable(rel), rel)
+	}
+}
+
+// TestGetPairsPortable tests that catalogs which can't be created on Windows are refused
+func TestGetPairsPortable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("reserved names can't be created on Windows")
+	}
+	root := t.TempDir()
+	writeCatalogs(t, root, "en/aux.json", "en/common.json", "fr/common.json")
+
+	ds, err := ScanDirectory(root, "en")
+	assert.NoError(t, err)
+
+	_, err = ds.GetPairs()
+	assert.ErrorContains(t, err, "reserved name")
+	assert.Len(t, ds.FindMissingPairs(), 0)
+}
@@ -0,0 +1,84 @@
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// MinShare is the share of letters that must be written in the expected script
+const MinShare = 0.5
+
+// tables maps ISO 15924 script codes to the Unicode scripts they are written in
+var tables = map[string][]*unicode.RangeTable{
+	"Latn": {unicode.Latin},
+	"Cyrl": {unicode.Cyrillic},
+	"Grek": {unicode.Greek},
+	"Armn": {unicode.Armenian},
+	"Geor": {unicode.Georgian},
+	"Hebr": {unicode.Hebrew},
+	"Arab": {unicode.Arabic},
+	"Thai": {unicode.Thai},
+	"Laoo": {unicode.Lao},
+	"Khmr": {unicode.Khmer},
+	"Mymr": {unicode.Myanmar},
+	"Deva": {unicode.Devanagari},
+	"Beng": {unicode.Bengali},
+	"Guru": {unicode.Gurmukhi},
+	"Gujr": {unicode.Gujarati},
+	"Taml": {unicode.Tamil},
+	"Telu": {unicode.Telugu},
+	"Knda": {unicode.Kannada},
+	"Mlym": {unicode.Malayalam},
+	"Sinh": {unicode.Sinhala},
+	"Ethi": {unicode.Ethiopic},
+	"Hans": {unicode.Han},
+	"Hant": {unicode.Han},
+	"Jpan": {unicode.Han, unicode.Hiragana, unicode.Katakana},
+	"Kore": {unicode.Hangul, unicode.Han},
+}
+
+// markup matches placeholders, tags and URLs, which stay in Latin whatever the language
+var markup = regexp.MustCompile(`\{[^{}]*\}|%[-+# 0-9.]*[a-zA-Z]|<[^<>]*>|https?://\S+|&[a-zA-Z]+;`)
+
+// Expected returns the script code a language is written in, e.g. "Cyrl" for ru
+// and "Jpan" for ja. It returns "" for codes it can't resolve.
+func Expected(code string) string {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return ""
+	}
+	s, conf := tag.Script()
+	if conf == language.No {
+		return ""
+	}
+	return s.String()
+}
+
+// Check reports an error if text is not predominantly written in the script of the
+// language code. Languages with an unknown script and texts without letters pass.
+func Check(text, code string) error {
+	expected := Expected(code)
+	ranges, ok := tables[expected]
+	if !ok {
+		return nil
+	}
+
+	letters, matching := 0, 0
+	for _, r := range markup.ReplaceAllString(text, "") {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, ranges...) {
+			matching++
+		}
+	}
+
+	if letters == 0 || float64(matching)/float64(letters) >= MinShare {
+		return nil
+	}
+	return fmt.Errorf("translation is not in the %s script expected for %s (%d of %d letters)", expected, code, matching, letters)
+}
@@ -0,0 +1,33 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExpected tests the script resolved for language codes
+func TestExpected(t *testing.T) {
+	assert.Equal(t, "Cyrl", Expected("ru"))
+	assert.Equal(t, "Hans", Expected("zh"))
+	assert.Equal(t, "Hant", Expected("zh-TW"))
+	assert.Equal(t, "Jpan", Expected("ja"))
+	assert.Equal(t, "Latn", Expected("fr-FR"))
+	assert.Equal(t, "Cyrl", Expected("sr"))
+	assert.Equal(t, "Latn", Expected("sr-Latn"))
+	assert.Equal(t, "", Expected("not a code"))
+}
+
+// TestCheck tests detecting translations written in the wrong script
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("Привет, {name}!", "ru"))
+	assert.NoError(t, Check("ようこそ、{user}さん。iPhone をご利用ください", "ja"))
+	assert.NoError(t, Check("欢迎使用 <b>%s</b>", "zh-CN"))
+	assert.NoError(t, Check("Bonjour", "fr"))
+	assert.NoError(t, Check("{count} 123", "ru"))
+	assert.NoError(t, Check("anything", "tlh"))
+
+	assert.Error(t, Check("Hello, {name}!", "ru"))
+	assert.Error(t, Check("Привет", "ja"))
+	assert.Error(t, Check("Welcome back", "zh"))
+}
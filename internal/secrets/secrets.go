@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Kinds of secrets
+const (
+	// KindAPIKey is a provider API key or access token (OpenAI, AWS, GitHub, Slack, ...)
+	KindAPIKey = "api-key"
+	// KindPrivateKey is a PEM private key
+	KindPrivateKey = "private-key"
+	// KindJWT is a JSON Web Token
+	KindJWT = "jwt"
+	// KindCredential is a password or token assignment, or a URL with a password
+	KindCredential = "credential"
+	// KindEmail is an email address
+	KindEmail = "email"
+	// KindInternalURL is a URL of a private host (localhost, *.internal, 10.0.0.0/8, ...)
+	KindInternalURL = "internal-url"
+)
+
+// Kinds lists every kind of secret
+var Kinds = []string{KindAPIKey, KindPrivateKey, KindJWT, KindCredential, KindEmail, KindInternalURL}
+
+var patterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{KindPrivateKey, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{KindJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{KindAPIKey, regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}` +
+		`|\b(AKIA|ASIA)[0-9A-Z]{16}\b` +
+		`|\bgh[pousr]_[A-Za-z0-9]{36,}` +
+		`|\bgithub_pat_[A-Za-z0-9_]{22,}` +
+		`|\bglpat-[A-Za-z0-9_-]{20,}` +
+		`|\bxox[abposr]-[A-Za-z0-9-]{10,}` +
+		`|\bAIza[0-9A-Za-z_-]{35}` +
+		`|\b[rs]k_(live|test)_[0-9A-Za-z]{16,}`)},
+	{KindCredential, regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|password|passwd|pwd)\s*[:=]\s*["']?[^\s"']{8,}` +
+		`|\b[a-z][a-z0-9+.-]*://[^\s/:@]+:[^\s/@]+@`)},
+	{KindEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+}
+
+// urls match URLs, capturing their host
+var urls = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://(?:[^\s/@]*@)?(\[[0-9a-f:.]+\]|[^\s/:?#]+)`)
+
+// internalSuffixes are top-level domains that don't resolve on the internet
+var internalSuffixes = []string{".local", ".internal", ".corp", ".lan", ".intranet", ".home.arpa", ".localdomain"}
+
+// Options configures the detection of secrets
+type Options struct {
+	// Disabled turns detection off
+	Disabled bool `json:"disabled,omitempty"`
+	// Allow lists values that are not secrets, "*" matching any text, e.g. "*@example.com"
+	Allow []string `json:"allow,omitempty"`
+	// Ignore lists the kinds of secrets not to look for, e.g. "email"
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// Finding is a secret found in a text
+type Finding struct {
+	Kind  string `json:"kind"`
+	Match string `json:"match"`
+}
+
+// String describes a finding without revealing the secret
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s", f.Kind, Redact(f.Match))
+}
+
+// Validate returns an error for unknown kinds of secrets
+func (o Options) Validate() error {
+	for _, kind := range o.Ignore {
+		known := false
+		for _, k := range Kinds {
+			known = known || k == kind
+		}
+		if !known {
+			return fmt.Errorf("unknown secret kind %q (expected one of %s)", kind, strings.Join(Kinds, ", "))
+		}
+	}
+	return nil
+}
+
+// Scan returns the secrets found in text. Text is normalized first, so that secrets
+// spelled with fullwidth characters or split by invisible characters are still found.
+func (o Options) Scan(text string) []Finding {
+	if o.Disabled || text == "" {
+		return nil
+	}
+	text = normalize(text)
+
+	findings := []Finding{}
+	seen := map[string]bool{}
+	add := func(kind, match string) {
+		if seen[match] || o.ignored(kind) || o.allowed(match) {
+			return
+		}
+		seen[match] = true
+		findings = append(findings, Finding{Kind: kind, Match: match})
+	}
+
+	for _, p := range patterns {
+		for _, loc := range p.re.FindAllStringIndex(text, -1) {
+			// user:password@host in URLs is a credential, not an email address
+			if p.kind == KindEmail && loc[0] > 0 && strings.ContainsRune(":/", rune(text[loc[0]-1])) {
+				continue
+			}
+			add(p.kind, text[loc[0]:loc[1]])
+		}
+	}
+	for _, m := range urls.FindAllStringSubmatch(text, -1) {
+		if internalHost(m[1]) {
+			add(KindInternalURL, m[0])
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Kind < findings[j].Kind })
+	return findings
+}
+
+func (o Options) ignored(kind string) bool {
+	for _, k := range o.Ignore {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Options) allowed(match string) bool {
+	for _, pattern := range o.Allow {
+		if globMatch(pattern, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches s against a pattern where "*" matches any text, ignoring case
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(s)
+}
+
+// normalize folds compatibility characters (fullwidth letters, ...) and drops the
+// invisible format characters (zero-width spaces and joiners, ...) of text
+func normalize(text string) string {
+	text = norm.NFKC.String(text)
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// internalHost reports whether a URL host is private: localhost, an internal domain
+// or a loopback, private or link-local IP address
+func internalHost(host string) bool {
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+	}
+	return false
+}
+
+// Redact keeps the first characters of a secret, enough to find it, and hides the rest
+func Redact(s string) string {
+	r := []rune(s)
+	keep := 4
+	if len(r) <= 2*keep {
+		keep = len(r) / 4
+	}
+	return string(r[:keep]) + strings.Repeat("*", 8)
+}
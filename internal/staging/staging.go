@@ -0,0 +1,83 @@
+package staging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/pandodao/i18n-cli/internal/state"
+)
+
+const stateFile = "staging.json"
+
+// Entry is a machine translation held for review before it is written to its catalog
+type Entry struct {
+	ID   string `json:"id"`
+	File string `json:"file"`
+	Key  string `json:"key"`
+	// Language code of the catalog
+	Lang        string `json:"lang"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	// Value of the key in the catalog the translation replaces, for retranslations
+	Previous string `json:"previous,omitempty"`
+	// Provider and model the translation came from
+	Provider string    `json:"provider,omitempty"`
+	StagedAt time.Time `json:"stagedAt"`
+}
+
+// Store holds the pending translations by ID
+type Store map[string]Entry
+
+// ID returns the ID of the pending translation of key in file
+func ID(file, key string) string {
+	sum := sha256.Sum256([]byte(file + "\x00" + key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Load returns the pending translations of the project
+func Load() (Store, error) {
+	s := Store{}
+	if err := state.Load(stateFile, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the pending translations of the project
+func (s Store) Save() error {
+	return state.Save(stateFile, s)
+}
+
+// Add holds e for review, replacing the pending translation of the same key
+func (s Store) Add(e Entry) {
+	e.ID = ID(e.File, e.Key)
+	s[e.ID] = e
+}
+
+// Keys returns the keys of file with a pending translation
+func (s Store) Keys(file string) map[string]bool {
+	keys := map[string]bool{}
+	for _, e := range s {
+		if e.File == file {
+			keys[e.Key] = true
+		}
+	}
+	return keys
+}
+
+// Entries returns the pending translations sorted by file, then key
+func (s Store) Entries() []Entry {
+	entries := make([]Entry, 0, len(s))
+	for _, e := range s {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
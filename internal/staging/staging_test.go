@@ -0,0 +1,25 @@
+package staging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStore tests that restaging a key replaces its pending translation
+func TestStore(t *testing.T) {
+	s := Store{}
+	s.Add(Entry{File: "fr/common.json", Key: "title", Translation: "Titre"})
+	s.Add(Entry{File: "de/common.json", Key: "title", Translation: "Titel"})
+	s.Add(Entry{File: "fr/common.json", Key: "title", Translation: "Le titre"})
+
+	assert.Len(t, s, 2)
+	assert.Equal(t, "Le titre", s[ID("fr/common.json", "title")].Translation)
+	assert.NotEqual(t, ID("fr/common.json", "title"), ID("de/common.json", "title"))
+	assert.Equal(t, map[string]bool{"title": true}, s.Keys("fr/common.json"))
+	assert.Empty(t, s.Keys("es/common.json"))
+
+	entries := s.Entries()
+	assert.Equal(t, "de/common.json", entries[0].File)
+	assert.Equal(t, entries[0].ID, ID("de/common.json", "title"))
+}
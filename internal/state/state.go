@@ -0,0 +1,152 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// AppName is the name of the per-user directories of i18n-cli
+const AppName = "i18n-cli"
+
+// LegacyDirName is the directory, relative to the working directory, where state was
+// kept before it moved to the per-user data directory. It is still read as a fallback.
+const LegacyDirName = ".i18n-cli"
+
+// DataDir returns the per-user data directory: $XDG_DATA_HOME/i18n-cli (default
+// ~/.local/share) on Linux and other Unixes, ~/Library/Application Support/i18n-cli
+// on macOS and %AppData%\i18n-cli on Windows
+func DataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, AppName), nil
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, AppName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", AppName), nil
+}
+
+// CacheDir returns the per-user cache directory: $XDG_CACHE_HOME/i18n-cli (default
+// ~/.cache) on Linux, ~/Library/Caches/i18n-cli on macOS and %LocalAppData%\i18n-cli
+// on Windows. Everything in it can be deleted at any time.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, AppName), nil
+}
+
+// project is the catalog root the state of this run belongs to, "" for the working
+// directory
+var project string
+
+// SetProject keys the state of this run on dir, the root of its catalogs, so that a
+// project keeps the same state whatever directory it is run from
+func SetProject(dir string) {
+	project = dir
+}
+
+// ProjectDir returns the data directory of the project: that of its catalog root when
+// set, of the working directory otherwise. Projects are told apart by their absolute
+// path, e.g. projects/locales-1a2b3c4d.
+func ProjectDir() (string, error) {
+	return projectDirOf(project)
+}
+
+// projectDirOf returns the data directory of the project in dir, "" for the working
+// directory
+func projectDirOf(dir string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		if dir, err = os.Getwd(); err != nil {
+			return "", err
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(data, "projects", filepath.Base(dir)+"-"+hex.EncodeToString(sum[:4])), nil
+}
+
+// Dir returns the state directory of the project, creating it if it doesn't exist
+func Dir() (string, error) {
+	dir, err := ProjectDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns the path of a named file inside the state directory of the project
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load reads a JSON state file into v. A missing file leaves v untouched and is not an
+// error. Files kept for the working directory, before projects were keyed on their
+// catalog root, and in the legacy working directory location are read if needed.
+func Load(name string, v interface{}) error {
+	dir, err := ProjectDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) && project != "" {
+		var wd string
+		if wd, err = projectDirOf(""); err == nil {
+			data, err = os.ReadFile(filepath.Join(wd, name))
+		}
+	}
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(filepath.Join(LegacyDirName, name))
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Save writes v as a JSON state file
+func Save(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
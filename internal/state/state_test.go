@@ -0,0 +1,89 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveLoad tests state files are kept per project in the data directory
+func TestSaveLoad(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	project := t.TempDir()
+	require.NoError(t, os.Chdir(project))
+	defer os.Chdir(wd)
+
+	dir, err := ProjectDir()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dir, filepath.Join(os.Getenv("XDG_DATA_HOME"), AppName, "projects", filepath.Base(project)+"-")))
+
+	// Legacy files in the working directory are still read
+	require.NoError(t, os.MkdirAll(LegacyDirName, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(LegacyDirName, "state.json"), []byte(`{"n": 1}`), 0644))
+	var v struct{ N int }
+	require.NoError(t, Load("state.json", &v))
+	assert.Equal(t, 1, v.N)
+
+	v.N = 2
+	require.NoError(t, Save("state.json", v))
+	_, err = os.Stat(filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+
+	var loaded struct{ N int }
+	require.NoError(t, Load("state.json", &loaded))
+	assert.Equal(t, 2, loaded.N)
+
+	// Missing files are not an error
+	require.NoError(t, Load("missing.json", &loaded))
+}
+
+// TestSetProject tests that a project keyed on its catalog root keeps its state whatever
+// directory it runs from, and still reads the state kept for the working directory
+func TestSetProject(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defer SetProject("")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd)
+	project := t.TempDir()
+	root := filepath.Join(project, "web", "locales")
+	require.NoError(t, os.MkdirAll(root, 0755))
+
+	// State saved before projects were keyed on their root
+	require.NoError(t, os.Chdir(filepath.Join(project, "web")))
+	require.NoError(t, Save("state.json", struct{ N int }{1}))
+
+	SetProject("locales")
+	var v struct{ N int }
+	require.NoError(t, Load("state.json", &v))
+	assert.Equal(t, 1, v.N)
+	v.N = 2
+	require.NoError(t, Save("state.json", v))
+	fromWeb, err := ProjectDir()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(fromWeb), "locales-"))
+
+	require.NoError(t, os.Chdir(project))
+	SetProject(filepath.Join("web", "locales"))
+	fromProject, err := ProjectDir()
+	require.NoError(t, err)
+	assert.Equal(t, fromWeb, fromProject)
+	var loaded struct{ N int }
+	require.NoError(t, Load("state.json", &loaded))
+	assert.Equal(t, 2, loaded.N)
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Store metadata fields, each mapped to a catalog key
+const (
+	FieldName             = "name"
+	FieldSubtitle         = "subtitle"
+	FieldShortDescription = "shortDescription"
+	FieldDescription      = "description"
+	FieldKeywords         = "keywords"
+	FieldPromotionalText  = "promotionalText"
+)
+
+// Fields lists every metadata field in display order
+var Fields = []string{FieldName, FieldSubtitle, FieldShortDescription, FieldDescription, FieldKeywords, FieldPromotionalText}
+
+// DefaultKeys maps each field to the catalog key holding it by default
+func DefaultKeys() map[string]string {
+	keys := make(map[string]string, len(Fields))
+	for _, field := range Fields {
+		keys[field] = "store/" + field
+	}
+	return keys
+}
+
+// File is a metadata file of a store listing
+type File struct {
+	// File name in the locale directory
+	Name string
+	// Fields the file is filled from, the first one with a value wins
+	Sources []string
+	// Maximum length in characters
+	Limit int
+}
+
+// Store describes the metadata layout of an app store console, as used by fastlane
+type Store struct {
+	Name  string
+	Files []File
+	// Store locale codes by language code, for codes the store spells differently
+	Locales map[string]string
+}
+
+// Apple is the App Store Connect layout (fastlane deliver)
+var Apple = Store{
+	Name: "apple",
+	Files: []File{
+		{Name: "name.txt", Sources: []string{FieldName}, Limit: 30},
+		{Name: "subtitle.txt", Sources: []string{FieldSubtitle}, Limit: 30},
+		{Name: "promotional_text.txt", Sources: []string{FieldPromotionalText}, Limit: 170},
+		{Name: "description.txt", Sources: []string{FieldDescription}, Limit: 4000},
+		{Name: "keywords.txt", Sources: []string{FieldKeywords}, Limit: 100},
+	},
+	Locales: map[string]string{
+		"ar": "ar-SA", "de": "de-DE", "en": "en-US", "es": "es-ES", "fr": "fr-FR",
+		"nl": "nl-NL", "pt": "pt-PT", "zh": "zh-Hans", "zh-CN": "zh-Hans", "zh-TW": "zh-Hant",
+		"zh-HK": "zh-Hant",
+	},
+}
+
+// Google is the Google Play Console layout (fastlane supply)
+var Google = Store{
+	Name: "google",
+	Files: []File{
+		{Name: "title.txt", Sources: []string{FieldName}, Limit: 30},
+		{Name: "short_description.txt", Sources: []string{FieldShortDescription, FieldSubtitle}, Limit: 80},
+		{Name: "full_description.txt", Sources: []string{FieldDescription}, Limit: 4000},
+	},
+	Locales: map[string]string{
+		"ar": "ar", "de": "de-DE", "en": "en-US", "es": "es-ES", "fr": "fr-FR", "it": "it-IT",
+		"ja": "ja-JP", "ko": "ko-KR", "nl": "nl-NL", "pl": "pl-PL", "pt": "pt-PT", "ru": "ru-RU",
+		"sv": "sv-SE", "tr": "tr-TR", "zh": "zh-CN", "zh-Hans": "zh-CN", "zh-Hant": "zh-TW",
+		"es-MX": "es-419",
+	},
+}
+
+// Stores lists the supported stores by name
+var Stores = map[string]Store{Apple.Name: Apple, Google.Name: Google}
+
+// Violation is a metadata file exceeding its store limit
+type Violation struct {
+	Store  string
+	Locale string
+	File   string
+	Length int
+	Limit  int
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s/%s/%s is %d characters long, the limit is %d", v.Store, v.Locale, v.File, v.Length, v.Limit)
+}
+
+// Locale returns the store locale code for a language code
+func (s Store) Locale(code string) string {
+	code = strings.ReplaceAll(code, "_", "-")
+	if locale, ok := s.Locales[code]; ok {
+		return locale
+	}
+	return code
+}
+
+// keywordSeparator matches the spaces around keyword commas, which count against the limit
+var keywordSeparator = regexp.MustCompile(`\s*,\s*`)
+
+// Export writes the metadata files of one language to <dir>/<store>/<locale>/. Fields
+// without a value are skipped. Files are written even when they exceed the store
+// limits; the violations are returned for the caller to report.
+func (s Store) Export(dir, code string, values map[string]string) ([]Violation, error) {
+	locale := s.Locale(code)
+	localeDir := filepath.Join(dir, s.Name, locale)
+
+	violations := []Violation{}
+	for _, file := range s.Files {
+		value := ""
+		for _, field := range file.Sources {
+			if v := strings.TrimSpace(values[field]); v != "" {
+				value = v
+				if field == FieldKeywords {
+					value = keywordSeparator.ReplaceAllString(v, ",")
+				}
+				break
+			}
+		}
+		if value == "" {
+			continue
+		}
+
+		if err := os.MkdirAll(localeDir, 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(localeDir, file.Name), []byte(value+"\n"), 0644); err != nil {
+			return nil, err
+		}
+
+		if length := utf8.RuneCountInString(value); length > file.Limit {
+			violations = append(violations, Violation{Store: s.Name, Locale: locale, File: file.Name, Length: length, Limit: file.Limit})
+		}
+	}
+	return violations, nil
+}
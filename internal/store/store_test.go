@@ -0,0 +1,49 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLocale tests mapping language codes to store locales
+func TestLocale(t *testing.T) {
+	assert.Equal(t, "fr-FR", Apple.Locale("fr"))
+	assert.Equal(t, "ja", Apple.Locale("ja"))
+	assert.Equal(t, "zh-Hans", Apple.Locale("zh-CN"))
+	assert.Equal(t, "ja-JP", Google.Locale("ja"))
+	assert.Equal(t, "pt-BR", Google.Locale("pt-BR"))
+	assert.Equal(t, "de-AT", Google.Locale("de_AT"))
+}
+
+// TestExport tests writing store files and reporting length violations
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	values := map[string]string{
+		FieldName:        "Photo Tidy",
+		FieldSubtitle:    strings.Repeat("x", 31),
+		FieldDescription: "Clean up your camera roll.",
+		FieldKeywords:    "photos, cleanup , duplicates",
+	}
+
+	violations, err := Apple.Export(dir, "fr", values)
+	assert.NoError(t, err)
+	assert.Equal(t, []Violation{{Store: "apple", Locale: "fr-FR", File: "subtitle.txt", Length: 31, Limit: 30}}, violations)
+
+	keywords, err := os.ReadFile(filepath.Join(dir, "apple", "fr-FR", "keywords.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "photos,cleanup,duplicates\n", string(keywords))
+	_, err = os.Stat(filepath.Join(dir, "apple", "fr-FR", "promotional_text.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// The short description falls back to the subtitle
+	violations, err = Google.Export(dir, "fr", values)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+	short, err := os.ReadFile(filepath.Join(dir, "google", "fr-FR", "short_description.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 31)+"\n", string(short))
+}
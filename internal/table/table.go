@@ -0,0 +1,158 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/width"
+)
+
+// Bidi isolation marks: FIRST STRONG ISOLATE and POP DIRECTIONAL ISOLATE
+const (
+	isolateStart = "⁨"
+	isolateEnd   = "⁩"
+)
+
+// ellipsis ends truncated cells
+const ellipsis = "…"
+
+// Width returns the number of terminal columns s takes: CJK and other wide characters
+// take two, combining marks and invisible format characters none
+func Width(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+func runeWidth(r rune) int {
+	if r < 0x20 || r == 0x7f || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// Truncate shortens s to at most max columns, ending it with an ellipsis when cut.
+// Wide characters are never split. 0 or less means no limit.
+func Truncate(s string, max int) string {
+	if max <= 0 || Width(s) <= max {
+		return s
+	}
+
+	var b strings.Builder
+	w := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if w+rw > max-Width(ellipsis) {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+	}
+	return b.String() + ellipsis
+}
+
+// Isolate wraps text containing right-to-left characters (Arabic, Hebrew, ...) in bidi
+// isolation marks, so that it doesn't reorder the separators and cells around it
+func Isolate(s string) string {
+	for _, r := range s {
+		p, _ := bidi.LookupRune(r)
+		if c := p.Class(); c == bidi.R || c == bidi.AL {
+			return isolateStart + s + isolateEnd
+		}
+	}
+	return s
+}
+
+// Cell renders a value for a markdown table cell: pipes and line breaks are escaped,
+// the value truncated to max columns (0 for no limit) and isolated if right-to-left
+func Cell(v string, max int) string {
+	v = strings.ReplaceAll(v, "|", "\\|")
+	v = strings.ReplaceAll(v, "\r", "")
+	v = strings.ReplaceAll(v, "\n", "\\n")
+	return Isolate(Truncate(v, max))
+}
+
+// Table is a markdown table whose columns are aligned by display width
+type Table struct {
+	header []string
+	rows   [][]string
+	// MaxWidth truncates the cells of every row to this many columns, 0 for no limit
+	MaxWidth int
+}
+
+// New returns a table with the given column headers
+func New(header ...string) *Table {
+	return &Table{header: header}
+}
+
+// Add appends a row; values are formatted with fmt.Sprint
+func (t *Table) Add(values ...interface{}) {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = fmt.Sprint(v)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// String renders the table as markdown, one line per row
+func (t *Table) String() string {
+	cells := make([][]string, 0, len(t.rows)+1)
+	cells = append(cells, t.render(t.header, 0))
+	for _, row := range t.rows {
+		cells = append(cells, t.render(row, t.MaxWidth))
+	}
+
+	widths := make([]int, len(t.header))
+	for _, row := range cells {
+		for i, cell := range row {
+			if w := Width(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	var b strings.Builder
+	for n, row := range cells {
+		t.writeRow(&b, row, widths)
+		if n == 0 {
+			divider := make([]string, len(widths))
+			for i, w := range widths {
+				divider[i] = strings.Repeat("-", w)
+			}
+			t.writeRow(&b, divider, widths)
+		}
+	}
+	return b.String()
+}
+
+// render returns the cells of a row, with as many cells as the header
+func (t *Table) render(row []string, max int) []string {
+	cells := make([]string, len(t.header))
+	for i := range cells {
+		if i < len(row) {
+			cells[i] = Cell(row[i], max)
+		}
+	}
+	return cells
+}
+
+func (t *Table) writeRow(b *strings.Builder, cells []string, widths []int) {
+	b.WriteString("|")
+	for i, cell := range cells {
+		b.WriteString(" " + cell + strings.Repeat(" ", widths[i]-Width(cell)) + " |")
+	}
+	b.WriteString("\n")
+}
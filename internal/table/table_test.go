@@ -0,0 +1,55 @@
+package table
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWidth tests the display width of wide, combining and invisible characters
+func TestWidth(t *testing.T) {
+	assert.Equal(t, 5, Width("Hello"))
+	assert.Equal(t, 8, Width("日本語版"))
+	assert.Equal(t, 8, Width("ｆｕｌｌ"))
+	assert.Equal(t, 4, Width("café"))
+	assert.Equal(t, 4, Width("café"))
+	assert.Equal(t, 5, Width("⁨שלום⁩!"))
+}
+
+// TestTruncate tests that truncation counts columns and never splits wide characters
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Hello", Truncate("Hello", 5))
+	assert.Equal(t, "Hell…", Truncate("Hello world", 5))
+	assert.Equal(t, "日本…", Truncate("日本語版", 6))
+	assert.Equal(t, "日本…", Truncate("日本語版", 5))
+	assert.Equal(t, "日本語版", Truncate("日本語版", 0))
+}
+
+// TestIsolate tests that only right-to-left text is isolated
+func TestIsolate(t *testing.T) {
+	assert.Equal(t, "Hello", Isolate("Hello"))
+	assert.Equal(t, "⁨مرحبا⁩", Isolate("مرحبا"))
+	assert.Equal(t, "⁨Welcome שלום⁩", Isolate("Welcome שלום"))
+}
+
+// TestTable tests aligned rendering of CJK, RTL and escaped cells
+func TestTable(t *testing.T) {
+	tbl := New("Language", "Key", "Text")
+	tbl.MaxWidth = 8
+	tbl.Add("ja", "home/title", "ようこそ、ゲストさん")
+	tbl.Add("ar", "a|b", "مرحبا")
+	tbl.Add("fr", 3)
+
+	lines := strings.Split(strings.TrimSuffix(tbl.String(), "\n"), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "| Language | Key      | Text    |", lines[0])
+	assert.Equal(t, "| -------- | -------- | ------- |", lines[1])
+	assert.Equal(t, "| ja       | home/ti… | ようこ… |", lines[2])
+	assert.Equal(t, "| ar       | a\\|b     | ⁨مرحبا⁩   |", lines[3])
+	assert.Equal(t, "| fr       | 3        |         |", lines[4])
+	for _, line := range lines {
+		assert.Equal(t, Width(lines[0]), Width(line), line)
+	}
+	assert.Equal(t, 3, tbl.Len())
+}
@@ -0,0 +1,149 @@
+package templating
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pattern matches the template variables of texts, {{supportEmail}} or, Helm style,
+// {{ .Values.support.email }}. The second group is the dotted path of the variable.
+var Pattern = regexp.MustCompile(`\{\{-?\s*(\.Values\.)?([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)\s*-?\}\}`)
+
+// Values are the values of template variables, as read from a values file. Texts are
+// resolved with them for validation only; catalogs always keep the template form.
+type Values map[string]interface{}
+
+// Load reads a values file, in YAML or JSON
+func Load(path string) (Values, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v := Values{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid values file %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// Variables returns the paths of the template variables of text, in order of appearance
+func Variables(text string) []string {
+	vars := []string{}
+	for _, m := range Pattern.FindAllStringSubmatch(text, -1) {
+		vars = append(vars, m[2])
+	}
+	return vars
+}
+
+// Lookup returns the value of the variable at a dotted path, false when the values
+// don't define it as a scalar
+func (v Values) Lookup(path string) (string, bool) {
+	var node interface{} = map[string]interface{}(v)
+	for _, part := range strings.Split(path, ".") {
+		var m map[string]interface{}
+		switch n := node.(type) {
+		case map[string]interface{}:
+			m = n
+		case Values:
+			// Nested mappings are decoded with the type of the outer one
+			m = n
+		default:
+			return "", false
+		}
+		var ok bool
+		if node, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+	switch node.(type) {
+	case map[string]interface{}, Values, []interface{}, nil:
+		return "", false
+	}
+	return fmt.Sprint(node), true
+}
+
+// Resolve replaces the template variables of text by their values. Variables the
+// values don't define are left as written.
+func (v Values) Resolve(text string) string {
+	return Pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := v.Lookup(Pattern.FindStringSubmatch(match)[2]); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Check verifies the translation keeps the template variables of the source, and
+// that the values define all of them. The nil Values check nothing.
+func (v Values) Check(source, translation string) error {
+	if v == nil {
+		return nil
+	}
+	wanted, found := set(Variables(source)), set(Variables(translation))
+	err := &VariablesError{}
+	for name := range wanted {
+		if !found[name] {
+			err.Missing = append(err.Missing, "{{"+name+"}}")
+		}
+	}
+	for name := range found {
+		if !wanted[name] {
+			err.Unexpected = append(err.Unexpected, "{{"+name+"}}")
+		}
+	}
+	for name := range wanted {
+		if _, ok := v.Lookup(name); !ok {
+			err.Undefined = append(err.Undefined, "{{"+name+"}}")
+		}
+	}
+	if len(err.Missing) == 0 && len(err.Unexpected) == 0 && len(err.Undefined) == 0 {
+		return nil
+	}
+	sort.Strings(err.Missing)
+	sort.Strings(err.Unexpected)
+	sort.Strings(err.Undefined)
+	return err
+}
+
+// VariablesError is a translation whose template variables differ from those of its
+// source, or a source using variables the values file doesn't define
+type VariablesError struct {
+	// Variables of the source the translation lacks, e.g. {{supportEmail}}
+	Missing []string
+	// Variables of the translation not in the source
+	Unexpected []string
+	// Variables of the source without a value
+	Undefined []string
+}
+
+func (e *VariablesError) Error() string {
+	parts := []string{}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, " "))
+	}
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Unexpected, " "))
+	}
+	if len(parts) > 0 {
+		msg := fmt.Sprintf("template variables changed in translation: %s", strings.Join(parts, ", "))
+		if len(e.Undefined) > 0 {
+			msg += fmt.Sprintf("; undefined in the values file: %s", strings.Join(e.Undefined, " "))
+		}
+		return msg
+	}
+	return fmt.Sprintf("template variables undefined in the values file: %s", strings.Join(e.Undefined, " "))
+}
+
+// set returns the distinct names of a list
+func set(names []string) map[string]bool {
+	s := make(map[string]bool, len(names))
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package templating
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad tests that values files are read in YAML and JSON, nested values included
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("supportEmail: help@acme.com\nsupport:\n  phone: 555-0100\n  hours: 9\n"), 0644))
+	v, err := Load(yamlPath)
+	require.NoError(t, err)
+	value, ok := v.Lookup("support.phone")
+	assert.True(t, ok)
+	assert.Equal(t, "555-0100", value)
+	value, _ = v.Lookup("support.hours")
+	assert.Equal(t, "9", value)
+	_, ok = v.Lookup("support")
+	assert.False(t, ok)
+	_, ok = v.Lookup("support.fax")
+	assert.False(t, ok)
+
+	jsonPath := filepath.Join(dir, "values.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"supportEmail": "help@acme.com"}`), 0644))
+	v, err = Load(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Write to help@acme.com", v.Resolve("Write to {{supportEmail}}"))
+
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[1, 2]`), 0644))
+	_, err = Load(jsonPath)
+	assert.Error(t, err)
+}
+
+// TestCheck tests that translations must keep the template variables of their source
+func TestCheck(t *testing.T) {
+	v := Values{"supportEmail": "help@acme.com", "app": map[string]interface{}{"name": "Acme"}}
+	assert.Equal(t, []string{"supportEmail", "app.name"}, Variables("Mail {{supportEmail}} about {{ .Values.app.name }}"))
+	assert.Equal(t, "Mail help@acme.com about Acme, {{unknown}}", v.Resolve("Mail {{supportEmail}} about {{ .Values.app.name }}, {{unknown}}"))
+
+	assert.NoError(t, v.Check("Write to {{supportEmail}}", "Écrivez à {{supportEmail}}"))
+	assert.NoError(t, v.Check("Welcome", "Bienvenue"))
+	assert.EqualError(t, v.Check("Write to {{supportEmail}}", "Écrivez à {{courriel}}"),
+		"template variables changed in translation: missing {{supportEmail}}, unexpected {{courriel}}")
+	assert.EqualError(t, v.Check("Call {{supportPhone}}", "Appelez {{supportPhone}}"),
+		"template variables undefined in the values file: {{supportPhone}}")
+
+	var none Values
+	assert.NoError(t, none.Check("Write to {{supportEmail}}", "Écrivez"))
+}
@@ -0,0 +1,144 @@
+package tm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// SetVector stores the embedding vector of a source text
+func (m *Memory) SetVector(text string, vector []float32) {
+	if m.vectors == nil {
+		m.vectors = make(map[string][]float32)
+	}
+	m.vectors[text] = vector
+}
+
+// Vector returns the embedding vector of a source text, if known
+func (m *Memory) Vector(text string) ([]float32, bool) {
+	v, ok := m.vectors[text]
+	return v, ok
+}
+
+// Similar returns the entry of the language whose source embedding is the closest to
+// the embedding of text, by cosine similarity. Entries for text itself are ignored.
+func (m *Memory) Similar(text, lang string) (Match, bool) {
+	vector, ok := m.vectors[text]
+	if !ok {
+		return Match{}, false
+	}
+
+	best := Match{}
+	found := false
+	for _, e := range m.entries[lang] {
+		other, ok := m.vectors[e.Source]
+		if !ok || e.Source == text {
+			continue
+		}
+		if score := Cosine(vector, other); !found || score > best.Score {
+			best = Match{Entry: e, Score: score}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Cosine returns the cosine similarity of two vectors, 0 if their lengths differ
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// EmbeddingCache keeps the embedding vectors of texts on disk so each text is only
+// embedded once per model
+type EmbeddingCache struct {
+	path    string
+	vectors map[string]string // text hash -> base64 little-endian float32s
+	dirty   bool
+}
+
+// LoadEmbeddingCache reads the cache of a model from dir. A missing cache is empty.
+func LoadEmbeddingCache(dir, model string) (*EmbeddingCache, error) {
+	c := &EmbeddingCache{
+		path:    filepath.Join(dir, "embeddings-"+model+".json"),
+		vectors: make(map[string]string),
+	}
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.vectors); err != nil {
+		// A corrupt cache is rebuilt rather than failing the run
+		c.vectors = make(map[string]string)
+	}
+	return c, nil
+}
+
+// Get returns the cached vector of text
+func (c *EmbeddingCache) Get(text string) ([]float32, bool) {
+	encoded, ok := c.vectors[hashText(text)]
+	if !ok {
+		return nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data)%4 != 0 {
+		return nil, false
+	}
+	vector := make([]float32, len(data)/4)
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, vector); err != nil {
+		return nil, false
+	}
+	return vector, true
+}
+
+// Put caches the vector of text
+func (c *EmbeddingCache) Put(text string, vector []float32) {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, vector)
+	c.vectors[hashText(text)] = base64.StdEncoding.EncodeToString(buf.Bytes())
+	c.dirty = true
+}
+
+// Save writes the cache if vectors were added
+func (c *EmbeddingCache) Save() error {
+	if !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c.vectors)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,208 @@
+package tm
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entry is a translation unit: a source text and its translation into one language
+type Entry struct {
+	Key    string `json:"key"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Lang   string `json:"lang"`
+}
+
+// Match is a memory entry similar to a looked-up source text
+type Match struct {
+	Entry
+	// Similarity between 0 and 1, 1 is an exact match
+	Score float64
+}
+
+// Memory holds translation units grouped by target language
+type Memory struct {
+	entries map[string][]Entry
+	exact   map[string]map[string]Entry
+	// embedding vectors by source text
+	vectors map[string][]float32
+}
+
+// New returns an empty translation memory
+func New() *Memory {
+	return &Memory{
+		entries: make(map[string][]Entry),
+		exact:   make(map[string]map[string]Entry),
+	}
+}
+
+// Add stores an entry in the memory. Empty sources or targets are ignored.
+func (m *Memory) Add(e Entry) {
+	if e.Source == "" || e.Target == "" {
+		return
+	}
+	if _, ok := m.exact[e.Lang]; !ok {
+		m.exact[e.Lang] = make(map[string]Entry)
+	}
+	if _, ok := m.exact[e.Lang][e.Source]; ok {
+		return
+	}
+	m.exact[e.Lang][e.Source] = e
+	m.entries[e.Lang] = append(m.entries[e.Lang], e)
+}
+
+// AddCatalog stores every translated key of a source/target catalog pair. Keys are
+// added in order, so that ties between matches are broken the same way on every run.
+func (m *Memory) AddCatalog(source, target map[string]string, lang string) {
+	keys := make([]string, 0, len(source))
+	for k := range source {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if dst, ok := target[k]; ok {
+			m.Add(Entry{Key: k, Source: source[k], Target: dst, Lang: lang})
+		}
+	}
+}
+
+// Len returns the number of entries stored for a language
+func (m *Memory) Len(lang string) int {
+	return len(m.entries[lang])
+}
+
+// Entries returns the entries stored for a language
+func (m *Memory) Entries(lang string) []Entry {
+	return m.entries[lang]
+}
+
+// Exact returns the entry whose source is exactly text
+func (m *Memory) Exact(text, lang string) (Entry, bool) {
+	e, ok := m.exact[lang][text]
+	return e, ok
+}
+
+// Best returns the most similar entry to text for the language
+func (m *Memory) Best(text, lang string) (Match, bool) {
+	if e, ok := m.Exact(text, lang); ok {
+		return Match{Entry: e, Score: 1}, true
+	}
+
+	best := Match{}
+	found := false
+	for _, e := range m.entries[lang] {
+		// skip candidates whose length alone rules out a useful match
+		if lengthRatio(text, e.Source) < 0.5 {
+			continue
+		}
+		if score := Similarity(text, e.Source); !found || score > best.Score {
+			best = Match{Entry: e, Score: score}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Nearest returns up to n entries of the language whose sources share the most words
+// with text, most similar first. Entries sharing no word are left out.
+func (m *Memory) Nearest(text, lang string, n int) []Match {
+	words := wordSet(text)
+	matches := []Match{}
+	for _, e := range m.entries[lang] {
+		if score := overlap(words, wordSet(e.Source)); score > 0 {
+			matches = append(matches, Match{Entry: e, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Key < matches[j].Key
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// wordSet returns the lower-cased words of text
+func wordSet(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// overlap returns the Jaccard similarity of two word sets
+func overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func lengthRatio(a, b string) float64 {
+	la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b)
+	if la == 0 || lb == 0 {
+		return 0
+	}
+	if la > lb {
+		la, lb = lb, la
+	}
+	return float64(la) / float64(lb)
+}
+
+// Similarity returns the character-level edit similarity of a and b, ignoring case
+func Similarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(a))
+	rb := []rune(strings.ToLower(b))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package tm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNearest tests entries are ranked by shared words
+func TestNearest(t *testing.T) {
+	m := New()
+	m.AddCatalog(map[string]string{
+		"save":      "Save changes",
+		"discard":   "Discard changes",
+		"saveDraft": "Save as draft",
+		"welcome":   "Welcome back",
+		"empty":     "Save",
+	}, map[string]string{
+		"save":      "Enregistrer les modifications",
+		"discard":   "Annuler les modifications",
+		"saveDraft": "Enregistrer comme brouillon",
+		"welcome":   "Bon retour",
+		"empty":     "",
+	}, "fr")
+
+	keys := []string{}
+	for _, match := range m.Nearest("Save your changes", "fr", 2) {
+		keys = append(keys, match.Key)
+	}
+	assert.Equal(t, []string{"save", "discard"}, keys)
+
+	assert.Len(t, m.Nearest("Save", "fr", 5), 2)
+	assert.Empty(t, m.Nearest("Goodbye", "fr", 3))
+	assert.Empty(t, m.Nearest("Save", "de", 3))
+}
+
+// TestSimilar tests embedding matches and the embedding cache
+func TestSimilar(t *testing.T) {
+	m := New()
+	m.Add(Entry{Key: "save", Source: "Save changes", Target: "Enregistrer les modifications", Lang: "fr"})
+	m.Add(Entry{Key: "cancel", Source: "Cancel", Target: "Annuler", Lang: "fr"})
+
+	_, ok := m.Similar("Save the changes", "fr")
+	assert.False(t, ok, "texts without a vector have no match")
+
+	m.SetVector("Save changes", []float32{1, 0.1, 0})
+	m.SetVector("Cancel", []float32{0, 0, 1})
+	m.SetVector("Save the changes", []float32{0.9, 0.2, 0})
+
+	match, ok := m.Similar("Save the changes", "fr")
+	assert.True(t, ok)
+	assert.Equal(t, "save", match.Key)
+	assert.InDelta(t, 0.99, match.Score, 0.01)
+
+	dir := t.TempDir()
+	cache, err := LoadEmbeddingCache(dir, "model")
+	assert.NoError(t, err)
+	cache.Put("Save changes", []float32{1, 0.1, 0})
+	assert.NoError(t, cache.Save())
+
+	reloaded, err := LoadEmbeddingCache(dir, "model")
+	assert.NoError(t, err)
+	vector, ok := reloaded.Get("Save changes")
+	assert.True(t, ok)
+	assert.Equal(t, []float32{1, 0.1, 0}, vector)
+	_, ok = reloaded.Get("Cancel")
+	assert.False(t, ok)
+}
@@ -0,0 +1,180 @@
+package tm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNamespace is the namespace of the xml:lang attribute
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool string `xml:"creationtool,attr"`
+	SegType      string `xml:"segtype,attr"`
+	Format       string `xml:"o-tmf,attr"`
+	AdminLang    string `xml:"adminlang,attr"`
+	SourceLang   string `xml:"srclang,attr"`
+	DataType     string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	ID       string       `xml:"tuid,attr,omitempty"`
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+type tmxVariant struct {
+	Lang    string `xml:"xml:lang,attr"`
+	Segment string `xml:"seg"`
+}
+
+// WriteTMX writes entries as a TMX 1.4 document, the translation memory exchange format
+// of CAT tools. Entries sharing their key and source text make one translation unit,
+// with a variant per language.
+func WriteTMX(w io.Writer, sourceLang string, entries []Entry) error {
+	type unitKey struct{ key, source string }
+	units := map[unitKey]*tmxUnit{}
+	order := []unitKey{}
+	for _, e := range entries {
+		k := unitKey{e.Key, e.Source}
+		u, ok := units[k]
+		if !ok {
+			u = &tmxUnit{ID: e.Key, Variants: []tmxVariant{{Lang: sourceLang, Segment: e.Source}}}
+			units[k] = u
+			order = append(order, k)
+		}
+		u.Variants = append(u.Variants, tmxVariant{Lang: e.Lang, Segment: e.Target})
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].key != order[j].key {
+			return order[i].key < order[j].key
+		}
+		return order[i].source < order[j].source
+	})
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool: "i18n-cli",
+			SegType:      "block",
+			Format:       "i18n-cli",
+			AdminLang:    sourceLang,
+			SourceLang:   sourceLang,
+			DataType:     "plaintext",
+		},
+	}
+	for _, k := range order {
+		u := units[k]
+		sort.SliceStable(u.Variants[1:], func(i, j int) bool { return u.Variants[i+1].Lang < u.Variants[j+1].Lang })
+		doc.Units = append(doc.Units, *u)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadTMX reads the translation units of a TMX document as entries from sourceLang,
+// or from the source language of its header when empty, into each other language.
+// Inline markup of segments is kept as its text, and units without a source variant
+// are left out.
+func ReadTMX(r io.Reader, sourceLang string) ([]Entry, error) {
+	dec := xml.NewDecoder(r)
+	entries := []Entry{}
+	var (
+		id       string
+		variants []tmxVariant
+		lang     string
+		segment  *strings.Builder
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid TMX: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "header":
+				if sourceLang == "" {
+					sourceLang = attr(t, "srclang")
+				}
+			case "tu":
+				id, variants = attr(t, "tuid"), nil
+			case "tuv":
+				lang = attr(t, "lang")
+			case "seg":
+				segment = &strings.Builder{}
+			}
+		case xml.CharData:
+			if segment != nil {
+				segment.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "seg":
+				if segment != nil {
+					variants = append(variants, tmxVariant{Lang: lang, Segment: segment.String()})
+					segment = nil
+				}
+			case "tu":
+				entries = append(entries, unitEntries(id, variants, sourceLang)...)
+			}
+		}
+	}
+	if sourceLang == "" {
+		return nil, fmt.Errorf("invalid TMX: no source language")
+	}
+	return entries, nil
+}
+
+// unitEntries returns the entries of the variants of a translation unit
+func unitEntries(id string, variants []tmxVariant, sourceLang string) []Entry {
+	source, found := "", false
+	for _, v := range variants {
+		if strings.EqualFold(v.Lang, sourceLang) {
+			source, found = v.Segment, true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	entries := []Entry{}
+	for _, v := range variants {
+		if !strings.EqualFold(v.Lang, sourceLang) {
+			entries = append(entries, Entry{Key: id, Source: source, Target: v.Segment, Lang: v.Lang})
+		}
+	}
+	return entries
+}
+
+// attr returns the value of the attribute of e named name, in any namespace, so that
+// both xml:lang and the lang of TMX 1.1 are read
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name && (a.Name.Space == "" || a.Name.Space == xmlNamespace) {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package tm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTMXRoundTrip tests that entries written as TMX are read back
+func TestTMXRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Key: "cart.title", Source: "Your cart", Target: "Votre panier", Lang: "fr"},
+		{Key: "cart.title", Source: "Your cart", Target: "Ihr Warenkorb", Lang: "de"},
+		{Key: "a & b", Source: "Save <b>now</b>", Target: "Enregistrer <b>maintenant</b>", Lang: "fr"},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTMX(&buf, "en", entries))
+	assert.Contains(t, buf.String(), `<tmx version="1.4">`)
+	assert.Contains(t, buf.String(), `<tuv xml:lang="de">`)
+
+	read, err := ReadTMX(&buf, "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, entries, read)
+}
+
+// TestReadTMX tests reading TMX written by other tools: inline markup, TMX 1.1
+// languages and units without the source language
+func TestReadTMX(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<tmx version="1.1"><header srclang="EN-US" creationtool="cat"/><body>
+  <tu><tuv lang="EN-US"><seg>Hello <ph>{name}</ph></seg></tuv><tuv lang="FR-FR"><seg>Bonjour <ph>{name}</ph></seg></tuv></tu>
+  <tu><tuv lang="DE-DE"><seg>Orphan</seg></tuv></tu>
+</body></tmx>`
+	entries, err := ReadTMX(strings.NewReader(doc), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Source: "Hello {name}", Target: "Bonjour {name}", Lang: "FR-FR"}}, entries)
+
+	_, err = ReadTMX(strings.NewReader("<tmx><body>"), "en")
+	assert.ErrorContains(t, err, "invalid TMX")
+}
@@ -0,0 +1,345 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Settings of the export of spans, in the OpenTelemetry protocol (OTLP) over HTTP with
+// JSON bodies, which collectors (OpenTelemetry Collector, Jaeger, Grafana Tempo,
+// Honeycomb...) accept
+type Settings struct {
+	// OTLP/HTTP endpoint, e.g. "http://localhost:4318"; spans are posted to its
+	// /v1/traces path. Defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Headers of export requests, e.g. an API key of the backend. Default to the
+	// OTEL_EXPORTER_OTLP_HEADERS environment variable (key=value,key=value).
+	Headers map[string]string `json:"headers,omitempty"`
+	// File OTLP JSON documents are appended to, one line per run, e.g. to keep as a CI
+	// artifact
+	File string `json:"file,omitempty"`
+	// Name of the service of the spans (default: i18n-cli)
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// DefaultServiceName is the service name of spans unless set otherwise
+const DefaultServiceName = "i18n-cli"
+
+// Validate checks the endpoint of s
+func (s *Settings) Validate() error {
+	if s.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("tracing.endpoint must be an http or https URL, got %q", s.Endpoint)
+	}
+	return nil
+}
+
+// Kinds of spans, as numbered by OTLP
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// statusError is the OTLP status code of failed spans
+const statusError = 2
+
+// Tracer collects the spans of runs until they are exported
+type Tracer struct {
+	settings Settings
+	client   *http.Client
+
+	mu    sync.Mutex
+	ended []*Span
+}
+
+// New returns a tracer exporting spans as set by settings, falling back to the
+// OTEL_EXPORTER_OTLP_* environment variables for the endpoint and headers
+func New(settings Settings) *Tracer {
+	if settings.Endpoint == "" && settings.File == "" {
+		settings.Endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if settings.Headers == nil {
+		settings.Headers = parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	}
+	if settings.ServiceName == "" {
+		settings.ServiceName = DefaultServiceName
+	}
+	return &Tracer{settings: settings, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Exports reports whether the spans of t go anywhere
+func (t *Tracer) Exports() bool {
+	return t.settings.Endpoint != "" || t.settings.File != ""
+}
+
+// parseHeaders parses headers written key=value,key=value
+func parseHeaders(s string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		if v, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = v
+		}
+		headers[strings.TrimSpace(key)] = value
+	}
+	return headers
+}
+
+// Span is a timed operation of a trace. The methods of a nil span do nothing, so that
+// code is instrumented the same way whether tracing is enabled or not.
+type Span struct {
+	tracer   *Tracer
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []string
+	status   int
+	message  string
+}
+
+type tracerKey struct{}
+
+type spanKey struct{}
+
+// WithTracer returns a copy of ctx whose spans are collected by t
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// FromContext returns the tracer of ctx, nil without one
+func FromContext(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	return t
+}
+
+// Start starts a span named name, a child of the span of ctx or the root of a new
+// trace, with attributes given as name/value pairs. It returns ctx carrying the span
+// and the span, or ctx itself and a nil span when ctx has no tracer.
+func Start(ctx context.Context, name string, attrs ...string) (context.Context, *Span) {
+	return start(ctx, name, kindInternal, attrs)
+}
+
+// StartClient starts a span like Start, of a request sent to a remote service
+func StartClient(ctx context.Context, name string, attrs ...string) (context.Context, *Span) {
+	return start(ctx, name, kindClient, attrs)
+}
+
+func start(ctx context.Context, name string, kind int, attrs []string) (context.Context, *Span) {
+	t := FromContext(ctx)
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{tracer: t, spanID: newID(8), name: name, kind: kind, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		s.traceID = newID(16)
+	}
+	s.Set(attrs...)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// newID returns n random bytes, hex encoded
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Set adds attributes given as name/value pairs to s
+func (s *Span) Set(attrs ...string) {
+	if s == nil {
+		return
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.attrs = append(s.attrs, attrs[i], attrs[i+1])
+	}
+}
+
+// Fail marks s as failed with err, when not nil
+func (s *Span) Fail(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.status, s.message = statusError, err.Error()
+}
+
+// End ends s, which is exported with the next flush of its tracer
+func (s *Span) End() {
+	if s == nil || !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.mu.Lock()
+	s.tracer.ended = append(s.tracer.ended, s)
+	s.tracer.mu.Unlock()
+}
+
+// Flush exports the spans of the tracer of ctx ended since the last flush
+func Flush(ctx context.Context) error {
+	if t := FromContext(ctx); t != nil {
+		return t.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush exports the spans ended since the last flush, to the endpoint and the file of t
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	spans := t.ended
+	t.ended = nil
+	t.mu.Unlock()
+	if len(spans) == 0 || !t.Exports() {
+		return nil
+	}
+
+	body, err := json.Marshal(t.document(spans))
+	if err != nil {
+		return err
+	}
+	if t.settings.File != "" {
+		if err := appendLine(t.settings.File, body); err != nil {
+			return fmt.Errorf("writing spans: %w", err)
+		}
+	}
+	if t.settings.Endpoint != "" {
+		if err := t.post(ctx, body); err != nil {
+			return fmt.Errorf("exporting spans: %w", err)
+		}
+	}
+	return nil
+}
+
+// post sends an OTLP JSON document to the traces path of the endpoint
+func (t *Tracer) post(ctx context.Context, body []byte) error {
+	endpoint := strings.TrimSuffix(t.settings.Endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.settings.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// appendLine appends data and a newline to the file at path
+func appendLine(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// OTLP JSON encoding of spans (opentelemetry/proto/collector/trace/v1)
+type (
+	otlpDocument struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID      string          `json:"traceId"`
+		SpanID       string          `json:"spanId"`
+		ParentSpanID string          `json:"parentSpanId,omitempty"`
+		Name         string          `json:"name"`
+		Kind         int             `json:"kind"`
+		Start        string          `json:"startTimeUnixNano"`
+		End          string          `json:"endTimeUnixNano"`
+		Attributes   []otlpAttribute `json:"attributes,omitempty"`
+		Status       otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// document returns the OTLP document of spans
+func (t *Tracer) document(spans []*Span) otlpDocument {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/pandodao/i18n-cli"}}
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:      s.traceID,
+			SpanID:       s.spanID,
+			ParentSpanID: s.parentID,
+			Name:         s.name,
+			Kind:         s.kind,
+			Start:        strconv.FormatInt(s.start.UnixNano(), 10),
+			End:          strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:   attributes(s.attrs),
+			Status:       otlpStatus{Code: s.status, Message: s.message},
+		}
+		scope.Spans = append(scope.Spans, span)
+	}
+	resource := otlpResource{Attributes: attributes([]string{"service.name", t.settings.ServiceName})}
+	return otlpDocument{ResourceSpans: []otlpResourceSpans{{Resource: resource, ScopeSpans: []otlpScopeSpans{scope}}}}
+}
+
+// attributes returns the OTLP attributes of name/value pairs
+func attributes(pairs []string) []otlpAttribute {
+	attrs := make([]otlpAttribute, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		attrs = append(attrs, otlpAttribute{Key: pairs[i], Value: otlpValue{StringValue: pairs[i+1]}})
+	}
+	return attrs
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithoutTracer tests that spans are nil and harmless when ctx has no tracer
+func TestWithoutTracer(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "file.process", "lang", "fr")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, spanCtx)
+	span.Set("key", "value")
+	span.Fail(errors.New("failed"))
+	span.End()
+	assert.NoError(t, Flush(ctx))
+}
+
+// TestSpans tests that child spans share the trace of their parent
+func TestSpans(t *testing.T) {
+	tracer := New(Settings{File: filepath.Join(t.TempDir(), "spans.jsonl")})
+	ctx := WithTracer(context.Background(), tracer)
+
+	ctx, root := Start(ctx, "sync")
+	_, child := StartClient(ctx, "provider.request", "provider", "openai")
+	child.Fail(errors.New("429 Too Many Requests"))
+	child.End()
+	child.End()
+	root.End()
+	_, other := Start(WithTracer(context.Background(), tracer), "translate")
+	other.End()
+
+	assert.Len(t, tracer.ended, 3)
+	assert.Equal(t, root.traceID, child.traceID)
+	assert.Equal(t, root.spanID, child.parentID)
+	assert.Empty(t, root.parentID)
+	assert.NotEqual(t, root.traceID, other.traceID)
+	assert.Len(t, root.traceID, 32)
+	assert.Len(t, root.spanID, 16)
+	assert.Equal(t, kindClient, child.kind)
+	assert.Equal(t, statusError, child.status)
+	assert.Equal(t, []string{"provider", "openai"}, child.attrs)
+}
+
+// TestFlush tests that spans are posted to the collector and appended to the file as
+// OTLP JSON, once
+func TestFlush(t *testing.T) {
+	var posted []otlpDocument
+	var paths, keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc otlpDocument
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&doc))
+		posted = append(posted, doc)
+		paths = append(paths, r.URL.Path)
+		keys = append(keys, r.Header.Get("X-Api-Key"))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "spans.jsonl")
+	tracer := New(Settings{Endpoint: server.URL + "/", Headers: map[string]string{"X-Api-Key": "secret"}, File: file, ServiceName: "catalogs"})
+	ctx := WithTracer(context.Background(), tracer)
+	_, span := Start(ctx, "files.commit", "files", "3")
+	span.End()
+
+	assert.NoError(t, Flush(ctx))
+	assert.NoError(t, Flush(ctx))
+	assert.Equal(t, []string{"/v1/traces"}, paths)
+	assert.Equal(t, []string{"secret"}, keys)
+
+	doc := posted[0].ResourceSpans[0]
+	assert.Equal(t, "service.name", doc.Resource.Attributes[0].Key)
+	assert.Equal(t, "catalogs", doc.Resource.Attributes[0].Value.StringValue)
+	spans := doc.ScopeSpans[0].Spans
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "files.commit", spans[0].Name)
+	assert.Equal(t, kindInternal, spans[0].Kind)
+	assert.Equal(t, []otlpAttribute{{Key: "files", Value: otlpValue{StringValue: "3"}}}, spans[0].Attributes)
+
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var written otlpDocument
+	assert.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, posted[0], written)
+}
+
+// TestCollectorError tests that failed exports are reported
+func TestCollectorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	tracer := New(Settings{Endpoint: server.URL})
+	_, span := Start(WithTracer(context.Background(), tracer), "sync")
+	span.End()
+	assert.ErrorContains(t, tracer.Flush(context.Background()), "401")
+}
+
+// TestEnvironment tests the fallback to the OTEL_EXPORTER_OTLP_* environment variables
+func TestEnvironment(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-honeycomb-team=abc%3D,bad, dataset = ci")
+
+	tracer := New(Settings{})
+	assert.True(t, tracer.Exports())
+	assert.Equal(t, "http://collector:4318", tracer.settings.Endpoint)
+	assert.Equal(t, map[string]string{"x-honeycomb-team": "abc=", "dataset": "ci"}, tracer.settings.Headers)
+	assert.Equal(t, DefaultServiceName, tracer.settings.ServiceName)
+
+	// A file set in the config doesn't also export to the environment endpoint
+	assert.Empty(t, New(Settings{File: "spans.jsonl"}).settings.Endpoint)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	assert.False(t, New(Settings{}).Exports())
+}
+
+// TestValidate tests the validation of endpoints
+func TestValidate(t *testing.T) {
+	assert.NoError(t, (&Settings{}).Validate())
+	assert.NoError(t, (&Settings{Endpoint: "https://api.honeycomb.io"}).Validate())
+	assert.Error(t, (&Settings{Endpoint: "localhost:4318"}).Validate())
+	assert.Error(t, (&Settings{Endpoint: "grpc://collector:4317"}).Validate())
+}
@@ -0,0 +1,65 @@
+package upstream
+
+import (
+	"sort"
+
+	"github.com/pandodao/i18n-cli/internal/icu"
+)
+
+// Conflict is a key translated both by us and upstream, differently
+type Conflict struct {
+	Key    string `json:"key"`
+	Ours   string `json:"ours"`
+	Theirs string `json:"theirs"`
+}
+
+// Result is the outcome of merging the upstream translations of a catalog into ours
+type Result struct {
+	// Upstream translations of the keys we have no translation for, by key
+	Imported map[string]string `json:"imported"`
+	// Keys translated differently upstream, whose translation of ours is kept
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Keys whose upstream source text differs from ours, so their upstream translation
+	// no longer applies
+	SourceChanged []string `json:"sourceChanged,omitempty"`
+	// Keys whose upstream translation doesn't keep the ICU arguments of our source
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+// Merge merges the upstream translations theirs into the translations ours of source,
+// by key. Our translations are preferred: only keys we have no translation for are
+// imported, and the keys translated differently are reported as conflicts. When the
+// upstream source texts are given, translations of texts that differ from ours are
+// left out. Neither ours nor theirs are changed.
+func Merge(source, ours, upstreamSource, theirs map[string]string) Result {
+	result := Result{Imported: map[string]string{}}
+	keys := make([]string, 0, len(source))
+	for k := range source {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		text, translation := source[k], theirs[k]
+		if text == "" || translation == "" {
+			continue
+		}
+		current := ours[k]
+		if current != "" {
+			if current != translation {
+				result.Conflicts = append(result.Conflicts, Conflict{Key: k, Ours: current, Theirs: translation})
+			}
+			continue
+		}
+		if upstreamSource != nil && upstreamSource[k] != text {
+			result.SourceChanged = append(result.SourceChanged, k)
+			continue
+		}
+		if translation != text && icu.Check(text, translation) != nil {
+			result.Rejected = append(result.Rejected, k)
+			continue
+		}
+		result.Imported[k] = translation
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMerge tests that only our missing translations are imported, and that
+// conflicts, changed sources and broken arguments are reported
+func TestMerge(t *testing.T) {
+	source := map[string]string{
+		"save":    "Save",
+		"cancel":  "Cancel",
+		"title":   "Welcome",
+		"files":   "{count, number} files",
+		"changed": "Sign in",
+		"blank":   "",
+		"ours":    "Only ours",
+	}
+	ours := map[string]string{"cancel": "Annuler", "title": "Bienvenue", "ours": "Seulement à nous"}
+	upstreamSource := map[string]string{
+		"save":    "Save",
+		"cancel":  "Cancel",
+		"title":   "Welcome",
+		"files":   "{count, number} files",
+		"changed": "Log in",
+		"blank":   "",
+	}
+	theirs := map[string]string{
+		"save":    "Enregistrer",
+		"cancel":  "Annuler",
+		"title":   "Bienvenue !",
+		"files":   "{nombre} fichiers",
+		"changed": "Connexion",
+		"blank":   "Vide",
+	}
+
+	result := Merge(source, ours, upstreamSource, theirs)
+	assert.Equal(t, map[string]string{"save": "Enregistrer"}, result.Imported)
+	assert.Equal(t, []Conflict{{Key: "title", Ours: "Bienvenue", Theirs: "Bienvenue !"}}, result.Conflicts)
+	assert.Equal(t, []string{"changed"}, result.SourceChanged)
+	assert.Equal(t, []string{"files"}, result.Rejected)
+
+	// Without the upstream source texts, translations are imported by key alone
+	result = Merge(source, ours, nil, theirs)
+	assert.Equal(t, map[string]string{"save": "Enregistrer", "changed": "Connexion"}, result.Imported)
+	assert.Empty(t, result.SourceChanged)
+}
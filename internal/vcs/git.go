@@ -0,0 +1,151 @@
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Repo is a git working tree
+type Repo struct {
+	Dir string
+}
+
+// Open returns the repository containing dir
+func Open(dir string) (*Repo, error) {
+	out, err := (&Repo{Dir: dir}).git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", dir, err)
+	}
+	return &Repo{Dir: out}, nil
+}
+
+// git runs a git command in the repository and returns its trimmed output
+func (r *Repo) git(args ...string) (string, error) {
+	out, err := r.output(args...)
+	return strings.TrimSpace(out), err
+}
+
+// output runs a git command in the repository and returns its output as is
+func (r *Repo) output(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// CurrentBranch returns the name of the checked out branch
+func (r *Repo) CurrentBranch() (string, error) {
+	return r.git("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// Clean reports whether the files under paths have no uncommitted changes
+func (r *Repo) Clean(paths ...string) (bool, error) {
+	out, err := r.git(append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return false, err
+	}
+	return out == "", nil
+}
+
+// CreateBranch creates the branch name from base and checks it out
+func (r *Repo) CreateBranch(name, base string) error {
+	_, err := r.git("checkout", "-q", "-b", name, base)
+	return err
+}
+
+// Checkout checks out an existing branch
+func (r *Repo) Checkout(name string) error {
+	_, err := r.git("checkout", "-q", name)
+	return err
+}
+
+// DeleteBranch deletes a local branch
+func (r *Repo) DeleteBranch(name string) error {
+	_, err := r.git("branch", "-q", "-D", name)
+	return err
+}
+
+// Stage stages every change under path, except the files in exclude
+func (r *Repo) Stage(path string, exclude ...string) error {
+	if _, err := r.git("add", "-A", "--", path); err != nil {
+		return err
+	}
+	for _, p := range exclude {
+		if _, err := r.git("reset", "-q", "--", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StagedFiles returns the paths of the staged changes, relative to the repository root
+func (r *Repo) StagedFiles() ([]string, error) {
+	out, err := r.git("diff", "--cached", "--name-only")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Commit commits the staged changes
+func (r *Repo) Commit(message string) error {
+	_, err := r.git("commit", "-q", "-m", message)
+	return err
+}
+
+// Push pushes branch to remote and sets it as upstream
+func (r *Repo) Push(remote, branch string) error {
+	_, err := r.git("push", "-q", "-u", remote, branch)
+	return err
+}
+
+// RemoteURL returns the URL of a remote
+func (r *Repo) RemoteURL(remote string) (string, error) {
+	return r.git("remote", "get-url", remote)
+}
+
+// ChangedFiles returns the paths of the uncommitted changes under path, staged or not,
+// untracked files included, relative to the repository root
+func (r *Repo) ChangedFiles(path string) ([]string, error) {
+	out, err := r.output("status", "--porcelain", "-z", "-uall", "--", path)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	files := []string{}
+	entries := strings.Split(strings.TrimRight(out, "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		if len(entries[i]) < 4 {
+			continue
+		}
+		files = append(files, entries[i][3:])
+		// Renames and copies are followed by their original path
+		if status := entries[i][0]; status == 'R' || status == 'C' {
+			i++
+		}
+	}
+	return files, nil
+}
+
+// FileAt returns the content of the file at path, relative to the repository root, in
+// revision rev
+func (r *Repo) FileAt(rev, path string) (string, error) {
+	return r.output("show", rev+":"+path)
+}
+
+// CommitSignedOff commits the staged changes with a Signed-off-by trailer of the
+// configured user
+func (r *Repo) CommitSignedOff(message string) error {
+	_, err := r.git("commit", "-q", "-s", "-m", message)
+	return err
+}
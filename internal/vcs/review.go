@@ -0,0 +1,154 @@
+package vcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Hosting services pull requests can be opened on
+const (
+	HostGitHub = "github"
+	HostGitLab = "gitlab"
+)
+
+// Remote is a repository on a hosting service
+type Remote struct {
+	Host string // e.g. github.com
+	Path string // e.g. owner/repo
+}
+
+// ParseRemote parses an SSH (git@host:owner/repo.git) or HTTPS remote URL
+func ParseRemote(raw string) (Remote, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(raw), ".git")
+	if !strings.Contains(s, "://") {
+		// scp-like syntax
+		if at := strings.Index(s, "@"); at >= 0 {
+			s = s[at+1:]
+		}
+		host, path, ok := strings.Cut(s, ":")
+		if !ok || host == "" || path == "" {
+			return Remote{}, fmt.Errorf("unsupported remote URL %q", raw)
+		}
+		return Remote{Host: host, Path: strings.Trim(path, "/")}, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return Remote{}, fmt.Errorf("unsupported remote URL %q: %w", raw, err)
+	}
+	path := strings.Trim(u.Path, "/")
+	if u.Hostname() == "" || path == "" {
+		return Remote{}, fmt.Errorf("unsupported remote URL %q", raw)
+	}
+	return Remote{Host: u.Hostname(), Path: path}, nil
+}
+
+// Service guesses the hosting service of the remote from its host name
+func (r Remote) Service() string {
+	if strings.Contains(r.Host, "gitlab") {
+		return HostGitLab
+	}
+	return HostGitHub
+}
+
+// PullRequest describes a pull (merge) request to open
+type PullRequest struct {
+	Title string
+	Body  string
+	Head  string // branch with the changes
+	Base  string // branch to merge into
+	Draft bool
+}
+
+// Open opens the pull request on the given service and returns its URL. GitHub
+// pull requests are opened with the gh CLI, GitLab merge requests through the API
+// with the token in GITLAB_TOKEN.
+func (pr PullRequest) Open(repo *Repo, remote Remote, service string) (string, error) {
+	switch service {
+	case HostGitHub:
+		return pr.openGitHub(repo)
+	case HostGitLab:
+		return pr.openGitLab(remote)
+	}
+	return "", fmt.Errorf("unknown hosting service %q (expected github or gitlab)", service)
+}
+
+func (pr PullRequest) openGitHub(repo *Repo) (string, error) {
+	args := []string{"pr", "create", "--title", pr.Title, "--body", pr.Body, "--head", pr.Head, "--base", pr.Base}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = repo.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("gh pr create: %s", msg)
+		}
+		return "", fmt.Errorf("gh pr create: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (pr PullRequest) openGitLab(remote Remote) (string, error) {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		return "", fmt.Errorf("GITLAB_TOKEN is not set")
+	}
+	api := os.Getenv("GITLAB_API_URL")
+	if api == "" {
+		api = "https://" + remote.Host + "/api/v4"
+	}
+
+	title := pr.Title
+	if pr.Draft {
+		title = "Draft: " + title
+	}
+	payload, err := json.Marshal(map[string]string{
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+		"title":         title,
+		"description":   pr.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := strings.TrimSuffix(api, "/") + "/projects/" + url.PathEscape(remote.Path) + "/merge_requests"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("gitlab merge request: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", err
+	}
+	return created.WebURL, nil
+}
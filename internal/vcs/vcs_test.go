@@ -0,0 +1,123 @@
+package vcs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemote(t *testing.T) {
+	cases := map[string]Remote{
+		"git@github.com:pandodao/i18n-cli.git":        {Host: "github.com", Path: "pandodao/i18n-cli"},
+		"https://github.com/pandodao/i18n-cli":        {Host: "github.com", Path: "pandodao/i18n-cli"},
+		"ssh://git@gitlab.example.com:2222/a/b/c.git": {Host: "gitlab.example.com", Path: "a/b/c"},
+		"https://user@gitlab.com/group/project.git":   {Host: "gitlab.com", Path: "group/project"},
+	}
+	for raw, want := range cases {
+		got, err := ParseRemote(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	_, err := ParseRemote("not a remote")
+	assert.Error(t, err)
+
+	assert.Equal(t, HostGitLab, Remote{Host: "gitlab.example.com"}.Service())
+	assert.Equal(t, HostGitHub, Remote{Host: "github.com"}.Service())
+}
+
+func TestBranchAndCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	repo := &Repo{Dir: dir}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		_, err := repo.git(args...)
+		require.NoError(t, err)
+	}
+	locales := filepath.Join(dir, "locales")
+	require.NoError(t, os.MkdirAll(locales, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(locales, "en.json"), []byte(`{"a": "A"}`), 0644))
+	require.NoError(t, repo.Stage("locales"))
+	require.NoError(t, repo.Commit("initial"))
+
+	opened, err := Open(locales)
+	require.NoError(t, err)
+	resolved, _ := filepath.EvalSymlinks(dir)
+	gotDir, _ := filepath.EvalSymlinks(opened.Dir)
+	assert.Equal(t, resolved, gotDir)
+
+	clean, err := repo.Clean("locales")
+	require.NoError(t, err)
+	assert.True(t, clean)
+
+	require.NoError(t, repo.CreateBranch("i18n/fr", "main"))
+	require.NoError(t, os.WriteFile(filepath.Join(locales, "fr.json"), []byte(`{"a": "A fr"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(locales, ".lock"), []byte(`x`), 0644))
+
+	clean, err = repo.Clean("locales")
+	require.NoError(t, err)
+	assert.False(t, clean)
+
+	require.NoError(t, repo.Stage("locales", "locales/.lock"))
+	staged, err := repo.StagedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"locales/fr.json"}, staged)
+	require.NoError(t, repo.Commit("add fr"))
+
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "i18n/fr", branch)
+
+	require.NoError(t, repo.Checkout("main"))
+	_, err = os.Stat(filepath.Join(locales, "fr.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	repo := &Repo{Dir: dir}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		_, err := repo.git(args...)
+		require.NoError(t, err)
+	}
+	locales := filepath.Join(dir, "locales")
+	require.NoError(t, os.MkdirAll(filepath.Join(locales, "fr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(locales, "en.json"), []byte(`{"a": "A"}`), 0644))
+	require.NoError(t, repo.Stage("locales"))
+	require.NoError(t, repo.Commit("initial"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(locales, "en.json"), []byte(`{"a": "B"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(locales, "fr", "common.json"), []byte(`{"a": "A fr"}`), 0644))
+	changed, err := repo.ChangedFiles("locales")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"locales/en.json", "locales/fr/common.json"}, changed)
+
+	committed, err := repo.FileAt("HEAD", "locales/en.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": "A"}`, committed)
+
+	require.NoError(t, repo.Stage("locales"))
+	require.NoError(t, repo.CommitSignedOff("update"))
+	message, err := repo.git("log", "-1", "--format=%B")
+	require.NoError(t, err)
+	assert.Contains(t, message, "Signed-off-by: test <test@example.com>")
+}
@@ -0,0 +1,96 @@
+package wordcount
+
+import (
+	"unicode"
+)
+
+// Count returns the number of words and characters (excluding whitespace) in text.
+// Ideographic, kana, and hangul characters count as one word each, as CAT tools do.
+func Count(text string) (words int, chars int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			inWord = false
+			continue
+		case unicode.Is(unicode.Han, r), unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r), unicode.Is(unicode.Hangul, r):
+			words++
+			inWord = false
+		default:
+			if !inWord {
+				words++
+				inWord = true
+			}
+		}
+		chars++
+	}
+	return words, chars
+}
+
+// Band is a translation memory match category used for vendor quotes
+type Band struct {
+	Name string
+	// Lowest match score (0-1) that falls into the band
+	MinScore float64
+	// Share of the full per-word rate charged for the band
+	Weight float64
+}
+
+// Repetitions is the band of texts repeated within the untranslated content
+var Repetitions = Band{Name: "Repetitions", MinScore: 1, Weight: 0.25}
+
+// Bands are the match bands, from best to worst, with common CAT tool weights
+var Bands = []Band{
+	{Name: "100%", MinScore: 1, Weight: 0.3},
+	{Name: "95-99%", MinScore: 0.95, Weight: 0.5},
+	{Name: "85-94%", MinScore: 0.85, Weight: 0.6},
+	{Name: "75-84%", MinScore: 0.75, Weight: 0.8},
+	{Name: "No match", MinScore: 0, Weight: 1},
+}
+
+// BandFor returns the index in Bands of a match score
+func BandFor(score float64) int {
+	for i, b := range Bands {
+		if score >= b.MinScore {
+			return i
+		}
+	}
+	return len(Bands) - 1
+}
+
+// Stats accumulates word counts per band
+type Stats struct {
+	Keys        int
+	Words       int
+	Chars       int
+	Repetitions int
+	BandWords   []int
+}
+
+// NewStats returns empty stats with one counter per band
+func NewStats() *Stats {
+	return &Stats{BandWords: make([]int, len(Bands))}
+}
+
+// Add counts a text with its best match score. Repeated texts are counted as repetitions.
+func (s *Stats) Add(text string, score float64, repeated bool) {
+	words, chars := Count(text)
+	s.Keys++
+	s.Words += words
+	s.Chars += chars
+
+	if repeated && score < 1 {
+		s.Repetitions += words
+		return
+	}
+	s.BandWords[BandFor(score)] += words
+}
+
+// Weighted returns the word count weighted by the band rates
+func (s *Stats) Weighted() float64 {
+	total := float64(s.Repetitions) * Repetitions.Weight
+	for i, words := range s.BandWords {
+		total += float64(words) * Bands[i].Weight
+	}
+	return total
+}
@@ -0,0 +1,32 @@
+package wordcount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCount tests word and character counts for spaced and CJK text
+func TestCount(t *testing.T) {
+	words, chars := Count("Save  your changes!")
+	assert.Equal(t, 3, words)
+	assert.Equal(t, 16, chars)
+
+	words, chars = Count("保存する")
+	assert.Equal(t, 4, words)
+	assert.Equal(t, 4, chars)
+}
+
+// TestStatsWeighted tests that words are weighted by their match band
+func TestStatsWeighted(t *testing.T) {
+	s := NewStats()
+	s.Add("one two three four", 0, false)
+	s.Add("one two", 1, false)
+	s.Add("one two three four", 0, true)
+	s.Add("five six", 0.9, false)
+
+	assert.Equal(t, 4, s.Keys)
+	assert.Equal(t, 4, s.Repetitions)
+	assert.Equal(t, 4, s.BandWords[len(Bands)-1])
+	assert.InDelta(t, 4*1+2*0.3+4*0.25+2*0.6, s.Weighted(), 0.001)
+}